@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/desktop"
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/reminders"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// accessNotificationID is the ID of the notification raised when the daemon
+// cannot start for lack of Reminders access.
+const accessNotificationID = "reminderrelay_reminders_access"
+
+// runGrantAccess asks macOS for access to Reminders, or, if it was denied
+// before, opens System Settings and waits until it is granted. A loaded
+// daemon is restarted afterwards so it picks the access up.
+func runGrantAccess(args []string) error {
+	fs := flag.NewFlagSet("grant-access", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 5*time.Minute, "how long to wait for access to be granted in System Settings")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: reminderrelay grant-access [--timeout <duration>]")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	if err := grantAccess(ctx, *timeout); err != nil {
+		return err
+	}
+	fmt.Println("✓ Reminders access granted")
+	return restartDaemonIfLoaded()
+}
+
+// grantAccess drives the macOS permissions dialog for Reminders. Access that
+// was never requested is requested; access denied before can only be
+// granted in System Settings, which is opened, and is then waited for up to
+// timeout.
+func grantAccess(ctx context.Context, timeout time.Duration) error {
+	status := reminders.AuthorizationStatus()
+	if status == reminders.AuthorizationNotDetermined {
+		fmt.Println("Asking macOS for access to Reminders…")
+		status = reminders.RequestAccess()
+	}
+	switch status {
+	case reminders.AuthorizationGranted:
+		return nil
+	case reminders.AuthorizationUnsupported:
+		return errors.New("access to Reminders can only be granted on macOS")
+	case reminders.AuthorizationRestricted:
+		return fmt.Errorf("%w: access is restricted by a configuration profile or Screen Time", model.ErrAccessDenied)
+	}
+
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintf(os.Stderr, "⚠️  Reminders access is %s.\n", status)
+	fmt.Fprintln(os.Stderr, "   Opening System Settings → Privacy & Security → Reminders…")
+	_ = exec.Command("open", reminders.PrivacySettingsURL).Start()
+	fmt.Fprintln(os.Stderr, "   Allow full access for reminderrelay there; waiting (Ctrl-C to stop)…")
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("%w: still %s after %s", model.ErrAccessDenied, reminders.AuthorizationStatus(), timeout)
+		case <-ticker.C:
+			if reminders.AuthorizationStatus() == reminders.AuthorizationGranted {
+				return nil
+			}
+		}
+	}
+}
+
+// notifyNoAccess tells the user through the notifications set up under
+// notify that the daemon cannot start for lack of Reminders access. Nobody
+// sees the terminal of a daemon started by launchd.
+func notifyNoAccess(cfg *config.Config, accessErr error, logger *slog.Logger) {
+	if cfg.Notify == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var notifiers []syncp.ConflictNotifier
+	if cfg.Notify.HAPersistent {
+		haAdapter, err := newHAAdapter(cfg, logger)
+		if err != nil {
+			logger.Warn("could not notify Home Assistant of missing Reminders access", "error", err)
+		} else {
+			notifiers = append(notifiers, haAdapter)
+		}
+	}
+	if cfg.Notify.MacOS {
+		notifiers = append(notifiers, desktop.NewNotifier(0, logger))
+	}
+	message := fmt.Sprintf("ReminderRelay cannot read Apple Reminders (%v), so nothing is synced. "+
+		"Run `reminderrelay grant-access` in a terminal on the Mac, or allow access in "+
+		"System Settings → Privacy & Security → Reminders.", accessErr)
+	for _, n := range notifiers {
+		if err := n.CreateNotification(ctx, accessNotificationID, "ReminderRelay needs Reminders access", message); err != nil {
+			logger.Warn("sending notification of missing Reminders access failed", "error", err)
+		}
+	}
+}
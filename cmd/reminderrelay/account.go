@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/njoerd114/reminderrelay/internal/reminders"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// checkAccounts refuses to sync when a Reminders account the state DB was
+// built against was replaced, as when the user signed into another iCloud
+// account: every reminder then has a new identifier, and the first pass
+// would delete every tracked item from Home Assistant. If the user agrees
+// through prompter, the sync state is forgotten instead, so the bootstrap
+// that follows matches the items of both sides afresh; a nil prompter
+// always refuses. The current accounts are recorded for the next start.
+func checkAccounts(ctx context.Context, store *state.Store, remLists *reminders.Backend, prompter *setup.Prompter, logger *slog.Logger) error {
+	current, err := remLists.Accounts(ctx)
+	if err != nil {
+		return fmt.Errorf("listing Reminders accounts: %w", err)
+	}
+	changed, err := store.ChangedAccounts(ctx, current)
+	if err != nil {
+		return fmt.Errorf("reading Reminders accounts: %w", err)
+	}
+	empty, err := store.IsEmpty(ctx)
+	if err != nil {
+		return err
+	}
+	if len(changed) > 0 && !empty {
+		accounts := strings.Join(changed, ", ")
+		logger.Warn("Reminders account changed since the last run; every reminder has a new identifier",
+			"accounts", accounts)
+		if prompter == nil || !prompter.Confirm(fmt.Sprintf("The Reminders account %s changed since the last run. Forget the sync state and bootstrap again?", accounts), false) {
+			return fmt.Errorf("the Reminders account %s changed since the last run, so syncing would delete every tracked item from Home Assistant\n\nRun 'reminderrelay sync-once' in a terminal to bootstrap again", accounts)
+		}
+		n, err := store.Forget(ctx)
+		if err != nil {
+			return fmt.Errorf("forgetting sync state: %w", err)
+		}
+		logger.Info("forgot sync state after Reminders account change", "items", n)
+	}
+	if err := store.RecordAccounts(ctx, current); err != nil {
+		return fmt.Errorf("recording Reminders accounts: %w", err)
+	}
+	return nil
+}
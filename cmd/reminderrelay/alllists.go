@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+)
+
+// expandAllLists implements sync_all_lists: it returns the list mappings of
+// cfg plus one for every other Reminders list in names that is not excluded.
+// Such a list is mapped to the HA todo entity named after it, or to one with
+// the same display name; failing both, a Local To-do list is created for it.
+// A list no free entity can be found or created for is logged and left
+// unmapped.
+func expandAllLists(ctx context.Context, cfg *config.Config, names []string, logger *slog.Logger) (map[string]string, error) {
+	mappings := maps.Clone(cfg.ListMappings)
+	if mappings == nil {
+		mappings = make(map[string]string)
+	}
+	used := make(map[string]string, len(mappings)) // entity → list
+	for list, target := range mappings {
+		used[target] = list
+	}
+
+	entities, err := setup.DiscoverHATodoEntities(ctx, cfg.HAURL, cfg.HAToken)
+	if err != nil {
+		return nil, fmt.Errorf("sync_all_lists: discovering HA todo entities: %w", err)
+	}
+	byID := make(map[string]bool, len(entities))
+	byName := make(map[string]string, len(entities))
+	for _, e := range entities {
+		byID[e.EntityID] = true
+		if _, dup := byName[e.FriendlyName]; !dup {
+			byName[e.FriendlyName] = e.EntityID
+		}
+	}
+
+	for _, list := range names {
+		if _, mapped := mappings[list]; mapped || slices.Contains(cfg.ExcludeLists, list) {
+			continue
+		}
+		entityID := setup.EntityIDFor(list)
+		switch {
+		case byID[entityID]:
+		case byName[list] != "":
+			entityID = byName[list]
+		default:
+			created, err := setup.CreateLocalTodo(ctx, cfg.HAURL, cfg.HAToken, list)
+			if err != nil {
+				logger.Warn("sync_all_lists: could not create a Home Assistant list; not syncing it",
+					"list", list, "error", err)
+				continue
+			}
+			logger.Info("sync_all_lists: created Home Assistant list", "list", list, "entity_id", created)
+			entityID = created
+		}
+		if other, taken := used[entityID]; taken {
+			logger.Warn("sync_all_lists: entity already synced with another list; not syncing this one",
+				"list", list, "entity_id", entityID, "other_list", other)
+			continue
+		}
+		used[entityID] = list
+		mappings[list] = entityID
+	}
+	return mappings, nil
+}
+
+// allListsMapped returns the list mappings of cfg with every Reminders list
+// in names that sync_all_lists covers counted as mapped, without looking up
+// or creating entities. Used to tell which state rows are still in use.
+func allListsMapped(cfg *config.Config, names []string) map[string]string {
+	mappings := maps.Clone(cfg.ListMappings)
+	if mappings == nil {
+		mappings = make(map[string]string)
+	}
+	for _, list := range names {
+		if _, mapped := mappings[list]; !mapped && !slices.Contains(cfg.ExcludeLists, list) {
+			mappings[list] = setup.EntityIDFor(list)
+		}
+	}
+	return mappings
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+
+	"github.com/njoerd114/reminderrelay/internal/caldav"
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/homeassistant"
+	"github.com/njoerd114/reminderrelay/internal/reminders"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// newHAAdapter creates the Home Assistant adapter for cfg, with its time zone,
+// fallback URLs, request timeout, rate limit, TLS settings, and description
+// template and any further opts.
+func newHAAdapter(cfg *config.Config, logger *slog.Logger, opts ...homeassistant.AdapterOption) (*homeassistant.Adapter, error) {
+	opts = append([]homeassistant.AdapterOption{
+		homeassistant.WithTimeZone(cfg.Location()),
+		homeassistant.WithRequestTimeout(cfg.HATimeout),
+		homeassistant.WithFallbackURLs(cfg.HAFallbackURLs...),
+		homeassistant.WithRateLimit(cfg.HARateLimit),
+	}, opts...)
+	if t := cfg.HATLS; t != nil {
+		tlsConfig, err := homeassistant.LoadTLSConfig(homeassistant.TLSFiles{
+			CAFile:             t.CAFile,
+			CertFile:           t.CertFile,
+			KeyFile:            t.KeyFile,
+			InsecureSkipVerify: t.InsecureSkipVerify,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ha_tls: %w", err)
+		}
+		opts = append(opts, homeassistant.WithTLS(tlsConfig))
+	}
+	if cfg.HADescriptionTemplate != "" {
+		tmpl, err := homeassistant.ParseDescriptionTemplate(cfg.HADescriptionTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("ha_description_template: %w", err)
+		}
+		opts = append(opts, homeassistant.WithDescriptionTemplate(tmpl))
+	}
+	return homeassistant.NewAdapter(cfg.HAURL, cfg.HAToken, logger, opts...)
+}
+
+// newRemindersAdapter creates the Reminders adapter for cfg, with its time
+// zone, the accounts of its lists and any further opts.
+func newRemindersAdapter(cfg *config.Config, logger *slog.Logger, opts ...reminders.AdapterOption) (*reminders.Adapter, error) {
+	opts = append([]reminders.AdapterOption{
+		reminders.WithTimeZone(cfg.Location()),
+		reminders.WithSources(cfg.RemindersSources),
+	}, opts...)
+	return reminders.NewAdapter(logger, opts...)
+}
+
+// haEntities returns the HA todo entities mappings target, sorted and each
+// once. Targets served by another backend are left out.
+func haEntities(mappings map[string]string) []string {
+	var entities []string
+	for _, target := range mappings {
+		if name, _ := syncp.SplitTarget(target); name == "" {
+			entities = append(entities, target)
+		}
+	}
+	sort.Strings(entities)
+	return slices.Compact(entities)
+}
+
+// mappingQuirks returns the quirks of cfg's list mappings keyed by target, so
+// they follow a mapping whose Reminders list is renamed.
+func mappingQuirks(cfg *config.Config) (map[string]syncp.Quirks, error) {
+	quirks := make(map[string]syncp.Quirks, len(cfg.Quirks))
+	for list, preset := range cfg.Quirks {
+		q, err := syncp.QuirksPreset(preset)
+		if err != nil {
+			return nil, fmt.Errorf("quirks[%q]: %w", list, err)
+		}
+		quirks[cfg.ListMappings[list]] = q
+	}
+	return quirks, nil
+}
+
+// syncTargets returns the backends list mappings can target: Home Assistant
+// for plain entity IDs, and every CalDAV server in cfg under its name, with
+// the alert policies and list prefixes of cfg's list mappings.
+func syncTargets(cfg *config.Config, ha *homeassistant.Adapter, logger *slog.Logger) (*syncp.Registry, error) {
+	targets := syncp.NewRegistry(withChaos("ha", homeassistant.NewBackend(ha), logger))
+
+	names := make([]string, 0, len(cfg.CalDAV))
+	for name := range cfg.CalDAV {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		server := cfg.CalDAV[name]
+		client, err := caldav.NewClient(server.URL, server.Username, server.Password, logger.With("caldav", name))
+		if err != nil {
+			return nil, err
+		}
+		if err := targets.Register(name, withChaos("caldav."+name, caldav.NewBackend(client, caldav.WithTimeZone(cfg.Location())), logger)); err != nil {
+			return nil, fmt.Errorf("caldav.%s: %w", name, err)
+		}
+	}
+	for list, policy := range cfg.Alerts {
+		if err := targets.SetAlertPolicy(cfg.ListMappings[list], syncp.AlertPolicy(policy)); err != nil {
+			return nil, fmt.Errorf("alerts[%q]: %w", list, err)
+		}
+	}
+	if len(cfg.ListPrefixes) > 0 {
+		// Every list of a shared target is registered, the one without
+		// a tag as its default list.
+		shared := make(map[string]bool, len(cfg.ListPrefixes))
+		for list := range cfg.ListPrefixes {
+			shared[cfg.ListMappings[list]] = true
+		}
+		for list, target := range cfg.ListMappings {
+			if !shared[target] {
+				continue
+			}
+			if err := targets.SetListPrefix(target, list, cfg.ListPrefixes[list]); err != nil {
+				return nil, fmt.Errorf("list_prefixes[%q]: %w", list, err)
+			}
+		}
+	}
+	return targets, nil
+}
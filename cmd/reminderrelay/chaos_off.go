@@ -0,0 +1,19 @@
+//go:build !chaos
+
+package main
+
+import (
+	"log/slog"
+
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// withChaos returns b unchanged; build with -tags chaos to inject faults.
+func withChaos(_ string, b syncp.TaskBackend, _ *slog.Logger) syncp.TaskBackend {
+	return b
+}
+
+// withChaosConn returns c unchanged; build with -tags chaos to inject faults.
+func withChaosConn(c syncp.HAConnector, _ *slog.Logger) syncp.HAConnector {
+	return c
+}
@@ -0,0 +1,51 @@
+//go:build chaos
+
+package main
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/chaos"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// defaultChaosRate is the fault probability when REMINDERRELAY_CHAOS_RATE is
+// unset.
+const defaultChaosRate = 0.1
+
+var (
+	injectorOnce sync.Once
+	injector     *chaos.Injector
+)
+
+// chaosInjector returns the process-wide fault injector, configured from
+// REMINDERRELAY_CHAOS_RATE and REMINDERRELAY_CHAOS_SEED.
+func chaosInjector(logger *slog.Logger) *chaos.Injector {
+	injectorOnce.Do(func() {
+		rate := defaultChaosRate
+		if v, err := strconv.ParseFloat(os.Getenv("REMINDERRELAY_CHAOS_RATE"), 64); err == nil {
+			rate = v
+		}
+		seed := uint64(time.Now().UnixNano())
+		if v, err := strconv.ParseUint(os.Getenv("REMINDERRELAY_CHAOS_SEED"), 10, 64); err == nil {
+			seed = v
+		}
+		logger.Warn("chaos build: injecting faults into every backend", "rate", rate, "seed", seed)
+		injector = chaos.NewInjector(rate, seed, logger.With("chaos", true))
+	})
+	return injector
+}
+
+// withChaos wraps b so its calls fail at random.
+func withChaos(name string, b syncp.TaskBackend, logger *slog.Logger) syncp.TaskBackend {
+	return chaos.WrapBackend(name, b, chaosInjector(logger))
+}
+
+// withChaosConn wraps c so it drops WebSocket events at random.
+func withChaosConn(c syncp.HAConnector, logger *slog.Logger) syncp.HAConnector {
+	return chaos.WrapConnector(c, chaosInjector(logger))
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+)
+
+// runConfig dispatches the "config get" and "config set" subcommands.
+func runConfig(args []string) error {
+	const usage = "usage: reminderrelay config get [--config <path>] <key> | config set [--config <path>] <key> <value>"
+	if len(args) == 0 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	defaultCfg, _ := config.DefaultPath()
+	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "get":
+		if fs.NArg() != 1 {
+			return fmt.Errorf("%s", usage)
+		}
+		val, err := config.GetValue(*cfgPath, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		fmt.Println(val)
+		return nil
+
+	case "set":
+		if fs.NArg() != 2 {
+			return fmt.Errorf("%s", usage)
+		}
+		if err := config.SetValue(*cfgPath, fs.Arg(0), fs.Arg(1)); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "✓ %s updated in %s\n", fs.Arg(0), *cfgPath)
+		return restartDaemonIfLoaded()
+	}
+
+	return fmt.Errorf("unknown config command %q — %s", args[0], usage)
+}
+
+// restartDaemonIfLoaded reloads the launchd job so a running daemon picks up
+// config changes. It does nothing when the daemon is not installed.
+func restartDaemonIfLoaded() error {
+	if !setup.IsDaemonLoaded() {
+		return nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("resolving home directory: %w", err)
+	}
+	if err := setup.LoadDaemon(homeDir); err != nil {
+		return fmt.Errorf("restarting daemon: %w", err)
+	}
+	fmt.Fprintln(os.Stderr, "✓ Daemon restarted")
+	return nil
+}
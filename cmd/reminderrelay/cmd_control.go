@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/control"
+	"github.com/njoerd114/reminderrelay/internal/render"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// controlTimeout bounds control requests other than a full sync pass.
+const controlTimeout = 5 * time.Second
+
+// runPause stops the running daemon's syncing until resume or restart.
+func runPause(args []string) error {
+	fs := flag.NewFlagSet("pause", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	client, err := controlClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	if err := client.Pause(ctx); err != nil {
+		return controlError(err)
+	}
+	fmt.Println("Sync paused. Run 'reminderrelay resume' to continue (a daemon restart resumes too).")
+	return nil
+}
+
+// runResume resumes a paused daemon.
+func runResume(args []string) error {
+	fs := flag.NewFlagSet("resume", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	client, err := controlClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	if err := client.Resume(ctx); err != nil {
+		return controlError(err)
+	}
+	fmt.Println("Sync resumed.")
+	return nil
+}
+
+// runConflicts lists the conflicts the running daemon resolved recently.
+func runConflicts(args []string) error {
+	fs := flag.NewFlagSet("conflicts", flag.ExitOnError)
+	noColor := fs.Bool("no-color", false, "disable coloured output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	client, err := controlClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	conflicts, err := client.Conflicts(ctx)
+	if err != nil {
+		return controlError(err)
+	}
+	if len(conflicts) == 0 {
+		fmt.Println("No conflicts since the daemon started.")
+		return nil
+	}
+
+	out := render.New(os.Stdout, *noColor)
+	rows := make([][]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		fields := "whole item"
+		if len(c.Fields) > 0 {
+			fields = strings.Join(c.Fields, ", ")
+		}
+		winner := "Reminders"
+		if c.Winner == "ha" {
+			winner = "Home Assistant"
+		}
+		rows = append(rows, []string{
+			c.At.Local().Format("2006-01-02 15:04"),
+			c.List,
+			c.Title,
+			fields,
+			winner,
+		})
+	}
+	out.Table([]string{"WHEN", "LIST", "ITEM", "FIELDS", "WON"}, rows)
+	return nil
+}
+
+// syncViaDaemon asks the running daemon for a pass and prints its result,
+// in JSON when output is "json". A non-empty list or entity limits the pass
+// to the mappings they select.
+func syncViaDaemon(list, entity, output string) error {
+	client, err := controlClient()
+	if err != nil {
+		return err
+	}
+	var res *control.SyncResult
+	if list != "" || entity != "" {
+		res, err = client.SyncSelected(context.Background(), list, entity)
+	} else {
+		res, err = client.Sync(context.Background())
+	}
+	if err != nil {
+		return controlError(err)
+	}
+	s := res.Stats
+	var passErr error
+	if res.Error != "" {
+		passErr = fmt.Errorf("sync pass: %s", res.Error)
+	}
+	if output == "json" {
+		if err := printSyncResult(s, passErr); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("Sync complete: %d created, %d updated, %d deleted, %d conflicts, %d errors\n",
+			s.Created, s.Updated, s.Deleted, s.Conflicts, s.Errors)
+	}
+	return passError(passErr, s.Errors > 0 || len(s.ListErrors) > 0)
+}
+
+// printSyncResult writes the result of a sync-once pass to stdout as one
+// JSON object, with the pass's error, if any.
+func printSyncResult(stats control.Stats, passErr error) error {
+	res := control.SyncResult{Stats: stats}
+	if passErr != nil {
+		res.Error = passErr.Error()
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(res); err != nil {
+		return fmt.Errorf("writing result: %w", err)
+	}
+	return nil
+}
+
+// passError returns err, the error of a sync-once pass, marked for
+// [exitItemErrors] when the pass ran but some items or lists failed.
+func passError(err error, itemErrors bool) error {
+	if err == nil || !itemErrors || state.IsCorrupt(err) {
+		return err
+	}
+	return &exitError{code: exitItemErrors, err: err}
+}
+
+// liveStatus returns the running daemon's status, or nil when none answers.
+func liveStatus() *control.Status {
+	client, err := controlClient()
+	if err != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	st, err := client.Status(ctx)
+	if err != nil {
+		return nil
+	}
+	return st
+}
+
+// controlClient returns a client for the daemon's default control socket.
+func controlClient() (*control.Client, error) {
+	path, err := control.DefaultSocketPath()
+	if err != nil {
+		return nil, fmt.Errorf("resolving control socket path: %w", err)
+	}
+	return control.NewClient(path), nil
+}
+
+// controlError adds a hint to errors caused by the daemon not running.
+func controlError(err error) error {
+	if errors.Is(err, control.ErrNotRunning) {
+		return fmt.Errorf("%w — start it with 'reminderrelay daemon' or 'reminderrelay setup'", err)
+	}
+	return err
+}
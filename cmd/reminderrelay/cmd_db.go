@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"syscall"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/render"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// runDB dispatches the "db vacuum", "db analyze" and "db integrity-check"
+// maintenance subcommands and reports the size of the state DB afterwards.
+// Only vacuum, which needs the database to itself, stops the daemon.
+func runDB(args []string) error {
+	const usage = "usage: reminderrelay db vacuum|analyze|integrity-check [--config <path>] [--no-color]"
+	if len(args) == 0 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	fs := flag.NewFlagSet("db", flag.ExitOnError)
+	defaultCfg, _ := config.DefaultPath()
+	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
+	noColor := fs.Bool("no-color", false, "disable coloured output")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config from %q: %w", *cfgPath, err)
+	}
+	dbPath, err := stateDBPath(cfg)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("state DB: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	out := render.New(os.Stdout, *noColor)
+
+	switch args[0] {
+	case "vacuum":
+		return vacuumStateDB(ctx, out, dbPath)
+
+	case "analyze":
+		return withStateDB(dbPath, func(store *state.Store) error {
+			if err := store.Analyze(ctx); err != nil {
+				return err
+			}
+			fmt.Println("✓ Query planner statistics refreshed")
+			return printDBStats(ctx, out, store, dbPath)
+		})
+
+	case "integrity-check":
+		return withStateDB(dbPath, func(store *state.Store) error {
+			if err := store.IntegrityCheck(ctx); err != nil {
+				if state.IsCorrupt(err) {
+					fmt.Println(out.Style(render.Bad, "✗ "+err.Error()))
+					return errors.New("state DB failed the integrity check; run 'reminderrelay restore' to restore a backup")
+				}
+				return err
+			}
+			fmt.Println(out.Style(render.Good, "✓ No problems found"))
+			return printDBStats(ctx, out, store, dbPath)
+		})
+	}
+
+	return fmt.Errorf("unknown db command %q — %s", args[0], usage)
+}
+
+// vacuumStateDB vacuums the state DB at dbPath and removes stale WAL files
+// next to it, stopping the daemon meanwhile.
+func vacuumStateDB(ctx context.Context, out *render.Printer, dbPath string) error {
+	homeDir, _ := os.UserHomeDir()
+	wasLoaded := setup.IsDaemonLoaded()
+	if wasLoaded {
+		if err := setup.UnloadDaemon(homeDir); err != nil {
+			return fmt.Errorf("stopping daemon: %w", err)
+		}
+	}
+
+	vacuumErr := func() error {
+		// The daemon may also have been started by hand.
+		release, err := state.Lock(dbPath)
+		var locked *state.LockedError
+		if errors.As(err, &locked) {
+			return fmt.Errorf("%w; stop it before vacuuming", locked)
+		}
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		return withStateDB(dbPath, func(store *state.Store) error {
+			before, err := store.Stats(ctx)
+			if err != nil {
+				return err
+			}
+			if err := store.Vacuum(ctx); err != nil {
+				return err
+			}
+			after, err := store.Stats(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✓ Vacuumed: %s → %s\n", humanSize(before.Size+before.WALSize), humanSize(after.Size+after.WALSize))
+
+			removed, err := state.PruneStaleWAL(dbPath)
+			for _, path := range removed {
+				fmt.Printf("✓ Removed stale %s\n", path)
+			}
+			if err != nil {
+				return err
+			}
+			return printDBStats(ctx, out, store, dbPath)
+		})
+	}()
+
+	if wasLoaded {
+		if err := setup.LoadDaemon(homeDir); err != nil {
+			return fmt.Errorf("restarting daemon: %w", err)
+		}
+		fmt.Println("✓ Daemon restarted")
+	}
+	return vacuumErr
+}
+
+// withStateDB opens the state DB at dbPath, passes it to fn, and closes it.
+func withStateDB(dbPath string, fn func(*state.Store) error) error {
+	store, err := state.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("opening state DB at %q: %w", dbPath, err)
+	}
+	defer func() { _ = store.Close() }()
+	return fn(store)
+}
+
+// printDBStats prints the size of the state DB and its rows per table and
+// per list.
+func printDBStats(ctx context.Context, out *render.Printer, store *state.Store, dbPath string) error {
+	st, err := store.Stats(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	out.Heading("State DB")
+	out.Fields([][2]string{
+		{"Path", dbPath},
+		{"Size", humanSize(st.Size)},
+		{"Unused", humanSize(st.Free) + " (reclaimed by 'reminderrelay db vacuum')"},
+		{"WAL", humanSize(st.WALSize)},
+	})
+
+	fmt.Println()
+	rows := make([][]string, 0, len(st.TableRows))
+	for _, table := range slices.Sorted(maps.Keys(st.TableRows)) {
+		rows = append(rows, []string{table, strconv.Itoa(st.TableRows[table])})
+	}
+	out.Table([]string{"TABLE", "ROWS"}, rows)
+
+	if len(st.ListItems) > 0 {
+		fmt.Println()
+		rows = rows[:0]
+		for _, list := range slices.Sorted(maps.Keys(st.ListItems)) {
+			rows = append(rows, []string{list, strconv.Itoa(st.ListItems[list])})
+		}
+		out.Table([]string{"LIST", "ITEMS"}, rows)
+	}
+	return nil
+}
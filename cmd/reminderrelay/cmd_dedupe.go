@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/reminders"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+	"github.com/njoerd114/reminderrelay/internal/state"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// runDedupe walks through the titles several items of a mapped list share and
+// merges or renumbers them on both sides, as the user chooses. The daemon is
+// stopped meanwhile so it cannot sync half-fixed items.
+func runDedupe(args []string) error {
+	const usage = "usage: reminderrelay dedupe [--config <path>] [--list <list>]"
+
+	fs := flag.NewFlagSet("dedupe", flag.ExitOnError)
+	defaultCfg, _ := config.DefaultPath()
+	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
+	only := fs.String("list", "", "only check this Reminders list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config from %q: %w", *cfgPath, err)
+	}
+	store, err := openStateStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	remAdapter, err := newRemindersAdapter(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initialising Reminders client: %w", err)
+	}
+	haAdapter, err := newHAAdapter(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initialising Home Assistant client: %w", err)
+	}
+	remLists := reminders.NewBackend(remAdapter)
+
+	mappings, err := activeMappings(ctx, cfg, store, remLists, *only, logger)
+	if err != nil {
+		return err
+	}
+
+	targets, err := syncTargets(cfg, haAdapter, logger)
+	if err != nil {
+		return err
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	wasLoaded := setup.IsDaemonLoaded()
+	if wasLoaded {
+		if err := setup.UnloadDaemon(homeDir); err != nil {
+			return fmt.Errorf("stopping daemon: %w", err)
+		}
+	}
+
+	dedupe := syncp.NewDedupe(remLists, targets, store, logger, os.Stdin, os.Stdout)
+	res, runErr := dedupe.Run(ctx, mappings)
+	switch {
+	case runErr != nil:
+		fmt.Printf("Stopped after %d merged and %d renamed title(s).\n", res.Merged, res.Renamed)
+	case res.Found == 0:
+		fmt.Println("No duplicate titles found.")
+	default:
+		fmt.Printf("\n✓ %d merged, %d renamed, %d skipped\n", res.Merged, res.Renamed, res.Found-res.Merged-res.Renamed)
+	}
+
+	if wasLoaded {
+		if err := setup.LoadDaemon(homeDir); err != nil {
+			return fmt.Errorf("restarting daemon: %w", err)
+		}
+		fmt.Println("✓ Daemon restarted")
+	}
+	return runErr
+}
+
+// activeMappings returns the list mappings the daemon syncs: those of cfg,
+// with sync_all_lists expanded, limited to the list only unless it is empty.
+// Lists in shadow mode are left out with a note: they are not synced yet,
+// and a command must not write to one until it is promoted.
+func activeMappings(ctx context.Context, cfg *config.Config, store *state.Store, remLists *reminders.Backend,
+	only string, logger *slog.Logger,
+) (map[string]string, error) {
+	mappings := maps.Clone(cfg.ListMappings)
+	if cfg.SyncAllLists {
+		names, err := remLists.Lists(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("sync_all_lists: %w", err)
+		}
+		if mappings, err = expandAllLists(ctx, cfg, names, logger); err != nil {
+			return nil, err
+		}
+	}
+	if only != "" {
+		target, ok := mappings[only]
+		if !ok {
+			return nil, fmt.Errorf("list %q is not mapped", only)
+		}
+		mappings = map[string]string{only: target}
+	}
+
+	for list := range mappings {
+		sl, err := store.GetShadowList(ctx, list)
+		if err != nil {
+			return nil, err
+		}
+		shadow := (cfg.Shadow != nil && slices.Contains(cfg.Shadow.Lists, list)) || (sl != nil && sl.Held)
+		if shadow && (sl == nil || !sl.Promoted) {
+			fmt.Printf("Skipping %q: it is in shadow mode.\n", list)
+			delete(mappings, list)
+		}
+	}
+	return mappings, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/reminders"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// runPushItem overwrites Home Assistant's version of an item with Reminders'.
+func runPushItem(args []string) error {
+	return runForceItem("push-item", args, syncp.ToHA)
+}
+
+// runPullItem overwrites Reminders' version of an item with Home Assistant's.
+func runPullItem(args []string) error {
+	return runForceItem("pull-item", args, syncp.ToReminders)
+}
+
+// runForceItem copies one synced item to the side dir names, whatever the
+// automatic resolution would do, and records it as synced. The daemon is
+// stopped meanwhile so a pass cannot interleave with the write.
+func runForceItem(name string, args []string, dir syncp.Direction) error {
+	usage := "usage: reminderrelay " + name + " [--config <path>] --list <list> --title <title>"
+
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	defaultCfg, _ := config.DefaultPath()
+	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
+	listName := fs.String("list", "", "Reminders list of the item")
+	title := fs.String("title", "", "title of the item")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *listName == "" || *title == "" {
+		return fmt.Errorf("%s", usage)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config from %q: %w", *cfgPath, err)
+	}
+	store, err := openStateStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	remAdapter, err := newRemindersAdapter(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initialising Reminders client: %w", err)
+	}
+	haAdapter, err := newHAAdapter(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initialising Home Assistant client: %w", err)
+	}
+	remLists := reminders.NewBackend(remAdapter)
+
+	mappings, err := activeMappings(ctx, cfg, store, remLists, *listName, logger)
+	if err != nil {
+		return err
+	}
+	target, ok := mappings[*listName]
+	if !ok {
+		return fmt.Errorf("list %q is in shadow mode; promote it first", *listName)
+	}
+	if err := haAdapter.ProbeCapabilities(ctx, haEntities(mappings)); err != nil {
+		logger.Warn("could not read todo features of every HA entity, assuming full support", "error", err)
+	}
+	targets, err := syncTargets(cfg, haAdapter, logger)
+	if err != nil {
+		return err
+	}
+	quirks, err := mappingQuirks(cfg)
+	if err != nil {
+		return err
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	wasLoaded := setup.IsDaemonLoaded()
+	if wasLoaded {
+		if err := setup.UnloadDaemon(homeDir); err != nil {
+			return fmt.Errorf("stopping daemon: %w", err)
+		}
+	}
+
+	reconciler := syncp.NewReconciler(remLists, targets, store, logger, syncp.WithQuirks(quirks))
+	n, forceErr := reconciler.ForceItem(ctx, *listName, target, *title, dir)
+	if forceErr == nil {
+		to := "Home Assistant"
+		if dir == syncp.ToReminders {
+			to = "Reminders"
+		}
+		fmt.Printf("✓ Copied %q to %s\n", *title, to)
+		if n > 1 {
+			fmt.Printf("Note: %d items share this title; all were copied.\n", n)
+		}
+	}
+
+	if wasLoaded {
+		if err := setup.LoadDaemon(homeDir); err != nil {
+			return fmt.Errorf("restarting daemon: %w", err)
+		}
+		fmt.Println("✓ Daemon restarted")
+	}
+	return forceErr
+}
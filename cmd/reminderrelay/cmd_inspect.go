@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/reminders"
+	"github.com/njoerd114/reminderrelay/internal/render"
+	"github.com/njoerd114/reminderrelay/internal/state"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// actionDescriptions explains the actions [syncp.Inspection.Action] names.
+var actionDescriptions = map[string]string{
+	"none":                  "nothing, the item is in sync",
+	"create_in_ha":          "create it in Home Assistant",
+	"create_in_reminders":   "create it in Reminders",
+	"update_ha":             "copy the Reminders version to Home Assistant",
+	"update_reminders":      "copy the Home Assistant version to Reminders",
+	"delete_from_ha":        "delete it from Home Assistant, as it was deleted in Reminders",
+	"delete_from_reminders": "delete it from Reminders, as it was deleted in Home Assistant",
+	"merge":                 "merge the edits of both sides field by field",
+	"restore_ha":            "re-create it in Home Assistant, as it is pinned to Reminders",
+	"restore_reminders":     "re-create it in Reminders, as it is pinned to Home Assistant",
+}
+
+// runInspect prints one item as Reminders, Home Assistant, and the state DB
+// see it, side by side, with what the next pass would do about it. It
+// changes nothing, so the daemon may keep running.
+func runInspect(args []string) error {
+	const usage = "usage: reminderrelay inspect [--config <path>] [--list <list>] [--no-color] <title|uid>"
+
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	defaultCfg, _ := config.DefaultPath()
+	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
+	only := fs.String("list", "", "only look in this Reminders list")
+	noColor := fs.Bool("no-color", false, "disable coloured output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%s", usage)
+	}
+	query := fs.Arg(0)
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config from %q: %w", *cfgPath, err)
+	}
+	store, err := openStateStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	remAdapter, err := newRemindersAdapter(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initialising Reminders client: %w", err)
+	}
+	haAdapter, err := newHAAdapter(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initialising Home Assistant client: %w", err)
+	}
+	remLists := reminders.NewBackend(remAdapter)
+
+	mappings, err := activeMappings(ctx, cfg, store, remLists, *only, logger)
+	if err != nil {
+		return err
+	}
+	if err := haAdapter.ProbeCapabilities(ctx, haEntities(mappings)); err != nil {
+		logger.Warn("could not read todo features of every HA entity, assuming full support", "error", err)
+	}
+	targets, err := syncTargets(cfg, haAdapter, logger)
+	if err != nil {
+		return err
+	}
+	quirks, err := mappingQuirks(cfg)
+	if err != nil {
+		return err
+	}
+
+	reconciler := syncp.NewReconciler(remLists, targets, store, logger,
+		syncp.WithQuirks(quirks), syncp.WithNormalization(titleNormalization(cfg)))
+	found, err := reconciler.Inspect(ctx, mappings, query)
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return fmt.Errorf("no item titled %q or with that UID in %d list(s)", query, len(mappings))
+	}
+
+	out := render.New(os.Stdout, *noColor)
+	for i, in := range found {
+		if i > 0 {
+			fmt.Println()
+		}
+		out.Heading(fmt.Sprintf("%s → %s", in.ListName, in.Target))
+		out.Table([]string{"FIELD", "REMINDERS", "HOME ASSISTANT", "STATE DB"}, inspectRows(in))
+		next := actionDescriptions[in.Action]
+		if next == "" {
+			next = in.Action
+		}
+		style := render.Warn
+		if in.Action == "none" {
+			style = render.Good
+		}
+		fmt.Printf("Next pass: %s (%s)\n", out.Style(style, next), in.Action)
+	}
+	return nil
+}
+
+// inspectRows returns the field-by-field rows of in. The state DB column
+// shows the content both sides agreed on at the last sync.
+func inspectRows(in syncp.Inspection) [][]string {
+	rem, ha, si := in.Reminders, in.HA, in.State
+	var base *model.Item
+	if si != nil {
+		base = si.Base
+	}
+	field := func(name string, get func(*model.Item) string, stateValue func(*state.Item) string) []string {
+		row := []string{name, itemValue(rem, get), itemValue(ha, get), "—"}
+		switch {
+		case si == nil:
+		case stateValue != nil:
+			row[3] = stateValue(si)
+		case base != nil:
+			row[3] = get(base)
+		}
+		return row
+	}
+	return [][]string{
+		field("uid", func(it *model.Item) string { return it.UID },
+			func(si *state.Item) string { return si.RemindersUID + " ↔ " + si.HAUID }),
+		field("title", func(it *model.Item) string { return it.Title },
+			func(si *state.Item) string { return si.Title }),
+		field("description", func(it *model.Item) string { return strconv.Quote(it.Description) }, nil),
+		field("due", func(it *model.Item) string { return formatDue(it.DueDate) }, nil),
+		field("priority", func(it *model.Item) string { return it.Priority.String() }, nil),
+		field("completed", func(it *model.Item) string { return strconv.FormatBool(it.Completed) }, nil),
+		field("modified", func(it *model.Item) string { return formatTime(it.ModifiedAt) },
+			func(si *state.Item) string { return "synced " + formatTime(si.LastSyncedAt) }),
+		field("hash", func(it *model.Item) string { return shortHash(it.ContentHash()) },
+			func(si *state.Item) string { return shortHash(si.LastSyncHash) }),
+		field("pin", func(*model.Item) string { return "" },
+			func(si *state.Item) string { return string(si.Pin) }),
+	}
+}
+
+// itemValue returns get(it), or a dash when the side has no such item.
+func itemValue(it *model.Item, get func(*model.Item) string) string {
+	if it == nil {
+		return "—"
+	}
+	return get(it)
+}
+
+// formatDue formats a due date, with its time unless it is midnight.
+func formatDue(due *time.Time) string {
+	if due == nil {
+		return ""
+	}
+	if h, m, s := due.Clock(); h == 0 && m == 0 && s == 0 {
+		return due.Format("2006-01-02")
+	}
+	return due.Local().Format("2006-01-02 15:04")
+}
+
+// formatTime formats t in local time, or "unknown" when it is zero.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// shortHash returns the first 12 characters of a content hash.
+func shortHash(hash string) string {
+	return hash[:min(12, len(hash))]
+}
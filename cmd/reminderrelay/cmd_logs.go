@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/logfile"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+)
+
+// launchdLogs are the files launchd appends the daemon's stdout and stderr
+// to; see plist.tmpl.
+var launchdLogs = []string{"output.log", "errors.log"}
+
+// runLogs dispatches the "logs prune" subcommand, which removes rotated log
+// files beyond those kept and empties launchd's files once they outgrow the
+// rotation size, for example those of an install that predates rotation.
+func runLogs(args []string) error {
+	const usage = "usage: reminderrelay logs prune [--config <path>] [--keep <n>]"
+	if len(args) == 0 || args[0] != "prune" {
+		return fmt.Errorf("%s", usage)
+	}
+
+	fs := flag.NewFlagSet("logs", flag.ExitOnError)
+	defaultCfg, _ := config.DefaultPath()
+	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
+	keep := fs.Int("keep", -1, "rotated log files to keep (default: log_rotation.keep in config.yaml)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config from %q: %w", *cfgPath, err)
+	}
+	opts := logRotation(cfg)
+	if *keep >= 0 {
+		opts.Keep = *keep
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("resolving home directory: %w", err)
+	}
+
+	// Sizes are taken first, as the files are gone once pruned.
+	logFile := setup.LogFile(homeDir)
+	rotated, err := logfile.Rotated(logFile)
+	if err != nil {
+		return err
+	}
+	sizes := make(map[string]int64, len(rotated))
+	for _, path := range rotated {
+		if info, err := os.Stat(path); err == nil {
+			sizes[path] = info.Size()
+		}
+	}
+	removed, err := logfile.Prune(logFile, opts.Keep)
+	var freed int64
+	for _, path := range removed {
+		freed += sizes[path]
+		fmt.Printf("  ✓ Removed %s\n", filepath.Base(path))
+	}
+	if err != nil {
+		return err
+	}
+
+	// launchd holds these open for appending, so emptying them in place is
+	// safe while the daemon runs.
+	for _, name := range launchdLogs {
+		path := filepath.Join(setup.LogDir(homeDir), name)
+		info, err := os.Stat(path)
+		if err != nil || info.Size() <= opts.MaxSize {
+			continue
+		}
+		if err := os.Truncate(path, 0); err != nil {
+			return fmt.Errorf("emptying %s: %w", name, err)
+		}
+		freed += info.Size()
+		fmt.Printf("  ✓ Emptied %s\n", name)
+	}
+
+	if freed == 0 {
+		fmt.Println("Nothing to prune.")
+		return nil
+	}
+	fmt.Printf("Freed %s.\n", humanSize(freed))
+	return nil
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/reminders"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+	"github.com/njoerd114/reminderrelay/internal/state"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// runAddMapping adds a single list ↔ entity mapping to an existing config and
+// runs a targeted bootstrap so items already present on both sides are linked
+// by title rather than duplicated. Without positional arguments the user picks
+// the pair from discovered lists and entities.
+func runAddMapping(args []string) error {
+	const usage = "usage: reminderrelay add-mapping [--config <path>] [--plan-out <file>] [<list> <entity_id>]"
+
+	fs := flag.NewFlagSet("add-mapping", flag.ExitOnError)
+	defaultCfg, _ := config.DefaultPath()
+	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
+	planOut := fs.String("plan-out", "", "write the bootstrap match plan to this .json or .md file before confirming")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 && fs.NArg() != 2 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config from %q: %w", *cfgPath, err)
+	}
+
+	listName, entityID, source := fs.Arg(0), fs.Arg(1), ""
+	if fs.NArg() == 0 {
+		wiz := setup.NewWizard(os.Stdin, os.Stdout, logger)
+		if listName, source, entityID, err = wiz.PickMapping(ctx, cfg.HAURL, cfg.HAToken, cfg.ListMappings); err != nil {
+			return err
+		}
+	}
+
+	if err := config.AddListMapping(*cfgPath, listName, entityID); err != nil {
+		return err
+	}
+	if source != "" {
+		if err := config.SetRemindersSource(*cfgPath, listName, source); err != nil {
+			_ = config.RemoveListMapping(*cfgPath, listName)
+			return err
+		}
+		if cfg.RemindersSources == nil {
+			cfg.RemindersSources = make(map[string]string)
+		}
+		cfg.RemindersSources[listName] = source
+	}
+
+	ran, err := bootstrapMapping(ctx, cfg, listName, entityID, *planOut, logger)
+	if err != nil || !ran {
+		// Roll back so the daemon never syncs an unlinked list and duplicates
+		// every item already present on both sides.
+		if rbErr := config.RemoveListMapping(*cfgPath, listName); rbErr != nil {
+			logger.Error("rolling back list mapping", "list", listName, "error", rbErr)
+		}
+		if err != nil {
+			return fmt.Errorf("bootstrapping %q: %w", listName, err)
+		}
+		fmt.Printf("Mapping %q not added.\n", listName)
+		return nil
+	}
+
+	fmt.Printf("✓ Mapped %q → %s\n", listName, entityID)
+	return restartDaemonIfLoaded()
+}
+
+// bootstrapMapping runs the interactive title-matching bootstrap for a single
+// new mapping, writing the match plan to planOut unless it is empty. It
+// reports whether the user confirmed it.
+func bootstrapMapping(ctx context.Context, cfg *config.Config, listName, entityID, planOut string, logger *slog.Logger) (bool, error) {
+	dbPath, err := stateDBPath(cfg)
+	if err != nil {
+		return false, err
+	}
+	store, err := state.Open(dbPath)
+	if err != nil {
+		return false, fmt.Errorf("opening state DB at %q: %w", dbPath, err)
+	}
+	defer func() { _ = store.Close() }()
+
+	remAdapter, err := newRemindersAdapter(cfg, logger)
+	if err != nil {
+		return false, fmt.Errorf("initialising Reminders client: %w", err)
+	}
+	haAdapter, err := newHAAdapter(cfg, logger)
+	if err != nil {
+		return false, fmt.Errorf("initialising Home Assistant client: %w", err)
+	}
+
+	// Rows left behind by an earlier mapping of the same list would make the
+	// reconciler treat missing items as deleted; start from a clean slate.
+	stale, err := store.DeleteList(ctx, listName)
+	if err != nil {
+		return false, err
+	}
+	if stale > 0 {
+		fmt.Printf("Discarded %d stale state row(s) from an earlier %q mapping.\n", stale, listName)
+	}
+
+	targets, err := syncTargets(cfg, haAdapter, logger)
+	if err != nil {
+		return false, err
+	}
+	bootstrap := syncp.NewBootstrap(reminders.NewBackend(remAdapter), targets, store, logger, os.Stdin, os.Stdout,
+		bootstrapOptions(cfg, planOut)...)
+	return bootstrap.RunForLists(ctx, map[string]string{listName: entityID})
+}
+
+// runRemoveMapping removes a list mapping from the config and forgets its
+// state rows. Items in Reminders and Home Assistant are left untouched.
+func runRemoveMapping(args []string) error {
+	const usage = "usage: reminderrelay remove-mapping [--config <path>] [--yes] <list>"
+
+	fs := flag.NewFlagSet("remove-mapping", flag.ExitOnError)
+	defaultCfg, _ := config.DefaultPath()
+	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
+	yes := fs.Bool("yes", false, "skip the confirmation prompt")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%s", usage)
+	}
+	listName := fs.Arg(0)
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config from %q: %w", *cfgPath, err)
+	}
+	entityID, ok := cfg.ListMappings[listName]
+	if !ok {
+		return fmt.Errorf("list %q is not in list_mappings", listName)
+	}
+
+	dbPath, err := stateDBPath(cfg)
+	if err != nil {
+		return err
+	}
+	store, err := state.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("opening state DB at %q: %w", dbPath, err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	tracked, err := store.GetAllItemsForList(ctx, listName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Removing %q → %s (%d linked item(s)).\n", listName, entityID, len(tracked))
+	fmt.Println("Items in Reminders and Home Assistant are kept; only the sync link is forgotten.")
+	if !*yes && !setup.NewPrompter(os.Stdin, os.Stdout).Confirm("Continue?", false) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	// Stop the daemon first so it cannot re-create rows for the list between
+	// the state cleanup and the restart.
+	homeDir, _ := os.UserHomeDir()
+	wasLoaded := setup.IsDaemonLoaded()
+	if wasLoaded {
+		if err := setup.UnloadDaemon(homeDir); err != nil {
+			return fmt.Errorf("stopping daemon: %w", err)
+		}
+	}
+
+	if err := config.RemoveListMapping(*cfgPath, listName); err != nil {
+		return err
+	}
+	n, err := store.DeleteList(ctx, listName)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Removed %q and %d state row(s)\n", listName, n)
+
+	if wasLoaded {
+		if err := setup.LoadDaemon(homeDir); err != nil {
+			return fmt.Errorf("restarting daemon: %w", err)
+		}
+		fmt.Println("✓ Daemon restarted")
+	}
+	return nil
+}
+
+// runPrune forgets the state of every list that has rows in the state DB but
+// is no longer in list_mappings, e.g. after a mapping was deleted by editing
+// config.yaml directly. Items in Reminders and Home Assistant are untouched.
+func runPrune(args []string) error {
+	const usage = "usage: reminderrelay prune [--config <path>] [--yes]"
+
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	defaultCfg, _ := config.DefaultPath()
+	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
+	yes := fs.Bool("yes", false, "skip the confirmation prompt")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config from %q: %w", *cfgPath, err)
+	}
+
+	dbPath, err := stateDBPath(cfg)
+	if err != nil {
+		return err
+	}
+	store, err := state.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("opening state DB at %q: %w", dbPath, err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	mapped := cfg.ListMappings
+	if cfg.SyncAllLists {
+		// Rows of every list sync_all_lists covers are still in use.
+		lists, err := setup.DiscoverRemindersLists(slog.New(slog.DiscardHandler))
+		if err != nil {
+			return fmt.Errorf("sync_all_lists: %w", err)
+		}
+		names := make([]string, 0, len(lists))
+		for _, l := range lists {
+			names = append(names, l.Title)
+		}
+		mapped = allListsMapped(cfg, names)
+	}
+	orphans, err := store.OrphanedLists(ctx, mapped)
+	if err != nil {
+		return err
+	}
+	if len(orphans) == 0 {
+		fmt.Println("Nothing to prune: every list in the state DB is mapped.")
+		return nil
+	}
+
+	fmt.Println("State for lists no longer in list_mappings:")
+	for _, o := range orphans {
+		fmt.Printf("  %-24s %d linked item(s)\n", o.ListName, o.Items)
+	}
+	fmt.Println("Items in Reminders and Home Assistant are kept; only the sync links are forgotten.")
+	if !*yes && !setup.NewPrompter(os.Stdin, os.Stdout).Confirm("Prune?", false) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	total := 0
+	for _, o := range orphans {
+		n, err := store.DeleteList(ctx, o.ListName)
+		if err != nil {
+			return err
+		}
+		total += n
+	}
+	fmt.Printf("✓ Pruned %d list(s) and %d state row(s)\n", len(orphans), total)
+	return nil
+}
+
+// warnOrphanedLists logs every list with leftover state rows but no mapping.
+// Removal needs confirmation, so the daemon only points at `prune`.
+func warnOrphanedLists(ctx context.Context, store *state.Store, cfg *config.Config, logger *slog.Logger) {
+	orphans, err := store.OrphanedLists(ctx, cfg.ListMappings)
+	if err != nil {
+		logger.Warn("checking for orphaned state rows", "error", err)
+		return
+	}
+	for _, o := range orphans {
+		logger.Warn("state DB has rows for a list that is no longer mapped; run 'reminderrelay prune' to remove them",
+			"list", o.ListName, "items", o.Items)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/njoerd114/reminderrelay/internal/menubar"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+)
+
+// runMenubar shows the running daemon's state in the macOS menu bar, with
+// actions to sync now and pause, until quit from its menu or interrupted.
+func runMenubar(args []string) error {
+	fs := flag.NewFlagSet("menubar", flag.ExitOnError)
+	interval := fs.Duration("interval", menubar.DefaultInterval, "how often to poll the daemon's status")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: reminderrelay menubar [--interval <duration>]")
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("resolving home directory: %w", err)
+	}
+	client, err := controlClient()
+	if err != nil {
+		return err
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	m := menubar.New(client, setup.LogFile(homeDir), logger, menubar.WithInterval(*interval))
+	return m.Run(ctx)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// runPin pins a tracked item to one side so that side's version always wins
+// conflicts and deletions on the other side are undone. Without arguments it
+// lists the pinned items.
+func runPin(args []string) error {
+	const usage = "usage: reminderrelay pin [<list> <title> reminders|ha]"
+
+	fs := flag.NewFlagSet("pin", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := openStateStore(nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+	ctx := context.Background()
+
+	switch fs.NArg() {
+	case 0:
+		return printPinned(ctx, store)
+	case 3:
+	default:
+		return fmt.Errorf("%s", usage)
+	}
+
+	listName, title := fs.Arg(0), fs.Arg(1)
+	var pin state.Pin
+	switch fs.Arg(2) {
+	case "reminders":
+		pin = state.PinReminders
+	case "ha":
+		pin = state.PinHA
+	default:
+		return fmt.Errorf("unknown side %q — use reminders or ha", fs.Arg(2))
+	}
+
+	if err := setPin(ctx, store, listName, title, pin); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Pinned %q in %s to %s\n", title, listName, sideName(pin))
+	return nil
+}
+
+// runUnpin removes the pin from a tracked item.
+func runUnpin(args []string) error {
+	const usage = "usage: reminderrelay unpin <list> <title>"
+
+	fs := flag.NewFlagSet("unpin", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	store, err := openStateStore(nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	listName, title := fs.Arg(0), fs.Arg(1)
+	if err := setPin(context.Background(), store, listName, title, state.PinNone); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Unpinned %q in %s\n", title, listName)
+	return nil
+}
+
+// setPin applies pin to the items titled title in listName, failing if none
+// is tracked.
+func setPin(ctx context.Context, store *state.Store, listName, title string, pin state.Pin) error {
+	n, err := store.SetPin(ctx, listName, title, pin)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no synced item titled %q in list %q", title, listName)
+	}
+	if n > 1 {
+		fmt.Printf("Note: %d items share this title; all were changed.\n", n)
+	}
+	return nil
+}
+
+// printPinned lists every pinned item.
+func printPinned(ctx context.Context, store *state.Store) error {
+	items, err := store.PinnedItems(ctx)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		fmt.Println("No pinned items.")
+		return nil
+	}
+	for _, it := range items {
+		fmt.Printf("  %-24s %-32s %s\n", it.ListName, it.Title, sideName(it.Pin))
+	}
+	return nil
+}
+
+// sideName returns the display name of the side pin refers to.
+func sideName(pin state.Pin) string {
+	if pin == state.PinHA {
+		return "Home Assistant"
+	}
+	return "Reminders"
+}
+
+// openStateStore opens the state DB at [stateDBPath] for cfg, or at
+// [defaultStateDBPath] if cfg is nil.
+func openStateStore(cfg *config.Config) (*state.Store, error) {
+	var (
+		dbPath string
+		err    error
+	)
+	if cfg != nil {
+		dbPath, err = stateDBPath(cfg)
+	} else {
+		dbPath, err = defaultStateDBPath()
+	}
+	if err != nil {
+		return nil, err
+	}
+	store, err := state.Open(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening state DB at %q: %w", dbPath, err)
+	}
+	return store, nil
+}
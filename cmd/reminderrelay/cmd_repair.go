@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/reminders"
+	"github.com/njoerd114/reminderrelay/internal/render"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// runRepair fixes state rows that no longer match the live items: it relinks
+// rows to items recreated under a new UID, drops rows whose item is gone on
+// both sides, and recomputes outdated sync hashes. The fixes are listed
+// first and applied only once confirmed; items themselves are never changed.
+// The daemon is stopped meanwhile.
+func runRepair(args []string) error {
+	const usage = "usage: reminderrelay repair [--config <path>] [--list <list>] [--dry-run] [--yes] [--no-color]"
+
+	fs := flag.NewFlagSet("repair", flag.ExitOnError)
+	defaultCfg, _ := config.DefaultPath()
+	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
+	only := fs.String("list", "", "only repair this Reminders list")
+	dryRun := fs.Bool("dry-run", false, "list the fixes without applying them")
+	yes := fs.Bool("yes", false, "apply the fixes without asking")
+	noColor := fs.Bool("no-color", false, "disable coloured output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config from %q: %w", *cfgPath, err)
+	}
+	store, err := openStateStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	remAdapter, err := newRemindersAdapter(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initialising Reminders client: %w", err)
+	}
+	haAdapter, err := newHAAdapter(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initialising Home Assistant client: %w", err)
+	}
+	remLists := reminders.NewBackend(remAdapter)
+
+	mappings, err := activeMappings(ctx, cfg, store, remLists, *only, logger)
+	if err != nil {
+		return err
+	}
+	targets, err := syncTargets(cfg, haAdapter, logger)
+	if err != nil {
+		return err
+	}
+
+	// Stop the daemon before planning, so no pass changes the rows between
+	// the plan and its application.
+	homeDir, _ := os.UserHomeDir()
+	wasLoaded := !*dryRun && setup.IsDaemonLoaded()
+	if wasLoaded {
+		if err := setup.UnloadDaemon(homeDir); err != nil {
+			return fmt.Errorf("stopping daemon: %w", err)
+		}
+	}
+
+	repairErr := repairState(ctx, syncp.NewVerifier(remLists, targets, store, 0), mappings, *dryRun, *yes, *noColor)
+
+	if wasLoaded {
+		if err := setup.LoadDaemon(homeDir); err != nil {
+			return fmt.Errorf("restarting daemon: %w", err)
+		}
+		fmt.Println("✓ Daemon restarted")
+	}
+	return repairErr
+}
+
+// repairState lists the repairs v plans for mappings and, unless dryRun is
+// set, applies them once confirmed on stdin or yes is set.
+func repairState(ctx context.Context, v *syncp.Verifier, mappings map[string]string, dryRun, yes, noColor bool) error {
+	repairs, err := v.PlanRepairs(ctx, mappings)
+	if err != nil {
+		return err
+	}
+	out := render.New(os.Stdout, noColor)
+	if len(repairs) == 0 {
+		fmt.Printf("%s Nothing to repair (%d list(s) checked).\n", out.Style(render.Good, "✓"), len(mappings))
+		return nil
+	}
+
+	rows := make([][]string, 0, len(repairs))
+	for _, r := range repairs {
+		rows = append(rows, []string{r.ListName, string(r.Kind), r.Title, r.Detail})
+	}
+	out.Table([]string{"LIST", "FIX", "ITEM", "DETAIL"}, rows)
+	if dryRun {
+		fmt.Printf("\nDry run: %d fix(es) not applied.\n", len(repairs))
+		return nil
+	}
+
+	fmt.Println()
+	if !yes && !setup.NewPrompter(os.Stdin, os.Stdout).Confirm(fmt.Sprintf("Apply %d fix(es) to the state DB?", len(repairs)), false) {
+		fmt.Println("Nothing changed.")
+		return nil
+	}
+	applied, err := v.ApplyRepairs(ctx, repairs)
+	if err != nil {
+		fmt.Printf("Stopped after %d of %d fix(es).\n", applied, len(repairs))
+		return err
+	}
+	fmt.Printf("%s Applied %d fix(es)\n", out.Style(render.Good, "✓"), applied)
+	return nil
+}
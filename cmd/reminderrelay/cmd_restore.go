@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/reminders"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+	"github.com/njoerd114/reminderrelay/internal/state"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// runRestore replaces the state DB with a backup and links items synced since
+// the backup was written, so the next pass does not duplicate them. Without
+// --backup it lists the backups. The daemon is stopped meanwhile.
+func runRestore(args []string) error {
+	const usage = "usage: reminderrelay restore [--config <path>] [--backup <file>]"
+
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	defaultCfg, _ := config.DefaultPath()
+	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
+	backup := fs.String("backup", "", "backup to restore: a path, or a file name in the backup directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config from %q: %w", *cfgPath, err)
+	}
+	dbPath, err := stateDBPath(cfg)
+	if err != nil {
+		return err
+	}
+	dir := state.BackupDir(dbPath)
+	if *backup == "" {
+		return listBackups(dir)
+	}
+	path := *backup
+	if !strings.ContainsRune(path, filepath.Separator) {
+		path = filepath.Join(dir, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	homeDir, _ := os.UserHomeDir()
+	wasLoaded := setup.IsDaemonLoaded()
+	if wasLoaded {
+		if err := setup.UnloadDaemon(homeDir); err != nil {
+			return fmt.Errorf("stopping daemon: %w", err)
+		}
+	}
+
+	replaced, restoreErr := state.Restore(ctx, path, dbPath)
+	if restoreErr == nil {
+		fmt.Printf("✓ Restored the state from %s\n", filepath.Base(path))
+		if replaced != "" {
+			fmt.Printf("  The previous state was kept at %s\n", replaced)
+		}
+		restoreErr = relinkAfterRestore(ctx, cfg, logger)
+	}
+
+	if wasLoaded {
+		if err := setup.LoadDaemon(homeDir); err != nil {
+			return fmt.Errorf("restarting daemon: %w", err)
+		}
+		fmt.Println("✓ Daemon restarted")
+	}
+	return restoreErr
+}
+
+// listBackups prints the backups in dir, newest first.
+func listBackups(dir string) error {
+	backups, err := state.Backups(dir)
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		fmt.Printf("No backups in %s.\n", dir)
+		return nil
+	}
+	fmt.Printf("Backups in %s, newest first:\n", dir)
+	for _, b := range backups {
+		written := ""
+		if info, err := os.Stat(b); err == nil {
+			written = info.ModTime().Format(time.DateTime)
+		}
+		fmt.Printf("  %-32s %s\n", filepath.Base(b), written)
+	}
+	fmt.Println("\nRestore one with: reminderrelay restore --backup <file>")
+	return nil
+}
+
+// relinkAfterRestore links items that exist on both sides of a mapped list
+// but are unknown to the restored state DB.
+func relinkAfterRestore(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
+	store, err := openStateStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	remAdapter, err := newRemindersAdapter(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initialising Reminders client: %w", err)
+	}
+	haAdapter, err := newHAAdapter(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initialising Home Assistant client: %w", err)
+	}
+	remBackend := reminders.NewBackend(remAdapter)
+
+	mappings := maps.Clone(cfg.ListMappings)
+	if cfg.SyncAllLists {
+		names, err := remBackend.Lists(ctx)
+		if err != nil {
+			return fmt.Errorf("sync_all_lists: %w", err)
+		}
+		if mappings, err = expandAllLists(ctx, cfg, names, logger); err != nil {
+			return err
+		}
+	}
+	targets, err := syncTargets(cfg, haAdapter, logger)
+	if err != nil {
+		return err
+	}
+
+	bootstrap := syncp.NewBootstrap(remBackend, targets, store, logger, os.Stdin, os.Stdout)
+	linked, err := bootstrap.LinkUntracked(ctx, mappings)
+	if err != nil {
+		return fmt.Errorf("linking items synced since the backup: %w", err)
+	}
+	if linked > 0 {
+		fmt.Printf("✓ Linked %d item(s) synced since the backup\n", linked)
+	}
+	return nil
+}
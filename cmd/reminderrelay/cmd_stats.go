@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/render"
+)
+
+// runStats prints the totals of the sync runs recorded in the state DB over
+// a recent period. It only reads the DB, so the daemon may keep running.
+func runStats(args []string) error {
+	const usage = "usage: reminderrelay stats [--config <path>] [--since <period>] [--no-color]"
+
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	defaultCfg, _ := config.DefaultPath()
+	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
+	sinceStr := fs.String("since", "30d", "period to report on, e.g. 7d or 12h")
+	noColor := fs.Bool("no-color", false, "disable coloured output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%s", usage)
+	}
+	period, err := parsePeriod(*sinceStr)
+	if err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config from %q: %w", *cfgPath, err)
+	}
+	store, err := openStateStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	now := time.Now()
+	sum, err := store.SummarizeSyncRuns(ctx, now.Add(-period))
+	if err != nil {
+		return err
+	}
+	last, err := store.LastSuccessfulSyncRun(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := render.New(os.Stdout, *noColor)
+	out.Heading("Sync statistics, last " + *sinceStr)
+	var fields [][2]string
+	if sum.Runs == 0 {
+		fields = append(fields, [2]string{"Runs", "none recorded in this period"})
+	} else {
+		// Rates are per day of recorded history, which may be shorter than
+		// the period asked for.
+		days := max(now.Sub(sum.First).Hours()/24, 1.0/24)
+		days = min(days, period.Hours()/24)
+		perDay := func(n int) string {
+			return fmt.Sprintf("%d (%.1f/day)", n, float64(n)/days)
+		}
+
+		failed := fmt.Sprintf("%d failed (%.1f%%)", sum.Failed, 100*float64(sum.Failed)/float64(sum.Runs))
+		if sum.Failed > 0 {
+			failed = out.Style(render.Warn, failed)
+		}
+		itemErrors := strconv.Itoa(sum.Errors)
+		if sum.Errors > 0 {
+			itemErrors = out.Style(render.Warn, itemErrors)
+		}
+		fields = append(fields, [][2]string{
+			{"Runs", perDay(sum.Runs) + ", " + failed},
+			{"Created", perDay(sum.Created)},
+			{"Updated", perDay(sum.Updated)},
+			{"Deleted", perDay(sum.Deleted)},
+			{"Conflicts", perDay(sum.Conflicts)},
+			{"Item errors", itemErrors},
+			{"Duration", fmt.Sprintf("%s average, %s longest",
+				(sum.Duration / time.Duration(sum.Runs)).Round(time.Millisecond),
+				sum.MaxDuration.Round(time.Millisecond))},
+			{"Since", sum.First.Local().Format("2006-01-02 15:04")},
+		}...)
+	}
+
+	lastOK := out.Style(render.Warn, "never")
+	if last != nil {
+		lastOK = fmt.Sprintf("%s (%s ago, %s pass, %s)",
+			last.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			now.Sub(last.StartedAt).Round(time.Second), last.Kind,
+			last.Duration.Round(time.Millisecond))
+	}
+	out.Fields(append(fields, [2]string{"Last success", lastOK}))
+	return nil
+}
+
+// parsePeriod parses a period such as "30d" or "12h": a number of days, or
+// anything [time.ParseDuration] accepts.
+func parsePeriod(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid period %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid period %q", s)
+	}
+	return d, nil
+}
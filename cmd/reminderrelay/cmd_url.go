@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/control"
+	"github.com/njoerd114/reminderrelay/internal/desktop"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+)
+
+// urlNotificationID is the ID of the notification raised when an action
+// requested by URL fails and the URL has no x-error callback to report to.
+const urlNotificationID = "reminderrelay_url_action"
+
+// runOpenURL performs the daemon action a reminderrelay:// URL requests, as
+// the URL handler app does for URLs opened by Shortcuts or other apps, and
+// then opens the URL's x-success or x-error callback, if any.
+func runOpenURL(args []string) error {
+	fs := flag.NewFlagSet("open-url", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: reminderrelay open-url <%s://action>", control.URLScheme)
+	}
+	req, err := control.ParseURL(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	params, err := urlAction(req)
+	if cb := req.Callback(params, err); cb != "" {
+		if oerr := exec.Command("open", cb).Run(); oerr != nil {
+			fmt.Fprintf(os.Stderr, "warning: opening callback %s: %v\n", cb, oerr)
+		}
+	} else if err != nil && req.Error == "" {
+		// Opened from an app, nobody sees the output; tell the user instead.
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+		n := desktop.NewNotifier(0, logger)
+		_ = n.CreateNotification(context.Background(), urlNotificationID,
+			"ReminderRelay: "+req.Action+" failed", err.Error())
+	}
+	if err != nil {
+		return err
+	}
+	for _, k := range []string{"paused", "passes", "last_pass_at", "last_error", "created", "updated", "deleted", "conflicts", "errors"} {
+		if v := params.Get(k); v != "" {
+			fmt.Printf("%s=%s\n", k, v)
+		}
+	}
+	return nil
+}
+
+// urlAction asks the running daemon for the action of req and returns what
+// is passed on to the x-success callback.
+func urlAction(req *control.URLRequest) (url.Values, error) {
+	client, err := controlClient()
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	switch req.Action {
+	case control.ActionSync:
+		var res *control.SyncResult
+		if req.List != "" || req.Entity != "" {
+			res, err = client.SyncSelected(context.Background(), req.List, req.Entity)
+		} else {
+			res, err = client.Sync(context.Background())
+		}
+		if err != nil {
+			return nil, controlError(err)
+		}
+		if res.Error != "" {
+			return nil, fmt.Errorf("sync pass: %s", res.Error)
+		}
+		s := res.Stats
+		params.Set("created", strconv.Itoa(s.Created))
+		params.Set("updated", strconv.Itoa(s.Updated))
+		params.Set("deleted", strconv.Itoa(s.Deleted))
+		params.Set("conflicts", strconv.Itoa(s.Conflicts))
+		params.Set("errors", strconv.Itoa(s.Errors))
+		return params, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	switch req.Action {
+	case control.ActionPause:
+		err = client.Pause(ctx)
+		params.Set("paused", "true")
+	case control.ActionResume:
+		err = client.Resume(ctx)
+		params.Set("paused", "false")
+	case control.ActionStatus:
+		var st *control.Status
+		if st, err = client.Status(ctx); err == nil {
+			params.Set("paused", strconv.FormatBool(st.Paused))
+			params.Set("passes", strconv.Itoa(st.Passes))
+			if !st.LastPassAt.IsZero() {
+				params.Set("last_pass_at", st.LastPassAt.Format(time.RFC3339))
+			}
+			if st.LastError != "" {
+				params.Set("last_error", st.LastError)
+			}
+		}
+	}
+	if err != nil {
+		return nil, controlError(err)
+	}
+	return params, nil
+}
+
+// runURLHandler installs or removes the app that lets other apps, such as
+// Shortcuts, open reminderrelay:// URLs.
+func runURLHandler(args []string) error {
+	fs := flag.NewFlagSet("url-handler", flag.ExitOnError)
+	uninstall := fs.Bool("uninstall", false, "remove the URL handler app")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: reminderrelay url-handler [--uninstall]")
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("resolving home directory: %w", err)
+	}
+
+	if *uninstall {
+		if err := setup.RemoveURLHandler(homeDir); err != nil {
+			return err
+		}
+		fmt.Printf("✓ URL handler removed; %s:// URLs no longer open anything\n", control.URLScheme)
+		return nil
+	}
+
+	binary := setup.BinaryInstallPath()
+	if _, err := os.Stat(binary); err != nil {
+		// Not installed by setup; use the running binary.
+		self, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("resolving current executable path: %w", err)
+		}
+		if binary, err = filepath.EvalSymlinks(self); err != nil {
+			return fmt.Errorf("resolving executable symlinks: %w", err)
+		}
+	}
+	if err := setup.InstallURLHandler(homeDir, binary); err != nil {
+		return fmt.Errorf("installing URL handler: %w", err)
+	}
+	fmt.Printf("✓ URL handler installed: %s\n", setup.URLHandlerPath(homeDir))
+	fmt.Printf("  Try it: open '%s://sync'\n", control.URLScheme)
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/reminders"
+	"github.com/njoerd114/reminderrelay/internal/render"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// runVerify cross-checks the state DB against the live items of both sides
+// and prints what does not add up. It changes nothing, so the daemon may keep
+// running. Returns an error when something was found, for scripts.
+func runVerify(args []string) error {
+	const usage = "usage: reminderrelay verify [--config <path>] [--list <list>] [--settle <duration>] [--no-color]"
+
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	defaultCfg, _ := config.DefaultPath()
+	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
+	only := fs.String("list", "", "only check this Reminders list")
+	settle := fs.Duration("settle", 10*time.Minute, "report linked items that differ once unchanged for this long")
+	noColor := fs.Bool("no-color", false, "disable coloured output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config from %q: %w", *cfgPath, err)
+	}
+	store, err := openStateStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	remAdapter, err := newRemindersAdapter(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initialising Reminders client: %w", err)
+	}
+	haAdapter, err := newHAAdapter(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initialising Home Assistant client: %w", err)
+	}
+	remLists := reminders.NewBackend(remAdapter)
+
+	mappings, err := activeMappings(ctx, cfg, store, remLists, *only, logger)
+	if err != nil {
+		return err
+	}
+	targets, err := syncTargets(cfg, haAdapter, logger)
+	if err != nil {
+		return err
+	}
+
+	findings, err := syncp.NewVerifier(remLists, targets, store, *settle).Verify(ctx, mappings)
+	if err != nil {
+		return err
+	}
+	problems := len(findings)
+
+	out := render.New(os.Stdout, *noColor)
+	if problems > 0 {
+		rows := make([][]string, 0, len(findings))
+		for _, f := range findings {
+			side := "both"
+			switch f.Side {
+			case "reminders":
+				side = "Reminders"
+			case "ha":
+				side = mappings[f.ListName]
+			}
+			rows = append(rows, []string{f.ListName, string(f.Kind), side, f.Title, f.Detail})
+		}
+		out.Table([]string{"LIST", "FINDING", "SIDE", "ITEM", "DETAIL"}, rows)
+	}
+
+	if *only == "" {
+		orphaned, err := store.OrphanedLists(ctx, mappings)
+		if err != nil {
+			return err
+		}
+		for _, o := range orphaned {
+			if o.Items == 0 {
+				continue
+			}
+			problems++
+			fmt.Printf("State of %d item(s) is kept for %q, which is not mapped (run 'reminderrelay prune').\n", o.Items, o.ListName)
+		}
+	}
+
+	if problems == 0 {
+		fmt.Printf("%s State DB, Reminders, and Home Assistant agree (%d list(s) checked).\n",
+			out.Style(render.Good, "✓"), len(mappings))
+		return nil
+	}
+	return fmt.Errorf("verify found %d problem(s)", problems)
+}
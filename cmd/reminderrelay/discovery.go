@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// localTodoProvisioner maps new Reminders lists to fresh Home Assistant
+// Local To-do lists and records the mapping in the config file.
+type localTodoProvisioner struct {
+	cfgPath string
+	cfg     *config.Config
+	store   *state.Store
+	// derived skips writing the config file, for sync_all_lists, which
+	// derives its mappings again at every start.
+	derived bool
+}
+
+// Provision creates a Local To-do list named list and maps list to it. An HA
+// list of the same name is left alone: syncing into it needs the title
+// matching of 'reminderrelay add-mapping' to avoid duplicates.
+func (p *localTodoProvisioner) Provision(ctx context.Context, list string) (string, error) {
+	entities, err := setup.DiscoverHATodoEntities(ctx, p.cfg.HAURL, p.cfg.HAToken)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range entities {
+		if e.FriendlyName == list {
+			return "", fmt.Errorf("Home Assistant already has a list called %q (%s)", list, e.EntityID)
+		}
+	}
+
+	// Rows left behind by an earlier mapping would make the reconciler treat
+	// every reminder as deleted in the new, empty list.
+	if _, err := p.store.DeleteList(ctx, list); err != nil {
+		return "", err
+	}
+	entityID, err := setup.CreateLocalTodo(ctx, p.cfg.HAURL, p.cfg.HAToken, list)
+	if err != nil {
+		return "", err
+	}
+	if p.derived {
+		return entityID, nil
+	}
+	if err := config.AddListMapping(p.cfgPath, list, entityID); err != nil {
+		return "", fmt.Errorf("created %s but could not save the mapping: %w", entityID, err)
+	}
+	return entityID, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/scheduler"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// backupJobName is the job that backs up the state DB. Disabling it also
+// stops all other backups and corruption recovery from restoring backups.
+const backupJobName = "db-backup"
+
+// syncRunRetention is how long the db-maintenance job keeps the sync runs
+// `reminderrelay stats` reports on.
+const syncRunRetention = 90 * 24 * time.Hour
+
+// defaultBackupsKept is the number of state DB backups kept unless the
+// backups block says otherwise.
+const defaultBackupsKept = 7
+
+// backupsEnabled reports whether cfg leaves state DB backups on.
+func backupsEnabled(cfg *config.Config) bool {
+	job := cfg.Jobs[backupJobName]
+	return job == nil || !job.Disabled
+}
+
+// backupsKept returns the number of state DB backups cfg keeps.
+func backupsKept(cfg *config.Config) int {
+	if cfg.Backups != nil {
+		return cfg.Backups.Keep
+	}
+	return defaultBackupsKept
+}
+
+// builtinJobs returns the daemon's auxiliary jobs on their default schedule.
+// The backup job writes to backupDir and keeps the newest keep backups.
+func builtinJobs(store *state.Store, backupDir string, keep int) []scheduler.Job {
+	return []scheduler.Job{
+		{
+			Name:   "db-maintenance",
+			Every:  24 * time.Hour,
+			Jitter: time.Hour,
+			Run: func(ctx context.Context) error {
+				if _, err := store.PruneSyncRuns(ctx, time.Now().Add(-syncRunRetention)); err != nil {
+					return err
+				}
+				return store.Maintain(ctx)
+			},
+		},
+		{
+			Name:   backupJobName,
+			Every:  24 * time.Hour,
+			Jitter: time.Hour,
+			Run: func(ctx context.Context) error {
+				_, err := store.Backup(ctx, backupDir, keep)
+				return err
+			},
+		},
+	}
+}
+
+// auxiliaryJobs returns the built-in jobs with the overrides from the jobs
+// block of cfg applied. Disabled jobs are left out; an override for an
+// unknown job is an error.
+func auxiliaryJobs(cfg *config.Config, store *state.Store, backupDir string) ([]scheduler.Job, error) {
+	all := builtinJobs(store, backupDir, backupsKept(cfg))
+	known := make(map[string]bool, len(all))
+	for _, job := range all {
+		known[job.Name] = true
+	}
+	for name := range cfg.Jobs {
+		if !known[name] {
+			names := make([]string, 0, len(known))
+			for n := range known {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("jobs.%s: no such job (known: %s)", name, strings.Join(names, ", "))
+		}
+	}
+
+	var jobs []scheduler.Job
+	for _, job := range all {
+		if override := cfg.Jobs[job.Name]; override != nil {
+			if override.Disabled {
+				continue
+			}
+			if override.Every != 0 {
+				job.Every = override.Every
+			}
+			if override.Jitter != 0 {
+				job.Jitter = override.Jitter
+			}
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
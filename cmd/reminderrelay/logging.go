@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/logfile"
+	"github.com/njoerd114/reminderrelay/internal/redact"
+)
+
+// Log rotation defaults, used unless the log_rotation block says otherwise.
+const (
+	defaultLogMaxSizeMB = 10
+	defaultLogMaxAge    = 7 * 24 * time.Hour
+	defaultLogsKept     = 5
+)
+
+// newLogHandler returns the daemon's log handler writing to w in format,
+// "text" or "json", from level up.
+func newLogHandler(w io.Writer, format string, level slog.Leveler) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: level}
+	switch format {
+	case "", "text":
+		return slog.NewTextHandler(w, opts), nil
+	case "json":
+		return slog.NewJSONHandler(w, opts), nil
+	}
+	return nil, fmt.Errorf("--log-format %q must be text or json", format)
+}
+
+// logRotation returns the rotation limits of the daemon's log file.
+func logRotation(cfg *config.Config) logfile.Options {
+	opts := logfile.Options{
+		MaxSize: defaultLogMaxSizeMB << 20,
+		MaxAge:  defaultLogMaxAge,
+		Keep:    defaultLogsKept,
+	}
+	if r := cfg.LogRotation; r != nil {
+		opts = logfile.Options{MaxSize: int64(r.MaxSizeMB) << 20, MaxAge: r.MaxAge, Keep: r.Keep}
+	}
+	return opts
+}
+
+// configRedactor returns a redactor for the secrets in cfg: the HA token,
+// the telemetry headers, and the CalDAV passwords. A header such as
+// "Bearer <key>" is scrubbed whole and by its credential alone, as an error
+// may quote either. A nil cfg yields a redactor without secrets.
+func configRedactor(cfg *config.Config) *redact.Redactor {
+	if cfg == nil {
+		return redact.New()
+	}
+	secrets := []string{cfg.HAToken}
+	if cfg.Telemetry != nil {
+		for _, v := range cfg.Telemetry.Headers {
+			secrets = append(secrets, v)
+			if f := strings.Fields(v); len(f) > 1 {
+				secrets = append(secrets, f[len(f)-1])
+			}
+		}
+	}
+	for _, server := range cfg.CalDAV {
+		secrets = append(secrets, server.Password)
+	}
+	return redact.New(secrets...)
+}
@@ -1,15 +1,48 @@
 // ReminderRelay is a macOS daemon that syncs Apple Reminders ↔ Home Assistant
-// todo lists bidirectionally using last-write-wins conflict resolution.
+// todo lists bidirectionally, merging concurrent edits field by field.
 //
 // Usage:
 //
 //	reminderrelay setup                     # interactive first-run wizard
 //	reminderrelay daemon [--config <path>]  # start polling + WebSocket listener
 //	reminderrelay sync-once [--config ...]  # single reconcile pass then exit
-//	reminderrelay status                    # show daemon & config state
+//	reminderrelay sync-once --via-daemon    # ask the running daemon for a pass
+//	reminderrelay sync-once --list <list>   # sync one mapping only (or --entity <id>)
+//	reminderrelay status [--no-color]       # show daemon & config state
+//	reminderrelay pause                     # pause the running daemon's syncing
+//	reminderrelay resume                    # resume a paused daemon
+//	reminderrelay stats [--since 30d]       # totals of the sync runs of a period
+//	reminderrelay conflicts [--no-color]    # list recently resolved conflicts
+//	reminderrelay menubar                   # show the daemon's state in the menu bar
+//	reminderrelay promote <list>            # promote a shadow-mode list mapping
+//	reminderrelay config get <key>          # print a config value
+//	reminderrelay config set <key> <value>  # validate and update a config value
+//	reminderrelay add-mapping [<list> <id>] # map another list + targeted bootstrap
+//	reminderrelay remove-mapping <list>     # unmap a list and forget its state
+//	reminderrelay prune [--yes]             # forget state of lists no longer mapped
+//	reminderrelay pin <list> <title> <side> # make one side always win for an item
+//	reminderrelay unpin <list> <title>      # remove an item's pin
+//	reminderrelay push-item --list <l> --title <t> # overwrite HA's version of an item
+//	reminderrelay pull-item --list <l> --title <t> # overwrite Reminders' version of an item
+//	reminderrelay dedupe [--list <list>]    # merge or rename items sharing a title
+//	reminderrelay restore [--backup <file>] # list state DB backups or restore one
+//	reminderrelay db vacuum|analyze|integrity-check # maintain the state DB
+//	reminderrelay logs prune [--keep <n>]   # remove old rotated log files
+//	reminderrelay verify [--list <list>]    # check state against both sides, read-only
+//	reminderrelay inspect <title|uid>       # show an item on both sides and in the state DB
+//	reminderrelay repair [--dry-run]        # fix state rows that no longer match the items
+//	reminderrelay grant-access              # ask macOS for access to Reminders
+//	reminderrelay url-handler [--uninstall] # let Shortcuts open reminderrelay:// URLs
+//	reminderrelay open-url <url>            # run the daemon action a URL requests
 //	reminderrelay uninstall [--purge]       # stop daemon and remove files
+//	reminderrelay uninstall --legacy        # remove leftovers of older installs
 //	reminderrelay version                   # print version
 //
+// Every command takes --profile <name> to act on a separate relay with its
+// own config, state DB, logs, and launchd job; see package profile.
+// --state-db <path> overrides the location of the state DB, which the
+// state_db config key sets permanently.
+//
 // Legacy flag-based invocation is still supported for backward compatibility:
 //
 //	reminderrelay --daemon [--config <path>] [--verbose]
@@ -17,22 +50,34 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
+	"net"
 	"os"
-	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/control"
+	"github.com/njoerd114/reminderrelay/internal/dashboard"
+	"github.com/njoerd114/reminderrelay/internal/desktop"
 	"github.com/njoerd114/reminderrelay/internal/homeassistant"
+	"github.com/njoerd114/reminderrelay/internal/logfile"
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/profile"
 	"github.com/njoerd114/reminderrelay/internal/reminders"
+	"github.com/njoerd114/reminderrelay/internal/render"
+	"github.com/njoerd114/reminderrelay/internal/scheduler"
 	"github.com/njoerd114/reminderrelay/internal/setup"
 	"github.com/njoerd114/reminderrelay/internal/state"
 	syncp "github.com/njoerd114/reminderrelay/internal/sync"
@@ -42,15 +87,53 @@ import (
 // version is set at build time via -ldflags "-X main.version=..."
 var version = "dev"
 
+// Exit codes of the process. sync-once tells a pass that ran but failed for
+// some items or lists apart from one that could not run at all; the daemon
+// tells missing Reminders access apart from other failures to start.
+const (
+	exitFatal      = 1
+	exitItemErrors = 2
+	exitNoAccess   = 3
+)
+
+// exitError is an error to exit with a code other than [exitFatal].
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
 func main() {
 	if err := run(); err != nil {
-		slog.Error("fatal error", "error", err)
-		os.Exit(1)
+		code := exitFatal
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.code
+		}
+		if code == exitItemErrors {
+			slog.Error("sync pass finished with errors", "error", err)
+		} else {
+			slog.Error("fatal error", "error", err)
+		}
+		os.Exit(code)
 	}
 }
 
 // run dispatches to the appropriate subcommand or falls back to legacy flags.
 func run() error {
+	// --profile and --state-db apply to every subcommand, so they are taken
+	// out before their own flags are parsed.
+	args, err := profile.FromArgs(os.Args[1:])
+	if err != nil {
+		return err
+	}
+	if args, err = stateDBFromArgs(args); err != nil {
+		return err
+	}
+	os.Args = append(os.Args[:1], args...)
+
 	// No arguments → smart usage.
 	if len(os.Args) < 2 {
 		return printUsage()
@@ -67,7 +150,55 @@ func run() error {
 	case "sync-once":
 		return runSync(os.Args[2:], false)
 	case "status":
-		return runStatus()
+		return runStatus(os.Args[2:])
+	case "pause":
+		return runPause(os.Args[2:])
+	case "resume":
+		return runResume(os.Args[2:])
+	case "stats":
+		return runStats(os.Args[2:])
+	case "menubar":
+		return runMenubar(os.Args[2:])
+	case "conflicts":
+		return runConflicts(os.Args[2:])
+	case "promote":
+		return runPromote(os.Args[2:])
+	case "config":
+		return runConfig(os.Args[2:])
+	case "add-mapping":
+		return runAddMapping(os.Args[2:])
+	case "remove-mapping":
+		return runRemoveMapping(os.Args[2:])
+	case "prune":
+		return runPrune(os.Args[2:])
+	case "pin":
+		return runPin(os.Args[2:])
+	case "unpin":
+		return runUnpin(os.Args[2:])
+	case "push-item":
+		return runPushItem(os.Args[2:])
+	case "pull-item":
+		return runPullItem(os.Args[2:])
+	case "dedupe":
+		return runDedupe(os.Args[2:])
+	case "restore":
+		return runRestore(os.Args[2:])
+	case "db":
+		return runDB(os.Args[2:])
+	case "logs":
+		return runLogs(os.Args[2:])
+	case "verify":
+		return runVerify(os.Args[2:])
+	case "inspect":
+		return runInspect(os.Args[2:])
+	case "repair":
+		return runRepair(os.Args[2:])
+	case "grant-access":
+		return runGrantAccess(os.Args[2:])
+	case "url-handler":
+		return runURLHandler(os.Args[2:])
+	case "open-url":
+		return runOpenURL(os.Args[2:])
 	case "uninstall":
 		return runUninstall(os.Args[2:])
 	case "version":
@@ -94,10 +225,35 @@ func printUsage() error {
 	fmt.Fprintln(os.Stderr, "  reminderrelay setup                  Interactive first-run wizard")
 	fmt.Fprintln(os.Stderr, "  reminderrelay daemon [--config ...]   Run as continuous daemon")
 	fmt.Fprintln(os.Stderr, "  reminderrelay sync-once [--config ..] Single sync pass then exit")
-	fmt.Fprintln(os.Stderr, "  reminderrelay status                  Show daemon & config state")
+	fmt.Fprintln(os.Stderr, "  reminderrelay status [--no-color]     Show daemon & config state")
+	fmt.Fprintln(os.Stderr, "  reminderrelay pause | resume          Pause or resume the running daemon")
+	fmt.Fprintln(os.Stderr, "  reminderrelay stats [--since 30d]     Show totals of recent sync runs")
+	fmt.Fprintln(os.Stderr, "  reminderrelay conflicts [--no-color]  List conflicts resolved recently")
+	fmt.Fprintln(os.Stderr, "  reminderrelay menubar                 Show the daemon's state in the menu bar")
+	fmt.Fprintln(os.Stderr, "  reminderrelay promote <list>          Promote a shadow-mode list mapping")
+	fmt.Fprintln(os.Stderr, "  reminderrelay config get|set ...      Read or edit config.yaml safely")
+	fmt.Fprintln(os.Stderr, "  reminderrelay add-mapping [...]       Map another list and link its items")
+	fmt.Fprintln(os.Stderr, "  reminderrelay remove-mapping <list>   Unmap a list and forget its state")
+	fmt.Fprintln(os.Stderr, "  reminderrelay prune [--yes]           Forget state of lists no longer mapped")
+	fmt.Fprintln(os.Stderr, "  reminderrelay pin [<list> <title> ..] Pin an item to reminders or ha")
+	fmt.Fprintln(os.Stderr, "  reminderrelay unpin <list> <title>    Remove an item's pin")
+	fmt.Fprintln(os.Stderr, "  reminderrelay push-item|pull-item --list <l> --title <t>  Copy one item over the other side")
+	fmt.Fprintln(os.Stderr, "  reminderrelay dedupe [--list <list>]  Merge or rename items sharing a title")
+	fmt.Fprintln(os.Stderr, "  reminderrelay restore [--backup <f>]  List state backups or restore one")
+	fmt.Fprintln(os.Stderr, "  reminderrelay db vacuum|analyze|integrity-check  Maintain the state DB")
+	fmt.Fprintln(os.Stderr, "  reminderrelay logs prune [--keep <n>] Remove old rotated log files")
+	fmt.Fprintln(os.Stderr, "  reminderrelay verify [--list <list>]  Check state against both sides (read-only)")
+	fmt.Fprintln(os.Stderr, "  reminderrelay inspect <title|uid>     Show an item on both sides and in the state DB")
+	fmt.Fprintln(os.Stderr, "  reminderrelay repair [--dry-run]      Fix state rows that no longer match the items")
+	fmt.Fprintln(os.Stderr, "  reminderrelay grant-access            Ask macOS for access to Reminders")
+	fmt.Fprintln(os.Stderr, "  reminderrelay url-handler [--uninstall]  Let Shortcuts open reminderrelay:// URLs")
+	fmt.Fprintln(os.Stderr, "  reminderrelay open-url <url>          Run the daemon action a reminderrelay:// URL requests")
 	fmt.Fprintln(os.Stderr, "  reminderrelay uninstall [--purge]     Stop daemon and remove files")
 	fmt.Fprintln(os.Stderr, "  reminderrelay version                 Print version")
 	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "  --profile <name>  act on a separate relay, e.g. for a second Home Assistant")
+	fmt.Fprintln(os.Stderr, "  --state-db <path> use the state DB at path instead of the configured one")
+	fmt.Fprintln(os.Stderr, "")
 
 	if cfgErr != nil {
 		fmt.Fprintln(os.Stderr, "No config file found. Run 'reminderrelay setup' to get started.")
@@ -127,11 +283,36 @@ func runSync(args []string, daemon bool) error {
 	defaultCfg, _ := config.DefaultPath()
 	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
 	verbose := fs.Bool("verbose", false, "enable debug logging")
+	logFormat := fs.String("log-format", "", "log line format, text or json (default: log_format in config.yaml)")
+	logFile := fs.String("log-file", "", "write the log to this file, rotating it, instead of stderr")
+	planOut := fs.String("plan-out", "", "write the bootstrap match plan to this .json or .md file before confirming")
+	opts := syncOptions{daemon: daemon}
+	fs.BoolVar(&opts.readOnly, "read-only", false, "log what would change without writing to either side")
+	fs.BoolVar(&opts.explain, "explain", false, "log the hashes, times and chosen action of every tracked item")
+	viaDaemon := false
+	if !daemon {
+		fs.BoolVar(&viaDaemon, "via-daemon", false, "ask the running daemon for the pass instead")
+		fs.BoolVar(&opts.force, "force", false, "apply deletions the deletion guard would hold back")
+		fs.StringVar(&opts.onlyList, "list", "", "sync only the mapping of this Reminders list")
+		fs.StringVar(&opts.onlyEntity, "entity", "", "sync only the mappings with this target, e.g. todo.shopping")
+		fs.StringVar(&opts.output, "output", "", "also print the result on stdout: json")
+	}
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	if opts.output != "" && opts.output != "json" {
+		return fmt.Errorf("--output %q must be json", opts.output)
+	}
+	opts.cfgPath, opts.verbose = *cfgPath, *verbose
+	opts.planOut, opts.logFormat, opts.logFile = *planOut, *logFormat, *logFile
 
-	return startSync(*cfgPath, *verbose, daemon)
+	if viaDaemon {
+		if opts.force || opts.readOnly || opts.explain {
+			return fmt.Errorf("--force, --read-only and --explain cannot be combined with --via-daemon")
+		}
+		return syncViaDaemon(opts.onlyList, opts.onlyEntity, opts.output)
+	}
+	return startSync(opts)
 }
 
 // runLegacy supports the old --daemon / --sync-once flag interface.
@@ -150,65 +331,332 @@ func runLegacy() error {
 		return fmt.Errorf("--daemon and --sync-once are mutually exclusive")
 	}
 
-	return startSync(*cfgPath, *verbose, *daemon)
+	return startSync(syncOptions{cfgPath: *cfgPath, daemon: *daemon, verbose: *verbose})
 }
 
 // runStatus prints the current daemon and configuration state.
-func runStatus() error {
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	noColor := fs.Bool("no-color", false, "disable coloured output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
 	cfgPath, _ := config.DefaultPath()
 	homeDir, _ := os.UserHomeDir()
-	dbPath, _ := state.DefaultDBPath()
+	dbPath, _ := defaultStateDBPath()
 
-	fmt.Println("ReminderRelay Status")
-	fmt.Println("────────────────────")
+	// Errors shown below come from the daemon and may quote a secret.
+	cfg, loadErr := config.Load(cfgPath)
+	redactor := configRedactor(cfg)
 
-	// Daemon state.
-	if setup.IsDaemonLoaded() {
-		fmt.Println("  Daemon:    running (launchd)")
+	out := render.New(os.Stdout, *noColor)
+	out.Heading("ReminderRelay Status")
+
+	var fields [][2]string
+	if name := profile.Name(); name != "" {
+		fields = append(fields, [2]string{"Profile", name})
+	}
+
+	// Daemon state: ask the live process first, then fall back to launchd.
+	live := liveStatus()
+	if live != nil {
+		daemon := out.Style(render.Good, "running")
+		if live.Paused {
+			daemon = out.Style(render.Warn, "paused")
+		}
+		fields = append(fields, [2]string{"Daemon", fmt.Sprintf("%s (pid %d, %s, up %s)",
+			daemon, live.PID, live.Version, time.Since(live.StartedAt).Round(time.Second))})
+		fields = append(fields, [2]string{"Last sync", redactor.String(lastSyncSummary(out, live))})
+		fields = append(fields, [2]string{"Last fetch", lastFetchSummary(out, live)})
+		if len(live.UnmappedLists) > 0 {
+			fields = append(fields, [2]string{"Unmapped", out.Style(render.Warn, strings.Join(live.UnmappedLists, ", ")) +
+				" (run 'reminderrelay add-mapping')"})
+		}
+		if len(live.Duplicates) > 0 {
+			fields = append(fields, [2]string{"Duplicates", out.Style(render.Warn, duplicatesSummary(live.Duplicates)) +
+				" (run 'reminderrelay dedupe')"})
+		}
+		for _, dir := range []string{"to_ha", "to_reminders"} {
+			if l, ok := live.Latency[dir]; ok {
+				fields = append(fields, [2]string{"Latency " + dir, latencySummary(l)})
+			}
+		}
+	} else if setup.IsDaemonLoaded() {
+		fields = append(fields, [2]string{"Daemon", out.Style(render.Warn, "loaded") + " (launchd, not answering)"})
 	} else {
-		fmt.Println("  Daemon:    not loaded")
+		fields = append(fields, [2]string{"Daemon", out.Style(render.Warn, "not loaded")})
 	}
 
+	fields = append(fields, [2]string{"Reminders", accessSummary(out, live)})
+
 	// Config state.
 	if _, err := os.Stat(cfgPath); err == nil {
-		if cfg, loadErr := config.Load(cfgPath); loadErr == nil {
-			fmt.Printf("  Config:    %s ✓\n", cfgPath)
-			fmt.Printf("  HA URL:    %s\n", cfg.HAURL)
-			fmt.Printf("  Lists:     %d mapping(s)\n", len(cfg.ListMappings))
-			fmt.Printf("  Poll:      %s\n", cfg.PollInterval)
+		if loadErr == nil {
+			fields = append(fields,
+				[2]string{"Config", cfgPath + " " + out.Style(render.Good, "✓")},
+				[2]string{"HA URL", cfg.HAURL},
+				[2]string{"Lists", listsSummary(cfg)},
+				[2]string{"Poll", cfg.PollInterval.String()},
+			)
 		} else {
-			fmt.Printf("  Config:    %s (invalid: %v)\n", cfgPath, loadErr)
+			fields = append(fields, [2]string{"Config", cfgPath + " " + out.Style(render.Bad, fmt.Sprintf("(invalid: %v)", loadErr))})
 		}
 	} else {
-		fmt.Printf("  Config:    not found (%s)\n", cfgPath)
+		fields = append(fields, [2]string{"Config", out.Style(render.Warn, "not found") + " (" + cfgPath + ")"})
 	}
 
 	// State DB.
+	var (
+		shadowLists []*state.ShadowList
+		jobRuns     []*state.JobRun
+		queued      map[string]int
+	)
 	if info, err := os.Stat(dbPath); err == nil {
-		fmt.Printf("  State DB:  %s (%s)\n", dbPath, humanSize(info.Size()))
+		fields = append(fields, [2]string{"State DB", fmt.Sprintf("%s (%s)", dbPath, humanSize(info.Size()))})
+		shadowLists, jobRuns, queued = loadDBStatus(dbPath)
+		if len(queued) > 0 {
+			fields = append(fields, [2]string{"Queued", out.Style(render.Warn, queuedSummary(queued)) +
+				" (replayed once Home Assistant is reachable)"})
+		}
 	} else {
-		fmt.Printf("  State DB:  not found\n")
+		fields = append(fields, [2]string{"State DB", out.Style(render.Warn, "not found")})
 	}
 
 	// Plist.
 	plistPath := setup.PlistPath(homeDir)
 	if _, err := os.Stat(plistPath); err == nil {
-		fmt.Printf("  Plist:     %s\n", plistPath)
+		fields = append(fields, [2]string{"Plist", plistPath})
 	} else {
-		fmt.Printf("  Plist:     not installed\n")
+		fields = append(fields, [2]string{"Plist", out.Style(render.Dim, "not installed")})
 	}
 
 	// Logs.
-	logDir := setup.LogDir(homeDir)
-	fmt.Printf("  Logs:      %s\n", logDir)
+	fields = append(fields, [2]string{"Logs", setup.LogDir(homeDir)})
+
+	// Older install layouts, which may run a second daemon.
+	if legacy, err := setup.FindLegacyInstalls(homeDir); err == nil && len(legacy) > 0 {
+		fields = append(fields, [2]string{"Leftovers", out.Style(render.Warn,
+			fmt.Sprintf("%d file(s) from an older install", len(legacy))) +
+			" (run 'reminderrelay uninstall --legacy')"})
+	}
+
+	out.Fields(fields)
+
+	if len(shadowLists) > 0 {
+		fmt.Println()
+		rows := make([][]string, 0, len(shadowLists))
+		for _, sl := range shadowLists {
+			status := out.Style(render.Warn, "shadow")
+			switch {
+			case sl.Promoted:
+				status = out.Style(render.Good, "promoted")
+			case sl.Held:
+				status = out.Style(render.Warn, "held after recovery")
+			}
+			rows = append(rows, []string{
+				sl.ListName,
+				status,
+				strconv.Itoa(sl.Passes),
+				fmt.Sprintf("+%d ~%d -%d", sl.WouldCreate, sl.WouldUpdate, sl.WouldDelete),
+			})
+		}
+		out.Table([]string{"SHADOW LIST", "STATE", "PASSES", "LAST PLAN"}, rows)
+	}
+
+	if len(jobRuns) > 0 {
+		fmt.Println()
+		rows := make([][]string, 0, len(jobRuns))
+		for _, run := range jobRuns {
+			result := out.Style(render.Good, "ok")
+			if run.LastError != "" {
+				result = out.Style(render.Bad, redactor.String(run.LastError))
+			}
+			rows = append(rows, []string{
+				run.Name,
+				run.LastRunAt.Local().Format("2006-01-02 15:04"),
+				run.Duration.Round(time.Millisecond).String(),
+				strconv.Itoa(run.Runs),
+				result,
+			})
+		}
+		out.Table([]string{"JOB", "LAST RUN", "TOOK", "RUNS", "RESULT"}, rows)
+	}
 
 	return nil
 }
 
+// listsSummary describes which lists the config syncs.
+func listsSummary(cfg *config.Config) string {
+	summary := fmt.Sprintf("%d mapping(s)", len(cfg.ListMappings))
+	if cfg.SyncAllLists {
+		summary += fmt.Sprintf(" + all other lists (%d excluded)", len(cfg.ExcludeLists))
+	}
+	return summary
+}
+
+// lastSyncSummary describes the live daemon's latest sync pass.
+func lastSyncSummary(out *render.Printer, st *control.Status) string {
+	if st.Passes == 0 {
+		return out.Style(render.Dim, "none yet")
+	}
+	s := st.LastStats
+	summary := fmt.Sprintf("%s ago (+%d ~%d -%d, %d conflicts)",
+		time.Since(st.LastPassAt).Round(time.Second), s.Created, s.Updated, s.Deleted, s.Conflicts)
+	if st.LastError != "" {
+		summary += " " + out.Style(render.Bad, st.LastError)
+	}
+	return summary
+}
+
+// lastFetchSummary describes when the live daemon last read reminders from
+// EventKit. A daemon that never did usually lacks Reminders access.
+func lastFetchSummary(out *render.Printer, st *control.Status) string {
+	if st.LastFetchAt.IsZero() {
+		return out.Style(render.Warn, "none yet")
+	}
+	return fmt.Sprintf("%s ago", time.Since(st.LastFetchAt).Round(time.Second))
+}
+
+// accessSummary describes the access to Reminders macOS grants this command
+// and, if it differs, the access of the live daemon st, which may be nil:
+// macOS grants access per app, so a daemon started by launchd may lack the
+// access a terminal has.
+func accessSummary(out *render.Printer, st *control.Status) string {
+	style := func(access string) string {
+		switch access {
+		case reminders.AuthorizationGranted.String():
+			return out.Style(render.Good, access)
+		case reminders.AuthorizationNotDetermined.String(), reminders.AuthorizationUnsupported.String():
+			return out.Style(render.Warn, access)
+		default:
+			return out.Style(render.Bad, access)
+		}
+	}
+	local := reminders.AuthorizationStatus().String()
+	summary := "access " + style(local)
+	if st != nil && st.RemindersAccess != "" && st.RemindersAccess != local {
+		summary += ", daemon " + style(st.RemindersAccess)
+	}
+	granted := reminders.AuthorizationGranted.String()
+	if local != granted || (st != nil && st.RemindersAccess != "" && st.RemindersAccess != granted) {
+		summary += " (run 'reminderrelay grant-access')"
+	}
+	return summary
+}
+
+// latencySummary describes the recent propagation latencies in one direction.
+func latencySummary(l control.Latency) string {
+	round := func(d time.Duration) time.Duration { return d.Round(100 * time.Millisecond) }
+	summary := fmt.Sprintf("p50 %s, p90 %s, p99 %s over %d change(s)",
+		round(l.P50), round(l.P90), round(l.P99), l.Samples)
+	if l.WithinObjective > 0 {
+		summary += fmt.Sprintf(", %.1f%% within objective", 100*l.WithinObjective)
+	}
+	return summary
+}
+
+// duplicatesSummary names the titles several items of a list share.
+func duplicatesSummary(dups []control.Duplicate) string {
+	parts := make([]string, 0, len(dups))
+	for _, d := range dups {
+		parts = append(parts, fmt.Sprintf("%q in %s", d.Title, d.List))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// queuedSummary formats the number of queued writes per list for status
+// output.
+func queuedSummary(queued map[string]int) string {
+	lists := slices.Sorted(maps.Keys(queued))
+	parts := make([]string, 0, len(lists))
+	for _, list := range lists {
+		parts = append(parts, fmt.Sprintf("%d write(s) in %s", queued[list], list))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// loadDBStatus reads shadow-mode progress, job runs and the number of queued
+// writes per list from the state DB. Errors are silently ignored — status
+// output is best-effort.
+func loadDBStatus(dbPath string) ([]*state.ShadowList, []*state.JobRun, map[string]int) {
+	store, err := state.Open(dbPath)
+	if err != nil {
+		return nil, nil, nil
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	lists, _ := store.GetAllShadowLists(ctx)
+	runs, _ := store.GetAllJobRuns(ctx)
+	queued, _ := store.OutboundCounts(ctx)
+	return lists, runs, queued
+}
+
+// runPromote marks a shadow-mode list mapping as promoted so the daemon
+// starts applying its changes on the next pass.
+func runPromote(args []string) error {
+	fs := flag.NewFlagSet("promote", flag.ExitOnError)
+	defaultCfg, _ := config.DefaultPath()
+	cfgPath := fs.String("config", defaultCfg, "path to config.yaml")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: reminderrelay promote [--config <path>] <list>")
+	}
+	listName := fs.Arg(0)
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config from %q: %w", *cfgPath, err)
+	}
+	if _, ok := cfg.ListMappings[listName]; !ok {
+		return fmt.Errorf("list %q is not in list_mappings", listName)
+	}
+
+	dbPath, err := stateDBPath(cfg)
+	if err != nil {
+		return err
+	}
+	store, err := state.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("opening state DB at %q: %w", dbPath, err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// A shadow list has never written any state. Link its existing items by
+	// title before promoting so the first real pass does not duplicate them.
+	ctx := context.Background()
+	rows, err := store.GetAllItemsForList(ctx, listName)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+		ran, err := bootstrapMapping(ctx, cfg, listName, cfg.ListMappings[listName], "", logger)
+		if err != nil {
+			return fmt.Errorf("bootstrapping %q: %w", listName, err)
+		}
+		if !ran {
+			fmt.Printf("%q not promoted.\n", listName)
+			return nil
+		}
+	}
+
+	if err := store.PromoteShadowList(ctx, listName); err != nil {
+		return err
+	}
+	fmt.Printf("✓ %q promoted — changes will be applied on the next sync pass.\n", listName)
+	return nil
+}
+
 // runUninstall stops the daemon and removes installed files.
 func runUninstall(args []string) error {
 	fs := flag.NewFlagSet("uninstall", flag.ExitOnError)
 	purge := fs.Bool("purge", false, "also remove config, state DB, and logs")
+	legacyOnly := fs.Bool("legacy", false, "only remove leftovers of older install layouts")
+	yes := fs.Bool("yes", false, "remove leftovers without asking")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
@@ -218,6 +666,11 @@ func runUninstall(args []string) error {
 		return fmt.Errorf("resolving home directory: %w", err)
 	}
 
+	if *legacyOnly {
+		removeLegacyInstalls(homeDir, *yes)
+		return nil
+	}
+
 	fmt.Println("Uninstalling ReminderRelay...")
 
 	// 1. Unload daemon.
@@ -237,53 +690,155 @@ func runUninstall(args []string) error {
 		fmt.Println("  ✓ Plist removed")
 	}
 
-	// 3. Remove binary.
-	fmt.Println("  Removing binary...")
-	if err := setup.RemoveBinary(); err != nil {
-		fmt.Printf("  ⚠ %v\n", err)
+	// 3. Remove binary, unless the daemon of another profile still runs it.
+	if others, err := setup.InstalledAgents(homeDir); err == nil && len(others) > 0 {
+		fmt.Printf("  Binary kept: %d other profile(s) still installed\n", len(others))
 	} else {
-		fmt.Println("  ✓ Binary removed")
+		fmt.Println("  Removing binary...")
+		if err := setup.RemoveBinary(); err != nil {
+			fmt.Printf("  ⚠ %v\n", err)
+		} else {
+			fmt.Println("  ✓ Binary removed")
+		}
 	}
 
-	// 4. Optional purge.
+	// The URL handler runs the binary, so it goes with it.
+	if _, err := os.Stat(setup.URLHandlerPath(homeDir)); err == nil {
+		if err := setup.RemoveURLHandler(homeDir); err != nil {
+			fmt.Printf("  ⚠ %v\n", err)
+		} else {
+			fmt.Println("  ✓ URL handler removed")
+		}
+	}
+
+	// 4. Optional purge. A state DB moved elsewhere is left alone: its
+	// directory may hold the user's other files.
 	if *purge {
-		fmt.Println("  Purging config, state DB, and logs...")
-		if err := setup.PurgeUserData(homeDir); err != nil {
+		dbPath, _ := defaultStateDBPath()
+		stateDir, err := state.DefaultDir()
+		if err == nil {
+			fmt.Println("  Purging config, state DB, and logs...")
+			err = setup.PurgeUserData(homeDir, stateDir)
+		}
+		if err != nil {
 			fmt.Printf("  ⚠ %v\n", err)
 		} else {
 			fmt.Println("  ✓ User data purged")
 		}
+		if dbPath != "" && filepath.Dir(dbPath) != stateDir {
+			fmt.Printf("  State DB at %s kept; remove it yourself\n", dbPath)
+		}
 	} else {
 		fmt.Println("")
 		fmt.Println("  Config and state DB preserved.")
 		fmt.Println("  Run with --purge to also remove them:")
-		fmt.Println("    reminderrelay uninstall --purge")
+		fmt.Printf("    reminderrelay%s uninstall --purge\n", profile.Flag())
 	}
 
+	removeLegacyInstalls(homeDir, *yes)
+
 	fmt.Println("")
 	fmt.Println("✓ ReminderRelay uninstalled.")
 	return nil
 }
 
+// removeLegacyInstalls lists launchd agents and binaries left behind by older
+// install layouts and removes them after confirmation. A leftover agent keeps
+// a second daemon syncing the same lists, so this runs on every uninstall.
+func removeLegacyInstalls(homeDir string, yes bool) {
+	found, err := setup.FindLegacyInstalls(homeDir)
+	if err != nil {
+		fmt.Printf("  ⚠ scanning for older installs: %v\n", err)
+		return
+	}
+	if len(found) == 0 {
+		return
+	}
+
+	fmt.Println("")
+	fmt.Println("  Found leftovers of an older install:")
+	for _, a := range found {
+		fmt.Printf("    %-14s %s\n", a.Kind, a.Path)
+	}
+	if !yes && !setup.NewPrompter(os.Stdin, os.Stdout).Confirm("Remove them?", false) {
+		fmt.Println("  Leftovers kept.")
+		return
+	}
+	if err := setup.RemoveLegacyInstalls(found); err != nil {
+		fmt.Printf("  ⚠ %v\n", err)
+		return
+	}
+	fmt.Println("  ✓ Leftovers removed")
+}
+
 // --- Sync core (shared by subcommand and legacy paths) -----------------------
 
-// startSync is the shared implementation for daemon and sync-once modes.
-func startSync(cfgPath string, verbose, daemon bool) error {
-	// --- Logger --------------------------------------------------------------
+// syncOptions are the command-line settings of a daemon or sync-once run.
+type syncOptions struct {
+	cfgPath string
+	daemon  bool
+	verbose bool
 
-	logLevel := slog.LevelInfo
-	if verbose {
-		logLevel = slog.LevelDebug
-	}
-	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
-	slog.SetDefault(logger)
+	planOut   string // file the bootstrap writes its match plan to, if set
+	logFormat string // overrides log_format, if set
+	logFile   string // written and rotated instead of stderr, if set
 
+	// force turns the deletion guard off for a sync-once pass.
+	force bool
+	// readOnly writes to neither side, as read_only in the config does.
+	readOnly bool
+	// explain logs the decision about every tracked item and its reason.
+	explain bool
+	// onlyList and onlyEntity, if set, limit a sync-once pass to the
+	// mappings chosen as by [syncp.SelectMappings]; the other lists are
+	// left alone.
+	onlyList, onlyEntity string
+	// output is the format sync-once reports its result in on stdout:
+	// "" for none beyond the log, or "json".
+	output string
+}
+
+// startSync is the shared implementation for daemon and sync-once modes. A
+// sync-once pass that fails for some items or lists only returns an
+// [exitError] with [exitItemErrors].
+func startSync(opts syncOptions) error {
 	// --- Config --------------------------------------------------------------
 
-	cfg, err := config.Load(cfgPath)
+	cfg, err := config.Load(opts.cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config from %q: %w", opts.cfgPath, err)
+	}
+
+	// --- Logger --------------------------------------------------------------
+
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		return fmt.Errorf("log_level: %w", err)
+	}
+	if opts.verbose {
+		logLevel = slog.LevelDebug
+	}
+	if opts.logFormat == "" {
+		opts.logFormat = cfg.LogFormat
+	}
+	var logOut io.Writer = os.Stderr
+	if opts.logFile != "" {
+		w, err := logfile.Open(opts.logFile, logRotation(cfg))
+		if err != nil {
+			return err
+		}
+		// Left open so the error main logs on the way out reaches it.
+		logOut = w
+	}
+	handler, err := newLogHandler(logOut, opts.logFormat, logLevel)
 	if err != nil {
-		return fmt.Errorf("loading config from %q: %w", cfgPath, err)
+		return err
 	}
+	// Secrets are scrubbed before a record reaches any destination.
+	redactor := configRedactor(cfg)
+	logger := slog.New(redactor.Handler(handler))
+	slog.SetDefault(logger)
+
 	logger.Info("config loaded",
 		"ha_url", cfg.HAURL,
 		"poll_interval", cfg.PollInterval,
@@ -294,7 +849,10 @@ func startSync(cfgPath string, verbose, daemon bool) error {
 
 	if cfg.Telemetry != nil {
 		telCfg := telemetry.Config{
+			Exporter:     cfg.Telemetry.Exporter,
+			File:         cfg.Telemetry.File,
 			OTLPEndpoint: cfg.Telemetry.OTLPEndpoint,
+			Protocol:     cfg.Telemetry.Protocol,
 			Insecure:     cfg.Telemetry.Insecure,
 			ServiceName:  cfg.Telemetry.ServiceName,
 			Headers:      cfg.Telemetry.Headers,
@@ -303,7 +861,14 @@ func startSync(cfgPath string, verbose, daemon bool) error {
 		if err != nil {
 			logger.Error("telemetry setup failed, continuing without telemetry", "error", err)
 		} else {
-			logger.Info("telemetry enabled", "endpoint", cfg.Telemetry.OTLPEndpoint)
+			if cfg.Telemetry.Exporter == telemetry.ExporterStdout {
+				logger.Info("telemetry enabled", "exporter", cfg.Telemetry.Exporter, "file", cfg.Telemetry.File)
+			} else {
+				// Logs go to the collector as well as stderr.
+				logger = slog.New(redactor.Handler(telemetry.LogHandler(handler, logLevel)))
+				slog.SetDefault(logger)
+				logger.Info("telemetry enabled", "endpoint", cfg.Telemetry.OTLPEndpoint, "protocol", cfg.Telemetry.Protocol)
+			}
 			defer func() {
 				flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 				defer cancel()
@@ -316,13 +881,24 @@ func startSync(cfgPath string, verbose, daemon bool) error {
 
 	// --- State DB ------------------------------------------------------------
 
-	dbPath, err := state.DefaultDBPath()
+	dbPath, err := stateDBPath(cfg)
 	if err != nil {
-		return fmt.Errorf("resolving state DB path: %w", err)
+		return err
+	}
+	// A second process syncing the same lists would create every new item
+	// twice.
+	release, err := state.Lock(dbPath)
+	var locked *state.LockedError
+	if errors.As(err, &locked) {
+		return alreadyRunningError(locked)
 	}
-	store, err := state.Open(dbPath)
 	if err != nil {
-		return fmt.Errorf("opening state DB at %q: %w", dbPath, err)
+		return err
+	}
+	defer release()
+	store, recovery, err := openSyncStore(context.Background(), dbPath, cfg, logger)
+	if err != nil {
+		return err
 	}
 	defer func() {
 		if closeErr := store.Close(); closeErr != nil {
@@ -330,21 +906,61 @@ func startSync(cfgPath string, verbose, daemon bool) error {
 		}
 	}()
 	logger.Info("state DB opened", "path", dbPath)
+	backupDir := state.BackupDir(dbPath)
+	if opts.daemon && backupsEnabled(cfg) {
+		if path, err := store.Backup(context.Background(), backupDir, backupsKept(cfg)); err != nil {
+			logger.Error("backing up state DB at startup", "error", err)
+		} else {
+			logger.Info("state DB backed up", "path", path)
+		}
+	}
+
+	// Lists held back by an earlier state rebuild run in shadow mode on top
+	// of the configured ones.
+	shadowLists, shadowPasses, shadowAutoPromote := []string(nil), 3, false
+	if cfg.Shadow != nil {
+		shadowLists = append(shadowLists, cfg.Shadow.Lists...)
+		shadowPasses, shadowAutoPromote = cfg.Shadow.Passes, cfg.Shadow.AutoPromote
+	}
+	held, err := store.HeldLists(context.Background())
+	if err != nil {
+		return err
+	}
+	shadowLists = append(shadowLists, held...)
 
 	// --- Reminders adapter ---------------------------------------------------
 
 	logger.Info("initialising Apple Reminders client (may trigger permissions prompt)…")
-	remAdapter, err := reminders.NewAdapter(logger)
-	if err != nil && strings.Contains(err.Error(), "access denied") {
-		// macOS has denied Reminders access (TCC). Open System Settings to the
-		// correct privacy page so the user can flip the switch, then retry once.
-		fmt.Fprintln(os.Stderr, "")
-		fmt.Fprintln(os.Stderr, "⚠️  Reminders access is denied.")
-		fmt.Fprintln(os.Stderr, "   Opening System Settings → Privacy & Security → Reminders…")
-		_ = exec.Command("open", "x-apple.systempreferences:com.apple.preference.security?Privacy_Reminders").Start()
-		fmt.Fprint(os.Stderr, "   Press Enter after granting access to retry: ")
-		_, _ = bufio.NewReader(os.Stdin).ReadString('\n')
-		remAdapter, err = reminders.NewAdapter(logger)
+	var (
+		remOpts []reminders.AdapterOption
+		haOpts  []homeassistant.AdapterOption
+	)
+	if cfg.Cache != nil {
+		maxAge := cfg.Cache.MaxAge
+		if cfg.Cache.Disabled {
+			maxAge = 0
+		}
+		remOpts = append(remOpts, reminders.WithCacheMaxAge(maxAge))
+		if cfg.Cache.HA {
+			haOpts = append(haOpts, homeassistant.WithCacheMaxAge(maxAge))
+		}
+	}
+	// Nobody answers a permissions dialog shown to the daemon, so it only
+	// starts with access already granted.
+	interactive := !opts.daemon && stdinIsTerminal()
+	var remAdapter *reminders.Adapter
+	err = reminders.CheckAccess(interactive)
+	if err == nil {
+		remAdapter, err = newRemindersAdapter(cfg, logger, remOpts...)
+	}
+	if errors.Is(err, model.ErrAccessDenied) {
+		if !interactive {
+			notifyNoAccess(cfg, err, logger)
+			return &exitError{code: exitNoAccess, err: fmt.Errorf("%w\n\nRun 'reminderrelay grant-access' in a terminal", err)}
+		}
+		if err = grantAccess(context.Background(), 5*time.Minute); err == nil {
+			remAdapter, err = newRemindersAdapter(cfg, logger, remOpts...)
+		}
 	}
 	if err != nil {
 		return fmt.Errorf("initialising Reminders client: %w", err)
@@ -353,7 +969,7 @@ func startSync(cfgPath string, verbose, daemon bool) error {
 
 	// --- Home Assistant adapter & connectivity check -------------------------
 
-	haAdapter, err := homeassistant.NewAdapter(cfg.HAURL, cfg.HAToken, logger)
+	haAdapter, err := newHAAdapter(cfg, logger, haOpts...)
 	if err != nil {
 		return fmt.Errorf("initialising Home Assistant client: %w", err)
 	}
@@ -366,24 +982,209 @@ func startSync(cfgPath string, verbose, daemon bool) error {
 		return fmt.Errorf("connecting to Home Assistant at %q: %w\n\nCheck ha_url and ha_token in your config file", cfg.HAURL, err)
 	}
 	logger.Info("Home Assistant reachable")
+	if cfg.Notify != nil && cfg.Notify.HAPersistent {
+		// Reminders access may have been missing at the last start.
+		_ = haAdapter.DismissNotification(ctx, accessNotificationID)
+	}
 
-	// --- First-run bootstrap -------------------------------------------------
+	remLists := reminders.NewBackend(remAdapter)
+	remBackend := withChaos("reminders", remLists, logger)
+	if cfg.SyncAllLists {
+		names, err := remLists.Lists(ctx)
+		if err == nil {
+			names, err = priorNames(ctx, store, remLists, names)
+		}
+		if err != nil {
+			return fmt.Errorf("sync_all_lists: %w", err)
+		}
+		if cfg.ListMappings, err = expandAllLists(ctx, cfg, names, logger); err != nil {
+			return err
+		}
+		logger.Info("sync_all_lists: syncing every Reminders list", "lists", len(cfg.ListMappings))
+	}
+	selected, targeted := cfg.ListMappings, opts.onlyList != "" || opts.onlyEntity != ""
+	if targeted {
+		selected = syncp.SelectMappings(cfg.ListMappings, opts.onlyList, opts.onlyEntity)
+		if len(selected) == 0 {
+			return fmt.Errorf("no list mapping matches --list %q --entity %q", opts.onlyList, opts.onlyEntity)
+		}
+	}
+	warnOrphanedLists(ctx, store, cfg, logger)
+	readOnly := cfg.ReadOnly || opts.readOnly
+	if readOnly {
+		logger.Warn("read-only mode: passes are logged but nothing is written to Reminders or Home Assistant")
+	}
+	var (
+		confirmCreate func(string) bool
+		prompter      *setup.Prompter // nil unless asking is possible
+	)
+	switch {
+	case readOnly:
+		confirmCreate = func(string) bool { return false }
+	case !opts.daemon && stdinIsTerminal():
+		prompter = setup.NewPrompter(os.Stdin, os.Stdout)
+		confirmCreate = func(prompt string) bool { return prompter.Confirm(prompt, true) }
+	}
+	if err := checkAccounts(ctx, store, remLists, prompter, logger); err != nil {
+		return err
+	}
+	ensureMappedEntities(ctx, opts.cfgPath, cfg, store, confirmCreate, logger)
+	if err := haAdapter.ProbeCapabilities(ctx, haEntities(cfg.ListMappings)); err != nil {
+		logger.Warn("could not read todo features of every HA entity, assuming full support", "error", err)
+	}
+	if targeted {
+		// ensureMappedEntities may have dropped or re-pointed mappings.
+		selected = syncp.SelectMappings(cfg.ListMappings, opts.onlyList, opts.onlyEntity)
+	}
+	if err := preflight(ctx, cfg, selected, store, remLists, haAdapter); err != nil {
+		return err
+	}
+	targets, err := syncTargets(cfg, haAdapter, logger)
+	if err != nil {
+		return err
+	}
+
+	// --- Bootstrap (first run and new mappings) ------------------------------
+
+	var notifiers []syncp.ConflictNotifier // for notices outside the sync passes
+	if cfg.Notify != nil && cfg.Notify.HAPersistent {
+		notifiers = append(notifiers, haAdapter)
+	}
+	var macNotifier *desktop.Notifier
+	if cfg.Notify != nil && cfg.Notify.MacOS {
+		macNotifier = desktop.NewNotifier(cfg.Notify.MacOSMinInterval, logger)
+		notifiers = append(notifiers, macNotifier)
+	}
 
-	bootstrap := syncp.NewBootstrap(remAdapter, haAdapter, store, logger, os.Stdin, os.Stdout)
-	if _, err := bootstrap.Run(ctx, cfg.ListMappings); err != nil {
-		return fmt.Errorf("first-run bootstrap: %w", err)
+	bootstrapOpts := append(bootstrapOptions(cfg, opts.planOut), syncp.WithShadowLists(shadowLists))
+	if readOnly {
+		bootstrapOpts = append(bootstrapOpts, syncp.WithSummaryOnly())
+	}
+	bootstrap := syncp.NewBootstrap(remBackend, targets, store, logger, os.Stdin, os.Stdout, bootstrapOpts...)
+	if recovery != nil {
+		finishRecovery(ctx, recovery, bootstrap, cfg, notifiers, logger)
+	}
+	if _, err := bootstrap.Run(ctx, selected); err != nil {
+		return fmt.Errorf("bootstrap: %w", err)
 	}
 
 	// --- Sync engine ---------------------------------------------------------
 
-	reconciler := syncp.NewReconciler(remAdapter, haAdapter, store, logger)
-	engine := syncp.NewEngine(reconciler, haAdapter, cfg.ListMappings, cfg.PollInterval, logger)
+	reconcilerOpts := []syncp.ReconcilerOption{
+		syncp.WithParallelism(cfg.ParallelLists),
+		syncp.WithPassTimeout(cfg.PassTimeout),
+		syncp.WithShutdownGrace(cfg.ShutdownGrace),
+		syncp.WithNormalization(titleNormalization(cfg)),
+		syncp.WithIgnoreMarkers(cfg.IgnoreMarkers),
+		syncp.WithShorthand(shorthandLists(cfg), cfg.Location()),
+	}
+	if len(shadowLists) > 0 {
+		reconcilerOpts = append(reconcilerOpts,
+			syncp.WithShadow(shadowLists, shadowPasses, shadowAutoPromote))
+		logger.Info("shadow mode enabled", "lists", shadowLists, "passes", shadowPasses)
+	}
+
+	if len(cfg.Quirks) > 0 {
+		quirks, err := mappingQuirks(cfg)
+		if err != nil {
+			return err
+		}
+		reconcilerOpts = append(reconcilerOpts, syncp.WithQuirks(quirks))
+		logger.Info("quirks presets enabled", "lists", cfg.Quirks)
+	}
+
+	if len(cfg.Mirrors) > 0 {
+		reconcilerOpts = append(reconcilerOpts, syncp.WithMirrors(cfg.Mirrors, func(target string) syncp.StateStore {
+			return store.ForInstance("mirror:" + target)
+		}))
+		logger.Info("mirrors enabled", "lists", cfg.Mirrors)
+	}
+
+	switch {
+	case opts.force:
+		logger.Warn("deletion guard off for this pass (--force)")
+	case cfg.DeletionGuard == nil:
+		reconcilerOpts = append(reconcilerOpts, syncp.WithDeletionGuard(0, 0))
+	case !cfg.DeletionGuard.Disabled:
+		reconcilerOpts = append(reconcilerOpts,
+			syncp.WithDeletionGuard(cfg.DeletionGuard.MaxItems, cfg.DeletionGuard.MaxPercent))
+	}
+
+	if readOnly {
+		reconcilerOpts = append(reconcilerOpts, syncp.WithReadOnly())
+	}
+	if opts.explain {
+		reconcilerOpts = append(reconcilerOpts, syncp.WithExplain())
+	}
+
+	reconciler := syncp.NewReconciler(remBackend, targets, store, logger, reconcilerOpts...)
+	var engineOpts []syncp.EngineOption
+	if cfg.Notify != nil && cfg.Notify.HAPersistent {
+		engineOpts = append(engineOpts, syncp.WithProblemNotifier(haAdapter, cfg.Notify.FailureThreshold))
+	}
+	if cfg.Notify != nil && cfg.Notify.Conflicts {
+		engineOpts = append(engineOpts, syncp.WithConflictNotifier(haAdapter))
+	}
+	if macNotifier != nil {
+		engineOpts = append(engineOpts,
+			syncp.WithProblemNotifier(macNotifier, cfg.Notify.FailureThreshold),
+			syncp.WithConflictNotifier(macNotifier))
+	}
+	if cfg.LatencyObjective > 0 {
+		engineOpts = append(engineOpts, syncp.WithLatencyObjective(cfg.LatencyObjective))
+	}
+	if cfg.Backups != nil && cfg.Backups.BeforeEachPass && backupsEnabled(cfg) {
+		keep := cfg.Backups.Keep
+		engineOpts = append(engineOpts, syncp.WithPassBackup(func(ctx context.Context) error {
+			_, err := store.Backup(ctx, backupDir, keep)
+			return err
+		}))
+	}
+	var (
+		ignoreLists []string
+		provisioner syncp.ListProvisioner
+	)
+	if cfg.Discovery != nil {
+		ignoreLists = cfg.Discovery.Ignore
+		if cfg.Discovery.AutoCreate {
+			provisioner = &localTodoProvisioner{cfgPath: opts.cfgPath, cfg: cfg, store: store}
+		}
+	}
+	if cfg.SyncAllLists {
+		ignoreLists = append(ignoreLists, cfg.ExcludeLists...)
+		provisioner = &localTodoProvisioner{cfgPath: opts.cfgPath, cfg: cfg, store: store, derived: true}
+	}
+	if readOnly {
+		provisioner = nil // creating an HA list is a write too
+	}
+	if opts.daemon && remAdapter.NotifiesChanges() {
+		// CalDAV servers push nothing, so their lists keep the short poll.
+		safetyPoll := cfg.SafetyPollInterval
+		for _, target := range cfg.ListMappings {
+			if server, _ := syncp.SplitTarget(target); server != "" {
+				safetyPoll = cfg.PollInterval
+				break
+			}
+		}
+		engineOpts = append(engineOpts, syncp.WithRemindersWatcher(remAdapter, safetyPoll))
+		logger.Info("reacting to Reminders changes as they happen", "safety_poll_interval", safetyPoll)
+	}
+	engineOpts = append(engineOpts,
+		syncp.WithListTracking(remLists, store, configRenamer{cfgPath: opts.cfgPath}),
+		syncp.WithListDiscovery(remLists, ignoreLists, provisioner))
+	engine := syncp.NewEngine(reconciler, withChaosConn(haAdapter, logger), cfg.ListMappings, cfg.PollInterval, logger, engineOpts...)
 
 	// --- Dispatch mode -------------------------------------------------------
 
-	if !daemon {
-		logger.Info("running single sync pass")
-		stats, err := engine.RunOnce(ctx)
+	if !opts.daemon {
+		var stats syncp.Stats
+		if targeted {
+			logger.Info("running single sync pass over selected lists", "lists", slices.Sorted(maps.Keys(selected)))
+			stats, err = engine.SyncSelected(ctx, opts.onlyList, opts.onlyEntity)
+		} else {
+			logger.Info("running single sync pass")
+			stats, err = engine.RunOnce(ctx)
+		}
 		logger.Info("sync complete",
 			"created", stats.Created,
 			"updated", stats.Updated,
@@ -391,18 +1192,143 @@ func startSync(cfgPath string, verbose, daemon bool) error {
 			"conflicts", stats.Conflicts,
 			"errors", stats.Errors,
 		)
-		return err
+		if opts.output == "json" {
+			if err := printSyncResult(control.StatsFrom(stats), err); err != nil {
+				return err
+			}
+		}
+		return passError(err, stats.Errors > 0 || len(stats.ListErrors) > 0)
 	}
 
 	// daemon mode
-	logger.Info("daemon starting", "poll_interval", cfg.PollInterval)
-	if err := engine.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+	socketPath, err := control.DefaultSocketPath()
+	if err != nil {
+		return fmt.Errorf("resolving control socket path: %w", err)
+	}
+	ln, err := control.Listen(socketPath)
+	switch {
+	case errors.Is(err, control.ErrAlreadyRunning):
+		return fmt.Errorf("control socket %q: %w", socketPath, err)
+	case err != nil:
+		logger.Error("control socket unavailable, CLI commands cannot reach the daemon", "error", err)
+	default:
+		srv := control.NewServer(engine, version, logger, control.WithRemindersAccess(
+			func() string { return reminders.AuthorizationStatus().String() }, remAdapter.LastFetch))
+		go func() {
+			if err := srv.Serve(ctx, ln); err != nil {
+				logger.Error("control socket stopped", "error", err)
+			}
+		}()
+	}
+
+	if cfg.DashboardListen != "" {
+		if host, _, _ := net.SplitHostPort(cfg.DashboardListen); !config.IsLoopbackHost(host) {
+			logger.Warn("dashboard is served to the network without a login (dashboard_allow_lan)",
+				"listen", cfg.DashboardListen)
+		}
+		dash := dashboard.NewServer(engine, store, cfg.ListMappings, logger)
+		go func() {
+			if err := dash.Serve(ctx, cfg.DashboardListen); err != nil {
+				logger.Error("dashboard stopped", "error", err)
+			}
+		}()
+	}
+
+	jobs, err := auxiliaryJobs(cfg, store, backupDir)
+	if err != nil {
+		return err
+	}
+	sched := scheduler.New(store, logger)
+	for _, job := range jobs {
+		sched.Add(job)
+	}
+	go func() { _ = sched.Run(ctx) }() // returns only once ctx is cancelled
+
+	logger.Info("daemon starting", "poll_interval", cfg.PollInterval, "jobs", len(jobs))
+	err = engine.Run(ctx)
+	if state.IsCorrupt(err) {
+		// Recover on disk now so the restarted daemon (launchd's KeepAlive)
+		// finds a usable database; the current store can't be swapped out
+		// under the running engine.
+		logger.Error("state DB corrupted while running, recovering", "error", err)
+		_ = store.Close()
+		rec, recErr := recoverStateDB(ctx, dbPath, cfg)
+		if recErr != nil {
+			return fmt.Errorf("%w (recovery failed: %v)", err, recErr)
+		}
+		recovered, openErr := state.Open(dbPath)
+		if openErr != nil {
+			return fmt.Errorf("opening recovered state DB at %q: %w", dbPath, openErr)
+		}
+		defer func() { _ = recovered.Close() }()
+		bootstrap := syncp.NewBootstrap(remBackend, targets, recovered, logger, os.Stdin, os.Stdout)
+		finishRecovery(ctx, rec, bootstrap, cfg, notifiers, logger)
+		return fmt.Errorf("state DB was corrupted and has been recovered; restart the daemon to resume: %w", err)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
 		return fmt.Errorf("sync engine: %w", err)
 	}
 	logger.Info("shutdown complete")
 	return nil
 }
 
+// alreadyRunningError explains a state directory locked by another process,
+// naming launchd when the installed daemon job is loaded.
+func alreadyRunningError(locked *state.LockedError) error {
+	if setup.IsDaemonLoaded() {
+		home, _ := os.UserHomeDir()
+		return fmt.Errorf("daemon already running via launchd (%w); stop it with `launchctl unload %s` before running it by hand",
+			locked, setup.PlistPath(home))
+	}
+	return fmt.Errorf("another reminderrelay daemon or sync-once is already running (%w)", locked)
+}
+
+// bootstrapOptions returns the bootstrap options for the bootstrap block and
+// ignore markers of cfg and, if planOut is not empty, for writing the match
+// plan to it.
+func bootstrapOptions(cfg *config.Config, planOut string) []syncp.BootstrapOption {
+	opts := []syncp.BootstrapOption{syncp.WithBootstrapIgnoreMarkers(cfg.IgnoreMarkers)}
+	if cfg.Bootstrap != nil && cfg.Bootstrap.FuzzyMatch {
+		opts = append(opts, syncp.WithFuzzyMatch(cfg.Bootstrap.FuzzyThreshold))
+	}
+	if cfg.Bootstrap != nil && cfg.Bootstrap.OnMismatch != "" {
+		opts = append(opts, syncp.WithMismatchPolicy(syncp.MismatchPolicy(cfg.Bootstrap.OnMismatch)))
+	}
+	if cfg.Bootstrap != nil && cfg.Bootstrap.OnCompletionMismatch != "" {
+		opts = append(opts, syncp.WithCompletionPolicy(syncp.CompletionPolicy(cfg.Bootstrap.OnCompletionMismatch)))
+	}
+	if planOut != "" {
+		opts = append(opts, syncp.WithPlanOut(planOut))
+	}
+	return opts
+}
+
+// shorthandLists returns the Reminders lists parse_shorthand turns on.
+func shorthandLists(cfg *config.Config) []string {
+	var lists []string
+	for list, on := range cfg.ParseShorthand {
+		if on {
+			lists = append(lists, list)
+		}
+	}
+	return lists
+}
+
+// titleNormalization returns the title normalisation the normalize_titles
+// block of cfg selects, none when it is omitted.
+func titleNormalization(cfg *config.Config) syncp.Normalization {
+	n := cfg.NormalizeTitles
+	if n == nil {
+		return syncp.Normalization{}
+	}
+	return syncp.Normalization{
+		Trim:          n.Trim,
+		CollapseSpace: n.CollapseWhitespace,
+		NFC:           n.NFC,
+		Punctuation:   n.Punctuation,
+	}
+}
+
 // humanSize returns a human-readable file size string.
 func humanSize(bytes int64) string {
 	const unit = 1024
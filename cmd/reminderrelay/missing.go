@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+	"github.com/njoerd114/reminderrelay/internal/state"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// ensureMappedEntities looks for list mappings whose Home Assistant entity
+// does not exist and creates a Local To-do list named after the Reminders
+// list for each, if confirm agrees. A nil confirm creates them only with
+// discovery.auto_create set. A mapping left without an entity is dropped
+// from this run, as every reconcile of it would fail; a list that replaces
+// the mapped entity under another entity ID is saved to the config file.
+func ensureMappedEntities(ctx context.Context, cfgPath string, cfg *config.Config, store *state.Store, confirm func(prompt string) bool, logger *slog.Logger) {
+	if len(haEntities(cfg.ListMappings)) == 0 {
+		return
+	}
+	entities, err := setup.DiscoverHATodoEntities(ctx, cfg.HAURL, cfg.HAToken)
+	if err != nil {
+		logger.Warn("could not check that mapped HA entities exist", "error", err)
+		return
+	}
+	if len(entities) == 0 {
+		// HA lists no todo entities at all while it is still starting up;
+		// creating lists then would duplicate every one of them.
+		logger.Warn("Home Assistant reports no todo entities; not checking list mappings")
+		return
+	}
+	exists := make(map[string]bool, len(entities))
+	byName := make(map[string]string, len(entities))
+	for _, e := range entities {
+		exists[e.EntityID] = true
+		byName[e.FriendlyName] = e.EntityID
+	}
+
+	autoCreate := cfg.Discovery != nil && cfg.Discovery.AutoCreate
+	for list, target := range cfg.ListMappings {
+		if name, _ := syncp.SplitTarget(target); name != "" || exists[target] {
+			continue
+		}
+		if other := byName[list]; other != "" {
+			logger.Warn("mapped HA entity does not exist, but another list has the same name; not syncing this list",
+				"list", list, "entity_id", target, "hint", fmt.Sprintf("point list_mappings[%q] at %s", list, other))
+			delete(cfg.ListMappings, list)
+			continue
+		}
+		create := autoCreate
+		if confirm != nil {
+			create = confirm(fmt.Sprintf("Home Assistant has no %s. Create a Local To-do list called %q for it?", target, list))
+		}
+		if !create {
+			logger.Warn("mapped HA entity does not exist; not syncing this list",
+				"list", list, "entity_id", target)
+			delete(cfg.ListMappings, list)
+			continue
+		}
+
+		// Rows left from the vanished entity would make the reconciler
+		// treat every reminder as deleted in the new, empty list.
+		if _, err := store.DeleteList(ctx, list); err != nil {
+			logger.Error("clearing state rows of list", "list", list, "error", err)
+			delete(cfg.ListMappings, list)
+			continue
+		}
+		created, err := setup.CreateLocalTodo(ctx, cfg.HAURL, cfg.HAToken, list)
+		if err != nil {
+			logger.Warn("could not create a Home Assistant list; not syncing this list",
+				"list", list, "entity_id", target, "error", err)
+			delete(cfg.ListMappings, list)
+			continue
+		}
+		logger.Info("created Home Assistant list for mapping", "list", list, "entity_id", created)
+		if created == target {
+			continue
+		}
+		cfg.ListMappings[list] = created
+		if err := config.SetListMapping(cfgPath, list, created); err != nil {
+			logger.Warn("created list has another entity ID; update list_mappings yourself",
+				"list", list, "entity_id", created, "error", err)
+		}
+	}
+}
+
+// stdinIsTerminal reports whether standard input is a terminal, so that the
+// user can be asked questions.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/homeassistant"
+	"github.com/njoerd114/reminderrelay/internal/reminders"
+	"github.com/njoerd114/reminderrelay/internal/setup"
+	"github.com/njoerd114/reminderrelay/internal/state"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// preflight checks that every Reminders list of mappings exists and that every
+// Home Assistant entity they map to answers get_items, before the first pass
+// would fail on them one item at a time. All problems are reported in one
+// error, each with the closest existing name as a suggestion. A mapped list
+// that was renamed since the last run is not a problem: the engine follows
+// the rename on its first pass.
+func preflight(ctx context.Context, cfg *config.Config, mappings map[string]string, store *state.Store, remLists *reminders.Backend, haAdapter *homeassistant.Adapter) error {
+	current, err := remLists.ListIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("listing Reminders lists: %w", err)
+	}
+	known, err := store.ListIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("reading list identifiers: %w", err)
+	}
+	names := make([]string, 0, len(current))
+	exists := make(map[string]bool, len(current))
+	for _, name := range current {
+		names = append(names, name)
+		exists[name] = true
+	}
+
+	var problems []string
+	for _, list := range slices.Sorted(maps.Keys(mappings)) {
+		if exists[list] {
+			continue
+		}
+		if id, ok := known[list]; ok && current[id] != "" {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("Reminders list %q does not exist%s", list, suggestion(list, names)))
+	}
+
+	var failed []string
+	failures := make(map[string]error)
+	for _, entityID := range haEntities(mappings) {
+		if _, err := haAdapter.GetItems(ctx, entityID); err != nil {
+			failed = append(failed, entityID)
+			failures[entityID] = err
+		}
+	}
+	if len(failed) > 0 {
+		// Suggestions are only worth a discovery call when something failed.
+		var entityIDs []string
+		if entities, err := setup.DiscoverHATodoEntities(ctx, cfg.HAURL, cfg.HAToken); err == nil {
+			for _, e := range entities {
+				entityIDs = append(entityIDs, e.EntityID)
+			}
+		}
+		for _, entityID := range failed {
+			others := slices.DeleteFunc(slices.Clone(entityIDs), func(id string) bool { return id == entityID })
+			problems = append(problems, fmt.Sprintf("HA entity %s does not answer get_items: %v%s",
+				entityID, failures[entityID], suggestion(entityID, others)))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("checking list mappings found %d problem(s):\n  - %s\n\nFix list_mappings in your config file",
+		len(problems), strings.Join(problems, "\n  - "))
+}
+
+// suggestion returns a "did you mean" hint naming the candidate closest to
+// name, or "" when none is close.
+func suggestion(name string, candidates []string) string {
+	if closest, ok := syncp.ClosestTitle(name, candidates); ok {
+		return fmt.Sprintf(" (did you mean %q?)", closest)
+	}
+	return ""
+}
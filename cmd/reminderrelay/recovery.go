@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/state"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// recoveryNotificationID identifies the notification about a recovered state
+// DB, so a repeat replaces it instead of stacking up.
+const recoveryNotificationID = "reminderrelay_state_recovered"
+
+// openSyncStore opens the state DB for syncing. A corrupted database is
+// replaced via [recoverStateDB]; the returned recovery is then non-nil and
+// must be passed to [finishRecovery] once the adapters are ready.
+func openSyncStore(ctx context.Context, dbPath string, cfg *config.Config, logger *slog.Logger) (*state.Store, *state.Recovery, error) {
+	store, err := state.Open(dbPath)
+	if err == nil {
+		if err = store.Check(ctx); err != nil {
+			_ = store.Close()
+		}
+	}
+	if err == nil {
+		return store, nil, nil
+	}
+	if !state.IsCorrupt(err) {
+		return nil, nil, fmt.Errorf("opening state DB at %q: %w", dbPath, err)
+	}
+
+	logger.Error("state DB is corrupted, recovering", "path", dbPath, "error", err)
+	rec, err := recoverStateDB(ctx, dbPath, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	store, err = state.Open(dbPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening recovered state DB at %q: %w", dbPath, err)
+	}
+	return store, rec, nil
+}
+
+// recoverStateDB replaces the corrupted state DB at dbPath with the newest
+// backup if backups are enabled, or rebuilds it with every mapped list held
+// in shadow mode.
+func recoverStateDB(ctx context.Context, dbPath string, cfg *config.Config) (*state.Recovery, error) {
+	var backupDir string
+	if backupsEnabled(cfg) {
+		backupDir = state.BackupDir(dbPath)
+	}
+	lists := make([]string, 0, len(cfg.ListMappings))
+	for name := range cfg.ListMappings {
+		lists = append(lists, name)
+	}
+
+	rec, err := state.Recover(ctx, dbPath, backupDir, lists)
+	if err != nil {
+		return nil, fmt.Errorf("recovering state DB: %w", err)
+	}
+	return rec, nil
+}
+
+// finishRecovery links items synced since a restored backup was taken, so
+// they are not duplicated, and tells the user what happened through the log
+// and every configured notifier.
+func finishRecovery(ctx context.Context, rec *state.Recovery, bootstrap *syncp.Bootstrap, cfg *config.Config,
+	notifiers []syncp.ConflictNotifier, logger *slog.Logger,
+) {
+	var linked int
+	if rec.RestoredFrom != "" {
+		n, err := bootstrap.LinkUntracked(ctx, cfg.ListMappings)
+		if err != nil {
+			logger.Error("re-linking items after restoring backup", "error", err)
+		}
+		linked = n
+	}
+
+	title, message := recoveryMessage(rec, linked)
+	logger.Error(title, "details", message)
+	for _, n := range notifiers {
+		if err := n.CreateNotification(ctx, recoveryNotificationID, title, message); err != nil {
+			logger.Error("posting recovery notification", "error", err)
+		}
+	}
+}
+
+// recoveryMessage describes rec with the next steps for the user.
+func recoveryMessage(rec *state.Recovery, linked int) (title, message string) {
+	if rec.RestoredFrom != "" {
+		return "ReminderRelay restored its state from a backup",
+			fmt.Sprintf("The sync state database was corrupted. ReminderRelay restored the backup from %s "+
+				"and re-linked %d item(s) synced since then, so syncing continues normally.\n\n"+
+				"If an item now appears twice, delete the extra copy. The damaged database was kept at `%s`.",
+				rec.BackupTime.Local().Format("2006-01-02 15:04"), linked, rec.CorruptPath)
+	}
+
+	lists := append([]string(nil), rec.HeldLists...)
+	sort.Strings(lists)
+	return "ReminderRelay paused syncing after losing its state",
+		fmt.Sprintf("The sync state database was corrupted and no usable backup was found, so ReminderRelay "+
+			"started over with an empty state. To avoid duplicating items, these lists only plan changes "+
+			"until you promote them: **%s**.\n\n"+
+			"Run `reminderrelay status` to review the plan, then `reminderrelay promote <list>` to link "+
+			"the existing items and resume syncing. The damaged database was kept at `%s`.",
+			strings.Join(lists, "**, **"), rec.CorruptPath)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"slices"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/reminders"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// configRenamer records list renames followed by the engine in the config
+// file.
+type configRenamer struct {
+	cfgPath string
+}
+
+// RenameMapping renames the mapping of oldName in list_mappings. A mapping
+// derived by sync_all_lists is not in the file; it is added under newName so
+// the list keeps its target rather than getting a new one at the next start.
+func (r configRenamer) RenameMapping(_ context.Context, oldName, newName, target string) error {
+	if _, err := config.GetValue(r.cfgPath, "list_mappings."+oldName); err != nil {
+		return config.AddListMapping(r.cfgPath, newName, target)
+	}
+	return config.RenameListMapping(r.cfgPath, oldName, newName)
+}
+
+// priorNames returns names with every Reminders list renamed while the
+// daemon was stopped under the name the state DB knows it by. sync_all_lists
+// then maps it to the entity it synced with before, and the engine follows
+// the rename on its first pass.
+func priorNames(ctx context.Context, store *state.Store, remLists *reminders.Backend, names []string) ([]string, error) {
+	current, err := remLists.ListIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known, err := store.ListIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := slices.Clone(names)
+	for old, id := range known {
+		renamed, ok := current[id]
+		if !ok || renamed == old || slices.Contains(names, old) {
+			continue
+		}
+		if i := slices.Index(out, renamed); i >= 0 {
+			out[i] = old
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// stateDBFlag is the value of the --state-db flag, which applies to every
+// subcommand; empty if not given.
+var stateDBFlag string
+
+// stateDBFromArgs sets [stateDBFlag] from a --state-db flag anywhere in
+// args, before any "--", and returns args without it.
+func stateDBFromArgs(args []string) ([]string, error) {
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		flagName, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || flagName != "state-db" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, fmt.Errorf("flag --state-db needs a path")
+			}
+			i++
+			value = args[i]
+		}
+		abs, err := filepath.Abs(value)
+		if err != nil {
+			return nil, fmt.Errorf("flag --state-db: %w", err)
+		}
+		stateDBFlag = abs
+	}
+	return rest, nil
+}
+
+// stateDBPath returns the path of the state DB: the --state-db flag if
+// given, else the state_db setting of cfg, else the default path. cfg may be
+// nil.
+func stateDBPath(cfg *config.Config) (string, error) {
+	if stateDBFlag != "" {
+		return stateDBFlag, nil
+	}
+	if cfg != nil && cfg.StateDB != "" {
+		return cfg.StateDB, nil
+	}
+	path, err := state.DefaultDBPath()
+	if err != nil {
+		return "", fmt.Errorf("resolving state DB path: %w", err)
+	}
+	return path, nil
+}
+
+// defaultStateDBPath is [stateDBPath] for commands without a --config flag:
+// it honours the state_db setting of the config at its default path, if
+// that loads.
+func defaultStateDBPath() (string, error) {
+	var cfg *config.Config
+	if cfgPath, err := config.DefaultPath(); err == nil {
+		cfg, _ = config.Load(cfgPath)
+	}
+	return stateDBPath(cfg)
+}
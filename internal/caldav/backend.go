@@ -0,0 +1,157 @@
+package caldav
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// Backend exposes the task calendars of a [Client] as a sync task backend,
+// with calendar names (the last path segment of the calendar URL) as list
+// identifiers. Create one with [NewBackend].
+//
+// Writes are conditional on the ETag seen by the last Fetch, so an item
+// edited on the server in between is never overwritten; the next pass
+// picks up the newer version instead.
+type Backend struct {
+	client *Client
+	clock  clock.Clock
+	loc    *time.Location // see WithTimeZone
+
+	mu        sync.Mutex
+	resources map[string]map[string]resource // calendar → UID → last fetched
+}
+
+// BackendOption configures optional Backend behaviour.
+type BackendOption func(*Backend)
+
+// WithClock replaces the clock used to stamp written tasks. Intended for
+// tests.
+func WithClock(c clock.Clock) BackendOption {
+	return func(b *Backend) { b.clock = c }
+}
+
+// WithTimeZone sets the zone all-day due dates are read and written in.
+// Defaults to the system's local zone.
+func WithTimeZone(loc *time.Location) BackendOption {
+	return func(b *Backend) { b.loc = loc }
+}
+
+// NewBackend creates a Backend for client.
+func NewBackend(client *Client, opts ...BackendOption) *Backend {
+	b := &Backend{client: client, clock: clock.Real(), loc: time.Local, resources: make(map[string]map[string]resource)}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Fetch returns the tasks of every calendar in lists.
+func (b *Backend) Fetch(ctx context.Context, lists []string) ([]*model.Item, error) {
+	var out []*model.Item
+	for _, name := range lists {
+		res, err := b.client.list(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		byUID := make(map[string]resource, len(res))
+		for _, r := range res {
+			item := r.cal.item(b.loc)
+			if item.UID == "" {
+				continue
+			}
+			item.ListName = name
+			byUID[item.UID] = r
+			out = append(out, &item)
+		}
+		b.mu.Lock()
+		b.resources[name] = byUID
+		b.mu.Unlock()
+	}
+	return out, nil
+}
+
+// Create adds item to the calendar named list under a new UID.
+func (b *Backend) Create(ctx context.Context, list string, item *model.Item) (string, error) {
+	uid, err := newUID()
+	if err != nil {
+		return "", err
+	}
+	cal := newCalendar(uid)
+	cal.apply(item, model.AllFields, b.clock.Now(), b.loc)
+	href := b.client.calendarURL(list).JoinPath(uid + ".ics").String()
+	if err := b.client.put(ctx, href, "", cal); err != nil {
+		return "", fmt.Errorf("creating task %q in %s: %w", item.Title, list, err)
+	}
+	return uid, nil
+}
+
+// Update writes fields of item over current.
+func (b *Backend) Update(ctx context.Context, list string, current, item *model.Item, fields model.Fields) error {
+	r, err := b.lookup(ctx, list, current.UID)
+	if err != nil {
+		return err
+	}
+	defer b.forget(list, current.UID) // its ETag is stale either way
+	r.cal.apply(item, fields, b.clock.Now(), b.loc)
+	if err := b.client.put(ctx, r.href, r.etag, r.cal); err != nil {
+		return fmt.Errorf("updating task %q in %s: %w", current.Title, list, err)
+	}
+	return nil
+}
+
+// Delete removes current from the calendar named list.
+func (b *Backend) Delete(ctx context.Context, list string, current *model.Item) error {
+	r, err := b.lookup(ctx, list, current.UID)
+	if err != nil {
+		return err
+	}
+	if err := b.client.remove(ctx, r.href, r.etag); err != nil {
+		return fmt.Errorf("deleting task %q from %s: %w", current.Title, list, err)
+	}
+	b.forget(list, current.UID)
+	return nil
+}
+
+// lookup returns the resource of uid from the last fetch of list, fetching
+// the calendar again if it is not known.
+func (b *Backend) lookup(ctx context.Context, list, uid string) (resource, error) {
+	b.mu.Lock()
+	r, ok := b.resources[list][uid]
+	b.mu.Unlock()
+	if ok {
+		return r, nil
+	}
+	if _, err := b.Fetch(ctx, []string{list}); err != nil {
+		return resource{}, err
+	}
+	b.mu.Lock()
+	r, ok = b.resources[list][uid]
+	b.mu.Unlock()
+	if !ok {
+		return resource{}, fmt.Errorf("task %q not found in %s", uid, list)
+	}
+	return r, nil
+}
+
+// forget drops the cached resource of uid.
+func (b *Backend) forget(list, uid string) {
+	b.mu.Lock()
+	delete(b.resources[list], uid)
+	b.mu.Unlock()
+}
+
+// newUID returns a random UID for a new task.
+func newUID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating task UID: %w", err)
+	}
+	return hex.EncodeToString(buf) + "@reminderrelay", nil
+}
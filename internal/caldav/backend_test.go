@@ -0,0 +1,153 @@
+package caldav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// fakeServer is an in-memory CalDAV server holding VTODO resources by path.
+type fakeServer struct {
+	mu    sync.Mutex
+	files map[string]string // path → iCalendar data
+	etags map[string]int
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, p, _ := r.BasicAuth(); u != "alice" || p != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	etag := fmt.Sprintf(`"%d"`, s.etags[r.URL.Path])
+	switch r.Method {
+	case "REPORT":
+		var b strings.Builder
+		b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">`)
+		for path, data := range s.files {
+			if strings.HasPrefix(path, r.URL.Path) {
+				fmt.Fprintf(&b, `<d:response><d:href>%s</d:href><d:propstat><d:prop><d:getetag>"%d"</d:getetag><c:calendar-data>%s</c:calendar-data></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`,
+					path, s.etags[path], html.EscapeString(data))
+			}
+		}
+		b.WriteString(`</d:multistatus>`)
+		w.WriteHeader(http.StatusMultiStatus)
+		_, _ = io.WriteString(w, b.String())
+	case http.MethodPut:
+		_, exists := s.files[r.URL.Path]
+		if (r.Header.Get("If-None-Match") == "*" && exists) ||
+			(r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != etag) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		s.files[r.URL.Path] = string(data)
+		s.etags[r.URL.Path]++
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		if r.Header.Get("If-Match") != etag {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		delete(s.files, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// touch simulates an edit made on the server by another client.
+func (s *fakeServer) touch(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.etags[path]++
+}
+
+func newTestBackend(t *testing.T, password string) (*Backend, *fakeServer) {
+	t.Helper()
+	fake := &fakeServer{files: make(map[string]string), etags: make(map[string]int)}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	client, err := NewClient(srv.URL+"/dav/calendars/alice", "alice", password, testLogger)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return NewBackend(client), fake
+}
+
+func TestBackend_CreateFetchUpdateDelete(t *testing.T) {
+	b, fake := newTestBackend(t, "secret")
+	ctx := context.Background()
+
+	uid, err := b.Create(ctx, "tasks", &model.Item{Title: "Buy milk", Priority: model.PriorityMedium})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	items, err := b.Fetch(ctx, []string{"tasks"})
+	if err != nil || len(items) != 1 {
+		t.Fatalf("Fetch = %v, %v; want the created task", items, err)
+	}
+	if got := items[0]; got.UID != uid || got.Title != "Buy milk" || got.Priority != model.PriorityMedium || got.ListName != "tasks" {
+		t.Errorf("fetched = %+v", got)
+	}
+
+	done := *items[0]
+	done.Completed = true
+	if err := b.Update(ctx, "tasks", items[0], &done, model.FieldCompleted); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	items, _ = b.Fetch(ctx, []string{"tasks"})
+	if len(items) != 1 || !items[0].Completed {
+		t.Fatalf("after Update = %+v, want completed", items)
+	}
+
+	if err := b.Delete(ctx, "tasks", items[0]); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if len(fake.files) != 0 {
+		t.Errorf("files = %v, want none after Delete", fake.files)
+	}
+}
+
+func TestBackend_UpdateRefusesNewerServerEdit(t *testing.T) {
+	b, fake := newTestBackend(t, "secret")
+	ctx := context.Background()
+
+	if _, err := b.Create(ctx, "tasks", &model.Item{Title: "Buy milk"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	items, _ := b.Fetch(ctx, []string{"tasks"})
+	for path := range fake.files {
+		fake.touch(path)
+	}
+
+	edited := *items[0]
+	edited.Title = "Buy oat milk"
+	err := b.Update(ctx, "tasks", items[0], &edited, model.FieldTitle)
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("Update = %v, want ErrPreconditionFailed", err)
+	}
+}
+
+func TestBackend_Unauthorized(t *testing.T) {
+	b, _ := newTestBackend(t, "wrong")
+	_, err := b.Fetch(context.Background(), []string{"tasks"})
+	if !errors.Is(err, model.ErrUnauthorized) {
+		t.Errorf("Fetch = %v, want ErrUnauthorized", err)
+	}
+}
@@ -0,0 +1,204 @@
+// Package caldav syncs with task calendars on CalDAV servers such as
+// Nextcloud Tasks or Radicale. It implements just enough of CalDAV
+// (RFC 4791) and iCalendar (RFC 5545) to list, create, update, and delete
+// VTODO resources, and exposes them to the sync engine as a [Backend].
+//
+// Properties ReminderRelay does not model — categories, alarms, X-
+// extensions — are preserved when an item is updated.
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// requestTimeout bounds a single request to the server.
+const requestTimeout = 30 * time.Second
+
+// calendarQuery asks for the ETag and data of every VTODO in a calendar.
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/><c:calendar-data/></d:prop>
+  <c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO"/></c:comp-filter></c:filter>
+</c:calendar-query>`
+
+// ErrPreconditionFailed is returned (wrapped) when a resource changed on the
+// server since it was fetched, so a write was refused rather than
+// overwriting the newer version.
+var ErrPreconditionFailed = errors.New("resource changed on the server")
+
+// Client talks to the calendar home collection of a single CalDAV account.
+// Create one with [NewClient].
+type Client struct {
+	base     *url.URL
+	username string
+	password string
+	hc       *http.Client
+	log      *slog.Logger
+}
+
+// NewClient creates a Client for the calendar home at rawURL, e.g.
+// https://cloud.example.com/remote.php/dav/calendars/alice/. Requests use
+// HTTP basic authentication when username is set.
+func NewClient(rawURL, username, password string, logger *slog.Logger) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("caldav URL %q must be a valid http or https URL", rawURL)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &Client{
+		base:     u,
+		username: username,
+		password: password,
+		hc:       &http.Client{Timeout: requestTimeout},
+		log:      logger,
+	}, nil
+}
+
+// resource is a VTODO as stored on the server.
+type resource struct {
+	href string // absolute URL
+	etag string
+	cal  *calendar
+}
+
+// calendarURL returns the collection URL of the calendar named name.
+func (c *Client) calendarURL(name string) *url.URL {
+	return c.base.JoinPath(name + "/")
+}
+
+// multistatus is the subset of a WebDAV multistatus response used here.
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				ETag string `xml:"DAV: getetag"`
+				Data string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// list returns every VTODO in the calendar named name.
+func (c *Client) list(ctx context.Context, name string) ([]resource, error) {
+	col := c.calendarURL(name)
+	resp, err := c.do(ctx, "REPORT", col.String(), strings.NewReader(calendarQuery), map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+		"Depth":        "1",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing tasks in %q: %w", name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("listing tasks in %q: %w", name, statusError(resp))
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("decoding task list of %q: %w", name, err)
+	}
+
+	var out []resource
+	for _, r := range ms.Responses {
+		for _, ps := range r.Propstat {
+			if ps.Prop.Data == "" || !strings.Contains(ps.Status, " 200 ") {
+				continue
+			}
+			cal, err := parseCalendar(ps.Prop.Data)
+			if err != nil {
+				c.log.Warn("skipping unreadable CalDAV task", "href", r.Href, "error", err)
+				continue
+			}
+			href, err := col.Parse(r.Href)
+			if err != nil {
+				return nil, fmt.Errorf("resolving %q: %w", r.Href, err)
+			}
+			out = append(out, resource{href: href.String(), etag: ps.Prop.ETag, cal: cal})
+		}
+	}
+	return out, nil
+}
+
+// put writes cal to href. A non-empty etag only overwrites that version;
+// an empty one only creates a new resource.
+func (c *Client) put(ctx context.Context, href, etag string, cal *calendar) error {
+	headers := map[string]string{"Content-Type": "text/calendar; charset=utf-8"}
+	if etag != "" {
+		headers["If-Match"] = etag
+	} else {
+		headers["If-None-Match"] = "*"
+	}
+	resp, err := c.do(ctx, http.MethodPut, href, strings.NewReader(cal.encode()), headers)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+	return nil
+}
+
+// remove deletes href if it still has the given etag.
+func (c *Client) remove(ctx context.Context, href, etag string) error {
+	var headers map[string]string
+	if etag != "" {
+		headers = map[string]string{"If-Match": etag}
+	}
+	resp, err := c.do(ctx, http.MethodDelete, href, nil, headers)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+	return statusError(resp)
+}
+
+// do sends a request with the client's credentials.
+func (c *Client) do(ctx context.Context, method, target string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	if body == nil {
+		body = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return c.hc.Do(req)
+}
+
+// statusError describes an unexpected response.
+func statusError(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("CalDAV server returned %s — check the caldav credentials: %w", resp.Status, model.ErrUnauthorized)
+	case http.StatusPreconditionFailed:
+		return ErrPreconditionFailed
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	return fmt.Errorf("CalDAV server returned %s: %s", resp.Status, bytes.TrimSpace(snippet))
+}
@@ -0,0 +1,331 @@
+package caldav
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+const (
+	icalDate     = "20060102"
+	icalDateTime = "20060102T150405"
+	icalUTC      = "20060102T150405Z"
+
+	// foldAt is the maximum line length in octets (RFC 5545 §3.1).
+	foldAt = 75
+)
+
+// property is a single content line of an iCalendar object, kept verbatim
+// apart from folding so properties ReminderRelay does not model survive a
+// round trip untouched.
+type property struct {
+	name   string // upper-cased
+	params string // raw, including the leading ';', or ""
+	value  string // raw, still escaped
+}
+
+// calendar is a parsed iCalendar object holding a single VTODO. before and
+// after are the lines around the VTODO (VCALENDAR headers, VTIMEZONE, …).
+type calendar struct {
+	before []string
+	todo   []property
+	after  []string
+}
+
+// parseCalendar parses an iCalendar object containing a VTODO.
+func parseCalendar(data string) (*calendar, error) {
+	var c calendar
+	state := 0 // 0 before VTODO, 1 inside, 2 after
+	for _, line := range unfold(data) {
+		switch {
+		case state == 0 && strings.EqualFold(line, "BEGIN:VTODO"):
+			state = 1
+		case state == 1 && strings.EqualFold(line, "END:VTODO"):
+			state = 2
+		case state == 1:
+			c.todo = append(c.todo, parseProperty(line))
+		case state == 0:
+			c.before = append(c.before, line)
+		default:
+			c.after = append(c.after, line)
+		}
+	}
+	if state != 2 {
+		return nil, fmt.Errorf("no complete VTODO in calendar data")
+	}
+	return &c, nil
+}
+
+// newCalendar returns an empty VTODO calendar with the given UID.
+func newCalendar(uid string) *calendar {
+	return &calendar{
+		before: []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//ReminderRelay//EN"},
+		todo:   []property{{name: "UID", value: escapeText(uid)}},
+		after:  []string{"END:VCALENDAR"},
+	}
+}
+
+// encode renders c as a folded iCalendar object.
+func (c *calendar) encode() string {
+	var b strings.Builder
+	write := func(line string) {
+		b.WriteString(fold(line))
+		b.WriteString("\r\n")
+	}
+	for _, l := range c.before {
+		write(l)
+	}
+	write("BEGIN:VTODO")
+	for _, p := range c.todo {
+		write(p.name + p.params + ":" + p.value)
+	}
+	write("END:VTODO")
+	for _, l := range c.after {
+		write(l)
+	}
+	return b.String()
+}
+
+// get returns the first property called name, or nil.
+func (c *calendar) get(name string) *property {
+	for i := range c.todo {
+		if c.todo[i].name == name {
+			return &c.todo[i]
+		}
+	}
+	return nil
+}
+
+// set replaces every property called name with a single one.
+func (c *calendar) set(name, params, value string) {
+	c.del(name)
+	c.todo = append(c.todo, property{name: name, params: params, value: value})
+}
+
+// del removes every property called name.
+func (c *calendar) del(name string) {
+	kept := c.todo[:0]
+	for _, p := range c.todo {
+		if p.name != name {
+			kept = append(kept, p)
+		}
+	}
+	c.todo = kept
+}
+
+// item converts the VTODO to a [model.Item].
+func (c *calendar) item(loc *time.Location) model.Item {
+	var item model.Item
+	if p := c.get("UID"); p != nil {
+		item.UID = unescapeText(p.value)
+	}
+	if p := c.get("SUMMARY"); p != nil {
+		item.Title = unescapeText(p.value)
+	}
+	if p := c.get("DESCRIPTION"); p != nil {
+		item.Description = unescapeText(p.value)
+	}
+	if p := c.get("PRIORITY"); p != nil {
+		n, _ := strconv.Atoi(strings.TrimSpace(p.value))
+		item.Priority = model.NormalizePriority(n)
+	}
+	if p := c.get("DUE"); p != nil {
+		if t, err := parseTime(p, loc); err == nil {
+			item.DueDate = &t
+		}
+	}
+	if p := c.get("URL"); p != nil {
+		item.URL = p.value
+	}
+	if p := c.get("STATUS"); p != nil {
+		item.Completed = strings.EqualFold(p.value, "COMPLETED")
+	} else {
+		item.Completed = c.get("COMPLETED") != nil
+	}
+	if p := c.get("COMPLETED"); p != nil && item.Completed {
+		if t, err := parseTime(p, loc); err == nil {
+			item.CompletedAt = &t
+		}
+	}
+	if p := c.get("LAST-MODIFIED"); p != nil {
+		if t, err := parseTime(p, loc); err == nil {
+			item.ModifiedAt = t
+		}
+	}
+	return item
+}
+
+// apply writes fields of item into the VTODO. now stamps DTSTAMP,
+// LAST-MODIFIED, and a new COMPLETED unless item says when it was completed.
+// Due dates are written as by formatDue in loc.
+func (c *calendar) apply(item *model.Item, fields model.Fields, now time.Time, loc *time.Location) {
+	if fields.Has(model.FieldTitle) {
+		c.set("SUMMARY", "", escapeText(item.Title))
+	}
+	if fields.Has(model.FieldDescription) {
+		if item.Description == "" {
+			c.del("DESCRIPTION")
+		} else {
+			c.set("DESCRIPTION", "", escapeText(item.Description))
+		}
+	}
+	if fields.Has(model.FieldPriority) {
+		if item.Priority == model.PriorityNone {
+			c.del("PRIORITY")
+		} else {
+			c.set("PRIORITY", "", strconv.Itoa(int(item.Priority)))
+		}
+	}
+	if fields.Has(model.FieldDueDate) {
+		if item.DueDate == nil {
+			c.del("DUE")
+		} else {
+			params, value := formatDue(*item.DueDate, loc)
+			c.set("DUE", params, value)
+		}
+	}
+	if fields.Has(model.FieldURL) {
+		if item.URL == "" {
+			c.del("URL")
+		} else {
+			c.set("URL", "", item.URL)
+		}
+	}
+	if fields.Has(model.FieldCompleted) {
+		if item.Completed {
+			c.set("STATUS", "", "COMPLETED")
+			completed := now
+			if item.CompletedAt != nil {
+				completed = *item.CompletedAt
+			}
+			c.set("COMPLETED", "", completed.UTC().Format(icalUTC))
+			c.set("PERCENT-COMPLETE", "", "100")
+		} else {
+			c.set("STATUS", "", "NEEDS-ACTION")
+			c.del("COMPLETED")
+			c.del("PERCENT-COMPLETE")
+		}
+	}
+	stamp := now.UTC().Format(icalUTC)
+	c.set("DTSTAMP", "", stamp)
+	c.set("LAST-MODIFIED", "", stamp)
+}
+
+// formatDue renders a due date: all-day when it falls on midnight in loc, as
+// Reminders and HA store date-only due dates, otherwise a UTC date-time.
+func formatDue(t time.Time, loc *time.Location) (params, value string) {
+	l := t.In(loc)
+	if l.Hour() == 0 && l.Minute() == 0 && l.Second() == 0 {
+		return ";VALUE=DATE", l.Format(icalDate)
+	}
+	return "", t.UTC().Format(icalUTC)
+}
+
+// parseTime parses a DATE or DATE-TIME property value, honouring TZID.
+// All-day dates and date-times without a zone are taken to be in loc.
+func parseTime(p *property, loc *time.Location) (time.Time, error) {
+	v := strings.TrimSpace(p.value)
+	switch {
+	case len(v) == len(icalDate):
+		return time.ParseInLocation(icalDate, v, loc)
+	case strings.HasSuffix(v, "Z"):
+		return time.Parse(icalUTC, v)
+	}
+	if tzid := param(p.params, "TZID"); tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	return time.ParseInLocation(icalDateTime, v, loc)
+}
+
+// param returns the value of the parameter name in raw, or "".
+func param(raw, name string) string {
+	for _, part := range strings.Split(raw, ";") {
+		k, v, ok := strings.Cut(part, "=")
+		if ok && strings.EqualFold(k, name) {
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
+
+// parseProperty splits a content line into name, parameters, and value.
+func parseProperty(line string) property {
+	// The value starts at the first colon outside a quoted parameter value.
+	quoted := false
+	split := len(line)
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			split = i
+			break
+		}
+	}
+	head, value := line[:split], ""
+	if split < len(line) {
+		value = line[split+1:]
+	}
+	name, params := head, ""
+	if i := strings.IndexByte(head, ';'); i >= 0 {
+		name, params = head[:i], head[i:]
+	}
+	return property{name: strings.ToUpper(name), params: params, value: value}
+}
+
+// unfold splits data into logical content lines, joining folded ones.
+func unfold(data string) []string {
+	var lines []string
+	for _, raw := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if raw == "" {
+			continue
+		}
+		if (raw[0] == ' ' || raw[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += raw[1:]
+			continue
+		}
+		lines = append(lines, raw)
+	}
+	return lines
+}
+
+// fold breaks line into chunks of at most foldAt octets, without splitting
+// a UTF-8 sequence.
+func fold(line string) string {
+	if len(line) <= foldAt {
+		return line
+	}
+	var b strings.Builder
+	limit := foldAt
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = foldAt - 1 // continuation lines start with a space
+	}
+	b.WriteString(line)
+	return b.String()
+}
+
+var (
+	textEscaper   = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+// escapeText escapes a TEXT value (RFC 5545 §3.3.11).
+func escapeText(s string) string {
+	return textEscaper.Replace(strings.ReplaceAll(s, "\r\n", "\n"))
+}
+
+// unescapeText reverses [escapeText].
+func unescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
@@ -0,0 +1,138 @@
+package caldav
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // zones for the DST test
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+const nextcloudTodo = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Nextcloud Tasks v0.16.0\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"UID:abc-123\r\n" +
+	"SUMMARY:Milk\\, eggs\r\n" +
+	"DESCRIPTION:first line\\nsecond \r\n" +
+	" line\r\n" +
+	"PRIORITY:1\r\n" +
+	"DUE;VALUE=DATE:20260301\r\n" +
+	"STATUS:NEEDS-ACTION\r\n" +
+	"CATEGORIES:home\r\n" +
+	"X-APPLE-SORT-ORDER:42\r\n" +
+	"LAST-MODIFIED:20260215T101500Z\r\n" +
+	"END:VTODO\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestCalendar_Item(t *testing.T) {
+	cal, err := parseCalendar(nextcloudTodo)
+	if err != nil {
+		t.Fatalf("parseCalendar: %v", err)
+	}
+	got := cal.item(time.UTC)
+
+	due := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	want := model.Item{
+		UID:         "abc-123",
+		Title:       "Milk, eggs",
+		Description: "first line\nsecond line",
+		Priority:    model.PriorityHigh,
+		DueDate:     &due,
+		ModifiedAt:  time.Date(2026, 2, 15, 10, 15, 0, 0, time.UTC),
+	}
+	if got.ContentHash() != want.ContentHash() || got.UID != want.UID || !got.ModifiedAt.Equal(want.ModifiedAt) {
+		t.Errorf("item = %+v, want %+v", got, want)
+	}
+}
+
+func TestCalendar_ApplyKeepsUnknownProperties(t *testing.T) {
+	cal, err := parseCalendar(nextcloudTodo)
+	if err != nil {
+		t.Fatalf("parseCalendar: %v", err)
+	}
+	item := cal.item(time.UTC)
+	item.Title = strings.Repeat("very long title ", 8)
+	item.Completed = true
+
+	now := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	cal.apply(&item, model.FieldTitle|model.FieldCompleted, now, time.UTC)
+	out := cal.encode()
+
+	for _, want := range []string{"CATEGORIES:home", "X-APPLE-SORT-ORDER:42", "STATUS:COMPLETED", "COMPLETED:20260302T080000Z"} {
+		if !strings.Contains(out, want+"\r\n") {
+			t.Errorf("encoded calendar lacks %q:\n%s", want, out)
+		}
+	}
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > foldAt {
+			t.Errorf("line longer than %d octets: %q", foldAt, line)
+		}
+	}
+
+	again, err := parseCalendar(out)
+	if err != nil {
+		t.Fatalf("re-parsing: %v", err)
+	}
+	if got := again.item(time.UTC); got.Title != item.Title || !got.Completed {
+		t.Errorf("round trip = %+v, want title and completion applied", got)
+	}
+}
+
+func TestCalendar_CompletionTime(t *testing.T) {
+	cal, err := parseCalendar(nextcloudTodo)
+	if err != nil {
+		t.Fatalf("parseCalendar: %v", err)
+	}
+	item := cal.item(time.UTC)
+	if item.CompletedAt != nil {
+		t.Errorf("CompletedAt = %v, want nil for an open item", item.CompletedAt)
+	}
+
+	// The time the item was completed elsewhere is kept, not the time of writing.
+	done := time.Date(2026, 2, 28, 19, 45, 0, 0, time.UTC)
+	item.Completed, item.CompletedAt = true, &done
+	cal.apply(&item, model.FieldCompleted, time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC), time.UTC)
+	if out := cal.encode(); !strings.Contains(out, "COMPLETED:20260228T194500Z\r\n") {
+		t.Errorf("encoded calendar lacks the completion time:\n%s", out)
+	}
+	if got := cal.item(time.UTC); got.CompletedAt == nil || !got.CompletedAt.Equal(done) {
+		t.Errorf("CompletedAt = %v, want %v", got.CompletedAt, done)
+	}
+}
+
+func TestCalendar_URL(t *testing.T) {
+	cal, err := parseCalendar(nextcloudTodo)
+	if err != nil {
+		t.Fatalf("parseCalendar: %v", err)
+	}
+	item := cal.item(time.UTC)
+	item.URL = "https://example.com/milk"
+	now := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	cal.apply(&item, model.FieldURL, now, time.UTC)
+	if got := cal.item(time.UTC).URL; got != item.URL {
+		t.Errorf("URL = %q, want %q", got, item.URL)
+	}
+	item.URL = ""
+	cal.apply(&item, model.FieldURL, now, time.UTC)
+	if out := cal.encode(); strings.Contains(out, "URL:") {
+		t.Errorf("encoded calendar keeps a cleared URL:\n%s", out)
+	}
+}
+
+func TestFormatDue_AllDayInZone(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Midnight on the day Berlin springs forward is 23:00 UTC the day before.
+	params, value := formatDue(time.Date(2026, 3, 29, 0, 0, 0, 0, loc), loc)
+	if params != ";VALUE=DATE" || value != "20260329" {
+		t.Errorf("formatDue = %q %q, want an all-day 20260329", params, value)
+	}
+	due, err := parseTime(&property{params: params, value: value}, loc)
+	if err != nil || !due.Equal(time.Date(2026, 3, 29, 0, 0, 0, 0, loc)) {
+		t.Errorf("parseTime = %v, %v; want midnight in Berlin", due, err)
+	}
+}
@@ -0,0 +1,258 @@
+// Package chaos injects faults into the sync engine's task backends and Home
+// Assistant connection, for soak tests that check the engine converges in
+// spite of them. Faults are drawn from a seeded random source, so a failing
+// run can be replayed.
+//
+// The daemon only uses this package when built with the "chaos" build tag;
+// see the README.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// Fault is a kind of injected failure.
+type Fault string
+
+// Injected faults.
+const (
+	// FaultTimeout fails a call with a deadline error before it reaches the
+	// backend.
+	FaultTimeout Fault = "timeout"
+	// FaultServerError fails a call as if the server answered HTTP 500.
+	FaultServerError Fault = "server_error"
+	// FaultPartialWrite performs a write but reports it as failed, as when
+	// the connection drops before the reply arrives.
+	FaultPartialWrite Fault = "partial_write"
+	// FaultDisconnect drops WebSocket change events, as while the connection
+	// is down.
+	FaultDisconnect Fault = "disconnect"
+)
+
+// ErrInjected is wrapped by every error this package returns.
+var ErrInjected = errors.New("injected fault")
+
+// Injector decides which calls fail. It is safe for concurrent use. Create
+// one with [NewInjector].
+type Injector struct {
+	log *slog.Logger
+
+	mu     sync.Mutex
+	rng    *rand.Rand
+	rate   float64
+	counts map[Fault]int
+}
+
+// NewInjector creates an Injector that fails a call with probability rate,
+// drawing from a random source seeded with seed.
+func NewInjector(rate float64, seed uint64, logger *slog.Logger) *Injector {
+	return &Injector{
+		log:    logger,
+		rng:    rand.New(rand.NewPCG(seed, seed)),
+		rate:   rate,
+		counts: make(map[Fault]int),
+	}
+}
+
+// SetRate changes the failure probability; zero stops injecting faults.
+func (i *Injector) SetRate(rate float64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.rate = rate
+}
+
+// Counts returns how often each fault was injected.
+func (i *Injector) Counts() map[Fault]int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	out := make(map[Fault]int, len(i.counts))
+	for f, n := range i.counts {
+		out[f] = n
+	}
+	return out
+}
+
+// pick returns one of faults with the configured probability, or "".
+func (i *Injector) pick(op string, faults ...Fault) Fault {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.rate <= 0 || i.rng.Float64() >= i.rate {
+		return ""
+	}
+	f := faults[i.rng.IntN(len(faults))]
+	i.counts[f]++
+	i.log.Warn("injecting fault", "op", op, "fault", f)
+	return f
+}
+
+// errorFor returns the error a call fails with under f.
+func errorFor(op string, f Fault) error {
+	switch f {
+	case FaultTimeout:
+		return fmt.Errorf("%s: %w: %w", op, ErrInjected, context.DeadlineExceeded)
+	case FaultPartialWrite:
+		return fmt.Errorf("%s: %w: connection reset after write", op, ErrInjected)
+	}
+	return fmt.Errorf("%s: %w: server returned 500 Internal Server Error", op, ErrInjected)
+}
+
+// TaskBackend mirrors the sync engine's task backend interface, so wrapped
+// backends can be passed wherever the engine expects one.
+type TaskBackend interface {
+	Fetch(ctx context.Context, lists []string) ([]*model.Item, error)
+	Create(ctx context.Context, list string, item *model.Item) (string, error)
+	Update(ctx context.Context, list string, current, item *model.Item, fields model.Fields) error
+	Delete(ctx context.Context, list string, current *model.Item) error
+}
+
+// Backend injects faults into the calls of a wrapped [TaskBackend]. Create
+// one with [WrapBackend].
+type Backend struct {
+	name string
+	next TaskBackend
+	inj  *Injector
+}
+
+// WrapBackend returns next with faults from inj injected. name labels the
+// backend in logs.
+func WrapBackend(name string, next TaskBackend, inj *Injector) *Backend {
+	return &Backend{name: name, next: next, inj: inj}
+}
+
+// Fetch may time out or fail with a server error.
+func (b *Backend) Fetch(ctx context.Context, lists []string) ([]*model.Item, error) {
+	op := b.name + " fetch"
+	if f := b.inj.pick(op, FaultTimeout, FaultServerError); f != "" {
+		return nil, errorFor(op, f)
+	}
+	return b.next.Fetch(ctx, lists)
+}
+
+// Create may time out, fail, or create the item and report a failure.
+func (b *Backend) Create(ctx context.Context, list string, item *model.Item) (string, error) {
+	op := b.name + " create"
+	f := b.inj.pick(op, FaultTimeout, FaultServerError, FaultPartialWrite)
+	if f != "" && f != FaultPartialWrite {
+		return "", errorFor(op, f)
+	}
+	uid, err := b.next.Create(ctx, list, item)
+	if err == nil && f == FaultPartialWrite {
+		return "", errorFor(op, f)
+	}
+	return uid, err
+}
+
+// Update may time out, fail, or write the item and report a failure.
+func (b *Backend) Update(ctx context.Context, list string, current, item *model.Item, fields model.Fields) error {
+	op := b.name + " update"
+	f := b.inj.pick(op, FaultTimeout, FaultServerError, FaultPartialWrite)
+	if f != "" && f != FaultPartialWrite {
+		return errorFor(op, f)
+	}
+	err := b.next.Update(ctx, list, current, item, fields)
+	if err == nil && f == FaultPartialWrite {
+		return errorFor(op, f)
+	}
+	return err
+}
+
+// Delete may time out, fail, or delete the item and report a failure.
+func (b *Backend) Delete(ctx context.Context, list string, current *model.Item) error {
+	op := b.name + " delete"
+	f := b.inj.pick(op, FaultTimeout, FaultServerError, FaultPartialWrite)
+	if f != "" && f != FaultPartialWrite {
+		return errorFor(op, f)
+	}
+	err := b.next.Delete(ctx, list, current)
+	if err == nil && f == FaultPartialWrite {
+		return errorFor(op, f)
+	}
+	return err
+}
+
+// SupportedFields passes through the fields the wrapped backend can store
+// on list, all of them if it does not say.
+func (b *Backend) SupportedFields(list string) model.Fields {
+	if l, ok := b.next.(interface{ SupportedFields(string) model.Fields }); ok {
+		return l.SupportedFields(list)
+	}
+	return model.AllFields
+}
+
+// Connector mirrors the sync engine's Home Assistant WebSocket interface.
+type Connector interface {
+	Connect(ctx context.Context) error
+	Close() error
+	SubscribeChanges(ctx context.Context, entityIDs []string, callback func(entityID string)) error
+	OnRestart(fn func())
+}
+
+// disconnectEvents is how many change events a simulated disconnect drops.
+const disconnectEvents = 5
+
+// Conn injects disconnects into a wrapped [Connector]. Create one with
+// [WrapConnector].
+type Conn struct {
+	next Connector
+	inj  *Injector
+
+	mu      sync.Mutex
+	dropped int // events still to drop in the current disconnect
+}
+
+// WrapConnector returns next with faults from inj injected. Connect may
+// fail, and a subscription occasionally drops the next few change events,
+// so only polling can pick those changes up.
+func WrapConnector(next Connector, inj *Injector) *Conn {
+	return &Conn{next: next, inj: inj}
+}
+
+// Connect may time out.
+func (c *Conn) Connect(ctx context.Context) error {
+	if f := c.inj.pick("ws connect", FaultTimeout); f != "" {
+		return errorFor("ws connect", f)
+	}
+	return c.next.Connect(ctx)
+}
+
+// Close closes the wrapped connection.
+func (c *Conn) Close() error {
+	return c.next.Close()
+}
+
+// SubscribeChanges subscribes through the wrapped connection, dropping
+// events while a simulated disconnect lasts.
+func (c *Conn) SubscribeChanges(ctx context.Context, entityIDs []string, callback func(entityID string)) error {
+	return c.next.SubscribeChanges(ctx, entityIDs, func(entityID string) {
+		if c.drop() {
+			return
+		}
+		callback(entityID)
+	})
+}
+
+// OnRestart passes fn to the wrapped connection.
+func (c *Conn) OnRestart(fn func()) {
+	c.next.OnRestart(fn)
+}
+
+// drop reports whether the next event is lost to a disconnect.
+func (c *Conn) drop() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dropped == 0 && c.inj.pick("ws event", FaultDisconnect) != "" {
+		c.dropped = disconnectEvents
+	}
+	if c.dropped > 0 {
+		c.dropped--
+		return true
+	}
+	return false
+}
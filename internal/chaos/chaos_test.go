@@ -0,0 +1,57 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// countingBackend records the writes that reached it.
+type countingBackend struct{ creates int }
+
+func (*countingBackend) Fetch(context.Context, []string) ([]*model.Item, error) { return nil, nil }
+
+func (c *countingBackend) Create(context.Context, string, *model.Item) (string, error) {
+	c.creates++
+	return "uid", nil
+}
+
+func (*countingBackend) Update(context.Context, string, *model.Item, *model.Item, model.Fields) error {
+	return nil
+}
+
+func (*countingBackend) Delete(context.Context, string, *model.Item) error { return nil }
+
+func TestBackend_InjectsEveryFault(t *testing.T) {
+	next := &countingBackend{}
+	inj := NewInjector(1, 7, testLogger)
+	b := WrapBackend("test", next, inj)
+
+	failures := 200
+	for range failures {
+		if _, err := b.Create(context.Background(), "list", &model.Item{Title: "x"}); !errors.Is(err, ErrInjected) {
+			t.Fatalf("Create error = %v, want ErrInjected", err)
+		}
+	}
+
+	counts := inj.Counts()
+	for _, f := range []Fault{FaultTimeout, FaultServerError, FaultPartialWrite} {
+		if counts[f] == 0 {
+			t.Errorf("fault %s never injected: %v", f, counts)
+		}
+	}
+	if next.creates != counts[FaultPartialWrite] {
+		t.Errorf("creates reaching the backend = %d, want one per partial write (%d)", next.creates, counts[FaultPartialWrite])
+	}
+
+	inj.SetRate(0)
+	if _, err := b.Create(context.Background(), "list", &model.Item{Title: "x"}); err != nil {
+		t.Errorf("Create with rate 0 error = %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+// Package clock abstracts the passage of time so that time-dependent
+// behaviour — polling intervals, retry backoff, sync timestamps — can be
+// tested deterministically.
+//
+// Production code uses [Real]. Tests use [Fake], whose time only moves when
+// [Fake.Advance] is called.
+package clock
+
+import "time"
+
+// Clock provides the current time and timer primitives.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for d to elapse and then sends the current time on the
+	// returned channel.
+	After(d time.Duration) <-chan time.Time
+	// NewTicker returns a Ticker that fires every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks at intervals. It mirrors [time.Ticker] as an
+// interface so fake implementations can be substituted.
+type Ticker interface {
+	// C returns the channel on which ticks are delivered.
+	C() <-chan time.Time
+	// Stop turns off the ticker. No more ticks are sent after Stop returns.
+	Stop()
+}
+
+// Real returns a Clock backed by the standard library.
+func Real() Clock { return realClock{} }
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct{ t *time.Ticker }
+
+func (r realTicker) C() <-chan time.Time { return r.t.C }
+func (r realTicker) Stop()               { r.t.Stop() }
@@ -0,0 +1,70 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+func TestFake_AfterFiresOnlyWhenDue(t *testing.T) {
+	f := NewFake(start)
+	ch := f.After(time.Minute)
+
+	f.Advance(59 * time.Second)
+	select {
+	case <-ch:
+		t.Fatal("After fired before its deadline")
+	default:
+	}
+
+	f.Advance(time.Second)
+	select {
+	case got := <-ch:
+		if !got.Equal(start.Add(time.Minute)) {
+			t.Errorf("fired at %v, want %v", got, start.Add(time.Minute))
+		}
+	default:
+		t.Fatal("After did not fire at its deadline")
+	}
+}
+
+func TestFake_TickerRepeatsAndStops(t *testing.T) {
+	f := NewFake(start)
+	tk := f.NewTicker(10 * time.Second)
+
+	for i := 1; i <= 3; i++ {
+		f.Advance(10 * time.Second)
+		select {
+		case <-tk.C():
+		default:
+			t.Fatalf("tick %d not delivered", i)
+		}
+	}
+
+	tk.Stop()
+	f.Advance(10 * time.Second)
+	select {
+	case <-tk.C():
+		t.Fatal("tick delivered after Stop")
+	default:
+	}
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	f := NewFake(start)
+	done := make(chan struct{})
+
+	go func() {
+		<-f.After(time.Second)
+		close(done)
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("goroutine waiting on After was not released")
+	}
+}
@@ -0,0 +1,136 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a manually driven [Clock] for tests. Time only moves when
+// [Fake.Advance] is called, at which point every due timer and ticker fires.
+// Create one with [NewFake].
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// fakeWaiter is a pending After timer or an active ticker.
+type fakeWaiter struct {
+	at      time.Time
+	period  time.Duration // zero for one-shot timers
+	ch      chan time.Time
+	stopped bool
+}
+
+// NewFake returns a Fake whose current time is start.
+func NewFake(start time.Time) *Fake {
+	f := &Fake{now: start}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the fake's current time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel that receives the fake time once it has been
+// advanced by at least d.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := &fakeWaiter{at: f.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- f.now
+		return w.ch
+	}
+	f.addWaiter(w)
+	return w.ch
+}
+
+// NewTicker returns a Ticker that fires each time the fake is advanced past
+// another multiple of d. Like [time.Ticker], ticks are dropped if the reader
+// falls behind.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := &fakeWaiter{at: f.now.Add(d), period: d, ch: make(chan time.Time, 1)}
+	f.addWaiter(w)
+	return &fakeTicker{f: f, w: w}
+}
+
+// Advance moves the fake time forward by d and fires every timer and ticker
+// that became due.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+	remaining := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.stopped {
+			continue
+		}
+		if w.at.After(f.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+		select {
+		case w.ch <- f.now:
+		default: // reader is behind; drop the tick like time.Ticker does
+		}
+		if w.period > 0 {
+			for !w.at.After(f.now) {
+				w.at = w.at.Add(w.period)
+			}
+			remaining = append(remaining, w)
+		}
+	}
+	f.waiters = remaining
+}
+
+// BlockUntil blocks until at least n timers or tickers are pending. Use it to
+// synchronise with a goroutine that is about to wait on the clock before
+// calling [Fake.Advance].
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for f.pending() < n {
+		f.cond.Wait()
+	}
+}
+
+// addWaiter registers w and wakes BlockUntil callers. f.mu must be held.
+func (f *Fake) addWaiter(w *fakeWaiter) {
+	f.waiters = append(f.waiters, w)
+	f.cond.Broadcast()
+}
+
+// pending counts active waiters. f.mu must be held.
+func (f *Fake) pending() int {
+	n := 0
+	for _, w := range f.waiters {
+		if !w.stopped {
+			n++
+		}
+	}
+	return n
+}
+
+type fakeTicker struct {
+	f *Fake
+	w *fakeWaiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.w.ch }
+
+func (t *fakeTicker) Stop() {
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	t.w.stopped = true
+}
@@ -3,11 +3,19 @@ package config
 
 import (
 	"fmt"
+	"io"
+	"maps"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
+	"github.com/njoerd114/reminderrelay/internal/profile"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,26 +24,445 @@ type Config struct {
 	// HAURL is the base URL of the Home Assistant instance (e.g. "http://homeassistant.local:8123").
 	HAURL string `yaml:"ha_url"`
 
+	// HAFallbackURLs are tried in order while HAURL does not answer, e.g. the
+	// Nabu Casa remote URL for a laptop away from home. The daemon returns
+	// to a preferred URL once it answers again.
+	HAFallbackURLs []string `yaml:"ha_fallback_urls,omitempty"`
+
 	// HAToken is the long-lived access token used to authenticate with Home Assistant.
 	HAToken string `yaml:"ha_token"`
 
+	// HATLS secures the Home Assistant connection with a private CA or a
+	// client certificate. Omit the block to use the system trust store.
+	HATLS *HATLSConfig `yaml:"ha_tls,omitempty"`
+
 	// PollInterval controls how often Apple Reminders are polled for changes.
 	// Minimum 10s, maximum 5m. Defaults to 30s if unset.
 	PollInterval time.Duration `yaml:"poll_interval"`
 
+	// SafetyPollInterval replaces PollInterval on macOS, where EventKit
+	// change notifications trigger a pass as soon as Reminders change. The
+	// poll then only catches missed notifications. At least PollInterval,
+	// maximum 1h. Defaults to 5m if unset.
+	SafetyPollInterval time.Duration `yaml:"safety_poll_interval,omitempty"`
+
+	// ParallelLists is how many list mappings a pass syncs at once.
+	// Between 1 and 16. Defaults to 4 if unset.
+	ParallelLists int `yaml:"parallel_lists,omitempty"`
+
+	// HATimeout limits each Home Assistant request; a request still running
+	// after it is abandoned and retried. Between 1s and 5m. Defaults to 30s
+	// if unset.
+	HATimeout time.Duration `yaml:"ha_timeout,omitempty"`
+
+	// PassTimeout limits each sync pass; a pass still running after it is
+	// abandoned and its remaining changes left to the next pass. At least
+	// ha_timeout, maximum 1h. Defaults to 10m if unset.
+	PassTimeout time.Duration `yaml:"pass_timeout,omitempty"`
+
+	// HARateLimit is how many requests a second the daemon sends Home
+	// Assistant at most, retries included, with bursts of up to a second's
+	// worth. Between 1 and 1000. Defaults to 10 if unset.
+	HARateLimit float64 `yaml:"ha_rate_limit,omitempty"`
+
+	// HADescriptionTemplate lays out how an item's notes, priority, and URL
+	// share the description of HA todo items, with the placeholders
+	// {{notes}}, {{priority}}, {{url}}, and {{tags}}. Empty uses
+	// "{{priority}}{{notes}}", as in "[High] 2% milk".
+	HADescriptionTemplate string `yaml:"ha_description_template,omitempty"`
+
+	// ShutdownGrace is how long the item a pass is writing on shutdown may
+	// take to finish, so that it is recorded in the state DB. Between 1s and
+	// 15s, within the 20s launchd waits before killing the daemon. Defaults
+	// to 10s if unset.
+	ShutdownGrace time.Duration `yaml:"shutdown_grace,omitempty"`
+
+	// LatencyObjective is how quickly a change should reach the other side,
+	// e.g. 1m. The status command reports the share of recent changes that
+	// met it. Zero reports latency percentiles only.
+	LatencyObjective time.Duration `yaml:"latency_objective,omitempty"`
+
+	// LogLevel is the lowest level the daemon logs: "debug", "info", "warn"
+	// or "error". --verbose lowers it to debug. Defaults to "info".
+	LogLevel string `yaml:"log_level,omitempty"`
+
+	// LogFormat is the format of the daemon's log lines: "text" or "json",
+	// one JSON object per line. Defaults to "text".
+	LogFormat string `yaml:"log_format,omitempty"`
+
+	// LogRotation limits the log file the daemon installed by setup writes.
+	// Omit the block to rotate at 10 MB or after 7 days and keep 5 rotated
+	// files.
+	LogRotation *LogRotationConfig `yaml:"log_rotation,omitempty"`
+
 	// ListMappings maps Apple Reminders list names to Home Assistant todo entity IDs.
 	// Example: {"Shopping": "todo.shopping", "Work": "todo.work_tasks"}
+	// A value of the form "<server>:<calendar>" maps the list to a task
+	// calendar on a server defined under CalDAV instead.
 	ListMappings map[string]string `yaml:"list_mappings"`
 
-	// Telemetry configures optional OpenTelemetry export via OTLP gRPC.
+	// SyncAllLists maps every Reminders list without an entry in
+	// ListMappings to the HA todo entity named after it, creating a Local
+	// To-do list where none exists. Derived mappings are not written back.
+	SyncAllLists bool `yaml:"sync_all_lists,omitempty"`
+
+	// ExcludeLists names Reminders lists SyncAllLists leaves out.
+	ExcludeLists []string `yaml:"exclude_lists,omitempty"`
+
+	// RemindersSources names the Reminders account, such as "iCloud" or
+	// "Exchange", each list is synced from, keyed by Reminders list. Only
+	// needed for a list whose name another account uses too; lists without
+	// an entry are looked up by name alone.
+	RemindersSources map[string]string `yaml:"reminders_sources,omitempty"`
+
+	// Quirks selects a preset for list mappings whose target is backed by a
+	// third-party integration, keyed by Reminders list: "todoist", "bring",
+	// "anylist", or "local_todo" for HA's own lists. Presets re-link items
+	// whose UID the integration changes and leave alone the fields it does
+	// not keep. Mappings without an entry are synced as local_todo.
+	Quirks map[string]string `yaml:"quirks,omitempty"`
+
+	// Mirrors lists further targets each Reminders list is published to,
+	// keyed by Reminders list, such as a copy for a wall tablet. The target
+	// in ListMappings stays authoritative; mirrors receive Reminders'
+	// content one way, and edits made in them are overwritten.
+	Mirrors map[string][]string `yaml:"mirrors,omitempty"`
+
+	// ListPrefixes tells apart Reminders lists mapped to the same target,
+	// keyed by Reminders list: a tag such as "[Work]" that starts the
+	// target's titles of that list's items, as in "[Work] Email boss". Items
+	// created on the target are routed to the list their tag names. Of the
+	// lists sharing a target, at most one may go without a tag; it receives
+	// the target's items that carry none.
+	ListPrefixes map[string]string `yaml:"list_prefixes,omitempty"`
+
+	// ParseShorthand turns on shorthand parsing for items created on the
+	// target, keyed by Reminders list: "milk !high tomorrow" is created in
+	// Reminders as "milk" with high priority, due tomorrow.
+	ParseShorthand map[string]bool `yaml:"parse_shorthand,omitempty"`
+
+	// Alerts selects how list mappings keep the alert time of Reminders
+	// items, keyed by Reminders list: "due" writes it as the target's due
+	// date, "description" keeps it in a tag in the description, and "off"
+	// leaves alerts out. Mappings without an entry are synced as off.
+	Alerts map[string]string `yaml:"alerts,omitempty"`
+
+	// CalDAV defines CalDAV servers that list mappings can target, keyed by a
+	// name used in list_mappings values. Omit the block to sync with Home
+	// Assistant only.
+	CalDAV map[string]*CalDAVServer `yaml:"caldav,omitempty"`
+
+	// Telemetry configures optional OpenTelemetry export via OTLP over gRPC
+	// or HTTP.
 	// Omit the block entirely to disable telemetry.
 	Telemetry *TelemetryConfig `yaml:"telemetry,omitempty"`
+
+	// Shadow runs selected list mappings in shadow mode: changes are planned
+	// and reported but never applied until the mapping is promoted.
+	// Omit the block entirely to sync every mapping normally.
+	Shadow *ShadowConfig `yaml:"shadow,omitempty"`
+
+	// ReadOnly makes the daemon and sync-once plan and log every change
+	// without writing to Reminders or Home Assistant, for a trial run.
+	ReadOnly bool `yaml:"read_only,omitempty"`
+
+	// Jobs overrides the schedule of the daemon's auxiliary jobs, keyed by job
+	// name. Omit the block to run every job on its default schedule.
+	Jobs map[string]*JobConfig `yaml:"jobs,omitempty"`
+
+	// Notify configures how sync problems are surfaced beyond the daemon logs.
+	// Omit the block entirely to only log problems.
+	Notify *NotifyConfig `yaml:"notify,omitempty"`
+
+	// Cache tunes snapshot caching of idle lists. Omit the block to cache
+	// with the default settings.
+	Cache *CacheConfig `yaml:"cache,omitempty"`
+
+	// Discovery controls how Reminders lists without a mapping are handled.
+	// Omit the block to log and report such lists without creating anything.
+	Discovery *DiscoveryConfig `yaml:"discovery,omitempty"`
+
+	// Backups tunes the state DB backups written daily by the db-backup job
+	// and at every daemon start. Omit the block to keep 7 backups.
+	Backups *BackupConfig `yaml:"backups,omitempty"`
+
+	// DeletionGuard limits how many items one pass may delete from a list,
+	// so an empty response during an outage does not wipe the other side.
+	// Omit the block to use the default limits.
+	DeletionGuard *DeletionGuardConfig `yaml:"deletion_guard,omitempty"`
+
+	// Bootstrap tunes how the first sync of a list mapping pairs existing
+	// items. Omit the block to pair items by normalised title only.
+	Bootstrap *BootstrapConfig `yaml:"bootstrap,omitempty"`
+
+	// Timezone is the IANA zone, e.g. "Europe/Berlin", due dates are
+	// interpreted in: a due date without a time is midnight in it, and is
+	// written to Home Assistant as the date it falls on there. Empty uses
+	// the system's local zone, as Reminders does.
+	Timezone string `yaml:"timezone,omitempty"`
+
+	// NormalizeTitles normalises titles before they are hashed and compared,
+	// so that sides storing the same title differently do not keep updating
+	// each other. Omit the block to compare titles as they are.
+	NormalizeTitles *NormalizeTitlesConfig `yaml:"normalize_titles,omitempty"`
+
+	// IgnoreMarkers opt single items out of the sync: a marker starting
+	// with "#", such as "#nosync", matches items with it as a word in the
+	// title or description; any other, such as "!", items whose title starts
+	// with it.
+	IgnoreMarkers []string `yaml:"ignore_markers,omitempty"`
+
+	// DashboardListen is the host:port the daemon serves its web dashboard
+	// on, e.g. "127.0.0.1:8787". Empty disables the dashboard. It must be a
+	// loopback address unless DashboardAllowLAN is set.
+	DashboardListen string `yaml:"dashboard_listen,omitempty"`
+
+	// DashboardAllowLAN lets DashboardListen be a non-loopback address,
+	// serving the dashboard, which has no login, to the network.
+	DashboardAllowLAN bool `yaml:"dashboard_allow_lan,omitempty"`
+
+	// StateDB is the path of the state database, e.g. on an encrypted
+	// volume. A relative path is resolved against the config file's
+	// directory. Empty uses $XDG_DATA_HOME/reminderrelay/state.db, falling
+	// back to ~/.local/share/reminderrelay/state.db. Backups are kept in a
+	// backups directory next to it.
+	StateDB string `yaml:"state_db,omitempty"`
+}
+
+// HATLSConfig holds TLS settings for the Home Assistant connection, used by
+// both its REST and WebSocket clients.
+type HATLSConfig struct {
+	// CAFile is a PEM bundle of CAs trusted for the HA server certificate,
+	// in addition to the system roots. Use it for a private CA. Relative
+	// paths, here and below, are resolved against the config file's
+	// directory.
+	CAFile string `yaml:"ca_file,omitempty"`
+
+	// CertFile and KeyFile are the PEM client certificate and key presented
+	// to a reverse proxy that requires one.
+	CertFile string `yaml:"cert_file,omitempty"`
+	KeyFile  string `yaml:"key_file,omitempty"`
+
+	// InsecureSkipVerify accepts any server certificate. Prefer CAFile.
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify,omitempty"`
+}
+
+// resolve makes the file paths of t absolute, resolving relative ones
+// against dir.
+func (t *HATLSConfig) resolve(dir string) error {
+	for _, p := range []*string{&t.CAFile, &t.CertFile, &t.KeyFile} {
+		if *p == "" {
+			continue
+		}
+		abs, err := resolvePath(dir, *p)
+		if err != nil {
+			return fmt.Errorf("ha_tls: %w", err)
+		}
+		*p = abs
+	}
+	return nil
+}
+
+// CalDAVServer holds the connection settings of one CalDAV account.
+type CalDAVServer struct {
+	// URL is the account's calendar home collection, e.g.
+	// "https://cloud.example.com/remote.php/dav/calendars/alice/". Calendars
+	// are addressed by name relative to it.
+	URL string `yaml:"url"`
+
+	// Username and Password are sent with HTTP basic authentication. Use an
+	// app password where the server offers one.
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+// CacheConfig holds fetch-cache settings shared by the Reminders and Home
+// Assistant adapters.
+type CacheConfig struct {
+	// Disabled turns caching off so every pass queries Reminders and Home
+	// Assistant directly.
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// MaxAge forces a full refresh of a cached list at least this often,
+	// even when no change was observed. Defaults to 5m.
+	MaxAge time.Duration `yaml:"max_age,omitempty"`
+
+	// HA caches Home Assistant lists too. Off by default: HA reports no
+	// change for edits that keep the number of open items, such as a
+	// rename, so with the cache they can take up to MaxAge to sync instead
+	// of one poll interval.
+	HA bool `yaml:"ha,omitempty"`
+}
+
+// NotifyConfig holds optional problem-notification settings.
+type NotifyConfig struct {
+	// HAPersistent posts a single Home Assistant persistent notification while
+	// the daemon is degraded and dismisses it once the problem is resolved.
+	HAPersistent bool `yaml:"ha_persistent"`
+
+	// FailureThreshold is the number of consecutive failed passes after which
+	// a list is reported as degraded. Defaults to 3.
+	FailureThreshold int `yaml:"failure_threshold,omitempty"`
+
+	// Conflicts posts a Home Assistant persistent notification showing both
+	// versions whenever overlapping edits are resolved, and fires a
+	// reminderrelay_conflict event for automations.
+	Conflicts bool `yaml:"conflicts,omitempty"`
+
+	// MacOS posts macOS user notifications on the Mac running the daemon for
+	// the same problems as HAPersistent and for every resolved conflict.
+	MacOS bool `yaml:"macos,omitempty"`
+
+	// MacOSMinInterval is the minimum time between two macOS notifications
+	// about the same problem or item, so an HA outage does not notify on
+	// every pass. Defaults to 30m.
+	MacOSMinInterval time.Duration `yaml:"macos_min_interval,omitempty"`
+}
+
+// DiscoveryConfig holds settings for Reminders lists that have no mapping.
+type DiscoveryConfig struct {
+	// AutoCreate creates a Home Assistant Local To-do list for every new
+	// Reminders list and adds the mapping to the config file. The daemon
+	// also creates one at startup for a mapping whose entity does not exist.
+	// Needs a token of an HA admin user.
+	AutoCreate bool `yaml:"auto_create,omitempty"`
+
+	// Ignore names Reminders lists that are never reported or created.
+	Ignore []string `yaml:"ignore,omitempty"`
+}
+
+// BackupConfig holds state DB backup settings. Disabling the db-backup job
+// under Jobs turns backups off altogether.
+type BackupConfig struct {
+	// Keep is the number of backups kept; older ones are removed. Defaults
+	// to 7.
+	Keep int `yaml:"keep,omitempty"`
+
+	// BeforeEachPass also backs up the state DB before every full sync
+	// pass. Each such backup counts towards Keep.
+	BeforeEachPass bool `yaml:"before_each_pass,omitempty"`
+}
+
+// LogRotationConfig holds log file rotation settings. Zero fields keep the
+// defaults.
+type LogRotationConfig struct {
+	// MaxSizeMB is the size in megabytes after which the log file is
+	// rotated. Defaults to 10.
+	MaxSizeMB int `yaml:"max_size_mb,omitempty"`
+
+	// MaxAge is how long a log file is written before it is rotated.
+	// Defaults to 7 days.
+	MaxAge time.Duration `yaml:"max_age,omitempty"`
+
+	// Keep is the number of rotated files kept; older ones are removed.
+	// Defaults to 5.
+	Keep int `yaml:"keep,omitempty"`
+}
+
+// DeletionGuardConfig holds the limits on deletions per pass. A list whose
+// pass would exceed either limit is not synced until the pass is forced with
+// `reminderrelay sync-once --force`. Zero fields keep the defaults.
+type DeletionGuardConfig struct {
+	// Disabled turns the guard off.
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// MaxItems is the number of items one pass may delete from a list.
+	// Defaults to 25.
+	MaxItems int `yaml:"max_items,omitempty"`
+
+	// MaxPercent is the share of a list's items, in percent, one pass may
+	// delete. Passes deleting five items or fewer are always allowed.
+	// Defaults to 50.
+	MaxPercent int `yaml:"max_percent,omitempty"`
+}
+
+// BootstrapConfig holds settings for the first sync of a list mapping.
+type BootstrapConfig struct {
+	// FuzzyMatch offers to link items whose titles are alike but not equal,
+	// e.g. "Call plumber" and "Call the plumber", asking about each pair.
+	FuzzyMatch bool `yaml:"fuzzy_match,omitempty"`
+
+	// FuzzyThreshold is how alike two titles must be to be offered, from 0
+	// to 1 for equal titles. Defaults to 0.8.
+	FuzzyThreshold float64 `yaml:"fuzzy_threshold,omitempty"`
+
+	// OnMismatch settles pairs whose titles match but whose contents differ:
+	// "ask" (the default) shows each one and asks which side to keep,
+	// "reminders" or "ha" keep that side, and "newest" keeps the version
+	// modified last.
+	OnMismatch string `yaml:"on_mismatch,omitempty"`
+
+	// OnCompletionMismatch settles pairs completed on one side only: "ask"
+	// (the default) shows each one and asks, "prefer-open" reopens the
+	// completed item, and "prefer-completed" completes the open one.
+	OnCompletionMismatch string `yaml:"on_completion_mismatch,omitempty"`
+}
+
+// NormalizeTitlesConfig selects the normalisation steps applied to titles.
+// A normalised title is also what is written when the item is next synced.
+type NormalizeTitlesConfig struct {
+	// Trim strips leading and trailing whitespace.
+	Trim bool `yaml:"trim,omitempty"`
+
+	// CollapseWhitespace replaces each run of whitespace with one space.
+	CollapseWhitespace bool `yaml:"collapse_whitespace,omitempty"`
+
+	// NFC composes titles to Unicode normalisation form C, so that "é"
+	// typed as "e" and a combining accent equals a precomposed "é".
+	NFC bool `yaml:"nfc,omitempty"`
+
+	// Punctuation folds typographic quotes, dashes, and ellipses to ASCII,
+	// so that "Don’t" equals "Don't".
+	Punctuation bool `yaml:"punctuation,omitempty"`
+}
+
+// JobConfig overrides the schedule of one auxiliary job. Zero fields keep the
+// job's defaults.
+type JobConfig struct {
+	// Disabled stops the job from running.
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// Every is the interval between runs. Minimum 1m.
+	Every time.Duration `yaml:"every,omitempty"`
+
+	// Jitter is the maximum random delay added to each run.
+	Jitter time.Duration `yaml:"jitter,omitempty"`
+}
+
+// ShadowConfig holds settings for shadow-mode list mappings.
+type ShadowConfig struct {
+	// Lists names the Reminders lists (keys of list_mappings) to run in
+	// shadow mode.
+	Lists []string `yaml:"lists"`
+
+	// Passes is the number of reconcile passes a list stays in shadow mode
+	// before it becomes eligible for promotion. Defaults to 3.
+	Passes int `yaml:"passes,omitempty"`
+
+	// AutoPromote promotes a shadow list to normal syncing automatically once
+	// Passes is reached. When false, promotion waits for
+	// `reminderrelay promote <list>`.
+	AutoPromote bool `yaml:"auto_promote,omitempty"`
 }
 
 // TelemetryConfig holds optional OpenTelemetry settings.
 type TelemetryConfig struct {
-	// OTLPEndpoint is the gRPC host:port of the OTLP collector (e.g. "localhost:4317").
-	OTLPEndpoint string `yaml:"otlp_endpoint"`
+	// Exporter is "otlp" to send to a collector or "stdout" to write spans
+	// and metrics as JSON for local debugging. Defaults to "otlp".
+	Exporter string `yaml:"exporter,omitempty"`
+
+	// File is where the stdout exporter appends instead of stdout. A
+	// relative path is resolved against the config file's directory.
+	File string `yaml:"file,omitempty"`
+
+	// OTLPEndpoint is the host:port of the OTLP collector (e.g.
+	// "localhost:4317"). With the http protocol it may also be a base URL
+	// such as "https://otlp.example.com/otlp". Required for the otlp
+	// exporter.
+	OTLPEndpoint string `yaml:"otlp_endpoint,omitempty"`
+
+	// Protocol is the OTLP transport: "grpc" or "http". Defaults to "grpc".
+	Protocol string `yaml:"protocol,omitempty"`
 
 	// Insecure disables TLS for the collector connection. Use for local collectors.
 	Insecure bool `yaml:"insecure"`
@@ -43,35 +470,66 @@ type TelemetryConfig struct {
 	// ServiceName overrides the OTel service.name attribute. Defaults to "reminderrelay".
 	ServiceName string `yaml:"service_name"`
 
-	// Headers contains key-value pairs sent as gRPC metadata on every OTLP
-	// request. Equivalent to the OTEL_EXPORTER_OTLP_HEADERS environment
+	// Headers contains key-value pairs sent as gRPC metadata or HTTP headers
+	// on every OTLP request. Equivalent to the OTEL_EXPORTER_OTLP_HEADERS environment
 	// variable. Use this for authentication tokens, e.g.:
 	//   Authorization: "Bearer <token>"
 	Headers map[string]string `yaml:"headers,omitempty"`
 }
 
-// DefaultPath returns the default config file path: ~/.config/reminderrelay/config.yaml.
+// DefaultPath returns the default config file path of the selected profile:
+// ~/.config/reminderrelay/config.yaml, or
+// ~/.config/reminderrelay/<profile>/config.yaml.
 func DefaultPath() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("resolving home directory: %w", err)
 	}
-	return filepath.Join(home, ".config", "reminderrelay", "config.yaml"), nil
+	return filepath.Join(profile.Dir(filepath.Join(home, ".config", "reminderrelay")), "config.yaml"), nil
 }
 
-// Load reads and validates the configuration file at the given path.
+// Load reads and validates the configuration file at the given path,
+// merging in any files it includes (see the include key in
+// config.example.yaml).
 func Load(path string) (*Config, error) {
-	f, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("opening config file %q: %w", path, err)
 	}
-	defer func() { _ = f.Close() }()
 
+	cfg, err := parseAt(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("config file %q: %w", path, err)
+	}
+	if cfg.HATLS != nil {
+		if err := cfg.HATLS.resolve(filepath.Dir(path)); err != nil {
+			return nil, fmt.Errorf("config file %q: %w", path, err)
+		}
+	}
+	if cfg.StateDB != "" {
+		abs, err := resolvePath(filepath.Dir(path), cfg.StateDB)
+		if err != nil {
+			return nil, fmt.Errorf("config file %q: state_db: %w", path, err)
+		}
+		cfg.StateDB = abs
+	}
+	if cfg.Telemetry != nil && cfg.Telemetry.File != "" {
+		abs, err := resolvePath(filepath.Dir(path), cfg.Telemetry.File)
+		if err != nil {
+			return nil, fmt.Errorf("config file %q: telemetry.file: %w", path, err)
+		}
+		cfg.Telemetry.File = abs
+	}
+	return cfg, nil
+}
+
+// parse decodes and validates a configuration document.
+func parse(r io.Reader) (*Config, error) {
 	var cfg Config
-	dec := yaml.NewDecoder(f)
+	dec := yaml.NewDecoder(r)
 	dec.KnownFields(true) // reject unknown keys to catch typos early
 	if err := dec.Decode(&cfg); err != nil {
-		return nil, fmt.Errorf("parsing config file %q: %w", path, err)
+		return nil, fmt.Errorf("parsing: %w", err)
 	}
 
 	if err := cfg.validate(); err != nil {
@@ -81,6 +539,19 @@ func Load(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// Location returns the zone Timezone names, or the system's local zone when
+// it is empty.
+func (c *Config) Location() *time.Location {
+	if c.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.Local // rejected by validate
+	}
+	return loc
+}
+
 // validate checks that all required fields are present and well-formed.
 func (c *Config) validate() error {
 	if c.HAURL == "" {
@@ -91,10 +562,35 @@ func (c *Config) validate() error {
 		return fmt.Errorf("ha_url %q must be a valid http or https URL", c.HAURL)
 	}
 
+	for _, fallback := range c.HAFallbackURLs {
+		u, err := url.ParseRequestURI(fallback)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("ha_fallback_urls entry %q must be a valid http or https URL", fallback)
+		}
+		if fallback == c.HAURL {
+			return fmt.Errorf("ha_fallback_urls repeats ha_url %q", fallback)
+		}
+	}
+
 	if c.HAToken == "" {
 		return fmt.Errorf("ha_token is required")
 	}
 
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return fmt.Errorf("timezone %q: %w", c.Timezone, err)
+		}
+	}
+
+	if c.HATLS != nil {
+		if (c.HATLS.CertFile == "") != (c.HATLS.KeyFile == "") {
+			return fmt.Errorf("ha_tls.cert_file and ha_tls.key_file must be set together")
+		}
+		if c.HATLS.CertFile != "" && !strings.HasPrefix(c.HAURL, "https:") {
+			return fmt.Errorf("ha_tls.cert_file needs an https ha_url")
+		}
+	}
+
 	if c.PollInterval == 0 {
 		c.PollInterval = 30 * time.Second
 	}
@@ -104,9 +600,79 @@ func (c *Config) validate() error {
 	if c.PollInterval > 5*time.Minute {
 		return fmt.Errorf("poll_interval %v is too long (maximum 5m)", c.PollInterval)
 	}
+	if c.SafetyPollInterval == 0 {
+		c.SafetyPollInterval = max(5*time.Minute, c.PollInterval)
+	}
+	if c.SafetyPollInterval < c.PollInterval {
+		return fmt.Errorf("safety_poll_interval %v is shorter than poll_interval %v", c.SafetyPollInterval, c.PollInterval)
+	}
+	if c.SafetyPollInterval > time.Hour {
+		return fmt.Errorf("safety_poll_interval %v is too long (maximum 1h)", c.SafetyPollInterval)
+	}
+
+	if c.ParallelLists == 0 {
+		c.ParallelLists = 4
+	}
+	if c.ParallelLists < 1 || c.ParallelLists > 16 {
+		return fmt.Errorf("parallel_lists %d must be between 1 and 16", c.ParallelLists)
+	}
+
+	if c.HATimeout == 0 {
+		c.HATimeout = 30 * time.Second
+	}
+	if c.HATimeout < time.Second || c.HATimeout > 5*time.Minute {
+		return fmt.Errorf("ha_timeout %v must be between 1s and 5m", c.HATimeout)
+	}
+	if c.PassTimeout == 0 {
+		c.PassTimeout = max(10*time.Minute, c.HATimeout)
+	}
+	if c.PassTimeout < c.HATimeout {
+		return fmt.Errorf("pass_timeout %v is shorter than ha_timeout %v", c.PassTimeout, c.HATimeout)
+	}
+	if c.PassTimeout > time.Hour {
+		return fmt.Errorf("pass_timeout %v is too long (maximum 1h)", c.PassTimeout)
+	}
+	if c.HARateLimit == 0 {
+		c.HARateLimit = 10
+	}
+	if c.HARateLimit < 1 || c.HARateLimit > 1000 {
+		return fmt.Errorf("ha_rate_limit %v must be between 1 and 1000", c.HARateLimit)
+	}
+	if c.ShutdownGrace == 0 {
+		c.ShutdownGrace = 10 * time.Second
+	}
+	if c.ShutdownGrace < time.Second || c.ShutdownGrace > 15*time.Second {
+		return fmt.Errorf("shutdown_grace %v must be between 1s and 15s", c.ShutdownGrace)
+	}
 
-	if len(c.ListMappings) == 0 {
-		return fmt.Errorf("list_mappings must contain at least one entry")
+	if c.LatencyObjective < 0 {
+		return fmt.Errorf("latency_objective %v must not be negative", c.LatencyObjective)
+	}
+	switch c.LogLevel {
+	case "":
+		c.LogLevel = "info"
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("log_level %q must be debug, info, warn or error", c.LogLevel)
+	}
+	switch c.LogFormat {
+	case "":
+		c.LogFormat = "text"
+	case "text", "json":
+	default:
+		return fmt.Errorf("log_format %q must be text or json", c.LogFormat)
+	}
+
+	if len(c.ListMappings) == 0 && !c.SyncAllLists {
+		return fmt.Errorf("list_mappings must contain at least one entry unless sync_all_lists is set")
+	}
+	if len(c.ExcludeLists) > 0 && !c.SyncAllLists {
+		return fmt.Errorf("exclude_lists only applies with sync_all_lists")
+	}
+	for _, list := range c.ExcludeLists {
+		if list == "" {
+			return fmt.Errorf("exclude_lists contains an empty Reminders list name")
+		}
 	}
 	for list, entity := range c.ListMappings {
 		if list == "" {
@@ -115,17 +681,310 @@ func (c *Config) validate() error {
 		if entity == "" {
 			return fmt.Errorf("list_mappings[%q] has an empty HA entity ID", list)
 		}
+		if server, calendar, ok := strings.Cut(entity, ":"); ok && !strings.Contains(server, ".") {
+			if _, known := c.CalDAV[server]; !known {
+				return fmt.Errorf("list_mappings[%q] targets CalDAV server %q, which is not defined under caldav", list, server)
+			}
+			if calendar == "" {
+				return fmt.Errorf("list_mappings[%q] has an empty CalDAV calendar name", list)
+			}
+		}
+	}
+
+	for name, server := range c.CalDAV {
+		if name == "" || strings.ContainsAny(name, ":.") {
+			return fmt.Errorf("caldav server name %q must be non-empty and contain no ':' or '.'", name)
+		}
+		if server == nil || server.URL == "" {
+			return fmt.Errorf("caldav.%s.url is required", name)
+		}
+		u, err := url.ParseRequestURI(server.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("caldav.%s.url %q must be a valid http or https URL", name, server.URL)
+		}
 	}
 
 	if c.Telemetry != nil {
-		if c.Telemetry.OTLPEndpoint == "" {
+		switch c.Telemetry.Exporter {
+		case "":
+			c.Telemetry.Exporter = "otlp"
+		case "otlp", "stdout":
+		default:
+			return fmt.Errorf("telemetry.exporter %q must be otlp or stdout", c.Telemetry.Exporter)
+		}
+		if c.Telemetry.File != "" && c.Telemetry.Exporter != "stdout" {
+			return fmt.Errorf("telemetry.file only applies with exporter: stdout")
+		}
+		if c.Telemetry.OTLPEndpoint == "" && c.Telemetry.Exporter == "otlp" {
 			return fmt.Errorf("telemetry.otlp_endpoint is required when telemetry is configured")
 		}
+		if c.Telemetry.Protocol == "" {
+			c.Telemetry.Protocol = "grpc"
+		}
+		if c.Telemetry.Protocol != "grpc" && c.Telemetry.Protocol != "http" {
+			return fmt.Errorf("telemetry.protocol %q must be grpc or http", c.Telemetry.Protocol)
+		}
+	}
+
+	if c.Notify != nil {
+		if c.Notify.FailureThreshold == 0 {
+			c.Notify.FailureThreshold = 3
+		}
+		if c.Notify.FailureThreshold < 1 {
+			return fmt.Errorf("notify.failure_threshold must be at least 1")
+		}
+		if c.Notify.MacOSMinInterval < 0 {
+			return fmt.Errorf("notify.macos_min_interval must not be negative")
+		}
+	}
+
+	for name, job := range c.Jobs {
+		if job == nil {
+			continue
+		}
+		if job.Every != 0 && job.Every < time.Minute {
+			return fmt.Errorf("jobs.%s.every %v is too short (minimum 1m)", name, job.Every)
+		}
+		if job.Jitter < 0 {
+			return fmt.Errorf("jobs.%s.jitter must not be negative", name)
+		}
+	}
+
+	if c.Cache != nil {
+		if c.Cache.MaxAge == 0 {
+			c.Cache.MaxAge = 5 * time.Minute
+		}
+		if c.Cache.MaxAge < 0 {
+			return fmt.Errorf("cache.max_age must not be negative")
+		}
+	}
+
+	if c.DashboardListen != "" {
+		host, port, err := net.SplitHostPort(c.DashboardListen)
+		if err != nil {
+			return fmt.Errorf("dashboard_listen %q must be host:port: %w", c.DashboardListen, err)
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("dashboard_listen %q has an invalid port", c.DashboardListen)
+		}
+		if !c.DashboardAllowLAN && !IsLoopbackHost(host) {
+			return fmt.Errorf("dashboard_listen %q is not a loopback address; the dashboard has no login, "+
+				"so set dashboard_allow_lan: true to serve it to the network anyway", c.DashboardListen)
+		}
 	}
 
+	if c.Backups != nil {
+		if c.Backups.Keep == 0 {
+			c.Backups.Keep = 7
+		}
+		if c.Backups.Keep < 1 {
+			return fmt.Errorf("backups.keep must be at least 1")
+		}
+	}
+
+	if c.LogRotation != nil {
+		if c.LogRotation.MaxSizeMB == 0 {
+			c.LogRotation.MaxSizeMB = 10
+		}
+		if c.LogRotation.MaxAge == 0 {
+			c.LogRotation.MaxAge = 7 * 24 * time.Hour
+		}
+		if c.LogRotation.Keep == 0 {
+			c.LogRotation.Keep = 5
+		}
+		if c.LogRotation.MaxSizeMB < 1 {
+			return fmt.Errorf("log_rotation.max_size_mb must be at least 1")
+		}
+		if c.LogRotation.MaxAge < time.Hour {
+			return fmt.Errorf("log_rotation.max_age %v must be at least 1h", c.LogRotation.MaxAge)
+		}
+		if c.LogRotation.Keep < 1 {
+			return fmt.Errorf("log_rotation.keep must be at least 1")
+		}
+	}
+
+	if c.DeletionGuard != nil {
+		if c.DeletionGuard.MaxItems < 0 {
+			return fmt.Errorf("deletion_guard.max_items must not be negative")
+		}
+		if c.DeletionGuard.MaxPercent < 0 || c.DeletionGuard.MaxPercent > 100 {
+			return fmt.Errorf("deletion_guard.max_percent must be between 0 and 100")
+		}
+	}
+
+	if c.Bootstrap != nil {
+		switch c.Bootstrap.OnMismatch {
+		case "", "ask", "reminders", "ha", "newest":
+		default:
+			return fmt.Errorf("bootstrap.on_mismatch must be ask, reminders, ha, or newest, got %q", c.Bootstrap.OnMismatch)
+		}
+		switch c.Bootstrap.OnCompletionMismatch {
+		case "", "ask", "prefer-open", "prefer-completed":
+		default:
+			return fmt.Errorf("bootstrap.on_completion_mismatch must be ask, prefer-open, or prefer-completed, got %q", c.Bootstrap.OnCompletionMismatch)
+		}
+	}
+	if c.Bootstrap != nil && c.Bootstrap.FuzzyMatch {
+		if c.Bootstrap.FuzzyThreshold == 0 {
+			c.Bootstrap.FuzzyThreshold = 0.8
+		}
+		if c.Bootstrap.FuzzyThreshold < 0 || c.Bootstrap.FuzzyThreshold > 1 {
+			return fmt.Errorf("bootstrap.fuzzy_threshold must be between 0 and 1")
+		}
+	}
+
+	for _, marker := range c.IgnoreMarkers {
+		if marker == "" || marker == "#" || strings.ContainsFunc(marker, unicode.IsSpace) {
+			return fmt.Errorf("ignore_markers contains %q; markers must be non-empty and contain no whitespace", marker)
+		}
+	}
+
+	for list, source := range c.RemindersSources {
+		if _, ok := c.ListMappings[list]; !ok {
+			return fmt.Errorf("reminders_sources contains %q, which is not in list_mappings", list)
+		}
+		if source == "" {
+			return fmt.Errorf("reminders_sources[%q] must name a Reminders account", list)
+		}
+	}
+
+	for list, preset := range c.Quirks {
+		if _, ok := c.ListMappings[list]; !ok {
+			return fmt.Errorf("quirks contains %q, which is not in list_mappings", list)
+		}
+		switch preset {
+		case "local_todo", "todoist", "bring", "anylist":
+		default:
+			return fmt.Errorf("quirks[%q] %q must be local_todo, todoist, bring or anylist", list, preset)
+		}
+	}
+
+	if err := c.validateListPrefixes(); err != nil {
+		return err
+	}
+
+	mapped := make(map[string]bool, len(c.ListMappings))
+	for _, target := range c.ListMappings {
+		mapped[target] = true
+	}
+	for list, targets := range c.Mirrors {
+		if _, ok := c.ListMappings[list]; !ok {
+			return fmt.Errorf("mirrors contains %q, which is not in list_mappings", list)
+		}
+		for _, target := range targets {
+			switch server, _, ok := strings.Cut(target, ":"); {
+			case target == "":
+				return fmt.Errorf("mirrors[%q] contains an empty target", list)
+			case mapped[target]:
+				return fmt.Errorf("mirrors[%q] target %q is already used by list_mappings or another mirror", list, target)
+			case ok && !strings.Contains(server, "."):
+				if _, known := c.CalDAV[server]; !known {
+					return fmt.Errorf("mirrors[%q] targets CalDAV server %q, which is not defined under caldav", list, server)
+				}
+			}
+			mapped[target] = true
+		}
+	}
+
+	for list := range c.ParseShorthand {
+		if _, ok := c.ListMappings[list]; !ok {
+			return fmt.Errorf("parse_shorthand contains %q, which is not in list_mappings", list)
+		}
+	}
+
+	for list, policy := range c.Alerts {
+		if _, ok := c.ListMappings[list]; !ok {
+			return fmt.Errorf("alerts contains %q, which is not in list_mappings", list)
+		}
+		switch policy {
+		case "off", "due", "description":
+		default:
+			return fmt.Errorf("alerts[%q] %q must be off, due or description", list, policy)
+		}
+	}
+
+	if c.Shadow != nil {
+		if c.Shadow.Passes == 0 {
+			c.Shadow.Passes = 3
+		}
+		if c.Shadow.Passes < 1 {
+			return fmt.Errorf("shadow.passes must be at least 1")
+		}
+		for _, list := range c.Shadow.Lists {
+			if _, ok := c.ListMappings[list]; !ok {
+				return fmt.Errorf("shadow.lists contains %q, which is not in list_mappings", list)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateListPrefixes checks the tags of list_prefixes and that lists
+// sharing a target can be told apart by them. Shared targets have one set of
+// alerts and quirks, so their lists must agree on those too.
+func (c *Config) validateListPrefixes() error {
+	for list, tag := range c.ListPrefixes {
+		if _, ok := c.ListMappings[list]; !ok {
+			return fmt.Errorf("list_prefixes contains %q, which is not in list_mappings", list)
+		}
+		if strings.TrimSpace(tag) == "" || tag != strings.TrimSpace(tag) {
+			return fmt.Errorf("list_prefixes[%q] %q must be non-empty and not start or end with whitespace", list, tag)
+		}
+	}
+
+	shared := make(map[string][]string, len(c.ListMappings))
+	for _, list := range slices.Sorted(maps.Keys(c.ListMappings)) {
+		target := c.ListMappings[list]
+		shared[target] = append(shared[target], list)
+	}
+	for _, list := range slices.Sorted(maps.Keys(c.ListPrefixes)) {
+		if len(shared[c.ListMappings[list]]) < 2 {
+			return fmt.Errorf("list_prefixes[%q] is set, but no other list maps to %q", list, c.ListMappings[list])
+		}
+	}
+	for _, target := range slices.Sorted(maps.Keys(shared)) {
+		lists := shared[target]
+		if len(lists) < 2 {
+			continue
+		}
+		untagged := ""
+		for i, list := range lists {
+			tag, ok := c.ListPrefixes[list]
+			if !ok {
+				if untagged != "" {
+					return fmt.Errorf("lists %q and %q both map to %q; all but one need list_prefixes", untagged, list, target)
+				}
+				untagged = list
+			}
+			for _, other := range lists[:i] {
+				otherTag, tagged := c.ListPrefixes[other]
+				if ok && tagged && otherTag == tag {
+					return fmt.Errorf("list_prefixes[%q] and list_prefixes[%q] are both %q, but the lists share %q", other, list, tag, target)
+				}
+				if c.Alerts[other] != c.Alerts[list] {
+					return fmt.Errorf("alerts[%q] and alerts[%q] must match, as both lists map to %q", other, list, target)
+				}
+				if c.Quirks[other] != c.Quirks[list] {
+					return fmt.Errorf("quirks[%q] and quirks[%q] must match, as both lists map to %q", other, list, target)
+				}
+			}
+		}
+	}
 	return nil
 }
 
+// IsLoopbackHost reports whether host, the host part of a listen address,
+// only accepts connections from this machine: localhost or a loopback IP.
+// An empty host listens on all interfaces.
+func IsLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // Write serializes the configuration to YAML and writes it to the given path.
 // Parent directories are created with mode 0700; the file itself is written
 // with mode 0600 because it contains the HA access token.
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -63,6 +64,347 @@ list_mappings:
 	if cfg.PollInterval != 30*time.Second {
 		t.Errorf("PollInterval = %v, want default 30s", cfg.PollInterval)
 	}
+	if cfg.SafetyPollInterval != 5*time.Minute {
+		t.Errorf("SafetyPollInterval = %v, want default 5m", cfg.SafetyPollInterval)
+	}
+}
+
+func TestLoad_SafetyPollInterval(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"set", "safety_poll_interval: 15m", 15 * time.Minute, false},
+		{"default follows a longer poll_interval", "poll_interval: 5m", 5 * time.Minute, false},
+		{"shorter than poll_interval", "poll_interval: 2m\nsafety_poll_interval: 1m", 0, true},
+		{"too long", "safety_poll_interval: 2h", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+`+tt.yaml+`
+list_mappings:
+  Shopping: todo.shopping
+`)
+			cfg, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.SafetyPollInterval != tt.want {
+				t.Errorf("SafetyPollInterval = %v, want %v", cfg.SafetyPollInterval, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_ParallelLists(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    int
+		wantErr bool
+	}{
+		{"default", "", 4, false},
+		{"set", "parallel_lists: 8", 8, false},
+		{"negative", "parallel_lists: -1", 0, true},
+		{"too many", "parallel_lists: 17", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+`+tt.yaml+`
+list_mappings:
+  Shopping: todo.shopping
+`)
+			cfg, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.ParallelLists != tt.want {
+				t.Errorf("ParallelLists = %d, want %d", cfg.ParallelLists, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_Timeouts(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		wantHA   time.Duration
+		wantPass time.Duration
+		wantErr  bool
+	}{
+		{"defaults", "", 30 * time.Second, 10 * time.Minute, false},
+		{"set", "ha_timeout: 5s\npass_timeout: 2m", 5 * time.Second, 2 * time.Minute, false},
+		{"ha too short", "ha_timeout: 500ms", 0, 0, true},
+		{"pass shorter than ha", "ha_timeout: 1m\npass_timeout: 30s", 0, 0, true},
+		{"pass too long", "pass_timeout: 2h", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+`+tt.yaml+`
+list_mappings:
+  Shopping: todo.shopping
+`)
+			cfg, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (cfg.HATimeout != tt.wantHA || cfg.PassTimeout != tt.wantPass) {
+				t.Errorf("timeouts = %v, %v, want %v, %v", cfg.HATimeout, cfg.PassTimeout, tt.wantHA, tt.wantPass)
+			}
+		})
+	}
+}
+
+func TestLoad_HARateLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    float64
+		wantErr bool
+	}{
+		{"default", "", 10, false},
+		{"fractional", "ha_rate_limit: 2.5", 2.5, false},
+		{"too low", "ha_rate_limit: 0.5", 0, true},
+		{"too high", "ha_rate_limit: 5000", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+`+tt.yaml+`
+list_mappings:
+  Shopping: todo.shopping
+`)
+			cfg, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.HARateLimit != tt.want {
+				t.Errorf("ha_rate_limit = %v, want %v", cfg.HARateLimit, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_ShutdownGrace(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"default", "", 10 * time.Second, false},
+		{"set", "shutdown_grace: 3s", 3 * time.Second, false},
+		{"too short", "shutdown_grace: 100ms", 0, true},
+		{"past launchd's timeout", "shutdown_grace: 30s", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+`+tt.yaml+`
+list_mappings:
+  Shopping: todo.shopping
+`)
+			cfg, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.ShutdownGrace != tt.want {
+				t.Errorf("shutdown_grace = %v, want %v", cfg.ShutdownGrace, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_LogLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    string
+		wantErr bool
+	}{
+		{"default", "", "info", false},
+		{"debug", "log_level: debug", "debug", false},
+		{"unknown", "log_level: verbose", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+`+tt.yaml+`
+list_mappings:
+  Shopping: todo.shopping
+`)
+			cfg, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.LogLevel != tt.want {
+				t.Errorf("log_level = %q, want %q", cfg.LogLevel, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_LogFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    string
+		wantErr bool
+	}{
+		{"default", "", "text", false},
+		{"json", "log_format: json", "json", false},
+		{"unknown", "log_format: logfmt", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+`+tt.yaml+`
+list_mappings:
+  Shopping: todo.shopping
+`)
+			cfg, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.LogFormat != tt.want {
+				t.Errorf("log_format = %q, want %q", cfg.LogFormat, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_Timezone(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    string
+		wantErr bool
+	}{
+		{"default", "", time.Local.String(), false},
+		{"named", "timezone: Europe/Berlin", "Europe/Berlin", false},
+		{"unknown", "timezone: Mars/Olympus_Mons", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+`+tt.yaml+`
+list_mappings:
+  Shopping: todo.shopping
+`)
+			cfg, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.Location().String() != tt.want {
+				t.Errorf("Location() = %q, want %q", cfg.Location(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_LogRotation(t *testing.T) {
+	base := `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+`
+	cfg, err := Load(writeConfig(t, base+"log_rotation:\n  max_size_mb: 50\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r := cfg.LogRotation; r.MaxSizeMB != 50 || r.MaxAge != 7*24*time.Hour || r.Keep != 5 {
+		t.Errorf("log_rotation = %+v, want max_size_mb 50 and the defaults", r)
+	}
+
+	for _, bad := range []string{"max_size_mb: -1", "max_age: 10m", "keep: -2"} {
+		if _, err := Load(writeConfig(t, base+"log_rotation:\n  "+bad+"\n")); err == nil {
+			t.Errorf("expected error for log_rotation %q, got nil", bad)
+		}
+	}
+}
+
+func TestLoad_HATLS(t *testing.T) {
+	path := writeConfig(t, `
+ha_url: "https://ha.local:8123"
+ha_token: "token"
+ha_tls:
+  ca_file: certs/ca.pem
+  cert_file: /etc/reminderrelay/client.pem
+  key_file: /etc/reminderrelay/client-key.pem
+list_mappings:
+  Shopping: todo.shopping
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := filepath.Join(filepath.Dir(path), "certs", "ca.pem"); cfg.HATLS.CAFile != want {
+		t.Errorf("ca_file = %q, want %q resolved against the config file", cfg.HATLS.CAFile, want)
+	}
+	if cfg.HATLS.CertFile != "/etc/reminderrelay/client.pem" {
+		t.Errorf("cert_file = %q, want the absolute path kept", cfg.HATLS.CertFile)
+	}
+
+	path = writeConfig(t, `
+ha_url: "https://ha.local:8123"
+ha_token: "token"
+ha_tls:
+  cert_file: client.pem
+list_mappings:
+  Shopping: todo.shopping
+`)
+	if _, err := Load(path); err == nil {
+		t.Error("Load() accepted cert_file without key_file")
+	}
+}
+
+func TestLoad_HAFallbackURLs(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{"remote", `["https://abc.ui.nabu.casa"]`, false},
+		{"not a URL", `["abc.ui.nabu.casa"]`, true},
+		{"repeats ha_url", `["http://ha.local:8123"]`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_fallback_urls: `+tt.yaml+`
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+`)
+			if _, err := Load(path); (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
 }
 
 func TestLoad_MissingHAURL(t *testing.T) {
@@ -142,6 +484,33 @@ list_mappings: {}
 	}
 }
 
+func TestLoad_SyncAllLists(t *testing.T) {
+	path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+sync_all_lists: true
+exclude_lists: [Archive]
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.SyncAllLists || len(cfg.ExcludeLists) != 1 {
+		t.Errorf("SyncAllLists = %v, ExcludeLists = %v", cfg.SyncAllLists, cfg.ExcludeLists)
+	}
+
+	path = writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+exclude_lists: [Archive]
+`)
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for exclude_lists without sync_all_lists, got nil")
+	}
+}
+
 func TestLoad_UnknownKey(t *testing.T) {
 	path := writeConfig(t, `
 ha_url: "http://ha.local:8123"
@@ -233,6 +602,67 @@ telemetry:
 	}
 }
 
+func TestLoad_TelemetryProtocol(t *testing.T) {
+	base := `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+telemetry:
+  otlp_endpoint: "https://otlp.example.com/otlp"
+`
+	cfg, err := Load(writeConfig(t, base))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Telemetry.Protocol != "grpc" {
+		t.Errorf("Protocol = %q, want grpc by default", cfg.Telemetry.Protocol)
+	}
+
+	cfg, err = Load(writeConfig(t, base+"  protocol: http\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Telemetry.Protocol != "http" {
+		t.Errorf("Protocol = %q, want http", cfg.Telemetry.Protocol)
+	}
+
+	if _, err := Load(writeConfig(t, base+"  protocol: thrift\n")); err == nil {
+		t.Error("expected error for unknown telemetry.protocol, got nil")
+	}
+}
+
+func TestLoad_TelemetryStdoutExporter(t *testing.T) {
+	path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+telemetry:
+  exporter: stdout
+  file: telemetry.jsonl
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Telemetry.Exporter != "stdout" {
+		t.Errorf("Exporter = %q, want stdout", cfg.Telemetry.Exporter)
+	}
+	if want := filepath.Join(filepath.Dir(path), "telemetry.jsonl"); cfg.Telemetry.File != want {
+		t.Errorf("File = %q, want %q next to the config", cfg.Telemetry.File, want)
+	}
+
+	for _, bad := range []string{
+		"telemetry:\n  exporter: zipkin\n",
+		"telemetry:\n  otlp_endpoint: localhost:4317\n  file: telemetry.jsonl\n",
+	} {
+		if _, err := Load(writeConfig(t, "ha_url: http://ha.local:8123\nha_token: token\nlist_mappings:\n  Shopping: todo.shopping\n"+bad)); err == nil {
+			t.Errorf("expected error for %q, got nil", bad)
+		}
+	}
+}
+
 func TestLoad_TelemetryHeaders(t *testing.T) {
 	path := writeConfig(t, `
 ha_url: "http://ha.local:8123"
@@ -259,3 +689,488 @@ telemetry:
 		t.Errorf("x-dataset header = %q, want %q", cfg.Telemetry.Headers["x-dataset"], "test")
 	}
 }
+
+func TestLoad_ShadowDefaults(t *testing.T) {
+	path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+  Groceries: todo.groceries
+shadow:
+  lists: [Groceries]
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Shadow == nil {
+		t.Fatal("expected Shadow to be non-nil")
+	}
+	if cfg.Shadow.Passes != 3 {
+		t.Errorf("Passes = %d, want default 3", cfg.Shadow.Passes)
+	}
+	if cfg.Shadow.AutoPromote {
+		t.Error("AutoPromote = true, want false by default")
+	}
+}
+
+func TestLoad_ShadowUnknownList(t *testing.T) {
+	path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+shadow:
+  lists: [Groceries]
+`)
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("expected error for shadow list missing from list_mappings, got nil")
+	}
+}
+
+func TestLoad_Quirks(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{"preset", "quirks:\n  Shopping: bring", false},
+		{"unknown list", "quirks:\n  Groceries: bring", true},
+		{"unknown preset", "quirks:\n  Shopping: trello", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+`+tt.yaml+"\n")
+			_, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_RemindersSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{"source", "reminders_sources:\n  Shopping: Exchange", false},
+		{"unknown list", "reminders_sources:\n  Groceries: iCloud", true},
+		{"empty", "reminders_sources:\n  Shopping: \"\"", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+`+tt.yaml+"\n")
+			_, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_Mirrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{"mirrors", "mirrors:\n  Shopping: [todo.tablet, todo.dashboard]", false},
+		{"unknown list", "mirrors:\n  Groceries: [todo.tablet]", true},
+		{"mapped target", "mirrors:\n  Shopping: [todo.shopping]", true},
+		{"twice", "mirrors:\n  Shopping: [todo.tablet, todo.tablet]", true},
+		{"unknown server", "mirrors:\n  Shopping: [\"nc:Shopping\"]", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+`+tt.yaml+"\n")
+			_, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_ListPrefixes(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{"default list", "  Work: todo.shopping\nlist_prefixes:\n  Work: \"[Work]\"", false},
+		{"all tagged", "  Work: todo.shopping\nlist_prefixes:\n  Work: \"[Work]\"\n  Shopping: \"[Shop]\"", false},
+		{"shared untagged", "  Work: todo.shopping", true},
+		{"same tag", "  Work: todo.shopping\nlist_prefixes:\n  Work: \"[W]\"\n  Shopping: \"[W]\"", true},
+		{"unshared", "  Work: todo.work\nlist_prefixes:\n  Work: \"[Work]\"", true},
+		{"unknown list", "  Work: todo.shopping\nlist_prefixes:\n  Work: \"[Work]\"\n  Home: \"[Home]\"", true},
+		{"blank tag", "  Work: todo.shopping\nlist_prefixes:\n  Work: \" \"", true},
+		{"alerts differ", "  Work: todo.shopping\nlist_prefixes:\n  Work: \"[Work]\"\nalerts:\n  Work: due", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+`+tt.yaml+"\n")
+			_, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_IgnoreMarkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{"markers", "ignore_markers: [\"!\", \"#nosync\"]", false},
+		{"empty", "ignore_markers: [\"\"]", true},
+		{"bare hash", "ignore_markers: [\"#\"]", true},
+		{"whitespace", "ignore_markers: [\"no sync\"]", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+`+tt.yaml+"\n")
+			_, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_ParseShorthand(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{"enabled", "parse_shorthand:\n  Shopping: true", false},
+		{"unknown list", "parse_shorthand:\n  Groceries: true", true},
+		{"not a flag", "parse_shorthand:\n  Shopping: often", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+`+tt.yaml+"\n")
+			_, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_Alerts(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{"policy", "alerts:\n  Shopping: due", false},
+		{"unknown list", "alerts:\n  Groceries: due", true},
+		{"unknown policy", "alerts:\n  Shopping: notes", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+`+tt.yaml+"\n")
+			_, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_DeletionGuardPercentOutOfRange(t *testing.T) {
+	path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+deletion_guard:
+  max_percent: 150
+`)
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected error for max_percent above 100, got nil")
+	}
+}
+
+func TestLoad_BootstrapFuzzyMatch(t *testing.T) {
+	path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+bootstrap:
+  fuzzy_match: true
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Bootstrap.FuzzyThreshold != 0.8 {
+		t.Errorf("FuzzyThreshold = %v, want default 0.8", cfg.Bootstrap.FuzzyThreshold)
+	}
+
+	path = writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+bootstrap:
+  fuzzy_match: true
+  fuzzy_threshold: 1.5
+`)
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected error for fuzzy_threshold above 1, got nil")
+	}
+}
+
+func TestLoad_BootstrapOnMismatch(t *testing.T) {
+	for _, tt := range []struct {
+		value   string
+		wantErr bool
+	}{
+		{"ask", false},
+		{"reminders", false},
+		{"ha", false},
+		{"newest", false},
+		{"oldest", true},
+	} {
+		t.Run(tt.value, func(t *testing.T) {
+			path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+bootstrap:
+  on_mismatch: `+tt.value+`
+`)
+			cfg, err := Load(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.Bootstrap.OnMismatch != tt.value {
+				t.Errorf("OnMismatch = %q, want %q", cfg.Bootstrap.OnMismatch, tt.value)
+			}
+		})
+	}
+}
+
+func TestLoad_BootstrapOnCompletionMismatch(t *testing.T) {
+	path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+bootstrap:
+  on_completion_mismatch: prefer-open
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Bootstrap.OnCompletionMismatch != "prefer-open" {
+		t.Errorf("OnCompletionMismatch = %q, want prefer-open", cfg.Bootstrap.OnCompletionMismatch)
+	}
+
+	path = writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+bootstrap:
+  on_completion_mismatch: newest
+`)
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected error for an unknown on_completion_mismatch, got nil")
+	}
+}
+
+func TestLoad_NotifyDefaults(t *testing.T) {
+	path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+notify:
+  ha_persistent: true
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Notify == nil || !cfg.Notify.HAPersistent {
+		t.Fatal("expected Notify.HAPersistent to be true")
+	}
+	if cfg.Notify.FailureThreshold != 3 {
+		t.Errorf("FailureThreshold = %d, want default 3", cfg.Notify.FailureThreshold)
+	}
+}
+
+func TestLoad_CacheDefaults(t *testing.T) {
+	path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+cache:
+  disabled: false
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Cache == nil || cfg.Cache.MaxAge != 5*time.Minute || cfg.Cache.HA {
+		t.Errorf("Cache = %+v, want default max_age 5m without HA caching", cfg.Cache)
+	}
+}
+
+func TestLoad_JobsValidation(t *testing.T) {
+	path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+jobs:
+  db-maintenance:
+    every: 30s
+`)
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected error for job interval below 1m")
+	}
+}
+
+func TestLoad_DashboardListen(t *testing.T) {
+	for _, tt := range []struct {
+		listen   string
+		allowLAN bool
+		wantErr  bool
+	}{
+		{"127.0.0.1:8787", false, false},
+		{"localhost:8787", false, false},
+		{"[::1]:8787", false, false},
+		{":8787", false, true},
+		{"0.0.0.0:8787", false, true},
+		{"192.168.1.5:8787", false, true},
+		{"mac.local:8787", false, true},
+		{"0.0.0.0:8787", true, false},
+		{"192.168.1.5:8787", true, false},
+		{"127.0.0.1", false, true},
+		{"127.0.0.1:http", false, true},
+	} {
+		path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+dashboard_listen: "`+tt.listen+`"
+dashboard_allow_lan: `+strconv.FormatBool(tt.allowLAN)+`
+`)
+		if _, err := Load(path); (err != nil) != tt.wantErr {
+			t.Errorf("Load(dashboard_listen: %q, dashboard_allow_lan: %v) error = %v, wantErr %v", tt.listen, tt.allowLAN, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoad_StateDB(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	for _, tt := range []struct {
+		stateDB string
+		want    func(dir string) string
+	}{
+		{"", func(string) string { return "" }},
+		{"/Volumes/Vault/state.db", func(string) string { return "/Volumes/Vault/state.db" }},
+		{"state/relay.db", func(dir string) string { return filepath.Join(dir, "state", "relay.db") }},
+		{"~/Vault/state.db", func(string) string { return filepath.Join(home, "Vault", "state.db") }},
+	} {
+		path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Shopping: todo.shopping
+state_db: "`+tt.stateDB+`"
+`)
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load(state_db: %q): %v", tt.stateDB, err)
+		}
+		if want := tt.want(filepath.Dir(path)); cfg.StateDB != want {
+			t.Errorf("Load(state_db: %q).StateDB = %q, want %q", tt.stateDB, cfg.StateDB, want)
+		}
+	}
+}
+
+func TestLoad_CalDAVMappings(t *testing.T) {
+	const servers = `
+caldav:
+  nextcloud:
+    url: "https://cloud.example.com/remote.php/dav/calendars/alice/"
+    username: alice
+    password: app-password
+`
+	for _, tt := range []struct {
+		target  string
+		wantErr bool
+	}{
+		{"todo.work", false},
+		{"nextcloud:tasks", false},
+		{"radicale:tasks", true},
+		{"nextcloud:", true},
+	} {
+		path := writeConfig(t, `
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+list_mappings:
+  Work: "`+tt.target+`"
+`+servers)
+		if _, err := Load(path); (err != nil) != tt.wantErr {
+			t.Errorf("Load(Work: %q) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GetValue returns the YAML rendering of the value at key in the config file
+// at path, after merging its includes. key is a dot-separated path such as
+// "poll_interval", "telemetry.otlp_endpoint", or "list_mappings.Shopping".
+func GetValue(path, key string) (string, error) {
+	doc, err := readMergedDocument(path)
+	if err != nil {
+		return "", err
+	}
+
+	node := lookup(doc, splitKey(key))
+	if node == nil {
+		return "", fmt.Errorf("key %q is not set", key)
+	}
+	if node.Kind == yaml.ScalarNode {
+		return node.Value, nil
+	}
+
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return "", fmt.Errorf("rendering %q: %w", key, err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// SetValue sets key to value in the config file at path, preserving comments
+// and key order. Included files are never edited; a value set here overrides
+// the same key in any include. value is parsed as YAML, so "60s", "true", or
+// "{Shopping: todo.shopping}" are all accepted. Missing intermediate maps are
+// created. The edited document is fully validated before it is written; an
+// invalid edit leaves the file untouched.
+func SetValue(path, key, value string) error {
+	var parsed yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		return fmt.Errorf("parsing value %q: %w", value, err)
+	}
+	newVal := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: ""}
+	if len(parsed.Content) > 0 {
+		newVal = parsed.Content[0]
+	}
+
+	return editDocument(path, func(doc *yaml.Node) error {
+		if err := assign(doc, splitKey(key), newVal); err != nil {
+			return fmt.Errorf("setting %q: %w", key, err)
+		}
+		return nil
+	})
+}
+
+// AddListMapping adds listName → entityID to list_mappings in the config
+// file at path, preserving comments. It fails if listName is already mapped.
+func AddListMapping(path, listName, entityID string) error {
+	return editDocument(path, func(doc *yaml.Node) error {
+		if node := lookup(doc, []string{"list_mappings", listName}); node != nil {
+			return fmt.Errorf("list %q is already mapped to %s", listName, node.Value)
+		}
+		val := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: entityID}
+		return assign(doc, []string{"list_mappings", listName}, val)
+	})
+}
+
+// SetListMapping points the mapping of listName in the config file at path
+// at entityID, preserving comments. It fails if listName is not mapped in
+// that file.
+func SetListMapping(path, listName, entityID string) error {
+	return editDocument(path, func(doc *yaml.Node) error {
+		node := lookup(doc, []string{"list_mappings", listName})
+		if node == nil {
+			return fmt.Errorf("list %q is not in list_mappings", listName)
+		}
+		node.Value = entityID
+		return nil
+	})
+}
+
+// SetRemindersSource sets the Reminders account listName is synced from in
+// reminders_sources of the config file at path, preserving comments.
+func SetRemindersSource(path, listName, source string) error {
+	return editDocument(path, func(doc *yaml.Node) error {
+		val := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: source}
+		return assign(doc, []string{"reminders_sources", listName}, val)
+	})
+}
+
+// RemoveListMapping removes listName from list_mappings in the config file at
+// path, preserving comments. The list is also dropped from shadow.lists,
+// quirks and reminders_sources so the result stays valid. It fails if listName is not mapped.
+func RemoveListMapping(path, listName string) error {
+	return editDocument(path, func(doc *yaml.Node) error {
+		if !removeKey(lookup(doc, []string{"list_mappings"}), listName) {
+			if merged, err := readMergedDocument(path); err == nil && lookup(merged, []string{"list_mappings", listName}) != nil {
+				return fmt.Errorf("list %q is mapped in an included file; remove it there", listName)
+			}
+			return fmt.Errorf("list %q is not in list_mappings", listName)
+		}
+		if lists := lookup(doc, []string{"shadow", "lists"}); lists != nil && lists.Kind == yaml.SequenceNode {
+			kept := lists.Content[:0]
+			for _, n := range lists.Content {
+				if n.Value != listName {
+					kept = append(kept, n)
+				}
+			}
+			lists.Content = kept
+		}
+		removeKey(lookup(doc, []string{"quirks"}), listName)
+		removeKey(lookup(doc, []string{"reminders_sources"}), listName)
+		return nil
+	})
+}
+
+// RenameListMapping renames the key oldName in list_mappings of the config
+// file at path to newName, keeping its target and comments. The list is also
+// renamed in shadow.lists, quirks and reminders_sources. It fails if oldName is not mapped or
+// newName is.
+func RenameListMapping(path, oldName, newName string) error {
+	return editDocument(path, func(doc *yaml.Node) error {
+		mappings := lookup(doc, []string{"list_mappings"})
+		if mappings == nil || mappings.Kind != yaml.MappingNode || valueIndex(mappings, oldName) < 0 {
+			return fmt.Errorf("list %q is not in list_mappings", oldName)
+		}
+		if node := lookup(doc, []string{"list_mappings", newName}); node != nil {
+			return fmt.Errorf("list %q is already mapped to %s", newName, node.Value)
+		}
+		mappings.Content[valueIndex(mappings, oldName)-1].Value = newName
+		if lists := lookup(doc, []string{"shadow", "lists"}); lists != nil && lists.Kind == yaml.SequenceNode {
+			for _, n := range lists.Content {
+				if n.Value == oldName {
+					n.Value = newName
+				}
+			}
+		}
+		for _, key := range []string{"quirks", "reminders_sources"} {
+			if m := lookup(doc, []string{key}); m != nil && m.Kind == yaml.MappingNode {
+				if i := valueIndex(m, oldName); i >= 0 {
+					m.Content[i-1].Value = newName
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// editDocument applies edit to the parsed config file at path, validates the
+// result, and writes it back. The file is left untouched if edit or
+// validation fails.
+func editDocument(path string, edit func(doc *yaml.Node) error) error {
+	doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+	if err := edit(doc); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("encoding config: %w", err)
+	}
+	_ = enc.Close()
+
+	if _, err := parseAt(path, buf.Bytes()); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("writing config file %q: %w", path, err)
+	}
+	return nil
+}
+
+// readDocument parses the config file at path into a YAML node tree.
+func readDocument(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file %q: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing config file %q: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		// Empty file: start from an empty mapping document.
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	return &doc, nil
+}
+
+// readMergedDocument returns the config file at path with its includes
+// merged in, as a document node.
+func readMergedDocument(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file %q: %w", path, err)
+	}
+	root, err := mergeDocument(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("config file %q: %w", path, err)
+	}
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}, nil
+}
+
+// splitKey splits a dotted key path into its segments.
+func splitKey(key string) []string {
+	return strings.Split(key, ".")
+}
+
+// lookup walks a mapping path starting at a document node. It returns nil if
+// any segment is missing.
+func lookup(doc *yaml.Node, path []string) *yaml.Node {
+	node := doc.Content[0]
+	for _, seg := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		idx := valueIndex(node, seg)
+		if idx < 0 {
+			return nil
+		}
+		node = node.Content[idx]
+	}
+	return node
+}
+
+// assign sets the value at path, creating intermediate mappings as needed.
+// The line comment of a replaced value is kept; comments on keys are
+// untouched because key nodes are never replaced.
+func assign(doc *yaml.Node, path []string, val *yaml.Node) error {
+	node := doc.Content[0]
+	for i, seg := range path {
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("%q is not a map", strings.Join(path[:i], "."))
+		}
+		idx := valueIndex(node, seg)
+		last := i == len(path)-1
+
+		switch {
+		case idx < 0 && last:
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: seg}, val)
+			return nil
+		case idx < 0:
+			child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: seg}, child)
+			node = child
+		case last:
+			val.LineComment = node.Content[idx].LineComment
+			node.Content[idx] = val
+			return nil
+		default:
+			node = node.Content[idx]
+		}
+	}
+	return nil
+}
+
+// removeKey deletes name and its value from mapping m, reporting whether the
+// key was present.
+func removeKey(m *yaml.Node, name string) bool {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return false
+	}
+	idx := valueIndex(m, name)
+	if idx < 0 {
+		return false
+	}
+	m.Content = append(m.Content[:idx-1], m.Content[idx+1:]...)
+	return true
+}
+
+// valueIndex returns the index in m.Content of the value stored under name,
+// or -1 if the mapping has no such key.
+func valueIndex(m *yaml.Node, name string) int {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == name {
+			return i + 1
+		}
+	}
+	return -1
+}
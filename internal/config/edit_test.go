@@ -0,0 +1,223 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const editBase = `# ReminderRelay configuration
+ha_url: "http://ha.local:8123"
+ha_token: "token"
+
+# How often to poll.
+poll_interval: 30s # keep this low
+list_mappings:
+  Shopping: todo.shopping
+`
+
+func TestGetValue(t *testing.T) {
+	path := writeConfig(t, editBase)
+
+	got, err := GetValue(path, "poll_interval")
+	if err != nil {
+		t.Fatalf("GetValue: %v", err)
+	}
+	if got != "30s" {
+		t.Errorf("poll_interval = %q, want 30s", got)
+	}
+
+	got, err = GetValue(path, "list_mappings")
+	if err != nil {
+		t.Fatalf("GetValue(list_mappings): %v", err)
+	}
+	if !strings.Contains(got, "Shopping: todo.shopping") {
+		t.Errorf("list_mappings = %q, want YAML map containing Shopping", got)
+	}
+
+	if _, err := GetValue(path, "telemetry.otlp_endpoint"); err == nil {
+		t.Error("expected error for unset key, got nil")
+	}
+}
+
+func TestSetValue_PreservesComments(t *testing.T) {
+	path := writeConfig(t, editBase)
+
+	if err := SetValue(path, "poll_interval", "60s"); err != nil {
+		t.Fatalf("SetValue: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading config: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{"# ReminderRelay configuration", "# How often to poll.", "# keep this low"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("comment %q lost after SetValue:\n%s", want, out)
+		}
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load after SetValue: %v", err)
+	}
+	if cfg.PollInterval != 60*time.Second {
+		t.Errorf("PollInterval = %v, want 60s", cfg.PollInterval)
+	}
+}
+
+func TestSetValue_CreatesNestedKeys(t *testing.T) {
+	path := writeConfig(t, editBase)
+
+	if err := SetValue(path, "list_mappings.Work", "todo.work"); err != nil {
+		t.Fatalf("SetValue(list_mappings.Work): %v", err)
+	}
+	if err := SetValue(path, "telemetry.otlp_endpoint", "localhost:4317"); err != nil {
+		t.Fatalf("SetValue(telemetry.otlp_endpoint): %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.ListMappings["Work"] != "todo.work" {
+		t.Errorf("ListMappings[Work] = %q, want todo.work", cfg.ListMappings["Work"])
+	}
+	if cfg.Telemetry == nil || cfg.Telemetry.OTLPEndpoint != "localhost:4317" {
+		t.Errorf("Telemetry = %+v, want endpoint localhost:4317", cfg.Telemetry)
+	}
+}
+
+func TestSetValue_RejectsInvalid(t *testing.T) {
+	path := writeConfig(t, editBase)
+	before, _ := os.ReadFile(path)
+
+	if err := SetValue(path, "poll_interval", "1s"); err == nil {
+		t.Error("expected validation error for poll_interval 1s, got nil")
+	}
+	if err := SetValue(path, "pol_interval", "60s"); err == nil {
+		t.Error("expected error for unknown key, got nil")
+	}
+
+	after, _ := os.ReadFile(path)
+	if string(before) != string(after) {
+		t.Error("config file modified despite invalid edit")
+	}
+}
+
+func TestAddAndRemoveListMapping(t *testing.T) {
+	path := writeConfig(t, editBase+`shadow:
+  lists: [Shopping]
+quirks:
+  Shopping: bring
+reminders_sources:
+  Shopping: iCloud
+`)
+
+	if err := AddListMapping(path, "Work.Tasks", "todo.work"); err != nil {
+		t.Fatalf("AddListMapping: %v", err)
+	}
+	if err := SetRemindersSource(path, "Work.Tasks", "Exchange"); err != nil {
+		t.Fatalf("SetRemindersSource: %v", err)
+	}
+	if err := AddListMapping(path, "Work.Tasks", "todo.other"); err == nil {
+		t.Error("expected error when adding an existing mapping, got nil")
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load after add: %v", err)
+	}
+	if cfg.ListMappings["Work.Tasks"] != "todo.work" {
+		t.Errorf("ListMappings[Work.Tasks] = %q, want todo.work (dotted names must not be split)", cfg.ListMappings["Work.Tasks"])
+	}
+	if cfg.RemindersSources["Work.Tasks"] != "Exchange" {
+		t.Errorf("RemindersSources[Work.Tasks] = %q, want Exchange", cfg.RemindersSources["Work.Tasks"])
+	}
+
+	if err := RemoveListMapping(path, "Shopping"); err != nil {
+		t.Fatalf("RemoveListMapping: %v", err)
+	}
+	if err := RemoveListMapping(path, "Shopping"); err == nil {
+		t.Error("expected error when removing an unknown mapping, got nil")
+	}
+
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load after remove: %v", err)
+	}
+	if _, ok := cfg.ListMappings["Shopping"]; ok {
+		t.Error("Shopping mapping still present after removal")
+	}
+	if len(cfg.Shadow.Lists) != 0 || len(cfg.Quirks) != 0 || len(cfg.RemindersSources) != 1 {
+		t.Errorf("Shadow.Lists = %v, Quirks = %v, RemindersSources = %v, want removed list dropped",
+			cfg.Shadow.Lists, cfg.Quirks, cfg.RemindersSources)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "# How often to poll.") {
+		t.Error("comments were not preserved")
+	}
+}
+
+func TestSetListMapping(t *testing.T) {
+	path := writeConfig(t, editBase)
+
+	if err := SetListMapping(path, "Shopping", "todo.shopping_2"); err != nil {
+		t.Fatalf("SetListMapping: %v", err)
+	}
+	if err := SetListMapping(path, "Groceries", "todo.groceries"); err == nil {
+		t.Error("expected error when setting an unknown mapping, got nil")
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load after set: %v", err)
+	}
+	if cfg.ListMappings["Shopping"] != "todo.shopping_2" {
+		t.Errorf("ListMappings[Shopping] = %q, want todo.shopping_2", cfg.ListMappings["Shopping"])
+	}
+}
+
+func TestRenameListMapping(t *testing.T) {
+	path := writeConfig(t, editBase+`  Work: todo.work
+shadow:
+  lists: [Shopping]
+quirks:
+  Shopping: bring
+reminders_sources:
+  Shopping: iCloud
+`)
+
+	if err := RenameListMapping(path, "Shopping", "Groceries"); err != nil {
+		t.Fatalf("RenameListMapping: %v", err)
+	}
+	if err := RenameListMapping(path, "Shopping", "Food"); err == nil {
+		t.Error("expected error when renaming an unknown mapping, got nil")
+	}
+	if err := RenameListMapping(path, "Groceries", "Work"); err == nil {
+		t.Error("expected error when renaming onto an existing mapping, got nil")
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load after rename: %v", err)
+	}
+	if cfg.ListMappings["Groceries"] != "todo.shopping" {
+		t.Errorf("ListMappings[Groceries] = %q, want todo.shopping", cfg.ListMappings["Groceries"])
+	}
+	if _, ok := cfg.ListMappings["Shopping"]; ok {
+		t.Error("Shopping mapping still present after rename")
+	}
+	if len(cfg.Shadow.Lists) != 1 || cfg.Shadow.Lists[0] != "Groceries" {
+		t.Errorf("Shadow.Lists = %v, want [Groceries]", cfg.Shadow.Lists)
+	}
+	if cfg.Quirks["Groceries"] != "bring" || len(cfg.Quirks) != 1 {
+		t.Errorf("Quirks = %v, want the preset under Groceries", cfg.Quirks)
+	}
+	if cfg.RemindersSources["Groceries"] != "iCloud" || len(cfg.RemindersSources) != 1 {
+		t.Errorf("RemindersSources = %v, want the account under Groceries", cfg.RemindersSources)
+	}
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxIncludeDepth bounds how deeply included files may include others.
+const maxIncludeDepth = 8
+
+// includeList is the value of an include key: a single path or a list.
+type includeList []string
+
+// UnmarshalYAML accepts both `include: a.yaml` and `include: [a.yaml, b.yaml]`.
+func (l *includeList) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*l = includeList{n.Value}
+		return nil
+	}
+	var paths []string
+	if err := n.Decode(&paths); err != nil {
+		return err
+	}
+	*l = paths
+	return nil
+}
+
+// fileConfig is the shape of a single config file: any subset of [Config]
+// plus an optional include key.
+type fileConfig struct {
+	Config  `yaml:",inline"`
+	Include includeList `yaml:"include"`
+}
+
+// parseAt decodes and validates the configuration in data, read from path.
+// Files named by include keys are read relative to the including file and
+// merged before validation:
+//
+//   - included files are applied in the order listed, each overriding the
+//     ones before it, and the including file overrides all of its includes;
+//   - maps such as list_mappings are merged key by key, any other value is
+//     replaced whole.
+//
+// Included files may include others. Every file is checked for unknown keys
+// on its own, so a typo is reported against the file that contains it.
+func parseAt(path string, data []byte) (*Config, error) {
+	merged, err := mergeDocument(path, data)
+	if err != nil {
+		return nil, err
+	}
+	out, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("encoding merged config: %w", err)
+	}
+	return parse(bytes.NewReader(out))
+}
+
+// mergeDocument returns the root mapping of the config file at path, holding
+// data, with every include merged in.
+func mergeDocument(path string, data []byte) (*yaml.Node, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %q: %w", path, err)
+	}
+	return mergeIncludes(abs, data, nil)
+}
+
+// mergeIncludes returns the root mapping of the file at path with its
+// includes resolved and the include key removed. stack holds the chain of
+// including files, to detect cycles.
+func mergeIncludes(path string, data []byte, stack []string) (*yaml.Node, error) {
+	var fc fileConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&fc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing: %w", err)
+	}
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+	if len(fc.Include) == 0 {
+		return root, nil
+	}
+	removeKey(root, "include")
+
+	if len(stack) >= maxIncludeDepth {
+		return nil, fmt.Errorf("includes nested more than %d levels deep", maxIncludeDepth)
+	}
+	stack = append(stack, path)
+
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, inc := range fc.Include {
+		if inc == "" {
+			return nil, fmt.Errorf("include contains an empty path")
+		}
+		incPath, err := resolvePath(filepath.Dir(path), inc)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range stack {
+			if p == incPath {
+				return nil, fmt.Errorf("include cycle: %s → %s", strings.Join(stack, " → "), incPath)
+			}
+		}
+		incData, err := os.ReadFile(incPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading include %q: %w", inc, err)
+		}
+		node, err := mergeIncludes(incPath, incData, stack)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", inc, err)
+		}
+		mergeMapping(merged, node)
+	}
+	mergeMapping(merged, root)
+	return merged, nil
+}
+
+// resolvePath returns the absolute path of a file named in a config file,
+// such as an include entry. Relative paths are resolved against dir, the
+// config file's directory; a leading ~/ refers to the home directory.
+func resolvePath(dir, inc string) (string, error) {
+	if rest, ok := strings.CutPrefix(inc, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("resolving home directory: %w", err)
+		}
+		inc = filepath.Join(home, rest)
+	}
+	if !filepath.IsAbs(inc) {
+		inc = filepath.Join(dir, inc)
+	}
+	return filepath.Abs(inc)
+}
+
+// mergeMapping applies the keys of src onto dst. Where both hold a mapping
+// the two are merged recursively; otherwise the value from src wins.
+func mergeMapping(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, val := src.Content[i], src.Content[i+1]
+		idx := valueIndex(dst, key.Value)
+		switch {
+		case idx < 0:
+			dst.Content = append(dst.Content, key, val)
+		case dst.Content[idx].Kind == yaml.MappingNode && val.Kind == yaml.MappingNode:
+			mergeMapping(dst.Content[idx], val)
+		default:
+			dst.Content[idx] = val
+		}
+	}
+}
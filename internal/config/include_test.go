@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes name → content into a fresh directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoad_IncludePrecedence(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": `
+include: [secrets.yaml, mappings.yaml]
+ha_url: "http://ha.local:8123"
+poll_interval: 60s
+list_mappings:
+  Work: todo.work_main
+`,
+		"secrets.yaml": `ha_token: "secret"`,
+		"mappings.yaml": `
+poll_interval: 20s
+list_mappings:
+  Shopping: todo.shopping
+  Work: todo.work
+`,
+	})
+
+	cfg, err := Load(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.HAToken != "secret" {
+		t.Errorf("HAToken = %q, want value from secrets.yaml", cfg.HAToken)
+	}
+	if cfg.PollInterval.String() != "1m0s" {
+		t.Errorf("PollInterval = %v, want main file to override includes", cfg.PollInterval)
+	}
+	want := map[string]string{"Shopping": "todo.shopping", "Work": "todo.work_main"}
+	if len(cfg.ListMappings) != 2 || cfg.ListMappings["Shopping"] != want["Shopping"] || cfg.ListMappings["Work"] != want["Work"] {
+		t.Errorf("ListMappings = %v, want %v merged key by key", cfg.ListMappings, want)
+	}
+}
+
+func TestLoad_IncludeUnknownKeyNamesFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": "include: extra.yaml\nha_url: \"http://ha.local:8123\"\nha_token: t\nlist_mappings: {A: todo.a}\n",
+		"extra.yaml":  "pol_interval: 30s\n",
+	})
+
+	_, err := Load(filepath.Join(dir, "config.yaml"))
+	if err == nil || !strings.Contains(err.Error(), `include "extra.yaml"`) {
+		t.Errorf("err = %v, want unknown key reported against extra.yaml", err)
+	}
+}
+
+func TestLoad_IncludeCycle(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": "include: a.yaml\n",
+		"a.yaml":      "include: config.yaml\n",
+	})
+
+	_, err := Load(filepath.Join(dir, "config.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("err = %v, want include cycle error", err)
+	}
+}
+
+func TestEdit_WithIncludes(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml":   "include: mappings.yaml\nha_url: \"http://ha.local:8123\"\nha_token: t\n",
+		"mappings.yaml": "list_mappings:\n  Shopping: todo.shopping\n",
+	})
+	path := filepath.Join(dir, "config.yaml")
+
+	if got, err := GetValue(path, "list_mappings.Shopping"); err != nil || got != "todo.shopping" {
+		t.Errorf("GetValue = %q, %v; want value from include", got, err)
+	}
+
+	if err := AddListMapping(path, "Work", "todo.work"); err != nil {
+		t.Fatalf("AddListMapping: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.ListMappings) != 2 {
+		t.Errorf("ListMappings = %v, want included and added mapping", cfg.ListMappings)
+	}
+
+	err = RemoveListMapping(path, "Shopping")
+	if err == nil || !strings.Contains(err.Error(), "included file") {
+		t.Errorf("RemoveListMapping = %v, want error pointing at the included file", err)
+	}
+}
@@ -0,0 +1,115 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// Client talks to a daemon's control socket. Create one with [NewClient].
+type Client struct {
+	http *http.Client
+}
+
+// NewClient returns a Client for the socket at path. No connection is made
+// until the first request.
+func NewClient(path string) *Client {
+	dialer := &net.Dialer{}
+	return &Client{http: &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", path)
+			},
+		},
+	}}
+}
+
+// Status returns the daemon's live status.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var st Status
+	if err := c.do(ctx, http.MethodGet, "/status", &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// Conflicts returns the conflicts the daemon resolved recently, newest first.
+func (c *Client) Conflicts(ctx context.Context) ([]Conflict, error) {
+	var out []Conflict
+	if err := c.do(ctx, http.MethodGet, "/conflicts", &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Pause stops the daemon's scheduled and WebSocket-triggered syncs.
+func (c *Client) Pause(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/pause", nil)
+}
+
+// Resume undoes [Client.Pause].
+func (c *Client) Resume(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/resume", nil)
+}
+
+// Sync runs a full pass in the daemon and waits for it to finish.
+func (c *Client) Sync(ctx context.Context) (*SyncResult, error) {
+	var res SyncResult
+	if err := c.do(ctx, http.MethodPost, "/sync", &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SyncSelected runs a pass in the daemon over the mapping of the Reminders
+// list list, or the mappings with the target target, and waits for it to
+// finish. An empty list or target matches any.
+func (c *Client) SyncSelected(ctx context.Context, list, target string) (*SyncResult, error) {
+	q := url.Values{}
+	if list != "" {
+		q.Set("list", list)
+	}
+	if target != "" {
+		q.Set("target", target)
+	}
+	var res SyncResult
+	if err := c.do(ctx, http.MethodPost, "/sync?"+q.Encode(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// do sends a request and decodes a JSON reply into out, if non-nil.
+func (c *Client) do(ctx context.Context, method, path string, out interface{}) error {
+	// The host is ignored; the transport always dials the socket.
+	req, err := http.NewRequestWithContext(ctx, method, "http://reminderrelay"+path, nil)
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return ErrNotRunning
+		}
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: unexpected status %s", method, path, resp.Status)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s reply: %w", path, err)
+	}
+	return nil
+}
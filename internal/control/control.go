@@ -0,0 +1,180 @@
+// Package control lets the CLI talk to a running daemon over a Unix domain
+// socket: query live status, pause and resume syncing, trigger a pass, and
+// list recently resolved conflicts.
+//
+// The protocol is plain HTTP with JSON bodies, so the socket can also be
+// inspected with curl --unix-socket. Access is restricted to the owning user
+// through file permissions on the socket and its directory.
+package control
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/profile"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// ErrNotRunning is returned by [Client] methods when no daemon is listening
+// on the socket.
+var ErrNotRunning = errors.New("daemon is not running")
+
+// ErrAlreadyRunning is returned by [Listen] when another daemon already
+// serves the socket.
+var ErrAlreadyRunning = errors.New("another daemon is already running")
+
+// DefaultSocketPath returns the default path of the control socket in the
+// state directory of the selected profile:
+// ~/.local/share/reminderrelay[/<profile>]/control.sock
+func DefaultSocketPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving home directory: %w", err)
+	}
+	return filepath.Join(profile.Dir(filepath.Join(home, ".local", "share", "reminderrelay")), "control.sock"), nil
+}
+
+// --- Wire types --------------------------------------------------------------
+
+// Status is the reply to a status request.
+type Status struct {
+	PID        int       `json:"pid"`
+	Version    string    `json:"version"`
+	StartedAt  time.Time `json:"started_at"`
+	Paused     bool      `json:"paused"`
+	Passes     int       `json:"passes"`
+	LastPassAt time.Time `json:"last_pass_at,omitzero"`
+	LastStats  Stats     `json:"last_stats"`
+	LastError  string    `json:"last_error,omitempty"`
+	// Latency maps a direction ("to_ha", "to_reminders") to the recent
+	// propagation latencies in that direction.
+	Latency map[string]Latency `json:"latency,omitempty"`
+	// UnmappedLists names the Reminders lists that are not synced.
+	UnmappedLists []string `json:"unmapped_lists,omitempty"`
+	// Duplicates lists the titles several items of a list share.
+	Duplicates []Duplicate `json:"duplicates,omitempty"`
+	// RemindersAccess is the daemon's access to Reminders, such as
+	// "granted" or "denied"; see [WithRemindersAccess].
+	RemindersAccess string `json:"reminders_access,omitempty"`
+	// LastFetchAt is when the daemon last read reminders from EventKit.
+	LastFetchAt time.Time `json:"last_fetch_at,omitzero"`
+}
+
+// Duplicate mirrors [syncp.Duplicate].
+type Duplicate struct {
+	List      string `json:"list"`
+	Title     string `json:"title"`
+	Reminders int    `json:"reminders"`
+	HA        int    `json:"ha"`
+}
+
+// Latency mirrors [syncp.LatencySummary]. Durations are in nanoseconds.
+type Latency struct {
+	Samples         int           `json:"samples"`
+	P50             time.Duration `json:"p50"`
+	P90             time.Duration `json:"p90"`
+	P99             time.Duration `json:"p99"`
+	Max             time.Duration `json:"max"`
+	WithinObjective float64       `json:"within_objective,omitempty"`
+}
+
+// Stats mirrors [syncp.Stats] with list errors flattened to strings.
+type Stats struct {
+	Created    int               `json:"created"`
+	Updated    int               `json:"updated"`
+	Deleted    int               `json:"deleted"`
+	Conflicts  int               `json:"conflicts"`
+	Errors     int               `json:"errors"`
+	ListErrors map[string]string `json:"list_errors,omitempty"`
+}
+
+// SyncResult is the reply to a sync request.
+type SyncResult struct {
+	Stats Stats  `json:"stats"`
+	Error string `json:"error,omitempty"`
+}
+
+// Conflict is a resolved conflict as listed by the conflicts request.
+type Conflict struct {
+	At     time.Time `json:"at"`
+	List   string    `json:"list"`
+	Title  string    `json:"title"`
+	Fields []string  `json:"fields,omitempty"` // nil when resolved by last-write-wins
+	Winner string    `json:"winner"`           // "reminders" or "ha"
+}
+
+// StatsFrom converts engine stats to their wire form, as served over the
+// control socket and printed by sync-once --output json.
+func StatsFrom(s syncp.Stats) Stats {
+	out := Stats{
+		Created:   s.Created,
+		Updated:   s.Updated,
+		Deleted:   s.Deleted,
+		Conflicts: s.Conflicts,
+		Errors:    s.Errors,
+	}
+	if len(s.ListErrors) > 0 {
+		out.ListErrors = make(map[string]string, len(s.ListErrors))
+		for list, err := range s.ListErrors {
+			out.ListErrors[list] = err.Error()
+		}
+	}
+	return out
+}
+
+// latencyFrom converts the engine's latency summaries to their wire form.
+func latencyFrom(summaries map[syncp.Direction]syncp.LatencySummary) map[string]Latency {
+	if len(summaries) == 0 {
+		return nil
+	}
+	out := make(map[string]Latency, len(summaries))
+	for dir, s := range summaries {
+		out[string(dir)] = Latency{
+			Samples:         s.Samples,
+			P50:             s.P50,
+			P90:             s.P90,
+			P99:             s.P99,
+			Max:             s.Max,
+			WithinObjective: s.WithinObjective,
+		}
+	}
+	return out
+}
+
+// duplicatesFrom converts the engine's duplicate titles to their wire form.
+func duplicatesFrom(dups []syncp.Duplicate) []Duplicate {
+	if len(dups) == 0 {
+		return nil
+	}
+	out := make([]Duplicate, 0, len(dups))
+	for _, d := range dups {
+		out = append(out, Duplicate{List: d.ListName, Title: d.Title, Reminders: d.Reminders, HA: d.HA})
+	}
+	return out
+}
+
+// conflictsFrom converts the engine's recent conflicts to their wire form,
+// keeping their newest-first order.
+func conflictsFrom(recent []syncp.RecentConflict) []Conflict {
+	out := make([]Conflict, 0, len(recent))
+	for _, c := range recent {
+		winner := "ha"
+		if c.RemindersWon {
+			winner = "reminders"
+		}
+		fields := append([]string(nil), c.Fields...)
+		sort.Strings(fields)
+		out = append(out, Conflict{
+			At:     c.At,
+			List:   c.ListName,
+			Title:  c.Result.Title,
+			Fields: fields,
+			Winner: winner,
+		})
+	}
+	return out
+}
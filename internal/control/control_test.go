@@ -0,0 +1,166 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+type fakeController struct {
+	paused   bool
+	syncs    int
+	selected []string // list and target of the last SyncSelected
+	status   syncp.Status
+}
+
+func (f *fakeController) Status() syncp.Status {
+	st := f.status
+	st.Paused = f.paused
+	return st
+}
+
+func (f *fakeController) Pause()  { f.paused = true }
+func (f *fakeController) Resume() { f.paused = false }
+
+func (f *fakeController) SyncNow(context.Context) (syncp.Stats, error) {
+	f.syncs++
+	return syncp.Stats{Created: 1, Errors: 1, ListErrors: map[string]error{"Work": errors.New("boom")}},
+		errors.New("1 list failed")
+}
+
+func (f *fakeController) SyncSelected(_ context.Context, list, target string) (syncp.Stats, error) {
+	f.selected = []string{list, target}
+	return syncp.Stats{Updated: 2}, nil
+}
+
+// startServer serves ctrl on a socket in a temp dir and returns a client.
+func startServer(t *testing.T, ctrl Controller, opts ...ServerOption) (*Client, string) {
+	t.Helper()
+	// Unix socket paths are length-limited; t.TempDir can exceed that on macOS.
+	dir, err := os.MkdirTemp("", "rr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	path := filepath.Join(dir, "control.sock")
+
+	ln, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = NewServer(ctrl, "test", testLogger, opts...).Serve(ctx, ln)
+		close(done)
+	}()
+	t.Cleanup(func() { cancel(); <-done })
+	return NewClient(path), path
+}
+
+func TestClientServer_Commands(t *testing.T) {
+	at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	ctrl := &fakeController{status: syncp.Status{
+		Passes:     3,
+		LastPassAt: at,
+		Conflicts: []syncp.RecentConflict{{
+			Conflict: syncp.Conflict{ListName: "Shopping", Fields: []string{"title"}, Result: model.Item{Title: "Milk"}},
+			At:       at,
+		}},
+	}}
+	client, _ := startServer(t, ctrl, WithRemindersAccess(
+		func() string { return "granted" },
+		func() time.Time { return at },
+	))
+	ctx := context.Background()
+
+	if err := client.Pause(ctx); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	st, err := client.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if !st.Paused || st.Passes != 3 || !st.LastPassAt.Equal(at) || st.PID != os.Getpid() {
+		t.Errorf("Status = %+v, want paused after 3 passes", st)
+	}
+	if st.RemindersAccess != "granted" || !st.LastFetchAt.Equal(at) {
+		t.Errorf("Status = %+v, want Reminders access granted, last fetch at %v", st, at)
+	}
+	if err := client.Resume(ctx); err != nil || ctrl.paused {
+		t.Errorf("Resume: err = %v, paused = %v", err, ctrl.paused)
+	}
+
+	res, err := client.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if ctrl.syncs != 1 || res.Stats.Created != 1 || res.Stats.ListErrors["Work"] != "boom" || res.Error == "" {
+		t.Errorf("Sync = %+v, want stats and error of the pass", res)
+	}
+
+	res, err = client.SyncSelected(ctx, "Shopping List", "")
+	if err != nil {
+		t.Fatalf("SyncSelected: %v", err)
+	}
+	if ctrl.syncs != 1 || !slices.Equal(ctrl.selected, []string{"Shopping List", ""}) || res.Stats.Updated != 2 {
+		t.Errorf("SyncSelected = %+v, selected %q; want a pass over Shopping List only", res, ctrl.selected)
+	}
+
+	conflicts, err := client.Conflicts(ctx)
+	if err != nil {
+		t.Fatalf("Conflicts: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Title != "Milk" || conflicts[0].Winner != "ha" {
+		t.Errorf("Conflicts = %+v, want Milk won by ha", conflicts)
+	}
+}
+
+func TestListen_SecondDaemonRefused(t *testing.T) {
+	_, path := startServer(t, &fakeController{})
+
+	if _, err := Listen(path); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("Listen = %v, want ErrAlreadyRunning", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("socket mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestClient_NotRunning(t *testing.T) {
+	dir, err := os.MkdirTemp("", "rr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// A stale socket file with nobody listening.
+	path := filepath.Join(dir, "control.sock")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClient(path).Status(context.Background()); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Status = %v, want ErrNotRunning", err)
+	}
+
+	ln, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen over stale socket: %v", err)
+	}
+	_ = ln.Close()
+}
@@ -0,0 +1,178 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+// Controller is the part of the sync engine the control server drives.
+// Implemented by [*syncp.Engine].
+type Controller interface {
+	Status() syncp.Status
+	Pause()
+	Resume()
+	SyncNow(ctx context.Context) (syncp.Stats, error)
+	SyncSelected(ctx context.Context, list, target string) (syncp.Stats, error)
+}
+
+// Server answers control requests for a running daemon. Create one with
+// [NewServer].
+type Server struct {
+	ctrl      Controller
+	version   string
+	startedAt time.Time
+	log       *slog.Logger
+
+	access    func() string    // nil unless WithRemindersAccess
+	lastFetch func() time.Time // nil unless WithRemindersAccess
+}
+
+// ServerOption configures optional Server behaviour.
+type ServerOption func(*Server)
+
+// WithRemindersAccess makes status replies report the daemon's access to
+// Reminders, as access returns it, and when it last read reminders from
+// EventKit, as lastFetch returns it. macOS grants access per process, so
+// the daemon's access may differ from that of a command run in a terminal.
+func WithRemindersAccess(access func() string, lastFetch func() time.Time) ServerOption {
+	return func(s *Server) { s.access, s.lastFetch = access, lastFetch }
+}
+
+// NewServer creates a Server for ctrl. version is reported in status replies.
+func NewServer(ctrl Controller, version string, logger *slog.Logger, opts ...ServerOption) *Server {
+	s := &Server{ctrl: ctrl, version: version, startedAt: time.Now(), log: logger}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Listen creates the control socket at path, readable by the current user
+// only. A socket left behind by a daemon that exited uncleanly is replaced;
+// if another daemon still answers on it, Listen returns [ErrAlreadyRunning].
+func Listen(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("creating socket directory: %w", err)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return nil, ErrAlreadyRunning
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("removing stale socket: %w", err)
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %q: %w", path, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("restricting socket permissions: %w", err)
+	}
+	return ln, nil
+}
+
+// Serve answers requests on ln until ctx is cancelled, then closes ln, which
+// also removes the socket file.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	s.log.Info("control socket listening", "path", ln.Addr().String())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving control socket: %w", err)
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler serving the control API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("GET /conflicts", s.handleConflicts)
+	mux.HandleFunc("POST /pause", s.handlePause)
+	mux.HandleFunc("POST /resume", s.handleResume)
+	mux.HandleFunc("POST /sync", s.handleSync)
+	return mux
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	st := s.ctrl.Status()
+	reply := Status{
+		PID:           os.Getpid(),
+		Version:       s.version,
+		StartedAt:     s.startedAt,
+		Paused:        st.Paused,
+		Passes:        st.Passes,
+		LastPassAt:    st.LastPassAt,
+		LastStats:     StatsFrom(st.LastStats),
+		LastError:     st.LastError,
+		Latency:       latencyFrom(st.Latency),
+		UnmappedLists: st.UnmappedLists,
+		Duplicates:    duplicatesFrom(st.Duplicates),
+	}
+	if s.access != nil {
+		reply.RemindersAccess = s.access()
+		reply.LastFetchAt = s.lastFetch()
+	}
+	writeJSON(w, http.StatusOK, reply)
+}
+
+func (s *Server) handleConflicts(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, conflictsFrom(s.ctrl.Status().Conflicts))
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, _ *http.Request) {
+	s.ctrl.Pause()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleResume(w http.ResponseWriter, _ *http.Request) {
+	s.ctrl.Resume()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
+	var (
+		stats syncp.Stats
+		err   error
+	)
+	list, target := r.URL.Query().Get("list"), r.URL.Query().Get("target")
+	if list != "" || target != "" {
+		stats, err = s.ctrl.SyncSelected(r.Context(), list, target)
+	} else {
+		stats, err = s.ctrl.SyncNow(r.Context())
+	}
+	res := SyncResult{Stats: StatsFrom(stats)}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// writeJSON writes v as the JSON response body.
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,103 @@
+package control
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URLScheme is the scheme of the URLs that drive the daemon from outside a
+// terminal, such as reminderrelay://sync opened by a Shortcut or a Focus
+// automation.
+const URLScheme = "reminderrelay"
+
+// Actions a URL can request.
+const (
+	ActionSync   = "sync"
+	ActionPause  = "pause"
+	ActionResume = "resume"
+	ActionStatus = "status"
+)
+
+// URLRequest is a daemon action requested by URL. Both the plain form and
+// the x-callback-url form are understood:
+//
+//	reminderrelay://sync?list=Groceries
+//	reminderrelay://x-callback-url/status?x-success=shortcuts://callback
+type URLRequest struct {
+	Action string
+	// List and Entity limit a sync to the mappings they select; both are
+	// empty for all mappings.
+	List, Entity string
+	// Success and Error are the x-success and x-error callbacks, empty when
+	// none was given.
+	Success, Error string
+}
+
+// ParseURL parses raw as a [URLScheme] URL.
+func ParseURL(raw string) (*URLRequest, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parsing URL: %w", err)
+	}
+	if !strings.EqualFold(u.Scheme, URLScheme) {
+		return nil, fmt.Errorf("URL scheme %q is not %s", u.Scheme, URLScheme)
+	}
+	action := u.Host
+	if strings.EqualFold(action, "x-callback-url") {
+		action = strings.Trim(u.Path, "/")
+	} else if p := strings.Trim(u.Path, "/"); p != "" {
+		return nil, fmt.Errorf("unexpected path %q in URL", u.Path)
+	}
+	action = strings.ToLower(action)
+
+	q := u.Query()
+	req := &URLRequest{
+		Action:  action,
+		Success: q.Get("x-success"),
+		Error:   q.Get("x-error"),
+	}
+	switch action {
+	case ActionSync:
+		req.List = q.Get("list")
+		req.Entity = q.Get("entity")
+	case ActionPause, ActionResume, ActionStatus:
+		if q.Get("list") != "" || q.Get("entity") != "" {
+			return nil, fmt.Errorf("%s does not take a list or entity", action)
+		}
+	case "":
+		return nil, fmt.Errorf("URL names no action (want %s, %s, %s or %s)",
+			ActionSync, ActionPause, ActionResume, ActionStatus)
+	default:
+		return nil, fmt.Errorf("unknown action %q (want %s, %s, %s or %s)",
+			action, ActionSync, ActionPause, ActionResume, ActionStatus)
+	}
+	return req, nil
+}
+
+// Callback returns the URL to open once the action is done: x-success with
+// params added to its query, or, if err is not nil, x-error with
+// errorMessage set. It returns "" when the request has no such callback or
+// the callback is not a valid URL.
+func (r *URLRequest) Callback(params url.Values, err error) string {
+	raw := r.Success
+	if err != nil {
+		raw = r.Error
+		params = url.Values{"errorCode": {"1"}, "errorMessage": {err.Error()}}
+	}
+	if raw == "" {
+		return ""
+	}
+	u, perr := url.Parse(raw)
+	if perr != nil {
+		return ""
+	}
+	q := u.Query()
+	for k, vs := range params {
+		for _, v := range vs {
+			q.Add(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,76 @@
+package control
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    URLRequest
+		wantErr bool
+	}{
+		{name: "sync", raw: "reminderrelay://sync", want: URLRequest{Action: ActionSync}},
+		{
+			name: "sync list",
+			raw:  "reminderrelay://sync?list=Groceries%20%26%20more&entity=todo.shopping",
+			want: URLRequest{Action: ActionSync, List: "Groceries & more", Entity: "todo.shopping"},
+		},
+		{
+			name: "x-callback-url",
+			raw:  "reminderrelay://x-callback-url/status?x-success=shortcuts%3A%2F%2Fok&x-error=shortcuts%3A%2F%2Ffail",
+			want: URLRequest{Action: ActionStatus, Success: "shortcuts://ok", Error: "shortcuts://fail"},
+		},
+		{name: "case", raw: "ReminderRelay://Pause", want: URLRequest{Action: ActionPause}},
+		{name: "trailing slash", raw: "reminderrelay://resume/", want: URLRequest{Action: ActionResume}},
+		{name: "other scheme", raw: "https://sync", wantErr: true},
+		{name: "unknown action", raw: "reminderrelay://purge", wantErr: true},
+		{name: "no action", raw: "reminderrelay://x-callback-url/", wantErr: true},
+		{name: "extra path", raw: "reminderrelay://sync/Groceries", wantErr: true},
+		{name: "pause with list", raw: "reminderrelay://pause?list=Groceries", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseURL(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseURL(%q) = %+v, want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseURL(%q): %v", tt.raw, err)
+			}
+			if *got != tt.want {
+				t.Errorf("ParseURL(%q) = %+v, want %+v", tt.raw, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLRequest_Callback(t *testing.T) {
+	req := &URLRequest{Action: ActionSync, Success: "shortcuts://ok?a=1", Error: "shortcuts://fail"}
+
+	got, err := url.Parse(req.Callback(url.Values{"created": {"2"}}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Host != "ok" || got.Query().Get("a") != "1" || got.Query().Get("created") != "2" {
+		t.Errorf("success callback = %s", got)
+	}
+
+	got, err = url.Parse(req.Callback(url.Values{"created": {"2"}}, errors.New("daemon is not running")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Host != "fail" || got.Query().Get("errorMessage") != "daemon is not running" || got.Query().Has("created") {
+		t.Errorf("error callback = %s", got)
+	}
+
+	if cb := (&URLRequest{Action: ActionSync}).Callback(nil, nil); cb != "" {
+		t.Errorf("callback without x-success = %q, want empty", cb)
+	}
+}
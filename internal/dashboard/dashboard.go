@@ -0,0 +1,265 @@
+// Package dashboard serves a small web UI for the running daemon: per-list
+// item counts, recent sync passes, conflicts awaiting review, and a button to
+// sync immediately. It is meant for household members who never open a
+// terminal.
+//
+// The dashboard has no authentication. It listens on a loopback address
+// unless the config opts into a LAN one. Requests addressed to a host name
+// other than localhost or the listen address are refused, so a website
+// cannot reach it through DNS rebinding, and cross-site form posts are
+// rejected so other websites cannot drive it from a browser.
+package dashboard
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+//go:embed dashboard.html
+var pageHTML string
+
+// Engine is the part of the sync engine the dashboard drives. Implemented by
+// [*syncp.Engine].
+type Engine interface {
+	Status() syncp.Status
+	SyncNow(ctx context.Context) (syncp.Stats, error)
+	ResolveConflict(ctx context.Context, id int, res syncp.Resolution) error
+}
+
+// ItemCounter reports the number of tracked items per list. Implemented by
+// [*state.Store].
+type ItemCounter interface {
+	ItemCounts(ctx context.Context) (map[string]int, error)
+}
+
+// Server serves the dashboard. Create one with [NewServer].
+type Server struct {
+	engine   Engine
+	counter  ItemCounter
+	mappings map[string]string
+	log      *slog.Logger
+	page     *template.Template
+	listen   string // address served on; set by Serve
+}
+
+// NewServer creates a dashboard for engine syncing listMappings.
+func NewServer(engine Engine, counter ItemCounter, listMappings map[string]string, logger *slog.Logger) *Server {
+	page := template.Must(template.New("dashboard").Funcs(template.FuncMap{
+		"when": formatWhen,
+		"join": strings.Join,
+	}).Parse(pageHTML))
+	return &Server{engine: engine, counter: counter, mappings: listMappings, log: logger, page: page}
+}
+
+// Serve listens on addr and serves the dashboard until ctx is cancelled.
+func (s *Server) Serve(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listening on %q: %w", addr, err)
+	}
+	s.listen = addr
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	s.log.Info("dashboard listening", "url", "http://"+ln.Addr().String())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving dashboard: %w", err)
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler serving the dashboard.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handlePage)
+	mux.HandleFunc("POST /sync", s.sameOrigin(s.handleSync))
+	mux.HandleFunc("POST /conflicts/{id}", s.sameOrigin(s.handleResolve))
+	return s.checkHost(mux)
+}
+
+// --- Page --------------------------------------------------------------------
+
+// listRow is one row of the lists table.
+type listRow struct {
+	Name     string
+	EntityID string
+	Items    int
+}
+
+// pageData is the template input.
+type pageData struct {
+	Status    syncp.Status
+	Lists     []listRow
+	Pending   []syncp.RecentConflict
+	Message   string
+	CountsErr string
+}
+
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	data := pageData{Status: s.engine.Status(), Message: r.URL.Query().Get("msg")}
+
+	counts, err := s.counter.ItemCounts(r.Context())
+	if err != nil {
+		data.CountsErr = err.Error()
+	}
+	for name, entity := range s.mappings {
+		data.Lists = append(data.Lists, listRow{Name: name, EntityID: entity, Items: counts[name]})
+	}
+	sort.Slice(data.Lists, func(i, j int) bool { return data.Lists[i].Name < data.Lists[j].Name })
+
+	for _, c := range data.Status.Conflicts {
+		if !c.Reviewed {
+			data.Pending = append(data.Pending, c)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.page.Execute(w, data); err != nil {
+		s.log.Error("rendering dashboard", "error", err)
+	}
+}
+
+// --- Actions -----------------------------------------------------------------
+
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
+	s.log.Info("sync requested via dashboard")
+	stats, err := s.engine.SyncNow(r.Context())
+	if err != nil {
+		redirect(w, r, "Sync failed: "+err.Error())
+		return
+	}
+	redirect(w, r, fmt.Sprintf("Sync complete: %d created, %d updated, %d deleted.",
+		stats.Created, stats.Updated, stats.Deleted))
+}
+
+func (s *Server) handleResolve(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid conflict ID", http.StatusBadRequest)
+		return
+	}
+	var res syncp.Resolution
+	switch r.FormValue("use") {
+	case "keep":
+		res = syncp.KeepResult
+	case "reminders":
+		res = syncp.UseReminders
+	case "ha":
+		res = syncp.UseHA
+	default:
+		http.Error(w, "invalid resolution", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.engine.ResolveConflict(r.Context(), id, res); err != nil {
+		redirect(w, r, "Could not resolve conflict: "+err.Error())
+		return
+	}
+	redirect(w, r, "Conflict resolved.")
+}
+
+// sameOrigin rejects form posts made from other sites.
+func (s *Server) sameOrigin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if site := r.Header.Get("Sec-Fetch-Site"); site != "" && site != "same-origin" && site != "none" {
+			http.Error(w, "cross-site request refused", http.StatusForbidden)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || u.Host != r.Host {
+				http.Error(w, "cross-site request refused", http.StatusForbidden)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// checkHost refuses requests addressed to a host the dashboard does not
+// serve. A web page whose own host name was pointed at this machine (DNS
+// rebinding) sends that name, which matches neither localhost nor the
+// listen address.
+func (s *Server) checkHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowedHost(r.Host) {
+			http.Error(w, "unknown host", http.StatusMisdirectedRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether host, the Host header of a request, names
+// this dashboard: localhost, a loopback address, or the host of the listen
+// address. When listening on all interfaces, any IP address is accepted; a
+// rebinding attack needs a host name.
+func (s *Server) allowedHost(host string) bool {
+	name, _, err := net.SplitHostPort(host)
+	if err != nil {
+		name = strings.Trim(host, "[]")
+	}
+	if strings.EqualFold(name, "localhost") {
+		return true
+	}
+	listenHost, _, _ := net.SplitHostPort(s.listen)
+	if listenHost != "" && strings.EqualFold(name, listenHost) {
+		return true
+	}
+	ip := net.ParseIP(name)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+	if s.listen == "" {
+		return false
+	}
+	listenIP := net.ParseIP(listenHost)
+	return listenHost == "" || (listenIP != nil && listenIP.IsUnspecified())
+}
+
+// redirect sends the browser back to the page, showing msg.
+func redirect(w http.ResponseWriter, r *http.Request, msg string) {
+	http.Redirect(w, r, "/?msg="+url.QueryEscape(msg), http.StatusSeeOther)
+}
+
+// formatWhen renders t relative to now, e.g. "3m ago".
+func formatWhen(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
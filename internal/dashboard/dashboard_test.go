@@ -0,0 +1,156 @@
+package dashboard
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	syncp "github.com/njoerd114/reminderrelay/internal/sync"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+type fakeEngine struct {
+	status   syncp.Status
+	syncs    int
+	resolved map[int]syncp.Resolution
+}
+
+func (f *fakeEngine) Status() syncp.Status { return f.status }
+
+func (f *fakeEngine) SyncNow(context.Context) (syncp.Stats, error) {
+	f.syncs++
+	return syncp.Stats{Created: 2}, nil
+}
+
+func (f *fakeEngine) ResolveConflict(_ context.Context, id int, res syncp.Resolution) error {
+	f.resolved[id] = res
+	return nil
+}
+
+type fakeCounter map[string]int
+
+func (f fakeCounter) ItemCounts(context.Context) (map[string]int, error) { return f, nil }
+
+func newTestServer() (*Server, *fakeEngine) {
+	eng := &fakeEngine{
+		resolved: map[int]syncp.Resolution{},
+		status: syncp.Status{
+			LastPassAt: time.Now(),
+			History:    []syncp.PassRecord{{At: time.Now(), Error: "list Work: timeout"}},
+			Conflicts: []syncp.RecentConflict{
+				{ID: 2, Conflict: syncp.Conflict{ListName: "Shopping", Result: model.Item{Title: "Buy oat milk"}}},
+				{ID: 1, Reviewed: true, Conflict: syncp.Conflict{ListName: "Shopping", Result: model.Item{Title: "Old one"}}},
+			},
+		},
+	}
+	mappings := map[string]string{"Shopping": "todo.shopping", "Work": "todo.work"}
+	return NewServer(eng, fakeCounter{"Shopping": 7}, mappings, testLogger), eng
+}
+
+func TestPage_ShowsListsHistoryAndPendingConflicts(t *testing.T) {
+	srv, _ := newTestServer()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "127.0.0.1:8787"
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"todo.shopping", "<td>7</td>", "todo.work", "list Work: timeout", "Buy oat milk", `action="/conflicts/2"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page does not contain %q", want)
+		}
+	}
+	if strings.Contains(body, "Old one") {
+		t.Error("page lists an already reviewed conflict")
+	}
+}
+
+func TestHandler_RefusesOtherHosts(t *testing.T) {
+	tests := []struct {
+		listen string
+		host   string
+		want   bool
+	}{
+		{"127.0.0.1:8787", "127.0.0.1:8787", true},
+		{"127.0.0.1:8787", "localhost:8787", true},
+		{"127.0.0.1:8787", "[::1]:8787", true},
+		{"127.0.0.1:8787", "evil.test:8787", false},
+		{"127.0.0.1:8787", "evil.test", false},
+		{"127.0.0.1:8787", "192.168.1.5:8787", false},
+		{"192.168.1.5:8787", "192.168.1.5:8787", true},
+		{"mac.local:8787", "mac.local:8787", true},
+		{"mac.local:8787", "evil.test:8787", false},
+		{"0.0.0.0:8787", "192.168.1.5:8787", true},
+		{"0.0.0.0:8787", "evil.test:8787", false},
+		{":8787", "192.168.1.5:8787", true},
+	}
+	for _, tt := range tests {
+		srv, eng := newTestServer()
+		srv.listen = tt.listen
+		h := srv.Handler()
+
+		get := httptest.NewRequest(http.MethodGet, "/", nil)
+		get.Host = tt.host
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, get)
+		if got := rec.Code == http.StatusOK; got != tt.want {
+			t.Errorf("listen %s, GET with Host %s: status = %d, want allowed %v", tt.listen, tt.host, rec.Code, tt.want)
+		}
+		if !tt.want && strings.Contains(rec.Body.String(), "todo.shopping") {
+			t.Errorf("listen %s, GET with Host %s: page served", tt.listen, tt.host)
+		}
+
+		post := httptest.NewRequest(http.MethodPost, "/sync", nil)
+		post.Host = tt.host
+		post.Header.Set("Origin", "http://"+tt.host)
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, post)
+		if got := eng.syncs == 1; got != tt.want {
+			t.Errorf("listen %s, POST with Host %s: status = %d, syncs = %d; want allowed %v", tt.listen, tt.host, rec.Code, eng.syncs, tt.want)
+		}
+	}
+}
+
+func TestActions(t *testing.T) {
+	srv, eng := newTestServer()
+	srv.listen = "example.com:80"
+	h := srv.Handler()
+
+	post := func(path string, form url.Values, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post("/sync", nil, "http://evil.test"); rec.Code != http.StatusForbidden || eng.syncs != 0 {
+		t.Errorf("cross-site sync: status = %d, syncs = %d; want 403 and no sync", rec.Code, eng.syncs)
+	}
+	// httptest requests are addressed to example.com.
+	if rec := post("/sync", nil, "http://example.com"); rec.Code != http.StatusSeeOther || eng.syncs != 1 {
+		t.Errorf("sync: status = %d, syncs = %d; want redirect after one sync", rec.Code, eng.syncs)
+	}
+
+	rec := post("/conflicts/2", url.Values{"use": {"ha"}}, "")
+	if rec.Code != http.StatusSeeOther || eng.resolved[2] != syncp.UseHA {
+		t.Errorf("resolve: status = %d, resolved = %v; want conflict 2 resolved with ha", rec.Code, eng.resolved)
+	}
+	if rec := post("/conflicts/2", url.Values{"use": {"both"}}, ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid resolution: status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,113 @@
+// Package desktop posts macOS user notifications for the daemon, so sync
+// problems and resolved conflicts reach the person at the Mac without
+// opening the logs or Home Assistant.
+//
+// Notifications are throttled per notification ID: while a problem persists,
+// an updated summary is shown at most once per interval instead of on every
+// sync pass.
+package desktop
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+)
+
+// DefaultMinInterval is how long a notification ID stays quiet after it was
+// shown, unless configured otherwise.
+const DefaultMinInterval = 30 * time.Minute
+
+// poster shows one notification. Replaced in tests.
+type poster func(ctx context.Context, title, message string) error
+
+// Notifier posts macOS user notifications. It satisfies the problem and
+// conflict notifier interfaces of the sync package. Create one with
+// [NewNotifier].
+type Notifier struct {
+	post        poster
+	minInterval time.Duration
+	clock       clock.Clock
+	log         *slog.Logger
+
+	mu    sync.Mutex
+	shown map[string]time.Time // notification ID → last shown
+}
+
+// Option configures optional Notifier behaviour.
+type Option func(*Notifier)
+
+// WithClock replaces the wall clock used for throttling. Intended for tests.
+func WithClock(c clock.Clock) Option {
+	return func(n *Notifier) { n.clock = c }
+}
+
+// NewNotifier creates a Notifier that shows each notification ID at most
+// once per minInterval. A zero minInterval selects [DefaultMinInterval].
+func NewNotifier(minInterval time.Duration, logger *slog.Logger, opts ...Option) *Notifier {
+	if minInterval == 0 {
+		minInterval = DefaultMinInterval
+	}
+	n := &Notifier{
+		post:        postNotification,
+		minInterval: minInterval,
+		clock:       clock.Real(),
+		log:         logger,
+		shown:       make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// CreateNotification shows message under title unless notificationID was
+// shown within the throttle interval. Markdown in message is reduced to
+// plain text.
+func (n *Notifier) CreateNotification(ctx context.Context, notificationID, title, message string) error {
+	now := n.clock.Now()
+
+	n.mu.Lock()
+	last, seen := n.shown[notificationID]
+	if seen && now.Sub(last) < n.minInterval {
+		n.mu.Unlock()
+		n.log.Debug("desktop notification throttled", "id", notificationID)
+		return nil
+	}
+	n.shown[notificationID] = now
+	n.mu.Unlock()
+
+	if err := n.post(ctx, title, plainText(message)); err != nil {
+		n.mu.Lock()
+		delete(n.shown, notificationID) // retry on the next call
+		n.mu.Unlock()
+		return fmt.Errorf("posting desktop notification: %w", err)
+	}
+	return nil
+}
+
+// DismissNotification is a no-op: delivered macOS notifications cannot be
+// withdrawn by the sender and expire from Notification Center on their own.
+// The throttle is kept, so a problem that flaps does not notify every time
+// it returns.
+func (n *Notifier) DismissNotification(_ context.Context, _ string) error {
+	return nil
+}
+
+// plainText strips the Markdown used in HA notifications.
+func plainText(md string) string {
+	md = strings.NewReplacer("**", "", "`", "").Replace(md)
+	lines := strings.Split(md, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "- "))
+		if line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,75 @@
+package desktop
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+type recorder struct {
+	messages []string
+	err      error
+}
+
+func (r *recorder) post(_ context.Context, _, message string) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func newTestNotifier(interval time.Duration) (*Notifier, *recorder, *clock.Fake) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	rec := &recorder{}
+	n := NewNotifier(interval, testLogger, WithClock(clk))
+	n.post = rec.post
+	return n, rec, clk
+}
+
+func TestNotifier_ThrottlesPerID(t *testing.T) {
+	n, rec, clk := newTestNotifier(10 * time.Minute)
+	ctx := context.Background()
+
+	_ = n.CreateNotification(ctx, "problems", "t", "failed 3 passes")
+	_ = n.CreateNotification(ctx, "problems", "t", "failed 4 passes")
+	_ = n.CreateNotification(ctx, "conflict_1", "t", "conflict")
+	if len(rec.messages) != 2 {
+		t.Fatalf("shown = %v, want first problem and the conflict only", rec.messages)
+	}
+
+	clk.Advance(10 * time.Minute)
+	_ = n.CreateNotification(ctx, "problems", "t", "failed 9 passes")
+	if len(rec.messages) != 3 || rec.messages[2] != "failed 9 passes" {
+		t.Errorf("shown = %v, want the update after the interval", rec.messages)
+	}
+}
+
+func TestNotifier_FailedPostIsRetried(t *testing.T) {
+	n, rec, _ := newTestNotifier(10 * time.Minute)
+	ctx := context.Background()
+
+	rec.err = errors.New("osascript missing")
+	if err := n.CreateNotification(ctx, "problems", "t", "m"); err == nil {
+		t.Fatal("expected error from failed post")
+	}
+	rec.err = nil
+	if err := n.CreateNotification(ctx, "problems", "t", "m"); err != nil || len(rec.messages) != 1 {
+		t.Errorf("retry: shown = %v, err = %v; want shown once", rec.messages, err)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	in := "ReminderRelay is having trouble syncing:\n\n- List **Shopping** failed. Update `ha_token`."
+	want := "ReminderRelay is having trouble syncing:\nList Shopping failed. Update ha_token."
+	if got := plainText(in); got != want {
+		t.Errorf("plainText = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build darwin
+
+package desktop
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// postNotification shows a notification through osascript. Title and message
+// are passed as arguments rather than spliced into the script, so they need
+// no AppleScript escaping.
+func postNotification(ctx context.Context, title, message string) error {
+	cmd := exec.CommandContext(ctx, "osascript",
+		"-e", "on run argv",
+		"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+		"-e", "end run",
+		title, message)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("osascript: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
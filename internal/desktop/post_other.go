@@ -0,0 +1,13 @@
+//go:build !darwin
+
+package desktop
+
+import (
+	"context"
+	"errors"
+)
+
+// postNotification fails: user notifications are only available on macOS.
+func postNotification(_ context.Context, _, _ string) error {
+	return errors.New("desktop notifications are only supported on macOS")
+}
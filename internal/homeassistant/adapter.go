@@ -3,6 +3,7 @@ package homeassistant
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,11 +11,16 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	haclient "github.com/mkelcik/go-ha-client/v2"
 
+	"github.com/njoerd114/reminderrelay/internal/clock"
 	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/redact"
 )
 
 // RESTClient is the subset of [haclient.Client] methods used by the adapter.
@@ -27,40 +33,59 @@ type RESTClient interface {
 	// CallServiceWithResponse POSTs with ?return_response=true. Used for
 	// todo.get_items which returns data.
 	CallServiceWithResponse(ctx context.Context, domain, service string, body io.Reader) (haclient.ServiceCallResponse, error)
+	// FireEvent POSTs body as the event data to /api/events/<eventType>.
+	FireEvent(ctx context.Context, eventType string, body io.Reader) error
+	// GetState GETs /api/states/<entityID>. Used to read an entity's
+	// supported_features.
+	GetState(ctx context.Context, entityID string) (haclient.StateEntity, error)
 }
 
 // haClientWrapper wraps [haclient.Client] and adds a plain CallService method
 // that POSTs without ?return_response — required for HA services that don't
 // support responses (e.g. todo.add_item, todo.update_item, todo.remove_item).
+// Errors it returns have the token scrubbed, as the HTTP layer may echo
+// request details into them.
 type haClientWrapper struct {
 	client  *haclient.Client
 	baseURL string
 	token   string
 	hc      *http.Client
+	redact  *redact.Redactor
 }
 
 func (w *haClientWrapper) Ping(ctx context.Context) error {
-	return w.client.Ping(ctx)
+	err := w.client.Ping(ctx)
+	if errors.Is(err, haclient.ErrUnauthorized) {
+		return fmt.Errorf("HA returned 401 Unauthorized — check ha_token: %w", model.ErrUnauthorized)
+	}
+	return w.redact.Error(err)
 }
 
 // CallService POSTs the body to /api/services/<domain>/<service> without
 // appending ?return_response, so HA does not try to return data.
 func (w *haClientWrapper) CallService(ctx context.Context, domain, service string, body io.Reader) error {
-	endpoint := fmt.Sprintf("%s/api/services/%s/%s",
-		strings.TrimRight(w.baseURL, "/"),
-		url.PathEscape(domain),
-		url.PathEscape(service),
-	)
+	return w.post(ctx, "/api/services/"+url.PathEscape(domain)+"/"+url.PathEscape(service), body)
+}
+
+// FireEvent POSTs body to /api/events/<eventType>, firing the event on the
+// HA event bus with body as its data.
+func (w *haClientWrapper) FireEvent(ctx context.Context, eventType string, body io.Reader) error {
+	return w.post(ctx, "/api/events/"+url.PathEscape(eventType), body)
+}
+
+// post sends a JSON POST to path on the HA API and maps error statuses.
+func (w *haClientWrapper) post(ctx context.Context, path string, body io.Reader) error {
+	endpoint := strings.TrimRight(w.baseURL, "/") + path
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
 	if err != nil {
-		return fmt.Errorf("create service request: %w", err)
+		return w.redact.Error(fmt.Errorf("create request: %w", err))
 	}
 	req.Header.Set("Authorization", "Bearer "+w.token)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := w.hc.Do(req)
 	if err != nil {
-		return fmt.Errorf("execute service request: %w", err)
+		return w.redact.Error(fmt.Errorf("execute request: %w", err))
 	}
 	defer func() { _ = resp.Body.Close() }()
 
@@ -69,10 +94,10 @@ func (w *haClientWrapper) CallService(ctx context.Context, domain, service strin
 			Message string `json:"message"`
 		}
 		_ = json.NewDecoder(resp.Body).Decode(&br)
-		return errors.New(br.Message)
+		return &rejectedError{message: br.Message}
 	}
 	if resp.StatusCode == http.StatusUnauthorized {
-		return fmt.Errorf("HA returned 401 Unauthorized — check ha_token")
+		return fmt.Errorf("HA returned 401 Unauthorized — check ha_token: %w", model.ErrUnauthorized)
 	}
 	if resp.StatusCode >= 300 {
 		return fmt.Errorf("HA returned unexpected status %d", resp.StatusCode)
@@ -81,61 +106,205 @@ func (w *haClientWrapper) CallService(ctx context.Context, domain, service strin
 }
 
 func (w *haClientWrapper) CallServiceWithResponse(ctx context.Context, domain, service string, body io.Reader) (haclient.ServiceCallResponse, error) {
-	return w.client.CallServiceWithResponse(ctx, domain, service, body)
+	resp, err := w.client.CallServiceWithResponse(ctx, domain, service, body)
+	if errors.Is(err, haclient.ErrUnauthorized) {
+		return resp, fmt.Errorf("HA returned 401 Unauthorized — check ha_token: %w", model.ErrUnauthorized)
+	}
+	return resp, w.redact.Error(err)
 }
 
+// GetState returns the state of entityID. An unknown entity is reported as a
+// rejected request, as retrying cannot help.
+func (w *haClientWrapper) GetState(ctx context.Context, entityID string) (haclient.StateEntity, error) {
+	st, err := w.client.GetStateForEntity(ctx, entityID)
+	switch {
+	case errors.Is(err, haclient.ErrUnauthorized):
+		return st, fmt.Errorf("HA returned 401 Unauthorized — check ha_token: %w", model.ErrUnauthorized)
+	case errors.Is(err, haclient.ErrNotFound):
+		return st, &rejectedError{message: fmt.Sprintf("entity %s not found", entityID)}
+	}
+	return st, w.redact.Error(err)
+}
+
+// DefaultRequestTimeout bounds how long a single HA request may take, so a
+// hung proxy costs one retry instead of stalling a pass.
+const DefaultRequestTimeout = 30 * time.Second
+
 // Adapter provides sync-engine–oriented operations on Home Assistant todo
 // lists via the REST and WebSocket APIs. Create one with [NewAdapter] or
 // [NewAdapterWithClient].
+//
+// While a [Adapter.SubscribeChanges] subscription is live, GetItems results
+// are cached per entity and reused until a state change is reported for
+// that entity, the adapter writes to it, or the cache exceeds its maximum age.
+//
+// After repeated failed calls the adapter fails fast with [ErrCircuitOpen]
+// instead of retrying, letting a trial call through every 30 seconds.
 type Adapter struct {
-	rest   RESTClient
-	ws     *haclient.WSClient
-	logger *slog.Logger
+	endpoints *endpoints
+	logger    *slog.Logger
+	clock     clock.Clock
+	loc       *time.Location       // see WithTimeZone
+	desc      *DescriptionTemplate // see WithDescriptionTemplate
+	cache     *snapshotCache
+	breaker   *breaker
+
+	capsMu sync.Mutex
+	caps   map[string]Capabilities // probed entities; see ProbeCapabilities
+
+	restartMu sync.Mutex
+	onRestart func() // see OnRestart
+
+	requestTimeout time.Duration
+	tlsConfig      *tls.Config // nil for the system defaults; see WithTLS
+	fallbackURLs   []string    // see WithFallbackURLs
+	rateLimit      float64     // requests per second; see WithRateLimit
+	limiter        *limiter    // nil without a rate limit
 }
 
-// NewAdapter creates an Adapter backed by real HA REST and WebSocket clients.
-// The WebSocket is configured with unlimited auto-reconnect.
-func NewAdapter(haURL, token string, logger *slog.Logger) (*Adapter, error) {
-	rest, err := haclient.NewClient(haURL,
-		haclient.WithToken(token),
-		haclient.WithLogger(logger),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("create HA REST client: %w", err)
+// AdapterOption configures optional Adapter behaviour.
+type AdapterOption func(*Adapter)
+
+// WithCacheMaxAge sets how long a cached GetItems result may be reused. The
+// cache is off by default: HA emits no state_changed event for edits that
+// leave the open-item count unchanged (e.g. renaming an item), so d is also
+// the longest such an edit can go unnoticed, where polling alone notices it
+// on the next pass. Zero disables snapshot caching.
+func WithCacheMaxAge(d time.Duration) AdapterOption {
+	return func(a *Adapter) { a.cache.maxAge = d }
+}
+
+// WithRequestTimeout limits how long each HA request may take before it is
+// abandoned and retried. Zero disables the limit. Defaults to
+// [DefaultRequestTimeout].
+func WithRequestTimeout(d time.Duration) AdapterOption {
+	return func(a *Adapter) { a.requestTimeout = d }
+}
+
+// WithClock replaces the clock used to age cached snapshots, to time the
+// circuit breaker, and to schedule checks of preferred URLs. Intended for
+// tests.
+func WithClock(c clock.Clock) AdapterOption {
+	return func(a *Adapter) {
+		a.clock = c
+		a.cache.clock = c
+		a.breaker.clock = c
 	}
+}
 
-	wrapper := &haClientWrapper{
-		client:  rest,
-		baseURL: haURL,
-		token:   token,
-		hc:      &http.Client{},
+// WithTimeZone sets the zone due dates are read and written in: a date-only
+// due date is midnight in loc, and a due date is sent as the date it falls
+// on in loc. Defaults to the system's local zone.
+func WithTimeZone(loc *time.Location) AdapterOption {
+	return func(a *Adapter) { a.loc = loc }
+}
+
+// WithDescriptionTemplate sets how the description, priority, and URL of
+// items share HA's description field. Defaults to
+// [DefaultDescriptionTemplate].
+func WithDescriptionTemplate(t *DescriptionTemplate) AdapterOption {
+	return func(a *Adapter) { a.desc = t }
+}
+
+// newAdapter applies opts to an Adapter with default settings and no
+// endpoints.
+func newAdapter(logger *slog.Logger, opts []AdapterOption) *Adapter {
+	a := &Adapter{
+		logger:  logger,
+		clock:   clock.Real(),
+		loc:     time.Local,
+		desc:    defaultDescription,
+		cache:   newSnapshotCache(clock.Real(), 0),
+		breaker: newBreaker(clock.Real()),
+		caps:    make(map[string]Capabilities),
+
+		requestTimeout: DefaultRequestTimeout,
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.rateLimit > 0 {
+		a.limiter = newLimiter(a.clock, a.rateLimit)
+	}
+	return a
+}
 
-	ws := rest.WS(
+// NewAdapter creates an Adapter backed by real HA REST and WebSocket clients.
+// The WebSocket is configured with unlimited auto-reconnect. With
+// [WithFallbackURLs], every URL gets clients of its own and the WebSocket
+// follows the URL in use.
+//
+// token is a long-lived access token, shared by all clients. It does not
+// expire, so there is no refresh: a revoked token surfaces as
+// [model.ErrUnauthorized] and needs a config change, not a reconnect.
+func NewAdapter(haURL, token string, logger *slog.Logger, opts ...AdapterOption) (*Adapter, error) {
+	a := newAdapter(logger, opts)
+	hc := httpClient(a.tlsConfig)
+	r := redact.New(token)
+
+	list := make([]*endpoint, 0, 1+len(a.fallbackURLs))
+	for _, u := range append([]string{haURL}, a.fallbackURLs...) {
+		rest, err := haclient.NewClient(u,
+			haclient.WithToken(token),
+			haclient.WithLogger(logger),
+			haclient.WithHTTPClient(hc),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("create HA REST client for %s: %w", u, err)
+		}
+		list = append(list, &endpoint{
+			url: u,
+			rest: &haClientWrapper{
+				client:  rest,
+				baseURL: u,
+				token:   token,
+				hc:      hc,
+				redact:  r,
+			},
+			newWS: func() *haclient.WSClient { return a.newWS(rest) },
+		})
+	}
+	a.endpoints = newEndpoints(list...)
+	a.cache.connected = func() bool {
+		ws, _ := a.endpoints.webSocket()
+		return ws != nil && ws.IsConnected()
+	}
+
+	return a, nil
+}
+
+// newWS creates the WebSocket client of rest.
+func (a *Adapter) newWS(rest *haclient.Client) *haclient.WSClient {
+	return newWSClient(rest, a.tlsConfig,
 		haclient.WithAutoReconnect(true),
 		haclient.WithMaxRetries(0), // unlimited retries
 		haclient.WithOnReconnect(func() {
-			logger.Info("HA WebSocket reconnected")
+			a.logger.Info("HA WebSocket reconnected")
+			// Events may have been missed while disconnected, or HA
+			// restarted.
+			a.cache.resetAll()
+			a.notifyRestart()
 		}),
 		haclient.WithOnReconnectError(func(err error) {
-			logger.Error("HA WebSocket reconnect failed", "error", err)
+			a.logger.Error("HA WebSocket reconnect failed", "error", err)
 		}),
 	)
-
-	return &Adapter{rest: wrapper, ws: ws, logger: logger}, nil
 }
 
 // NewAdapterWithClient creates an Adapter with a caller-supplied REST client.
 // Intended for testing with a mock [RESTClient]. WebSocket features
-// (SubscribeChanges) are unavailable on adapters created this way.
-func NewAdapterWithClient(rest RESTClient, logger *slog.Logger) *Adapter {
-	return &Adapter{rest: rest, logger: logger}
+// (SubscribeChanges) are unavailable on adapters created this way, so
+// GetItems results are never cached.
+func NewAdapterWithClient(rest RESTClient, logger *slog.Logger, opts ...AdapterOption) *Adapter {
+	a := newAdapter(logger, opts)
+	a.endpoints = newEndpoints(&endpoint{rest: rest})
+	return a
 }
 
 // Ping validates the HA connection and token with retry.
 func (a *Adapter) Ping(ctx context.Context) error {
-	err := Retry(ctx, defaultMaxAttempts, func() error {
-		return a.rest.Ping(ctx)
+	err := a.call(ctx, func(ctx context.Context) error {
+		return a.endpoints.Ping(ctx)
 	})
 	if err != nil {
 		return fmt.Errorf("ping HA: %w", err)
@@ -146,43 +315,91 @@ func (a *Adapter) Ping(ctx context.Context) error {
 // Connect establishes the WebSocket connection. Must be called before
 // [Adapter.SubscribeChanges].
 func (a *Adapter) Connect(ctx context.Context) error {
-	if a.ws == nil {
+	ws, _ := a.endpoints.webSocket()
+	if ws == nil {
 		return fmt.Errorf("WebSocket client not configured")
 	}
-	return a.ws.Connect(ctx)
+	return ws.Connect(ctx)
 }
 
 // Close shuts down the WebSocket connection gracefully.
 func (a *Adapter) Close() error {
-	if a.ws == nil {
+	ws, _ := a.endpoints.webSocket()
+	if ws == nil {
 		return nil
 	}
-	return a.ws.Close()
+	return ws.Close()
 }
 
-// GetItems fetches all todo items for the given HA entity.
+// GetItems fetches all todo items for the given HA entity, or returns the
+// cached result when the entity is known to be unchanged.
 func (a *Adapter) GetItems(ctx context.Context, entityID string) ([]model.Item, error) {
+	if items, ok := a.cache.get(entityID); ok {
+		a.logger.Debug("reusing cached HA items", "entity_id", entityID, "count", len(items))
+		return items, nil
+	}
+
+	m := a.cache.markFor(entityID)
 	data := buildGetItemsData(entityID)
 
 	var resp haclient.ServiceCallResponse
-	err := Retry(ctx, defaultMaxAttempts, func() error {
+	err := a.call(ctx, func(ctx context.Context) error {
 		var callErr error
-		resp, callErr = a.rest.CallServiceWithResponse(ctx, domainTodo, serviceGetItems, serviceBody(data))
+		resp, callErr = a.endpoints.CallServiceWithResponse(ctx, domainTodo, serviceGetItems, serviceBody(data))
 		return callErr
 	})
-	if err != nil {
-		return nil, fmt.Errorf("get items for %s: %w", entityID, err)
+	if err == nil {
+		var items []model.Item
+		if items, err = parseGetItemsResponse(resp, entityID, a.desc, a.loc); err == nil {
+			a.cache.put(entityID, items, m)
+			return items, nil
+		}
+	}
+	if a.unavailable(ctx, entityID) {
+		return nil, fmt.Errorf("%s is unavailable: %w", entityID, model.ErrListUnavailable)
 	}
+	return nil, fmt.Errorf("get items for %s: %w", entityID, err)
+}
 
-	return parseGetItemsResponse(resp, entityID)
+// unavailable reports whether entityID is in the unavailable state, as
+// while its integration loads. HA then leaves it out of get_items
+// responses, or fails the call.
+func (a *Adapter) unavailable(ctx context.Context, entityID string) bool {
+	st, err := a.endpoints.GetState(ctx, entityID)
+	return err == nil && st.State == stateUnavailable
 }
 
+// stateUnavailable is the state of an entity HA cannot reach.
+const stateUnavailable = "unavailable"
+
 // AddItem creates a new todo item in the given HA entity. The item's Priority
 // is encoded as a description prefix automatically.
+//
+// A request that timed out may still have added the item, so an attempt
+// after a failed one first fetches the entity and adds nothing if it holds
+// more items of the title than the last fetch before the write. Without
+// such a fetch any item of the title counts.
 func (a *Adapter) AddItem(ctx context.Context, entityID string, item *model.Item) error {
-	data := buildAddItemData(entityID, item)
-	err := Retry(ctx, defaultMaxAttempts, func() error {
-		return a.rest.CallService(ctx, domainTodo, serviceAddItem, serviceBody(data))
+	data := buildAddItemData(entityID, item, a.Capabilities(entityID), a.desc, a.loc)
+	known := 0
+	if items, ok := a.cache.peek(entityID); ok {
+		known = countTitled(items, item.Title)
+	}
+	defer a.cache.invalidate(entityID)
+	attempts := 0
+	err := a.call(ctx, func(ctx context.Context) error {
+		if attempts++; attempts > 1 {
+			items, err := a.fetchItems(ctx, entityID)
+			if err != nil {
+				return err
+			}
+			if countTitled(items, item.Title) > known {
+				a.logger.Info("item added by a failed attempt, not adding it again",
+					"entity_id", entityID, "title", item.Title)
+				return nil
+			}
+		}
+		return a.endpoints.CallService(ctx, domainTodo, serviceAddItem, serviceBody(data))
 	})
 	if err != nil {
 		return fmt.Errorf("add item %q to %s: %w", item.Title, entityID, err)
@@ -190,12 +407,38 @@ func (a *Adapter) AddItem(ctx context.Context, entityID string, item *model.Item
 	return nil
 }
 
-// UpdateItem updates an existing todo item in HA. currentTitle is the item's
-// title as it currently exists in HA, used to identify the target item.
-func (a *Adapter) UpdateItem(ctx context.Context, entityID, currentTitle string, item *model.Item) error {
-	data := buildUpdateItemData(entityID, currentTitle, item)
-	err := Retry(ctx, defaultMaxAttempts, func() error {
-		return a.rest.CallService(ctx, domainTodo, serviceUpdateItem, serviceBody(data))
+// fetchItems makes one get_items request for entityID, bypassing the cache
+// and the retries of [Adapter.call].
+func (a *Adapter) fetchItems(ctx context.Context, entityID string) ([]model.Item, error) {
+	resp, err := a.endpoints.CallServiceWithResponse(ctx, domainTodo, serviceGetItems, serviceBody(buildGetItemsData(entityID)))
+	if err != nil {
+		return nil, err
+	}
+	return parseGetItemsResponse(resp, entityID, a.desc, a.loc)
+}
+
+// countTitled returns how many of items are titled title.
+func countTitled(items []model.Item, title string) int {
+	n := 0
+	for _, it := range items {
+		if it.Title == title {
+			n++
+		}
+	}
+	return n
+}
+
+// UpdateItem writes the given fields of item to an existing todo item in HA.
+// currentTitle is the item's title as it currently exists in HA, used to
+// identify the target item. Fields outside fields are not sent.
+func (a *Adapter) UpdateItem(ctx context.Context, entityID, currentTitle string, item *model.Item, fields model.Fields) error {
+	data := buildUpdateItemData(entityID, currentTitle, item, fields, a.Capabilities(entityID), a.desc, a.loc)
+	if len(data) == 2 {
+		return nil // only entity_id and item: nothing to change
+	}
+	defer a.cache.invalidate(entityID)
+	err := a.call(ctx, func(ctx context.Context) error {
+		return a.endpoints.CallService(ctx, domainTodo, serviceUpdateItem, serviceBody(data))
 	})
 	if err != nil {
 		return fmt.Errorf("update item %q in %s: %w", currentTitle, entityID, err)
@@ -206,8 +449,9 @@ func (a *Adapter) UpdateItem(ctx context.Context, entityID, currentTitle string,
 // RemoveItem deletes a todo item from HA by its current title.
 func (a *Adapter) RemoveItem(ctx context.Context, entityID, title string) error {
 	data := buildRemoveItemData(entityID, title)
-	err := Retry(ctx, defaultMaxAttempts, func() error {
-		return a.rest.CallService(ctx, domainTodo, serviceRemoveItem, serviceBody(data))
+	defer a.cache.invalidate(entityID)
+	err := a.call(ctx, func(ctx context.Context) error {
+		return a.endpoints.CallService(ctx, domainTodo, serviceRemoveItem, serviceBody(data))
 	})
 	if err != nil {
 		return fmt.Errorf("remove item %q from %s: %w", title, entityID, err)
@@ -215,11 +459,59 @@ func (a *Adapter) RemoveItem(ctx context.Context, entityID, title string) error
 	return nil
 }
 
-// SubscribeChanges starts a WebSocket subscription for state_changed events
-// on the given todo entities. When any tracked entity changes, callback is
-// invoked with the entity ID. This method blocks until ctx is cancelled.
+// CreateNotification creates or replaces the HA persistent notification with
+// the given notificationID. Re-using the ID updates the existing notification
+// instead of stacking new ones.
+func (a *Adapter) CreateNotification(ctx context.Context, notificationID, title, message string) error {
+	data := buildNotificationData(notificationID, title, message)
+	err := a.call(ctx, func(ctx context.Context) error {
+		return a.endpoints.CallService(ctx, domainPersistentNotification, serviceCreate, serviceBody(data))
+	})
+	if err != nil {
+		return fmt.Errorf("create notification %q: %w", notificationID, err)
+	}
+	return nil
+}
+
+// FireEvent fires eventType on the HA event bus with data as its payload, so
+// automations can react to it.
+func (a *Adapter) FireEvent(ctx context.Context, eventType string, data map[string]interface{}) error {
+	err := a.call(ctx, func(ctx context.Context) error {
+		return a.endpoints.FireEvent(ctx, eventType, serviceBody(data))
+	})
+	if err != nil {
+		return fmt.Errorf("fire event %q: %w", eventType, err)
+	}
+	return nil
+}
+
+// DismissNotification removes the HA persistent notification with the given
+// notificationID. Dismissing a notification that does not exist is a no-op
+// in HA.
+func (a *Adapter) DismissNotification(ctx context.Context, notificationID string) error {
+	data := buildDismissNotificationData(notificationID)
+	err := a.call(ctx, func(ctx context.Context) error {
+		return a.endpoints.CallService(ctx, domainPersistentNotification, serviceDismiss, serviceBody(data))
+	})
+	if err != nil {
+		return fmt.Errorf("dismiss notification %q: %w", notificationID, err)
+	}
+	return nil
+}
+
+// SubscribeChanges starts a WebSocket subscription to a state trigger on the
+// given todo entities, so HA sends changes of those entities only. When any
+// of them changes, callback is invoked with the entity ID. This method blocks
+// until ctx is cancelled.
+//
+// A WebSocket that stays silent for heartbeatInterval is pinged, and one
+// that does not answer is replaced by a new connection, as a half-open
+// connection would otherwise stop delivering events unnoticed. When the
+// adapter switches to another URL, the subscription moves to that URL's
+// WebSocket.
 func (a *Adapter) SubscribeChanges(ctx context.Context, entityIDs []string, callback func(entityID string)) error {
-	if a.ws == nil {
+	ws, replaced := a.endpoints.webSocket()
+	if ws == nil {
 		return fmt.Errorf("WebSocket client not configured")
 	}
 
@@ -229,35 +521,130 @@ func (a *Adapter) SubscribeChanges(ctx context.Context, entityIDs []string, call
 		entitySet[id] = struct{}{}
 	}
 
-	sub, err := a.ws.SubscribeEvents(ctx, haclient.EventTypeStateChanged)
+	a.cache.setLive(true)
+	defer a.cache.setLive(false)
+
+	for {
+		err := a.subscribe(ctx, ws, replaced, entitySet, callback)
+		if !errors.Is(err, errWSReplaced) {
+			return err
+		}
+		// Until the new WebSocket connects, changes are picked up by
+		// polling.
+		for {
+			ws, replaced = a.endpoints.webSocket()
+			err := ws.Connect(ctx)
+			if err == nil {
+				break
+			}
+			a.logger.Error("HA WebSocket connect failed", "error", err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-replaced:
+			case <-a.clock.After(heartbeatInterval):
+			}
+		}
+	}
+}
+
+// OnRestart sets fn to be called when Home Assistant may have restarted:
+// when it reports having started, and when the WebSocket reconnects after
+// the connection dropped. Some integrations restore their items with new
+// UIDs on start, and changes made while the connection was down were not
+// reported, so the caller should check every list again. fn must not block.
+// HA start events are only received while a [Adapter.SubscribeChanges]
+// subscription runs.
+func (a *Adapter) OnRestart(fn func()) {
+	a.restartMu.Lock()
+	defer a.restartMu.Unlock()
+	a.onRestart = fn
+}
+
+// notifyRestart calls the function set with [Adapter.OnRestart], if any.
+func (a *Adapter) notifyRestart() {
+	a.restartMu.Lock()
+	fn := a.onRestart
+	a.restartMu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+// errWSReplaced ends a subscription on a WebSocket client the adapter no
+// longer uses.
+var errWSReplaced = errors.New("HA WebSocket client replaced")
+
+// subscribe runs a state trigger subscription on ws until ctx is cancelled,
+// it fails, or replaced is closed.
+func (a *Adapter) subscribe(ctx context.Context, ws *haclient.WSClient, replaced <-chan struct{}, entitySet map[string]struct{}, callback func(entityID string)) error {
+	sub, err := ws.SubscribeTrigger(ctx, stateTrigger(entitySet))
 	if err != nil {
-		return fmt.Errorf("subscribe state_changed: %w", err)
+		return replacedOr(replaced, fmt.Errorf("subscribe to state trigger: %w", err))
 	}
 	defer func() { _ = sub.Unsubscribe(ctx) }()
 
+	// Without start events, restarts are still noticed by the reconnect.
+	var started <-chan haclient.WSEvent
+	if startSub, err := ws.SubscribeEvents(ctx, eventHAStarted); err != nil {
+		a.logger.Warn("subscribing to HA start events failed", "error", err)
+	} else {
+		defer func() { _ = startSub.Unsubscribe(ctx) }()
+		started = startSub.Events()
+	}
+
+	heartbeat := a.clock.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	heard := a.clock.Now()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-replaced:
+			return errWSReplaced
+		case <-heartbeat.C():
+			if a.clock.Now().Sub(heard) < heartbeatInterval {
+				continue
+			}
+			if err := a.ping(ctx, ws); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				a.logger.Warn("HA WebSocket silent, reconnecting",
+					"silent_for", a.clock.Now().Sub(heard).Round(time.Second), "error", err)
+				a.renewWS(ws)
+				return errWSReplaced
+			}
+			heard = a.clock.Now()
 		case ev, ok := <-sub.Events():
 			if !ok {
-				return fmt.Errorf("subscription events channel closed")
+				return replacedOr(replaced, fmt.Errorf("subscription events channel closed"))
 			}
-			data, isStateChanged, parseErr := ev.StateChanged()
-			if parseErr != nil {
-				a.logger.Debug("failed to parse state_changed event", "error", parseErr)
+			heard = a.clock.Now()
+			var te triggerEvent
+			if err := json.Unmarshal(ev.Raw, &te); err != nil {
+				a.logger.Debug("failed to parse trigger event", "error", err)
 				continue
 			}
-			if !isStateChanged {
-				continue
+			entityID := te.Variables.Trigger.EntityID
+			if _, tracked := entitySet[entityID]; tracked {
+				a.logger.Debug("tracked entity changed", "entity_id", entityID)
+				a.cache.observe(entityID)
+				callback(entityID)
 			}
-			if _, tracked := entitySet[data.EntityID]; tracked {
-				a.logger.Debug("tracked entity changed", "entity_id", data.EntityID)
-				callback(data.EntityID)
+		case _, ok := <-started:
+			if !ok {
+				started = nil
+				continue
 			}
+			heard = a.clock.Now()
+			a.logger.Info("Home Assistant started")
+			a.cache.resetAll()
+			a.notifyRestart()
 		case subErr, ok := <-sub.Errors():
 			if !ok {
-				return fmt.Errorf("subscription errors channel closed")
+				return replacedOr(replaced, fmt.Errorf("subscription errors channel closed"))
 			}
 			a.logger.Error("subscription error", "error", subErr)
 			// Auto-reconnect restores the subscription; just log.
@@ -265,14 +652,54 @@ func (a *Adapter) SubscribeChanges(ctx context.Context, entityIDs []string, call
 	}
 }
 
+// eventHAStarted is the event HA fires once it has started and loaded its
+// integrations.
+const eventHAStarted = "homeassistant_started"
+
+// stateTrigger returns a state trigger firing on every state or attribute
+// change of the entities in entitySet, so that HA sends only their changes
+// rather than every state_changed event of the instance.
+func stateTrigger(entitySet map[string]struct{}) map[string]interface{} {
+	entityIDs := make([]string, 0, len(entitySet))
+	for id := range entitySet {
+		entityIDs = append(entityIDs, id)
+	}
+	slices.Sort(entityIDs)
+	return map[string]interface{}{
+		"platform":  "state",
+		"entity_id": entityIDs,
+	}
+}
+
+// triggerEvent is the part of a subscribe_trigger event the adapter reads.
+type triggerEvent struct {
+	Variables struct {
+		Trigger struct {
+			EntityID string `json:"entity_id"`
+		} `json:"trigger"`
+	} `json:"variables"`
+}
+
+// replacedOr returns errWSReplaced if replaced is closed, as closing the
+// previous WebSocket client fails its subscription, and err otherwise.
+func replacedOr(replaced <-chan struct{}, err error) error {
+	select {
+	case <-replaced:
+		return errWSReplaced
+	default:
+		return err
+	}
+}
+
 // serviceBody marshals data to a JSON [io.Reader] for service calls.
 func serviceBody(data map[string]interface{}) io.Reader {
 	b, _ := json.Marshal(data) //nolint:errcheck // map[string]interface{} always marshals
 	return bytes.NewReader(b)
 }
 
-// parseGetItemsResponse extracts todo items from the service call response.
-func parseGetItemsResponse(resp haclient.ServiceCallResponse, entityID string) ([]model.Item, error) {
+// parseGetItemsResponse extracts todo items from the service call response,
+// reading descriptions with desc.
+func parseGetItemsResponse(resp haclient.ServiceCallResponse, entityID string, desc *DescriptionTemplate, loc *time.Location) ([]model.Item, error) {
 	raw, ok := resp.ServiceResponse[entityID]
 	if !ok {
 		return nil, fmt.Errorf("no service response for entity %s", entityID)
@@ -285,7 +712,7 @@ func parseGetItemsResponse(resp haclient.ServiceCallResponse, entityID string) (
 
 	items := make([]model.Item, 0, len(haResp.Items))
 	for _, h := range haResp.Items {
-		items = append(items, haItemToModelItem(h))
+		items = append(items, haItemToModelItem(h, desc, loc))
 	}
 	return items, nil
 }
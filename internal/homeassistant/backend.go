@@ -0,0 +1,75 @@
+package homeassistant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// Backend exposes an [Adapter] as a sync task backend, with HA todo entity
+// IDs as list identifiers. It is the default target of list mappings.
+type Backend struct {
+	a *Adapter
+}
+
+// NewBackend returns the task backend for a.
+func NewBackend(a *Adapter) *Backend {
+	return &Backend{a: a}
+}
+
+// Fetch returns the items of every entity in lists.
+func (b *Backend) Fetch(ctx context.Context, lists []string) ([]*model.Item, error) {
+	var out []*model.Item
+	for _, entityID := range lists {
+		items, err := b.a.GetItems(ctx, entityID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			items[i].ListName = entityID
+			out = append(out, &items[i])
+		}
+	}
+	return out, nil
+}
+
+// Create adds item to entityID. HA does not return the UID of a new item, so
+// it is looked up by title afterwards; "" means no item with that title was
+// found.
+func (b *Backend) Create(ctx context.Context, entityID string, item *model.Item) (string, error) {
+	if err := b.a.AddItem(ctx, entityID, item); err != nil {
+		return "", err
+	}
+	items, err := b.a.GetItems(ctx, entityID)
+	if err != nil {
+		return "", fmt.Errorf("refetching items from %s: %w", entityID, err)
+	}
+	for _, h := range items {
+		if h.Title == item.Title {
+			return h.UID, nil
+		}
+	}
+	return "", nil
+}
+
+// Update writes fields of item to current, which HA identifies by its title.
+func (b *Backend) Update(ctx context.Context, entityID string, current, item *model.Item, fields model.Fields) error {
+	return b.a.UpdateItem(ctx, entityID, current.StoredTitle(), item, fields)
+}
+
+// Delete removes current, which HA identifies by its title.
+func (b *Backend) Delete(ctx context.Context, entityID string, current *model.Item) error {
+	return b.a.RemoveItem(ctx, entityID, current.StoredTitle())
+}
+
+// SupportedFields returns the fields entityID can store, as probed by
+// [Adapter.ProbeCapabilities]. Priority and URL need the entity's
+// description and a description template with a place for them.
+func (b *Backend) SupportedFields(entityID string) model.Fields {
+	f := b.a.Capabilities(entityID).Fields()
+	if !f.Has(model.FieldDescription) {
+		return f
+	}
+	return f&^model.FieldPriority | b.a.desc.Fields()
+}
@@ -0,0 +1,163 @@
+package homeassistant
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+const (
+	// breakerThreshold is the number of consecutive failed calls, each
+	// already retried, after which the breaker opens.
+	breakerThreshold = 5
+
+	// breakerCooldown is how long the breaker stays open before letting a
+	// single trial call through.
+	breakerCooldown = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned (wrapped) by [Adapter] methods while repeated
+// failures have opened its circuit breaker, without contacting HA.
+var ErrCircuitOpen = errors.New("Home Assistant unreachable, circuit open")
+
+// rejectedError is an error response from a reachable HA, such as a 400 for
+// an unknown item. It does not count towards opening the breaker.
+type rejectedError struct {
+	message string
+}
+
+func (e *rejectedError) Error() string { return e.message }
+
+// breaker fails calls fast once HA has failed breakerThreshold calls in a
+// row, so a dead instance costs one error per call instead of a full retry
+// schedule. After breakerCooldown it half-opens: one trial call goes through,
+// closing the breaker on success and reopening it on failure.
+type breaker struct {
+	clock     clock.Clock
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int       // consecutive failed calls
+	openedAt time.Time // zero while closed
+	probing  bool      // a trial call is in flight
+}
+
+func newBreaker(clk clock.Clock) *breaker {
+	return &breaker{clock: clk, threshold: breakerThreshold, cooldown: breakerCooldown}
+}
+
+// allow reports whether a call may go through, returning [ErrCircuitOpen]
+// if not. A call allowed while half-open is the trial call.
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return nil
+	}
+	if b.probing || b.clock.Now().Sub(b.openedAt) < b.cooldown {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// record counts the outcome of an allowed call and reports whether it
+// opened or closed the breaker.
+func (b *breaker) record(err error) (opened, closed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasProbe := b.probing
+	b.probing = false
+
+	if !tripsBreaker(err) {
+		b.failures = 0
+		if b.openedAt.IsZero() {
+			return false, false
+		}
+		b.openedAt = time.Time{}
+		return false, true
+	}
+
+	b.failures++
+	if wasProbe {
+		b.openedAt = b.clock.Now()
+		return false, false
+	}
+	if b.openedAt.IsZero() && b.failures >= b.threshold {
+		b.openedAt = b.clock.Now()
+		return true, false
+	}
+	return false, false
+}
+
+// abandon ends an allowed call without counting its outcome.
+func (b *breaker) abandon() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+// tripsBreaker reports whether err suggests HA is unreachable or failing, as
+// opposed to answering a bad request or rejecting the token.
+func tripsBreaker(err error) bool {
+	var rejected *rejectedError
+	switch {
+	case err == nil,
+		errors.As(err, &rejected),
+		errors.Is(err, model.ErrUnauthorized):
+		return false
+	}
+	return true
+}
+
+// call runs fn with [Retry] unless the breaker is open, and records the
+// outcome. Where HA has several URLs, a call that fails is tried once more
+// if another URL answers.
+func (a *Adapter) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := a.breaker.allow(); err != nil {
+		return err
+	}
+	if a.endpoints.due(a.clock.Now()) {
+		a.selectEndpoint(ctx)
+	}
+	err := a.retry(ctx, fn)
+	if tripsBreaker(err) && ctx.Err() == nil && a.selectEndpoint(ctx) {
+		err = a.retry(ctx, fn)
+	}
+	if ctx.Err() != nil {
+		// The caller gave up, which says nothing about HA.
+		a.breaker.abandon()
+		return err
+	}
+	switch opened, closed := a.breaker.record(err); {
+	case opened:
+		a.logger.Warn("Home Assistant keeps failing, failing calls fast",
+			"failures", a.breaker.threshold, "retry_in", a.breaker.cooldown, "error", err)
+	case closed:
+		a.logger.Info("Home Assistant answering again, circuit closed")
+	}
+	return err
+}
+
+// retry runs fn with [Retry], giving each attempt a context limited to the
+// request timeout once the rate limit lets it through.
+func (a *Adapter) retry(ctx context.Context, fn func(ctx context.Context) error) error {
+	return Retry(ctx, defaultMaxAttempts, func() error {
+		if a.limiter != nil {
+			if err := a.limiter.wait(ctx); err != nil {
+				return err
+			}
+		}
+		if a.requestTimeout <= 0 {
+			return fn(ctx)
+		}
+		attemptCtx, cancel := context.WithTimeout(ctx, a.requestTimeout)
+		defer cancel()
+		return fn(attemptCtx)
+	})
+}
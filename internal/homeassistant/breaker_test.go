@@ -0,0 +1,118 @@
+package homeassistant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	haclient "github.com/mkelcik/go-ha-client/v2"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+func TestBreaker_OpensAndHalfOpens(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	b := newBreaker(clk)
+	down := errors.New("connection refused")
+
+	for i := range breakerThreshold {
+		if err := b.allow(); err != nil {
+			t.Fatalf("call %d: allow() = %v, want nil while closed", i, err)
+		}
+		opened, _ := b.record(down)
+		if opened != (i == breakerThreshold-1) {
+			t.Fatalf("call %d: opened = %v", i, opened)
+		}
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() = %v, want ErrCircuitOpen while open", err)
+	}
+
+	// After the cooldown one trial call goes through; its failure reopens.
+	clk.Advance(breakerCooldown)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() = %v, want a trial call after the cooldown", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() = %v, want one trial call at a time", err)
+	}
+	b.record(down)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() = %v, want reopened after a failed trial", err)
+	}
+
+	clk.Advance(breakerCooldown)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() = %v, want a trial call", err)
+	}
+	if _, closed := b.record(nil); !closed {
+		t.Fatal("successful trial did not close the breaker")
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() = %v, want nil once closed", err)
+	}
+}
+
+func TestBreaker_IgnoresAnsweredErrors(t *testing.T) {
+	b := newBreaker(clock.NewFake(time.Now()))
+	for _, err := range []error{
+		&rejectedError{message: "Unable to find to-do list item"},
+		fmt.Errorf("get items: %w", model.ErrUnauthorized),
+	} {
+		for range breakerThreshold {
+			b.record(err)
+		}
+		if got := b.allow(); got != nil {
+			t.Errorf("after %d x %v: allow() = %v, want closed", breakerThreshold, err, got)
+		}
+	}
+}
+
+func TestAdapter_FailsFastWhileCircuitOpen(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	a, rest := newCachingAdapter(t, clk)
+	a.cache.setLive(false) // every GetItems reaches HA
+	for range breakerThreshold {
+		a.breaker.record(errors.New("connection refused"))
+	}
+
+	if _, err := a.GetItems(context.Background(), "todo.shopping"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("GetItems() error = %v, want ErrCircuitOpen", err)
+	}
+	if rest.gets != 0 {
+		t.Fatalf("get_items called %d times while open, want 0", rest.gets)
+	}
+
+	clk.Advance(breakerCooldown)
+	getItems(t, a)
+	getItems(t, a)
+	if rest.gets != 2 {
+		t.Errorf("get_items called %d times after recovery, want 2", rest.gets)
+	}
+}
+
+// deadlineREST records whether get_items was called with a deadline.
+type deadlineREST struct {
+	countingREST
+	deadline time.Time
+}
+
+func (d *deadlineREST) CallServiceWithResponse(ctx context.Context, domain, service string, body io.Reader) (haclient.ServiceCallResponse, error) {
+	d.deadline, _ = ctx.Deadline()
+	return d.countingREST.CallServiceWithResponse(ctx, domain, service, body)
+}
+
+func TestAdapter_LimitsEachRequest(t *testing.T) {
+	rest := &deadlineREST{}
+	a := NewAdapterWithClient(rest, slog.New(slog.NewTextHandler(io.Discard, nil)), WithRequestTimeout(time.Minute))
+	start := time.Now()
+	getItems(t, a)
+	if rest.deadline.IsZero() || rest.deadline.Sub(start) > time.Minute+time.Second {
+		t.Errorf("request deadline = %v, want within a minute of %v", rest.deadline, start)
+	}
+}
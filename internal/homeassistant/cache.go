@@ -0,0 +1,142 @@
+package homeassistant
+
+import (
+	"sync"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// snapshotCache holds the last GetItems result per entity and decides whether
+// it is still current. A snapshot is reused only while the WebSocket
+// subscription is live and no state_changed event has been seen for the
+// entity since the snapshot was taken.
+type snapshotCache struct {
+	mu        sync.Mutex
+	clock     clock.Clock
+	maxAge    time.Duration
+	connected func() bool // nil → assume connected
+
+	live    bool              // a state_changed subscription is active
+	gen     uint64            // bumped when the subscription (re)starts
+	changes map[string]uint64 // entity → state_changed events seen this gen
+	snaps   map[string]snapshot
+}
+
+// snapshot is a cached GetItems result with the change marks it was taken at.
+type snapshot struct {
+	items     []model.Item
+	gen       uint64
+	changes   uint64
+	fetchedAt time.Time
+}
+
+// mark identifies the cache generation and change count for an entity. It is
+// taken before a fetch so that events arriving mid-fetch invalidate the
+// resulting snapshot.
+type mark struct {
+	gen     uint64
+	changes uint64
+}
+
+func newSnapshotCache(clk clock.Clock, maxAge time.Duration) *snapshotCache {
+	return &snapshotCache{
+		clock:   clk,
+		maxAge:  maxAge,
+		changes: make(map[string]uint64),
+		snaps:   make(map[string]snapshot),
+	}
+}
+
+// get returns a copy of the cached items for entityID if they are current.
+func (c *snapshotCache) get(entityID string) ([]model.Item, bool) {
+	if c.maxAge <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.live || (c.connected != nil && !c.connected()) {
+		return nil, false
+	}
+	s, ok := c.snaps[entityID]
+	if !ok || s.gen != c.gen || s.changes != c.changes[entityID] ||
+		c.clock.Now().Sub(s.fetchedAt) >= c.maxAge {
+		return nil, false
+	}
+	return append([]model.Item(nil), s.items...), true
+}
+
+// markFor returns the current change marks for entityID.
+func (c *snapshotCache) markFor(entityID string) mark {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return mark{gen: c.gen, changes: c.changes[entityID]}
+}
+
+// put stores items fetched for entityID at mark m.
+func (c *snapshotCache) put(entityID string, items []model.Item, m mark) {
+	if c.maxAge <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snaps[entityID] = snapshot{
+		items:     append([]model.Item(nil), items...),
+		gen:       m.gen,
+		changes:   m.changes,
+		fetchedAt: c.clock.Now(),
+	}
+}
+
+// observe records a state_changed event for entityID.
+func (c *snapshotCache) observe(entityID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.changes[entityID]++
+}
+
+// peek returns a copy of the last items fetched for entityID, current or
+// not.
+func (c *snapshotCache) peek(entityID string) ([]model.Item, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.snaps[entityID]
+	if !ok {
+		return nil, false
+	}
+	return append([]model.Item(nil), s.items...), true
+}
+
+// invalidate discards the snapshot for entityID.
+func (c *snapshotCache) invalidate(entityID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.snaps, entityID)
+}
+
+// setLive marks the subscription as started or stopped. Both transitions
+// start a new generation: events may have been missed in between.
+func (c *snapshotCache) setLive(live bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.live = live
+	c.reset()
+}
+
+// reset discards all snapshots by starting a new generation. c.mu must be held.
+func (c *snapshotCache) reset() {
+	c.gen++
+	clear(c.changes)
+	clear(c.snaps)
+}
+
+// resetAll is reset for callers that do not hold c.mu.
+func (c *snapshotCache) resetAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reset()
+}
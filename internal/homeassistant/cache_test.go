@@ -0,0 +1,198 @@
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	haclient "github.com/mkelcik/go-ha-client/v2"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// countingREST is a RESTClient that returns a fixed item list and counts
+// get_items calls.
+type countingREST struct {
+	gets int
+}
+
+func (c *countingREST) Ping(context.Context) error { return nil }
+
+func (c *countingREST) CallService(context.Context, string, string, io.Reader) error { return nil }
+
+func (c *countingREST) FireEvent(context.Context, string, io.Reader) error { return nil }
+
+func (c *countingREST) GetState(context.Context, string) (haclient.StateEntity, error) {
+	return haclient.StateEntity{}, nil
+}
+
+func (c *countingREST) CallServiceWithResponse(_ context.Context, _, _ string, _ io.Reader) (haclient.ServiceCallResponse, error) {
+	c.gets++
+	return haclient.ServiceCallResponse{
+		ServiceResponse: map[string]json.RawMessage{
+			"todo.shopping": json.RawMessage(`{"items":[{"uid":"1","summary":"Milk","status":"needs_action"}]}`),
+		},
+	}, nil
+}
+
+// testCacheMaxAge is the max age of the adapters of newCachingAdapter.
+const testCacheMaxAge = 5 * time.Minute
+
+func newCachingAdapter(t *testing.T, clk clock.Clock) (*Adapter, *countingREST) {
+	t.Helper()
+	rest := &countingREST{}
+	a := NewAdapterWithClient(rest, slog.New(slog.NewTextHandler(io.Discard, nil)), WithClock(clk), WithCacheMaxAge(testCacheMaxAge))
+	// Simulate a live subscription; NewAdapterWithClient has no WebSocket.
+	a.cache.setLive(true)
+	return a, rest
+}
+
+func getItems(t *testing.T, a *Adapter) {
+	t.Helper()
+	items, err := a.GetItems(context.Background(), "todo.shopping")
+	if err != nil {
+		t.Fatalf("GetItems: %v", err)
+	}
+	if len(items) != 1 || items[0].Title != "Milk" {
+		t.Fatalf("GetItems = %+v, want one item \"Milk\"", items)
+	}
+}
+
+func TestGetItems_ReusesSnapshotWhileUnchanged(t *testing.T) {
+	a, rest := newCachingAdapter(t, clock.NewFake(time.Now()))
+
+	getItems(t, a)
+	getItems(t, a)
+
+	if rest.gets != 1 {
+		t.Errorf("get_items calls = %d, want 1", rest.gets)
+	}
+}
+
+func TestGetItems_RefetchesAfterStateChange(t *testing.T) {
+	a, rest := newCachingAdapter(t, clock.NewFake(time.Now()))
+
+	getItems(t, a)
+	a.cache.observe("todo.shopping")
+	getItems(t, a)
+
+	if rest.gets != 2 {
+		t.Errorf("get_items calls = %d, want 2", rest.gets)
+	}
+}
+
+func TestGetItems_RefetchesAfterWrite(t *testing.T) {
+	a, rest := newCachingAdapter(t, clock.NewFake(time.Now()))
+
+	getItems(t, a)
+	if err := a.RemoveItem(context.Background(), "todo.shopping", "Milk"); err != nil {
+		t.Fatalf("RemoveItem: %v", err)
+	}
+	getItems(t, a)
+
+	if rest.gets != 2 {
+		t.Errorf("get_items calls = %d, want 2", rest.gets)
+	}
+}
+
+func TestGetItems_RefetchesAfterMaxAge(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	a, rest := newCachingAdapter(t, clk)
+
+	getItems(t, a)
+	clk.Advance(testCacheMaxAge)
+	getItems(t, a)
+
+	if rest.gets != 2 {
+		t.Errorf("get_items calls = %d, want 2", rest.gets)
+	}
+}
+
+func TestGetItems_NoCacheWithoutSubscription(t *testing.T) {
+	a, rest := newCachingAdapter(t, clock.NewFake(time.Now()))
+	a.cache.setLive(false)
+
+	getItems(t, a)
+	getItems(t, a)
+
+	if rest.gets != 2 {
+		t.Errorf("get_items calls = %d, want 2", rest.gets)
+	}
+}
+
+func TestGetItems_NoCacheWhenDisconnected(t *testing.T) {
+	a, rest := newCachingAdapter(t, clock.NewFake(time.Now()))
+	a.cache.connected = func() bool { return false }
+
+	getItems(t, a)
+	getItems(t, a)
+
+	if rest.gets != 2 {
+		t.Errorf("get_items calls = %d, want 2", rest.gets)
+	}
+}
+
+func TestGetItems_ReconnectDiscardsSnapshots(t *testing.T) {
+	a, rest := newCachingAdapter(t, clock.NewFake(time.Now()))
+
+	getItems(t, a)
+	a.cache.resetAll()
+	getItems(t, a)
+
+	if rest.gets != 2 {
+		t.Errorf("get_items calls = %d, want 2", rest.gets)
+	}
+}
+
+func TestGetItems_CacheDisabled(t *testing.T) {
+	for name, opts := range map[string][]AdapterOption{
+		"default":  nil,
+		"zero age": {WithCacheMaxAge(0)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rest := &countingREST{}
+			a := NewAdapterWithClient(rest, slog.New(slog.NewTextHandler(io.Discard, nil)), opts...)
+			a.cache.setLive(true)
+
+			getItems(t, a)
+			getItems(t, a)
+
+			if rest.gets != 2 {
+				t.Errorf("get_items calls = %d, want 2", rest.gets)
+			}
+		})
+	}
+}
+
+// unavailableREST is a countingREST whose entities are all unavailable: HA
+// leaves them out of get_items responses.
+type unavailableREST struct {
+	countingREST
+}
+
+func (u *unavailableREST) GetState(_ context.Context, entityID string) (haclient.StateEntity, error) {
+	return haclient.StateEntity{EntityID: entityID, State: "unavailable"}, nil
+}
+
+func (u *unavailableREST) CallServiceWithResponse(context.Context, string, string, io.Reader) (haclient.ServiceCallResponse, error) {
+	return haclient.ServiceCallResponse{ServiceResponse: map[string]json.RawMessage{}}, nil
+}
+
+func TestGetItems_UnavailableEntity(t *testing.T) {
+	a := NewAdapterWithClient(&unavailableREST{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	_, err := a.GetItems(context.Background(), "todo.shopping")
+	if !errors.Is(err, model.ErrListUnavailable) {
+		t.Errorf("GetItems error = %v, want model.ErrListUnavailable", err)
+	}
+
+	// An available entity missing from the response is an ordinary error.
+	b := NewAdapterWithClient(&countingREST{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := b.GetItems(context.Background(), "todo.work"); err == nil || errors.Is(err, model.ErrListUnavailable) {
+		t.Errorf("GetItems error = %v, want a failure other than unavailable", err)
+	}
+}
@@ -0,0 +1,112 @@
+package homeassistant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	haclient "github.com/mkelcik/go-ha-client/v2"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// Capabilities is the set of todo features an HA entity supports, as
+// reported in its supported_features attribute. The bits are those of HA's
+// TodoListEntityFeature.
+type Capabilities int
+
+// Todo entity features.
+const (
+	CapCreate      Capabilities = 1 << iota // todo.add_item
+	CapDelete                               // todo.remove_item
+	CapUpdate                               // todo.update_item
+	CapMove                                 // reordering items
+	CapDueDate                              // due dates on items
+	CapDueDateTime                          // due date-times on items
+	CapDescription                          // descriptions on items
+
+	// AllCapabilities is assumed for entities that were not probed or whose
+	// probe failed, so they are written to as before probing existed.
+	AllCapabilities = CapCreate | CapDelete | CapUpdate | CapMove | CapDueDate | CapDueDateTime | CapDescription
+)
+
+// Has reports whether c includes every capability in d.
+func (c Capabilities) Has(d Capabilities) bool {
+	return c&d == d
+}
+
+// String lists the capabilities in c, such as "create,delete,update".
+func (c Capabilities) String() string {
+	names := []string{"create", "delete", "update", "move", "due_date", "due_datetime", "description"}
+	var set []string
+	for i, name := range names {
+		if c.Has(1 << i) {
+			set = append(set, name)
+		}
+	}
+	if len(set) == 0 {
+		return "none"
+	}
+	return strings.Join(set, ",")
+}
+
+// Fields returns the content fields an entity with c can store. Title and
+// completion are part of every todo item; the priority is encoded in the
+// description, so it needs description support.
+func (c Capabilities) Fields() model.Fields {
+	f := model.FieldTitle | model.FieldCompleted
+	if c.Has(CapDescription) {
+		f |= model.FieldDescription | model.FieldPriority
+	}
+	if c&(CapDueDate|CapDueDateTime) != 0 {
+		f |= model.FieldDueDate
+	}
+	return f
+}
+
+// ProbeCapabilities reads the supported_features of every entity in
+// entityIDs and remembers them, so that writes leave out the fields an
+// entity cannot store instead of failing as a whole. An entity that cannot
+// be read keeps [AllCapabilities]; the first such error is returned after
+// probing the others.
+func (a *Adapter) ProbeCapabilities(ctx context.Context, entityIDs []string) error {
+	var firstErr error
+	for _, entityID := range entityIDs {
+		var st haclient.StateEntity
+		err := a.call(ctx, func(ctx context.Context) error {
+			var getErr error
+			st, getErr = a.endpoints.GetState(ctx, entityID)
+			return getErr
+		})
+		features, ok := st.Attributes["supported_features"].(float64)
+		if err == nil && !ok {
+			err = fmt.Errorf("no supported_features attribute")
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("probe capabilities of %s: %w", entityID, err)
+			}
+			continue
+		}
+		caps := Capabilities(features)
+		a.capsMu.Lock()
+		a.caps[entityID] = caps
+		a.capsMu.Unlock()
+		if missing := AllCapabilities &^ CapMove &^ caps; missing != 0 {
+			a.logger.Info("HA entity lacks todo features, leaving them out of writes",
+				"entity_id", entityID, "supported", caps, "missing", missing)
+		}
+	}
+	return firstErr
+}
+
+// Capabilities returns the probed capabilities of entityID, or
+// [AllCapabilities] if it was not probed.
+func (a *Adapter) Capabilities(entityID string) Capabilities {
+	a.capsMu.Lock()
+	defer a.capsMu.Unlock()
+	if caps, ok := a.caps[entityID]; ok {
+		return caps
+	}
+	return AllCapabilities
+}
@@ -0,0 +1,70 @@
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+
+	haclient "github.com/mkelcik/go-ha-client/v2"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// featuresREST is a countingREST whose entities report supported_features
+// and which keeps the body of the last service call.
+type featuresREST struct {
+	countingREST
+	features map[string]float64
+	body     map[string]interface{}
+}
+
+func (f *featuresREST) GetState(_ context.Context, entityID string) (haclient.StateEntity, error) {
+	features, ok := f.features[entityID]
+	if !ok {
+		return haclient.StateEntity{}, &rejectedError{message: "entity " + entityID + " not found"}
+	}
+	return haclient.StateEntity{
+		EntityID:   entityID,
+		Attributes: map[string]interface{}{"supported_features": features},
+	}, nil
+}
+
+func (f *featuresREST) CallService(_ context.Context, _, _ string, body io.Reader) error {
+	f.body = nil
+	return json.NewDecoder(body).Decode(&f.body)
+}
+
+func TestProbeCapabilities_LeavesOutUnsupportedFields(t *testing.T) {
+	rest := &featuresREST{features: map[string]float64{
+		"todo.shopping": float64(CapCreate | CapDelete | CapUpdate),
+		"todo.work":     float64(AllCapabilities),
+	}}
+	a := NewAdapterWithClient(rest, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	err := a.ProbeCapabilities(context.Background(), []string{"todo.shopping", "todo.gone", "todo.work"})
+	if err == nil {
+		t.Error("ProbeCapabilities = nil, want the missing entity reported")
+	}
+	if got := a.Capabilities("todo.gone"); got != AllCapabilities {
+		t.Errorf("Capabilities(todo.gone) = %v, want all assumed", got)
+	}
+	if got := a.Capabilities("todo.shopping"); got.Has(CapDescription) || !got.Has(CapUpdate) {
+		t.Errorf("Capabilities(todo.shopping) = %v, want create,delete,update", got)
+	}
+
+	item := &model.Item{Title: "Milk", Description: "semi-skimmed", Completed: true}
+	if err := a.UpdateItem(context.Background(), "todo.shopping", "Milk", item, model.AllFields); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	if _, ok := rest.body["description"]; ok || rest.body["status"] != statusCompleted {
+		t.Errorf("update body = %v, want the status without the description", rest.body)
+	}
+	if err := a.UpdateItem(context.Background(), "todo.work", "Milk", item, model.AllFields); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	if rest.body["description"] != "semi-skimmed" {
+		t.Errorf("update body = %v, want the description on a full-featured entity", rest.body)
+	}
+}
@@ -14,6 +14,10 @@ const (
 	serviceUpdateItem = "update_item"
 	serviceRemoveItem = "remove_item"
 
+	domainPersistentNotification = "persistent_notification"
+	serviceCreate                = "create"
+	serviceDismiss               = "dismiss"
+
 	statusNeedsAction = "needs_action"
 	statusCompleted   = "completed"
 
@@ -36,22 +40,23 @@ type haItemsResponse struct {
 	Items []haTodoItem `json:"items"`
 }
 
-// haItemToModelItem converts an HA todo item to a [model.Item]. The priority
-// prefix (e.g. "[High] ") is stripped from the description and decoded into
-// the Priority field.
-func haItemToModelItem(h haTodoItem) model.Item {
-	priority, description := model.DecodePriorityPrefix(h.Description)
+// haItemToModelItem converts an HA todo item to a [model.Item]. The
+// description is read with desc, which decodes the priority prefix (e.g.
+// "[High] ") and URL it holds. The due date is parsed in loc.
+func haItemToModelItem(h haTodoItem, desc *DescriptionTemplate, loc *time.Location) model.Item {
+	description, priority, url := desc.Parse(h.Description)
 
 	item := model.Item{
 		UID:         h.UID,
 		Title:       h.Summary,
 		Description: description,
 		Priority:    priority,
+		URL:         url,
 		Completed:   h.Status == statusCompleted,
 	}
 
 	if h.Due != "" {
-		if t, err := parseDue(h.Due); err == nil {
+		if t, err := parseDue(h.Due, loc); err == nil {
 			item.DueDate = &t
 		}
 	}
@@ -59,20 +64,22 @@ func haItemToModelItem(h haTodoItem) model.Item {
 	return item
 }
 
-// buildAddItemData returns the service-call payload for todo.add_item.
-func buildAddItemData(entityID string, item *model.Item) map[string]interface{} {
+// buildAddItemData returns the service-call payload for todo.add_item, with
+// the description rendered by desc. Fields the entity cannot store, as caps
+// says, are left out. The due date is sent as the date it falls on in loc.
+func buildAddItemData(entityID string, item *model.Item, caps Capabilities, desc *DescriptionTemplate, loc *time.Location) map[string]interface{} {
 	data := map[string]interface{}{
 		"entity_id": entityID,
 		"item":      item.Title,
 	}
+	supported := caps.Fields()
 
-	desc := model.EncodePriorityPrefix(item.Priority, item.Description)
-	if desc != "" {
-		data["description"] = desc
+	if d := desc.Render(item); d != "" && supported.Has(model.FieldDescription) {
+		data["description"] = d
 	}
 
-	if item.DueDate != nil {
-		data["due_date"] = formatDue(item.DueDate)
+	if item.DueDate != nil && supported.Has(model.FieldDueDate) {
+		data["due_date"] = formatDue(item.DueDate, loc)
 	}
 
 	return data
@@ -80,27 +87,35 @@ func buildAddItemData(entityID string, item *model.Item) map[string]interface{}
 
 // buildUpdateItemData returns the service-call payload for todo.update_item.
 // currentTitle is the item's title as it currently exists in HA, used to
-// identify the item.
-func buildUpdateItemData(entityID, currentTitle string, item *model.Item) map[string]interface{} {
+// identify the item. Only the HA fields backing fields are sent, so values
+// edited in HA since the last sync — and HA-side formatting — are left alone.
+// Description, priority, and URL share HA's description field, rendered by
+// desc. Fields the entity cannot store, as caps says, are left out. The due
+// date is sent as the date it falls on in loc.
+func buildUpdateItemData(entityID, currentTitle string, item *model.Item, fields model.Fields, caps Capabilities, desc *DescriptionTemplate, loc *time.Location) map[string]interface{} {
 	data := map[string]interface{}{
 		"entity_id": entityID,
 		"item":      currentTitle,
 	}
+	if caps.Has(CapDescription) && fields.Has(desc.Fields()) {
+		data["description"] = desc.Render(item)
+	}
+	fields &= caps.Fields()
 
-	if item.Title != currentTitle {
+	if fields.Has(model.FieldTitle) && item.Title != currentTitle {
 		data["rename"] = item.Title
 	}
 
-	data["description"] = model.EncodePriorityPrefix(item.Priority, item.Description)
-
-	if item.DueDate != nil {
-		data["due_date"] = formatDue(item.DueDate)
+	if fields.Has(model.FieldDueDate) && item.DueDate != nil {
+		data["due_date"] = formatDue(item.DueDate, loc)
 	}
 
-	if item.Completed {
-		data["status"] = statusCompleted
-	} else {
-		data["status"] = statusNeedsAction
+	if fields.Has(model.FieldCompleted) {
+		if item.Completed {
+			data["status"] = statusCompleted
+		} else {
+			data["status"] = statusNeedsAction
+		}
 	}
 
 	return data
@@ -121,16 +136,39 @@ func buildGetItemsData(entityID string) map[string]interface{} {
 	}
 }
 
-// parseDue parses an HA due-date string. It tries date-only format first
-// ("2006-01-02"), then falls back to RFC 3339.
-func parseDue(s string) (time.Time, error) {
-	if t, err := time.Parse(dateLayout, s); err == nil {
+// buildNotificationData returns the service-call payload for
+// persistent_notification.create.
+func buildNotificationData(notificationID, title, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"notification_id": notificationID,
+		"title":           title,
+		"message":         message,
+	}
+}
+
+// buildDismissNotificationData returns the service-call payload for
+// persistent_notification.dismiss.
+func buildDismissNotificationData(notificationID string) map[string]interface{} {
+	return map[string]interface{}{
+		"notification_id": notificationID,
+	}
+}
+
+// parseDue parses an HA due-date string. A date-only string ("2006-01-02")
+// is midnight of that date in loc; an RFC 3339 one keeps its instant,
+// expressed in loc.
+func parseDue(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation(dateLayout, s, loc); err == nil {
 		return t, nil
 	}
-	return time.Parse(time.RFC3339, s)
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
 }
 
-// formatDue formats a time value as a date-only string for HA.
-func formatDue(t *time.Time) string {
-	return t.Format(dateLayout)
+// formatDue formats the date t falls on in loc as a date-only string for HA.
+func formatDue(t *time.Time, loc *time.Location) string {
+	return t.In(loc).Format(dateLayout)
 }
@@ -3,6 +3,7 @@ package homeassistant
 import (
 	"testing"
 	"time"
+	_ "time/tzdata" // zones for the DST tests
 
 	"github.com/njoerd114/reminderrelay/internal/model"
 )
@@ -20,7 +21,7 @@ func TestHAItemToModelItem_FullFields(t *testing.T) {
 		Due:         "2026-03-15",
 	}
 
-	got := haItemToModelItem(h)
+	got := haItemToModelItem(h, defaultDescription, time.UTC)
 
 	if got.UID != "ha-uid-123" {
 		t.Errorf("UID = %q, want %q", got.UID, "ha-uid-123")
@@ -52,7 +53,7 @@ func TestHAItemToModelItem_CompletedStatus(t *testing.T) {
 		Summary: "Done task",
 		Status:  statusCompleted,
 	}
-	got := haItemToModelItem(h)
+	got := haItemToModelItem(h, defaultDescription, time.UTC)
 	if !got.Completed {
 		t.Error("Completed = false, want true for status=completed")
 	}
@@ -65,7 +66,7 @@ func TestHAItemToModelItem_NoPriorityPrefix(t *testing.T) {
 		Status:      statusNeedsAction,
 		Description: "Just a note",
 	}
-	got := haItemToModelItem(h)
+	got := haItemToModelItem(h, defaultDescription, time.UTC)
 	if got.Priority != model.PriorityNone {
 		t.Errorf("Priority = %v, want %v", got.Priority, model.PriorityNone)
 	}
@@ -80,7 +81,7 @@ func TestHAItemToModelItem_MediumPriority(t *testing.T) {
 		Summary:     "Medium task",
 		Description: "[Medium] Some details",
 	}
-	got := haItemToModelItem(h)
+	got := haItemToModelItem(h, defaultDescription, time.UTC)
 	if got.Priority != model.PriorityMedium {
 		t.Errorf("Priority = %v, want %v", got.Priority, model.PriorityMedium)
 	}
@@ -95,7 +96,7 @@ func TestHAItemToModelItem_LowPriority(t *testing.T) {
 		Summary:     "Low task",
 		Description: "[Low] Not urgent",
 	}
-	got := haItemToModelItem(h)
+	got := haItemToModelItem(h, defaultDescription, time.UTC)
 	if got.Priority != model.PriorityLow {
 		t.Errorf("Priority = %v, want %v", got.Priority, model.PriorityLow)
 	}
@@ -107,7 +108,7 @@ func TestHAItemToModelItem_NoDueDate(t *testing.T) {
 		Summary: "No deadline",
 		Status:  statusNeedsAction,
 	}
-	got := haItemToModelItem(h)
+	got := haItemToModelItem(h, defaultDescription, time.UTC)
 	if got.DueDate != nil {
 		t.Errorf("DueDate = %v, want nil", got.DueDate)
 	}
@@ -119,7 +120,7 @@ func TestHAItemToModelItem_RFC3339DueDate(t *testing.T) {
 		Summary: "Datetime due",
 		Due:     "2026-04-01T14:30:00+02:00",
 	}
-	got := haItemToModelItem(h)
+	got := haItemToModelItem(h, defaultDescription, time.UTC)
 	if got.DueDate == nil {
 		t.Fatal("DueDate = nil, want parsed datetime")
 	}
@@ -135,7 +136,7 @@ func TestHAItemToModelItem_EmptyDescription(t *testing.T) {
 		Summary: "No notes",
 		Status:  statusNeedsAction,
 	}
-	got := haItemToModelItem(h)
+	got := haItemToModelItem(h, defaultDescription, time.UTC)
 	if got.Description != "" {
 		t.Errorf("Description = %q, want empty", got.Description)
 	}
@@ -157,7 +158,7 @@ func TestBuildAddItemData_FullFields(t *testing.T) {
 		DueDate:     &due,
 	}
 
-	data := buildAddItemData("todo.shopping", item)
+	data := buildAddItemData("todo.shopping", item, AllCapabilities, defaultDescription, time.UTC)
 
 	if data["entity_id"] != "todo.shopping" {
 		t.Errorf("entity_id = %v, want todo.shopping", data["entity_id"])
@@ -179,7 +180,7 @@ func TestBuildAddItemData_NoPriorityNoDescription(t *testing.T) {
 		Priority: model.PriorityNone,
 	}
 
-	data := buildAddItemData("todo.work", item)
+	data := buildAddItemData("todo.work", item, AllCapabilities, defaultDescription, time.UTC)
 
 	if _, ok := data["description"]; ok {
 		t.Errorf("description should be absent for no-priority empty description, got %v", data["description"])
@@ -195,7 +196,7 @@ func TestBuildAddItemData_PriorityOnlyNoDescription(t *testing.T) {
 		Priority: model.PriorityMedium,
 	}
 
-	data := buildAddItemData("todo.work", item)
+	data := buildAddItemData("todo.work", item, AllCapabilities, defaultDescription, time.UTC)
 
 	// "[Medium] " + "" = "[Medium] "
 	if data["description"] != "[Medium] " {
@@ -217,7 +218,7 @@ func TestBuildUpdateItemData_TitleChanged(t *testing.T) {
 		DueDate:     &due,
 	}
 
-	data := buildUpdateItemData("todo.shopping", "Old title", item)
+	data := buildUpdateItemData("todo.shopping", "Old title", item, model.AllFields, AllCapabilities, defaultDescription, time.UTC)
 
 	if data["entity_id"] != "todo.shopping" {
 		t.Errorf("entity_id = %v, want todo.shopping", data["entity_id"])
@@ -245,7 +246,7 @@ func TestBuildUpdateItemData_TitleUnchanged(t *testing.T) {
 		Completed: true,
 	}
 
-	data := buildUpdateItemData("todo.work", "Same title", item)
+	data := buildUpdateItemData("todo.work", "Same title", item, model.AllFields, AllCapabilities, defaultDescription, time.UTC)
 
 	if _, ok := data["rename"]; ok {
 		t.Error("rename should be absent when title unchanged")
@@ -255,6 +256,62 @@ func TestBuildUpdateItemData_TitleUnchanged(t *testing.T) {
 	}
 }
 
+func TestBuildUpdateItemData_CompletionOnly(t *testing.T) {
+	due := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	item := &model.Item{
+		Title:       "Same title",
+		Description: "notes",
+		Priority:    model.PriorityHigh,
+		DueDate:     &due,
+		Completed:   true,
+	}
+
+	data := buildUpdateItemData("todo.work", "Same title", item, model.FieldCompleted, AllCapabilities, defaultDescription, time.UTC)
+
+	for _, key := range []string{"rename", "description", "due_date"} {
+		if _, ok := data[key]; ok {
+			t.Errorf("%s = %v, want absent for a completion-only update", key, data[key])
+		}
+	}
+	if data["status"] != statusCompleted {
+		t.Errorf("status = %v, want %s", data["status"], statusCompleted)
+	}
+}
+
+func TestBuildUpdateItemData_PriorityRewritesDescription(t *testing.T) {
+	item := &model.Item{Title: "Same title", Description: "notes", Priority: model.PriorityLow}
+
+	data := buildUpdateItemData("todo.work", "Same title", item, model.FieldPriority, AllCapabilities, defaultDescription, time.UTC)
+
+	if data["description"] != "[Low] notes" {
+		t.Errorf("description = %v, want [Low] notes", data["description"])
+	}
+	if _, ok := data["status"]; ok {
+		t.Error("status should be absent when completion is unchanged")
+	}
+}
+
+func TestBuildItemData_OmitsUnsupportedFields(t *testing.T) {
+	due := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	item := &model.Item{Title: "Renamed", Description: "notes", Priority: model.PriorityHigh, DueDate: &due, Completed: true}
+	caps := CapCreate | CapDelete | CapUpdate // a bare shopping list
+
+	add := buildAddItemData("todo.shopping", item, caps, defaultDescription, time.UTC)
+	update := buildUpdateItemData("todo.shopping", "Old title", item, model.AllFields, caps, defaultDescription, time.UTC)
+
+	for name, data := range map[string]map[string]interface{}{"add": add, "update": update} {
+		if _, ok := data["description"]; ok {
+			t.Errorf("%s: description = %v, want it left out", name, data["description"])
+		}
+		if _, ok := data["due_date"]; ok {
+			t.Errorf("%s: due_date = %v, want it left out", name, data["due_date"])
+		}
+	}
+	if update["rename"] != "Renamed" || update["status"] != statusCompleted {
+		t.Errorf("update = %v, want the title and status still written", update)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // buildRemoveItemData
 // ---------------------------------------------------------------------------
@@ -270,12 +327,35 @@ func TestBuildRemoveItemData(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// buildNotificationData
+// ---------------------------------------------------------------------------
+
+func TestBuildNotificationData(t *testing.T) {
+	data := buildNotificationData("reminderrelay_problems", "ReminderRelay", "Shopping is failing")
+
+	if data["notification_id"] != "reminderrelay_problems" {
+		t.Errorf("notification_id = %v, want reminderrelay_problems", data["notification_id"])
+	}
+	if data["title"] != "ReminderRelay" {
+		t.Errorf("title = %v, want ReminderRelay", data["title"])
+	}
+	if data["message"] != "Shopping is failing" {
+		t.Errorf("message = %v, want Shopping is failing", data["message"])
+	}
+
+	dismiss := buildDismissNotificationData("reminderrelay_problems")
+	if len(dismiss) != 1 || dismiss["notification_id"] != "reminderrelay_problems" {
+		t.Errorf("dismiss payload = %v, want only notification_id", dismiss)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // parseDue / formatDue
 // ---------------------------------------------------------------------------
 
 func TestParseDue_DateOnly(t *testing.T) {
-	got, err := parseDue("2026-03-15")
+	got, err := parseDue("2026-03-15", time.UTC)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -286,7 +366,7 @@ func TestParseDue_DateOnly(t *testing.T) {
 }
 
 func TestParseDue_RFC3339(t *testing.T) {
-	got, err := parseDue("2026-04-01T14:30:00+02:00")
+	got, err := parseDue("2026-04-01T14:30:00+02:00", time.UTC)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -296,7 +376,7 @@ func TestParseDue_RFC3339(t *testing.T) {
 }
 
 func TestParseDue_Invalid(t *testing.T) {
-	_, err := parseDue("not-a-date")
+	_, err := parseDue("not-a-date", time.UTC)
 	if err == nil {
 		t.Error("expected error for invalid date, got nil")
 	}
@@ -304,12 +384,49 @@ func TestParseDue_Invalid(t *testing.T) {
 
 func TestFormatDue(t *testing.T) {
 	d := time.Date(2026, 12, 25, 10, 30, 0, 0, time.UTC)
-	got := formatDue(&d)
+	got := formatDue(&d, time.UTC)
 	if got != "2026-12-25" {
 		t.Errorf("formatDue = %q, want %q", got, "2026-12-25")
 	}
 }
 
+func TestDue_RoundTripsAcrossDST(t *testing.T) {
+	for _, zone := range []string{"America/Los_Angeles", "Europe/Berlin", "Pacific/Auckland"} {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The days clocks change in 2026 in the zones above, and their eves.
+		for _, date := range []string{"2026-03-07", "2026-03-08", "2026-03-28", "2026-03-29", "2026-04-05", "2026-09-27", "2026-10-25", "2026-11-01"} {
+			due, err := parseDue(date, loc)
+			if err != nil {
+				t.Fatalf("%s: parseDue(%q): %v", zone, date, err)
+			}
+			if h, m, _ := due.Clock(); h != 0 || m != 0 {
+				t.Errorf("%s: parseDue(%q) = %v, want midnight", zone, date, due)
+			}
+			if got := formatDue(&due, loc); got != date {
+				t.Errorf("%s: formatDue(parseDue(%q)) = %q", zone, date, got)
+			}
+		}
+	}
+}
+
+func TestFormatDue_UsesZoneDate(t *testing.T) {
+	loc, err := time.LoadLocation("Pacific/Auckland")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Midnight in Auckland is still the previous day in UTC.
+	due := time.Date(2026, 6, 1, 0, 0, 0, 0, loc)
+	if got := formatDue(&due, loc); got != "2026-06-01" {
+		t.Errorf("formatDue = %q, want 2026-06-01", got)
+	}
+	if got := formatDue(&due, time.UTC); got != "2026-05-31" {
+		t.Errorf("formatDue in UTC = %q, want 2026-05-31", got)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Round-trip: model.Item → addData → haTodoItem → model.Item
 // ---------------------------------------------------------------------------
@@ -325,7 +442,7 @@ func TestConversionRoundTrip(t *testing.T) {
 	}
 
 	// model.Item → addData
-	data := buildAddItemData("todo.events", original)
+	data := buildAddItemData("todo.events", original, AllCapabilities, defaultDescription, time.UTC)
 
 	// Simulate what HA would return via get_items
 	haItem := haTodoItem{
@@ -337,7 +454,7 @@ func TestConversionRoundTrip(t *testing.T) {
 	}
 
 	// haTodoItem → model.Item
-	result := haItemToModelItem(haItem)
+	result := haItemToModelItem(haItem, defaultDescription, time.UTC)
 
 	if result.Title != original.Title {
 		t.Errorf("Title = %q, want %q", result.Title, original.Title)
@@ -0,0 +1,172 @@
+package homeassistant
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	haclient "github.com/mkelcik/go-ha-client/v2"
+)
+
+// endpointRecheck is how often an adapter using a fallback URL checks
+// whether a preferred one answers again.
+const endpointRecheck = 5 * time.Minute
+
+// WithFallbackURLs lets [NewAdapter] fall back to urls, in order, while the
+// primary URL does not answer, e.g. a Nabu Casa remote URL for when the
+// machine is away from the LAN. The adapter returns to a preferred URL once
+// it answers again.
+func WithFallbackURLs(urls ...string) AdapterOption {
+	return func(a *Adapter) { a.fallbackURLs = urls }
+}
+
+// endpoint is one URL Home Assistant is reachable at.
+type endpoint struct {
+	url   string
+	rest  RESTClient
+	newWS func() *haclient.WSClient // nil where the WebSocket is unavailable
+}
+
+// endpoints routes calls to the active one of the URLs Home Assistant is
+// reachable at, listed in order of preference. It implements [RESTClient].
+type endpoints struct {
+	list []*endpoint
+
+	mu       sync.Mutex
+	active   int                // index into list
+	ws       *haclient.WSClient // of the active endpoint; nil without WebSocket
+	checked  time.Time          // when the endpoints were last pinged; zero before
+	replaced chan struct{}      // closed when ws is replaced; see webSocket
+}
+
+func newEndpoints(list ...*endpoint) *endpoints {
+	e := &endpoints{list: list, replaced: make(chan struct{})}
+	if list[0].newWS != nil {
+		e.ws = list[0].newWS()
+	}
+	return e
+}
+
+// current returns the active endpoint.
+func (e *endpoints) current() *endpoint {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.list[e.active]
+}
+
+// webSocket returns the WebSocket client of the active endpoint, nil if
+// there is none, and a channel closed once the client is replaced, because
+// another endpoint became active or the client was renewed.
+func (e *endpoints) webSocket() (*haclient.WSClient, <-chan struct{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.ws, e.replaced
+}
+
+// renew replaces the WebSocket client ws of the active endpoint with a new
+// one. It reports false if ws was already replaced.
+func (e *endpoints) renew(ws *haclient.WSClient) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if ws == nil || ws != e.ws {
+		return false
+	}
+	e.ws = e.list[e.active].newWS()
+	close(e.replaced)
+	e.replaced = make(chan struct{})
+	return true
+}
+
+// due reports whether the endpoints should be pinged before a call made at
+// now: before the first call, and every endpointRecheck while a fallback is
+// active.
+func (e *endpoints) due(now time.Time) bool {
+	if len(e.list) < 2 {
+		return false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.checked.IsZero() || (e.active > 0 && now.Sub(e.checked) >= endpointRecheck)
+}
+
+// activate records that the endpoints were pinged at now and makes endpoint
+// i active. If that changed the active endpoint, it returns the WebSocket
+// client of the previous one, for the caller to close, and true.
+func (e *endpoints) activate(i int, now time.Time) (*haclient.WSClient, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.checked = now
+	if i == e.active {
+		return nil, false
+	}
+	old := e.ws
+	e.active, e.ws = i, nil
+	if e.list[i].newWS != nil {
+		e.ws = e.list[i].newWS()
+	}
+	close(e.replaced)
+	e.replaced = make(chan struct{})
+	return old, true
+}
+
+func (e *endpoints) Ping(ctx context.Context) error {
+	return e.current().rest.Ping(ctx)
+}
+
+func (e *endpoints) CallService(ctx context.Context, domain, service string, body io.Reader) error {
+	return e.current().rest.CallService(ctx, domain, service, body)
+}
+
+func (e *endpoints) CallServiceWithResponse(ctx context.Context, domain, service string, body io.Reader) (haclient.ServiceCallResponse, error) {
+	return e.current().rest.CallServiceWithResponse(ctx, domain, service, body)
+}
+
+func (e *endpoints) GetState(ctx context.Context, entityID string) (haclient.StateEntity, error) {
+	return e.current().rest.GetState(ctx, entityID)
+}
+
+func (e *endpoints) FireEvent(ctx context.Context, eventType string, body io.Reader) error {
+	return e.current().rest.FireEvent(ctx, eventType, body)
+}
+
+// selectEndpoint pings the endpoints in order of preference and activates
+// the first that answers, keeping the active one if none does. It reports
+// whether the active endpoint changed.
+func (a *Adapter) selectEndpoint(ctx context.Context) bool {
+	eps := a.endpoints
+	if len(eps.list) < 2 {
+		return false
+	}
+	for i, ep := range eps.list {
+		if !a.answers(ctx, ep) {
+			continue
+		}
+		old, changed := eps.activate(i, a.clock.Now())
+		if !changed {
+			return false
+		}
+		if old != nil {
+			_ = old.Close()
+		}
+		// The new WebSocket has seen no events yet.
+		a.cache.resetAll()
+		a.logger.Info("switched Home Assistant URL", "url", ep.url, "preferred", i == 0)
+		return true
+	}
+	eps.mu.Lock()
+	eps.checked = a.clock.Now()
+	eps.mu.Unlock()
+	return false
+}
+
+// answers reports whether ep answers a single ping within the request
+// timeout. An unauthorized answer counts: the URL is reachable.
+func (a *Adapter) answers(ctx context.Context, ep *endpoint) bool {
+	if a.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.requestTimeout)
+		defer cancel()
+	}
+	return !tripsBreaker(ep.rest.Ping(ctx))
+}
@@ -0,0 +1,83 @@
+package homeassistant
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	haclient "github.com/mkelcik/go-ha-client/v2"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+)
+
+// switchableREST is a countingREST that can be taken offline.
+type switchableREST struct {
+	countingREST
+	down bool
+}
+
+func (s *switchableREST) Ping(ctx context.Context) error {
+	if s.down {
+		return errors.New("connection refused")
+	}
+	return s.countingREST.Ping(ctx)
+}
+
+func (s *switchableREST) CallServiceWithResponse(ctx context.Context, domain, service string, body io.Reader) (haclient.ServiceCallResponse, error) {
+	if s.down {
+		return haclient.ServiceCallResponse{}, errors.New("connection refused")
+	}
+	return s.countingREST.CallServiceWithResponse(ctx, domain, service, body)
+}
+
+func newFailoverAdapter(clk clock.Clock) (*Adapter, *switchableREST, *switchableREST) {
+	local, remote := &switchableREST{}, &switchableREST{}
+	a := newAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)), []AdapterOption{WithClock(clk)})
+	a.endpoints = newEndpoints(
+		&endpoint{url: "http://homeassistant.local:8123", rest: local},
+		&endpoint{url: "https://example.ui.nabu.casa", rest: remote},
+	)
+	return a, local, remote
+}
+
+func TestAdapter_PrefersFirstAnsweringURL(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	a, local, remote := newFailoverAdapter(clk)
+	local.down = true
+
+	getItems(t, a)
+	if local.gets != 0 || remote.gets != 1 {
+		t.Fatalf("gets = local %d, remote %d; want the remote URL used while local is down", local.gets, remote.gets)
+	}
+
+	// Back home: the local URL is preferred again at the next recheck.
+	local.down = false
+	getItems(t, a)
+	if remote.gets != 2 {
+		t.Fatalf("remote gets = %d, want the remote URL kept until the recheck", remote.gets)
+	}
+	clk.Advance(endpointRecheck)
+	getItems(t, a)
+	if local.gets != 1 || remote.gets != 2 {
+		t.Errorf("gets = local %d, remote %d; want the local URL used again", local.gets, remote.gets)
+	}
+}
+
+func TestAdapter_FailsOverAfterFailedCall(t *testing.T) {
+	a, local, remote := newFailoverAdapter(clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)))
+	getItems(t, a)
+
+	// Leaving the house: the call fails on the local URL and is tried once
+	// more on the remote one.
+	local.down = true
+	getItems(t, a)
+	if local.gets != 1 || remote.gets != 1 {
+		t.Errorf("gets = local %d, remote %d; want the failed call repeated on the remote URL", local.gets, remote.gets)
+	}
+	if err := a.breaker.allow(); err != nil {
+		t.Errorf("breaker = %v after a successful failover, want closed", err)
+	}
+}
@@ -0,0 +1,33 @@
+package homeassistant
+
+import (
+	"context"
+	"time"
+
+	haclient "github.com/mkelcik/go-ha-client/v2"
+)
+
+// heartbeatInterval is how long the WebSocket may go without traffic before
+// it is pinged. A ping must be answered within the request timeout.
+const heartbeatInterval = time.Minute
+
+// ping sends a WebSocket ping on ws and waits for the pong.
+func (a *Adapter) ping(ctx context.Context, ws *haclient.WSClient) error {
+	if a.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.requestTimeout)
+		defer cancel()
+	}
+	return ws.Ping(ctx)
+}
+
+// renewWS replaces ws, unless already replaced, with a new client for the
+// same URL and closes it, stopping its own reconnect attempts.
+func (a *Adapter) renewWS(ws *haclient.WSClient) {
+	if !a.endpoints.renew(ws) {
+		return
+	}
+	_ = ws.Close()
+	// Events may have been missed while the connection was silent.
+	a.cache.resetAll()
+}
@@ -0,0 +1,234 @@
+package homeassistant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+)
+
+// wsServer is an HA stand-in that accepts WebSocket trigger subscriptions,
+// sends events on request, and answers pings until silenced, like a
+// half-open connection.
+type wsServer struct {
+	*httptest.Server
+	silent     atomic.Bool
+	conns      atomic.Int32
+	subscribed chan int32          // receives the connection number of each subscription
+	triggers   chan map[string]any // receives the trigger of each subscription
+	events     chan map[string]any // event payloads to send on the latest subscription
+	starts     chan struct{}       // sends a homeassistant_started event for each value
+}
+
+func newWSServer(t *testing.T) *wsServer {
+	t.Helper()
+	s := &wsServer{
+		subscribed: make(chan int32, 4),
+		triggers:   make(chan map[string]any, 4),
+		events:     make(chan map[string]any),
+		starts:     make(chan struct{}),
+	}
+	upgrader := websocket.Upgrader{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		n := s.conns.Add(1)
+		var writeMu sync.Mutex
+		write := func(v any) {
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			_ = conn.WriteJSON(v)
+		}
+
+		write(map[string]string{"type": "auth_required"})
+		var msg map[string]any
+		if conn.ReadJSON(&msg) != nil {
+			return
+		}
+		write(map[string]string{"type": "auth_ok"})
+		done := make(chan struct{})
+		defer close(done)
+		for {
+			msg = nil
+			if conn.ReadJSON(&msg) != nil {
+				return
+			}
+			switch msg["type"] {
+			case "subscribe_trigger":
+				id := msg["id"]
+				write(map[string]any{"id": id, "type": "result", "success": true})
+				go func() {
+					for {
+						select {
+						case <-done:
+							return
+						case ev := <-s.events:
+							write(map[string]any{"id": id, "type": "event", "event": ev})
+						}
+					}
+				}()
+				s.triggers <- msg["trigger"].(map[string]any)
+				s.subscribed <- n
+			case "subscribe_events":
+				id := msg["id"]
+				write(map[string]any{"id": id, "type": "result", "success": true})
+				if msg["event_type"] == eventHAStarted {
+					go func() {
+						for {
+							select {
+							case <-done:
+								return
+							case <-s.starts:
+								write(map[string]any{"id": id, "type": "event", "event": map[string]any{"event_type": eventHAStarted}})
+							}
+						}
+					}()
+				}
+			case "unsubscribe_events":
+				write(map[string]any{"id": msg["id"], "type": "result", "success": true})
+			case "ping":
+				if !s.silent.Load() {
+					write(map[string]any{"id": msg["id"], "type": "pong"})
+				}
+			}
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *wsServer) waitSubscribed(t *testing.T) int32 {
+	t.Helper()
+	select {
+	case n := <-s.subscribed:
+		return n
+	case <-time.After(5 * time.Second):
+		t.Fatal("no subscription within 5s")
+		return 0
+	}
+}
+
+func TestSubscribeChanges_ReconnectsSilentWebSocket(t *testing.T) {
+	srv := newWSServer(t)
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	a, err := NewAdapter(srv.URL, "token", slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithClock(clk), WithRequestTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewAdapter: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := a.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer func() { _ = a.Close() }()
+
+	done := make(chan error, 1)
+	go func() { done <- a.SubscribeChanges(ctx, []string{"todo.shopping"}, func(string) {}) }()
+	if n := srv.waitSubscribed(t); n != 1 {
+		t.Fatalf("subscribed on connection %d, want 1", n)
+	}
+	<-srv.triggers
+
+	// An answered heartbeat keeps the connection.
+	clk.BlockUntil(1)
+	clk.Advance(heartbeatInterval)
+	time.Sleep(300 * time.Millisecond) // longer than the ping may take
+	if n := srv.conns.Load(); n != 1 {
+		t.Fatalf("%d connections after an answered heartbeat, want 1", n)
+	}
+
+	// An unanswered one replaces it.
+	srv.silent.Store(true)
+	clk.Advance(heartbeatInterval)
+	if n := srv.waitSubscribed(t); n != 2 {
+		t.Errorf("resubscribed on connection %d, want 2", n)
+	}
+	<-srv.triggers
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("SubscribeChanges() = %v, want context.Canceled", err)
+	}
+}
+
+func TestSubscribeChanges_TriggersOnMappedEntities(t *testing.T) {
+	srv := newWSServer(t)
+	a, err := NewAdapter(srv.URL, "token", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewAdapter: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := a.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer func() { _ = a.Close() }()
+
+	changed := make(chan string, 1)
+	go func() {
+		_ = a.SubscribeChanges(ctx, []string{"todo.work", "todo.shopping"}, func(id string) { changed <- id })
+	}()
+	srv.waitSubscribed(t)
+	trigger := <-srv.triggers
+	if trigger["platform"] != "state" || fmt.Sprint(trigger["entity_id"]) != "[todo.shopping todo.work]" {
+		t.Errorf("trigger = %v, want a state trigger on the mapped entities", trigger)
+	}
+
+	srv.events <- map[string]any{"variables": map[string]any{"trigger": map[string]any{
+		"platform": "state", "entity_id": "todo.shopping",
+	}}}
+	select {
+	case id := <-changed:
+		if id != "todo.shopping" {
+			t.Errorf("callback(%q), want todo.shopping", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no callback within 5s")
+	}
+}
+
+func TestSubscribeChanges_ReportsHAStart(t *testing.T) {
+	srv := newWSServer(t)
+	a, err := NewAdapter(srv.URL, "token", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewAdapter: %v", err)
+	}
+	restarted := make(chan struct{}, 1)
+	a.OnRestart(func() { restarted <- struct{}{} })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := a.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer func() { _ = a.Close() }()
+
+	go func() { _ = a.SubscribeChanges(ctx, []string{"todo.shopping"}, func(string) {}) }()
+	srv.waitSubscribed(t)
+	<-srv.triggers
+
+	select {
+	case srv.starts <- struct{}{}:
+	case <-time.After(5 * time.Second):
+		t.Fatal("not subscribed to start events within 5s")
+	}
+	select {
+	case <-restarted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("restart not reported within 5s")
+	}
+}
@@ -0,0 +1,59 @@
+package homeassistant
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+)
+
+// WithRateLimit limits the adapter to perSecond REST requests a second,
+// retries included, allowing bursts of up to one second's worth. Zero, the
+// default, disables the limit.
+func WithRateLimit(perSecond float64) AdapterOption {
+	return func(a *Adapter) { a.rateLimit = perSecond }
+}
+
+// limiter is a token bucket shared by every request of an adapter. Requests
+// over the limit reserve a future token and wait for it, so waiting requests
+// go out in the order they arrived.
+type limiter struct {
+	clock clock.Clock
+	rate  float64 // tokens added per second
+	burst float64 // bucket size
+
+	mu     sync.Mutex
+	tokens float64   // negative while requests wait for reserved tokens
+	last   time.Time // when tokens was last refilled
+}
+
+func newLimiter(clk clock.Clock, perSecond float64) *limiter {
+	burst := max(1, perSecond)
+	return &limiter{clock: clk, rate: perSecond, burst: burst, tokens: burst, last: clk.Now()}
+}
+
+// wait blocks until a request may be sent or ctx ends.
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := l.clock.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	deficit := -l.tokens
+	l.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+	select {
+	case <-l.clock.After(time.Duration(deficit / l.rate * float64(time.Second))):
+		return nil
+	case <-ctx.Done():
+		// Hand the reserved token back for the requests behind this one.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,85 @@
+package homeassistant
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+)
+
+func TestLimiter_BurstThenRate(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	l := newLimiter(clk, 2)
+	ctx := context.Background()
+
+	for i := range 2 {
+		if err := l.wait(ctx); err != nil {
+			t.Fatalf("wait %d = %v within the burst", i, err)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- l.wait(ctx) }()
+	clk.BlockUntil(1)
+	select {
+	case <-done:
+		t.Fatal("third request let through before a token was added")
+	default:
+	}
+	clk.Advance(500 * time.Millisecond)
+	if err := <-done; err != nil {
+		t.Errorf("wait = %v after a token was added", err)
+	}
+}
+
+func TestLimiter_CancelReturnsToken(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	l := newLimiter(clk, 1)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("wait = %v within the burst", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- l.wait(ctx) }()
+	clk.BlockUntil(1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("wait = %v after cancel, want context.Canceled", err)
+	}
+
+	// The cancelled request's reservation is not charged to the next one.
+	clk.Advance(time.Second)
+	if err := l.wait(context.Background()); err != nil {
+		t.Errorf("wait = %v a second later, want a token available", err)
+	}
+}
+
+func TestAdapter_RateLimitsRequests(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	rest := &countingREST{}
+	// WithRateLimit before WithClock still limits on the adapter's clock.
+	a := NewAdapterWithClient(rest, slog.New(slog.NewTextHandler(io.Discard, nil)), WithRateLimit(1), WithClock(clk))
+	getItems(t, a)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := a.GetItems(context.Background(), "todo.shopping")
+		done <- err
+	}()
+	clk.BlockUntil(1)
+	if rest.gets != 1 {
+		t.Fatalf("gets = %d before a token was added, want 1", rest.gets)
+	}
+	clk.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("GetItems: %v", err)
+	}
+	if rest.gets != 2 {
+		t.Errorf("gets = %d, want 2", rest.gets)
+	}
+}
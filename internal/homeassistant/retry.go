@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"math/rand"
 	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
 )
 
 const (
@@ -26,6 +28,12 @@ const (
 // jitter. It returns nil on the first successful call, or a wrapped error
 // containing the last failure if all attempts are exhausted.
 func Retry(ctx context.Context, maxAttempts int, fn func() error) error {
+	return RetryWithClock(ctx, clock.Real(), maxAttempts, fn)
+}
+
+// RetryWithClock is [Retry] with backoff delays measured by clk, so tests can
+// drive the schedule without sleeping.
+func RetryWithClock(ctx context.Context, clk clock.Clock, maxAttempts int, fn func() error) error {
 	var lastErr error
 	for attempt := range maxAttempts {
 		if err := ctx.Err(); err != nil {
@@ -42,7 +50,7 @@ func Retry(ctx context.Context, maxAttempts int, fn func() error) error {
 			select {
 			case <-ctx.Done():
 				return fmt.Errorf("retry cancelled: %w", ctx.Err())
-			case <-time.After(delay):
+			case <-clk.After(delay):
 			}
 		}
 	}
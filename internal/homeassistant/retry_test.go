@@ -2,9 +2,19 @@ package homeassistant
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
 	"testing"
 	"time"
+
+	haclient "github.com/mkelcik/go-ha-client/v2"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
 )
 
 func TestRetry_SucceedsFirstAttempt(t *testing.T) {
@@ -106,6 +116,41 @@ func TestRetry_SingleAttempt(t *testing.T) {
 	}
 }
 
+func TestRetryWithClock_WaitsForBackoff(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	calls := make(chan int, 3)
+	done := make(chan error, 1)
+
+	n := 0
+	go func() {
+		done <- RetryWithClock(context.Background(), clk, 3, func() error {
+			n++
+			calls <- n
+			return errors.New("fail")
+		})
+	}()
+
+	<-calls
+	// The first backoff is at least 250ms: advancing less must not retry.
+	clk.BlockUntil(1)
+	clk.Advance(249 * time.Millisecond)
+	select {
+	case <-calls:
+		t.Fatal("retried before the backoff delay elapsed")
+	default:
+	}
+
+	clk.Advance(maxDelay)
+	<-calls
+	clk.BlockUntil(1)
+	clk.Advance(maxDelay)
+	<-calls
+
+	if err := <-done; err == nil {
+		t.Fatal("expected error after all attempts failed, got nil")
+	}
+}
+
 func TestBackoffDelay_Increases(t *testing.T) {
 	d0 := backoffDelay(0)
 	d1 := backoffDelay(1)
@@ -135,3 +180,47 @@ func TestBackoffDelay_Capped(t *testing.T) {
 		t.Errorf("delay = %v, expected >= maxDelay/2 (%v)", d, maxDelay/2)
 	}
 }
+
+// lostReplyREST is a RESTClient whose first add_item adds the item but
+// reports a timeout, as when HA's answer is lost.
+type lostReplyREST struct {
+	countingREST
+	titles []string
+}
+
+func (l *lostReplyREST) CallService(_ context.Context, _, service string, body io.Reader) error {
+	var data struct {
+		Item string `json:"item"`
+	}
+	if err := json.NewDecoder(body).Decode(&data); err != nil {
+		return err
+	}
+	l.titles = append(l.titles, data.Item)
+	if len(l.titles) == 1 {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+func (l *lostReplyREST) CallServiceWithResponse(context.Context, string, string, io.Reader) (haclient.ServiceCallResponse, error) {
+	var items []string
+	for i, title := range l.titles {
+		items = append(items, fmt.Sprintf(`{"uid":"%d","summary":%q,"status":"needs_action"}`, i, title))
+	}
+	return haclient.ServiceCallResponse{
+		ServiceResponse: map[string]json.RawMessage{
+			"todo.shopping": json.RawMessage(`{"items":[` + strings.Join(items, ",") + `]}`),
+		},
+	}, nil
+}
+
+func TestAddItem_RetryDoesNotAddTwice(t *testing.T) {
+	rest := &lostReplyREST{}
+	a := NewAdapterWithClient(rest, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := a.AddItem(context.Background(), "todo.shopping", &model.Item{Title: "Milk"}); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	if len(rest.titles) != 1 {
+		t.Errorf("add_item reached HA %d times, want once", len(rest.titles))
+	}
+}
@@ -0,0 +1,197 @@
+package homeassistant
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// DefaultDescriptionTemplate is the description template used unless
+// [WithDescriptionTemplate] sets another: the priority tag, then the notes,
+// as in "[High] 2% milk".
+const DefaultDescriptionTemplate = "{{priority}}{{notes}}"
+
+// defaultDescription is the parsed [DefaultDescriptionTemplate].
+var defaultDescription = mustParseDescriptionTemplate(DefaultDescriptionTemplate)
+
+// mustParseDescriptionTemplate is [ParseDescriptionTemplate] for templates
+// known to be valid.
+func mustParseDescriptionTemplate(text string) *DescriptionTemplate {
+	t, err := ParseDescriptionTemplate(text)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Description template placeholders.
+const (
+	placeholderNotes    = "{{notes}}"
+	placeholderPriority = "{{priority}}"
+	placeholderURL      = "{{url}}"
+	placeholderTags     = "{{tags}}"
+)
+
+// DescriptionTemplate renders the description of a [model.Item] into the one
+// HA todo description field and parses it back. A template is text with
+// placeholders, each used at most once:
+//
+//   - {{notes}}, required: the item's description, such as Reminders notes.
+//   - {{priority}}: a tag such as "[High] ", with its trailing space, or
+//     nothing for no priority.
+//   - {{url}}: the item's URL, or nothing.
+//   - {{tags}}: the hashtags in the notes, such as "#groceries #weekly", or
+//     nothing. They are shown for reading only: the notes keep them, and
+//     edits to them in HA are ignored.
+//
+// Text around placeholders is written as is. A description that does not
+// fit the template, such as one typed in HA without the text around
+// {{notes}}, is read as notes only. Create one with
+// [ParseDescriptionTemplate].
+type DescriptionTemplate struct {
+	text  string
+	parts []string       // literal text and placeholders, in order
+	re    *regexp.Regexp // matches a rendered description; see Parse
+}
+
+// ParseDescriptionTemplate checks text and returns its template.
+func ParseDescriptionTemplate(text string) (*DescriptionTemplate, error) {
+	t := &DescriptionTemplate{text: text}
+	var pattern strings.Builder
+	pattern.WriteString(`^`)
+	seen := make(map[string]bool)
+	for rest := text; rest != ""; {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			t.literal(&pattern, rest)
+			break
+		}
+		t.literal(&pattern, rest[:start])
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return nil, fmt.Errorf("description template %q: unclosed {{", text)
+		}
+		name := rest[start : start+end+2]
+		rest = rest[start+end+2:]
+		if seen[name] {
+			return nil, fmt.Errorf("description template %q: %s used twice", text, name)
+		}
+		seen[name] = true
+		switch name {
+		case placeholderNotes:
+			pattern.WriteString(`((?s:.*?))`)
+		case placeholderPriority:
+			pattern.WriteString(`((?:\[(?:High|Medium|Low)\] )?)`)
+		case placeholderURL:
+			pattern.WriteString(`(\S*)`)
+		case placeholderTags:
+			pattern.WriteString(`((?:#\S+(?: #\S+)*)?)`)
+		default:
+			return nil, fmt.Errorf("description template %q: unknown placeholder %s (known: %s, %s, %s, %s)",
+				text, name, placeholderNotes, placeholderPriority, placeholderURL, placeholderTags)
+		}
+		t.parts = append(t.parts, name)
+	}
+	if !seen[placeholderNotes] {
+		return nil, fmt.Errorf("description template %q: %s is required", text, placeholderNotes)
+	}
+	pattern.WriteString(`$`)
+	t.re = regexp.MustCompile(pattern.String())
+	return t, nil
+}
+
+// literal appends text to the template as literal text.
+func (t *DescriptionTemplate) literal(pattern *strings.Builder, text string) {
+	if text == "" {
+		return
+	}
+	t.parts = append(t.parts, text)
+	pattern.WriteString(regexp.QuoteMeta(text))
+}
+
+// String returns the template's text.
+func (t *DescriptionTemplate) String() string {
+	return t.text
+}
+
+// Fields returns the content fields the template keeps in the description.
+func (t *DescriptionTemplate) Fields() model.Fields {
+	f := model.FieldDescription
+	for _, p := range t.parts {
+		switch p {
+		case placeholderPriority:
+			f |= model.FieldPriority
+		case placeholderURL:
+			f |= model.FieldURL
+		}
+	}
+	return f
+}
+
+// Render returns the HA description of item. An item with nothing to
+// render gets an empty description, whatever text the template has.
+func (t *DescriptionTemplate) Render(item *model.Item) string {
+	var b strings.Builder
+	empty := true
+	for _, p := range t.parts {
+		var v string
+		switch p {
+		case placeholderNotes:
+			v = item.Description
+		case placeholderPriority:
+			v = model.EncodePriorityPrefix(item.Priority, "")
+		case placeholderURL:
+			v = item.URL
+		case placeholderTags:
+			v = strings.Join(hashtags(item.Description), " ")
+		default:
+			b.WriteString(p)
+			continue
+		}
+		empty = empty && v == ""
+		b.WriteString(v)
+	}
+	if empty {
+		return ""
+	}
+	return b.String()
+}
+
+// Parse reads the notes, priority, and URL back from an HA description
+// rendered by [DescriptionTemplate.Render].
+func (t *DescriptionTemplate) Parse(description string) (notes string, priority model.Priority, url string) {
+	m := t.re.FindStringSubmatch(description)
+	if m == nil {
+		return description, model.PriorityNone, ""
+	}
+	i := 1
+	for _, p := range t.parts {
+		switch p {
+		case placeholderNotes:
+			notes = m[i]
+		case placeholderPriority:
+			priority, _ = model.DecodePriorityPrefix(m[i])
+		case placeholderURL:
+			url = m[i]
+		case placeholderTags:
+		default:
+			continue
+		}
+		i++
+	}
+	return notes, priority, url
+}
+
+// hashtags returns the words of s that start with "#" and have more after it,
+// in order.
+func hashtags(s string) []string {
+	var tags []string
+	for _, w := range strings.Fields(s) {
+		if len(w) > 1 && w[0] == '#' {
+			tags = append(tags, w)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,71 @@
+package homeassistant
+
+import (
+	"testing"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+func TestDescriptionTemplate_DefaultMatchesPriorityPrefix(t *testing.T) {
+	for _, item := range []model.Item{
+		{Description: "2% milk", Priority: model.PriorityHigh},
+		{Description: "2% milk"},
+		{Priority: model.PriorityLow},
+		{},
+	} {
+		want := model.EncodePriorityPrefix(item.Priority, item.Description)
+		got := defaultDescription.Render(&item)
+		if got != want {
+			t.Errorf("Render(%+v) = %q, want %q", item, got, want)
+		}
+		notes, prio, _ := defaultDescription.Parse(got)
+		if notes != item.Description || prio != item.Priority {
+			t.Errorf("Parse(%q) = %q, %v; want %q, %v", got, notes, prio, item.Description, item.Priority)
+		}
+	}
+}
+
+func TestDescriptionTemplate_RoundTrip(t *testing.T) {
+	tmpl, err := ParseDescriptionTemplate("{{notes}}\n\n{{priority}}{{url}} {{tags}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tmpl.Fields(); got != model.FieldDescription|model.FieldPriority|model.FieldURL {
+		t.Errorf("Fields() = %b, want description, priority and URL", got)
+	}
+	item := model.Item{
+		Description: "Oat milk #groceries\n\nfrom the market #weekly",
+		Priority:    model.PriorityMedium,
+		URL:         "https://example.com/list",
+	}
+	desc := tmpl.Render(&item)
+	want := "Oat milk #groceries\n\nfrom the market #weekly\n\n[Medium] https://example.com/list #groceries #weekly"
+	if desc != want {
+		t.Fatalf("Render = %q, want %q", desc, want)
+	}
+	notes, prio, url := tmpl.Parse(desc)
+	if notes != item.Description || prio != item.Priority || url != item.URL {
+		t.Errorf("Parse = %q, %v, %q; want %q, %v, %q", notes, prio, url, item.Description, item.Priority, item.URL)
+	}
+
+	if got := tmpl.Render(&model.Item{}); got != "" {
+		t.Errorf("Render of an empty item = %q, want empty", got)
+	}
+	// A description typed in HA without the template's layout is all notes.
+	if notes, prio, url := tmpl.Parse("call back"); notes != "call back" || prio != model.PriorityNone || url != "" {
+		t.Errorf("Parse(free text) = %q, %v, %q; want it as notes", notes, prio, url)
+	}
+}
+
+func TestParseDescriptionTemplate_Errors(t *testing.T) {
+	for _, text := range []string{
+		"{{priority}}",
+		"{{notes}} {{notes}}",
+		"{{notes}} {{due}}",
+		"{{notes}} {{url",
+	} {
+		if _, err := ParseDescriptionTemplate(text); err == nil {
+			t.Errorf("ParseDescriptionTemplate(%q): want an error", text)
+		}
+	}
+}
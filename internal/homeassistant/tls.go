@@ -0,0 +1,102 @@
+package homeassistant
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	haclient "github.com/mkelcik/go-ha-client/v2"
+)
+
+// TLSFiles names the files securing the HA connection beyond the system
+// defaults. Empty fields keep the defaults.
+type TLSFiles struct {
+	// CAFile is a PEM bundle of CAs trusted for the HA server certificate,
+	// in addition to the system roots.
+	CAFile string
+
+	// CertFile and KeyFile are the PEM client certificate and key presented
+	// to servers requiring one, such as a reverse proxy doing mTLS.
+	CertFile string
+	KeyFile  string
+
+	// InsecureSkipVerify accepts any server certificate.
+	InsecureSkipVerify bool
+}
+
+// LoadTLSConfig reads the files named in f into a TLS configuration for
+// [WithTLS].
+func LoadTLSConfig(f TLSFiles) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: f.InsecureSkipVerify, //nolint:gosec // opted into in the config file
+	}
+	if f.CAFile != "" {
+		pem, err := os.ReadFile(f.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA file %q contains no PEM certificates", f.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if (f.CertFile == "") != (f.KeyFile == "") {
+		return nil, errors.New("a client certificate needs both a cert file and a key file")
+	}
+	if f.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(f.CertFile, f.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// WithTLS secures the REST and WebSocket connections with cfg instead of
+// the system defaults. Only [NewAdapter] makes connections of its own.
+func WithTLS(cfg *tls.Config) AdapterOption {
+	return func(a *Adapter) { a.tlsConfig = cfg }
+}
+
+// httpClient returns the HTTP client for REST calls, using tlsConfig if set.
+func httpClient(tlsConfig *tls.Config) *http.Client {
+	if tlsConfig == nil {
+		return &http.Client{}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}
+}
+
+// dialerMu serialises the swaps of websocket.DefaultDialer in newWSClient.
+var dialerMu sync.Mutex
+
+// newWSClient creates the WebSocket client of rest, dialling with tlsConfig
+// if set. go-ha-client offers no option for the dialer: it takes
+// websocket.DefaultDialer when the client is created, so that is replaced
+// for the duration of the call.
+func newWSClient(rest *haclient.Client, tlsConfig *tls.Config, opts ...haclient.WSOption) *haclient.WSClient {
+	if tlsConfig == nil {
+		return rest.WS(opts...)
+	}
+	dialerMu.Lock()
+	defer dialerMu.Unlock()
+	orig := websocket.DefaultDialer
+	defer func() { websocket.DefaultDialer = orig }()
+
+	dialer := *orig
+	dialer.TLSClientConfig = tlsConfig
+	websocket.DefaultDialer = &dialer
+	return rest.WS(opts...)
+}
@@ -0,0 +1,153 @@
+package homeassistant
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// writeClientCert writes a self-signed client certificate and its key to
+// dir and returns the certificate and the file paths.
+func writeClientCert(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "reminderrelay"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return cert, certFile, keyFile
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newMTLSServer starts an HA stand-in that requires a client certificate
+// signed by clientCA, answering pings and WebSocket authentication.
+func newMTLSServer(t *testing.T, clientCA *x509.Certificate) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"message":"API running."}`))
+	})
+	mux.HandleFunc("/api/websocket", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_ = conn.WriteJSON(map[string]string{"type": "auth_required"})
+		var auth map[string]string
+		if conn.ReadJSON(&auth) != nil {
+			return
+		}
+		_ = conn.WriteJSON(map[string]string{"type": "auth_ok"})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+
+	srv := httptest.NewUnstartedServer(mux)
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCA)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNewAdapter_TLS(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certFile, keyFile := writeClientCert(t, dir)
+	srv := newMTLSServer(t, clientCert)
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", srv.Certificate().Raw)
+
+	tlsConfig, err := LoadTLSConfig(TLSFiles{CAFile: caFile, CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("LoadTLSConfig: %v", err)
+	}
+	a, err := NewAdapter(srv.URL, "token", slog.New(slog.NewTextHandler(io.Discard, nil)), WithTLS(tlsConfig))
+	if err != nil {
+		t.Fatalf("NewAdapter: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := a.Ping(ctx); err != nil {
+		t.Errorf("Ping() = %v, want the client certificate accepted", err)
+	}
+	if err := a.Connect(ctx); err != nil {
+		t.Errorf("Connect() = %v, want the WebSocket dialled with the client certificate", err)
+	}
+	_ = a.Close()
+
+	if websocket.DefaultDialer.TLSClientConfig != nil {
+		t.Error("websocket.DefaultDialer left modified")
+	}
+}
+
+func TestLoadTLSConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	_, certFile, keyFile := writeClientCert(t, dir)
+	notPEM := filepath.Join(dir, "ca.txt")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, f := range map[string]TLSFiles{
+		"missing CA":       {CAFile: filepath.Join(dir, "missing.pem")},
+		"CA without PEM":   {CAFile: notPEM},
+		"cert without key": {CertFile: certFile},
+		"key without cert": {KeyFile: keyFile},
+		"mismatched files": {CertFile: keyFile, KeyFile: certFile},
+	} {
+		if _, err := LoadTLSConfig(f); err == nil {
+			t.Errorf("%s: LoadTLSConfig() = nil error", name)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package logfile writes the daemon's log to a file that is rotated once it
+// grows too large or too old, keeping a limited number of rotated files.
+//
+// launchd appends a job's stdout and stderr to the same files forever, so
+// the daemon installed by setup writes its log through a [Writer] instead and
+// leaves launchd's files for output that bypasses the logger, such as a
+// crash.
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+)
+
+// rotatedLayout suffixes rotated files so they sort chronologically.
+const rotatedLayout = "20060102-150405.000"
+
+// Options limits the size and age of a log file and how many rotated files
+// are kept. Zero fields do not limit.
+type Options struct {
+	MaxSize int64         // bytes after which the file is rotated
+	MaxAge  time.Duration // time after which the file is rotated
+	Keep    int           // rotated files kept; older ones are removed
+}
+
+// Writer appends to a log file, rotating it as its [Options] say. It is safe
+// for concurrent use.
+type Writer struct {
+	path  string
+	opts  Options
+	clock clock.Clock
+
+	mu      sync.Mutex
+	f       *os.File
+	size    int64
+	started time.Time // when the current file was opened or rotated in
+}
+
+// Open opens the log file at path for appending, creating it and its
+// directory if needed. The file's age counts from when it is opened, as the
+// file system does not record when it was created.
+func Open(path string, opts Options) (*Writer, error) {
+	return open(path, opts, clock.Real())
+}
+
+func open(path string, opts Options, clk clock.Clock) (*Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+	w := &Writer{path: path, opts: opts, clock: clk}
+	if err := w.openFile(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// openFile opens w.path for appending and takes over its size.
+func (w *Writer) openFile() error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	w.f, w.size, w.started = f, info.Size(), w.clock.Now()
+	return nil
+}
+
+// Write appends p to the file, rotating it first if p would take it past
+// MaxSize or the file is older than MaxAge. A line is never split across
+// files.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return 0, os.ErrClosed
+	}
+	if w.size > 0 && w.due(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// due reports whether the file must be rotated before n more bytes are
+// written to it.
+func (w *Writer) due(n int64) bool {
+	if w.opts.MaxSize > 0 && w.size+n > w.opts.MaxSize {
+		return true
+	}
+	return w.opts.MaxAge > 0 && w.clock.Now().Sub(w.started) >= w.opts.MaxAge
+}
+
+// rotate moves the current file aside, starts a new one, and removes the
+// rotated files beyond Keep.
+func (w *Writer) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return fmt.Errorf("closing log file: %w", err)
+	}
+	w.f = nil
+	rotated := w.path + "." + w.clock.Now().UTC().Format(rotatedLayout)
+	if err := os.Rename(w.path, rotated); err != nil {
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+	if err := w.openFile(); err != nil {
+		return err
+	}
+	if w.opts.Keep > 0 {
+		// A failed clean-up leaves extra files behind; logging goes on.
+		_, _ = Prune(w.path, w.opts.Keep)
+	}
+	return nil
+}
+
+// Close closes the file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
+
+// Rotated returns the rotated files of the log file at path, newest first.
+func Rotated(path string) ([]string, error) {
+	paths, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, fmt.Errorf("listing rotated log files: %w", err)
+	}
+	var rotated []string
+	for _, p := range paths {
+		suffix := p[len(path)+1:]
+		if _, err := time.Parse(rotatedLayout, suffix); err == nil {
+			rotated = append(rotated, p)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(rotated)))
+	return rotated, nil
+}
+
+// Prune removes all but the newest keep rotated files of the log file at
+// path and returns the paths it removed.
+func Prune(path string, keep int) ([]string, error) {
+	rotated, err := Rotated(path)
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for i := max(keep, 0); i < len(rotated); i++ {
+		if err := os.Remove(rotated[i]); err != nil {
+			return removed, fmt.Errorf("removing rotated log file: %w", err)
+		}
+		removed = append(removed, rotated[i])
+	}
+	return removed, nil
+}
@@ -0,0 +1,104 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestWriter_RotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "reminderrelay.log")
+	clk := clock.NewFake(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	w, err := open(path, Options{MaxSize: 10, Keep: 2}, clk)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() { _ = w.Close() }()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		clk.Advance(time.Second)
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write(%q): %v", line, err)
+		}
+	}
+
+	if got := readFile(t, path); got != "fourth\n" {
+		t.Errorf("current file = %q, want only the last line", got)
+	}
+	rotated, err := Rotated(path)
+	if err != nil {
+		t.Fatalf("Rotated: %v", err)
+	}
+	if len(rotated) != 2 {
+		t.Fatalf("rotated = %v, want the 2 kept", rotated)
+	}
+	if got := readFile(t, rotated[0]); got != "third\n" {
+		t.Errorf("newest rotated file = %q, want %q", got, "third\n")
+	}
+}
+
+func TestWriter_RotatesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reminderrelay.log")
+	clk := clock.NewFake(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	w, err := open(path, Options{MaxAge: 24 * time.Hour}, clk)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() { _ = w.Close() }()
+
+	_, _ = w.Write([]byte("monday\n"))
+	clk.Advance(23 * time.Hour)
+	_, _ = w.Write([]byte("still monday\n"))
+	clk.Advance(time.Hour)
+	_, _ = w.Write([]byte("tuesday\n"))
+
+	rotated, _ := Rotated(path)
+	if len(rotated) != 1 || readFile(t, rotated[0]) != "monday\nstill monday\n" {
+		t.Errorf("rotated = %v, want one file with the first day's lines", rotated)
+	}
+	if got := readFile(t, path); got != "tuesday\n" {
+		t.Errorf("current file = %q, want %q", got, "tuesday\n")
+	}
+}
+
+func TestPrune_KeepsNewestAndIgnoresOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "reminderrelay.log")
+	for _, name := range []string{
+		"reminderrelay.log",
+		"reminderrelay.log.20260101-090000.000",
+		"reminderrelay.log.20260102-090000.000",
+		"reminderrelay.log.20260103-090000.000",
+		"reminderrelay.log.bak",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := Prune(path, 1)
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if len(removed) != 2 || !strings.HasSuffix(removed[0], "20260102-090000.000") {
+		t.Errorf("removed = %v, want the two oldest rotated files", removed)
+	}
+	for _, name := range []string{"reminderrelay.log", "reminderrelay.log.20260103-090000.000", "reminderrelay.log.bak"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s was removed: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+//go:build darwin && cgo
+
+package menubar
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AppKit -framework Foundation
+#import <AppKit/AppKit.h>
+#include <stdlib.h>
+
+extern void rrMenuAction(int action);
+
+@interface RRMenuTarget : NSObject
+- (void)choose:(NSMenuItem *)sender;
+@end
+
+@implementation RRMenuTarget
+- (void)choose:(NSMenuItem *)sender {
+	rrMenuAction((int)sender.tag);
+}
+@end
+
+static NSStatusItem *rrItem;
+static RRMenuTarget *rrTarget;
+
+// rrRun creates the status item and runs the app; it returns only if the
+// app stops without terminating the process.
+static void rrRun(void) {
+	@autoreleasepool {
+		[NSApplication sharedApplication];
+		// No Dock icon and no main menu: the status item is all there is.
+		[NSApp setActivationPolicy:NSApplicationActivationPolicyAccessory];
+		rrTarget = [RRMenuTarget new];
+		rrItem = [[NSStatusBar systemStatusBar] statusItemWithLength:NSVariableStatusItemLength];
+		rrItem.button.title = @"↻";
+		rrItem.menu = [NSMenu new];
+		[NSApp run];
+	}
+}
+
+// rrShow replaces the title and menu of the status item. labels and
+// actions hold n entries; separator marks separator lines and 0 disabled
+// ones. The menu is rebuilt on the main thread.
+static void rrShow(const char *title, const char **labels, const int *actions, int n, int separator) {
+	NSString *t = [NSString stringWithUTF8String:title];
+	NSMutableArray<NSString *> *ls = [NSMutableArray arrayWithCapacity:n];
+	NSMutableArray<NSNumber *> *as = [NSMutableArray arrayWithCapacity:n];
+	for (int i = 0; i < n; i++) {
+		[ls addObject:[NSString stringWithUTF8String:labels[i]]];
+		[as addObject:@(actions[i])];
+	}
+	dispatch_async(dispatch_get_main_queue(), ^{
+		if (rrItem == nil) {
+			return;
+		}
+		rrItem.button.title = t;
+		NSMenu *menu = [NSMenu new];
+		menu.autoenablesItems = NO;
+		for (NSUInteger i = 0; i < ls.count; i++) {
+			int action = as[i].intValue;
+			if (action == separator) {
+				[menu addItem:[NSMenuItem separatorItem]];
+				continue;
+			}
+			NSMenuItem *item = [[NSMenuItem alloc] initWithTitle:ls[i] action:@selector(choose:) keyEquivalent:@""];
+			item.target = rrTarget;
+			item.tag = action;
+			item.enabled = action != 0;
+			[menu addItem:item];
+		}
+		rrItem.menu = menu;
+	});
+}
+
+static void rrQuit(void) {
+	dispatch_async(dispatch_get_main_queue(), ^{
+		[NSApp terminate:nil];
+	});
+}
+*/
+import "C"
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// supported is true: AppKit is linked in.
+const supported = true
+
+// AppKit must run on the main thread, which the main goroutine is locked to
+// here, before main starts.
+func init() { runtime.LockOSThread() }
+
+// onAction receives the actions of the menu items chosen; set by runApp.
+var onAction func(Action)
+
+// runApp shows the status item and runs AppKit's event loop, calling act
+// for every menu item chosen, until the app quits.
+func runApp(act func(Action)) error {
+	onAction = act
+	C.rrRun()
+	return nil
+}
+
+// showView shows v in the status item.
+func showView(v View) {
+	title := C.CString(v.Title)
+	defer C.free(unsafe.Pointer(title))
+	// One spare entry keeps the allocations non-empty.
+	n := len(v.Items)
+	labels := unsafe.Slice((**C.char)(C.malloc(C.size_t(n+1)*C.size_t(unsafe.Sizeof((*C.char)(nil))))), n+1)
+	defer C.free(unsafe.Pointer(&labels[0]))
+	actions := unsafe.Slice((*C.int)(C.malloc(C.size_t(n+1)*C.size_t(unsafe.Sizeof(C.int(0))))), n+1)
+	defer C.free(unsafe.Pointer(&actions[0]))
+	for i, it := range v.Items {
+		labels[i] = C.CString(it.Label)
+		defer C.free(unsafe.Pointer(labels[i]))
+		actions[i] = C.int(it.Action)
+	}
+	C.rrShow(title, &labels[0], &actions[0], C.int(n), C.int(ActionSeparator))
+}
+
+// quitApp terminates the app, and with it the process.
+func quitApp() { C.rrQuit() }
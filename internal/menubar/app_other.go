@@ -0,0 +1,10 @@
+//go:build !darwin || !cgo
+
+package menubar
+
+// supported is false: there is no menu bar without AppKit.
+const supported = false
+
+func runApp(func(Action)) error { return ErrUnsupported }
+func showView(View)             {}
+func quitApp()                  {}
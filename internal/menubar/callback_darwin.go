@@ -0,0 +1,15 @@
+//go:build darwin && cgo
+
+package menubar
+
+// The exported callback lives apart from app_darwin.go: a file with
+// //export may only declare, not define, C functions in its preamble.
+
+import "C"
+
+//export rrMenuAction
+func rrMenuAction(action C.int) {
+	if onAction != nil {
+		onAction(Action(action))
+	}
+}
@@ -0,0 +1,347 @@
+// Package menubar shows the running daemon in the macOS menu bar: when it
+// last synced, whether the last pass had errors, and actions to sync now or
+// pause. It is a separate process that polls the daemon's control socket,
+// so a crash or restart of either does not take down the other.
+//
+// What the menu shows is computed by [Render], independent of AppKit; the
+// status item itself is only available in darwin builds with cgo.
+package menubar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/control"
+)
+
+// DefaultInterval is how often the daemon's status is polled, unless
+// configured otherwise.
+const DefaultInterval = 5 * time.Second
+
+// ErrUnsupported is returned by [Menu.Run] in builds without AppKit.
+var ErrUnsupported = errors.New("the menu bar is only available on macOS")
+
+// Action is what a menu item does when chosen.
+type Action int
+
+// Menu actions.
+const (
+	// ActionNone marks an informational item, shown disabled.
+	ActionNone Action = iota
+	ActionSync
+	ActionPause
+	ActionResume
+	ActionOpenLog
+	ActionQuit
+	// ActionSeparator marks a separator line.
+	ActionSeparator
+)
+
+// Item is one line of the menu.
+type Item struct {
+	Label  string
+	Action Action
+}
+
+// View is what the status item shows: its title in the menu bar and the
+// lines of its menu.
+type View struct {
+	Title string
+	Items []Item
+}
+
+// Titles of the status item. The badge after the icon tells at a glance
+// that something needs a look.
+const (
+	titleOK         = "↻"
+	titleSyncing    = "↻…"
+	titlePaused     = "↻ ⏸"
+	titleError      = "↻ ⚠"
+	titleNotRunning = "↻ ✕"
+)
+
+// maxErrorLen is how much of an error message fits in a menu item.
+const maxErrorLen = 70
+
+// Render returns the view of the daemon status st at now. A nil st means no
+// daemon answers; syncing is set while a pass requested from the menu runs,
+// and syncErr is the error of the last one, if it failed.
+func Render(st *control.Status, now time.Time, syncing bool, syncErr string) View {
+	sep := Item{Action: ActionSeparator}
+	if st == nil {
+		return View{Title: titleNotRunning, Items: []Item{
+			{Label: "ReminderRelay is not running"},
+			{Label: "Start it with 'reminderrelay setup' or 'reminderrelay daemon'"},
+			sep,
+			{Label: "Open Log", Action: ActionOpenLog},
+			sep,
+			{Label: "Quit Menu Bar", Action: ActionQuit},
+		}}
+	}
+
+	state := "running"
+	if st.Paused {
+		state = "paused"
+	}
+	items := []Item{{Label: fmt.Sprintf("ReminderRelay %s — %s", st.Version, state)}}
+	switch {
+	case syncing:
+		items = append(items, Item{Label: "Syncing…"})
+	case st.LastPassAt.IsZero():
+		items = append(items, Item{Label: "Last sync: not yet"})
+	default:
+		items = append(items, Item{Label: "Last sync: " + ago(now.Sub(st.LastPassAt))})
+	}
+
+	var problems []string
+	if syncErr != "" {
+		problems = append(problems, "Sync failed: "+syncErr)
+	}
+	if st.LastError != "" {
+		problems = append(problems, "Error: "+st.LastError)
+	}
+	if n := st.LastStats.Errors; n > 0 {
+		problems = append(problems, fmt.Sprintf("%d item error(s) in the last pass", n))
+	}
+	for _, list := range slices.Sorted(maps.Keys(st.LastStats.ListErrors)) {
+		problems = append(problems, fmt.Sprintf("%s: %s", list, st.LastStats.ListErrors[list]))
+	}
+	if a := st.RemindersAccess; a != "" && a != "granted" {
+		problems = append(problems, "Reminders access: "+a)
+	}
+	for _, p := range problems {
+		items = append(items, Item{Label: truncate(p, maxErrorLen)})
+	}
+
+	items = append(items, sep)
+	if syncing {
+		items = append(items, Item{Label: "Sync Now"})
+	} else {
+		items = append(items, Item{Label: "Sync Now", Action: ActionSync})
+	}
+	if st.Paused {
+		items = append(items, Item{Label: "Resume Syncing", Action: ActionResume})
+	} else {
+		items = append(items, Item{Label: "Pause Syncing", Action: ActionPause})
+	}
+	items = append(items,
+		Item{Label: "Open Log", Action: ActionOpenLog},
+		sep,
+		Item{Label: "Quit Menu Bar", Action: ActionQuit},
+	)
+
+	title := titleOK
+	switch {
+	case syncing:
+		title = titleSyncing
+	case len(problems) > 0:
+		title = titleError
+	case st.Paused:
+		title = titlePaused
+	}
+	return View{Title: title, Items: items}
+}
+
+// ago describes a duration in the past coarsely, as in "3 min ago".
+func ago(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%d min ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d h ago", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%d days ago", int(d/(24*time.Hour)))
+	}
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+// Only the first line of s is kept.
+func truncate(s string, n int) string {
+	s, _, _ = strings.Cut(s, "\n")
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// Option configures a [Menu].
+type Option func(*Menu)
+
+// WithInterval sets how often the daemon's status is polled. The default
+// is [DefaultInterval].
+func WithInterval(d time.Duration) Option {
+	return func(m *Menu) { m.interval = d }
+}
+
+// WithClock sets the clock used for polling and to tell how long ago the
+// last sync was.
+func WithClock(c clock.Clock) Option {
+	return func(m *Menu) { m.clock = c }
+}
+
+// Menu is the menu bar companion of a daemon. Create one with [New].
+type Menu struct {
+	client   *control.Client
+	logFile  string
+	interval time.Duration
+	clock    clock.Clock
+	log      *slog.Logger
+
+	refresh chan struct{}
+
+	mu        sync.Mutex
+	syncing   bool
+	syncErr   string
+	syncErrAt time.Time // when the sync that failed with syncErr returned
+}
+
+// New returns a Menu showing the daemon client talks to. Open Log opens
+// logFile.
+func New(client *control.Client, logFile string, logger *slog.Logger, opts ...Option) *Menu {
+	m := &Menu{
+		client:   client,
+		logFile:  logFile,
+		interval: DefaultInterval,
+		clock:    clock.Real(),
+		log:      logger,
+		refresh:  make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Run shows the status item and keeps it up to date until the user quits
+// or ctx is cancelled. It must be called from the main goroutine, which
+// AppKit needs, and returns [ErrUnsupported] in builds without AppKit.
+func (m *Menu) Run(ctx context.Context) error {
+	if !supported {
+		return ErrUnsupported
+	}
+	go m.poll(ctx)
+	return runApp(m.act)
+}
+
+// poll refreshes the shown status every interval, and at once when an
+// action asks for it, until ctx is cancelled, which quits the app.
+func (m *Menu) poll(ctx context.Context) {
+	ticker := m.clock.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.update(ctx)
+		select {
+		case <-ctx.Done():
+			quitApp()
+			return
+		case <-ticker.C():
+		case <-m.refresh:
+		}
+	}
+}
+
+// update fetches the daemon's status and shows it.
+func (m *Menu) update(ctx context.Context) {
+	reqCtx, cancel := context.WithTimeout(ctx, m.interval)
+	st, err := m.client.Status(reqCtx)
+	cancel()
+	if err != nil && !errors.Is(err, control.ErrNotRunning) {
+		m.log.Debug("fetching daemon status failed", "error", err)
+	}
+
+	showView(m.view(st))
+}
+
+// view returns the view of the daemon status st. The error of a failed sync
+// from the menu is shown until the daemon finishes a later pass.
+func (m *Menu) view(st *control.Status) View {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.syncErr != "" && st != nil && st.LastPassAt.After(m.syncErrAt) {
+		m.syncErr = ""
+	}
+	return Render(st, m.clock.Now(), m.syncing, m.syncErr)
+}
+
+// requestRefresh makes the poll loop update the view without waiting for
+// the next tick.
+func (m *Menu) requestRefresh() {
+	select {
+	case m.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// act performs the action of the menu item chosen. It is called on the
+// main thread, so anything slow runs in a goroutine.
+func (m *Menu) act(a Action) {
+	switch a {
+	case ActionSync:
+		m.mu.Lock()
+		if m.syncing {
+			m.mu.Unlock()
+			return
+		}
+		m.syncing = true
+		m.mu.Unlock()
+		m.requestRefresh()
+		go m.sync()
+	case ActionPause, ActionResume:
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), m.interval)
+			defer cancel()
+			var err error
+			if a == ActionPause {
+				err = m.client.Pause(ctx)
+			} else {
+				err = m.client.Resume(ctx)
+			}
+			if err != nil {
+				m.log.Warn("changing the daemon's paused state failed", "error", err)
+			}
+			m.requestRefresh()
+		}()
+	case ActionOpenLog:
+		go func() {
+			//nolint:gosec // the daemon's own log file
+			if err := exec.Command("open", m.logFile).Run(); err != nil {
+				m.log.Warn("opening log failed", "file", m.logFile, "error", err)
+			}
+		}()
+	case ActionQuit:
+		quitApp()
+	}
+}
+
+// sync runs a pass in the daemon and records whether it failed.
+func (m *Menu) sync() {
+	res, err := m.client.Sync(context.Background())
+	syncErr := ""
+	switch {
+	case err != nil:
+		syncErr = err.Error()
+	case res.Error != "":
+		syncErr = res.Error
+	}
+	if syncErr != "" {
+		m.log.Warn("sync requested from the menu bar failed", "error", syncErr)
+	}
+	m.mu.Lock()
+	m.syncing = false
+	m.syncErr = syncErr
+	m.syncErrAt = m.clock.Now()
+	m.mu.Unlock()
+	m.requestRefresh()
+}
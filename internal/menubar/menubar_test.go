@@ -0,0 +1,172 @@
+package menubar
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/control"
+)
+
+// labels returns the labels of the items of v with action a.
+func labels(v View, a Action) []string {
+	var out []string
+	for _, it := range v.Items {
+		if it.Action == a {
+			out = append(out, it.Label)
+		}
+	}
+	return out
+}
+
+func TestRender(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	healthy := control.Status{Version: "1.2.0", Passes: 4, LastPassAt: now.Add(-3 * time.Minute), RemindersAccess: "granted"}
+
+	tests := []struct {
+		name      string
+		st        *control.Status
+		syncing   bool
+		syncErr   string
+		wantTitle string
+		wantInfo  []string
+		wantAct   []Action
+	}{
+		{
+			name:      "not running",
+			wantTitle: titleNotRunning,
+			wantInfo:  []string{"ReminderRelay is not running"},
+			wantAct:   []Action{ActionOpenLog, ActionQuit},
+		},
+		{
+			name:      "healthy",
+			st:        &healthy,
+			wantTitle: titleOK,
+			wantInfo:  []string{"ReminderRelay 1.2.0 — running", "Last sync: 3 min ago"},
+			wantAct:   []Action{ActionSync, ActionPause, ActionOpenLog, ActionQuit},
+		},
+		{
+			name:      "paused",
+			st:        &control.Status{Version: "1.2.0", Paused: true},
+			wantTitle: titlePaused,
+			wantInfo:  []string{"ReminderRelay 1.2.0 — paused", "Last sync: not yet"},
+			wantAct:   []Action{ActionSync, ActionResume, ActionOpenLog, ActionQuit},
+		},
+		{
+			name: "errors",
+			st: &control.Status{
+				Version:         "1.2.0",
+				LastPassAt:      now.Add(-2 * time.Hour),
+				LastError:       "HA unreachable\nwith details",
+				LastStats:       control.Stats{Errors: 2, ListErrors: map[string]string{"Work": "boom", "Groceries": "bang"}},
+				RemindersAccess: "denied",
+			},
+			wantTitle: titleError,
+			wantInfo: []string{
+				"ReminderRelay 1.2.0 — running", "Last sync: 2 h ago",
+				"Error: HA unreachable", "2 item error(s) in the last pass",
+				"Groceries: bang", "Work: boom", "Reminders access: denied",
+			},
+			wantAct: []Action{ActionSync, ActionPause, ActionOpenLog, ActionQuit},
+		},
+		{
+			name:      "syncing",
+			st:        &healthy,
+			syncing:   true,
+			wantTitle: titleSyncing,
+			wantInfo:  []string{"ReminderRelay 1.2.0 — running", "Syncing…", "Sync Now"},
+			wantAct:   []Action{ActionPause, ActionOpenLog, ActionQuit},
+		},
+		{
+			name:      "sync from menu failed",
+			st:        &healthy,
+			syncErr:   "daemon is not running",
+			wantTitle: titleError,
+			wantInfo:  []string{"ReminderRelay 1.2.0 — running", "Last sync: 3 min ago", "Sync failed: daemon is not running"},
+			wantAct:   []Action{ActionSync, ActionPause, ActionOpenLog, ActionQuit},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := Render(tt.st, now, tt.syncing, tt.syncErr)
+			if v.Title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", v.Title, tt.wantTitle)
+			}
+			info := labels(v, ActionNone)
+			if tt.st == nil {
+				info = info[:1]
+			}
+			if !slices.Equal(info, tt.wantInfo) {
+				t.Errorf("info items = %q, want %q", info, tt.wantInfo)
+			}
+			var acts []Action
+			for _, it := range v.Items {
+				if it.Action != ActionNone && it.Action != ActionSeparator {
+					acts = append(acts, it.Action)
+				}
+			}
+			if !slices.Equal(acts, tt.wantAct) {
+				t.Errorf("actions = %v, want %v", acts, tt.wantAct)
+			}
+		})
+	}
+}
+
+func TestMenu_SyncErrorClearedByLaterPass(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	m := New(nil, "", slog.New(slog.DiscardHandler), WithClock(clk))
+	m.syncErr, m.syncErrAt = "HA unreachable", clk.Now()
+
+	// The failed pass itself, and a daemon that has not passed since.
+	st := &control.Status{Version: "1.2.0", LastPassAt: clk.Now().Add(-time.Second)}
+	if v := m.view(st); v.Title != titleError {
+		t.Errorf("title before a later pass = %q, want %q", v.Title, titleError)
+	}
+	clk.Advance(time.Minute)
+	if v := m.view(nil); v.Title != titleNotRunning {
+		t.Errorf("title while not running = %q, want %q", v.Title, titleNotRunning)
+	}
+	if m.syncErr == "" {
+		t.Fatal("sync error cleared without a later pass")
+	}
+
+	st.LastPassAt = clk.Now()
+	v := m.view(st)
+	if v.Title != titleOK {
+		t.Errorf("title after a later pass = %q, want %q", v.Title, titleOK)
+	}
+	for _, l := range labels(v, ActionNone) {
+		if strings.HasPrefix(l, "Sync failed") {
+			t.Errorf("item %q still shown after a later pass", l)
+		}
+	}
+}
+
+func TestRender_TruncatesLongErrors(t *testing.T) {
+	st := &control.Status{LastError: strings.Repeat("x", 200)}
+	for _, l := range labels(Render(st, time.Now(), false, ""), ActionNone) {
+		if n := len([]rune(l)); n > maxErrorLen {
+			t.Errorf("item %q has %d runes, want at most %d", l, n, maxErrorLen)
+		}
+	}
+}
+
+func TestAgo(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "just now"},
+		{59 * time.Minute, "59 min ago"},
+		{5 * time.Hour, "5 h ago"},
+		{50 * time.Hour, "2 days ago"},
+	}
+	for _, tt := range tests {
+		if got := ago(tt.d); got != tt.want {
+			t.Errorf("ago(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package model
+
+import "errors"
+
+// ErrUnauthorized is returned (wrapped) by adapters when the remote side
+// rejects the configured credentials, e.g. an expired or revoked HA token.
+// Callers test for it with [errors.Is].
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrAccessDenied is returned (wrapped) by the Reminders adapter when macOS
+// denies the process access to Reminders, e.g. after it was revoked in
+// System Settings. Callers test for it with [errors.Is].
+var ErrAccessDenied = errors.New("access to Reminders denied")
+
+// ErrListUnavailable is returned (wrapped) by backends when a list exists
+// but cannot be read for the time being, e.g. an HA todo entity whose
+// integration is reloading. Callers test for it with [errors.Is] and skip
+// the list until it is back, rather than treating its items as gone.
+var ErrListUnavailable = errors.New("list unavailable")
@@ -71,12 +71,32 @@ type Item struct {
 	// DueDate is when the task is due. Nil means no due date.
 	DueDate *time.Time
 
+	// AlertAt is when the task's earliest alert fires (a Reminders "remind
+	// me" alarm). Nil means no alert.
+	AlertAt *time.Time
+
 	// Priority is the normalised priority level.
 	Priority Priority
 
+	// URL is the web address attached to the task, such as the one Reminders
+	// shows under a reminder's title. Empty means none.
+	URL string
+
+	// RawPriority is the exact EventKit priority, 0–9, of an item read from
+	// Reminders, or one to write back in place of Priority's canonical
+	// value when it falls in that level. Zero elsewhere. It is not part of
+	// [Item.ContentHash], as Home Assistant only knows the levels.
+	RawPriority int
+
 	// Completed is true when the task has been marked as done.
 	Completed bool
 
+	// CompletedAt is when the task was marked as done, for completed items
+	// whose backend reports it. It is not part of [Item.ContentHash]: it
+	// changes with Completed, and a backend that cannot store it would
+	// otherwise always look changed.
+	CompletedAt *time.Time
+
 	// ModifiedAt is the last modification time reported by the source adapter.
 	// Used for last-write-wins conflict resolution.
 	ModifiedAt time.Time
@@ -84,28 +104,108 @@ type Item struct {
 	// ListName is the Apple Reminders list this item belongs to.
 	// Used to look up the corresponding HA entity in the config mapping.
 	ListName string
+
+	// RawTitle is the title as the source adapter stores it, set only when
+	// the sync engine normalised Title. Backends that identify items by
+	// title use it through [Item.StoredTitle].
+	RawTitle string
+}
+
+// StoredTitle returns the title as the item's backend stores it: RawTitle
+// when Title was normalised, Title otherwise.
+func (i *Item) StoredTitle() string {
+	if i.RawTitle != "" {
+		return i.RawTitle
+	}
+	return i.Title
 }
 
 // ContentHash returns a deterministic SHA-256 hex digest of the fields that
-// matter for change detection: title, description, due date, priority, and
-// completed status. ModifiedAt is intentionally excluded — it changes on every
-// save and is only used for conflict resolution, not change detection.
+// matter for change detection: title, description, due date, priority,
+// completed status, alert time, and URL. ModifiedAt is intentionally excluded
+// — it changes on every save and is only used for conflict resolution, not
+// change detection. Items without an alert or URL hash as they did before
+// those were tracked.
 func (i *Item) ContentHash() string {
 	h := sha256.New()
 	h.Write([]byte(i.Title))
 	h.Write([]byte("|"))
 	h.Write([]byte(i.Description))
 	h.Write([]byte("|"))
-	if i.DueDate != nil {
-		h.Write([]byte(i.DueDate.UTC().Format(time.RFC3339)))
-	}
+	h.Write([]byte(dueKey(i.DueDate)))
 	h.Write([]byte("|"))
 	_, _ = fmt.Fprintf(h, "%d", i.Priority)
 	h.Write([]byte("|"))
 	_, _ = fmt.Fprintf(h, "%t", i.Completed)
+	if i.AlertAt != nil {
+		h.Write([]byte("|"))
+		h.Write([]byte(dueKey(i.AlertAt)))
+	}
+	if i.URL != "" {
+		h.Write([]byte("|url="))
+		h.Write([]byte(i.URL))
+	}
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// Fields is a set of the content fields covered by [Item.ContentHash].
+type Fields uint8
+
+// Content fields of an [Item].
+const (
+	FieldTitle Fields = 1 << iota
+	FieldDescription
+	FieldDueDate
+	FieldPriority
+	FieldCompleted
+	FieldAlert
+	FieldURL
+
+	// AllFields is the set of every content field.
+	AllFields = FieldTitle | FieldDescription | FieldDueDate | FieldPriority | FieldCompleted | FieldAlert | FieldURL
+)
+
+// Has reports whether f contains any field in g.
+func (f Fields) Has(g Fields) bool {
+	return f&g != 0
+}
+
+// ChangedFields returns the content fields whose values differ between a and
+// b, compared at the precision used by [Item.ContentHash].
+func ChangedFields(a, b *Item) Fields {
+	var f Fields
+	if a.Title != b.Title {
+		f |= FieldTitle
+	}
+	if a.Description != b.Description {
+		f |= FieldDescription
+	}
+	if dueKey(a.DueDate) != dueKey(b.DueDate) {
+		f |= FieldDueDate
+	}
+	if a.Priority != b.Priority {
+		f |= FieldPriority
+	}
+	if a.Completed != b.Completed {
+		f |= FieldCompleted
+	}
+	if dueKey(a.AlertAt) != dueKey(b.AlertAt) {
+		f |= FieldAlert
+	}
+	if a.URL != b.URL {
+		f |= FieldURL
+	}
+	return f
+}
+
+// dueKey renders a due date or alert time the way [Item.ContentHash] hashes it.
+func dueKey(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
 // --- Priority prefix encoding for Home Assistant descriptions ----------------
 
 const (
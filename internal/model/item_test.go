@@ -125,6 +125,35 @@ func TestContentHash_NilDueDate(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// ChangedFields
+// ---------------------------------------------------------------------------
+
+func TestChangedFields(t *testing.T) {
+	due := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	sameDue := due.In(time.FixedZone("CET", 3600)).Add(300 * time.Millisecond)
+	base := &Item{Title: "Task", Description: "notes", DueDate: &due}
+
+	tests := []struct {
+		name  string
+		other Item
+		want  Fields
+	}{
+		{"identical", *base, 0},
+		{"completed only", Item{Title: "Task", Description: "notes", DueDate: &due, Completed: true}, FieldCompleted},
+		{"due in other zone, sub-second", Item{Title: "Task", Description: "notes", DueDate: &sameDue}, 0},
+		{"due cleared, title changed", Item{Title: "Renamed", Description: "notes"}, FieldTitle | FieldDueDate},
+		{"priority", Item{Title: "Task", Description: "notes", DueDate: &due, Priority: PriorityHigh}, FieldPriority},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChangedFields(base, &tt.other); got != tt.want {
+				t.Errorf("ChangedFields = %05b, want %05b", got, tt.want)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Priority prefix encoding / decoding
 // ---------------------------------------------------------------------------
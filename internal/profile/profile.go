@@ -0,0 +1,94 @@
+// Package profile names the relay a command acts on. Every profile has its
+// own config file, state directory, logs, and launchd job, so that one Mac
+// can run several relays side by side, such as one for a personal and one
+// for a work Home Assistant.
+//
+// The default profile has the empty name and uses the locations of installs
+// that predate profiles. Every other profile lives in a subdirectory of
+// them named after it, e.g. ~/.config/reminderrelay/work/config.yaml.
+package profile
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// reserved names collide with subdirectories of the default profile.
+var reserved = map[string]bool{"backups": true}
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// name is the profile of this process, set once at startup by [Set].
+var name string
+
+// Valid reports whether s may name a profile: up to 32 lower-case letters,
+// digits, dashes and underscores, starting with a letter or digit.
+func Valid(s string) bool {
+	return validName.MatchString(s) && !reserved[s]
+}
+
+// Set selects the profile named s for the rest of the process. The empty
+// name selects the default profile.
+func Set(s string) error {
+	if s != "" && !Valid(s) {
+		return fmt.Errorf("invalid profile name %q: use up to 32 lower-case letters, digits, - and _", s)
+	}
+	name = s
+	return nil
+}
+
+// Name returns the selected profile, empty for the default one.
+func Name() string {
+	return name
+}
+
+// Flag returns the command-line flag selecting the selected profile,
+// preceded by a space, for commands printed as hints; empty for the default
+// profile.
+func Flag() string {
+	if name == "" {
+		return ""
+	}
+	return " --profile " + name
+}
+
+// Dir returns the directory of the selected profile among the per-profile
+// directories under base, base itself for the default profile.
+func Dir(base string) string {
+	if name == "" {
+		return base
+	}
+	return filepath.Join(base, name)
+}
+
+// FromArgs selects the profile named by a --profile flag anywhere in args,
+// before any "--", and returns args without it. Without the flag, the
+// default profile stays selected.
+func FromArgs(args []string) ([]string, error) {
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		flagName, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || flagName != "profile" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, fmt.Errorf("flag --profile needs a profile name")
+			}
+			i++
+			value = args[i]
+		}
+		if err := Set(value); err != nil {
+			return nil, err
+		}
+	}
+	return rest, nil
+}
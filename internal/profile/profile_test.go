@@ -0,0 +1,50 @@
+package profile
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFromArgs(t *testing.T) {
+	tests := []struct {
+		args        []string
+		wantRest    []string
+		wantProfile string
+		wantErr     bool
+	}{
+		{[]string{"daemon", "--verbose"}, []string{"daemon", "--verbose"}, "", false},
+		{[]string{"--profile", "work", "daemon"}, []string{"daemon"}, "work", false},
+		{[]string{"status", "-profile=home"}, []string{"status"}, "home", false},
+		{[]string{"pin", "--", "--profile", "x"}, []string{"pin", "--", "--profile", "x"}, "", false},
+		{[]string{"daemon", "--profile"}, nil, "", true},
+		{[]string{"--profile", "Work!", "daemon"}, nil, "", true},
+		{[]string{"--profile", "backups", "daemon"}, nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Cleanup(func() { name = "" })
+		name = ""
+		rest, err := FromArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("FromArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (!slices.Equal(rest, tt.wantRest) || Name() != tt.wantProfile) {
+			t.Errorf("FromArgs(%q) = %q with profile %q, want %q with %q", tt.args, rest, Name(), tt.wantRest, tt.wantProfile)
+		}
+	}
+}
+
+func TestDir(t *testing.T) {
+	t.Cleanup(func() { name = "" })
+	base := filepath.Join("home", ".config", "reminderrelay")
+	if got := Dir(base); got != base {
+		t.Errorf("Dir() = %q for the default profile, want %q", got, base)
+	}
+	if err := Set("work"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Dir(base), filepath.Join(base, "work"); got != want {
+		t.Errorf("Dir() = %q, want %q", got, want)
+	}
+}
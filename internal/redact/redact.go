@@ -0,0 +1,160 @@
+// Package redact scrubs secrets — the Home Assistant token, telemetry
+// authentication headers, CalDAV passwords — from log output and error
+// messages, so a secret that ends up in a wrapped error from the HTTP layer
+// or in a log attribute is never written out.
+package redact
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// Placeholder replaces every secret.
+const Placeholder = "[REDACTED]"
+
+// minSecretLen is the length below which a value is not treated as a secret:
+// scrubbing every occurrence of a very short string would mangle ordinary
+// text without protecting anything.
+const minSecretLen = 6
+
+// sensitiveKeys are parts of attribute keys whose values are always
+// replaced, whether or not they hold a known secret.
+var sensitiveKeys = []string{"token", "authorization", "password", "secret"}
+
+// Redactor replaces known secrets. The zero value and a nil *Redactor
+// replace nothing but still hide the values of sensitive attribute keys.
+type Redactor struct {
+	secrets []string // longest first, so a secret containing another goes whole
+}
+
+// New returns a Redactor for secrets. Empty and very short values are
+// ignored.
+func New(secrets ...string) *Redactor {
+	r := &Redactor{}
+	seen := make(map[string]bool)
+	for _, s := range secrets {
+		s = strings.TrimSpace(s)
+		if len(s) < minSecretLen || seen[s] {
+			continue
+		}
+		seen[s] = true
+		r.secrets = append(r.secrets, s)
+	}
+	sort.Slice(r.secrets, func(i, j int) bool { return len(r.secrets[i]) > len(r.secrets[j]) })
+	return r
+}
+
+// String returns s with every secret replaced by [Placeholder].
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Placeholder)
+	}
+	return s
+}
+
+// Error returns err with secrets scrubbed from its message. The original
+// error stays reachable through [errors.Unwrap], so [errors.Is] and
+// [errors.As] keep working; only its text changes. Errors without a secret
+// are returned as they are.
+func (r *Redactor) Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if scrubbed := r.String(msg); scrubbed != msg {
+		return &redactedError{msg: scrubbed, err: err}
+	}
+	return err
+}
+
+// redactedError is an error whose message had secrets scrubbed.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// ReplaceAttr scrubs secrets from a log attribute. It fits
+// [slog.HandlerOptions.ReplaceAttr] and is applied to every attribute by
+// [Redactor.Handler]. Values of keys such as "token" or "authorization" are
+// replaced entirely.
+func (r *Redactor) ReplaceAttr(_ []string, a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if isSensitive(a.Key) && a.Value.Kind() != slog.KindGroup {
+		return slog.String(a.Key, Placeholder)
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(r.String(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		scrubbed := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			scrubbed[i] = r.ReplaceAttr(nil, ga)
+		}
+		a.Value = slog.GroupValue(scrubbed...)
+	case slog.KindAny:
+		v := a.Value.Any()
+		if err, ok := v.(error); ok {
+			a.Value = slog.AnyValue(r.Error(err))
+		} else if s := fmt.Sprint(v); r.String(s) != s {
+			a.Value = slog.StringValue(r.String(s))
+		}
+	}
+	return a
+}
+
+// isSensitive reports whether values of key are secret by nature.
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler returns a handler that scrubs the message and attributes of every
+// record before passing it to h.
+func (r *Redactor) Handler(h slog.Handler) slog.Handler {
+	return &handler{next: h, r: r}
+}
+
+type handler struct {
+	next slog.Handler
+	r    *Redactor
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, rec slog.Record) error {
+	out := slog.NewRecord(rec.Time, rec.Level, h.r.String(rec.Message), rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.r.ReplaceAttr(nil, a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scrubbed := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		scrubbed[i] = h.r.ReplaceAttr(nil, a)
+	}
+	return &handler{next: h.next.WithAttrs(scrubbed), r: h.r}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), r: h.r}
+}
@@ -0,0 +1,64 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const token = "eyJhbGciOiJIUzI1NiJ9.secret-token"
+
+func TestError_ScrubsWrappedAndKeepsChain(t *testing.T) {
+	r := New(token, "", "abc")
+	inner := &url.Error{Op: "Post", URL: "http://ha.local/api?access_token=" + token, Err: errors.New("connection refused")}
+	err := r.Error(fmt.Errorf("execute request: %w", inner))
+
+	if strings.Contains(err.Error(), token) || !strings.Contains(err.Error(), Placeholder) {
+		t.Errorf("error = %q, want the token replaced", err)
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		t.Error("errors.As lost the wrapped *url.Error")
+	}
+
+	plain := errors.New("HA returned unexpected status 500")
+	if r.Error(plain) != plain {
+		t.Error("an error without a secret was wrapped")
+	}
+	if r.Error(nil) != nil {
+		t.Error("Error(nil) != nil")
+	}
+	if got := r.String("abc def"); got != "abc def" {
+		t.Errorf("String = %q, want short values left alone", got)
+	}
+}
+
+func TestHandler_ScrubsMessageAndAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	r := New(token, "Bearer otlp-api-key")
+	logger := slog.New(r.Handler(slog.NewTextHandler(&buf, nil))).
+		With("url", "http://ha.local/?t="+token)
+
+	logger.Info("calling with "+token,
+		"error", fmt.Errorf("dial: %s", token),
+		slog.Group("headers", "Authorization", "Basic dXNlcjpwYXNz", "accept", "application/json"),
+		"ha_token", "not-a-known-secret",
+		"status", 200,
+	)
+
+	out := buf.String()
+	for _, leaked := range []string{token, "dXNlcjpwYXNz", "not-a-known-secret"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("output leaks %q: %s", leaked, out)
+		}
+	}
+	for _, kept := range []string{"headers.accept=application/json", "status=200", "ha_token=" + Placeholder} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("output lacks %q: %s", kept, out)
+		}
+	}
+}
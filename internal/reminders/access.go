@@ -0,0 +1,76 @@
+package reminders
+
+import (
+	"fmt"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// Authorization is how far macOS privacy settings (TCC) let this process
+// access Reminders, after EKAuthorizationStatus.
+type Authorization int
+
+// Authorization states.
+const (
+	// AuthorizationNotDetermined means macOS has not asked the user yet.
+	AuthorizationNotDetermined Authorization = iota
+	// AuthorizationRestricted means a configuration profile or parental
+	// controls block access; the user cannot grant it.
+	AuthorizationRestricted
+	// AuthorizationDenied means the user declined or revoked access.
+	AuthorizationDenied
+	// AuthorizationGranted means full access.
+	AuthorizationGranted
+	// AuthorizationWriteOnly means reminders may be added but not read,
+	// which is not enough to sync.
+	AuthorizationWriteOnly
+	// AuthorizationUnsupported means EventKit is not available, as on
+	// platforms other than macOS.
+	AuthorizationUnsupported
+)
+
+// String returns the state as System Settings would describe it.
+func (a Authorization) String() string {
+	switch a {
+	case AuthorizationNotDetermined:
+		return "not yet requested"
+	case AuthorizationRestricted:
+		return "restricted"
+	case AuthorizationDenied:
+		return "denied"
+	case AuthorizationGranted:
+		return "granted"
+	case AuthorizationWriteOnly:
+		return "write-only"
+	default:
+		return "unsupported"
+	}
+}
+
+// PrivacySettingsURL opens the Reminders page of System Settings → Privacy
+// & Security, where access is granted.
+const PrivacySettingsURL = "x-apple.systempreferences:com.apple.preference.security?Privacy_Reminders"
+
+// CheckAccess reports whether [NewAdapter] can get access to Reminders
+// without a permissions dialog nobody answers. Access that macOS has not
+// asked for yet passes only if prompt is set, as for a command run in a
+// terminal; a daemon started by launchd would wait on the dialog. The
+// error wraps [model.ErrAccessDenied]. Where EventKit is unavailable,
+// NewAdapter reports the problem itself.
+func CheckAccess(prompt bool) error {
+	return checkAccess(AuthorizationStatus(), prompt)
+}
+
+func checkAccess(status Authorization, prompt bool) error {
+	switch status {
+	case AuthorizationGranted, AuthorizationUnsupported:
+		return nil
+	case AuthorizationNotDetermined:
+		if prompt {
+			return nil
+		}
+		return fmt.Errorf("%w: access was never requested", model.ErrAccessDenied)
+	default:
+		return fmt.Errorf("%w: access is %s", model.ErrAccessDenied, status)
+	}
+}
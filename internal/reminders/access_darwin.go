@@ -0,0 +1,45 @@
+//go:build darwin && cgo
+
+package reminders
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework EventKit -framework Foundation
+#import <EventKit/EventKit.h>
+
+static int rrAuthorizationStatus(void) {
+	return (int)[EKEventStore authorizationStatusForEntityType:EKEntityTypeReminder];
+}
+*/
+import "C"
+
+import ekreminders "github.com/BRO3886/go-eventkit/reminders"
+
+// AuthorizationStatus returns the current access to Reminders without
+// asking the user.
+func AuthorizationStatus() Authorization {
+	// EKAuthorizationStatus: notDetermined, restricted, denied,
+	// fullAccess (formerly authorized), writeOnly.
+	switch C.rrAuthorizationStatus() {
+	case 0:
+		return AuthorizationNotDetermined
+	case 1:
+		return AuthorizationRestricted
+	case 2:
+		return AuthorizationDenied
+	case 3:
+		return AuthorizationGranted
+	case 4:
+		return AuthorizationWriteOnly
+	default:
+		return AuthorizationDenied
+	}
+}
+
+// RequestAccess shows the macOS permissions dialog for Reminders, if the
+// user was not asked yet, and returns the access then granted.
+func RequestAccess() Authorization {
+	// go-eventkit requests access when a client is created.
+	_, _ = ekreminders.New()
+	return AuthorizationStatus()
+}
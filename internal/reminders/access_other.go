@@ -0,0 +1,10 @@
+//go:build !darwin || !cgo
+
+package reminders
+
+// AuthorizationStatus returns [AuthorizationUnsupported]: Reminders access
+// is only granted on macOS.
+func AuthorizationStatus() Authorization { return AuthorizationUnsupported }
+
+// RequestAccess returns [AuthorizationUnsupported].
+func RequestAccess() Authorization { return AuthorizationUnsupported }
@@ -5,21 +5,39 @@
 // accepts context.Context on every method for API consistency with the
 // architectural invariants, even though the underlying cgo calls are
 // non-cancellable (sub-200ms latency).
+//
+// FetchAll results are cached per list and reused until EventKit reports a
+// store change (see [ChangeMarker]), the adapter itself writes to Reminders,
+// or the cache exceeds its maximum age. An outdated snapshot still serves
+// as the base of the next fetch: go-eventkit offers no modification-date
+// predicate, so the list is queried in full, but only reminders whose
+// modification date moved are converted again.
 package reminders
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	ekreminders "github.com/BRO3886/go-eventkit/reminders"
 
+	"github.com/njoerd114/reminderrelay/internal/clock"
 	"github.com/njoerd114/reminderrelay/internal/model"
 )
 
+// DefaultCacheMaxAge bounds how long a cached list snapshot is reused without
+// a full EventKit query, as a backstop against missed change notifications.
+const DefaultCacheMaxAge = 5 * time.Minute
+
 // EventKitClient is the subset of [ekreminders.Client] methods used by the
 // adapter. Defining it as an interface allows mock injection in tests.
 type EventKitClient interface {
+	Lists() ([]ekreminders.List, error)
 	Reminders(opts ...ekreminders.ListOption) ([]ekreminders.Reminder, error)
 	CreateReminder(input ekreminders.CreateReminderInput) (*ekreminders.Reminder, error)
 	UpdateReminder(id string, input ekreminders.UpdateReminderInput) (*ekreminders.Reminder, error)
@@ -28,27 +46,121 @@ type EventKitClient interface {
 	UncompleteReminder(id string) (*ekreminders.Reminder, error)
 }
 
+// ChangeMarker reports a counter that advances whenever the Reminders
+// database changes, from any process. The adapter compares markers to decide
+// whether a cached fetch is still current.
+type ChangeMarker interface {
+	// Marker returns the current change counter.
+	Marker() uint64
+}
+
 // Adapter provides sync-engine–oriented operations on Apple Reminders via
 // EventKit. Create one with [NewAdapter] or [NewAdapterWithClient].
 type Adapter struct {
 	client EventKitClient
 	log    *slog.Logger
+	clock  clock.Clock
+	loc    *time.Location // see WithTimeZone
+
+	// sources names the account each list is read from and written to,
+	// keyed by list name; see WithSources.
+	sources map[string]string
+
+	// Fetch cache. Disabled when marker is nil or maxAge is zero.
+	marker ChangeMarker
+	maxAge time.Duration
+	mu     sync.Mutex
+	cache  map[string]cachedList
+
+	lastFetch time.Time // guarded by mu; see LastFetch
+}
+
+// cachedList is the last fetched snapshot of a single Reminders list.
+type cachedList struct {
+	items     []*model.Item
+	byUID     map[string]*model.Item // items, keyed by UID
+	marker    uint64
+	fetchedAt time.Time
+	outdated  bool // set by InvalidateCache
+}
+
+// AdapterOption configures optional Adapter behaviour.
+type AdapterOption func(*Adapter)
+
+// WithChangeMarker enables fetch caching keyed by m. [NewAdapter] installs
+// the EventKit store-change observer automatically where supported.
+func WithChangeMarker(m ChangeMarker) AdapterOption {
+	return func(a *Adapter) { a.marker = m }
+}
+
+// WithCacheMaxAge sets how long a cached snapshot may be reused. Zero
+// disables fetch caching.
+func WithCacheMaxAge(d time.Duration) AdapterOption {
+	return func(a *Adapter) { a.maxAge = d }
+}
+
+// WithClock replaces the clock used to age cached snapshots. Intended for
+// tests.
+func WithClock(c clock.Clock) AdapterOption {
+	return func(a *Adapter) { a.clock = c }
+}
+
+// WithTimeZone sets the zone due dates are expressed in, both read from and
+// written to EventKit. Defaults to the system's local zone, which is the
+// zone EventKit interprets due dates without a time in.
+func WithTimeZone(loc *time.Location) AdapterOption {
+	return func(a *Adapter) { a.loc = loc }
+}
+
+// WithSources selects the Reminders account, such as "iCloud" or
+// "Exchange", that each list named in sources is read from and created in,
+// for lists whose name another account uses too. Lists without an entry are
+// looked up by name alone, in whichever account EventKit finds first.
+func WithSources(sources map[string]string) AdapterOption {
+	return func(a *Adapter) { a.sources = sources }
 }
 
 // NewAdapter creates an Adapter backed by a real EventKit client.
 // This triggers the macOS TCC permissions prompt on first use.
-func NewAdapter(logger *slog.Logger) (*Adapter, error) {
+func NewAdapter(logger *slog.Logger, opts ...AdapterOption) (*Adapter, error) {
 	c, err := ekreminders.New()
 	if err != nil {
-		return nil, fmt.Errorf("initialising reminders client: %w", err)
+		return nil, fmt.Errorf("initialising reminders client: %w", accessError(err))
+	}
+
+	marker := newStoreChangeMarker()
+	if marker == nil {
+		logger.Debug("EventKit change notifications unavailable, fetch caching disabled")
+	}
+	opts = append([]AdapterOption{WithChangeMarker(marker)}, opts...)
+	return NewAdapterWithClient(c, logger, opts...), nil
+}
+
+// accessError marks EventKit's access-denied error with
+// [model.ErrAccessDenied] so callers outside this package can detect it.
+func accessError(err error) error {
+	if errors.Is(err, ekreminders.ErrAccessDenied) {
+		return fmt.Errorf("%w: %w", model.ErrAccessDenied, err)
 	}
-	return &Adapter{client: c, log: logger}, nil
+	return err
 }
 
 // NewAdapterWithClient creates an Adapter with a caller-supplied client.
-// Intended for testing with a mock [EventKitClient].
-func NewAdapterWithClient(client EventKitClient, logger *slog.Logger) *Adapter {
-	return &Adapter{client: client, log: logger}
+// Intended for testing with a mock [EventKitClient]. Fetch caching is off
+// unless a [ChangeMarker] is supplied.
+func NewAdapterWithClient(client EventKitClient, logger *slog.Logger, opts ...AdapterOption) *Adapter {
+	a := &Adapter{
+		client: client,
+		log:    logger,
+		clock:  clock.Real(),
+		loc:    time.Local,
+		maxAge: DefaultCacheMaxAge,
+		cache:  make(map[string]cachedList),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
 // FetchAll returns all reminders (completed and incomplete) across the given
@@ -60,21 +172,214 @@ func (a *Adapter) FetchAll(ctx context.Context, listNames []string) ([]*model.It
 
 	var items []*model.Item
 	for _, name := range listNames {
+		if cached, ok := a.cached(name); ok {
+			a.log.Debug("reusing cached reminders", "list", name, "count", len(cached))
+			items = append(items, cached...)
+			continue
+		}
+
+		// Read the marker before querying so a change that lands mid-fetch
+		// invalidates this snapshot on the next pass.
+		marker := a.currentMarker()
 		a.log.Debug("fetching reminders", "list", name)
 
-		rems, err := a.client.Reminders(ekreminders.WithList(name))
+		filter := ekreminders.WithList(name)
+		if source := a.sources[name]; source != "" {
+			id, err := a.listID(name, source)
+			if err != nil {
+				return nil, fmt.Errorf("fetching reminders for list %q: %w", name, err)
+			}
+			filter = ekreminders.WithListID(id)
+		}
+		rems, err := a.client.Reminders(filter)
 		if err != nil {
-			return nil, fmt.Errorf("fetching reminders for list %q: %w", name, err)
+			return nil, fmt.Errorf("fetching reminders for list %q: %w", name, accessError(err))
 		}
 
+		prev := a.previous(name)
+		fetched := make([]*model.Item, 0, len(rems))
+		changed := 0
 		for i := range rems {
-			items = append(items, reminderToItem(&rems[i], name))
+			if item, ok := unchanged(prev, &rems[i]); ok {
+				fetched = append(fetched, item)
+				continue
+			}
+			fetched = append(fetched, reminderToItem(&rems[i], name, a.loc))
+			changed++
 		}
-		a.log.Debug("fetched reminders", "list", name, "count", len(rems))
+		a.store(name, fetched, marker)
+		items = append(items, fetched...)
+		a.log.Debug("fetched reminders", "list", name, "count", len(rems), "changed", changed)
 	}
 	return items, nil
 }
 
+// ListNames returns the titles of all Reminders lists, sorted.
+func (a *Adapter) ListNames(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("listing Reminders lists: %w", err)
+	}
+	lists, err := a.client.Lists()
+	if err != nil {
+		return nil, fmt.Errorf("listing Reminders lists: %w", accessError(err))
+	}
+	names := make([]string, 0, len(lists))
+	for _, l := range lists {
+		names = append(names, l.Title)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ListIDs returns the title of every Reminders list, keyed by its calendar
+// identifier. Unlike titles, identifiers survive a rename.
+func (a *Adapter) ListIDs(ctx context.Context) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("listing Reminders lists: %w", err)
+	}
+	lists, err := a.client.Lists()
+	if err != nil {
+		return nil, fmt.Errorf("listing Reminders lists: %w", accessError(err))
+	}
+	ids := make(map[string]string, len(lists))
+	for _, l := range lists {
+		ids[l.ID] = l.Title
+	}
+	return ids, nil
+}
+
+// Accounts returns the calendar identifiers of every Reminders list, keyed
+// by the name of the account holding it, such as "iCloud". Signing into
+// another account keeps its name but changes every identifier.
+func (a *Adapter) Accounts(ctx context.Context) (map[string][]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("listing Reminders lists: %w", err)
+	}
+	lists, err := a.client.Lists()
+	if err != nil {
+		return nil, fmt.Errorf("listing Reminders lists: %w", accessError(err))
+	}
+	accounts := make(map[string][]string)
+	for _, l := range lists {
+		if l.ID != "" {
+			accounts[l.Source] = append(accounts[l.Source], l.ID)
+		}
+	}
+	return accounts, nil
+}
+
+// listID returns the calendar identifier of the list called name in the
+// Reminders account source. Account names match case-insensitively, as in
+// EventKit.
+func (a *Adapter) listID(name, source string) (string, error) {
+	lists, err := a.client.Lists()
+	if err != nil {
+		return "", fmt.Errorf("listing Reminders lists: %w", accessError(err))
+	}
+	for _, l := range lists {
+		if l.Title == name && strings.EqualFold(l.Source, source) {
+			return l.ID, nil
+		}
+	}
+	return "", fmt.Errorf("no list %q in Reminders account %q", name, source)
+}
+
+// InvalidateCache marks every cached list snapshot outdated so the next
+// FetchAll queries EventKit directly.
+func (a *Adapter) InvalidateCache() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for name, c := range a.cache {
+		c.outdated = true
+		a.cache[name] = c
+	}
+}
+
+// cachingEnabled reports whether fetch results may be cached.
+func (a *Adapter) cachingEnabled() bool {
+	return a.marker != nil && a.maxAge > 0
+}
+
+// currentMarker returns the store change marker, or zero without caching.
+func (a *Adapter) currentMarker() uint64 {
+	if !a.cachingEnabled() {
+		return 0
+	}
+	return a.marker.Marker()
+}
+
+// cached returns copies of the snapshot for listName if it is still current.
+func (a *Adapter) cached(listName string) ([]*model.Item, bool) {
+	if !a.cachingEnabled() {
+		return nil, false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	c, ok := a.cache[listName]
+	if !ok || c.outdated || c.marker != a.marker.Marker() || a.clock.Now().Sub(c.fetchedAt) >= a.maxAge {
+		return nil, false
+	}
+	return cloneItems(c.items), true
+}
+
+// previous returns the last snapshot of listName keyed by UID, current or
+// not, or nil if the list was never fetched. The map must not be modified.
+func (a *Adapter) previous(listName string) map[string]*model.Item {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cache[listName].byUID
+}
+
+// store records a fresh snapshot for listName. Snapshots are kept even
+// without caching, as the base of the next fetch.
+func (a *Adapter) store(listName string, items []*model.Item, marker uint64) {
+	items = cloneItems(items)
+	byUID := make(map[string]*model.Item, len(items))
+	for _, it := range items {
+		byUID[it.UID] = it
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastFetch = a.clock.Now()
+	a.cache[listName] = cachedList{items: items, byUID: byUID, marker: marker, fetchedAt: a.lastFetch}
+}
+
+// LastFetch returns when reminders were last read from EventKit, or the
+// zero time before the first read. Snapshots reused from the fetch cache do
+// not count, nor do failed reads: a process without Reminders access never
+// gets past the zero time.
+func (a *Adapter) LastFetch() time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.lastFetch
+}
+
+// unchanged returns a copy of the item prev holds for r if r was not
+// modified since. Reminders without a modification date are always
+// converted afresh.
+func unchanged(prev map[string]*model.Item, r *ekreminders.Reminder) (*model.Item, bool) {
+	p, ok := prev[r.ID]
+	if !ok || r.ModifiedAt == nil || p.ModifiedAt.IsZero() || !p.ModifiedAt.Equal(*r.ModifiedAt) {
+		return nil, false
+	}
+	cp := *p
+	return &cp, true
+}
+
+// cloneItems returns shallow copies of items so callers cannot mutate the
+// cached snapshot.
+func cloneItems(items []*model.Item) []*model.Item {
+	out := make([]*model.Item, len(items))
+	for i, it := range items {
+		cp := *it
+		out[i] = &cp
+	}
+	return out
+}
+
 // Create creates a new reminder from a [model.Item] and returns the
 // UID assigned by EventKit.
 func (a *Adapter) Create(ctx context.Context, item *model.Item) (string, error) {
@@ -82,13 +387,32 @@ func (a *Adapter) Create(ctx context.Context, item *model.Item) (string, error)
 		return "", fmt.Errorf("create reminder: %w", err)
 	}
 
-	input := itemToCreateInput(item)
+	var want string // identifier of the list, if its account is selected
+	if source := a.sources[item.ListName]; source != "" {
+		id, err := a.listID(item.ListName, source)
+		if err != nil {
+			return "", fmt.Errorf("creating reminder %q in list %q: %w", item.Title, item.ListName, err)
+		}
+		want = id
+	}
+
+	input := itemToCreateInput(item, a.loc)
 	a.log.Debug("creating reminder", "title", item.Title, "list", item.ListName)
+	defer a.InvalidateCache()
 
 	rem, err := a.client.CreateReminder(input)
 	if err != nil {
 		return "", fmt.Errorf("creating reminder %q in list %q: %w", item.Title, item.ListName, err)
 	}
+	if want != "" && rem.ListID != want {
+		// EventKit creates reminders by list name, in the first list of
+		// that name, which here belongs to another account.
+		if err := a.client.DeleteReminder(rem.ID); err != nil {
+			a.log.Error("removing reminder created in the wrong account failed", "uid", rem.ID, "error", err)
+		}
+		return "", fmt.Errorf("creating reminder %q in list %q of account %q: EventKit created it in a list of the same name in another account; rename one of the lists",
+			item.Title, item.ListName, a.sources[item.ListName])
+	}
 
 	// If the item should be completed, mark it now — CreateReminder always
 	// creates an incomplete reminder.
@@ -102,22 +426,25 @@ func (a *Adapter) Create(ctx context.Context, item *model.Item) (string, error)
 }
 
 // Update applies the fields from a [model.Item] to an existing reminder.
-func (a *Adapter) Update(ctx context.Context, uid string, item *model.Item) error {
+// fields names the fields that changed; see [itemToUpdateInput].
+func (a *Adapter) Update(ctx context.Context, uid string, item *model.Item, fields model.Fields) error {
 	if err := ctx.Err(); err != nil {
 		return fmt.Errorf("update reminder: %w", err)
 	}
 
 	a.log.Debug("updating reminder", "uid", uid, "title", item.Title)
+	defer a.InvalidateCache()
 
 	// Fetch current state to decide if completion status changed.
-	input := itemToUpdateInput(item)
+	input := itemToUpdateInput(item, fields, a.loc)
 	updated, err := a.client.UpdateReminder(uid, input)
 	if err != nil {
 		return fmt.Errorf("updating reminder %q: %w", uid, err)
 	}
 
 	// Handle completion status change through the dedicated API so that
-	// CompletionDate is set/cleared properly.
+	// CompletionDate is set/cleared properly. EventKit stamps it with the
+	// current time; item.CompletedAt cannot be written.
 	if item.Completed && !updated.Completed {
 		if _, err := a.client.CompleteReminder(uid); err != nil {
 			return fmt.Errorf("completing reminder %q: %w", uid, err)
@@ -138,6 +465,7 @@ func (a *Adapter) Delete(ctx context.Context, uid string) error {
 	}
 
 	a.log.Debug("deleting reminder", "uid", uid)
+	defer a.InvalidateCache()
 	if err := a.client.DeleteReminder(uid); err != nil {
 		return fmt.Errorf("deleting reminder %q: %w", uid, err)
 	}
@@ -0,0 +1,289 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	ekreminders "github.com/BRO3886/go-eventkit/reminders"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// fakeClient is an in-memory EventKitClient that counts list queries.
+type fakeClient struct {
+	reminders []ekreminders.Reminder
+	lists     []ekreminders.List // nil for a single list "Shopping"
+	queries   int
+	deleted   []string
+	err       error
+}
+
+func (f *fakeClient) Lists() ([]ekreminders.List, error) {
+	if f.lists != nil {
+		return f.lists, f.err
+	}
+	return []ekreminders.List{{Title: "Shopping"}}, f.err
+}
+
+func (f *fakeClient) Reminders(...ekreminders.ListOption) ([]ekreminders.Reminder, error) {
+	f.queries++
+	return f.reminders, f.err
+}
+
+func (f *fakeClient) CreateReminder(in ekreminders.CreateReminderInput) (*ekreminders.Reminder, error) {
+	r := ekreminders.Reminder{ID: "new", Title: in.Title}
+	// Like EventKit, create in the first list of the name.
+	for _, l := range f.lists {
+		if l.Title == in.ListName {
+			r.ListID = l.ID
+			break
+		}
+	}
+	f.reminders = append(f.reminders, r)
+	return &r, nil
+}
+
+func (f *fakeClient) UpdateReminder(id string, _ ekreminders.UpdateReminderInput) (*ekreminders.Reminder, error) {
+	return &ekreminders.Reminder{ID: id}, nil
+}
+
+func (f *fakeClient) DeleteReminder(id string) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func (f *fakeClient) CompleteReminder(id string) (*ekreminders.Reminder, error) {
+	return &ekreminders.Reminder{ID: id, Completed: true}, nil
+}
+
+func (f *fakeClient) UncompleteReminder(id string) (*ekreminders.Reminder, error) {
+	return &ekreminders.Reminder{ID: id}, nil
+}
+
+// fakeMarker is a ChangeMarker whose counter is advanced by hand.
+type fakeMarker struct{ n uint64 }
+
+func (m *fakeMarker) Marker() uint64 { return m.n }
+
+func TestFetchAll_CachesUntilStoreChanges(t *testing.T) {
+	client := &fakeClient{reminders: []ekreminders.Reminder{{ID: "r1", Title: "Buy milk"}}}
+	marker := &fakeMarker{n: 1}
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	a := NewAdapterWithClient(client, slog.Default(),
+		WithChangeMarker(marker), WithCacheMaxAge(time.Minute), WithClock(clk))
+	ctx := context.Background()
+
+	fetch := func() {
+		t.Helper()
+		items, err := a.FetchAll(ctx, []string{"Shopping"})
+		if err != nil {
+			t.Fatalf("FetchAll: %v", err)
+		}
+		if len(items) != len(client.reminders) {
+			t.Fatalf("FetchAll returned %d items, want %d", len(items), len(client.reminders))
+		}
+	}
+
+	if !a.LastFetch().IsZero() {
+		t.Errorf("LastFetch() = %v before any fetch, want zero", a.LastFetch())
+	}
+	fetch()
+	clk.Advance(10 * time.Second)
+	fetch()
+	if client.queries != 1 {
+		t.Errorf("queries = %d after idle pass, want 1 (cached)", client.queries)
+	}
+	if want := clk.Now().Add(-10 * time.Second); !a.LastFetch().Equal(want) {
+		t.Errorf("LastFetch() = %v, want %v (the cached pass does not count)", a.LastFetch(), want)
+	}
+
+	// An external change bumps the marker and forces a fresh query.
+	marker.n++
+	fetch()
+	if client.queries != 2 {
+		t.Errorf("queries = %d after store change, want 2", client.queries)
+	}
+
+	// The adapter's own writes invalidate the cache.
+	if _, err := a.Create(ctx, reminderToItem(&ekreminders.Reminder{Title: "Eggs"}, "Shopping", time.UTC)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	fetch()
+	if client.queries != 3 {
+		t.Errorf("queries = %d after own write, want 3", client.queries)
+	}
+
+	// Snapshots older than the max age are refreshed even without a change.
+	clk.Advance(time.Minute)
+	fetch()
+	if client.queries != 4 {
+		t.Errorf("queries = %d after max age, want 4", client.queries)
+	}
+}
+
+func TestFetchAll_NoMarkerDisablesCache(t *testing.T) {
+	client := &fakeClient{reminders: []ekreminders.Reminder{{ID: "r1", Title: "Buy milk"}}}
+	a := NewAdapterWithClient(client, slog.Default())
+
+	for range 2 {
+		if _, err := a.FetchAll(context.Background(), []string{"Shopping"}); err != nil {
+			t.Fatalf("FetchAll: %v", err)
+		}
+	}
+	if client.queries != 2 {
+		t.Errorf("queries = %d, want 2 without a change marker", client.queries)
+	}
+}
+
+func TestFetchAll_CachedItemsAreCopies(t *testing.T) {
+	client := &fakeClient{reminders: []ekreminders.Reminder{{ID: "r1", Title: "Buy milk"}}}
+	a := NewAdapterWithClient(client, slog.Default(), WithChangeMarker(&fakeMarker{n: 1}))
+	ctx := context.Background()
+
+	first, _ := a.FetchAll(ctx, []string{"Shopping"})
+	first[0].Title = "mutated"
+
+	second, _ := a.FetchAll(ctx, []string{"Shopping"})
+	if second[0].Title != "Buy milk" {
+		t.Errorf("cached Title = %q, want %q (callers must not mutate the cache)", second[0].Title, "Buy milk")
+	}
+}
+
+func TestFetchAll_ConvertsOnlyChangedReminders(t *testing.T) {
+	at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	later := at.Add(time.Minute)
+	client := &fakeClient{reminders: []ekreminders.Reminder{
+		{ID: "r1", Title: "Buy milk", ModifiedAt: &at},
+		{ID: "r2", Title: "Buy eggs", ModifiedAt: &at},
+		{ID: "r3", Title: "Buy tea"},
+	}}
+	a := NewAdapterWithClient(client, slog.Default())
+	ctx := context.Background()
+	if _, err := a.FetchAll(ctx, []string{"Shopping"}); err != nil {
+		t.Fatalf("FetchAll: %v", err)
+	}
+
+	// Titles changed behind the adapter's back show only where the
+	// modification date moved too, or where there is none to compare.
+	client.reminders[0].Title = "Buy oat milk"
+	client.reminders[1].Title = "Buy six eggs"
+	client.reminders[1].ModifiedAt = &later
+	client.reminders[2].Title = "Buy green tea"
+
+	items, err := a.FetchAll(ctx, []string{"Shopping"})
+	if err != nil {
+		t.Fatalf("FetchAll: %v", err)
+	}
+	got := make(map[string]string, len(items))
+	for _, it := range items {
+		got[it.UID] = it.Title
+	}
+	want := map[string]string{"r1": "Buy milk", "r2": "Buy six eggs", "r3": "Buy green tea"}
+	for uid, title := range want {
+		if got[uid] != title {
+			t.Errorf("%s: Title = %q, want %q", uid, got[uid], title)
+		}
+	}
+	if client.queries != 2 {
+		t.Errorf("queries = %d, want 2 (lists are always queried in full)", client.queries)
+	}
+}
+
+func TestChangeWatch_ReportsSettledChanges(t *testing.T) {
+	at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	w := changeWatch{last: 1}
+	steps := []struct {
+		marker uint64
+		after  time.Duration
+		want   bool
+	}{
+		{1, 0, false},                      // nothing changed
+		{2, 0, false},                      // change seen
+		{3, 300 * time.Millisecond, false}, // burst continues
+		{3, 300*time.Millisecond + changeSettle, true}, // settled
+		{3, 2 * changeSettle, false},                   // reported once
+	}
+	for i, s := range steps {
+		if got := w.observe(s.marker, at.Add(s.after)); got != s.want {
+			t.Errorf("step %d: observe(%d) = %v, want %v", i, s.marker, got, s.want)
+		}
+	}
+}
+
+func TestWatchChanges_NoMarker(t *testing.T) {
+	a := NewAdapterWithClient(&fakeClient{}, slog.Default())
+	if a.NotifiesChanges() {
+		t.Error("NotifiesChanges() = true without a change marker")
+	}
+	if err := a.WatchChanges(context.Background(), func() {}); !errors.Is(err, ErrNoChangeNotifications) {
+		t.Errorf("WatchChanges() error = %v, want ErrNoChangeNotifications", err)
+	}
+}
+
+func TestFetchAll_AccessDenied(t *testing.T) {
+	client := &fakeClient{err: ekreminders.ErrAccessDenied}
+	a := NewAdapterWithClient(client, slog.Default())
+
+	_, err := a.FetchAll(context.Background(), []string{"Shopping"})
+	if !errors.Is(err, model.ErrAccessDenied) {
+		t.Errorf("err = %v, want model.ErrAccessDenied", err)
+	}
+}
+
+func TestSources_SelectAccount(t *testing.T) {
+	client := &fakeClient{lists: []ekreminders.List{
+		{ID: "cal-exchange", Title: "Shopping", Source: "Exchange"},
+		{ID: "cal-icloud", Title: "Shopping", Source: "iCloud"},
+		{ID: "cal-work", Title: "Work", Source: "Exchange"},
+	}}
+	a := NewAdapterWithClient(client, slog.Default(), WithSources(map[string]string{
+		"Shopping": "icloud",
+		"Work":     "Exchange",
+		"Errands":  "iCloud",
+	}))
+	ctx := context.Background()
+
+	if _, err := a.FetchAll(ctx, []string{"Errands"}); err == nil {
+		t.Error("FetchAll of a list missing from its account succeeded, want error")
+	}
+
+	uid, err := a.Create(ctx, &model.Item{Title: "Report", ListName: "Work"})
+	if err != nil || uid != "new" {
+		t.Errorf("Create in Work = %q, %v, want new, nil", uid, err)
+	}
+
+	// EventKit picks the Exchange list of the shared name; the reminder is
+	// removed again instead of syncing from the wrong account.
+	if _, err := a.Create(ctx, &model.Item{Title: "Milk", ListName: "Shopping"}); err == nil {
+		t.Error("Create in Shopping of iCloud succeeded, want error")
+	}
+	if len(client.deleted) != 1 || client.deleted[0] != "new" {
+		t.Errorf("deleted = %v, want [new]", client.deleted)
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	tests := []struct {
+		status Authorization
+		prompt bool
+		denied bool
+	}{
+		{AuthorizationGranted, false, false},
+		{AuthorizationNotDetermined, true, false},
+		{AuthorizationNotDetermined, false, true},
+		{AuthorizationDenied, true, true},
+		{AuthorizationRestricted, true, true},
+		{AuthorizationWriteOnly, false, true},
+		{AuthorizationUnsupported, false, false},
+	}
+	for _, tt := range tests {
+		err := checkAccess(tt.status, tt.prompt)
+		if got := errors.Is(err, model.ErrAccessDenied); got != tt.denied {
+			t.Errorf("checkAccess(%s, prompt %v) = %v, want denied %v", tt.status, tt.prompt, err, tt.denied)
+		}
+	}
+}
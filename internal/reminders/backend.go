@@ -0,0 +1,58 @@
+package reminders
+
+import (
+	"context"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// Backend exposes an [Adapter] as a sync task backend, with Reminders list
+// names as list identifiers. It is always the source side of a sync.
+type Backend struct {
+	a *Adapter
+}
+
+// NewBackend returns the task backend for a.
+func NewBackend(a *Adapter) *Backend {
+	return &Backend{a: a}
+}
+
+// Fetch returns the reminders on lists.
+func (b *Backend) Fetch(ctx context.Context, lists []string) ([]*model.Item, error) {
+	return b.a.FetchAll(ctx, lists)
+}
+
+// Lists returns the names of all Reminders lists.
+func (b *Backend) Lists(ctx context.Context) ([]string, error) {
+	return b.a.ListNames(ctx)
+}
+
+// ListIDs returns the names of all Reminders lists, keyed by calendar
+// identifier.
+func (b *Backend) ListIDs(ctx context.Context) (map[string]string, error) {
+	return b.a.ListIDs(ctx)
+}
+
+// Accounts returns the calendar identifiers of all Reminders lists, keyed by
+// account name.
+func (b *Backend) Accounts(ctx context.Context) (map[string][]string, error) {
+	return b.a.Accounts(ctx)
+}
+
+// Create adds item to list and returns its EventKit UID.
+func (b *Backend) Create(ctx context.Context, list string, item *model.Item) (string, error) {
+	cp := *item
+	cp.ListName = list
+	return b.a.Create(ctx, &cp)
+}
+
+// Update writes item over current, identified by UID. EventKit updates are
+// whole-item, so fields only decides whether alarms are replaced.
+func (b *Backend) Update(ctx context.Context, _ string, current, item *model.Item, fields model.Fields) error {
+	return b.a.Update(ctx, current.UID, item, fields)
+}
+
+// Delete removes current, identified by UID.
+func (b *Backend) Delete(ctx context.Context, _ string, current *model.Item) error {
+	return b.a.Delete(ctx, current.UID)
+}
@@ -0,0 +1,52 @@
+//go:build darwin && cgo
+
+package reminders
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework EventKit -framework Foundation
+#import <EventKit/EventKit.h>
+#include <stdatomic.h>
+#include <stdint.h>
+
+static _Atomic uint64_t rrChangeCount = 1;
+static EKEventStore *rrStore = nil;
+static id rrObserver = nil;
+
+// rrStartObserving registers an observer for EKEventStoreChangedNotification
+// on a private store. Notifications are delivered on a background operation
+// queue, so no run loop is required.
+static void rrStartObserving(void) {
+	static dispatch_once_t once;
+	dispatch_once(&once, ^{
+		rrStore = [[EKEventStore alloc] init];
+		NSOperationQueue *queue = [[NSOperationQueue alloc] init];
+		rrObserver = [[NSNotificationCenter defaultCenter]
+			addObserverForName:EKEventStoreChangedNotification
+			            object:rrStore
+			             queue:queue
+			        usingBlock:^(NSNotification *note) {
+				atomic_fetch_add(&rrChangeCount, 1);
+			}];
+	});
+}
+
+static uint64_t rrChangeCountValue(void) {
+	return atomic_load(&rrChangeCount);
+}
+*/
+import "C"
+
+// storeChangeMarker counts EKEventStoreChangedNotification deliveries.
+type storeChangeMarker struct{}
+
+// newStoreChangeMarker starts observing EventKit store changes.
+func newStoreChangeMarker() ChangeMarker {
+	C.rrStartObserving()
+	return storeChangeMarker{}
+}
+
+// Marker returns the number of store changes observed so far.
+func (storeChangeMarker) Marker() uint64 {
+	return uint64(C.rrChangeCountValue())
+}
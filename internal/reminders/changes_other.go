@@ -0,0 +1,7 @@
+//go:build !darwin || !cgo
+
+package reminders
+
+// newStoreChangeMarker returns nil: EventKit change notifications are only
+// available on macOS, so fetch caching stays disabled elsewhere.
+func newStoreChangeMarker() ChangeMarker { return nil }
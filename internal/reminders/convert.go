@@ -1,6 +1,8 @@
 package reminders
 
 import (
+	"time"
+
 	ekreminders "github.com/BRO3886/go-eventkit/reminders"
 
 	"github.com/njoerd114/reminderrelay/internal/model"
@@ -9,22 +11,37 @@ import (
 // reminderToItem converts an EventKit Reminder to a normalised model.Item.
 // listName is passed explicitly because the go-eventkit Reminder.List field
 // contains the list name as reported by EventKit, which may differ from the
-// config mapping key in edge cases (e.g. leading/trailing whitespace).
-func reminderToItem(r *ekreminders.Reminder, listName string) *model.Item {
+// config mapping key in edge cases (e.g. leading/trailing whitespace). The due
+// date and alert time are expressed in loc.
+func reminderToItem(r *ekreminders.Reminder, listName string, loc *time.Location) *model.Item {
 	item := &model.Item{
 		UID:         r.ID,
 		Title:       r.Title,
 		Description: r.Notes,
 		Priority:    model.NormalizePriority(int(r.Priority)),
+		RawPriority: int(r.Priority),
+		URL:         r.URL,
 		Completed:   r.Completed,
 		ListName:    listName,
 	}
 
 	if r.DueDate != nil {
-		t := *r.DueDate
+		t := r.DueDate.In(loc)
 		item.DueDate = &t
 	}
 
+	if at := earliestAlarm(r); at != nil {
+		// Alerts fire to the minute; seconds would not survive a target
+		// that keeps the time as text.
+		t := at.In(loc).Truncate(time.Minute)
+		item.AlertAt = &t
+	}
+
+	if r.Completed && r.CompletionDate != nil {
+		t := r.CompletionDate.In(loc)
+		item.CompletedAt = &t
+	}
+
 	if r.ModifiedAt != nil {
 		item.ModifiedAt = *r.ModifiedAt
 	}
@@ -32,45 +49,92 @@ func reminderToItem(r *ekreminders.Reminder, listName string) *model.Item {
 	return item
 }
 
-// itemToCreateInput builds an EventKit CreateReminderInput from a model.Item.
-func itemToCreateInput(item *model.Item) ekreminders.CreateReminderInput {
+// earliestAlarm returns when the first of r's alarms fires, or nil if it has
+// none. Relative alarms fire relative to the due date and are skipped when r
+// has none.
+func earliestAlarm(r *ekreminders.Reminder) *time.Time {
+	var first *time.Time
+	for _, a := range r.Alarms {
+		at := a.AbsoluteDate
+		if at == nil {
+			if r.DueDate == nil {
+				continue
+			}
+			t := r.DueDate.Add(a.RelativeOffset)
+			at = &t
+		}
+		if first == nil || at.Before(*first) {
+			first = at
+		}
+	}
+	return first
+}
+
+// alarmsFor returns the alarms that make item's alert fire, expressed in loc:
+// one absolute alarm, or none when item has no alert.
+func alarmsFor(item *model.Item, loc *time.Location) []ekreminders.Alarm {
+	if item.AlertAt == nil {
+		return []ekreminders.Alarm{}
+	}
+	t := item.AlertAt.In(loc)
+	return []ekreminders.Alarm{{AbsoluteDate: &t}}
+}
+
+// itemToCreateInput builds an EventKit CreateReminderInput from a model.Item,
+// with the due date and alert time expressed in loc.
+func itemToCreateInput(item *model.Item, loc *time.Location) ekreminders.CreateReminderInput {
 	input := ekreminders.CreateReminderInput{
 		Title:    item.Title,
 		Notes:    item.Description,
 		ListName: item.ListName,
-		Priority: priorityToEventKit(item.Priority),
+		Priority: eventKitPriority(item),
+		URL:      item.URL,
 	}
 
 	if item.DueDate != nil {
-		t := *item.DueDate
+		t := item.DueDate.In(loc)
 		input.DueDate = &t
 	}
 
+	if item.AlertAt != nil {
+		input.Alarms = alarmsFor(item, loc)
+	}
+
 	return input
 }
 
 // itemToUpdateInput builds an EventKit UpdateReminderInput from a model.Item.
 // All syncable fields are set so the update is a full overwrite rather than a
 // partial patch — this matches the sync engine's semantics where the winning
-// side's complete state is applied.
-func itemToUpdateInput(item *model.Item) ekreminders.UpdateReminderInput {
+// side's complete state is applied. Alarms are the exception: they are only
+// replaced when fields has [model.FieldAlert], so a reminder keeps any alarms
+// after its earliest through edits of other fields. The due date and alert
+// time are expressed in loc.
+func itemToUpdateInput(item *model.Item, fields model.Fields, loc *time.Location) ekreminders.UpdateReminderInput {
 	title := item.Title
 	notes := item.Description
-	prio := priorityToEventKit(item.Priority)
+	prio := eventKitPriority(item)
+	url := item.URL
 
 	input := ekreminders.UpdateReminderInput{
 		Title:    &title,
 		Notes:    &notes,
 		Priority: &prio,
+		URL:      &url,
 	}
 
 	if item.DueDate != nil {
-		t := *item.DueDate
+		t := item.DueDate.In(loc)
 		input.DueDate = &t
 	} else {
 		input.ClearDueDate = true
 	}
 
+	if fields.Has(model.FieldAlert) {
+		alarms := alarmsFor(item, loc)
+		input.Alarms = &alarms
+	}
+
 	// Completed is handled separately in Adapter.Update via the dedicated
 	// CompleteReminder / UncompleteReminder APIs, so we intentionally leave
 	// input.Completed as nil here.
@@ -78,6 +142,17 @@ func itemToUpdateInput(item *model.Item) ekreminders.UpdateReminderInput {
 	return input
 }
 
+// eventKitPriority returns the EventKit priority to write for item: its
+// RawPriority when that falls in its priority level, so a priority such as 2
+// survives a round trip through Home Assistant, otherwise the level's
+// canonical value.
+func eventKitPriority(item *model.Item) ekreminders.Priority {
+	if item.RawPriority > 0 && model.NormalizePriority(item.RawPriority) == item.Priority {
+		return ekreminders.Priority(item.RawPriority)
+	}
+	return priorityToEventKit(item.Priority)
+}
+
 // priorityToEventKit maps a model.Priority back to the EventKit constant.
 // The mapping is lossless because model.Priority values are a subset of
 // EventKit priorities (0, 1, 5, 9).
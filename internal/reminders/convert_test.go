@@ -3,6 +3,7 @@ package reminders
 import (
 	"testing"
 	"time"
+	_ "time/tzdata" // zones for the DST test
 
 	ekreminders "github.com/BRO3886/go-eventkit/reminders"
 
@@ -25,10 +26,11 @@ func TestReminderToItem_FullFields(t *testing.T) {
 		DueDate:    &due,
 		ModifiedAt: &mod,
 		Priority:   ekreminders.PriorityHigh,
+		URL:        "https://example.com/milk",
 		Completed:  false,
 	}
 
-	got := reminderToItem(r, "Shopping")
+	got := reminderToItem(r, "Shopping", time.UTC)
 
 	if got.UID != "EK-UID-123" {
 		t.Errorf("UID = %q, want %q", got.UID, "EK-UID-123")
@@ -45,6 +47,9 @@ func TestReminderToItem_FullFields(t *testing.T) {
 	if got.Priority != model.PriorityHigh {
 		t.Errorf("Priority = %v, want %v", got.Priority, model.PriorityHigh)
 	}
+	if got.URL != "https://example.com/milk" {
+		t.Errorf("URL = %q, want %q", got.URL, "https://example.com/milk")
+	}
 	if got.Completed {
 		t.Error("Completed = true, want false")
 	}
@@ -63,7 +68,7 @@ func TestReminderToItem_NilOptionals(t *testing.T) {
 		Priority: ekreminders.PriorityNone,
 	}
 
-	got := reminderToItem(r, "Default")
+	got := reminderToItem(r, "Default", time.UTC)
 
 	if got.DueDate != nil {
 		t.Errorf("DueDate = %v, want nil", got.DueDate)
@@ -92,7 +97,7 @@ func TestReminderToItem_PriorityNormalization(t *testing.T) {
 			ID:       "test",
 			Priority: tt.ekPriority,
 		}
-		got := reminderToItem(r, "Test")
+		got := reminderToItem(r, "Test", time.UTC)
 		if got.Priority != tt.want {
 			t.Errorf("priority(%d) → %v, want %v", tt.ekPriority, got.Priority, tt.want)
 		}
@@ -105,7 +110,7 @@ func TestReminderToItem_CompletedState(t *testing.T) {
 		Title:     "Already done",
 		Completed: true,
 	}
-	got := reminderToItem(r, "Work")
+	got := reminderToItem(r, "Work", time.UTC)
 	if !got.Completed {
 		t.Error("Completed = false, want true")
 	}
@@ -125,7 +130,7 @@ func TestItemToCreateInput_FullFields(t *testing.T) {
 		Priority:    model.PriorityMedium,
 	}
 
-	got := itemToCreateInput(item)
+	got := itemToCreateInput(item, time.UTC)
 
 	if got.Title != "Write tests" {
 		t.Errorf("Title = %q, want %q", got.Title, "Write tests")
@@ -150,7 +155,7 @@ func TestItemToCreateInput_NoDueDate(t *testing.T) {
 		ListName: "Personal",
 		Priority: model.PriorityNone,
 	}
-	got := itemToCreateInput(item)
+	got := itemToCreateInput(item, time.UTC)
 	if got.DueDate != nil {
 		t.Errorf("DueDate = %v, want nil", got.DueDate)
 	}
@@ -167,9 +172,10 @@ func TestItemToUpdateInput_FullFields(t *testing.T) {
 		Description: "Updated notes",
 		DueDate:     &due,
 		Priority:    model.PriorityLow,
+		URL:         "https://example.com",
 	}
 
-	got := itemToUpdateInput(item)
+	got := itemToUpdateInput(item, model.AllFields, time.UTC)
 
 	if got.Title == nil || *got.Title != "Updated title" {
 		t.Errorf("Title = %v, want %q", got.Title, "Updated title")
@@ -183,6 +189,9 @@ func TestItemToUpdateInput_FullFields(t *testing.T) {
 	if got.Priority == nil || *got.Priority != ekreminders.PriorityLow {
 		t.Errorf("Priority = %v, want %v", got.Priority, ekreminders.PriorityLow)
 	}
+	if got.URL == nil || *got.URL != "https://example.com" {
+		t.Errorf("URL = %v, want %q", got.URL, "https://example.com")
+	}
 	if got.ClearDueDate {
 		t.Error("ClearDueDate = true, want false when DueDate is set")
 	}
@@ -196,7 +205,7 @@ func TestItemToUpdateInput_ClearDueDate(t *testing.T) {
 		Title:   "Remove deadline",
 		DueDate: nil,
 	}
-	got := itemToUpdateInput(item)
+	got := itemToUpdateInput(item, model.AllFields, time.UTC)
 	if !got.ClearDueDate {
 		t.Error("ClearDueDate = false, want true when DueDate is nil")
 	}
@@ -209,6 +218,72 @@ func TestItemToUpdateInput_ClearDueDate(t *testing.T) {
 // priorityToEventKit
 // ---------------------------------------------------------------------------
 
+func TestDueDate_ExpressedInZone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Midnight on the day New York springs forward, as EventKit reports it.
+	due := time.Date(2026, 3, 8, 0, 0, 0, 0, loc).UTC()
+	item := reminderToItem(&ekreminders.Reminder{ID: "r1", Title: "Clocks", DueDate: &due}, "Home", loc)
+	if got := item.DueDate.Format("2006-01-02 15:04"); got != "2026-03-08 00:00" {
+		t.Errorf("due date = %s, want midnight on 2026-03-08", got)
+	}
+	if in := itemToCreateInput(item, loc); !in.DueDate.Equal(due) || in.DueDate.Location() != loc {
+		t.Errorf("create input due date = %v, want %v in %s", in.DueDate, due, loc)
+	}
+}
+
+func TestReminderToItem_CompletionDate(t *testing.T) {
+	done := time.Date(2026, 3, 1, 19, 45, 0, 0, time.UTC)
+	item := reminderToItem(&ekreminders.Reminder{ID: "r1", Title: "Bins", Completed: true, CompletionDate: &done}, "Home", time.UTC)
+	if item.CompletedAt == nil || !item.CompletedAt.Equal(done) {
+		t.Errorf("CompletedAt = %v, want %v", item.CompletedAt, done)
+	}
+	item = reminderToItem(&ekreminders.Reminder{ID: "r1", Title: "Bins", CompletionDate: &done}, "Home", time.UTC)
+	if item.CompletedAt != nil {
+		t.Errorf("CompletedAt = %v, want nil for an open reminder", item.CompletedAt)
+	}
+}
+
+func TestReminderToItem_EarliestAlarm(t *testing.T) {
+	due := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	abs := time.Date(2026, 3, 1, 18, 0, 45, 0, time.UTC)
+	r := &ekreminders.Reminder{
+		ID: "r1", Title: "Bins", DueDate: &due,
+		Alarms: []ekreminders.Alarm{{AbsoluteDate: &abs}, {RelativeOffset: -24 * time.Hour}},
+	}
+	item := reminderToItem(r, "Home", time.UTC)
+	want := due.Add(-24 * time.Hour)
+	if item.AlertAt == nil || !item.AlertAt.Equal(want) {
+		t.Errorf("AlertAt = %v, want the relative alarm at %v", item.AlertAt, want)
+	}
+
+	// Without a due date, relative alarms cannot fire; seconds are dropped.
+	r.DueDate = nil
+	item = reminderToItem(r, "Home", time.UTC)
+	if want := abs.Truncate(time.Minute); item.AlertAt == nil || !item.AlertAt.Equal(want) {
+		t.Errorf("AlertAt = %v, want %v", item.AlertAt, want)
+	}
+}
+
+func TestItemToUpdateInput_AlarmsOnlyWhenAlertChanged(t *testing.T) {
+	alert := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	item := &model.Item{Title: "Bins", AlertAt: &alert}
+
+	if got := itemToUpdateInput(item, model.FieldTitle, time.UTC); got.Alarms != nil {
+		t.Errorf("Alarms = %v, want nil so existing alarms stay", *got.Alarms)
+	}
+	got := itemToUpdateInput(item, model.FieldAlert, time.UTC)
+	if got.Alarms == nil || len(*got.Alarms) != 1 || !(*got.Alarms)[0].AbsoluteDate.Equal(alert) {
+		t.Errorf("Alarms = %v, want one alarm at %v", got.Alarms, alert)
+	}
+	item.AlertAt = nil
+	if got := itemToUpdateInput(item, model.FieldAlert, time.UTC); got.Alarms == nil || len(*got.Alarms) != 0 {
+		t.Errorf("Alarms = %v, want an empty list to clear them", got.Alarms)
+	}
+}
+
 func TestPriorityToEventKit(t *testing.T) {
 	tests := []struct {
 		p    model.Priority
@@ -226,6 +301,30 @@ func TestPriorityToEventKit(t *testing.T) {
 	}
 }
 
+func TestEventKitPriority_KeepsRawWithinLevel(t *testing.T) {
+	tests := []struct {
+		p    model.Priority
+		raw  int
+		want ekreminders.Priority
+	}{
+		{model.PriorityHigh, 2, 2},
+		{model.PriorityLow, 7, 7},
+		{model.PriorityHigh, 0, ekreminders.PriorityHigh},
+		// The level changed elsewhere, so the raw value no longer applies.
+		{model.PriorityMedium, 2, ekreminders.PriorityMedium},
+		{model.PriorityNone, 3, ekreminders.PriorityNone},
+	}
+	for _, tt := range tests {
+		item := &model.Item{Priority: tt.p, RawPriority: tt.raw}
+		if got := eventKitPriority(item); got != tt.want {
+			t.Errorf("eventKitPriority(%v, raw %d) = %v, want %v", tt.p, tt.raw, got, tt.want)
+		}
+		if got := *itemToUpdateInput(item, model.AllFields, time.UTC).Priority; got != tt.want {
+			t.Errorf("update input priority (%v, raw %d) = %v, want %v", tt.p, tt.raw, got, tt.want)
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Round-trip: model.Item → CreateInput → Reminder → model.Item
 // ---------------------------------------------------------------------------
@@ -244,7 +343,7 @@ func TestConversionRoundTrip(t *testing.T) {
 	}
 
 	// model.Item → CreateInput
-	createInput := itemToCreateInput(original)
+	createInput := itemToCreateInput(original, time.UTC)
 
 	// Simulate what EventKit would return after creation
 	ekReminder := &ekreminders.Reminder{
@@ -259,7 +358,7 @@ func TestConversionRoundTrip(t *testing.T) {
 	}
 
 	// Reminder → model.Item
-	result := reminderToItem(ekReminder, "Shopping")
+	result := reminderToItem(ekReminder, "Shopping", time.UTC)
 
 	if result.Title != original.Title {
 		t.Errorf("Title = %q, want %q", result.Title, original.Title)
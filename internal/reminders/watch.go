@@ -0,0 +1,69 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// changeCheckInterval is how often WatchChanges reads the change marker.
+	// Reading it is an atomic load, not an EventKit query.
+	changeCheckInterval = 250 * time.Millisecond
+
+	// changeSettle is how long the marker must stay put before a change is
+	// reported. A single edit often posts several notifications.
+	changeSettle = time.Second
+)
+
+// ErrNoChangeNotifications is returned by [Adapter.WatchChanges] where
+// EventKit change notifications are unavailable.
+var ErrNoChangeNotifications = errors.New("EventKit change notifications unavailable")
+
+// NotifiesChanges reports whether [Adapter.WatchChanges] is available.
+func (a *Adapter) NotifiesChanges() bool {
+	return a.marker != nil
+}
+
+// WatchChanges calls changed each time the Reminders database changed, from
+// any process including this one, once the change has settled. It blocks
+// until ctx is cancelled.
+func (a *Adapter) WatchChanges(ctx context.Context, changed func()) error {
+	if a.marker == nil {
+		return ErrNoChangeNotifications
+	}
+	ticker := a.clock.NewTicker(changeCheckInterval)
+	defer ticker.Stop()
+
+	w := changeWatch{last: a.marker.Marker()}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+			if w.observe(a.marker.Marker(), a.clock.Now()) {
+				changed()
+			}
+		}
+	}
+}
+
+// changeWatch debounces change markers for WatchChanges.
+type changeWatch struct {
+	last  uint64
+	since time.Time // when an unreported change was last seen; zero if none
+}
+
+// observe records marker as read at now and reports whether a change has
+// settled and should be reported.
+func (w *changeWatch) observe(marker uint64, now time.Time) bool {
+	if marker != w.last {
+		w.last, w.since = marker, now
+		return false
+	}
+	if w.since.IsZero() || now.Sub(w.since) < changeSettle {
+		return false
+	}
+	w.since = time.Time{}
+	return true
+}
@@ -0,0 +1,192 @@
+// Package render formats CLI output as aligned tables and key-value blocks,
+// with optional ANSI colour. Colour is enabled only when the writer is a
+// terminal, NO_COLOR is unset, and the caller has not disabled it; tables
+// are truncated to the detected terminal width so output stays readable and
+// line-oriented for tools like grep and awk.
+package render
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/sys/unix"
+)
+
+// defaultWidth is used when the terminal width cannot be detected.
+const defaultWidth = 100
+
+// Style selects the colour applied by [Printer.Style].
+type Style int
+
+const (
+	// Plain applies no styling.
+	Plain Style = iota
+	// Good marks healthy values (green).
+	Good
+	// Warn marks values that need attention (yellow).
+	Warn
+	// Bad marks failures (red).
+	Bad
+	// Dim de-emphasises secondary information (grey).
+	Dim
+	// Bold emphasises headings.
+	Bold
+)
+
+var ansiCodes = map[Style]string{
+	Good: "\033[32m",
+	Warn: "\033[33m",
+	Bad:  "\033[31m",
+	Dim:  "\033[2m",
+	Bold: "\033[1m",
+}
+
+const ansiReset = "\033[0m"
+
+// ansiPattern matches SGR escape sequences so they can be ignored when
+// measuring column widths.
+var ansiPattern = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// Printer writes formatted output to an [io.Writer]. Create one with [New].
+type Printer struct {
+	w     io.Writer
+	color bool
+	width int
+}
+
+// New creates a Printer for w. Colour is enabled when w is a terminal,
+// NO_COLOR is unset, and noColor is false. The output width comes from the
+// terminal, then $COLUMNS, then a default of 100 columns.
+func New(w io.Writer, noColor bool) *Printer {
+	f, isFile := w.(*os.File)
+	tty := isFile && isTerminal(f)
+
+	return &Printer{
+		w:     w,
+		color: tty && !noColor && os.Getenv("NO_COLOR") == "",
+		width: detectWidth(f, tty),
+	}
+}
+
+// Style wraps text in the ANSI sequence for s when colour is enabled.
+func (p *Printer) Style(s Style, text string) string {
+	code, ok := ansiCodes[s]
+	if !p.color || !ok {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// Heading prints a bold title followed by an underline of matching width.
+func (p *Printer) Heading(title string) {
+	_, _ = fmt.Fprintln(p.w, p.Style(Bold, title))
+	_, _ = fmt.Fprintln(p.w, strings.Repeat("─", visibleWidth(title)))
+}
+
+// Fields prints label/value pairs with the values aligned in one column:
+//
+//	Daemon:    running (launchd)
+//	Config:    ~/.config/reminderrelay/config.yaml
+func (p *Printer) Fields(fields [][2]string) {
+	labelWidth := 0
+	for _, f := range fields {
+		labelWidth = max(labelWidth, visibleWidth(f[0])+1)
+	}
+	for _, f := range fields {
+		label := f[0] + ":"
+		line := "  " + pad(label, labelWidth) + "  " + f[1]
+		_, _ = fmt.Fprintln(p.w, p.truncate(line))
+	}
+}
+
+// Table prints rows under bold headers with every column left-aligned.
+// Cells may contain styled text; escape sequences are ignored when measuring
+// widths. Lines wider than the terminal are truncated with an ellipsis.
+func (p *Printer) Table(headers []string, rows [][]string) {
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = visibleWidth(h)
+	}
+	for _, row := range rows {
+		for i := range min(len(row), len(widths)) {
+			widths[i] = max(widths[i], visibleWidth(row[i]))
+		}
+	}
+
+	hdr := make([]string, len(headers))
+	for i, h := range headers {
+		hdr[i] = p.Style(Bold, h)
+	}
+	_, _ = fmt.Fprintln(p.w, p.truncate(joinRow(hdr, widths)))
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(p.w, p.truncate(joinRow(row, widths)))
+	}
+}
+
+// truncate shortens line to the printer width, appending "…". Styled lines
+// are stripped of colour before truncation so no escape sequence is cut.
+func (p *Printer) truncate(line string) string {
+	if p.width <= 0 || visibleWidth(line) <= p.width {
+		return line
+	}
+	plain := []rune(ansiPattern.ReplaceAllString(line, ""))
+	return string(plain[:p.width-1]) + "…"
+}
+
+// joinRow pads every cell but the last to its column width.
+func joinRow(cells []string, widths []int) string {
+	var b strings.Builder
+	for i, c := range cells {
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		if i < len(cells)-1 && i < len(widths) {
+			b.WriteString(pad(c, widths[i]))
+		} else {
+			b.WriteString(c)
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// pad right-pads s with spaces to the given visible width.
+func pad(s string, width int) string {
+	if n := width - visibleWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// visibleWidth returns the number of runes in s, ignoring ANSI sequences.
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiPattern.ReplaceAllString(s, ""))
+}
+
+// isTerminal reports whether f is a character device (a TTY).
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// detectWidth returns the terminal width of f, falling back to $COLUMNS and
+// then defaultWidth. Non-terminal output is never truncated.
+func detectWidth(f *os.File, tty bool) int {
+	if !tty {
+		return 0
+	}
+	if ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ); err == nil && ws.Col > 0 {
+		return int(ws.Col)
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultWidth
+}
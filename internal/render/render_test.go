@@ -0,0 +1,66 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func newTestPrinter(color bool, width int) (*Printer, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return &Printer{w: &buf, color: color, width: width}, &buf
+}
+
+func TestTable_AlignsColumns(t *testing.T) {
+	p, buf := newTestPrinter(false, 0)
+	p.Table([]string{"LIST", "ENTITY"}, [][]string{
+		{"Shopping", "todo.shopping"},
+		{"Work", "todo.work_tasks"},
+	})
+
+	want := "LIST      ENTITY\n" +
+		"Shopping  todo.shopping\n" +
+		"Work      todo.work_tasks\n"
+	if buf.String() != want {
+		t.Errorf("table output =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestTable_IgnoresColourWhenMeasuring(t *testing.T) {
+	p, buf := newTestPrinter(true, 0)
+	p.Table([]string{"A", "B"}, [][]string{
+		{p.Style(Good, "ok"), "x"},
+		{"failed", "y"},
+	})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	// Both data rows should place column B at the same visible offset.
+	first := ansiPattern.ReplaceAllString(lines[1], "")
+	second := ansiPattern.ReplaceAllString(lines[2], "")
+	if strings.Index(first, "x") != strings.Index(second, "y") {
+		t.Errorf("columns misaligned:\n%q\n%q", first, second)
+	}
+}
+
+func TestStyle_NoColor(t *testing.T) {
+	p, _ := newTestPrinter(false, 0)
+	if got := p.Style(Bad, "boom"); got != "boom" {
+		t.Errorf("Style without colour = %q, want plain text", got)
+	}
+}
+
+func TestFields_TruncatesToWidth(t *testing.T) {
+	p, buf := newTestPrinter(false, 20)
+	p.Fields([][2]string{{"Config", "/a/very/long/path/to/config.yaml"}})
+
+	line := strings.TrimRight(buf.String(), "\n")
+	if visibleWidth(line) != 20 {
+		t.Errorf("line width = %d, want 20: %q", visibleWidth(line), line)
+	}
+	if !strings.HasSuffix(line, "…") {
+		t.Errorf("truncated line should end with an ellipsis: %q", line)
+	}
+}
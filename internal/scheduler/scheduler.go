@@ -0,0 +1,147 @@
+// Package scheduler runs the daemon's auxiliary periodic jobs — maintenance
+// and similar housekeeping — next to the sync engine, so each new task does
+// not need a ticker of its own.
+//
+// The last run of every job is persisted, so a restarted daemon picks up the
+// schedule where it left off instead of running every job again at startup.
+// A random jitter spreads runs out so jobs that share an interval do not
+// always fire together.
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// Job is a periodic task.
+type Job struct {
+	// Name identifies the job in logs, config, and the state DB.
+	Name string
+	// Every is the interval between two runs.
+	Every time.Duration
+	// Jitter is the maximum random delay added to each scheduled run.
+	Jitter time.Duration
+	// Run performs the job. A returned error is logged and recorded; the job
+	// stays scheduled.
+	Run func(ctx context.Context) error
+}
+
+// RunStore persists the last run of each job. Implemented by [*state.Store].
+type RunStore interface {
+	GetJobRun(ctx context.Context, name string) (*state.JobRun, error)
+	RecordJobRun(ctx context.Context, run state.JobRun) error
+}
+
+// Scheduler runs jobs one at a time in a single goroutine. Create one with
+// [New].
+type Scheduler struct {
+	store  RunStore
+	log    *slog.Logger
+	clock  clock.Clock
+	jitter func(limit time.Duration) time.Duration
+	jobs   []Job
+}
+
+// Option configures optional Scheduler behaviour.
+type Option func(*Scheduler)
+
+// WithClock replaces the wall clock used for scheduling. Intended for tests.
+func WithClock(c clock.Clock) Option {
+	return func(s *Scheduler) { s.clock = c }
+}
+
+// New creates a Scheduler that persists job runs in store.
+func New(store RunStore, logger *slog.Logger, opts ...Option) *Scheduler {
+	s := &Scheduler{store: store, log: logger, clock: clock.Real(), jitter: randomJitter}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Add registers job. It must be called before [Scheduler.Run].
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Run executes jobs as they fall due until ctx is cancelled. A job that has
+// never run is due right away (plus jitter); otherwise it is due Every after
+// its persisted last run.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if len(s.jobs) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	now := s.clock.Now()
+	next := make([]time.Time, len(s.jobs))
+	for i, job := range s.jobs {
+		next[i] = now
+		last, err := s.store.GetJobRun(ctx, job.Name)
+		if err != nil {
+			s.log.Error("reading last job run", "job", job.Name, "error", err)
+		} else if last != nil {
+			next[i] = last.LastRunAt.Add(job.Every)
+		}
+		next[i] = next[i].Add(s.jitter(job.Jitter))
+		s.log.Debug("job scheduled", "job", job.Name, "next_run", next[i])
+	}
+
+	for {
+		i := earliest(next)
+		if wait := next[i].Sub(s.clock.Now()); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-s.clock.After(wait):
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		s.runJob(ctx, s.jobs[i])
+		next[i] = s.clock.Now().Add(s.jobs[i].Every + s.jitter(s.jobs[i].Jitter))
+	}
+}
+
+// runJob runs job once and records the outcome.
+func (s *Scheduler) runJob(ctx context.Context, job Job) {
+	start := s.clock.Now()
+	err := job.Run(ctx)
+	run := state.JobRun{Name: job.Name, LastRunAt: start.UTC(), Duration: s.clock.Now().Sub(start)}
+	if err != nil {
+		run.LastError = err.Error()
+		s.log.Error("job failed", "job", job.Name, "error", err)
+	} else {
+		s.log.Info("job finished", "job", job.Name, "duration", run.Duration)
+	}
+
+	if err := s.store.RecordJobRun(ctx, run); err != nil {
+		s.log.Error("recording job run", "job", job.Name, "error", err)
+	}
+}
+
+// earliest returns the index of the earliest time in ts.
+func earliest(ts []time.Time) int {
+	idx := 0
+	for i, t := range ts {
+		if t.Before(ts[idx]) {
+			idx = i
+		}
+	}
+	return idx
+}
+
+// randomJitter returns a random duration in [0, limit).
+func randomJitter(limit time.Duration) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	return rand.N(limit)
+}
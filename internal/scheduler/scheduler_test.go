@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+var start = time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+type memStore struct {
+	mu   sync.Mutex
+	runs map[string]state.JobRun
+}
+
+func newMemStore() *memStore { return &memStore{runs: make(map[string]state.JobRun)} }
+
+func (m *memStore) GetJobRun(_ context.Context, name string) (*state.JobRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run, ok := m.runs[name]
+	if !ok {
+		return nil, nil
+	}
+	return &run, nil
+}
+
+func (m *memStore) RecordJobRun(_ context.Context, run state.JobRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run.Runs = m.runs[run.Name].Runs + 1
+	m.runs[run.Name] = run
+	return nil
+}
+
+func (m *memStore) get(name string) state.JobRun {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.runs[name]
+}
+
+// startScheduler runs s until the test ends.
+func startScheduler(t *testing.T, s *Scheduler) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = s.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestScheduler_RunsDueJobsAndRecords(t *testing.T) {
+	clk := clock.NewFake(start)
+	store := newMemStore()
+	ran := make(chan struct{}, 4)
+
+	s := New(store, testLogger, WithClock(clk))
+	s.Add(Job{Name: "maintenance", Every: time.Hour, Run: func(context.Context) error {
+		ran <- struct{}{}
+		return errors.New("disk full")
+	}})
+	startScheduler(t, s)
+
+	<-ran // never run before → due immediately
+	clk.BlockUntil(1)
+	if got := store.get("maintenance"); got.Runs != 1 || got.LastError != "disk full" {
+		t.Fatalf("recorded run = %+v, want 1 run with error", got)
+	}
+
+	clk.Advance(time.Hour)
+	<-ran
+	clk.BlockUntil(1)
+	if got := store.get("maintenance"); got.Runs != 2 || !got.LastRunAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("recorded run = %+v, want second run at %v", got, start.Add(time.Hour))
+	}
+}
+
+func TestScheduler_ResumesFromPersistedRun(t *testing.T) {
+	clk := clock.NewFake(start)
+	store := newMemStore()
+	store.runs["maintenance"] = state.JobRun{Name: "maintenance", Runs: 1, LastRunAt: start.Add(-30 * time.Minute)}
+	ran := make(chan struct{}, 1)
+
+	s := New(store, testLogger, WithClock(clk))
+	s.Add(Job{Name: "maintenance", Every: time.Hour, Run: func(context.Context) error {
+		ran <- struct{}{}
+		return nil
+	}})
+	startScheduler(t, s)
+
+	clk.BlockUntil(1)
+	clk.Advance(29 * time.Minute)
+	select {
+	case <-ran:
+		t.Fatal("job ran before its persisted schedule was due")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	clk.Advance(time.Minute)
+	<-ran
+}
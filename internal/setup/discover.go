@@ -28,8 +28,30 @@ func (e HAEntity) String() string {
 
 // RemindersList represents a discovered Apple Reminders list.
 type RemindersList struct {
-	Title string
-	Count int
+	Title  string
+	Source string // account holding the list, such as "iCloud"
+	Count  int
+}
+
+// label returns the title of l, followed by its account if shared is set,
+// and its number of items.
+func (l RemindersList) label(shared bool) string {
+	if shared {
+		return fmt.Sprintf("%s in %s (%d items)", l.Title, l.Source, l.Count)
+	}
+	return fmt.Sprintf("%s (%d items)", l.Title, l.Count)
+}
+
+// sharedTitles returns the titles more than one of lists has, as in lists
+// of the same name in an iCloud and an Exchange account.
+func sharedTitles(lists []RemindersList) map[string]bool {
+	seen := make(map[string]bool, len(lists))
+	shared := make(map[string]bool)
+	for _, l := range lists {
+		shared[l.Title] = shared[l.Title] || seen[l.Title]
+		seen[l.Title] = true
+	}
+	return shared
 }
 
 // PingHA verifies connectivity with the Home Assistant instance using the
@@ -107,7 +129,7 @@ func DiscoverHATodoEntities(ctx context.Context, haURL, haToken string) ([]HAEnt
 }
 
 // DiscoverRemindersLists returns all Apple Reminders lists available on this
-// Mac. This triggers the macOS TCC permissions prompt on first use.
+// Mac, of every account. This triggers the macOS TCC permissions prompt on first use.
 func DiscoverRemindersLists(logger *slog.Logger) ([]RemindersList, error) {
 	client, err := ekreminders.New()
 	if err != nil {
@@ -124,8 +146,9 @@ func DiscoverRemindersLists(logger *slog.Logger) ([]RemindersList, error) {
 	var result []RemindersList
 	for _, l := range lists {
 		result = append(result, RemindersList{
-			Title: l.Title,
-			Count: l.Count,
+			Title:  l.Title,
+			Source: l.Source,
+			Count:  l.Count,
 		})
 	}
 	return result, nil
@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"strings"
 	"text/template"
+
+	"github.com/njoerd114/reminderrelay/internal/profile"
 )
 
 //go:embed plist.tmpl
@@ -21,14 +23,33 @@ const (
 	// InstallDir is the default install directory for the binary.
 	InstallDir = "/usr/local/bin"
 
-	// PlistLabel is the launchd job label.
+	// PlistLabel is the launchd job label of the default profile; see
+	// [JobLabel].
 	PlistLabel = "com.github.njoerd114.reminderrelay"
+
+	// LogFileName is the name of the log file the installed daemon writes
+	// and rotates in [LogDir].
+	LogFileName = "reminderrelay.log"
 )
 
 // plistData holds template values for the launchd plist.
 type plistData struct {
+	Label      string
 	BinaryPath string
-	HomeDir    string
+	Profile    string // empty for the default profile
+	LogDir     string
+	LogFile    string
+	DataHome   string // XDG_DATA_HOME, passed on so the daemon finds the same state DB
+}
+
+// JobLabel returns the launchd job label of the selected profile:
+// [PlistLabel], followed by "." and the profile name for profiles other than
+// the default.
+func JobLabel() string {
+	if profile.Name() == "" {
+		return PlistLabel
+	}
+	return PlistLabel + "." + profile.Name()
 }
 
 // BinaryInstallPath returns the full path to the installed binary.
@@ -36,14 +57,21 @@ func BinaryInstallPath() string {
 	return filepath.Join(InstallDir, BinaryName)
 }
 
-// PlistPath returns the launchd plist destination path.
+// PlistPath returns the launchd plist destination path of the selected
+// profile.
 func PlistPath(homeDir string) string {
-	return filepath.Join(homeDir, "Library", "LaunchAgents", PlistLabel+".plist")
+	return filepath.Join(homeDir, "Library", "LaunchAgents", JobLabel()+".plist")
 }
 
-// LogDir returns the log directory path.
+// LogDir returns the log directory path of the selected profile.
 func LogDir(homeDir string) string {
-	return filepath.Join(homeDir, "Library", "Logs", BinaryName)
+	return profile.Dir(filepath.Join(homeDir, "Library", "Logs", BinaryName))
+}
+
+// LogFile returns the path of the log file the installed daemon of the
+// selected profile writes.
+func LogFile(homeDir string) string {
+	return filepath.Join(LogDir(homeDir), LogFileName)
 }
 
 // InstallBinary copies the currently-running binary to /usr/local/bin.
@@ -88,8 +116,12 @@ func WritePlist(homeDir string) error {
 	}
 
 	data := plistData{
+		Label:      JobLabel(),
 		BinaryPath: BinaryInstallPath(),
-		HomeDir:    homeDir,
+		Profile:    profile.Name(),
+		LogDir:     LogDir(homeDir),
+		LogFile:    LogFile(homeDir),
+		DataHome:   os.Getenv("XDG_DATA_HOME"),
 	}
 
 	var buf bytes.Buffer
@@ -108,7 +140,7 @@ func WritePlist(homeDir string) error {
 	return nil
 }
 
-// CreateLogDir creates the ~/Library/Logs/reminderrelay/ directory.
+// CreateLogDir creates the log directory of the selected profile.
 func CreateLogDir(homeDir string) error {
 	dir := LogDir(homeDir)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
@@ -156,40 +188,44 @@ func RemovePlist(homeDir string) error {
 // RemoveBinary deletes the installed binary from /usr/local/bin.
 // Uses sudo if the directory is not writable.
 func RemoveBinary() error {
-	path := BinaryInstallPath()
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return nil // already gone
-	}
-
-	if isWritable(InstallDir) {
-		return os.Remove(path)
-	}
-
-	//nolint:gosec // sudo is intentional
-	cmd := exec.Command("sudo", "rm", "-f", path)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return removeFile(BinaryInstallPath())
 }
 
 // IsDaemonLoaded checks whether the launchd job is currently loaded.
 func IsDaemonLoaded() bool {
-	cmd := exec.Command("launchctl", "list", PlistLabel)
+	cmd := exec.Command("launchctl", "list", JobLabel())
 	return cmd.Run() == nil
 }
 
-// PurgeUserData removes config, state database, and log files.
-func PurgeUserData(homeDir string) error {
+// PurgeUserData removes the config, state directory stateDir, and log files
+// of the selected profile. The directories of other profiles, which the
+// default profile's contain, are kept.
+func PurgeUserData(homeDir, stateDir string) error {
 	dirs := []string{
-		filepath.Join(homeDir, ".config", BinaryName),
-		filepath.Join(homeDir, ".local", "share", BinaryName),
+		profile.Dir(filepath.Join(homeDir, ".config", BinaryName)),
+		stateDir,
 		LogDir(homeDir),
 	}
 	for _, dir := range dirs {
-		if err := os.RemoveAll(dir); err != nil {
-			return fmt.Errorf("removing %s: %w", dir, err)
+		if profile.Name() != "" {
+			if err := os.RemoveAll(dir); err != nil {
+				return fmt.Errorf("removing %s: %w", dir, err)
+			}
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("reading %s: %w", dir, err)
+		}
+		for _, e := range entries {
+			if e.IsDir() && profile.Valid(e.Name()) {
+				continue // another profile
+			}
+			if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+				return fmt.Errorf("removing %s: %w", filepath.Join(dir, e.Name()), err)
+			}
 		}
+		_ = os.Remove(dir) // only once no other profile is left in it
 	}
 	return nil
 }
@@ -0,0 +1,155 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/njoerd114/reminderrelay/internal/profile"
+)
+
+// legacyBinaryNames are binary names used by earlier or hand-rolled installs.
+var legacyBinaryNames = []string{BinaryName, "reminder-relay", "reminder_relay"}
+
+// legacyBinaryDirs returns the directories other than [InstallDir] where
+// earlier versions or `go install` may have placed a binary.
+func legacyBinaryDirs(homeDir string) []string {
+	return []string{
+		"/opt/homebrew/bin",
+		filepath.Join(homeDir, "go", "bin"),
+		filepath.Join(homeDir, ".local", "bin"),
+		filepath.Join(homeDir, "bin"),
+	}
+}
+
+// LegacyArtifact is a file left behind by an older install layout.
+type LegacyArtifact struct {
+	// Kind is "launchd agent" or "binary".
+	Kind string
+	Path string
+}
+
+// FindLegacyInstalls scans known locations for launchd agents and binaries
+// that belong to ReminderRelay but not to the current layout: plists with a
+// different label that run a reminderrelay binary, and binaries outside
+// [InstallDir]. The agents of other profiles and the running executable are
+// never reported.
+func FindLegacyInstalls(homeDir string) ([]LegacyArtifact, error) {
+	var found []LegacyArtifact
+
+	agentsDir := filepath.Join(homeDir, "Library", "LaunchAgents")
+	plists, err := filepath.Glob(filepath.Join(agentsDir, "*.plist"))
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", agentsDir, err)
+	}
+	for _, p := range plists {
+		if isProfileAgent(filepath.Base(p)) {
+			continue
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue // unreadable plists cannot be ours to clean up anyway
+		}
+		if mentionsReminderRelay(filepath.Base(p)) || mentionsReminderRelay(string(data)) {
+			found = append(found, LegacyArtifact{Kind: "launchd agent", Path: p})
+		}
+	}
+
+	self, _ := os.Executable()
+	if resolved, err := filepath.EvalSymlinks(self); err == nil {
+		self = resolved
+	}
+	for _, dir := range append([]string{InstallDir}, legacyBinaryDirs(homeDir)...) {
+		for _, name := range legacyBinaryNames {
+			p := filepath.Join(dir, name)
+			if p == BinaryInstallPath() || p == self {
+				continue
+			}
+			if info, err := os.Stat(p); err == nil && info.Mode().IsRegular() {
+				found = append(found, LegacyArtifact{Kind: "binary", Path: p})
+			}
+		}
+	}
+	return found, nil
+}
+
+// RemoveLegacyInstalls unloads and deletes the given artifacts, continuing
+// past failures. It returns the first error encountered.
+func RemoveLegacyInstalls(artifacts []LegacyArtifact) error {
+	var firstErr error
+	for _, a := range artifacts {
+		if a.Kind == "launchd agent" {
+			//nolint:gosec // path comes from FindLegacyInstalls
+			_ = exec.Command("launchctl", "unload", a.Path).Run() // may not be loaded
+		}
+		if err := removeFile(a.Path); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// isProfileAgent reports whether the plist file name belongs to the launchd
+// job of any profile; see [JobLabel].
+func isProfileAgent(name string) bool {
+	label, ok := strings.CutSuffix(name, ".plist")
+	if !ok {
+		return false
+	}
+	if label == PlistLabel {
+		return true
+	}
+	p, ok := strings.CutPrefix(label, PlistLabel+".")
+	return ok && profile.Valid(p)
+}
+
+// InstalledAgents returns the plist paths of the launchd jobs of every
+// profile, the selected one included.
+func InstalledAgents(homeDir string) ([]string, error) {
+	agentsDir := filepath.Join(homeDir, "Library", "LaunchAgents")
+	plists, err := filepath.Glob(filepath.Join(agentsDir, PlistLabel+"*.plist"))
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", agentsDir, err)
+	}
+	var agents []string
+	for _, p := range plists {
+		if isProfileAgent(filepath.Base(p)) {
+			agents = append(agents, p)
+		}
+	}
+	return agents, nil
+}
+
+// mentionsReminderRelay reports whether s refers to ReminderRelay, ignoring
+// case and word separators.
+func mentionsReminderRelay(s string) bool {
+	s = strings.ToLower(s)
+	s = strings.NewReplacer("-", "", "_", "", " ", "").Replace(s)
+	return strings.Contains(s, "reminderrelay")
+}
+
+// removeFile deletes path, using sudo if its directory is not writable.
+func removeFile(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil // already gone
+	}
+
+	if isWritable(filepath.Dir(path)) {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("removing %s: %w", path, err)
+		}
+		return nil
+	}
+
+	//nolint:gosec // sudo is intentional
+	cmd := exec.Command("sudo", "rm", "-f", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sudo rm %s: %w", path, err)
+	}
+	return nil
+}
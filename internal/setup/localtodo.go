@@ -0,0 +1,134 @@
+package setup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// localTodoWait bounds how long CreateLocalTodo waits for the entity of a new
+// list to appear.
+const localTodoWait = 15 * time.Second
+
+// flowResult is the minimal JSON shape of a config flow step reply.
+type flowResult struct {
+	FlowID string `json:"flow_id"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// CreateLocalTodo adds a Home Assistant "Local To-do" list called name and
+// returns the entity ID of its todo entity. The token must belong to an
+// admin user, since it drives the integration's config flow.
+func CreateLocalTodo(ctx context.Context, haURL, haToken, name string) (string, error) {
+	flow, err := postFlow(ctx, haURL, haToken, "/api/config/config_entries/flow",
+		map[string]any{"handler": "local_todo", "show_advanced_options": false})
+	if err != nil {
+		return "", fmt.Errorf("starting local_todo setup: %w", err)
+	}
+	if flow.Type != "form" {
+		return "", fmt.Errorf("starting local_todo setup: unexpected %s step %s", flow.Type, flow.Reason)
+	}
+	done, err := postFlow(ctx, haURL, haToken, "/api/config/config_entries/flow/"+flow.FlowID,
+		map[string]any{"todo_list_name": name})
+	if err != nil {
+		return "", fmt.Errorf("creating local_todo list %q: %w", name, err)
+	}
+	if done.Type != "create_entry" {
+		return "", fmt.Errorf("creating local_todo list %q: HA aborted the setup (%s)", name, done.Reason)
+	}
+
+	// The entity appears once HA has loaded the new config entry.
+	deadline := time.Now().Add(localTodoWait)
+	for {
+		entities, err := DiscoverHATodoEntities(ctx, haURL, haToken)
+		if err != nil {
+			return "", err
+		}
+		for _, e := range entities {
+			if e.FriendlyName == name {
+				return e.EntityID, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("local_todo list %q was created but its entity did not appear within %s", name, localTodoWait)
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// postFlow POSTs body to a config flow endpoint and decodes the reply.
+func postFlow(ctx context.Context, haURL, haToken, path string, body map[string]any) (*flowResult, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+	endpoint := strings.TrimRight(haURL, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+haToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", haURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("HA returned HTTP 401; creating lists needs an admin user's token")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HA returned HTTP %d", resp.StatusCode)
+	}
+	var out flowResult
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("parsing config flow response: %w", err)
+	}
+	return &out, nil
+}
+
+// EntityIDFor returns the todo entity ID Home Assistant derives from a list
+// called name, e.g. "todo.weekend_chores" for "Weekend Chores". Letters
+// outside ASCII are folded where a common transliteration exists and become
+// separators otherwise, so the result is a best guess for such names.
+func EntityIDFor(name string) string {
+	var b strings.Builder
+	sep := false
+	for _, r := range strings.ToLower(name) {
+		t, ok := transliterations[r]
+		if !ok && ((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')) {
+			t, ok = string(r), true
+		}
+		if !ok {
+			sep = true
+			continue
+		}
+		if sep && b.Len() > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteString(t)
+		sep = false
+	}
+	return "todo." + b.String()
+}
+
+// transliterations folds common accented letters the way HA's slugify does.
+var transliterations = map[rune]string{
+	'ä': "a", 'ö': "o", 'ü': "u", 'ß': "ss",
+	'à': "a", 'á': "a", 'â': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'ø': "o", 'œ': "oe",
+	'ù': "u", 'ú': "u", 'û': "u", 'ý': "y", 'ÿ': "y",
+}
@@ -0,0 +1,72 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+)
+
+// PickMapping discovers Reminders lists and HA todo entities and asks the
+// user to choose a single new list ↔ entity pair. Lists already present in
+// existing are not offered. When discovery fails on either side the user is
+// asked to type the name instead. source is the account of the list if
+// another account has a list of the same name, and empty otherwise.
+func (wiz *Wizard) PickMapping(ctx context.Context, haURL, haToken string, existing map[string]string) (listName, source, entityID string, err error) {
+	_, _ = fmt.Fprintf(wiz.w, "  Discovering Reminders lists (may trigger permissions prompt)...\n")
+	remLists, remErr := DiscoverRemindersLists(wiz.logger)
+	if remErr != nil {
+		wiz.logger.Warn("could not discover Reminders lists", "error", remErr)
+	}
+
+	shared := sharedTitles(remLists)
+	var (
+		unmapped   []RemindersList
+		remOptions []string
+	)
+	for _, l := range remLists {
+		if _, mapped := existing[l.Title]; !mapped {
+			unmapped = append(unmapped, l)
+			remOptions = append(remOptions, l.label(shared[l.Title]))
+		}
+	}
+
+	switch {
+	case remErr == nil && len(remOptions) == 0:
+		return "", "", "", fmt.Errorf("every Reminders list is already mapped")
+	case remErr == nil:
+		idx, err := wiz.prompt.Select("Reminders list", remOptions)
+		if err != nil {
+			return "", "", "", fmt.Errorf("selecting Reminders list: %w", err)
+		}
+		listName = unmapped[idx].Title
+		if shared[listName] {
+			source = unmapped[idx].Source
+		}
+	default:
+		_, _ = fmt.Fprintf(wiz.w, "  ⚠ Could not list Reminders — type the list name manually.\n")
+		listName = wiz.prompt.String("Reminders list", "")
+	}
+	if _, mapped := existing[listName]; mapped {
+		return "", "", "", fmt.Errorf("list %q is already mapped to %s", listName, existing[listName])
+	}
+
+	_, _ = fmt.Fprintf(wiz.w, "  Discovering HA todo entities...\n")
+	haEntities, haErr := DiscoverHATodoEntities(ctx, haURL, haToken)
+	if haErr != nil || len(haEntities) == 0 {
+		if haErr != nil {
+			wiz.logger.Warn("could not discover HA entities", "error", haErr)
+		}
+		_, _ = fmt.Fprintf(wiz.w, "  ⚠ Could not list HA entities — type the entity ID manually.\n")
+		entityID = wiz.prompt.String("HA entity ID (e.g. todo.shopping)", "")
+		return listName, source, entityID, nil
+	}
+
+	names := make([]string, len(haEntities))
+	for i, e := range haEntities {
+		names[i] = e.String()
+	}
+	idx, err := wiz.prompt.Select(fmt.Sprintf("HA entity for %q", listName), names)
+	if err != nil {
+		return "", "", "", fmt.Errorf("selecting HA entity: %w", err)
+	}
+	return listName, source, haEntities[idx].EntityID, nil
+}
@@ -0,0 +1,105 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/njoerd114/reminderrelay/internal/control"
+	"github.com/njoerd114/reminderrelay/internal/profile"
+)
+
+const (
+	// URLHandlerName is the name of the app that receives reminderrelay://
+	// URLs and hands them to 'reminderrelay open-url'.
+	URLHandlerName = "ReminderRelay URL Handler.app"
+
+	// urlHandlerID is the bundle identifier of the URL handler app.
+	urlHandlerID = PlistLabel + ".urlhandler"
+
+	// lsregister registers apps with Launch Services, which routes URLs by
+	// scheme to the app claiming it.
+	lsregister = "/System/Library/Frameworks/CoreServices.framework/Frameworks/LaunchServices.framework/Support/lsregister"
+)
+
+// URLHandlerPath returns where the URL handler app is installed.
+func URLHandlerPath(homeDir string) string {
+	return filepath.Join(homeDir, "Applications", URLHandlerName)
+}
+
+// urlHandlerScript returns the AppleScript of the URL handler app: every URL
+// opened is passed to binaryPath, with the selected profile, in the
+// background so the applet quits at once.
+func urlHandlerScript(binaryPath string) string {
+	return "on open location theURL\n" +
+		"\tdo shell script quoted form of " + appleScriptString(binaryPath) +
+		" & " + appleScriptString(profile.Flag()+" open-url ") +
+		" & quoted form of theURL & \" > /dev/null 2>&1 &\"\n" +
+		"end open location\n"
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return "\"" + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + "\""
+}
+
+// InstallURLHandler builds the URL handler app in ~/Applications, claiming
+// the reminderrelay:// scheme for binaryPath, and registers it with Launch
+// Services. An existing handler is replaced. The app has no Dock icon.
+func InstallURLHandler(homeDir, binaryPath string) error {
+	app := URLHandlerPath(homeDir)
+	if err := os.MkdirAll(filepath.Dir(app), 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(app), err)
+	}
+	if err := os.RemoveAll(app); err != nil {
+		return fmt.Errorf("removing old %s: %w", app, err)
+	}
+
+	src, err := os.CreateTemp("", "reminderrelay-urlhandler-*.applescript")
+	if err != nil {
+		return fmt.Errorf("creating handler script: %w", err)
+	}
+	defer func() { _ = os.Remove(src.Name()) }()
+	if _, err := src.WriteString(urlHandlerScript(binaryPath)); err != nil {
+		_ = src.Close()
+		return fmt.Errorf("writing handler script: %w", err)
+	}
+	if err := src.Close(); err != nil {
+		return fmt.Errorf("writing handler script: %w", err)
+	}
+
+	plist := filepath.Join(app, "Contents", "Info.plist")
+	urlTypes := fmt.Sprintf(`[{"CFBundleURLName":%q,"CFBundleURLSchemes":[%q]}]`, urlHandlerID, control.URLScheme)
+	steps := [][]string{
+		{"osacompile", "-o", app, src.Name()},
+		{"plutil", "-replace", "CFBundleIdentifier", "-string", urlHandlerID, plist},
+		{"plutil", "-replace", "CFBundleURLTypes", "-json", urlTypes, plist},
+		{"plutil", "-replace", "LSUIElement", "-bool", "true", plist},
+		{lsregister, "-f", app},
+	}
+	for _, step := range steps {
+		//nolint:gosec // fixed tools, user-controlled paths
+		cmd := exec.Command(step[0], step[1:]...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			_ = os.RemoveAll(app)
+			return fmt.Errorf("%s: %s: %w", filepath.Base(step[0]), strings.TrimSpace(string(output)), err)
+		}
+	}
+	return nil
+}
+
+// RemoveURLHandler unregisters and deletes the URL handler app. It is not an
+// error if it is not installed.
+func RemoveURLHandler(homeDir string) error {
+	app := URLHandlerPath(homeDir)
+	if _, err := os.Stat(app); os.IsNotExist(err) {
+		return nil
+	}
+	_ = exec.Command(lsregister, "-u", app).Run() // the app is removed either way
+	if err := os.RemoveAll(app); err != nil {
+		return fmt.Errorf("removing %s: %w", app, err)
+	}
+	return nil
+}
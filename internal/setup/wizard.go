@@ -6,9 +6,11 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/njoerd114/reminderrelay/internal/config"
+	"github.com/njoerd114/reminderrelay/internal/profile"
 )
 
 // Wizard guides the user through first-run configuration and installation.
@@ -64,7 +66,7 @@ func (wiz *Wizard) Run(ctx context.Context) error {
 	// Step 2: Discover & map lists.
 	_, _ = fmt.Fprintf(wiz.w, "Step 2/4 — List Mappings\n")
 
-	listMappings, err := wiz.buildListMappings(ctx, haURL, haToken)
+	listMappings, sources, err := wiz.buildListMappings(ctx, haURL, haToken)
 	if err != nil {
 		return err
 	}
@@ -84,10 +86,11 @@ func (wiz *Wizard) Run(ctx context.Context) error {
 	_, _ = fmt.Fprintf(wiz.w, "Step 4/4 — Save Configuration\n")
 
 	cfg := &config.Config{
-		HAURL:        haURL,
-		HAToken:      haToken,
-		PollInterval: pollInterval,
-		ListMappings: listMappings,
+		HAURL:            haURL,
+		HAToken:          haToken,
+		PollInterval:     pollInterval,
+		ListMappings:     listMappings,
+		RemindersSources: sources,
 	}
 
 	if err := cfg.Write(cfgPath); err != nil {
@@ -99,18 +102,20 @@ func (wiz *Wizard) Run(ctx context.Context) error {
 }
 
 // buildListMappings discovers Reminders lists and HA entities, then lets the
-// user pair them interactively.
-func (wiz *Wizard) buildListMappings(ctx context.Context, haURL, haToken string) (map[string]string, error) {
+// user pair them interactively. It also returns the account of every mapped
+// list whose name another account uses too.
+func (wiz *Wizard) buildListMappings(ctx context.Context, haURL, haToken string) (map[string]string, map[string]string, error) {
 	// Discover Reminders lists.
 	_, _ = fmt.Fprintf(wiz.w, "  Discovering Reminders lists (may trigger permissions prompt)...\n")
 	remLists, remErr := DiscoverRemindersLists(wiz.logger)
+	shared := sharedTitles(remLists)
 	if remErr != nil {
 		wiz.logger.Warn("could not discover Reminders lists", "error", remErr)
 		_, _ = fmt.Fprintf(wiz.w, "  ⚠ Could not list Reminders — you can type list names manually.\n")
 	} else {
 		_, _ = fmt.Fprintf(wiz.w, "  Found %d Reminders list(s):\n", len(remLists))
 		for _, l := range remLists {
-			_, _ = fmt.Fprintf(wiz.w, "    • %s (%d items)\n", l.Title, l.Count)
+			_, _ = fmt.Fprintf(wiz.w, "    • %s\n", l.label(shared[l.Title]))
 		}
 	}
 	_, _ = fmt.Fprintf(wiz.w, "\n")
@@ -133,6 +138,7 @@ func (wiz *Wizard) buildListMappings(ctx context.Context, haURL, haToken string)
 	_, _ = fmt.Fprintf(wiz.w, "  Map Reminders lists to HA entities (empty Reminders name to finish):\n\n")
 
 	mappings := make(map[string]string)
+	sources := make(map[string]string)
 	haEntityNames := make([]string, len(haEntities))
 	for i, e := range haEntities {
 		haEntityNames[i] = e.String()
@@ -144,18 +150,21 @@ func (wiz *Wizard) buildListMappings(ctx context.Context, haURL, haToken string)
 			// Show selection from discovered lists.
 			remOptions := make([]string, len(remLists))
 			for i, l := range remLists {
-				remOptions[i] = fmt.Sprintf("%s (%d items)", l.Title, l.Count)
+				remOptions[i] = l.label(shared[l.Title])
 			}
 			remOptions = append(remOptions, "(done — finish mapping)")
 
 			idx, err := wiz.prompt.Select("Reminders list", remOptions)
 			if err != nil {
-				return nil, fmt.Errorf("selecting Reminders list: %w", err)
+				return nil, nil, fmt.Errorf("selecting Reminders list: %w", err)
 			}
 			if idx == len(remOptions)-1 {
 				break // done
 			}
 			remName = remLists[idx].Title
+			if shared[remName] {
+				sources[remName] = remLists[idx].Source
+			}
 		} else {
 			remName = wiz.prompt.String("Reminders list (empty to finish)", "")
 			if remName == "" {
@@ -164,12 +173,21 @@ func (wiz *Wizard) buildListMappings(ctx context.Context, haURL, haToken string)
 		}
 
 		var entityID string
-		if haErr == nil && len(haEntities) > 0 {
-			idx, err := wiz.prompt.Select(fmt.Sprintf("HA entity for %q", remName), haEntityNames)
+		if haErr == nil {
+			options := append(slices.Clone(haEntityNames), fmt.Sprintf("(new Local To-do list %q)", remName))
+			idx, err := wiz.prompt.Select(fmt.Sprintf("HA entity for %q", remName), options)
 			if err != nil {
-				return nil, fmt.Errorf("selecting HA entity: %w", err)
+				return nil, nil, fmt.Errorf("selecting HA entity: %w", err)
+			}
+			if idx < len(haEntities) {
+				entityID = haEntities[idx].EntityID
+			} else {
+				_, _ = fmt.Fprintf(wiz.w, "  Creating Local To-do list %q...\n", remName)
+				if entityID, err = CreateLocalTodo(ctx, haURL, haToken, remName); err != nil {
+					_, _ = fmt.Fprintf(wiz.w, "  ⚠ Could not create the list: %v\n\n", err)
+					continue
+				}
 			}
-			entityID = haEntities[idx].EntityID
 		} else {
 			entityID = wiz.prompt.String("HA entity ID (e.g. todo.shopping)", "")
 			if entityID == "" {
@@ -182,18 +200,18 @@ func (wiz *Wizard) buildListMappings(ctx context.Context, haURL, haToken string)
 	}
 
 	if len(mappings) == 0 {
-		return nil, fmt.Errorf("at least one list mapping is required")
+		return nil, nil, fmt.Errorf("at least one list mapping is required")
 	}
 	_, _ = fmt.Fprintf(wiz.w, "\n")
-	return mappings, nil
+	return mappings, sources, nil
 }
 
 // offerDaemonInstall asks the user whether to install as a background daemon.
 func (wiz *Wizard) offerDaemonInstall(_ context.Context) error {
 	if !wiz.prompt.Confirm("Install as background daemon (starts on login)?", true) {
 		_, _ = fmt.Fprintf(wiz.w, "\n  Skipping daemon install.\n")
-		_, _ = fmt.Fprintf(wiz.w, "  You can run manually with: reminderrelay daemon\n")
-		_, _ = fmt.Fprintf(wiz.w, "  Or install later with:     reminderrelay setup\n\n")
+		_, _ = fmt.Fprintf(wiz.w, "  You can run manually with: reminderrelay%s daemon\n", profile.Flag())
+		_, _ = fmt.Fprintf(wiz.w, "  Or install later with:     reminderrelay%s setup\n\n", profile.Flag())
 		return nil
 	}
 
@@ -233,8 +251,8 @@ func (wiz *Wizard) offerDaemonInstall(_ context.Context) error {
 	_, _ = fmt.Fprintf(wiz.w, "\nSetup complete! ReminderRelay is syncing in the background.\n")
 	_, _ = fmt.Fprintf(wiz.w, "  Config:  %s\n", cfgPath)
 	_, _ = fmt.Fprintf(wiz.w, "  Logs:    %s\n", LogDir(homeDir))
-	_, _ = fmt.Fprintf(wiz.w, "  Status:  reminderrelay status\n")
-	_, _ = fmt.Fprintf(wiz.w, "  Remove:  reminderrelay uninstall\n\n")
+	_, _ = fmt.Fprintf(wiz.w, "  Status:  reminderrelay%s status\n", profile.Flag())
+	_, _ = fmt.Fprintf(wiz.w, "  Remove:  reminderrelay%s uninstall\n\n", profile.Flag())
 
 	return nil
 }
@@ -0,0 +1,72 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockFile is the name of the lock file in the state directory.
+const lockFile = "daemon.lock"
+
+// LockedError is returned by [Lock] while another process holds the lock.
+type LockedError struct {
+	PID int // of the process holding the lock; 0 if unknown
+}
+
+func (e *LockedError) Error() string {
+	if e.PID == 0 {
+		return "state directory is locked by another process"
+	}
+	return fmt.Sprintf("state directory is locked by process %d", e.PID)
+}
+
+// Lock takes an exclusive lock on the directory of the state database at
+// dbPath, so that only one process syncs it at a time. The lock is held
+// until release is called or the process exits, however it exits. While
+// another process holds it, Lock returns a [*LockedError].
+func Lock(dbPath string) (release func(), err error) {
+	dir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating state directory: %w", err)
+	}
+	path := filepath.Join(dir, lockFile)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock file: %w", err)
+	}
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		pid := lockHolder(f)
+		_ = f.Close()
+		if errors.Is(err, unix.EWOULDBLOCK) {
+			return nil, &LockedError{PID: pid}
+		}
+		return nil, fmt.Errorf("locking %q: %w", path, err)
+	}
+
+	// The PID is for the error message of the next process only; the lock
+	// is the flock, which the kernel drops when this process exits.
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return func() {
+		_ = f.Truncate(0)
+		_ = f.Close()
+	}, nil
+}
+
+// lockHolder returns the PID recorded in the lock file f, or 0.
+func lockHolder(f *os.File) int {
+	b, err := io.ReadAll(io.LimitReader(f, 32))
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(b)))
+	return pid
+}
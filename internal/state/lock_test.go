@@ -0,0 +1,31 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLock_SecondHolderRefused(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state", "state.db")
+	release, err := Lock(dbPath)
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+
+	var locked *LockedError
+	if _, err := Lock(dbPath); !errors.As(err, &locked) {
+		t.Fatalf("second Lock = %v, want *LockedError", err)
+	}
+	if locked.PID != os.Getpid() {
+		t.Errorf("LockedError.PID = %d, want %d", locked.PID, os.Getpid())
+	}
+
+	release()
+	again, err := Lock(dbPath)
+	if err != nil {
+		t.Fatalf("Lock after release: %v", err)
+	}
+	again()
+}
@@ -0,0 +1,172 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Stats describes how the database uses its space.
+type Stats struct {
+	Size      int64 // bytes of the database file
+	Free      int64 // bytes of unused pages, which [Store.Vacuum] gives back
+	WALSize   int64 // bytes of the write-ahead log not yet folded back
+	TableRows map[string]int
+	ListItems map[string]int // tracked items per Reminders list
+}
+
+// Stats reports the size of the database and the rows in each table. It
+// covers the whole database, not just the store's instance.
+func (s *Store) Stats(ctx context.Context) (*Stats, error) {
+	var pageSize, pages, free int64
+	for _, p := range []struct {
+		pragma string
+		dst    *int64
+	}{
+		{"page_size", &pageSize},
+		{"page_count", &pages},
+		{"freelist_count", &free},
+	} {
+		if err := s.db.QueryRowContext(ctx, "PRAGMA "+p.pragma).Scan(p.dst); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", p.pragma, err)
+		}
+	}
+	st := &Stats{
+		Size:      pages * pageSize,
+		Free:      free * pageSize,
+		TableRows: make(map[string]int),
+		ListItems: make(map[string]int),
+	}
+	if path := s.path(ctx); path != "" {
+		if info, err := os.Stat(path + "-wal"); err == nil {
+			st.WALSize = info.Size()
+		}
+	}
+
+	tables, err := s.tables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		var n int
+		// Table names come from sqlite_master, not from the caller.
+		q := `SELECT COUNT(*) FROM "` + strings.ReplaceAll(table, `"`, `""`) + `"`
+		if err := s.db.QueryRowContext(ctx, q).Scan(&n); err != nil {
+			return nil, fmt.Errorf("counting rows of %s: %w", table, err)
+		}
+		st.TableRows[table] = n
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT list_name, COUNT(*) FROM sync_items GROUP BY list_name`)
+	if err != nil {
+		return nil, fmt.Errorf("counting items per list: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var list string
+		var n int
+		if err := rows.Scan(&list, &n); err != nil {
+			return nil, fmt.Errorf("counting items per list: %w", err)
+		}
+		st.ListItems[list] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("counting items per list: %w", err)
+	}
+	return st, nil
+}
+
+// tables returns the names of the database's tables, SQLite's own left out.
+func (s *Store) tables(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("listing tables: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("listing tables: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// path returns the file of the database, or "" if it cannot be told.
+func (s *Store) path(ctx context.Context) string {
+	var seq int
+	var name, file string
+	if err := s.db.QueryRowContext(ctx, `PRAGMA database_list`).Scan(&seq, &name, &file); err != nil {
+		return ""
+	}
+	return file
+}
+
+// Vacuum rebuilds the database file without its unused pages and truncates
+// the write-ahead log. It needs the database to itself for its duration.
+func (s *Store) Vacuum(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `VACUUM`); err != nil {
+		return fmt.Errorf("vacuuming database: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return fmt.Errorf("checkpointing write-ahead log: %w", err)
+	}
+	return nil
+}
+
+// Analyze refreshes the statistics the query planner uses for every table
+// and index, unlike [Store.Maintain], which refreshes only those SQLite
+// judges stale.
+func (s *Store) Analyze(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `ANALYZE`); err != nil {
+		return fmt.Errorf("analyzing database: %w", err)
+	}
+	return nil
+}
+
+// IntegrityCheck runs SQLite's full integrity check, which unlike
+// [Store.Check] also verifies indexes against their tables, and reports a
+// failure as [ErrCorrupt].
+func (s *Store) IntegrityCheck(ctx context.Context) error {
+	return s.check(ctx, "integrity_check")
+}
+
+// PruneStaleWAL removes write-ahead log and shared-memory files next to the
+// state database at dbPath and in its backup directory whose database is
+// gone, such as those left by a database moved aside by [Restore]. It
+// returns the paths it removed.
+func PruneStaleWAL(dbPath string) ([]string, error) {
+	var removed []string
+	for _, dir := range []string{filepath.Dir(dbPath), BackupDir(dbPath)} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return removed, fmt.Errorf("reading %s: %w", dir, err)
+		}
+		for _, e := range entries {
+			db, ok := strings.CutSuffix(e.Name(), "-wal")
+			if !ok {
+				db, ok = strings.CutSuffix(e.Name(), "-shm")
+			}
+			if !ok || e.IsDir() {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(dir, db)); !os.IsNotExist(err) {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if err := os.Remove(path); err != nil {
+				return removed, fmt.Errorf("removing %s: %w", path, err)
+			}
+			removed = append(removed, path)
+		}
+	}
+	return removed, nil
+}
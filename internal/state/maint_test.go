@@ -0,0 +1,118 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestStats_CountsRowsPerTableAndList(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	for i, list := range []string{"Shopping", "Shopping", "Work"} {
+		item := sampleItem()
+		item.RemindersUID = fmt.Sprintf("rem-%d", i)
+		item.HAUID = fmt.Sprintf("ha-%d", i)
+		item.ListName = list
+		if err := s.UpsertItem(ctx, item); err != nil {
+			t.Fatalf("UpsertItem: %v", err)
+		}
+	}
+
+	st, err := s.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if st.Size == 0 {
+		t.Error("Stats().Size = 0, want the database size")
+	}
+	if got := st.TableRows["sync_items"]; got != 3 {
+		t.Errorf("TableRows[sync_items] = %d, want 3", got)
+	}
+	if _, ok := st.TableRows["outbox"]; !ok {
+		t.Error("TableRows lacks the empty outbox table")
+	}
+	if st.ListItems["Shopping"] != 2 || st.ListItems["Work"] != 1 {
+		t.Errorf("ListItems = %v, want Shopping: 2, Work: 1", st.ListItems)
+	}
+}
+
+func TestVacuum_ReclaimsFreePages(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	for i := range 200 {
+		item := sampleItem()
+		item.RemindersUID = fmt.Sprintf("rem-%d", i)
+		item.HAUID = fmt.Sprintf("ha-%d", i)
+		item.Title = strings.Repeat("x", 500)
+		if err := s.UpsertItem(ctx, item); err != nil {
+			t.Fatalf("UpsertItem: %v", err)
+		}
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM sync_items`); err != nil {
+		t.Fatalf("deleting items: %v", err)
+	}
+	if err := s.Maintain(ctx); err != nil {
+		t.Fatalf("Maintain: %v", err)
+	}
+	before, err := s.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if before.Free == 0 {
+		t.Fatal("no free pages after deleting every item")
+	}
+
+	if err := s.Vacuum(ctx); err != nil {
+		t.Fatalf("Vacuum: %v", err)
+	}
+	if err := s.IntegrityCheck(ctx); err != nil {
+		t.Fatalf("IntegrityCheck after Vacuum: %v", err)
+	}
+	after, err := s.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if after.Free != 0 || after.Size >= before.Size {
+		t.Errorf("after Vacuum: size %d, free %d; want below %d, 0", after.Size, after.Free, before.Size)
+	}
+}
+
+func TestPruneStaleWAL(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "state.db")
+	backups := BackupDir(dbPath)
+	if err := os.MkdirAll(backups, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{
+		"state.db", "state.db-wal", "state.db-shm", // live
+		"state.db.replaced-1-wal", "state.db.replaced-1-shm", // stale
+		"backups/state-1.db", "backups/state-1.db-wal", // live
+		"backups/state-2.db-shm", // stale
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := PruneStaleWAL(dbPath)
+	if err != nil {
+		t.Fatalf("PruneStaleWAL: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "state.db.replaced-1-shm"),
+		filepath.Join(dir, "state.db.replaced-1-wal"),
+		filepath.Join(backups, "state-2.db-shm"),
+	}
+	if !slices.Equal(removed, want) {
+		t.Errorf("PruneStaleWAL removed %v, want %v", removed, want)
+	}
+	if _, err := os.Stat(dbPath + "-wal"); err != nil {
+		t.Errorf("live WAL removed: %v", err)
+	}
+}
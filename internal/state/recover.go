@@ -0,0 +1,240 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// ErrCorrupt reports that the state database failed an integrity check.
+var ErrCorrupt = errors.New("state database is corrupted")
+
+// backupLayout names backup files so they sort chronologically. Milliseconds
+// keep a backup at startup apart from one before the first pass.
+const backupLayout = "20060102-150405.000"
+
+// IsCorrupt reports whether err was caused by a damaged database file, as
+// opposed to a transient problem such as a locked database.
+func IsCorrupt(err error) bool {
+	if errors.Is(err, ErrCorrupt) {
+		return true
+	}
+	var se sqlite3.Error
+	return errors.As(err, &se) && (se.Code == sqlite3.ErrCorrupt || se.Code == sqlite3.ErrNotADB)
+}
+
+// Check runs SQLite's quick integrity check and reports a failure as
+// [ErrCorrupt].
+func (s *Store) Check(ctx context.Context) error {
+	return s.check(ctx, "quick_check")
+}
+
+// check runs the integrity check pragma and reports a failure as
+// [ErrCorrupt].
+func (s *Store) check(ctx context.Context, pragma string) error {
+	rows, err := s.db.QueryContext(ctx, "PRAGMA "+pragma)
+	if err != nil {
+		return fmt.Errorf("checking integrity: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var problems []string
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			return fmt.Errorf("reading integrity check: %w", err)
+		}
+		if msg != "ok" && len(problems) < 3 {
+			problems = append(problems, msg)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("checking integrity: %w", err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrCorrupt, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// --- Backups -----------------------------------------------------------------
+
+// BackupDir returns the directory for backups of the state database at
+// dbPath: a backups directory next to it.
+func BackupDir(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), "backups")
+}
+
+// Backup writes a consistent copy of the database to dir and removes all but
+// the newest keep backups. It returns the path of the new backup.
+func (s *Store) Backup(ctx context.Context, dir string, keep int) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("creating backup directory: %w", err)
+	}
+	path := filepath.Join(dir, "state-"+time.Now().UTC().Format(backupLayout)+".db")
+	if _, err := s.db.ExecContext(ctx, `VACUUM INTO ?`, path); err != nil {
+		return "", fmt.Errorf("writing backup %q: %w", path, err)
+	}
+
+	backups, err := Backups(dir)
+	if err != nil {
+		return path, err
+	}
+	for i := keep; i < len(backups); i++ {
+		if err := os.Remove(backups[i]); err != nil {
+			return path, fmt.Errorf("removing old backup: %w", err)
+		}
+	}
+	return path, nil
+}
+
+// Backups returns the backups in dir, newest first.
+func Backups(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "state-*.db"))
+	if err != nil {
+		return nil, fmt.Errorf("listing backups: %w", err)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+	return paths, nil
+}
+
+// Restore replaces the database at path with backup, which must pass an
+// integrity check. The database it replaces is moved aside, and its path
+// returned, so a restore can be undone; it is put back if the restore fails.
+// No [Store] may have path open.
+func Restore(ctx context.Context, backup, path string) (string, error) {
+	replaced := path + ".replaced-" + time.Now().UTC().Format(backupLayout)
+	moved := make([]string, 0, 3)
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		err := os.Rename(path+suffix, replaced+suffix)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			putBack(replaced, path, moved)
+			return "", fmt.Errorf("moving current database aside: %w", err)
+		}
+		moved = append(moved, suffix)
+	}
+
+	if err := restoreBackup(ctx, backup, path); err != nil {
+		putBack(replaced, path, moved)
+		return "", fmt.Errorf("restoring %q: %w", backup, err)
+	}
+	if len(moved) == 0 {
+		return "", nil
+	}
+	return replaced, nil
+}
+
+// putBack moves the files of a database moved aside to replaced back to path.
+func putBack(replaced, path string, suffixes []string) {
+	for _, suffix := range suffixes {
+		_ = os.Rename(replaced+suffix, path+suffix)
+	}
+}
+
+// --- Recovery ----------------------------------------------------------------
+
+// Recovery describes how [Recover] replaced a corrupted database.
+type Recovery struct {
+	// CorruptPath is where the damaged database was moved, for inspection.
+	CorruptPath string
+	// RestoredFrom is the backup that replaced it; empty when no usable
+	// backup existed and the state was rebuilt empty.
+	RestoredFrom string
+	// BackupTime is when the restored backup was written.
+	BackupTime time.Time
+	// HeldLists are the lists put in shadow mode after a rebuild.
+	HeldLists []string
+}
+
+// Recover moves the corrupted database at path aside and replaces it with the
+// newest backup in backupDir that passes an integrity check. Without one, or
+// when backupDir is empty, it creates an empty database and holds lists in
+// shadow mode, so nothing is written to either side until the user has
+// reviewed a pass and promoted them.
+func Recover(ctx context.Context, path, backupDir string, lists []string) (*Recovery, error) {
+	rec := &Recovery{CorruptPath: path + ".corrupt-" + time.Now().UTC().Format(backupLayout)}
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if err := os.Rename(path+suffix, rec.CorruptPath+suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("moving corrupted database aside: %w", err)
+		}
+	}
+
+	if backupDir != "" {
+		backups, err := Backups(backupDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, backup := range backups {
+			if restoreBackup(ctx, backup, path) == nil {
+				rec.RestoredFrom = backup
+				if info, err := os.Stat(backup); err == nil {
+					rec.BackupTime = info.ModTime()
+				}
+				return rec, nil
+			}
+		}
+	}
+
+	store, err := Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating new state database: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+	if err := store.HoldLists(ctx, lists); err != nil {
+		return nil, err
+	}
+	rec.HeldLists = append([]string(nil), lists...)
+	sort.Strings(rec.HeldLists)
+	return rec, nil
+}
+
+// restoreBackup copies backup to path and verifies the copy, removing it
+// again if it is unusable.
+func restoreBackup(ctx context.Context, backup, path string) (err error) {
+	defer func() {
+		if err != nil {
+			for _, suffix := range []string{"", "-wal", "-shm"} {
+				_ = os.Remove(path + suffix)
+			}
+		}
+	}()
+	if err := copyFile(backup, path); err != nil {
+		return err
+	}
+	store, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+	return store.Check(ctx)
+}
+
+// copyFile copies src to a new file dst, readable by the owner only.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("opening %q: %w", src, err)
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("creating %q: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("copying %q: %w", src, err)
+	}
+	return out.Close()
+}
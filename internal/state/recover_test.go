@@ -0,0 +1,178 @@
+package state
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// corrupt overwrites the database at path with garbage.
+func corrupt(t *testing.T, path string) {
+	t.Helper()
+	for _, suffix := range []string{"-wal", "-shm"} {
+		_ = os.Remove(path + suffix)
+	}
+	if err := os.WriteFile(path, []byte("definitely not an SQLite database, just garbage bytes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecover_RestoresNewestBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.db")
+	backups := filepath.Join(dir, "backups")
+	ctx := context.Background()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := s.UpsertItem(ctx, sampleItem()); err != nil {
+		t.Fatalf("UpsertItem: %v", err)
+	}
+	if _, err := s.Backup(ctx, backups, 3); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	_ = s.Close()
+
+	corrupt(t, path)
+	if _, err := Open(path); !IsCorrupt(err) {
+		t.Fatalf("Open(corrupted) error = %v, want IsCorrupt", err)
+	}
+
+	rec, err := Recover(ctx, path, backups, []string{"Shopping"})
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if rec.RestoredFrom == "" || len(rec.HeldLists) != 0 {
+		t.Errorf("Recovery = %+v, want restored from backup without held lists", rec)
+	}
+	if _, err := os.Stat(rec.CorruptPath); err != nil {
+		t.Errorf("corrupted database not kept: %v", err)
+	}
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open(restored): %v", err)
+	}
+	defer func() { _ = s.Close() }()
+	if got, _ := s.GetItemByRemindersUID(ctx, sampleItem().RemindersUID); got == nil {
+		t.Error("restored database lost the backed-up item")
+	}
+}
+
+func TestRecover_RebuildsAndHoldsListsWithoutBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.db")
+	ctx := context.Background()
+
+	corrupt(t, path)
+	rec, err := Recover(ctx, path, filepath.Join(dir, "none"), []string{"Work", "Shopping"})
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if rec.RestoredFrom != "" || len(rec.HeldLists) != 2 {
+		t.Errorf("Recovery = %+v, want rebuild holding both lists", rec)
+	}
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open(rebuilt): %v", err)
+	}
+	defer func() { _ = s.Close() }()
+	held, err := s.HeldLists(ctx)
+	if err != nil || len(held) != 2 || held[0] != "Shopping" {
+		t.Errorf("HeldLists = %v, %v; want Shopping and Work", held, err)
+	}
+	if err := s.PromoteShadowList(ctx, "Shopping"); err != nil {
+		t.Fatal(err)
+	}
+	if held, _ := s.HeldLists(ctx); len(held) != 1 {
+		t.Errorf("HeldLists after promote = %v, want Work only", held)
+	}
+}
+
+func TestBackup_KeepsNewest(t *testing.T) {
+	s := openTestStore(t)
+	dir := t.TempDir()
+	for _, name := range []string{"state-20250101-000000.db", "state-20250102-000000.db"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path, err := s.Backup(context.Background(), dir, 2)
+	if err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	got, _ := Backups(dir)
+	if len(got) != 2 || got[0] != path || filepath.Base(got[1]) != "state-20250102-000000.db" {
+		t.Errorf("Backups = %v, want the new backup and the newest old one", got)
+	}
+}
+
+func TestRestore_ReplacesDatabase(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.db")
+	backups := filepath.Join(dir, "backups")
+	ctx := context.Background()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	backup, err := s.Backup(ctx, backups, 3)
+	if err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	if err := s.UpsertItem(ctx, sampleItem()); err != nil {
+		t.Fatalf("UpsertItem: %v", err)
+	}
+	_ = s.Close()
+
+	replaced, err := Restore(ctx, backup, path)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if _, err := os.Stat(replaced); err != nil {
+		t.Errorf("replaced database not kept: %v", err)
+	}
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open(restored): %v", err)
+	}
+	defer func() { _ = s.Close() }()
+	if got, _ := s.GetItemByRemindersUID(ctx, sampleItem().RemindersUID); got != nil {
+		t.Error("restored database has an item added after the backup")
+	}
+}
+
+func TestRestore_KeepsDatabaseWhenBackupIsBad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.db")
+	bad := filepath.Join(dir, "state-bad.db")
+	ctx := context.Background()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := s.UpsertItem(ctx, sampleItem()); err != nil {
+		t.Fatalf("UpsertItem: %v", err)
+	}
+	_ = s.Close()
+	corrupt(t, bad)
+
+	if _, err := Restore(ctx, bad, path); err == nil {
+		t.Fatal("Restore(corrupted backup) succeeded, want error")
+	}
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open after failed restore: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+	if got, _ := s.GetItemByRemindersUID(ctx, sampleItem().RemindersUID); got == nil {
+		t.Error("failed restore lost the current database")
+	}
+}
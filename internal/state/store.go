@@ -14,11 +14,19 @@ import (
 	"time"
 
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/profile"
 )
 
+// schemaVersion is stored in PRAGMA user_version. Databases created before
+// versioning report 0 and are upgraded by [migrate].
+const schemaVersion = 15
+
 const schema = `
 CREATE TABLE IF NOT EXISTS sync_items (
     id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+    instance           TEXT    NOT NULL DEFAULT '',
     reminders_uid      TEXT    NOT NULL DEFAULT '',
     ha_uid             TEXT    NOT NULL DEFAULT '',
     list_name          TEXT    NOT NULL,
@@ -26,14 +34,202 @@ CREATE TABLE IF NOT EXISTS sync_items (
     last_sync_hash     TEXT    NOT NULL DEFAULT '',
     reminders_modified TEXT    NOT NULL DEFAULT '',
     ha_modified        TEXT    NOT NULL DEFAULT '',
-    last_synced_at     TEXT    NOT NULL DEFAULT ''
+    last_synced_at     TEXT    NOT NULL DEFAULT '',
+    has_base           INTEGER NOT NULL DEFAULT 0,
+    base_description   TEXT    NOT NULL DEFAULT '',
+    base_due           TEXT    NOT NULL DEFAULT '',
+    base_priority      INTEGER NOT NULL DEFAULT 0,
+    base_completed     INTEGER NOT NULL DEFAULT 0,
+    pinned             TEXT    NOT NULL DEFAULT '',
+    ha_seen_hash       TEXT    NOT NULL DEFAULT '',
+    base_raw_priority  INTEGER NOT NULL DEFAULT 0,
+    base_alert         TEXT    NOT NULL DEFAULT '',
+    base_completed_at  TEXT    NOT NULL DEFAULT '',
+    base_url           TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reminders_uid ON sync_items (instance, reminders_uid) WHERE reminders_uid != '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ha_uid         ON sync_items (instance, ha_uid)         WHERE ha_uid != '';
+CREATE INDEX        IF NOT EXISTS idx_list_name      ON sync_items (instance, list_name);
+
+CREATE TABLE IF NOT EXISTS shadow_lists (
+    instance     TEXT    NOT NULL DEFAULT '',
+    list_name    TEXT    NOT NULL,
+    passes       INTEGER NOT NULL DEFAULT 0,
+    would_create INTEGER NOT NULL DEFAULT 0,
+    would_update INTEGER NOT NULL DEFAULT 0,
+    would_delete INTEGER NOT NULL DEFAULT 0,
+    last_pass_at TEXT    NOT NULL DEFAULT '',
+    promoted     INTEGER NOT NULL DEFAULT 0,
+    held         INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (instance, list_name)
+);
+` + jobRunsSchema + listIDsSchema + outboxSchema + intentsSchema + syncRunsSchema + accountsSchema
+
+const jobRunsSchema = `
+CREATE TABLE IF NOT EXISTS job_runs (
+    instance    TEXT    NOT NULL DEFAULT '',
+    name        TEXT    NOT NULL,
+    runs        INTEGER NOT NULL DEFAULT 0,
+    last_run_at TEXT    NOT NULL DEFAULT '',
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    last_error  TEXT    NOT NULL DEFAULT '',
+    PRIMARY KEY (instance, name)
+);
+`
+
+// listIDsSchema records the EventKit calendar identifier of each list, so a
+// renamed Reminders list can be recognised by its identifier.
+const listIDsSchema = `
+CREATE TABLE IF NOT EXISTS list_ids (
+    instance  TEXT NOT NULL DEFAULT '',
+    list_name TEXT NOT NULL,
+    list_id   TEXT NOT NULL,
+    PRIMARY KEY (instance, list_name)
+);
+`
+
+// outboxSchema queues the writes to the target side of a list mapping that
+// failed, so they are replayed in order; see [Store.QueueOutbound].
+const outboxSchema = `
+CREATE TABLE IF NOT EXISTS outbox (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    instance      TEXT    NOT NULL DEFAULT '',
+    list_name     TEXT    NOT NULL,
+    reminders_uid TEXT    NOT NULL DEFAULT '',
+    ha_uid        TEXT    NOT NULL DEFAULT '',
+    action        TEXT    NOT NULL,
+    title         TEXT    NOT NULL DEFAULT '',
+    queued_at     TEXT    NOT NULL DEFAULT '',
+    attempts      INTEGER NOT NULL DEFAULT 1,
+    last_error    TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_item ON outbox (instance, list_name, reminders_uid, ha_uid);
+`
+
+// intentsSchema records every write a pass makes while it makes it, so a
+// write interrupted between one side and the state DB is noticed; see
+// [Store.BeginIntent].
+const intentsSchema = `
+CREATE TABLE IF NOT EXISTS intents (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    instance      TEXT NOT NULL DEFAULT '',
+    list_name     TEXT NOT NULL,
+    reminders_uid TEXT NOT NULL DEFAULT '',
+    ha_uid        TEXT NOT NULL DEFAULT '',
+    action        TEXT NOT NULL,
+    title         TEXT NOT NULL DEFAULT '',
+    started_at    TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_intents_list ON intents (instance, list_name);
+`
+
+// syncRunsSchema keeps the outcome of every sync pass for
+// [Store.SummarizeSyncRuns]. started_at is in Unix milliseconds, so runs can
+// be selected by time range.
+const syncRunsSchema = `
+CREATE TABLE IF NOT EXISTS sync_runs (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    instance    TEXT    NOT NULL DEFAULT '',
+    kind        TEXT    NOT NULL,
+    started_at  INTEGER NOT NULL,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    created     INTEGER NOT NULL DEFAULT 0,
+    updated     INTEGER NOT NULL DEFAULT 0,
+    deleted     INTEGER NOT NULL DEFAULT 0,
+    conflicts   INTEGER NOT NULL DEFAULT 0,
+    errors      INTEGER NOT NULL DEFAULT 0,
+    error       TEXT    NOT NULL DEFAULT ''
 );
 
-CREATE UNIQUE INDEX IF NOT EXISTS idx_reminders_uid ON sync_items (reminders_uid) WHERE reminders_uid != '';
-CREATE UNIQUE INDEX IF NOT EXISTS idx_ha_uid         ON sync_items (ha_uid)         WHERE ha_uid != '';
-CREATE INDEX        IF NOT EXISTS idx_list_name      ON sync_items (list_name);
+CREATE INDEX IF NOT EXISTS idx_sync_runs_started ON sync_runs (instance, started_at);
+`
+
+// accountsSchema records the calendar identifiers of the lists of every
+// Reminders account, so a switch to another account, which changes all of
+// them, is noticed; see [Store.ChangedAccounts].
+const accountsSchema = `
+CREATE TABLE IF NOT EXISTS accounts (
+    instance TEXT NOT NULL DEFAULT '',
+    source   TEXT NOT NULL,
+    list_id  TEXT NOT NULL,
+    PRIMARY KEY (instance, source, list_id)
+);
 `
 
+// migrateV0 moves the rows of a pre-versioning database into the current
+// tables. Its rows belong to the default instance. Databases from before
+// shadow mode have no shadow_lists table; an empty one stands in for it.
+const migrateV0 = `
+DROP INDEX IF EXISTS idx_reminders_uid;
+DROP INDEX IF EXISTS idx_ha_uid;
+DROP INDEX IF EXISTS idx_list_name;
+CREATE TABLE IF NOT EXISTS shadow_lists (
+    list_name    TEXT    PRIMARY KEY,
+    passes       INTEGER NOT NULL DEFAULT 0,
+    would_create INTEGER NOT NULL DEFAULT 0,
+    would_update INTEGER NOT NULL DEFAULT 0,
+    would_delete INTEGER NOT NULL DEFAULT 0,
+    last_pass_at TEXT    NOT NULL DEFAULT '',
+    promoted     INTEGER NOT NULL DEFAULT 0
+);
+ALTER TABLE sync_items   RENAME TO sync_items_v0;
+ALTER TABLE shadow_lists RENAME TO shadow_lists_v0;
+` + schema + `
+INSERT INTO sync_items
+    (id, reminders_uid, ha_uid, list_name, title, last_sync_hash,
+     reminders_modified, ha_modified, last_synced_at)
+SELECT id, reminders_uid, ha_uid, list_name, title, last_sync_hash,
+       reminders_modified, ha_modified, last_synced_at
+FROM sync_items_v0;
+INSERT INTO shadow_lists
+    (list_name, passes, would_create, would_update, would_delete, last_pass_at, promoted)
+SELECT list_name, passes, would_create, would_update, would_delete, last_pass_at, promoted
+FROM shadow_lists_v0;
+DROP TABLE sync_items_v0;
+DROP TABLE shadow_lists_v0;
+`
+
+// upgrades[v] migrates a versioned database from version v to v+1.
+var upgrades = map[int]string{
+	1: `
+ALTER TABLE sync_items ADD COLUMN has_base         INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sync_items ADD COLUMN base_description TEXT    NOT NULL DEFAULT '';
+ALTER TABLE sync_items ADD COLUMN base_due         TEXT    NOT NULL DEFAULT '';
+ALTER TABLE sync_items ADD COLUMN base_priority    INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sync_items ADD COLUMN base_completed   INTEGER NOT NULL DEFAULT 0;
+`,
+	2: `
+ALTER TABLE sync_items ADD COLUMN pinned TEXT NOT NULL DEFAULT '';
+`,
+	3: jobRunsSchema,
+	4: `
+ALTER TABLE shadow_lists ADD COLUMN held INTEGER NOT NULL DEFAULT 0;
+`,
+	5: listIDsSchema,
+	6: `
+ALTER TABLE sync_items ADD COLUMN ha_seen_hash TEXT NOT NULL DEFAULT '';
+`,
+	7: outboxSchema,
+	8: intentsSchema,
+	9: syncRunsSchema,
+	10: `
+ALTER TABLE sync_items ADD COLUMN base_raw_priority INTEGER NOT NULL DEFAULT 0;
+`,
+	11: `
+ALTER TABLE sync_items ADD COLUMN base_alert TEXT NOT NULL DEFAULT '';
+`,
+	12: `
+ALTER TABLE sync_items ADD COLUMN base_completed_at TEXT NOT NULL DEFAULT '';
+`,
+	13: `
+ALTER TABLE sync_items ADD COLUMN base_url TEXT NOT NULL DEFAULT '';
+`,
+	14: accountsSchema,
+}
+
 // Item represents a single tracked task in the state database.
 type Item struct {
 	ID                int64
@@ -45,21 +241,157 @@ type Item struct {
 	RemindersModified time.Time
 	HAModified        time.Time
 	LastSyncedAt      time.Time
+
+	// HASeenHash is the content hash of the HA item HAModified refers to.
+	// Targets such as Home Assistant report no modification times, so the
+	// sync engine records when it first saw each content instead.
+	HASeenHash string
+
+	// Base is the content both sides agreed on at the last sync: the common
+	// ancestor for three-way merges. Only the fields covered by
+	// [model.Item.ContentHash], RawPriority, and CompletedAt are stored, with
+	// Title taken from Title. Nil for rows written before it was tracked.
+	Base *model.Item
+
+	// Pin names the side whose version always wins for this item. It is
+	// changed only by [Store.SetPin]; [Store.UpsertItem] writes it for new
+	// rows but never overwrites it on existing ones.
+	Pin Pin
+}
+
+// Pin names the side an item is pinned to.
+type Pin string
+
+const (
+	// PinNone leaves conflicts to the normal merge rules.
+	PinNone Pin = ""
+	// PinReminders makes the Reminders version win every conflict, and
+	// deletions in Home Assistant are undone instead of propagated.
+	PinReminders Pin = "reminders"
+	// PinHA makes the Home Assistant version win every conflict, and
+	// deletions in Reminders are undone instead of propagated.
+	PinHA Pin = "ha"
+)
+
+// ShadowList records the progress of a list mapping running in shadow mode.
+// The Would* counters hold the planned mutations of the most recent pass.
+type ShadowList struct {
+	ListName    string
+	Passes      int
+	WouldCreate int
+	WouldUpdate int
+	WouldDelete int
+	LastPassAt  time.Time
+	Promoted    bool
+
+	// Held is set for lists put in shadow mode by [Recover] rather than by
+	// the config. They stay in shadow mode until promoted by hand.
+	Held bool
+}
+
+// JobRun records the most recent run of a scheduled auxiliary job.
+type JobRun struct {
+	Name      string
+	Runs      int // total runs, including this one
+	LastRunAt time.Time
+	Duration  time.Duration
+	LastError string // empty when the last run succeeded
+}
+
+// SyncRun is the outcome of one sync pass.
+type SyncRun struct {
+	Kind      string // what started the pass, such as "full"
+	StartedAt time.Time
+	Duration  time.Duration
+	Created   int
+	Updated   int
+	Deleted   int
+	Conflicts int
+	Errors    int
+	Error     string // empty when the pass succeeded
+}
+
+// SyncRunSummary totals the sync runs of a period.
+type SyncRunSummary struct {
+	Runs      int
+	Failed    int // runs that ended with an error
+	Created   int
+	Updated   int
+	Deleted   int
+	Conflicts int
+	Errors    int
+
+	Duration    time.Duration // of all runs together
+	MaxDuration time.Duration
+	First, Last time.Time // start of the oldest and newest run; zero without runs
+}
+
+// Outbound is a write to the target side of a list mapping that failed and
+// waits to be replayed, such as a change made in Reminders while Home
+// Assistant was down. It names the item and the action, not the content:
+// the replay writes the item as it is by then.
+type Outbound struct {
+	ID           int64
+	ListName     string
+	RemindersUID string // empty for items not in Reminders
+	HAUID        string // empty for items not yet in the target
+	Action       string
+	Title        string
+	QueuedAt     time.Time // when the write first failed
+	Attempts     int
+	LastError    string
 }
 
-// Store is the SQLite-backed state repository.
+// Intent is a write a pass is making to one side of a list mapping. It is
+// recorded before the write and removed once the state DB reflects it, so
+// an intent left behind marks a write that was interrupted, such as by a
+// crash after Home Assistant created an item but before its state row was
+// written.
+type Intent struct {
+	ID           int64
+	ListName     string
+	RemindersUID string // empty for items not in Reminders
+	HAUID        string // empty for items not yet in the target
+	Action       string
+	Title        string
+	StartedAt    time.Time
+}
+
+// OrphanedList is a list name that has state rows but no list mapping.
+type OrphanedList struct {
+	ListName string
+	Items    int // sync_items rows; 0 if only shadow-mode progress remains
+}
+
+// Store is the SQLite-backed state repository. Every query is scoped to one
+// instance label (see [Store.ForInstance]); the store returned by [Open]
+// uses the default, empty label.
 type Store struct {
-	db *sql.DB
+	db       *sql.DB
+	instance string
 }
 
-// DefaultDBPath returns the default path for the state database:
-// ~/.local/share/reminderrelay/state.db
+// DefaultDBPath returns the default path for the state database of the
+// selected profile: $XDG_DATA_HOME/reminderrelay[/<profile>]/state.db, with
+// XDG_DATA_HOME defaulting to ~/.local/share.
 func DefaultDBPath() (string, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "state.db"), nil
+}
+
+// DefaultDir returns the default state directory of the selected profile.
+func DefaultDir() (string, error) {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dataHome) {
+		return profile.Dir(filepath.Join(dataHome, "reminderrelay")), nil
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("resolving home directory: %w", err)
 	}
-	return filepath.Join(home, ".local", "share", "reminderrelay", "state.db"), nil
+	return profile.Dir(filepath.Join(home, ".local", "share", "reminderrelay")), nil
 }
 
 // Open opens (or creates) the SQLite database at path, applies the schema, and
@@ -85,15 +417,68 @@ func Open(path string) (*Store, error) {
 	return &Store{db: db}, nil
 }
 
+// ForInstance returns a view of the store whose rows are labelled with
+// instance, so several sync sets can share one database without seeing each
+// other's state. The view shares the connection of s; closing either closes
+// both.
+func (s *Store) ForInstance(instance string) *Store {
+	return &Store{db: s.db, instance: instance}
+}
+
+// Instance returns the instance label the store is scoped to.
+func (s *Store) Instance() string {
+	return s.instance
+}
+
 // Close releases the underlying database connection.
 func (s *Store) Close() error {
 	return s.db.Close()
 }
 
-// migrate applies the schema DDL idempotently (CREATE IF NOT EXISTS).
+// migrate brings the schema up to schemaVersion in a single transaction.
+// Fresh databases get the current DDL, pre-versioning databases are rebuilt,
+// and versioned databases are upgraded one step at a time.
 func migrate(db *sql.DB) error {
-	_, err := db.Exec(schema)
-	return err
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+	if version >= schemaVersion {
+		return nil
+	}
+
+	var legacy int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'sync_items'`).Scan(&legacy)
+	if err != nil {
+		return fmt.Errorf("inspecting schema: %w", err)
+	}
+	var steps []string
+	switch {
+	case version == 0 && legacy == 0:
+		steps = []string{schema}
+	case version == 0:
+		steps = []string{migrateV0}
+	default:
+		for v := version; v < schemaVersion; v++ {
+			steps = append(steps, upgrades[v])
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning migration: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, ddl := range steps {
+		if _, err := tx.Exec(ddl); err != nil {
+			return fmt.Errorf("migrating schema from version %d to %d: %w", version, schemaVersion, err)
+		}
+	}
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, schemaVersion)); err != nil {
+		return fmt.Errorf("recording schema version: %w", err)
+	}
+	return tx.Commit()
 }
 
 // GetItemByRemindersUID returns the item with the given Reminders UID,
@@ -101,9 +486,11 @@ func migrate(db *sql.DB) error {
 func (s *Store) GetItemByRemindersUID(ctx context.Context, uid string) (*Item, error) {
 	const q = `
 		SELECT id, reminders_uid, ha_uid, list_name, title,
-		       last_sync_hash, reminders_modified, ha_modified, last_synced_at
-		FROM sync_items WHERE reminders_uid = ?`
-	row := s.db.QueryRowContext(ctx, q, uid)
+		       last_sync_hash, reminders_modified, ha_modified, last_synced_at,
+		       has_base, base_description, base_due, base_priority, base_completed, pinned, ha_seen_hash,
+		       base_raw_priority, base_alert, base_completed_at, base_url
+		FROM sync_items WHERE instance = ? AND reminders_uid = ?`
+	row := s.db.QueryRowContext(ctx, q, s.instance, uid)
 	return scanItem(row)
 }
 
@@ -112,9 +499,11 @@ func (s *Store) GetItemByRemindersUID(ctx context.Context, uid string) (*Item, e
 func (s *Store) GetItemByHAUID(ctx context.Context, uid string) (*Item, error) {
 	const q = `
 		SELECT id, reminders_uid, ha_uid, list_name, title,
-		       last_sync_hash, reminders_modified, ha_modified, last_synced_at
-		FROM sync_items WHERE ha_uid = ?`
-	row := s.db.QueryRowContext(ctx, q, uid)
+		       last_sync_hash, reminders_modified, ha_modified, last_synced_at,
+		       has_base, base_description, base_due, base_priority, base_completed, pinned, ha_seen_hash,
+		       base_raw_priority, base_alert, base_completed_at, base_url
+		FROM sync_items WHERE instance = ? AND ha_uid = ?`
+	row := s.db.QueryRowContext(ctx, q, s.instance, uid)
 	return scanItem(row)
 }
 
@@ -122,9 +511,11 @@ func (s *Store) GetItemByHAUID(ctx context.Context, uid string) (*Item, error) {
 func (s *Store) GetAllItemsForList(ctx context.Context, listName string) ([]*Item, error) {
 	const q = `
 		SELECT id, reminders_uid, ha_uid, list_name, title,
-		       last_sync_hash, reminders_modified, ha_modified, last_synced_at
-		FROM sync_items WHERE list_name = ?`
-	rows, err := s.db.QueryContext(ctx, q, listName)
+		       last_sync_hash, reminders_modified, ha_modified, last_synced_at,
+		       has_base, base_description, base_due, base_priority, base_completed, pinned, ha_seen_hash,
+		       base_raw_priority, base_alert, base_completed_at, base_url
+		FROM sync_items WHERE instance = ? AND list_name = ?`
+	rows, err := s.db.QueryContext(ctx, q, s.instance, listName)
 	if err != nil {
 		return nil, fmt.Errorf("querying items for list %q: %w", listName, err)
 	}
@@ -147,19 +538,54 @@ func (s *Store) GetAllItemsForList(ctx context.Context, listName string) ([]*Ite
 func (s *Store) UpsertItem(ctx context.Context, item *Item) error {
 	const q = `
 		INSERT INTO sync_items
-		    (reminders_uid, ha_uid, list_name, title, last_sync_hash,
-		     reminders_modified, ha_modified, last_synced_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(reminders_uid) WHERE reminders_uid != '' DO UPDATE SET
+		    (instance, reminders_uid, ha_uid, list_name, title, last_sync_hash,
+		     reminders_modified, ha_modified, last_synced_at,
+		     has_base, base_description, base_due, base_priority, base_completed, pinned, ha_seen_hash,
+		     base_raw_priority, base_alert, base_completed_at, base_url)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(instance, reminders_uid) WHERE reminders_uid != '' DO UPDATE SET
 		    ha_uid             = excluded.ha_uid,
 		    list_name          = excluded.list_name,
 		    title              = excluded.title,
 		    last_sync_hash     = excluded.last_sync_hash,
 		    reminders_modified = excluded.reminders_modified,
 		    ha_modified        = excluded.ha_modified,
-		    last_synced_at     = excluded.last_synced_at`
+		    last_synced_at     = excluded.last_synced_at,
+		    has_base           = excluded.has_base,
+		    base_description   = excluded.base_description,
+		    base_due           = excluded.base_due,
+		    base_priority      = excluded.base_priority,
+		    base_completed     = excluded.base_completed,
+		    ha_seen_hash       = excluded.ha_seen_hash,
+		    base_raw_priority  = excluded.base_raw_priority,
+		    base_alert         = excluded.base_alert,
+		    base_completed_at  = excluded.base_completed_at,
+		    base_url           = excluded.base_url`
+
+	var (
+		hasBase, baseCompleted bool
+		baseDesc, baseDue      string
+		baseAlert, baseDone    string
+		baseURL                string
+		basePriority           model.Priority
+		baseRawPriority        int
+	)
+	if b := item.Base; b != nil {
+		hasBase, baseDesc, basePriority, baseCompleted = true, b.Description, b.Priority, b.Completed
+		baseRawPriority, baseURL = b.RawPriority, b.URL
+		if b.DueDate != nil {
+			baseDue = formatTime(*b.DueDate)
+		}
+		if b.AlertAt != nil {
+			baseAlert = formatTime(*b.AlertAt)
+		}
+		if b.CompletedAt != nil {
+			baseDone = formatTime(*b.CompletedAt)
+		}
+	}
 
 	res, err := s.db.ExecContext(ctx, q,
+		s.instance,
 		item.RemindersUID,
 		item.HAUID,
 		item.ListName,
@@ -168,6 +594,17 @@ func (s *Store) UpsertItem(ctx context.Context, item *Item) error {
 		formatTime(item.RemindersModified),
 		formatTime(item.HAModified),
 		formatTime(item.LastSyncedAt),
+		hasBase,
+		baseDesc,
+		baseDue,
+		basePriority,
+		baseCompleted,
+		item.Pin,
+		item.HASeenHash,
+		baseRawPriority,
+		baseAlert,
+		baseDone,
+		baseURL,
 	)
 	if err != nil {
 		return fmt.Errorf("upserting item %q: %w", item.Title, err)
@@ -181,24 +618,708 @@ func (s *Store) UpsertItem(ctx context.Context, item *Item) error {
 
 // DeleteItem removes the item with the given database ID.
 func (s *Store) DeleteItem(ctx context.Context, id int64) error {
-	const q = `DELETE FROM sync_items WHERE id = ?`
-	if _, err := s.db.ExecContext(ctx, q, id); err != nil {
+	const q = `DELETE FROM sync_items WHERE instance = ? AND id = ?`
+	if _, err := s.db.ExecContext(ctx, q, s.instance, id); err != nil {
 		return fmt.Errorf("deleting item id=%d: %w", id, err)
 	}
 	return nil
 }
 
-// IsEmpty reports whether the sync_items table has no rows.
-// Used by the first-run bootstrap to detect a fresh install.
+// DeleteList removes every sync_items row, any shadow-mode progress, queued
+// writes, intents and the recorded identifier for listName, returning the number of items removed. Items on either side are
+// not touched — only the linkage is forgotten.
+func (s *Store) DeleteList(ctx context.Context, listName string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM sync_items WHERE instance = ? AND list_name = ?`, s.instance, listName)
+	if err != nil {
+		return 0, fmt.Errorf("deleting items for list %q: %w", listName, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM shadow_lists WHERE instance = ? AND list_name = ?`, s.instance, listName); err != nil {
+		return 0, fmt.Errorf("deleting shadow state for list %q: %w", listName, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM list_ids WHERE instance = ? AND list_name = ?`, s.instance, listName); err != nil {
+		return 0, fmt.Errorf("deleting identifier of list %q: %w", listName, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM outbox WHERE instance = ? AND list_name = ?`, s.instance, listName); err != nil {
+		return 0, fmt.Errorf("deleting queued writes of list %q: %w", listName, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM intents WHERE instance = ? AND list_name = ?`, s.instance, listName); err != nil {
+		return 0, fmt.Errorf("deleting intents of list %q: %w", listName, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing list deletion: %w", err)
+	}
+
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// Forget removes the sync state of every list — items, shadow-mode progress,
+// queued writes, intents and recorded identifiers — returning the number of
+// items removed, so the next run bootstraps all lists afresh. Items on either
+// side, job and sync run history are not touched.
+func (s *Store) Forget(ctx context.Context) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM sync_items WHERE instance = ?`, s.instance)
+	if err != nil {
+		return 0, fmt.Errorf("deleting items: %w", err)
+	}
+	for _, table := range []string{"shadow_lists", "list_ids", "outbox", "intents"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE instance = ?`, s.instance); err != nil {
+			return 0, fmt.Errorf("deleting rows of %s: %w", table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing state deletion: %w", err)
+	}
+
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// SetPin pins every tracked item titled title in listName to pin, or unpins
+// it with [PinNone]. It returns the number of items changed; 0 means no such
+// item is tracked.
+func (s *Store) SetPin(ctx context.Context, listName, title string, pin Pin) (int, error) {
+	const q = `UPDATE sync_items SET pinned = ? WHERE instance = ? AND list_name = ? AND title = ?`
+	res, err := s.db.ExecContext(ctx, q, pin, s.instance, listName, title)
+	if err != nil {
+		return 0, fmt.Errorf("pinning %q in list %q: %w", title, listName, err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// PinnedItems returns every pinned item, ordered by list name and title.
+func (s *Store) PinnedItems(ctx context.Context) ([]*Item, error) {
+	const q = `
+		SELECT id, reminders_uid, ha_uid, list_name, title,
+		       last_sync_hash, reminders_modified, ha_modified, last_synced_at,
+		       has_base, base_description, base_due, base_priority, base_completed, pinned, ha_seen_hash,
+		       base_raw_priority, base_alert, base_completed_at, base_url
+		FROM sync_items WHERE instance = ? AND pinned != '' ORDER BY list_name, title`
+	rows, err := s.db.QueryContext(ctx, q, s.instance)
+	if err != nil {
+		return nil, fmt.Errorf("querying pinned items: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []*Item
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// OrphanedLists returns every list that has sync_items or shadow_lists rows
+// but is not a key of mapped, ordered by list name.
+func (s *Store) OrphanedLists(ctx context.Context, mapped map[string]string) ([]OrphanedList, error) {
+	const q = `
+		SELECT list_name, SUM(items) FROM (
+		    SELECT list_name, COUNT(*) AS items FROM sync_items
+		    WHERE instance = ? GROUP BY list_name
+		    UNION ALL
+		    SELECT list_name, 0 FROM shadow_lists WHERE instance = ?
+		) GROUP BY list_name ORDER BY list_name`
+	rows, err := s.db.QueryContext(ctx, q, s.instance, s.instance)
+	if err != nil {
+		return nil, fmt.Errorf("querying list names: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var orphans []OrphanedList
+	for rows.Next() {
+		var o OrphanedList
+		if err := rows.Scan(&o.ListName, &o.Items); err != nil {
+			return nil, fmt.Errorf("scanning list name: %w", err)
+		}
+		if _, ok := mapped[o.ListName]; !ok {
+			orphans = append(orphans, o)
+		}
+	}
+	return orphans, rows.Err()
+}
+
+// IsEmpty reports whether the sync_items table has no rows for the store's
+// instance. Used by the first-run bootstrap to detect a fresh install.
 func (s *Store) IsEmpty(ctx context.Context) (bool, error) {
 	var count int
-	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sync_items`).Scan(&count)
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sync_items WHERE instance = ?`, s.instance).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("checking if store is empty: %w", err)
 	}
 	return count == 0, nil
 }
 
+// ItemCounts returns the number of tracked items per list name.
+func (s *Store) ItemCounts(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT list_name, COUNT(*) FROM sync_items WHERE instance = ? GROUP BY list_name`, s.instance)
+	if err != nil {
+		return nil, fmt.Errorf("counting items: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var (
+			list string
+			n    int
+		)
+		if err := rows.Scan(&list, &n); err != nil {
+			return nil, fmt.Errorf("scanning item count: %w", err)
+		}
+		counts[list] = n
+	}
+	return counts, rows.Err()
+}
+
+// --- Shadow lists ------------------------------------------------------------
+
+// GetShadowList returns the shadow-mode progress for listName,
+// or (nil, nil) if the list has never run in shadow mode.
+func (s *Store) GetShadowList(ctx context.Context, listName string) (*ShadowList, error) {
+	const q = `
+		SELECT list_name, passes, would_create, would_update, would_delete,
+		       last_pass_at, promoted, held
+		FROM shadow_lists WHERE instance = ? AND list_name = ?`
+	return scanShadowList(s.db.QueryRowContext(ctx, q, s.instance, listName))
+}
+
+// GetAllShadowLists returns the shadow-mode progress of every list that has
+// run in shadow mode, ordered by list name.
+func (s *Store) GetAllShadowLists(ctx context.Context) ([]*ShadowList, error) {
+	const q = `
+		SELECT list_name, passes, would_create, would_update, would_delete,
+		       last_pass_at, promoted, held
+		FROM shadow_lists WHERE instance = ? ORDER BY list_name`
+	rows, err := s.db.QueryContext(ctx, q, s.instance)
+	if err != nil {
+		return nil, fmt.Errorf("querying shadow lists: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var lists []*ShadowList
+	for rows.Next() {
+		sl, err := scanShadowList(rows)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, sl)
+	}
+	return lists, rows.Err()
+}
+
+// RecordShadowPass increments the pass counter for listName and stores the
+// planned mutation counts of the pass.
+func (s *Store) RecordShadowPass(ctx context.Context, listName string, creates, updates, deletes int, at time.Time) error {
+	const q = `
+		INSERT INTO shadow_lists
+		    (instance, list_name, passes, would_create, would_update, would_delete, last_pass_at)
+		VALUES (?, ?, 1, ?, ?, ?, ?)
+		ON CONFLICT(instance, list_name) DO UPDATE SET
+		    passes       = passes + 1,
+		    would_create = excluded.would_create,
+		    would_update = excluded.would_update,
+		    would_delete = excluded.would_delete,
+		    last_pass_at = excluded.last_pass_at`
+	if _, err := s.db.ExecContext(ctx, q, s.instance, listName, creates, updates, deletes, formatTime(at)); err != nil {
+		return fmt.Errorf("recording shadow pass for %q: %w", listName, err)
+	}
+	return nil
+}
+
+// PromoteShadowList marks listName as promoted so the reconciler starts
+// applying its changes. Promoting a list that never ran in shadow mode is
+// allowed and simply records the promotion.
+func (s *Store) PromoteShadowList(ctx context.Context, listName string) error {
+	const q = `
+		INSERT INTO shadow_lists (instance, list_name, promoted) VALUES (?, ?, 1)
+		ON CONFLICT(instance, list_name) DO UPDATE SET promoted = 1`
+	if _, err := s.db.ExecContext(ctx, q, s.instance, listName); err != nil {
+		return fmt.Errorf("promoting shadow list %q: %w", listName, err)
+	}
+	return nil
+}
+
+// HoldLists puts lists in shadow mode regardless of the config, restarting
+// their shadow progress. Used after the state was rebuilt from scratch.
+func (s *Store) HoldLists(ctx context.Context, lists []string) error {
+	const q = `
+		INSERT INTO shadow_lists (instance, list_name, held) VALUES (?, ?, 1)
+		ON CONFLICT(instance, list_name) DO UPDATE SET
+		    held = 1, promoted = 0, passes = 0`
+	for _, list := range lists {
+		if _, err := s.db.ExecContext(ctx, q, s.instance, list); err != nil {
+			return fmt.Errorf("holding list %q: %w", list, err)
+		}
+	}
+	return nil
+}
+
+// HeldLists returns the names of held lists that are not yet promoted.
+func (s *Store) HeldLists(ctx context.Context) ([]string, error) {
+	lists, err := s.GetAllShadowLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var held []string
+	for _, sl := range lists {
+		if sl.Held && !sl.Promoted {
+			held = append(held, sl.ListName)
+		}
+	}
+	return held, nil
+}
+
+// --- List identifiers --------------------------------------------------------
+
+// ListIDs returns the recorded calendar identifier of every list, keyed by
+// list name.
+func (s *Store) ListIDs(ctx context.Context) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT list_name, list_id FROM list_ids WHERE instance = ?`, s.instance)
+	if err != nil {
+		return nil, fmt.Errorf("querying list identifiers: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	ids := make(map[string]string)
+	for rows.Next() {
+		var name, id string
+		if err := rows.Scan(&name, &id); err != nil {
+			return nil, fmt.Errorf("scanning list identifier: %w", err)
+		}
+		ids[name] = id
+	}
+	return ids, rows.Err()
+}
+
+// SetListID records id as the calendar identifier of listName. Any other
+// list recorded with the same identifier is forgotten, as an identifier
+// belongs to exactly one list.
+func (s *Store) SetListID(ctx context.Context, listName, id string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM list_ids WHERE instance = ? AND list_id = ? AND list_name != ?`,
+		s.instance, id, listName); err != nil {
+		return fmt.Errorf("releasing identifier %q: %w", id, err)
+	}
+	const q = `
+		INSERT INTO list_ids (instance, list_name, list_id) VALUES (?, ?, ?)
+		ON CONFLICT(instance, list_name) DO UPDATE SET list_id = excluded.list_id`
+	if _, err := tx.ExecContext(ctx, q, s.instance, listName, id); err != nil {
+		return fmt.Errorf("recording identifier of list %q: %w", listName, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing list identifier: %w", err)
+	}
+	return nil
+}
+
+// RenameList moves every row kept for oldName — items, shadow-mode progress,
+// queued writes and the recorded identifier — to newName, after the list was renamed in
+// Reminders. It fails if newName already has rows of its own.
+func (s *Store) RenameList(ctx context.Context, oldName, newName string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var taken int
+	const check = `
+		SELECT (SELECT COUNT(*) FROM sync_items   WHERE instance = ? AND list_name = ?)
+		     + (SELECT COUNT(*) FROM shadow_lists WHERE instance = ? AND list_name = ?)`
+	if err := tx.QueryRowContext(ctx, check, s.instance, newName, s.instance, newName).Scan(&taken); err != nil {
+		return fmt.Errorf("checking list %q: %w", newName, err)
+	}
+	if taken > 0 {
+		return fmt.Errorf("renaming list %q: state for %q already exists", oldName, newName)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM list_ids WHERE instance = ? AND list_name = ?`, s.instance, newName); err != nil {
+		return fmt.Errorf("releasing list name %q: %w", newName, err)
+	}
+	for _, table := range []string{"sync_items", "shadow_lists", "list_ids", "outbox", "intents"} {
+		q := `UPDATE ` + table + ` SET list_name = ? WHERE instance = ? AND list_name = ?`
+		if _, err := tx.ExecContext(ctx, q, newName, s.instance, oldName); err != nil {
+			return fmt.Errorf("renaming list %q in %s: %w", oldName, table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing list rename: %w", err)
+	}
+	return nil
+}
+
+// --- Accounts ----------------------------------------------------------------
+
+// ChangedAccounts returns the recorded Reminders accounts, sorted, none of
+// whose list identifiers is among current, which holds the calendar
+// identifiers of every list keyed by account name. Signing into another
+// iCloud account keeps the account name but replaces every identifier.
+func (s *Store) ChangedAccounts(ctx context.Context, current map[string][]string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT source, list_id FROM accounts WHERE instance = ? ORDER BY source`, s.instance)
+	if err != nil {
+		return nil, fmt.Errorf("querying accounts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	exists := make(map[string]bool)
+	for _, ids := range current {
+		for _, id := range ids {
+			exists[id] = true
+		}
+	}
+	var (
+		sources []string
+		kept    = make(map[string]bool)
+	)
+	for rows.Next() {
+		var source, id string
+		if err := rows.Scan(&source, &id); err != nil {
+			return nil, fmt.Errorf("scanning account: %w", err)
+		}
+		if _, seen := kept[source]; !seen {
+			sources = append(sources, source)
+		}
+		kept[source] = kept[source] || exists[id]
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("querying accounts: %w", err)
+	}
+
+	var changed []string
+	for _, source := range sources {
+		if !kept[source] {
+			changed = append(changed, source)
+		}
+	}
+	return changed, nil
+}
+
+// RecordAccounts replaces the recorded list identifiers of every Reminders
+// account with current, keyed by account name.
+func (s *Store) RecordAccounts(ctx context.Context, current map[string][]string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM accounts WHERE instance = ?`, s.instance); err != nil {
+		return fmt.Errorf("clearing accounts: %w", err)
+	}
+	const q = `INSERT OR IGNORE INTO accounts (instance, source, list_id) VALUES (?, ?, ?)`
+	for source, ids := range current {
+		for _, id := range ids {
+			if _, err := tx.ExecContext(ctx, q, s.instance, source, id); err != nil {
+				return fmt.Errorf("recording account %q: %w", source, err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing accounts: %w", err)
+	}
+	return nil
+}
+
+// --- Outbox ------------------------------------------------------------------
+
+// QueueOutbound records a failed write to the target side of o.ListName. An
+// item already queued keeps its place in the queue: its entry takes the new
+// action, title and error, and counts another attempt. o.ID, o.Attempts and,
+// for queued items, o.QueuedAt are ignored.
+func (s *Store) QueueOutbound(ctx context.Context, o Outbound) error {
+	const q = `
+		INSERT INTO outbox
+		    (instance, list_name, reminders_uid, ha_uid, action, title, queued_at, last_error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(instance, list_name, reminders_uid, ha_uid) DO UPDATE SET
+		    action     = excluded.action,
+		    title      = excluded.title,
+		    attempts   = attempts + 1,
+		    last_error = excluded.last_error`
+	_, err := s.db.ExecContext(ctx, q, s.instance, o.ListName, o.RemindersUID, o.HAUID,
+		o.Action, o.Title, formatTime(o.QueuedAt), o.LastError)
+	if err != nil {
+		return fmt.Errorf("queueing write of %q: %w", o.Title, err)
+	}
+	return nil
+}
+
+// QueuedOutbound returns the queued writes of listName, oldest first.
+func (s *Store) QueuedOutbound(ctx context.Context, listName string) ([]*Outbound, error) {
+	const q = `
+		SELECT id, list_name, reminders_uid, ha_uid, action, title, queued_at, attempts, last_error
+		FROM outbox WHERE instance = ? AND list_name = ? ORDER BY id`
+	rows, err := s.db.QueryContext(ctx, q, s.instance, listName)
+	if err != nil {
+		return nil, fmt.Errorf("querying queued writes of %q: %w", listName, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var queued []*Outbound
+	for rows.Next() {
+		var o Outbound
+		var queuedAt string
+		if err := rows.Scan(&o.ID, &o.ListName, &o.RemindersUID, &o.HAUID, &o.Action,
+			&o.Title, &queuedAt, &o.Attempts, &o.LastError); err != nil {
+			return nil, fmt.Errorf("scanning queued write: %w", err)
+		}
+		o.QueuedAt, _ = parseTime(queuedAt)
+		queued = append(queued, &o)
+	}
+	return queued, rows.Err()
+}
+
+// DeleteOutbound removes a queued write, once it went through or is no
+// longer needed.
+func (s *Store) DeleteOutbound(ctx context.Context, id int64) error {
+	const q = `DELETE FROM outbox WHERE instance = ? AND id = ?`
+	if _, err := s.db.ExecContext(ctx, q, s.instance, id); err != nil {
+		return fmt.Errorf("deleting queued write id=%d: %w", id, err)
+	}
+	return nil
+}
+
+// OutboundCounts returns the number of queued writes of every list that has
+// any.
+func (s *Store) OutboundCounts(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT list_name, COUNT(*) FROM outbox WHERE instance = ? GROUP BY list_name`, s.instance)
+	if err != nil {
+		return nil, fmt.Errorf("counting queued writes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var list string
+		var n int
+		if err := rows.Scan(&list, &n); err != nil {
+			return nil, fmt.Errorf("scanning queued write count: %w", err)
+		}
+		counts[list] = n
+	}
+	return counts, rows.Err()
+}
+
+// --- Intents -----------------------------------------------------------------
+
+// BeginIntent records that a write to i.ListName is about to be made and
+// returns the ID to pass to [Store.EndIntent] once the state DB reflects it.
+// i.ID is ignored.
+func (s *Store) BeginIntent(ctx context.Context, i Intent) (int64, error) {
+	const q = `
+		INSERT INTO intents (instance, list_name, reminders_uid, ha_uid, action, title, started_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+	res, err := s.db.ExecContext(ctx, q, s.instance, i.ListName, i.RemindersUID, i.HAUID,
+		i.Action, i.Title, formatTime(i.StartedAt))
+	if err != nil {
+		return 0, fmt.Errorf("recording intent to write %q: %w", i.Title, err)
+	}
+	return res.LastInsertId()
+}
+
+// EndIntent removes an intent recorded by [Store.BeginIntent].
+func (s *Store) EndIntent(ctx context.Context, id int64) error {
+	const q = `DELETE FROM intents WHERE instance = ? AND id = ?`
+	if _, err := s.db.ExecContext(ctx, q, s.instance, id); err != nil {
+		return fmt.Errorf("deleting intent id=%d: %w", id, err)
+	}
+	return nil
+}
+
+// Intents returns the intents of listName that were not ended, oldest
+// first.
+func (s *Store) Intents(ctx context.Context, listName string) ([]*Intent, error) {
+	const q = `
+		SELECT id, list_name, reminders_uid, ha_uid, action, title, started_at
+		FROM intents WHERE instance = ? AND list_name = ? ORDER BY id`
+	rows, err := s.db.QueryContext(ctx, q, s.instance, listName)
+	if err != nil {
+		return nil, fmt.Errorf("querying intents of %q: %w", listName, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var intents []*Intent
+	for rows.Next() {
+		var i Intent
+		var startedAt string
+		if err := rows.Scan(&i.ID, &i.ListName, &i.RemindersUID, &i.HAUID, &i.Action,
+			&i.Title, &startedAt); err != nil {
+			return nil, fmt.Errorf("scanning intent: %w", err)
+		}
+		i.StartedAt, _ = parseTime(startedAt)
+		intents = append(intents, &i)
+	}
+	return intents, rows.Err()
+}
+
+// --- Job runs ----------------------------------------------------------------
+
+// GetJobRun returns the last run of the job called name,
+// or (nil, nil) if it has never run.
+func (s *Store) GetJobRun(ctx context.Context, name string) (*JobRun, error) {
+	const q = `
+		SELECT name, runs, last_run_at, duration_ms, last_error
+		FROM job_runs WHERE instance = ? AND name = ?`
+	return scanJobRun(s.db.QueryRowContext(ctx, q, s.instance, name))
+}
+
+// GetAllJobRuns returns the last run of every job that has run, ordered by
+// name.
+func (s *Store) GetAllJobRuns(ctx context.Context) ([]*JobRun, error) {
+	const q = `
+		SELECT name, runs, last_run_at, duration_ms, last_error
+		FROM job_runs WHERE instance = ? ORDER BY name`
+	rows, err := s.db.QueryContext(ctx, q, s.instance)
+	if err != nil {
+		return nil, fmt.Errorf("querying job runs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var runs []*JobRun
+	for rows.Next() {
+		run, err := scanJobRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// RecordJobRun stores run as the latest run of its job and increments the
+// job's run counter. run.Runs is ignored.
+func (s *Store) RecordJobRun(ctx context.Context, run JobRun) error {
+	const q = `
+		INSERT INTO job_runs (instance, name, runs, last_run_at, duration_ms, last_error)
+		VALUES (?, ?, 1, ?, ?, ?)
+		ON CONFLICT(instance, name) DO UPDATE SET
+		    runs        = runs + 1,
+		    last_run_at = excluded.last_run_at,
+		    duration_ms = excluded.duration_ms,
+		    last_error  = excluded.last_error`
+	_, err := s.db.ExecContext(ctx, q, s.instance, run.Name,
+		formatTime(run.LastRunAt), run.Duration.Milliseconds(), run.LastError)
+	if err != nil {
+		return fmt.Errorf("recording run of job %q: %w", run.Name, err)
+	}
+	return nil
+}
+
+// --- Sync runs ---------------------------------------------------------------
+
+// RecordSyncRun stores the outcome of a sync pass.
+func (s *Store) RecordSyncRun(ctx context.Context, run SyncRun) error {
+	const q = `
+		INSERT INTO sync_runs (instance, kind, started_at, duration_ms,
+		                       created, updated, deleted, conflicts, errors, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := s.db.ExecContext(ctx, q, s.instance, run.Kind, run.StartedAt.UnixMilli(),
+		run.Duration.Milliseconds(), run.Created, run.Updated, run.Deleted, run.Conflicts,
+		run.Errors, run.Error)
+	if err != nil {
+		return fmt.Errorf("recording sync run: %w", err)
+	}
+	return nil
+}
+
+// SummarizeSyncRuns totals the sync runs started at or after since.
+func (s *Store) SummarizeSyncRuns(ctx context.Context, since time.Time) (*SyncRunSummary, error) {
+	const q = `
+		SELECT COUNT(*), COUNT(NULLIF(error, '')),
+		       COALESCE(SUM(created), 0), COALESCE(SUM(updated), 0), COALESCE(SUM(deleted), 0),
+		       COALESCE(SUM(conflicts), 0), COALESCE(SUM(errors), 0),
+		       COALESCE(SUM(duration_ms), 0), COALESCE(MAX(duration_ms), 0),
+		       COALESCE(MIN(started_at), 0), COALESCE(MAX(started_at), 0)
+		FROM sync_runs WHERE instance = ? AND started_at >= ?`
+	var sum SyncRunSummary
+	var total, longest, first, last int64
+	err := s.db.QueryRowContext(ctx, q, s.instance, since.UnixMilli()).Scan(
+		&sum.Runs, &sum.Failed, &sum.Created, &sum.Updated, &sum.Deleted,
+		&sum.Conflicts, &sum.Errors, &total, &longest, &first, &last)
+	if err != nil {
+		return nil, fmt.Errorf("summarising sync runs: %w", err)
+	}
+	sum.Duration = time.Duration(total) * time.Millisecond
+	sum.MaxDuration = time.Duration(longest) * time.Millisecond
+	if sum.Runs > 0 {
+		sum.First = time.UnixMilli(first).UTC()
+		sum.Last = time.UnixMilli(last).UTC()
+	}
+	return &sum, nil
+}
+
+// LastSuccessfulSyncRun returns the most recent sync run that ended without
+// an error, or (nil, nil) if there is none.
+func (s *Store) LastSuccessfulSyncRun(ctx context.Context) (*SyncRun, error) {
+	const q = `
+		SELECT kind, started_at, duration_ms, created, updated, deleted, conflicts, errors
+		FROM sync_runs WHERE instance = ? AND error = ''
+		ORDER BY started_at DESC, id DESC LIMIT 1`
+	var run SyncRun
+	var startedAt, durationMS int64
+	err := s.db.QueryRowContext(ctx, q, s.instance).Scan(&run.Kind, &startedAt, &durationMS,
+		&run.Created, &run.Updated, &run.Deleted, &run.Conflicts, &run.Errors)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying last successful sync run: %w", err)
+	}
+	run.StartedAt = time.UnixMilli(startedAt).UTC()
+	run.Duration = time.Duration(durationMS) * time.Millisecond
+	return &run, nil
+}
+
+// PruneSyncRuns deletes the sync runs started before before and returns how
+// many it deleted.
+func (s *Store) PruneSyncRuns(ctx context.Context, before time.Time) (int64, error) {
+	const q = `DELETE FROM sync_runs WHERE instance = ? AND started_at < ?`
+	res, err := s.db.ExecContext(ctx, q, s.instance, before.UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("pruning sync runs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// Maintain lets SQLite refresh its query planner statistics and folds the
+// write-ahead log back into the database file. It affects the whole
+// database, not just the store's instance.
+func (s *Store) Maintain(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `PRAGMA optimize`); err != nil {
+		return fmt.Errorf("optimizing database: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return fmt.Errorf("checkpointing write-ahead log: %w", err)
+	}
+	return nil
+}
+
 // --- helpers -----------------------------------------------------------------
 
 // scanner matches both *sql.Row and *sql.Rows so scanItem can be reused.
@@ -209,6 +1330,9 @@ type scanner interface {
 func scanItem(s scanner) (*Item, error) {
 	var item Item
 	var remMod, haMod, syncedAt string
+	var base model.Item
+	var hasBase bool
+	var baseDue, baseAlert, baseDone string
 
 	err := s.Scan(
 		&item.ID,
@@ -220,6 +1344,17 @@ func scanItem(s scanner) (*Item, error) {
 		&remMod,
 		&haMod,
 		&syncedAt,
+		&hasBase,
+		&base.Description,
+		&baseDue,
+		&base.Priority,
+		&base.Completed,
+		&item.Pin,
+		&item.HASeenHash,
+		&base.RawPriority,
+		&baseAlert,
+		&baseDone,
+		&base.URL,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil //nolint:nilnil // intentional: "not found" sentinel
@@ -232,9 +1367,70 @@ func scanItem(s scanner) (*Item, error) {
 	item.HAModified, _ = parseTime(haMod)
 	item.LastSyncedAt, _ = parseTime(syncedAt)
 
+	if hasBase {
+		base.Title = item.Title
+		if due, err := parseTime(baseDue); err == nil && !due.IsZero() {
+			base.DueDate = &due
+		}
+		if alert, err := parseTime(baseAlert); err == nil && !alert.IsZero() {
+			base.AlertAt = &alert
+		}
+		if done, err := parseTime(baseDone); err == nil && !done.IsZero() {
+			base.CompletedAt = &done
+		}
+		item.Base = &base
+	}
+
 	return &item, nil
 }
 
+func scanShadowList(s scanner) (*ShadowList, error) {
+	var sl ShadowList
+	var lastPass string
+	var promoted, held int
+
+	err := s.Scan(
+		&sl.ListName,
+		&sl.Passes,
+		&sl.WouldCreate,
+		&sl.WouldUpdate,
+		&sl.WouldDelete,
+		&lastPass,
+		&promoted,
+		&held,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil //nolint:nilnil // intentional: "not found" sentinel
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning shadow list row: %w", err)
+	}
+
+	sl.LastPassAt, _ = parseTime(lastPass)
+	sl.Promoted = promoted != 0
+	sl.Held = held != 0
+
+	return &sl, nil
+}
+
+func scanJobRun(s scanner) (*JobRun, error) {
+	var run JobRun
+	var lastRun string
+	var durationMS int64
+
+	err := s.Scan(&run.Name, &run.Runs, &lastRun, &durationMS, &run.LastError)
+	if err == sql.ErrNoRows {
+		return nil, nil //nolint:nilnil // intentional: "not found" sentinel
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning job run row: %w", err)
+	}
+
+	run.LastRunAt, _ = parseTime(lastRun)
+	run.Duration = time.Duration(durationMS) * time.Millisecond
+	return &run, nil
+}
+
 func formatTime(t time.Time) string {
 	if t.IsZero() {
 		return ""
@@ -2,9 +2,13 @@ package state
 
 import (
 	"context"
+	"database/sql"
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
 )
 
 func openTestStore(t *testing.T) *Store {
@@ -134,6 +138,7 @@ func TestUpsert_UpdatePath(t *testing.T) {
 	// Update title and hash via a second upsert on the same RemindersUID.
 	item.Title = "Buy oat milk"
 	item.LastSyncHash = "newHash"
+	item.HASeenHash = "seenHash"
 	if err := s.UpsertItem(ctx, item); err != nil {
 		t.Fatalf("update UpsertItem: %v", err)
 	}
@@ -148,6 +153,9 @@ func TestUpsert_UpdatePath(t *testing.T) {
 	if got.LastSyncHash != "newHash" {
 		t.Errorf("LastSyncHash = %q, want %q", got.LastSyncHash, "newHash")
 	}
+	if got.HASeenHash != "seenHash" {
+		t.Errorf("HASeenHash = %q, want %q", got.HASeenHash, "seenHash")
+	}
 
 	// Must still be exactly one row.
 	all, err := s.GetAllItemsForList(ctx, "Shopping")
@@ -296,3 +304,732 @@ func TestDefaultDBPath(t *testing.T) {
 		t.Error("DefaultDBPath returned empty string")
 	}
 }
+
+func TestDefaultDBPath_XDGDataHome(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	for _, tt := range []struct {
+		dataHome string
+		want     string
+	}{
+		{"/data", "/data/reminderrelay/state.db"},
+		{"relative", filepath.Join(home, ".local", "share", "reminderrelay", "state.db")},
+	} {
+		t.Setenv("XDG_DATA_HOME", tt.dataHome)
+		if got, err := DefaultDBPath(); err != nil || got != tt.want {
+			t.Errorf("DefaultDBPath() with XDG_DATA_HOME=%q = %q, %v; want %q", tt.dataHome, got, err, tt.want)
+		}
+	}
+}
+
+func TestShadowList_RecordAndPromote(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	got, err := s.GetShadowList(ctx, "Groceries")
+	if err != nil {
+		t.Fatalf("GetShadowList: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("expected nil for unknown shadow list, got %+v", got)
+	}
+
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if err := s.RecordShadowPass(ctx, "Groceries", 4, 1, 0, at); err != nil {
+		t.Fatalf("RecordShadowPass #1: %v", err)
+	}
+	if err := s.RecordShadowPass(ctx, "Groceries", 2, 0, 1, at.Add(time.Minute)); err != nil {
+		t.Fatalf("RecordShadowPass #2: %v", err)
+	}
+
+	got, err = s.GetShadowList(ctx, "Groceries")
+	if err != nil {
+		t.Fatalf("GetShadowList: %v", err)
+	}
+	if got.Passes != 2 {
+		t.Errorf("Passes = %d, want 2", got.Passes)
+	}
+	if got.WouldCreate != 2 || got.WouldUpdate != 0 || got.WouldDelete != 1 {
+		t.Errorf("counts = %d/%d/%d, want 2/0/1 (latest pass)", got.WouldCreate, got.WouldUpdate, got.WouldDelete)
+	}
+	if !got.LastPassAt.Equal(at.Add(time.Minute)) {
+		t.Errorf("LastPassAt = %v, want %v", got.LastPassAt, at.Add(time.Minute))
+	}
+	if got.Promoted {
+		t.Error("Promoted = true before PromoteShadowList")
+	}
+
+	if err := s.PromoteShadowList(ctx, "Groceries"); err != nil {
+		t.Fatalf("PromoteShadowList: %v", err)
+	}
+	all, err := s.GetAllShadowLists(ctx)
+	if err != nil {
+		t.Fatalf("GetAllShadowLists: %v", err)
+	}
+	if len(all) != 1 || !all[0].Promoted || all[0].Passes != 2 {
+		t.Errorf("after promote got %+v, want one promoted list with 2 passes", all)
+	}
+}
+
+func TestDeleteList(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	for _, it := range []*Item{
+		{RemindersUID: "r1", HAUID: "h1", ListName: "Shopping", Title: "Milk"},
+		{RemindersUID: "r2", HAUID: "h2", ListName: "Shopping", Title: "Eggs"},
+		{RemindersUID: "r3", HAUID: "h3", ListName: "Work", Title: "Email"},
+	} {
+		if err := s.UpsertItem(ctx, it); err != nil {
+			t.Fatalf("UpsertItem %q: %v", it.Title, err)
+		}
+	}
+	if err := s.RecordShadowPass(ctx, "Shopping", 1, 0, 0, time.Now()); err != nil {
+		t.Fatalf("RecordShadowPass: %v", err)
+	}
+
+	n, err := s.DeleteList(ctx, "Shopping")
+	if err != nil {
+		t.Fatalf("DeleteList: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("DeleteList removed %d items, want 2", n)
+	}
+
+	if shopping, _ := s.GetAllItemsForList(ctx, "Shopping"); len(shopping) != 0 {
+		t.Errorf("Shopping list: got %d items after delete, want 0", len(shopping))
+	}
+	if work, _ := s.GetAllItemsForList(ctx, "Work"); len(work) != 1 {
+		t.Errorf("Work list: got %d items, want 1 (untouched)", len(work))
+	}
+	if sl, _ := s.GetShadowList(ctx, "Shopping"); sl != nil {
+		t.Errorf("shadow state not removed: %+v", sl)
+	}
+}
+
+func TestRenameList(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	if err := s.UpsertItem(ctx, &Item{RemindersUID: "r1", HAUID: "h1", ListName: "Shopping", Title: "Milk"}); err != nil {
+		t.Fatalf("UpsertItem: %v", err)
+	}
+	if err := s.RecordShadowPass(ctx, "Shopping", 1, 0, 0, time.Now()); err != nil {
+		t.Fatalf("RecordShadowPass: %v", err)
+	}
+	if err := s.SetListID(ctx, "Shopping", "cal-1"); err != nil {
+		t.Fatalf("SetListID: %v", err)
+	}
+
+	if err := s.RenameList(ctx, "Shopping", "Groceries"); err != nil {
+		t.Fatalf("RenameList: %v", err)
+	}
+	if items, _ := s.GetAllItemsForList(ctx, "Groceries"); len(items) != 1 {
+		t.Errorf("Groceries list: got %d items, want 1", len(items))
+	}
+	if sl, _ := s.GetShadowList(ctx, "Groceries"); sl == nil || sl.Passes != 1 {
+		t.Errorf("shadow state not moved: %+v", sl)
+	}
+	ids, err := s.ListIDs(ctx)
+	if err != nil {
+		t.Fatalf("ListIDs: %v", err)
+	}
+	if len(ids) != 1 || ids["Groceries"] != "cal-1" {
+		t.Errorf("ListIDs = %v, want Groceries → cal-1", ids)
+	}
+
+	// The identifier follows the list when it is recorded under a new name.
+	if err := s.SetListID(ctx, "Food", "cal-1"); err != nil {
+		t.Fatalf("SetListID: %v", err)
+	}
+	if ids, _ := s.ListIDs(ctx); len(ids) != 1 || ids["Food"] != "cal-1" {
+		t.Errorf("ListIDs after re-recording = %v, want Food → cal-1", ids)
+	}
+
+	if err := s.UpsertItem(ctx, &Item{RemindersUID: "r2", HAUID: "h2", ListName: "Work", Title: "Email"}); err != nil {
+		t.Fatalf("UpsertItem: %v", err)
+	}
+	if err := s.RenameList(ctx, "Groceries", "Work"); err == nil {
+		t.Error("RenameList onto a list with state succeeded, want error")
+	}
+}
+
+func TestAccounts_ChangedAndForget(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	if err := s.RecordAccounts(ctx, map[string][]string{
+		"iCloud":    {"cal-1", "cal-2"},
+		"On My Mac": {"cal-3"},
+	}); err != nil {
+		t.Fatalf("RecordAccounts: %v", err)
+	}
+
+	// A list added or removed keeps the account.
+	changed, err := s.ChangedAccounts(ctx, map[string][]string{
+		"iCloud":    {"cal-2", "cal-4"},
+		"On My Mac": {"cal-3"},
+	})
+	if err != nil {
+		t.Fatalf("ChangedAccounts: %v", err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("ChangedAccounts = %v, want none", changed)
+	}
+
+	// Another iCloud account has the same name but new identifiers.
+	changed, err = s.ChangedAccounts(ctx, map[string][]string{
+		"iCloud":    {"cal-5", "cal-6"},
+		"On My Mac": {"cal-3"},
+	})
+	if err != nil {
+		t.Fatalf("ChangedAccounts: %v", err)
+	}
+	if len(changed) != 1 || changed[0] != "iCloud" {
+		t.Errorf("ChangedAccounts = %v, want [iCloud]", changed)
+	}
+
+	for _, it := range []*Item{
+		{RemindersUID: "r1", HAUID: "h1", ListName: "Shopping", Title: "Milk"},
+		{RemindersUID: "r2", HAUID: "h2", ListName: "Work", Title: "Email"},
+	} {
+		if err := s.UpsertItem(ctx, it); err != nil {
+			t.Fatalf("UpsertItem %q: %v", it.Title, err)
+		}
+	}
+	if err := s.SetListID(ctx, "Shopping", "cal-1"); err != nil {
+		t.Fatalf("SetListID: %v", err)
+	}
+	other := s.ForInstance("work")
+	if err := other.UpsertItem(ctx, &Item{RemindersUID: "r3", HAUID: "h3", ListName: "Work", Title: "Call"}); err != nil {
+		t.Fatalf("UpsertItem: %v", err)
+	}
+
+	n, err := s.Forget(ctx)
+	if err != nil {
+		t.Fatalf("Forget: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Forget removed %d items, want 2", n)
+	}
+	if empty, _ := s.IsEmpty(ctx); !empty {
+		t.Error("store not empty after Forget")
+	}
+	if ids, _ := s.ListIDs(ctx); len(ids) != 0 {
+		t.Errorf("ListIDs after Forget = %v, want none", ids)
+	}
+	if empty, _ := other.IsEmpty(ctx); empty {
+		t.Error("Forget removed the items of another instance")
+	}
+}
+
+func TestOrphanedLists(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	for _, it := range []*Item{
+		{RemindersUID: "r1", HAUID: "h1", ListName: "Shopping", Title: "Milk"},
+		{RemindersUID: "r2", HAUID: "h2", ListName: "Old", Title: "Eggs"},
+		{RemindersUID: "r3", HAUID: "h3", ListName: "Old", Title: "Bread"},
+	} {
+		if err := s.UpsertItem(ctx, it); err != nil {
+			t.Fatalf("UpsertItem %q: %v", it.Title, err)
+		}
+	}
+	if err := s.RecordShadowPass(ctx, "Old", 1, 0, 0, time.Now()); err != nil {
+		t.Fatalf("RecordShadowPass: %v", err)
+	}
+	if err := s.RecordShadowPass(ctx, "Abandoned", 1, 0, 0, time.Now()); err != nil {
+		t.Fatalf("RecordShadowPass: %v", err)
+	}
+
+	got, err := s.OrphanedLists(ctx, map[string]string{"Shopping": "todo.shopping"})
+	if err != nil {
+		t.Fatalf("OrphanedLists: %v", err)
+	}
+	want := []OrphanedList{{ListName: "Abandoned", Items: 0}, {ListName: "Old", Items: 2}}
+	if len(got) != len(want) {
+		t.Fatalf("OrphanedLists = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("OrphanedLists[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestItemCounts(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	for _, it := range []*Item{
+		{RemindersUID: "r1", HAUID: "h1", ListName: "Shopping", Title: "Milk"},
+		{RemindersUID: "r2", HAUID: "h2", ListName: "Shopping", Title: "Eggs"},
+		{RemindersUID: "r3", HAUID: "h3", ListName: "Work", Title: "Report"},
+	} {
+		if err := s.UpsertItem(ctx, it); err != nil {
+			t.Fatalf("UpsertItem %q: %v", it.Title, err)
+		}
+	}
+	if err := s.ForInstance("other").UpsertItem(ctx, &Item{RemindersUID: "r4", HAUID: "h4", ListName: "Work", Title: "X"}); err != nil {
+		t.Fatalf("UpsertItem: %v", err)
+	}
+
+	got, err := s.ItemCounts(ctx)
+	if err != nil {
+		t.Fatalf("ItemCounts: %v", err)
+	}
+	if len(got) != 2 || got["Shopping"] != 2 || got["Work"] != 1 {
+		t.Errorf("ItemCounts = %v, want Shopping:2 Work:1", got)
+	}
+}
+
+func TestForInstance_IsolatesRows(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	home, work := s.ForInstance("home"), s.ForInstance("work")
+
+	// The same Reminders UID may be tracked by two instances.
+	for _, st := range []*Store{home, work} {
+		if err := st.UpsertItem(ctx, &Item{RemindersUID: "r1", ListName: "Shopping", Title: st.Instance()}); err != nil {
+			t.Fatalf("UpsertItem (%s): %v", st.Instance(), err)
+		}
+	}
+	if err := work.RecordShadowPass(ctx, "Shopping", 1, 0, 0, time.Now()); err != nil {
+		t.Fatalf("RecordShadowPass: %v", err)
+	}
+
+	got, err := home.GetItemByRemindersUID(ctx, "r1")
+	if err != nil || got == nil {
+		t.Fatalf("GetItemByRemindersUID = %v, %v", got, err)
+	}
+	if got.Title != "home" {
+		t.Errorf("home item title = %q, want %q", got.Title, "home")
+	}
+	if sl, _ := home.GetShadowList(ctx, "Shopping"); sl != nil {
+		t.Errorf("home sees work's shadow state: %+v", sl)
+	}
+	if empty, _ := s.IsEmpty(ctx); !empty {
+		t.Error("default instance IsEmpty = false, want true")
+	}
+
+	if n, _ := home.DeleteList(ctx, "Shopping"); n != 1 {
+		t.Errorf("DeleteList removed %d items, want 1", n)
+	}
+	if items, _ := work.GetAllItemsForList(ctx, "Shopping"); len(items) != 1 {
+		t.Errorf("work items after home delete = %d, want 1", len(items))
+	}
+}
+
+func TestOpen_MigratesUnversionedDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	const legacy = `
+CREATE TABLE sync_items (
+    id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+    reminders_uid      TEXT    NOT NULL DEFAULT '',
+    ha_uid             TEXT    NOT NULL DEFAULT '',
+    list_name          TEXT    NOT NULL,
+    title              TEXT    NOT NULL,
+    last_sync_hash     TEXT    NOT NULL DEFAULT '',
+    reminders_modified TEXT    NOT NULL DEFAULT '',
+    ha_modified        TEXT    NOT NULL DEFAULT '',
+    last_synced_at     TEXT    NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX idx_reminders_uid ON sync_items (reminders_uid) WHERE reminders_uid != '';
+CREATE UNIQUE INDEX idx_ha_uid        ON sync_items (ha_uid)        WHERE ha_uid != '';
+CREATE INDEX        idx_list_name     ON sync_items (list_name);
+CREATE TABLE shadow_lists (
+    list_name    TEXT    PRIMARY KEY,
+    passes       INTEGER NOT NULL DEFAULT 0,
+    would_create INTEGER NOT NULL DEFAULT 0,
+    would_update INTEGER NOT NULL DEFAULT 0,
+    would_delete INTEGER NOT NULL DEFAULT 0,
+    last_pass_at TEXT    NOT NULL DEFAULT '',
+    promoted     INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO sync_items (reminders_uid, ha_uid, list_name, title) VALUES ('r1', 'h1', 'Shopping', 'Milk');
+INSERT INTO shadow_lists (list_name, passes, promoted) VALUES ('Shopping', 3, 1);
+`
+	if _, err := db.Exec(legacy); err != nil {
+		t.Fatalf("creating legacy schema: %v", err)
+	}
+	_ = db.Close()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+
+	got, err := s.GetItemByHAUID(ctx, "h1")
+	if err != nil || got == nil {
+		t.Fatalf("GetItemByHAUID = %v, %v", got, err)
+	}
+	if got.Title != "Milk" || got.RemindersUID != "r1" {
+		t.Errorf("migrated item = %+v, want Milk/r1", got)
+	}
+	sl, err := s.GetShadowList(ctx, "Shopping")
+	if err != nil || sl == nil {
+		t.Fatalf("GetShadowList = %v, %v", sl, err)
+	}
+	if sl.Passes != 3 || !sl.Promoted {
+		t.Errorf("migrated shadow list = %+v, want 3 passes, promoted", sl)
+	}
+
+	// Re-opening a migrated database is a no-op.
+	_ = s.Close()
+	s2, err := Open(path)
+	if err != nil {
+		t.Fatalf("re-Open: %v", err)
+	}
+	_ = s2.Close()
+}
+
+func TestOpen_MigratesDBWithoutShadowLists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	const legacy = `
+CREATE TABLE sync_items (
+    id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+    reminders_uid      TEXT    NOT NULL DEFAULT '',
+    ha_uid             TEXT    NOT NULL DEFAULT '',
+    list_name          TEXT    NOT NULL,
+    title              TEXT    NOT NULL,
+    last_sync_hash     TEXT    NOT NULL DEFAULT '',
+    reminders_modified TEXT    NOT NULL DEFAULT '',
+    ha_modified        TEXT    NOT NULL DEFAULT '',
+    last_synced_at     TEXT    NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX idx_reminders_uid ON sync_items (reminders_uid) WHERE reminders_uid != '';
+CREATE UNIQUE INDEX idx_ha_uid        ON sync_items (ha_uid)        WHERE ha_uid != '';
+CREATE INDEX        idx_list_name     ON sync_items (list_name);
+INSERT INTO sync_items (reminders_uid, ha_uid, list_name, title, last_sync_hash)
+VALUES ('r1', 'h1', 'Shopping', 'Milk', 'abc'), ('r2', 'h2', 'Work', 'Report', 'def');
+`
+	if _, err := db.Exec(legacy); err != nil {
+		t.Fatalf("creating legacy schema: %v", err)
+	}
+	_ = db.Close()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+
+	var version int
+	if err := s.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatalf("reading user_version: %v", err)
+	}
+	if version != schemaVersion {
+		t.Errorf("user_version = %d, want %d", version, schemaVersion)
+	}
+	for _, want := range []struct{ uid, list, title, hash string }{
+		{"h1", "Shopping", "Milk", "abc"},
+		{"h2", "Work", "Report", "def"},
+	} {
+		got, err := s.GetItemByHAUID(ctx, want.uid)
+		if err != nil || got == nil {
+			t.Fatalf("GetItemByHAUID(%q) = %v, %v", want.uid, got, err)
+		}
+		if got.ListName != want.list || got.Title != want.title || got.LastSyncHash != want.hash {
+			t.Errorf("migrated item = %+v, want %s/%s/%s", got, want.list, want.title, want.hash)
+		}
+	}
+	if sl, err := s.GetShadowList(ctx, "Shopping"); err != nil || sl != nil {
+		t.Errorf("GetShadowList = %+v, %v; want none", sl, err)
+	}
+}
+
+func TestUpsert_BaseRoundTrip(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	due := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	alert := due.Add(-time.Hour)
+	done := due.Add(time.Hour)
+
+	withBase := &Item{
+		RemindersUID: "r1", ListName: "Shopping", Title: "Milk",
+		Base: &model.Item{Title: "Milk", Description: "2%", DueDate: &due, AlertAt: &alert, Priority: model.PriorityHigh, RawPriority: 2, URL: "https://example.com/milk", Completed: true, CompletedAt: &done},
+	}
+	noBase := &Item{RemindersUID: "r2", ListName: "Shopping", Title: "Eggs"}
+	for _, it := range []*Item{withBase, noBase} {
+		if err := s.UpsertItem(ctx, it); err != nil {
+			t.Fatalf("UpsertItem %q: %v", it.Title, err)
+		}
+	}
+
+	got, err := s.GetItemByRemindersUID(ctx, "r1")
+	if err != nil || got == nil || got.Base == nil {
+		t.Fatalf("GetItemByRemindersUID = %+v, %v; want item with base", got, err)
+	}
+	b := got.Base
+	if b.Title != "Milk" || b.Description != "2%" || b.Priority != model.PriorityHigh || b.RawPriority != 2 || !b.Completed ||
+		b.DueDate == nil || !b.DueDate.Equal(due) || b.AlertAt == nil || !b.AlertAt.Equal(alert) ||
+		b.CompletedAt == nil || !b.CompletedAt.Equal(done) || b.URL != "https://example.com/milk" {
+		t.Errorf("base = %+v, want %+v", b, withBase.Base)
+	}
+
+	if got, _ := s.GetItemByRemindersUID(ctx, "r2"); got == nil || got.Base != nil {
+		t.Errorf("item without base: got %+v, want Base nil", got)
+	}
+}
+
+func TestOpen_UpgradesVersion1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v1.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	const v1 = `
+CREATE TABLE sync_items (
+    id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+    instance           TEXT    NOT NULL DEFAULT '',
+    reminders_uid      TEXT    NOT NULL DEFAULT '',
+    ha_uid             TEXT    NOT NULL DEFAULT '',
+    list_name          TEXT    NOT NULL,
+    title              TEXT    NOT NULL,
+    last_sync_hash     TEXT    NOT NULL DEFAULT '',
+    reminders_modified TEXT    NOT NULL DEFAULT '',
+    ha_modified        TEXT    NOT NULL DEFAULT '',
+    last_synced_at     TEXT    NOT NULL DEFAULT ''
+);
+CREATE TABLE shadow_lists (
+    instance     TEXT    NOT NULL DEFAULT '',
+    list_name    TEXT    NOT NULL,
+    passes       INTEGER NOT NULL DEFAULT 0,
+    would_create INTEGER NOT NULL DEFAULT 0,
+    would_update INTEGER NOT NULL DEFAULT 0,
+    would_delete INTEGER NOT NULL DEFAULT 0,
+    last_pass_at TEXT    NOT NULL DEFAULT '',
+    promoted     INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (instance, list_name)
+);
+INSERT INTO sync_items (reminders_uid, ha_uid, list_name, title) VALUES ('r1', 'h1', 'Shopping', 'Milk');
+PRAGMA user_version = 1;
+`
+	if _, err := db.Exec(v1); err != nil {
+		t.Fatalf("creating v1 schema: %v", err)
+	}
+	_ = db.Close()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	got, err := s.GetItemByRemindersUID(context.Background(), "r1")
+	if err != nil || got == nil {
+		t.Fatalf("GetItemByRemindersUID = %v, %v", got, err)
+	}
+	if got.Base != nil || got.Pin != PinNone {
+		t.Errorf("upgraded row Base = %+v, Pin = %q; want nil, unpinned", got.Base, got.Pin)
+	}
+}
+
+func TestSetPin_SurvivesUpsert(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	item := sampleItem()
+	if err := s.UpsertItem(ctx, item); err != nil {
+		t.Fatalf("UpsertItem: %v", err)
+	}
+	if n, err := s.SetPin(ctx, "Shopping", "Buy milk", PinHA); err != nil || n != 1 {
+		t.Fatalf("SetPin = %d, %v; want 1, nil", n, err)
+	}
+	if n, _ := s.SetPin(ctx, "Shopping", "Buy bread", PinHA); n != 0 {
+		t.Errorf("SetPin on untracked title = %d, want 0", n)
+	}
+
+	// A sync pass holding a stale copy must not clear the pin.
+	item.LastSyncHash = "def456"
+	if err := s.UpsertItem(ctx, item); err != nil {
+		t.Fatalf("UpsertItem: %v", err)
+	}
+	got, _ := s.GetItemByRemindersUID(ctx, item.RemindersUID)
+	if got == nil || got.Pin != PinHA {
+		t.Fatalf("Pin after upsert = %+v, want %q", got, PinHA)
+	}
+
+	pinned, err := s.PinnedItems(ctx)
+	if err != nil || len(pinned) != 1 || pinned[0].Title != "Buy milk" {
+		t.Errorf("PinnedItems = %v, %v; want Buy milk", pinned, err)
+	}
+
+	if _, err := s.SetPin(ctx, "Shopping", "Buy milk", PinNone); err != nil {
+		t.Fatalf("SetPin(PinNone): %v", err)
+	}
+	if pinned, _ := s.PinnedItems(ctx); len(pinned) != 0 {
+		t.Errorf("PinnedItems after unpin = %d, want 0", len(pinned))
+	}
+}
+
+func TestJobRuns_RecordAndGet(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	if got, err := s.GetJobRun(ctx, "maintenance"); err != nil || got != nil {
+		t.Fatalf("GetJobRun before any run = %+v, %v; want nil, nil", got, err)
+	}
+
+	at := time.Date(2026, 2, 1, 3, 0, 0, 0, time.UTC)
+	if err := s.RecordJobRun(ctx, JobRun{Name: "maintenance", LastRunAt: at, Duration: 1500 * time.Millisecond}); err != nil {
+		t.Fatalf("RecordJobRun: %v", err)
+	}
+	if err := s.RecordJobRun(ctx, JobRun{Name: "maintenance", LastRunAt: at.Add(time.Hour), LastError: "disk full"}); err != nil {
+		t.Fatalf("RecordJobRun: %v", err)
+	}
+
+	got, err := s.GetJobRun(ctx, "maintenance")
+	if err != nil || got == nil {
+		t.Fatalf("GetJobRun = %+v, %v", got, err)
+	}
+	if got.Runs != 2 || !got.LastRunAt.Equal(at.Add(time.Hour)) || got.LastError != "disk full" {
+		t.Errorf("job run = %+v, want 2 runs, latest at %v with error", got, at.Add(time.Hour))
+	}
+
+	all, err := s.GetAllJobRuns(ctx)
+	if err != nil || len(all) != 1 {
+		t.Errorf("GetAllJobRuns = %d, %v; want 1", len(all), err)
+	}
+	if err := s.Maintain(ctx); err != nil {
+		t.Errorf("Maintain: %v", err)
+	}
+}
+
+func TestSyncRuns_SummarizeAndPrune(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	at := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+
+	if last, err := s.LastSuccessfulSyncRun(ctx); err != nil || last != nil {
+		t.Fatalf("LastSuccessfulSyncRun before any run = %+v, %v; want nil, nil", last, err)
+	}
+	for _, run := range []SyncRun{
+		{Kind: "full", StartedAt: at.Add(-48 * time.Hour), Duration: time.Second, Created: 10},
+		{Kind: "full", StartedAt: at, Duration: 2 * time.Second, Created: 1, Updated: 2, Conflicts: 1},
+		{Kind: "ha_event", StartedAt: at.Add(time.Minute), Duration: 4 * time.Second, Errors: 1, Error: "connection refused"},
+	} {
+		if err := s.RecordSyncRun(ctx, run); err != nil {
+			t.Fatalf("RecordSyncRun: %v", err)
+		}
+	}
+
+	sum, err := s.SummarizeSyncRuns(ctx, at.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("SummarizeSyncRuns: %v", err)
+	}
+	if sum.Runs != 2 || sum.Failed != 1 || sum.Created != 1 || sum.Updated != 2 || sum.Conflicts != 1 || sum.Errors != 1 {
+		t.Errorf("summary = %+v, want 2 runs of the last hour, 1 failed", sum)
+	}
+	if sum.Duration != 6*time.Second || sum.MaxDuration != 4*time.Second || !sum.First.Equal(at) || !sum.Last.Equal(at.Add(time.Minute)) {
+		t.Errorf("summary durations = %+v, want 6s total, 4s longest", sum)
+	}
+
+	last, err := s.LastSuccessfulSyncRun(ctx)
+	if err != nil || last == nil || !last.StartedAt.Equal(at) || last.Updated != 2 {
+		t.Errorf("LastSuccessfulSyncRun = %+v, %v; want the run at %v", last, err, at)
+	}
+
+	if n, err := s.PruneSyncRuns(ctx, at.Add(-time.Hour)); err != nil || n != 1 {
+		t.Errorf("PruneSyncRuns = %d, %v; want 1", n, err)
+	}
+	if sum, _ := s.SummarizeSyncRuns(ctx, time.Time{}); sum.Runs != 2 {
+		t.Errorf("runs after pruning = %d, want 2", sum.Runs)
+	}
+}
+
+func TestOutbox_QueueReplayOrder(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	at := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+
+	for _, o := range []Outbound{
+		{ListName: "Shopping", RemindersUID: "rem-1", Action: "create", Title: "Milk", QueuedAt: at, LastError: "connection refused"},
+		{ListName: "Shopping", RemindersUID: "rem-2", HAUID: "ha-2", Action: "update", Title: "Eggs", QueuedAt: at},
+		// A second failure of the first write keeps its place.
+		{ListName: "Shopping", RemindersUID: "rem-1", Action: "create", Title: "Oat milk", QueuedAt: at.Add(time.Minute), LastError: "timeout"},
+		{ListName: "Work", RemindersUID: "rem-3", HAUID: "ha-3", Action: "delete", Title: "Report", QueuedAt: at},
+	} {
+		if err := s.QueueOutbound(ctx, o); err != nil {
+			t.Fatalf("QueueOutbound: %v", err)
+		}
+	}
+
+	queued, err := s.QueuedOutbound(ctx, "Shopping")
+	if err != nil || len(queued) != 2 {
+		t.Fatalf("QueuedOutbound = %d, %v; want 2", len(queued), err)
+	}
+	first := queued[0]
+	if first.RemindersUID != "rem-1" || first.Title != "Oat milk" || first.Attempts != 2 ||
+		first.LastError != "timeout" || !first.QueuedAt.Equal(at) {
+		t.Errorf("first queued = %+v, want rem-1 retitled, 2 attempts, queued at %v", first, at)
+	}
+	if queued[1].RemindersUID != "rem-2" {
+		t.Errorf("second queued = %+v, want rem-2", queued[1])
+	}
+
+	counts, err := s.OutboundCounts(ctx)
+	if err != nil || counts["Shopping"] != 2 || counts["Work"] != 1 {
+		t.Errorf("OutboundCounts = %v, %v; want Shopping 2, Work 1", counts, err)
+	}
+
+	if err := s.DeleteOutbound(ctx, first.ID); err != nil {
+		t.Fatalf("DeleteOutbound: %v", err)
+	}
+	if _, err := s.DeleteList(ctx, "Work"); err != nil {
+		t.Fatalf("DeleteList: %v", err)
+	}
+	counts, _ = s.OutboundCounts(ctx)
+	if len(counts) != 1 || counts["Shopping"] != 1 {
+		t.Errorf("OutboundCounts after deletions = %v, want Shopping 1", counts)
+	}
+}
+
+func TestIntents_BeginEnd(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	at := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+
+	milk, err := s.BeginIntent(ctx, Intent{ListName: "Shopping", RemindersUID: "rem-1", Action: "create_in_ha", Title: "Milk", StartedAt: at})
+	if err != nil {
+		t.Fatalf("BeginIntent: %v", err)
+	}
+	if _, err := s.BeginIntent(ctx, Intent{ListName: "Shopping", HAUID: "ha-2", Action: "create_in_reminders", Title: "Eggs", StartedAt: at}); err != nil {
+		t.Fatalf("BeginIntent: %v", err)
+	}
+	if _, err := s.BeginIntent(ctx, Intent{ListName: "Work", RemindersUID: "rem-3", Action: "delete_from_ha", Title: "Report", StartedAt: at}); err != nil {
+		t.Fatalf("BeginIntent: %v", err)
+	}
+
+	intents, err := s.Intents(ctx, "Shopping")
+	if err != nil || len(intents) != 2 {
+		t.Fatalf("Intents = %d, %v; want 2", len(intents), err)
+	}
+	if first := intents[0]; first.ID != milk || first.RemindersUID != "rem-1" || first.Action != "create_in_ha" || !first.StartedAt.Equal(at) {
+		t.Errorf("first intent = %+v, want the Milk create started at %v", first, at)
+	}
+
+	if err := s.EndIntent(ctx, milk); err != nil {
+		t.Fatalf("EndIntent: %v", err)
+	}
+	if intents, _ := s.Intents(ctx, "Shopping"); len(intents) != 1 || intents[0].Title != "Eggs" {
+		t.Errorf("Intents after EndIntent = %+v, want Eggs only", intents)
+	}
+	if _, err := s.DeleteList(ctx, "Work"); err != nil {
+		t.Fatalf("DeleteList: %v", err)
+	}
+	if intents, _ := s.Intents(ctx, "Work"); len(intents) != 0 {
+		t.Errorf("Intents of a deleted list = %+v, want none", intents)
+	}
+}
@@ -0,0 +1,175 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// AlertPolicy says where a list mapping target keeps the alert time of
+// Reminders items, which no target has a field for.
+type AlertPolicy string
+
+// Alert policies.
+const (
+	// AlertsOff leaves alerts out of the sync. Alerts set in Reminders are
+	// kept there and never written to the target.
+	AlertsOff AlertPolicy = "off"
+
+	// AlertsDue writes the alert time as the target's due date. The
+	// Reminders due date is then left alone, as for a field the target
+	// cannot store.
+	AlertsDue AlertPolicy = "due"
+
+	// AlertsDescription keeps the alert time in a tag at the start of the
+	// target's description, such as "[Alert 2026-03-01 09:00 +0100] ".
+	AlertsDescription AlertPolicy = "description"
+)
+
+// AlertPolicies returns the names of the alert policies.
+func AlertPolicies() []string {
+	return []string{string(AlertsOff), string(AlertsDue), string(AlertsDescription)}
+}
+
+// SetAlertPolicy makes target keep alerts as policy says. It must be called
+// before the registry is used. Targets without a policy are treated as
+// [AlertsOff].
+func (r *Registry) SetAlertPolicy(target string, policy AlertPolicy) error {
+	switch policy {
+	case AlertsOff, AlertsDue, AlertsDescription:
+	default:
+		return fmt.Errorf("unknown alert policy %q (known: %s)", policy, strings.Join(AlertPolicies(), ", "))
+	}
+	if r.alerts == nil {
+		r.alerts = make(map[string]AlertPolicy)
+	}
+	r.alerts[target] = policy
+	return nil
+}
+
+// alertBackend stores the alert time of items on a backend without an alert
+// field, in the field its policy names.
+type alertBackend struct {
+	next   TaskBackend
+	policy AlertPolicy
+}
+
+// withAlerts returns b wrapped to keep alerts as policy says, or b itself
+// under [AlertsOff].
+func withAlerts(b TaskBackend, policy AlertPolicy) TaskBackend {
+	if policy == "" || policy == AlertsOff {
+		return b
+	}
+	return &alertBackend{next: b, policy: policy}
+}
+
+// Fetch returns the items on lists with their alert times read back.
+func (b *alertBackend) Fetch(ctx context.Context, lists []string) ([]*model.Item, error) {
+	items, err := b.next.Fetch(ctx, lists)
+	if err != nil {
+		return nil, err
+	}
+	for _, it := range items {
+		switch b.policy {
+		case AlertsDue:
+			it.AlertAt, it.DueDate = it.DueDate, nil
+		case AlertsDescription:
+			it.AlertAt, it.Description = decodeAlertTag(it.Description)
+		}
+	}
+	return items, nil
+}
+
+// Create adds item to list with its alert time encoded.
+func (b *alertBackend) Create(ctx context.Context, list string, item *model.Item) (string, error) {
+	return b.next.Create(ctx, list, b.encode(item))
+}
+
+// Update writes item over current with its alert time encoded. A changed
+// alert counts as a change of the field that holds it.
+func (b *alertBackend) Update(ctx context.Context, list string, current, item *model.Item, fields model.Fields) error {
+	switch b.policy {
+	case AlertsDue:
+		f := fields &^ (model.FieldDueDate | model.FieldAlert)
+		if fields.Has(model.FieldAlert) {
+			f |= model.FieldDueDate
+		}
+		fields = f
+	case AlertsDescription:
+		if fields.Has(model.FieldAlert) {
+			fields = fields&^model.FieldAlert | model.FieldDescription
+		}
+	}
+	return b.next.Update(ctx, list, b.encode(current), b.encode(item), fields)
+}
+
+// Delete removes current from list.
+func (b *alertBackend) Delete(ctx context.Context, list string, current *model.Item) error {
+	return b.next.Delete(ctx, list, b.encode(current))
+}
+
+// SupportedFields returns the fields list can store, with the alert in
+// place of the due date under [AlertsDue], and in addition to the
+// description under [AlertsDescription].
+func (b *alertBackend) SupportedFields(list string) model.Fields {
+	f := model.AllFields &^ unsupportedFields(b.next, list)
+	switch {
+	case b.policy == AlertsDue && f.Has(model.FieldDueDate):
+		f = f&^model.FieldDueDate | model.FieldAlert
+	case b.policy == AlertsDescription && f.Has(model.FieldDescription):
+		f |= model.FieldAlert
+	}
+	return f
+}
+
+// encode returns a copy of item with its alert time in the field the policy
+// names.
+func (b *alertBackend) encode(item *model.Item) *model.Item {
+	c := *item
+	switch b.policy {
+	case AlertsDue:
+		c.DueDate = item.AlertAt
+	case AlertsDescription:
+		c.Description = encodeAlertTag(item.AlertAt, item.Description)
+	}
+	c.AlertAt = nil
+	return &c
+}
+
+const (
+	alertTagPrefix = "[Alert "
+	alertTagSuffix = "]"
+	alertTagLayout = "2006-01-02 15:04 -0700"
+)
+
+// encodeAlertTag prepends a tag holding at to description, or returns it
+// unchanged if at is nil.
+func encodeAlertTag(at *time.Time, description string) string {
+	if at == nil {
+		return description
+	}
+	return alertTagPrefix + at.Format(alertTagLayout) + alertTagSuffix + " " + description
+}
+
+// decodeAlertTag strips the tag written by [encodeAlertTag] from
+// description and returns the time it holds. The space after the tag is
+// optional, as a target may trim it from an otherwise empty description. A
+// description without a valid tag is returned unchanged with a nil time.
+func decodeAlertTag(description string) (*time.Time, string) {
+	rest, ok := strings.CutPrefix(description, alertTagPrefix)
+	if !ok {
+		return nil, description
+	}
+	value, rest, ok := strings.Cut(rest, alertTagSuffix)
+	if !ok {
+		return nil, description
+	}
+	at, err := time.Parse(alertTagLayout, value)
+	if err != nil {
+		return nil, description
+	}
+	return &at, strings.TrimPrefix(rest, " ")
+}
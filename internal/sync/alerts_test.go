@@ -0,0 +1,105 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+func TestAlertTag_RoundTrip(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	desc := encodeAlertTag(&at, "2% milk")
+	if desc != "[Alert 2026-03-01 09:30 +0100] 2% milk" {
+		t.Fatalf("encodeAlertTag = %q", desc)
+	}
+	got, rest := decodeAlertTag(desc)
+	if got == nil || !got.Equal(at) || rest != "2% milk" {
+		t.Errorf("decodeAlertTag(%q) = %v, %q; want %v, %q", desc, got, rest, at, "2% milk")
+	}
+
+	// A target may trim the space left after the tag of an empty description.
+	if got, rest := decodeAlertTag("[Alert 2026-03-01 09:30 +0100]"); got == nil || rest != "" {
+		t.Errorf("trimmed tag: got %v, %q; want the alert and an empty description", got, rest)
+	}
+	for _, desc := range []string{"[Alert soon] milk", "[Alert 2026-03-01 09:30 +0100 milk", "milk"} {
+		if got, rest := decodeAlertTag(desc); got != nil || rest != desc {
+			t.Errorf("decodeAlertTag(%q) = %v, %q; want it unchanged", desc, got, rest)
+		}
+	}
+}
+
+func TestReconcile_AlertsAsDueDate(t *testing.T) {
+	mod := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	alert := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+
+	item := newItem("rem-1", "Bins out", "Shopping", model.PriorityNone, false, mod)
+	item.DueDate, item.AlertAt = &due, &alert
+	rem := newMockReminders(item)
+	ha := newMockHA()
+	store := newMockStore()
+	targets := NewRegistry(ha)
+	if err := targets.SetAlertPolicy("todo.shopping", AlertsDue); err != nil {
+		t.Fatal(err)
+	}
+	r := NewReconciler(rem, targets, store, testLogger)
+
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	items := ha.getItems("todo.shopping")
+	if len(items) != 1 || items[0].DueDate == nil || !items[0].DueDate.Equal(alert) || items[0].AlertAt != nil {
+		t.Fatalf("HA items = %+v, want one due at the alert time", items)
+	}
+
+	// Moving the due date in HA moves the alert; the Reminders due date stays.
+	moved := alert.Add(time.Hour)
+	items[0].DueDate = &moved
+	items[0].ModifiedAt = mod.Add(time.Hour)
+	stats, err := r.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if stats.Updated != 1 {
+		t.Errorf("Updated = %d, want 1", stats.Updated)
+	}
+	got := rem.get("rem-1")
+	if got.AlertAt == nil || !got.AlertAt.Equal(moved) {
+		t.Errorf("Reminders alert = %v, want %v", got.AlertAt, moved)
+	}
+	if got.DueDate == nil || !got.DueDate.Equal(due) {
+		t.Errorf("Reminders due date = %v, want %v", got.DueDate, due)
+	}
+
+	if stats, err := r.Run(context.Background(), testMappings); err != nil || stats.Updated != 0 {
+		t.Errorf("third run: stats %+v, err %v; want nothing to update", stats, err)
+	}
+}
+
+func TestReconcile_AlertsOffLeavesThemInReminders(t *testing.T) {
+	mod := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	alert := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+
+	item := newItem("rem-1", "Bins out", "Shopping", model.PriorityNone, false, mod)
+	item.AlertAt = &alert
+	rem := newMockReminders(item)
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Bins out", ModifiedAt: mod})
+	store := newMockStore()
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+
+	for pass := 1; pass <= 2; pass++ {
+		stats, err := r.Run(context.Background(), testMappings)
+		if err != nil {
+			t.Fatalf("pass %d: %v", pass, err)
+		}
+		if stats.Updated != 0 {
+			t.Errorf("pass %d: Updated = %d, want 0", pass, stats.Updated)
+		}
+	}
+	if got := rem.get("rem-1"); got.AlertAt == nil || !got.AlertAt.Equal(alert) {
+		t.Errorf("Reminders alert = %v, want %v", got.AlertAt, alert)
+	}
+}
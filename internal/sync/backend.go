@@ -0,0 +1,126 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// TaskBackend is a provider of todo lists that the reconciler syncs with.
+// Apple Reminders is always the source side of a sync; the target side of
+// each list mapping is resolved through a [Registry].
+//
+// Adding a provider means implementing TaskBackend and registering it with
+// [Registry.Register]; the reconciler, bootstrap, and state DB work with any
+// implementation. The Reminders and Home Assistant backends live next to
+// their adapters, in [reminders.Backend] and [homeassistant.Backend].
+//
+// list is always the backend's own identifier for a list, such as a
+// Reminders list name or an HA entity ID. Items are identified to Update and
+// Delete by current, the item as last returned by Fetch, so each backend can
+// key on whatever it needs — a UID, or the title where the provider has no
+// stable ID for writes.
+type TaskBackend interface {
+	// Fetch returns every item on the given lists, each with ListName set
+	// to the list it was found on.
+	Fetch(ctx context.Context, lists []string) ([]*model.Item, error)
+	// Create adds item to list and returns the UID the backend assigned to
+	// it, or "" if it cannot be determined.
+	Create(ctx context.Context, list string, item *model.Item) (uid string, err error)
+	// Update writes item over current. fields names the fields that differ;
+	// a backend may write the others too.
+	Update(ctx context.Context, list string, current, item *model.Item, fields model.Fields) error
+	// Delete removes current from list.
+	Delete(ctx context.Context, list string, current *model.Item) error
+}
+
+// FieldLimiter is implemented by target backends whose lists may not store
+// every content field, such as an HA todo entity without due dates. The
+// reconciler takes the fields a list cannot store from the Reminders side,
+// so a field the target drops is not synced back as cleared.
+type FieldLimiter interface {
+	// SupportedFields returns the content fields list can store.
+	SupportedFields(list string) model.Fields
+}
+
+// unsupportedFields returns the content fields b cannot store on list. No
+// backend stores alerts itself; only an [AlertPolicy] gives them a place.
+func unsupportedFields(b TaskBackend, list string) model.Fields {
+	var f model.Fields
+	if l, ok := b.(FieldLimiter); ok {
+		f = model.AllFields &^ l.SupportedFields(list)
+	}
+	if _, ok := b.(*alertBackend); !ok {
+		f |= model.FieldAlert
+	}
+	return f
+}
+
+// Registry routes list mapping targets to the backend that holds them. A
+// target of the form "name:list" is served by the backend registered under
+// name; any other target — such as a bare HA entity ID — is served by the
+// default backend. Create one with [NewRegistry].
+type Registry struct {
+	fallback TaskBackend
+	named    map[string]TaskBackend
+	alerts   map[string]AlertPolicy       // target → policy; see SetAlertPolicy
+	prefixes map[string]map[string]string // target → list → tag; see SetListPrefix
+}
+
+// NewRegistry creates a Registry that serves unprefixed targets from
+// fallback.
+func NewRegistry(fallback TaskBackend) *Registry {
+	return &Registry{fallback: fallback, named: make(map[string]TaskBackend)}
+}
+
+// Register makes b serve targets prefixed with "name:". It must be called
+// before the registry is used.
+func (r *Registry) Register(name string, b TaskBackend) error {
+	if name == "" || strings.ContainsAny(name, ":.") {
+		return fmt.Errorf("backend name %q must be non-empty and contain no ':' or '.'", name)
+	}
+	if _, ok := r.named[name]; ok {
+		return fmt.Errorf("backend %q registered twice", name)
+	}
+	r.named[name] = b
+	return nil
+}
+
+// Names returns the registered backend names, sorted.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.named))
+	for name := range r.named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolve returns the backend serving target for the Reminders list listName
+// and the list identifier to pass to it, wrapped to hold only listName's
+// items of a shared target and to keep alerts as the target's policy says.
+func (r *Registry) resolve(target, listName string) (TaskBackend, string, error) {
+	name, list := SplitTarget(target)
+	b := r.fallback
+	if name != "" {
+		var ok bool
+		if b, ok = r.named[name]; !ok {
+			return nil, "", fmt.Errorf("target %q: no backend named %q (known: %s)", target, name, strings.Join(r.Names(), ", "))
+		}
+	}
+	return withAlerts(withPrefix(b, r.prefixes[target], listName), r.alerts[target]), list, nil
+}
+
+// SplitTarget splits a list mapping target into the backend name and the
+// backend's list identifier. name is "" for targets served by the default
+// backend.
+func SplitTarget(target string) (name, list string) {
+	name, list, ok := strings.Cut(target, ":")
+	if !ok || strings.Contains(name, ".") {
+		return "", target
+	}
+	return name, list
+}
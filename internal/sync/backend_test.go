@@ -0,0 +1,55 @@
+package sync
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+func TestRegistry_RoutesPrefixedTargets(t *testing.T) {
+	now := time.Now().UTC()
+	rem := newMockReminders(
+		newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, now),
+		newItem("rem-2", "Write report", "Work", model.PriorityNone, false, now),
+	)
+	ha := newMockHA()
+	other := newMockHA()
+
+	reg := NewRegistry(ha)
+	if err := reg.Register("other", other); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	r := NewReconciler(rem, reg, newMockStore(), testLogger)
+	mappings := map[string]string{"Shopping": "todo.shopping", "Work": "other:work"}
+	if _, err := r.Run(context.Background(), mappings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := ha.getItems("todo.shopping"); len(got) != 1 || got[0].Title != "Buy milk" {
+		t.Errorf("default backend items = %v, want Buy milk only", got)
+	}
+	if got := other.getItems("work"); len(got) != 1 || got[0].Title != "Write report" {
+		t.Errorf("other backend items = %v, want Write report under list %q", got, "work")
+	}
+	if len(ha.getItems("other:work")) != 0 {
+		t.Error("prefixed target was sent to the default backend")
+	}
+}
+
+func TestRegistry_UnknownBackend(t *testing.T) {
+	reg := NewRegistry(newMockHA())
+	if err := reg.Register("caldav", newMockHA()); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := reg.Register("caldav", newMockHA()); err == nil {
+		t.Error("duplicate Register succeeded")
+	}
+
+	_, _, err := reg.resolve("nextcloud:tasks", "Shopping")
+	if err == nil || !strings.Contains(err.Error(), "caldav") {
+		t.Errorf("resolve = %v, want error listing the known backends", err)
+	}
+}
@@ -0,0 +1,96 @@
+package sync
+
+import (
+	"time"
+)
+
+const (
+	// outageThreshold is the number of consecutive poll passes in which
+	// every Home Assistant list failed before Home Assistant is taken to be
+	// unreachable and polling backs off.
+	outageThreshold = 3
+
+	// maxOutageInterval caps the interval between poll passes while Home
+	// Assistant is unreachable.
+	maxOutageInterval = 10 * time.Minute
+)
+
+// outage tracks a Home Assistant outage across the poll passes of
+// [Engine.Run]. Only the poll loop uses it.
+type outage struct {
+	failures int       // consecutive passes in which every HA list failed
+	since    time.Time // when the first of them ran
+	next     time.Time // no poll pass before then; zero when not backing off
+}
+
+// haDown reports whether every list of listMappings synced with Home
+// Assistant failed in a pass, as they do while it is unreachable. Lists on
+// other backends do not count.
+func haDown(listMappings map[string]string, stats Stats, err error) bool {
+	if err == nil {
+		return false
+	}
+	n := 0
+	for listName, target := range listMappings {
+		if name, _ := SplitTarget(target); name != "" {
+			continue
+		}
+		if stats.ListErrors[listName] == nil {
+			return false
+		}
+		n++
+	}
+	return n > 0
+}
+
+// waiting reports whether the poll pass due at now is skipped to back off.
+func (o *outage) waiting(now time.Time) bool {
+	return now.Before(o.next)
+}
+
+// observePoll logs the outcome of a poll pass and backs off polling while
+// Home Assistant is unreachable: after outageThreshold failed passes the
+// interval doubles with every further one, up to maxOutageInterval. A single
+// summary is logged when the outage is detected and when it ends, instead of
+// an error every pass.
+func (e *Engine) observePoll(o *outage, stats Stats, err error) {
+	e.passMu.Lock()
+	mappings := e.listMappings
+	e.passMu.Unlock()
+	now := e.clock.Now()
+
+	if !haDown(mappings, stats, err) {
+		if o.failures >= outageThreshold {
+			e.log.Info("Home Assistant reachable again", "down_for", now.Sub(o.since).Round(time.Second))
+		}
+		*o = outage{}
+		if err != nil {
+			e.log.Error("reconcile failed", "error", err)
+		}
+		return
+	}
+
+	if o.failures == 0 {
+		o.since = now
+	}
+	o.failures++
+	if o.failures < outageThreshold {
+		e.log.Error("reconcile failed", "error", err)
+		return
+	}
+
+	limit := max(maxOutageInterval, e.pollInterval)
+	interval := e.pollInterval
+	for i := outageThreshold; i <= o.failures && interval < limit; i++ {
+		interval *= 2
+	}
+	interval = min(interval, limit)
+	o.next = now.Add(interval)
+
+	if o.failures == outageThreshold {
+		e.log.Error("Home Assistant unreachable, backing off",
+			"since", o.since, "retry_in", interval, "error", err)
+		return
+	}
+	e.log.Debug("Home Assistant still unreachable", "since", o.since, "retry_in", interval, "error", err)
+}
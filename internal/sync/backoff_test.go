@@ -0,0 +1,77 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// unreachableHA fails every Fetch while down.
+type unreachableHA struct {
+	*mockHA
+	down atomic.Bool
+}
+
+func (u *unreachableHA) Fetch(ctx context.Context, lists []string) ([]*model.Item, error) {
+	if u.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	return u.mockHA.Fetch(ctx, lists)
+}
+
+func TestEngine_BacksOffWhileHAIsDown(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	rem := newMockReminders()
+	ha := &unreachableHA{mockHA: newMockHA()}
+	ha.down.Store(true)
+	r := NewReconciler(rem, NewRegistry(ha), newMockStore(), testLogger, WithClock(clk))
+	e := NewEngine(r, nil, testMappings, 30*time.Second, testLogger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- e.Run(ctx) }()
+	waitFor(t, "initial pass", func() bool { return rem.fetchCount() == 1 })
+	clk.BlockUntil(1)
+
+	// tick advances the clock by one poll interval and returns the number
+	// of passes so far.
+	tick := func() int {
+		clk.Advance(30 * time.Second)
+		time.Sleep(10 * time.Millisecond)
+		return rem.fetchCount()
+	}
+
+	// Passes at 0s, 30s and 60s fail; then the interval doubles from 60s:
+	// passes at 120s and 240s, and the next one is due at 480s.
+	passes := 0
+	for range 8 {
+		passes = tick()
+	}
+	if passes != 5 {
+		t.Fatalf("passes by 240s = %d, want 5", passes)
+	}
+	for range 7 {
+		passes = tick()
+	}
+	if passes != 5 {
+		t.Fatalf("passes by 450s = %d, want none while backing off", passes)
+	}
+
+	ha.down.Store(false)
+	if passes = tick(); passes != 6 {
+		t.Fatalf("passes by 480s = %d, want the backed-off pass", passes)
+	}
+	if passes = tick(); passes != 7 {
+		t.Errorf("passes by 510s = %d, want the poll interval restored", passes)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+}
@@ -6,43 +6,62 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/njoerd114/reminderrelay/internal/clock"
 	"github.com/njoerd114/reminderrelay/internal/model"
 	"github.com/njoerd114/reminderrelay/internal/state"
 )
 
-// Bootstrap performs the first-run linkage of existing items between Apple
-// Reminders and Home Assistant. It matches items by title, prints a summary,
-// and (with user confirmation) writes the state DB entries and pushes
-// unmatched items from Reminders to HA.
+// Bootstrap performs the initial linkage of existing items between Apple
+// Reminders and Home Assistant, both on first run and for list mappings added
+// later. It matches items by title, prints a summary, and (with user
+// confirmation) writes the state DB entries and pushes unmatched items from
+// Reminders to HA.
 type Bootstrap struct {
-	rem    RemindersSource
-	ha     HASource
-	store  StateStore
-	log    *slog.Logger
-	reader io.Reader // for confirmation prompt (os.Stdin in production)
-	writer io.Writer // for summary output (os.Stdout in production)
+	rem     TaskBackend
+	targets *Registry
+	store   StateStore
+	log     *slog.Logger
+	clock   clock.Clock
+	in      *bufio.Scanner // for confirmation prompts (os.Stdin in production)
+	writer  io.Writer      // for summary output (os.Stdout in production)
+
+	shadowLists map[string]bool  // not bootstrapped until promoted
+	fuzzy       float64          // similarity for fuzzy pairs; 0 disables them
+	planOut     string           // file the match plan is written to; "" for none
+	summaryOnly bool             // see WithSummaryOnly
+	ignore      IgnoreMarkers    // see WithBootstrapIgnoreMarkers
+	mismatch    MismatchPolicy   // see WithMismatchPolicy
+	completion  CompletionPolicy // see WithCompletionPolicy
 }
 
-// NewBootstrap creates a Bootstrap wired to the given adapters and state store.
-// reader and writer control the confirmation prompt I/O.
-func NewBootstrap(rem RemindersSource, ha HASource, store StateStore, logger *slog.Logger, reader io.Reader, writer io.Writer) *Bootstrap {
-	return &Bootstrap{
-		rem:    rem,
-		ha:     ha,
-		store:  store,
-		log:    logger,
-		reader: reader,
-		writer: writer,
+// NewBootstrap creates a Bootstrap that links the Reminders backend rem with
+// the list mapping targets in targets, keeping state in store. reader and
+// writer control the confirmation prompt I/O.
+func NewBootstrap(rem TaskBackend, targets *Registry, store StateStore, logger *slog.Logger, reader io.Reader, writer io.Writer, opts ...BootstrapOption) *Bootstrap {
+	b := &Bootstrap{
+		rem:     rem,
+		targets: targets,
+		store:   store,
+		log:     logger,
+		clock:   clock.Real(),
+		in:      bufio.NewScanner(reader),
+		writer:  writer,
 	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
 }
 
 // matchResult holds the result of title-matching for a single list mapping.
 type matchResult struct {
 	listName string
-	entityID string
+	entityID string // the mapping target, as configured
+	target   target
 
 	// Matched pairs: Reminders item + HA item that share a title.
 	matched []matchedPair
@@ -53,55 +72,188 @@ type matchResult struct {
 }
 
 type matchedPair struct {
-	rem *model.Item
-	ha  *model.Item
+	rem      *model.Item
+	ha       *model.Item
+	fuzzy    bool      // titles only alike, confirmed by the user
+	keep     Direction // for differing contents, the side written; "" to link as is
+	complete Direction // for differing completion, the side whose status is set
 }
 
-// Run checks whether the state DB is empty and, if so, performs the first-run
-// bootstrap. Returns true if bootstrap was executed, false if skipped.
+// BootstrapOption configures optional Bootstrap behaviour.
+type BootstrapOption func(*Bootstrap)
+
+// WithShadowLists excludes the given lists from bootstrap until they are
+// promoted out of shadow mode, so a shadow list is never written to.
+func WithShadowLists(lists []string) BootstrapOption {
+	return func(b *Bootstrap) {
+		b.shadowLists = make(map[string]bool, len(lists))
+		for _, l := range lists {
+			b.shadowLists[l] = true
+		}
+	}
+}
+
+// WithFuzzyMatch offers to link items whose titles are not equal but at
+// least threshold alike (see titleSimilarity), asking about each pair before
+// the summary. Exact matches never need confirming.
+func WithFuzzyMatch(threshold float64) BootstrapOption {
+	return func(b *Bootstrap) { b.fuzzy = threshold }
+}
+
+// WithSummaryOnly prints the summary, and writes the plan if asked to, but
+// neither asks for confirmation nor links or pushes anything, as for a
+// read-only trial run.
+func WithSummaryOnly() BootstrapOption {
+	return func(b *Bootstrap) { b.summaryOnly = true }
+}
+
+// Run bootstraps every list mapping that has no rows in the state DB yet —
+// all of them on a fresh install, or just the newly added ones later — so
+// items already present on both sides are linked by title instead of being
+// duplicated. Lists with no items on either side need no linking and are
+// skipped silently. Returns true if a bootstrap was executed.
 func (b *Bootstrap) Run(ctx context.Context, listMappings map[string]string) (bool, error) {
 	empty, err := b.store.IsEmpty(ctx)
 	if err != nil {
 		return false, fmt.Errorf("checking state DB: %w", err)
 	}
-	if !empty {
-		b.log.Debug("state DB is not empty, skipping bootstrap")
+
+	pending, err := b.pendingLists(ctx, listMappings)
+	if err != nil {
+		return false, err
+	}
+	if len(pending) == 0 {
+		b.log.Debug("all list mappings are bootstrapped, skipping bootstrap")
 		return false, nil
 	}
 
-	b.log.Info("empty state DB detected, starting first-run bootstrap")
+	results, err := b.match(ctx, pending)
+	if err != nil {
+		return false, err
+	}
+	if len(results) == 0 {
+		b.log.Debug("bootstrap found no items to link")
+		return false, nil
+	}
+
+	if empty {
+		b.log.Info("empty state DB detected, starting first-run bootstrap")
+		return b.confirmAndExecute(ctx, results, "First-Run Bootstrap")
+	}
+	b.log.Info("new list mappings detected, starting bootstrap", "lists", len(results))
+	return b.confirmAndExecute(ctx, results, "New Mapping Bootstrap")
+}
 
+// pendingLists returns the mappings that have no state rows and are not held
+// back in shadow mode.
+func (b *Bootstrap) pendingLists(ctx context.Context, listMappings map[string]string) (map[string]string, error) {
+	pending := make(map[string]string)
+	for listName, entityID := range listMappings {
+		if b.shadowLists[listName] {
+			sl, err := b.store.GetShadowList(ctx, listName)
+			if err != nil {
+				return nil, fmt.Errorf("checking shadow state for %q: %w", listName, err)
+			}
+			if sl == nil || !sl.Promoted {
+				b.log.Debug("skipping bootstrap for shadow list", "list", listName)
+				continue
+			}
+		}
+
+		rows, err := b.store.GetAllItemsForList(ctx, listName)
+		if err != nil {
+			return nil, fmt.Errorf("checking state for %q: %w", listName, err)
+		}
+		if len(rows) == 0 {
+			pending[listName] = entityID
+		}
+	}
+	return pending, nil
+}
+
+// RunForLists performs the match/confirm bootstrap flow for listMappings
+// regardless of what else is in the state DB. It is used when a mapping is
+// added to an existing installation, so pre-existing items on both sides are
+// linked by title instead of being duplicated. Returns false only if the user
+// declined; lists with nothing to link need no confirmation.
+func (b *Bootstrap) RunForLists(ctx context.Context, listMappings map[string]string) (bool, error) {
+	b.log.Info("starting bootstrap for new list mappings", "lists", len(listMappings))
+	results, err := b.match(ctx, listMappings)
+	if err != nil {
+		return false, err
+	}
+	if len(results) == 0 {
+		return true, nil
+	}
+	return b.confirmAndExecute(ctx, results, "Bootstrap")
+}
+
+// match fetches both sides of listMappings and pairs items by title. Lists
+// with no items on either side are omitted from the result.
+func (b *Bootstrap) match(ctx context.Context, listMappings map[string]string) ([]matchResult, error) {
 	listNames := make([]string, 0, len(listMappings))
 	for name := range listMappings {
 		listNames = append(listNames, name)
 	}
 
 	// Fetch all Reminders items.
-	remItems, err := b.rem.FetchAll(ctx, listNames)
+	remItems, err := b.rem.Fetch(ctx, listNames)
 	if err != nil {
-		return false, fmt.Errorf("fetching reminders for bootstrap: %w", err)
+		return nil, fmt.Errorf("fetching reminders for bootstrap: %w", err)
 	}
 
 	// Group Reminders items by list.
 	remByList := make(map[string][]*model.Item)
 	for _, item := range remItems {
-		remByList[item.ListName] = append(remByList[item.ListName], item)
+		if !b.ignore.Match(item) {
+			remByList[item.ListName] = append(remByList[item.ListName], item)
+		}
 	}
 
 	// Match each list.
 	var results []matchResult
 	for listName, entityID := range listMappings {
-		haItems, err := b.ha.GetItems(ctx, entityID)
+		backend, list, err := b.targets.resolve(entityID, listName)
+		if err != nil {
+			return nil, err
+		}
+		haItems, err := backend.Fetch(ctx, []string{list})
 		if err != nil {
-			return false, fmt.Errorf("fetching HA items for %s: %w", entityID, err)
+			return nil, fmt.Errorf("fetching HA items for %s: %w", entityID, err)
 		}
 
+		haItems = slices.DeleteFunc(haItems, b.ignore.Match)
 		result := matchByTitle(listName, entityID, remByList[listName], haItems)
+		result.target = target{backend: backend, list: list}
+		if len(result.matched)+len(result.remOnly)+len(result.haOnly) == 0 {
+			continue // nothing on either side to link
+		}
 		results = append(results, result)
 	}
+	return results, nil
+}
+
+// confirmAndExecute writes the plan if asked to, prints the summary titled
+// heading, asks for confirmation, and executes the bootstrap. Returns false if the user declined.
+func (b *Bootstrap) confirmAndExecute(ctx context.Context, results []matchResult, heading string) (bool, error) {
+	if b.planOut != "" {
+		if err := b.writePlan(results); err != nil {
+			return false, err
+		}
+	}
+	if b.fuzzy > 0 && !b.summaryOnly {
+		b.confirmFuzzy(results)
+	}
+	if !b.summaryOnly {
+		b.resolveMismatches(results)
+	}
 
 	// Print summary.
-	b.printSummary(results)
+	b.printSummary(heading, results)
+	if b.summaryOnly {
+		b.log.Info("read-only: bootstrap not executed")
+		return false, nil
+	}
 
 	// Ask for confirmation.
 	if !b.confirm() {
@@ -118,8 +270,31 @@ func (b *Bootstrap) Run(ctx context.Context, listMappings map[string]string) (bo
 	return true, nil
 }
 
-// matchByTitle matches Reminders items to HA items by exact title (case-insensitive).
-func matchByTitle(listName, entityID string, remItems []*model.Item, haItems []model.Item) matchResult {
+// confirmFuzzy asks about every fuzzy pair among the unmatched items of
+// results and moves the confirmed ones to matched.
+func (b *Bootstrap) confirmFuzzy(results []matchResult) {
+	for i := range results {
+		r := &results[i]
+		pairs := fuzzyPairs(r.remOnly, r.haOnly, b.fuzzy)
+		if len(pairs) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(b.writer, "\nList %q has items with similar titles:\n", r.listName)
+		for _, p := range pairs {
+			_, _ = fmt.Fprintf(b.writer, "  Link %q (Reminders) with %q (HA)? [y/N] ", p.rem.Title, p.ha.Title)
+			if !b.yes() {
+				continue
+			}
+			r.matched = append(r.matched, p)
+			r.remOnly = slices.DeleteFunc(r.remOnly, func(it *model.Item) bool { return it == p.rem })
+			r.haOnly = slices.DeleteFunc(r.haOnly, func(it *model.Item) bool { return it == p.ha })
+		}
+	}
+}
+
+// matchByTitle matches Reminders items to HA items whose titles are equal
+// once normalised with titleKey.
+func matchByTitle(listName, entityID string, remItems, haItems []*model.Item) matchResult {
 	result := matchResult{
 		listName: listName,
 		entityID: entityID,
@@ -127,16 +302,15 @@ func matchByTitle(listName, entityID string, remItems []*model.Item, haItems []m
 
 	// Build HA title → item index.
 	haByTitle := make(map[string]*model.Item, len(haItems))
-	for i := range haItems {
-		haItems[i].ListName = listName
-		key := strings.ToLower(haItems[i].Title)
-		haByTitle[key] = &haItems[i]
+	for _, ha := range haItems {
+		ha.ListName = listName
+		haByTitle[titleKey(ha.Title)] = ha
 	}
 
 	matchedHATitles := make(map[string]bool)
 
 	for _, rem := range remItems {
-		key := strings.ToLower(rem.Title)
+		key := titleKey(rem.Title)
 		if ha, ok := haByTitle[key]; ok {
 			result.matched = append(result.matched, matchedPair{rem: rem, ha: ha})
 			matchedHATitles[key] = true
@@ -145,10 +319,9 @@ func matchByTitle(listName, entityID string, remItems []*model.Item, haItems []m
 		}
 	}
 
-	for i := range haItems {
-		key := strings.ToLower(haItems[i].Title)
-		if !matchedHATitles[key] {
-			result.haOnly = append(result.haOnly, &haItems[i])
+	for _, ha := range haItems {
+		if !matchedHATitles[titleKey(ha.Title)] {
+			result.haOnly = append(result.haOnly, ha)
 		}
 	}
 
@@ -156,7 +329,7 @@ func matchByTitle(listName, entityID string, remItems []*model.Item, haItems []m
 }
 
 // printSummary writes a human-readable summary of the match results.
-func (b *Bootstrap) printSummary(results []matchResult) {
+func (b *Bootstrap) printSummary(heading string, results []matchResult) {
 	totalMatched := 0
 	totalRemOnly := 0
 	totalHAOnly := 0
@@ -167,13 +340,17 @@ func (b *Bootstrap) printSummary(results []matchResult) {
 		totalHAOnly += len(r.haOnly)
 	}
 
-	_, _ = fmt.Fprintf(b.writer, "\n--- First-Run Bootstrap Summary ---\n\n")
+	_, _ = fmt.Fprintf(b.writer, "\n--- %s Summary ---\n\n", heading)
 
 	for _, r := range results {
 		_, _ = fmt.Fprintf(b.writer, "List %q ↔ %s:\n", r.listName, r.entityID)
 		_, _ = fmt.Fprintf(b.writer, "  Matched by title: %d\n", len(r.matched))
 		for _, m := range r.matched {
-			_, _ = fmt.Fprintf(b.writer, "    ✓ %s\n", m.rem.Title)
+			if m.fuzzy {
+				_, _ = fmt.Fprintf(b.writer, "    ≈ %s ↔ %s%s\n", m.rem.Title, m.ha.Title, pairNote(r, m))
+				continue
+			}
+			_, _ = fmt.Fprintf(b.writer, "    ✓ %s%s\n", m.rem.Title, pairNote(r, m))
 		}
 		if len(r.remOnly) > 0 {
 			_, _ = fmt.Fprintf(b.writer, "  Only in Reminders (will push to HA): %d\n", len(r.remOnly))
@@ -194,57 +371,79 @@ func (b *Bootstrap) printSummary(results []matchResult) {
 		totalMatched, totalRemOnly, totalHAOnly)
 }
 
-// confirm reads a y/n response from the reader.
+// pairNote describes how the contents of the matched pair m differ and how
+// that is settled, as a suffix for the summary; "" for equal contents.
+func pairNote(r matchResult, m matchedPair) string {
+	fields := mismatchFields(r, m)
+	var notes []string
+	switch {
+	case fields&^model.FieldCompleted == 0:
+	case m.keep == ToHA:
+		notes = append(notes, "Reminders version kept")
+	case m.keep == ToReminders:
+		notes = append(notes, "HA version kept")
+	default:
+		notes = append(notes, "differs in "+fieldNames(fields&^model.FieldCompleted))
+	}
+	if fields.Has(model.FieldCompleted) {
+		note := completionNote(&m)
+		switch {
+		case m.complete == ToHA && m.rem.Completed, m.complete == ToReminders && m.ha.Completed:
+			note += ", will be completed"
+		case m.complete != "":
+			note += ", will be reopened"
+		}
+		notes = append(notes, note)
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(notes, "; ") + ")"
+}
+
+// confirm asks whether to go ahead with the bootstrap.
 func (b *Bootstrap) confirm() bool {
 	_, _ = fmt.Fprintf(b.writer, "Proceed with sync? [y/N] ")
-	scanner := bufio.NewScanner(b.reader)
-	if scanner.Scan() {
-		answer := strings.TrimSpace(strings.ToLower(scanner.Text()))
+	return b.yes()
+}
+
+// yes reads a y/n response from the reader.
+func (b *Bootstrap) yes() bool {
+	if b.in.Scan() {
+		answer := strings.TrimSpace(strings.ToLower(b.in.Text()))
 		return answer == "y" || answer == "yes"
 	}
 	return false
 }
 
 // execute writes all matched pairs to the state DB and pushes unmatched items.
+// Pushes are reported as they go, since a large list takes minutes.
 func (b *Bootstrap) execute(ctx context.Context, results []matchResult) error {
-	now := time.Now().UTC()
+	now := b.clock.Now().UTC()
+
+	pushes := 0
+	for _, r := range results {
+		pushes += len(r.remOnly) + len(r.haOnly)
+	}
+	prog := newProgress(b.writer, b.clock, "pushed", pushes)
 
 	for _, r := range results {
 		// Write matched pairs.
 		for _, m := range r.matched {
-			si := &state.Item{
-				RemindersUID:      m.rem.UID,
-				HAUID:             m.ha.UID,
-				ListName:          r.listName,
-				Title:             m.rem.Title,
-				LastSyncHash:      m.rem.ContentHash(),
-				RemindersModified: m.rem.ModifiedAt,
-				HAModified:        m.ha.ModifiedAt,
-				LastSyncedAt:      now,
+			m, err := b.settle(ctx, r, m)
+			if err != nil {
+				return err
 			}
-			if err := b.store.UpsertItem(ctx, si); err != nil {
-				return fmt.Errorf("writing matched pair %q: %w", m.rem.Title, err)
+			if err := b.link(ctx, r.listName, m, now); err != nil {
+				return err
 			}
-			b.log.Debug("linked matched pair", "title", m.rem.Title)
 		}
 
 		// Push Reminders-only items to HA.
 		for _, item := range r.remOnly {
-			if err := b.ha.AddItem(ctx, r.entityID, item); err != nil {
-				return fmt.Errorf("pushing %q to HA: %w", item.Title, err)
-			}
-
-			// Refetch to get the HA UID.
-			haItems, err := b.ha.GetItems(ctx, r.entityID)
+			haUID, err := r.target.backend.Create(ctx, r.target.list, item)
 			if err != nil {
-				return fmt.Errorf("refetching items from %s: %w", r.entityID, err)
-			}
-			var haUID string
-			for _, h := range haItems {
-				if h.Title == item.Title {
-					haUID = h.UID
-					break
-				}
+				return fmt.Errorf("pushing %q to HA: %w", item.Title, err)
 			}
 
 			si := &state.Item{
@@ -255,16 +454,18 @@ func (b *Bootstrap) execute(ctx context.Context, results []matchResult) error {
 				LastSyncHash:      item.ContentHash(),
 				RemindersModified: item.ModifiedAt,
 				LastSyncedAt:      now,
+				Base:              baseOf(item),
 			}
 			if err := b.store.UpsertItem(ctx, si); err != nil {
 				return fmt.Errorf("writing state for %q: %w", item.Title, err)
 			}
 			b.log.Info("pushed to HA", "title", item.Title)
+			prog.step(r.listName)
 		}
 
 		// Push HA-only items to Reminders.
 		for _, item := range r.haOnly {
-			uid, err := b.rem.Create(ctx, item)
+			uid, err := b.rem.Create(ctx, r.listName, item)
 			if err != nil {
 				return fmt.Errorf("pushing %q to Reminders: %w", item.Title, err)
 			}
@@ -277,13 +478,70 @@ func (b *Bootstrap) execute(ctx context.Context, results []matchResult) error {
 				LastSyncHash: item.ContentHash(),
 				HAModified:   item.ModifiedAt,
 				LastSyncedAt: now,
+				Base:         baseOf(item),
 			}
 			if err := b.store.UpsertItem(ctx, si); err != nil {
 				return fmt.Errorf("writing state for %q: %w", item.Title, err)
 			}
 			b.log.Info("pushed to Reminders", "title", item.Title)
+			prog.step(r.listName)
 		}
 	}
 
 	return nil
 }
+
+// link writes the state DB entry for a matched pair.
+func (b *Bootstrap) link(ctx context.Context, listName string, m matchedPair, now time.Time) error {
+	si := &state.Item{
+		RemindersUID:      m.rem.UID,
+		HAUID:             m.ha.UID,
+		ListName:          listName,
+		Title:             m.rem.Title,
+		LastSyncHash:      m.rem.ContentHash(),
+		RemindersModified: m.rem.ModifiedAt,
+		HAModified:        m.ha.ModifiedAt,
+		LastSyncedAt:      now,
+		Base:              baseOf(m.rem),
+	}
+	if err := b.store.UpsertItem(ctx, si); err != nil {
+		return fmt.Errorf("writing matched pair %q: %w", m.rem.Title, err)
+	}
+	b.log.Debug("linked matched pair", "title", m.rem.Title)
+	return nil
+}
+
+// LinkUntracked links items that exist on both sides under the same title but
+// have no state row, without asking and without pushing anything. It is run
+// after a state DB backup was restored: items synced since the backup was
+// taken exist on both sides but are unknown to it, and would otherwise be
+// duplicated by the next pass. Returns the number of pairs linked.
+func (b *Bootstrap) LinkUntracked(ctx context.Context, listMappings map[string]string) (int, error) {
+	results, err := b.match(ctx, listMappings)
+	if err != nil {
+		return 0, err
+	}
+
+	now := b.clock.Now().UTC()
+	linked := 0
+	for _, r := range results {
+		for _, m := range r.matched {
+			remRow, err := b.store.GetItemByRemindersUID(ctx, m.rem.UID)
+			if err != nil {
+				return linked, err
+			}
+			haRow, err := b.store.GetItemByHAUID(ctx, m.ha.UID)
+			if err != nil {
+				return linked, err
+			}
+			if remRow != nil || haRow != nil {
+				continue
+			}
+			if err := b.link(ctx, r.listName, m, now); err != nil {
+				return linked, err
+			}
+			linked++
+		}
+	}
+	return linked, nil
+}
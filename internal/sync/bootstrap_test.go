@@ -21,7 +21,7 @@ func TestBootstrap_SkipsNonEmptyDB(t *testing.T) {
 	store.seed(stateItemHelper("rem-1", "ha-1", "Shopping", "Existing"))
 
 	var buf bytes.Buffer
-	b := NewBootstrap(rem, ha, store, testLogger, strings.NewReader(""), &buf)
+	b := NewBootstrap(rem, NewRegistry(ha), store, testLogger, strings.NewReader(""), &buf)
 	ran, err := b.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -47,9 +47,9 @@ func TestBootstrap_MatchesByTitle(t *testing.T) {
 
 	store := newMockStore()
 	var output bytes.Buffer
-	input := strings.NewReader("y\n")
+	input := strings.NewReader("r\ny\n") // keep the Reminders priority, then confirm
 
-	b := NewBootstrap(rem, ha, store, slog.Default(), input, &output)
+	b := NewBootstrap(rem, NewRegistry(ha), store, slog.Default(), input, &output)
 	ran, err := b.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -69,6 +69,9 @@ func TestBootstrap_MatchesByTitle(t *testing.T) {
 	if !strings.Contains(summary, "Only in HA") {
 		t.Error("summary should mention HA-only item")
 	}
+	if !strings.Contains(summary, "2/2 pushed") {
+		t.Error("output should report the pushes done")
+	}
 
 	// State DB should have 3 entries: 1 matched + 1 pushed to HA + 1 pushed to Rem.
 	if store.count() != 3 {
@@ -87,6 +90,113 @@ func TestBootstrap_MatchesByTitle(t *testing.T) {
 	}
 }
 
+func TestBootstrap_RunForLists_IgnoresOtherState(t *testing.T) {
+	now := time.Now().UTC()
+
+	rem := newMockReminders(newItem("rem-2", "Buy milk", "Groceries", model.PriorityNone, false, now))
+	ha := newMockHA()
+	ha.addItems("todo.groceries", model.Item{UID: "ha-2", Title: "buy milk", ModifiedAt: now})
+
+	// An existing, unrelated list is already tracked.
+	store := newMockStore()
+	store.seed(stateItemHelper("rem-1", "ha-1", "Shopping", "Existing"))
+
+	var output bytes.Buffer
+	b := NewBootstrap(rem, NewRegistry(ha), store, testLogger, strings.NewReader("y\n"), &output)
+	ran, err := b.RunForLists(context.Background(), map[string]string{"Groceries": "todo.groceries"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ran {
+		t.Fatal("targeted bootstrap should run even when the state DB is non-empty")
+	}
+
+	si, _ := store.GetItemByRemindersUID(context.Background(), "rem-2")
+	if si == nil || si.HAUID != "ha-2" {
+		t.Errorf("state row = %+v, want rem-2 linked to ha-2 by title", si)
+	}
+	if len(ha.getItems("todo.groceries")) != 1 || rem.count() != 1 {
+		t.Error("matched items must not be duplicated")
+	}
+}
+
+func TestBootstrap_NewMappingOnNonEmptyDB(t *testing.T) {
+	now := time.Now().UTC()
+	mappings := map[string]string{"Shopping": "todo.shopping", "Groceries": "todo.groceries"}
+
+	rem := newMockReminders(
+		newItem("rem-1", "Existing", "Shopping", model.PriorityNone, false, now),
+		newItem("rem-2", "Buy milk", "Groceries", model.PriorityNone, false, now),
+	)
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Existing", ModifiedAt: now})
+	ha.addItems("todo.groceries", model.Item{UID: "ha-2", Title: "Buy milk", ModifiedAt: now})
+
+	store := newMockStore()
+	store.seed(stateItemHelper("rem-1", "ha-1", "Shopping", "Existing"))
+
+	var output bytes.Buffer
+	b := NewBootstrap(rem, NewRegistry(ha), store, testLogger, strings.NewReader("y\n"), &output)
+	ran, err := b.Run(context.Background(), mappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ran {
+		t.Fatal("bootstrap should run for the list without state rows")
+	}
+	if strings.Contains(output.String(), `List "Shopping"`) {
+		t.Error("already-bootstrapped list should not be in the summary")
+	}
+
+	si, _ := store.GetItemByRemindersUID(context.Background(), "rem-2")
+	if si == nil || si.HAUID != "ha-2" {
+		t.Errorf("state row = %+v, want rem-2 linked to ha-2", si)
+	}
+	if len(ha.getItems("todo.groceries")) != 1 {
+		t.Error("matched item must not be duplicated in HA")
+	}
+}
+
+func TestBootstrap_SkipsEmptyNewList(t *testing.T) {
+	store := newMockStore()
+	var output bytes.Buffer
+
+	// No input is provided: prompting would cancel, so ran=false either way,
+	// but nothing should be printed for a list with no items.
+	b := NewBootstrap(newMockReminders(), NewRegistry(newMockHA()), store, testLogger, strings.NewReader(""), &output)
+	ran, err := b.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ran || output.Len() != 0 {
+		t.Errorf("ran = %v, output = %q; want no bootstrap for an empty list", ran, output.String())
+	}
+}
+
+func TestBootstrap_SkipsUnpromotedShadowList(t *testing.T) {
+	now := time.Now().UTC()
+	rem := newMockReminders(newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, now))
+	ha := newMockHA()
+	store := newMockStore()
+
+	b := NewBootstrap(rem, NewRegistry(ha), store, testLogger, strings.NewReader("y\n"), &bytes.Buffer{},
+		WithShadowLists([]string{"Shopping"}))
+	ran, err := b.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ran || len(ha.getItems("todo.shopping")) != 0 {
+		t.Fatal("unpromoted shadow list must not be bootstrapped")
+	}
+
+	_ = store.PromoteShadowList(context.Background(), "Shopping")
+	b = NewBootstrap(rem, NewRegistry(ha), store, testLogger, strings.NewReader("y\n"), &bytes.Buffer{},
+		WithShadowLists([]string{"Shopping"}))
+	if ran, _ := b.Run(context.Background(), testMappings); !ran {
+		t.Error("promoted shadow list should be bootstrapped")
+	}
+}
+
 func TestBootstrap_CancelledByUser(t *testing.T) {
 	now := time.Now().UTC()
 	rem := newMockReminders(
@@ -98,7 +208,7 @@ func TestBootstrap_CancelledByUser(t *testing.T) {
 	var output bytes.Buffer
 	input := strings.NewReader("n\n") // User says no.
 
-	b := NewBootstrap(rem, ha, store, slog.Default(), input, &output)
+	b := NewBootstrap(rem, NewRegistry(ha), store, slog.Default(), input, &output)
 	ran, err := b.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -113,6 +223,30 @@ func TestBootstrap_CancelledByUser(t *testing.T) {
 	}
 }
 
+func TestBootstrap_SummaryOnly(t *testing.T) {
+	now := time.Now().UTC()
+	rem := newMockReminders(
+		newItem("rem-1", "Task", "Shopping", model.PriorityNone, false, now),
+	)
+	ha := newMockHA()
+	store := newMockStore()
+
+	var output bytes.Buffer
+	input := strings.NewReader("y\n") // Never read.
+
+	b := NewBootstrap(rem, NewRegistry(ha), store, testLogger, input, &output, WithSummaryOnly())
+	ran, err := b.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ran || store.count() != 0 || len(ha.getItems("todo.shopping")) != 0 {
+		t.Error("bootstrap wrote to the state DB or HA with WithSummaryOnly")
+	}
+	if !strings.Contains(output.String(), "Task") {
+		t.Errorf("output = %q, want the summary", output.String())
+	}
+}
+
 func TestBootstrap_CaseInsensitiveMatch(t *testing.T) {
 	now := time.Now().UTC()
 
@@ -128,7 +262,7 @@ func TestBootstrap_CaseInsensitiveMatch(t *testing.T) {
 	var output bytes.Buffer
 	input := strings.NewReader("y\n")
 
-	b := NewBootstrap(rem, ha, store, slog.Default(), input, &output)
+	b := NewBootstrap(rem, NewRegistry(ha), store, slog.Default(), input, &output)
 	ran, err := b.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -163,7 +297,7 @@ func TestMatchByTitle_AllMatched(t *testing.T) {
 		newItem("rem-1", "A", "Shopping", model.PriorityNone, false, now),
 		newItem("rem-2", "B", "Shopping", model.PriorityNone, false, now),
 	}
-	haItems := []model.Item{
+	haItems := []*model.Item{
 		{UID: "ha-1", Title: "A", ModifiedAt: now},
 		{UID: "ha-2", Title: "B", ModifiedAt: now},
 	}
@@ -178,6 +312,65 @@ func TestMatchByTitle_AllMatched(t *testing.T) {
 	}
 }
 
+func TestMatchByTitle_Normalised(t *testing.T) {
+	now := time.Now().UTC()
+	remItems := []*model.Item{
+		newItem("rem-1", "Buy milk ", "Shopping", model.PriorityNone, false, now),
+		newItem("rem-2", "Don’t  forget", "Shopping", model.PriorityNone, false, now),
+		newItem("rem-3", "Cafe\u0301", "Shopping", model.PriorityNone, false, now),
+	}
+	haItems := []*model.Item{
+		{UID: "ha-1", Title: "buy milk", ModifiedAt: now},
+		{UID: "ha-2", Title: "Don't forget", ModifiedAt: now},
+		{UID: "ha-3", Title: "Café", ModifiedAt: now},
+	}
+
+	result := matchByTitle("Shopping", "todo.shopping", remItems, haItems)
+
+	if len(result.matched) != 3 {
+		t.Errorf("matched = %d, want 3 (remOnly=%d, haOnly=%d)", len(result.matched), len(result.remOnly), len(result.haOnly))
+	}
+}
+
+func TestBootstrap_FuzzyMatchAsksPerPair(t *testing.T) {
+	now := time.Now().UTC()
+	rem := newMockReminders(
+		newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, now),
+		newItem("rem-2", "Call the plumber", "Shopping", model.PriorityNone, false, now),
+	)
+	ha := newMockHA()
+	ha.addItems("todo.shopping",
+		model.Item{UID: "ha-1", Title: "Buy milk!", ModifiedAt: now},
+		model.Item{UID: "ha-2", Title: "Call the plumbers", ModifiedAt: now},
+	)
+	store := newMockStore()
+
+	// Pairs are offered most alike first: accept the plumber pair, refuse
+	// the milk pair, then confirm the bootstrap.
+	var output bytes.Buffer
+	b := NewBootstrap(rem, NewRegistry(ha), store, testLogger, strings.NewReader("y\nn\ny\n"), &output,
+		WithFuzzyMatch(0.8))
+	ran, err := b.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ran {
+		t.Fatal("bootstrap should have executed")
+	}
+
+	if got := strings.Count(output.String(), "(Reminders) with"); got != 2 {
+		t.Errorf("asked about %d pair(s), want 2:\n%s", got, output.String())
+	}
+	row, _ := store.GetItemByRemindersUID(context.Background(), "rem-2")
+	if row == nil || row.HAUID != "ha-2" {
+		t.Errorf("confirmed pair not linked: %+v", row)
+	}
+	// The refused pair is synced as two separate items.
+	if n := len(ha.getItems("todo.shopping")); n != 3 {
+		t.Errorf("HA items = %d, want 3", n)
+	}
+}
+
 // stateItemHelper creates a minimal state.Item for test seeding.
 func stateItemHelper(remUID, haUID, listName, title string) *stateItem {
 	return &stateItem{
@@ -190,3 +383,46 @@ func stateItemHelper(remUID, haUID, listName, title string) *stateItem {
 
 // stateItem is imported from the state package via the type used in store.
 type stateItem = state.Item
+
+func TestBootstrap_RunForLists_NothingToLink(t *testing.T) {
+	b := NewBootstrap(newMockReminders(), NewRegistry(newMockHA()), newMockStore(), testLogger, strings.NewReader(""), &bytes.Buffer{})
+	ok, err := b.RunForLists(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok {
+		t.Error("RunForLists should succeed without a prompt when there is nothing to link")
+	}
+}
+
+func TestBootstrap_LinkUntracked(t *testing.T) {
+	now := time.Now().UTC()
+	rem := newMockReminders(
+		newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, now),
+		newItem("rem-2", "Eggs", "Shopping", model.PriorityNone, false, now),
+	)
+	ha := newMockHA()
+	ha.addItems("todo.shopping",
+		model.Item{UID: "ha-1", Title: "Buy milk", ModifiedAt: now},
+		model.Item{UID: "ha-2", Title: "Eggs", ModifiedAt: now},
+		model.Item{UID: "ha-3", Title: "Bread", ModifiedAt: now},
+	)
+	store := newMockStore()
+	store.seed(&state.Item{RemindersUID: "rem-1", HAUID: "ha-1", ListName: "Shopping", Title: "Buy milk"})
+
+	var output bytes.Buffer
+	b := NewBootstrap(rem, NewRegistry(ha), store, testLogger, strings.NewReader(""), &output)
+	linked, err := b.LinkUntracked(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if linked != 1 || store.count() != 2 {
+		t.Errorf("linked = %d, rows = %d; want Eggs linked next to the tracked pair", linked, store.count())
+	}
+	if si, _ := store.GetItemByRemindersUID(context.Background(), "rem-2"); si == nil || si.HAUID != "ha-2" {
+		t.Errorf("state row = %+v, want rem-2 linked to ha-2", si)
+	}
+	if rem.count() != 2 || len(ha.getItems("todo.shopping")) != 3 || output.Len() != 0 {
+		t.Error("LinkUntracked must not push items or prompt")
+	}
+}
@@ -0,0 +1,94 @@
+package sync
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// lockLists locks the given Reminders lists in name order, so that passes
+// over overlapping lists cannot deadlock, and returns a func unlocking them.
+// A pass holds the locks from fetching the lists' Reminders items until it
+// has written its changes, so no other pass acts on a snapshot it outdated.
+func (r *Reconciler) lockLists(names []string) func() {
+	sorted := slices.Sorted(slices.Values(names))
+	locks := make([]*sync.Mutex, 0, len(sorted))
+
+	r.locksMu.Lock()
+	if r.listLocks == nil {
+		r.listLocks = make(map[string]*sync.Mutex)
+	}
+	for _, name := range sorted {
+		l := r.listLocks[name]
+		if l == nil {
+			l = new(sync.Mutex)
+			r.listLocks[name] = l
+		}
+		locks = append(locks, l)
+	}
+	r.locksMu.Unlock()
+
+	for _, l := range locks {
+		l.Lock()
+	}
+	return func() {
+		for _, l := range locks {
+			l.Unlock()
+		}
+	}
+}
+
+// passQueue coalesces pass triggers. A trigger for a key that is already
+// waiting is merged into it, and any number of triggers arriving while the
+// key's pass runs cause a single follow-up pass.
+type passQueue struct {
+	mu      sync.Mutex
+	pending map[string]bool
+	wake    chan struct{}
+}
+
+func newPassQueue() *passQueue {
+	return &passQueue{pending: make(map[string]bool), wake: make(chan struct{}, 1)}
+}
+
+// add queues a pass for key.
+func (q *passQueue) add(key string) {
+	q.mu.Lock()
+	q.pending[key] = true
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default: // already woken; the pending key is picked up with the others
+	}
+}
+
+// take returns the queued keys in order and empties the queue.
+func (q *passQueue) take() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	keys := make([]string, 0, len(q.pending))
+	for k := range q.pending {
+		keys = append(keys, k)
+	}
+	clear(q.pending)
+	slices.Sort(keys)
+	return keys
+}
+
+// run calls pass for queued keys, one at a time, until ctx is cancelled.
+func (q *passQueue) run(ctx context.Context, pass func(key string)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+			for _, key := range q.take() {
+				if ctx.Err() != nil {
+					return
+				}
+				pass(key)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package sync
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+func TestPassQueue_CoalescesTriggers(t *testing.T) {
+	q := newPassQueue()
+	started := make(chan string, 10)
+	release := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.run(ctx, func(key string) {
+		started <- key
+		<-release
+	})
+
+	q.add("todo.shopping")
+	if got := <-started; got != "todo.shopping" {
+		t.Fatalf("first pass for %q, want todo.shopping", got)
+	}
+	// Triggers while the pass runs are merged into one follow-up per key.
+	for range 5 {
+		q.add("todo.shopping")
+	}
+	q.add("todo.errands")
+	release <- struct{}{}
+
+	var got []string
+	for range 2 {
+		got = append(got, <-started)
+		release <- struct{}{}
+	}
+	if got[0] != "todo.errands" || got[1] != "todo.shopping" {
+		t.Errorf("follow-up passes = %v, want [todo.errands todo.shopping]", got)
+	}
+	select {
+	case key := <-started:
+		t.Errorf("extra pass for %q", key)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+// blockingHA holds its first Fetch until release is closed.
+type blockingHA struct {
+	*mockHA
+	once    sync.Once
+	fetched chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingHA) Fetch(ctx context.Context, lists []string) ([]*model.Item, error) {
+	b.once.Do(func() {
+		close(b.fetched)
+		<-b.release
+	})
+	return b.mockHA.Fetch(ctx, lists)
+}
+
+func TestReconciler_PassesOverOneListDoNotOverlap(t *testing.T) {
+	rem := newMockReminders()
+	ha := &blockingHA{mockHA: newMockHA(), fetched: make(chan struct{}), release: make(chan struct{})}
+	r := NewReconciler(rem, NewRegistry(ha), newMockStore(), testLogger)
+	ctx := context.Background()
+
+	full := make(chan error, 1)
+	go func() {
+		_, err := r.Run(ctx, testMappings)
+		full <- err
+	}()
+	<-ha.fetched // the full pass holds Shopping
+
+	entity := make(chan error, 1)
+	go func() {
+		_, err := r.ReconcileEntity(ctx, "Shopping", "todo.shopping")
+		entity <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	if n := rem.fetchCount(); n != 1 {
+		t.Fatalf("Reminders fetched %d times while the full pass ran, want 1", n)
+	}
+
+	close(ha.release)
+	for _, done := range []chan error{full, entity} {
+		if err := <-done; err != nil {
+			t.Fatalf("pass error = %v", err)
+		}
+	}
+	if n := rem.fetchCount(); n != 2 {
+		t.Errorf("Reminders fetched %d times, want 2", n)
+	}
+}
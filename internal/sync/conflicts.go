@@ -0,0 +1,143 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// ConflictEventType is the HA event fired for every resolved conflict.
+const ConflictEventType = "reminderrelay_conflict"
+
+// ConflictNotifier surfaces resolved conflicts as one notification per item.
+// Implemented by [homeassistant.Adapter] and [desktop.Notifier].
+type ConflictNotifier interface {
+	CreateNotification(ctx context.Context, notificationID, title, message string) error
+}
+
+// EventFirer is implemented by conflict notifiers that can also publish a
+// [ConflictEventType] event for automations, such as [homeassistant.Adapter].
+type EventFirer interface {
+	FireEvent(ctx context.Context, eventType string, data map[string]interface{}) error
+}
+
+// WithConflictNotifier reports every conflict the reconciler resolves through
+// n, so overlapping edits do not go unnoticed. It may be given once per
+// notification channel.
+func WithConflictNotifier(n ConflictNotifier) EngineOption {
+	return func(e *Engine) {
+		e.conflicts = append(e.conflicts, &conflictReporter{notifier: n, instance: e.instance, log: e.log})
+	}
+}
+
+// conflictReporter publishes resolved conflicts. Failures are logged only.
+type conflictReporter struct {
+	notifier ConflictNotifier
+	instance string
+	log      *slog.Logger
+}
+
+// report publishes every conflict in resolved.
+func (c *conflictReporter) report(ctx context.Context, resolved []Conflict) {
+	events, _ := c.notifier.(EventFirer)
+	for _, conflict := range resolved {
+		if events != nil {
+			if err := events.FireEvent(ctx, ConflictEventType, c.eventData(conflict)); err != nil {
+				c.log.Error("firing HA conflict event", "title", conflict.Result.Title, "error", err)
+			}
+		}
+		id, title := c.notificationID(conflict), "ReminderRelay resolved a conflict"
+		if err := c.notifier.CreateNotification(ctx, id, title, conflictMessage(conflict)); err != nil {
+			c.log.Error("posting conflict notification", "title", conflict.Result.Title, "error", err)
+		}
+	}
+}
+
+// notificationID returns a stable ID per item, so repeated conflicts on the
+// same item replace its notification instead of piling up.
+func (c *conflictReporter) notificationID(conflict Conflict) string {
+	sum := sha256.Sum256([]byte(c.instance + "\x00" + conflict.ListName + "\x00" + conflict.Reminders.UID))
+	return "reminderrelay_conflict_" + hex.EncodeToString(sum[:6])
+}
+
+// eventData renders conflict as the payload of a [ConflictEventType] event.
+func (c *conflictReporter) eventData(conflict Conflict) map[string]interface{} {
+	kept := "home_assistant"
+	if conflict.RemindersWon {
+		kept = "reminders"
+	}
+	data := map[string]interface{}{
+		"list":           conflict.ListName,
+		"title":          conflict.Result.Title,
+		"fields":         conflict.Fields,
+		"kept":           kept,
+		"reminders":      itemEventData(conflict.Reminders),
+		"home_assistant": itemEventData(conflict.HA),
+	}
+	if c.instance != "" {
+		data["instance"] = c.instance
+	}
+	return data
+}
+
+// itemEventData renders the content fields of item for an event payload.
+func itemEventData(item model.Item) map[string]interface{} {
+	data := map[string]interface{}{
+		"title":       item.Title,
+		"description": item.Description,
+		"priority":    item.Priority.String(),
+		"completed":   item.Completed,
+		"modified_at": item.ModifiedAt.UTC().Format(time.RFC3339),
+	}
+	if item.DueDate != nil {
+		data["due_date"] = item.DueDate.Format(time.DateOnly)
+	}
+	return data
+}
+
+// conflictMessage renders conflict as a Markdown notification body showing
+// both versions and the result.
+func conflictMessage(conflict Conflict) string {
+	winner, loser := "Reminders", "Home Assistant"
+	if !conflict.RemindersWon {
+		winner, loser = loser, winner
+	}
+
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "**%s** in %s was edited in both Reminders and Home Assistant.\n\n",
+		conflict.Result.Title, conflict.ListName)
+	if len(conflict.Fields) > 0 {
+		_, _ = fmt.Fprintf(&b, "Both sides changed: %s. The %s edit was newer and was kept; "+
+			"other edits from both sides were merged.\n\n", strings.Join(conflict.Fields, ", "), winner)
+	} else {
+		_, _ = fmt.Fprintf(&b, "The %s version was newer and replaced the %s version.\n\n", winner, loser)
+	}
+	_, _ = fmt.Fprintf(&b, "- Reminders: %s\n", describeItem(conflict.Reminders))
+	_, _ = fmt.Fprintf(&b, "- Home Assistant: %s\n", describeItem(conflict.HA))
+	_, _ = fmt.Fprintf(&b, "- Now: %s\n", describeItem(conflict.Result))
+	return b.String()
+}
+
+// describeItem renders the content fields of item on one line.
+func describeItem(item model.Item) string {
+	parts := []string{fmt.Sprintf("%q", item.Title)}
+	if item.Description != "" {
+		parts = append(parts, fmt.Sprintf("notes %q", item.Description))
+	}
+	if item.DueDate != nil {
+		parts = append(parts, "due "+item.DueDate.Format(time.DateOnly))
+	}
+	if item.Priority != model.PriorityNone {
+		parts = append(parts, item.Priority.String()+" priority")
+	}
+	if item.Completed {
+		parts = append(parts, "completed")
+	}
+	return strings.Join(parts, ", ")
+}
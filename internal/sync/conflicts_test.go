@@ -0,0 +1,85 @@
+package sync
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+type mockConflictNotifier struct {
+	mockNotifier
+	events []map[string]interface{}
+}
+
+func (m *mockConflictNotifier) FireEvent(_ context.Context, eventType string, data map[string]interface{}) error {
+	if eventType == ConflictEventType {
+		m.events = append(m.events, data)
+	}
+	return nil
+}
+
+func TestConflictReporter_ReportsBothVersions(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := newMockStore()
+	store.seed(&state.Item{
+		RemindersUID:      "rem-1",
+		HAUID:             "ha-1",
+		ListName:          "Shopping",
+		Title:             "Buy milk",
+		LastSyncHash:      newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, older).ContentHash(),
+		RemindersModified: older,
+		HAModified:        older,
+		LastSyncedAt:      older,
+	})
+	rem := newMockReminders(newItem("rem-1", "Buy whole milk", "Shopping", model.PriorityNone, false, older.Add(2*time.Hour)))
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Buy skim milk", ModifiedAt: older.Add(time.Hour)})
+
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+	stats, err := r.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats.Resolved) != 1 {
+		t.Fatalf("Resolved = %d, want 1", len(stats.Resolved))
+	}
+
+	n := &mockConflictNotifier{}
+	rep := &conflictReporter{notifier: n, log: testLogger}
+	rep.report(context.Background(), stats.Resolved)
+
+	if len(n.created) != 1 {
+		t.Fatalf("notifications = %d, want 1", len(n.created))
+	}
+	for _, want := range []string{"Buy whole milk", "Buy skim milk", "Shopping"} {
+		if !strings.Contains(n.created[0], want) {
+			t.Errorf("notification = %q, want it to mention %q", n.created[0], want)
+		}
+	}
+	if len(n.events) != 1 {
+		t.Fatalf("events = %d, want 1", len(n.events))
+	}
+	if got := n.events[0]["kept"]; got != "reminders" {
+		t.Errorf("kept = %v, want reminders", got)
+	}
+	if got := n.events[0]["home_assistant"].(map[string]interface{})["title"]; got != "Buy skim milk" {
+		t.Errorf("home_assistant title = %v, want Buy skim milk", got)
+	}
+}
+
+func TestConflictReporter_StableIDPerItem(t *testing.T) {
+	rep := &conflictReporter{log: testLogger}
+	a := Conflict{ListName: "Shopping", Reminders: model.Item{UID: "rem-1"}}
+	b := Conflict{ListName: "Shopping", Reminders: model.Item{UID: "rem-2"}}
+
+	if rep.notificationID(a) != rep.notificationID(a) {
+		t.Error("notification ID differs between calls for the same item")
+	}
+	if rep.notificationID(a) == rep.notificationID(b) {
+		t.Error("different items share a notification ID")
+	}
+}
@@ -0,0 +1,342 @@
+package sync
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// Dedupe walks the user through the titles that several items of a list
+// share. For each such title it proposes to merge the items into one or to
+// number them apart ("Milk", "Milk (2)", …), and applies the choice to both
+// sides. Afterwards the remaining items are linked by their now unique
+// titles, with Reminders' content winning the next pass.
+//
+// Dedupe writes to both sides, so the daemon must not run at the same time.
+type Dedupe struct {
+	rem     TaskBackend
+	targets *Registry
+	store   StateStore
+	log     *slog.Logger
+	clock   clock.Clock
+	in      *bufio.Scanner // answers (os.Stdin in production)
+	out     io.Writer      // proposals (os.Stdout in production)
+}
+
+// NewDedupe creates a Dedupe for the Reminders backend rem and the list
+// mapping targets in targets, keeping state in store. Answers are read from
+// reader and proposals written to writer.
+func NewDedupe(rem TaskBackend, targets *Registry, store StateStore, logger *slog.Logger, reader io.Reader, writer io.Writer) *Dedupe {
+	return &Dedupe{
+		rem:     rem,
+		targets: targets,
+		store:   store,
+		log:     logger,
+		clock:   clock.Real(),
+		in:      bufio.NewScanner(reader),
+		out:     writer,
+	}
+}
+
+// DedupeResult counts what a [Dedupe.Run] found and changed.
+type DedupeResult struct {
+	Found   int // titles shared by several items
+	Merged  int
+	Renamed int
+}
+
+// dupGroup is the items of one list that share a title.
+type dupGroup struct {
+	listName string
+	tgt      target
+	rem      []*model.Item
+	ha       []*model.Item
+}
+
+// Run proposes a fix for every duplicate title in listMappings, one at a
+// time. It stops at the first failed write; groups fixed until then stay
+// fixed.
+func (d *Dedupe) Run(ctx context.Context, listMappings map[string]string) (DedupeResult, error) {
+	var res DedupeResult
+	for _, listName := range slices.Sorted(maps.Keys(listMappings)) {
+		groups, err := d.find(ctx, listName, listMappings[listName])
+		if err != nil {
+			return res, err
+		}
+		for _, g := range groups {
+			res.Found++
+			d.print(g)
+			switch d.choose() {
+			case "m":
+				if err := d.merge(ctx, g); err != nil {
+					return res, err
+				}
+				res.Merged++
+			case "r":
+				if err := d.rename(ctx, g); err != nil {
+					return res, err
+				}
+				res.Renamed++
+			default:
+				_, _ = fmt.Fprintln(d.out, "  Skipped.")
+			}
+		}
+	}
+	return res, nil
+}
+
+// find returns the duplicate groups of one list mapping.
+func (d *Dedupe) find(ctx context.Context, listName, targetName string) ([]dupGroup, error) {
+	b, list, err := d.targets.resolve(targetName, listName)
+	if err != nil {
+		return nil, err
+	}
+	tgt := target{backend: b, list: list}
+	remItems, haItems, err := d.fetch(ctx, listName, tgt)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []dupGroup
+	for _, dup := range findDuplicates(listName, remItems, haItems) {
+		key := titleKey(dup.Title)
+		g := dupGroup{listName: listName, tgt: tgt}
+		for _, it := range remItems {
+			if titleKey(it.Title) == key {
+				g.rem = append(g.rem, it)
+			}
+		}
+		for _, it := range haItems {
+			if titleKey(it.Title) == key {
+				g.ha = append(g.ha, it)
+			}
+		}
+		// Oldest first: the oldest item keeps its title when renaming.
+		byAge := func(a, b *model.Item) int { return a.ModifiedAt.Compare(b.ModifiedAt) }
+		slices.SortStableFunc(g.rem, byAge)
+		slices.SortStableFunc(g.ha, byAge)
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// fetch returns the items of listName on both sides.
+func (d *Dedupe) fetch(ctx context.Context, listName string, tgt target) (remItems, haItems []*model.Item, err error) {
+	all, err := d.rem.Fetch(ctx, []string{listName})
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching reminders for %q: %w", listName, err)
+	}
+	for _, it := range all {
+		if it.ListName == listName {
+			remItems = append(remItems, it)
+		}
+	}
+	haItems, err = tgt.backend.Fetch(ctx, []string{tgt.list})
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching HA items for %s: %w", tgt.list, err)
+	}
+	for _, it := range haItems {
+		it.ListName = listName
+	}
+	return remItems, haItems, nil
+}
+
+// print describes a group and the choices for it.
+func (d *Dedupe) print(g dupGroup) {
+	title := cmp.Or(firstTitle(g.rem), firstTitle(g.ha))
+	_, _ = fmt.Fprintf(d.out, "\nList %q: %q appears %d× in Reminders, %d× in %s\n",
+		g.listName, title, len(g.rem), len(g.ha), g.tgt.list)
+	for _, it := range g.rem {
+		_, _ = fmt.Fprintf(d.out, "  Reminders  %s\n", describeCopy(it))
+	}
+	for _, it := range g.ha {
+		_, _ = fmt.Fprintf(d.out, "  %-10s %s\n", "HA", describeCopy(it))
+	}
+}
+
+// choose asks what to do with a group and returns "m", "r", or "s".
+func (d *Dedupe) choose() string {
+	_, _ = fmt.Fprint(d.out, "[m]erge into the newest Reminders item, [r]ename with numbers, or [s]kip? [s] ")
+	if !d.in.Scan() {
+		return "s"
+	}
+	switch strings.ToLower(strings.TrimSpace(d.in.Text())) {
+	case "m", "merge":
+		return "m"
+	case "r", "rename":
+		return "r"
+	}
+	return "s"
+}
+
+// merge keeps the newest Reminders item, or the newest HA item if Reminders
+// has none, and deletes every other item of the group on both sides.
+func (d *Dedupe) merge(ctx context.Context, g dupGroup) error {
+	keepRem := len(g.rem) > 0
+	for i, it := range g.rem {
+		if i == len(g.rem)-1 {
+			break // the newest is kept
+		}
+		if err := d.rem.Delete(ctx, g.listName, it); err != nil {
+			return fmt.Errorf("deleting %q from Reminders: %w", it.Title, err)
+		}
+	}
+	// HA removes items by title, so which copy survives is up to HA; with
+	// a Reminders item kept, its content overwrites the survivor anyway.
+	for i, it := range g.ha {
+		if i == len(g.ha)-1 {
+			break
+		}
+		if err := g.tgt.backend.Delete(ctx, g.tgt.list, it); err != nil {
+			return fmt.Errorf("deleting %q from %s: %w", it.Title, g.tgt.list, err)
+		}
+	}
+
+	kept := g.ha
+	if keepRem {
+		kept = g.rem
+	}
+	title := kept[len(kept)-1].Title
+	d.log.Info("merged duplicate items", "list", g.listName, "title", title)
+	_, _ = fmt.Fprintf(d.out, "  ✓ Merged into %q\n", title)
+	return d.relink(ctx, g, []string{title})
+}
+
+// rename leaves the oldest item on each side as it is and gives the others
+// numbered titles, the same numbers on both sides, so they pair up.
+func (d *Dedupe) rename(ctx context.Context, g dupGroup) error {
+	remItems, haItems, err := d.fetch(ctx, g.listName, g.tgt)
+	if err != nil {
+		return err
+	}
+	taken := make(map[string]bool, len(remItems)+len(haItems))
+	for _, it := range slices.Concat(remItems, haItems) {
+		taken[titleKey(it.Title)] = true
+	}
+
+	base := cmp.Or(firstTitle(g.rem), firstTitle(g.ha))
+	titles := []string{base}
+	for n := 2; len(titles) < max(len(g.rem), len(g.ha)); n++ {
+		t := fmt.Sprintf("%s (%d)", base, n)
+		if !taken[titleKey(t)] {
+			titles = append(titles, t)
+		}
+	}
+
+	for i, it := range g.rem[min(1, len(g.rem)):] {
+		renamed := *it
+		renamed.Title = titles[i+1]
+		if err := d.rem.Update(ctx, g.listName, it, &renamed, model.FieldTitle); err != nil {
+			return fmt.Errorf("renaming %q in Reminders: %w", it.Title, err)
+		}
+	}
+	for i, it := range g.ha[min(1, len(g.ha)):] {
+		renamed := *it
+		renamed.Title = titles[i+1]
+		if err := g.tgt.backend.Update(ctx, g.tgt.list, it, &renamed, model.FieldTitle); err != nil {
+			return fmt.Errorf("renaming %q in %s: %w", it.Title, g.tgt.list, err)
+		}
+	}
+
+	d.log.Info("renamed duplicate items", "list", g.listName, "title", base, "copies", len(titles))
+	_, _ = fmt.Fprintf(d.out, "  ✓ Renamed to %s\n", strings.Join(quoteAll(titles), ", "))
+	return d.relink(ctx, g, titles)
+}
+
+// relink replaces the state rows of the group's items with one row per title
+// in titles that exactly one item on each side now carries. The rows record
+// the HA item as last synced, so the next pass pushes the Reminders item's
+// content to HA where they differ. Items left unpaired are synced as new.
+func (d *Dedupe) relink(ctx context.Context, g dupGroup, titles []string) error {
+	rows, err := d.store.GetAllItemsForList(ctx, g.listName)
+	if err != nil {
+		return fmt.Errorf("reading state for %q: %w", g.listName, err)
+	}
+	uids := make(map[string]bool, len(g.rem)+len(g.ha))
+	for _, it := range slices.Concat(g.rem, g.ha) {
+		uids[it.UID] = true
+	}
+	for _, row := range rows {
+		if uids[row.RemindersUID] || uids[row.HAUID] {
+			if err := d.store.DeleteItem(ctx, row.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	remItems, haItems, err := d.fetch(ctx, g.listName, g.tgt)
+	if err != nil {
+		return err
+	}
+	now := d.clock.Now().UTC()
+	for _, title := range titles {
+		rem := withTitle(remItems, title)
+		ha := withTitle(haItems, title)
+		if len(rem) != 1 || len(ha) != 1 {
+			continue
+		}
+		row := &state.Item{
+			RemindersUID:      rem[0].UID,
+			HAUID:             ha[0].UID,
+			ListName:          g.listName,
+			Title:             rem[0].Title,
+			LastSyncHash:      ha[0].ContentHash(),
+			RemindersModified: rem[0].ModifiedAt,
+			HAModified:        ha[0].ModifiedAt,
+			LastSyncedAt:      now,
+			Base:              baseOf(ha[0]),
+		}
+		if err := d.store.UpsertItem(ctx, row); err != nil {
+			return fmt.Errorf("linking %q: %w", title, err)
+		}
+	}
+	return nil
+}
+
+// withTitle returns the items whose title matches title like [titleKey].
+func withTitle(items []*model.Item, title string) []*model.Item {
+	var out []*model.Item
+	for _, it := range items {
+		if titleKey(it.Title) == titleKey(title) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// firstTitle returns the title of the first of items, or "".
+func firstTitle(items []*model.Item) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return items[0].Title
+}
+
+// describeCopy renders an item of a duplicate group, with its last change
+// to tell the copies apart.
+func describeCopy(it *model.Item) string {
+	if it.ModifiedAt.IsZero() {
+		return describeItem(*it)
+	}
+	return describeItem(*it) + ", modified " + it.ModifiedAt.Local().Format(time.DateTime)
+}
+
+// quoteAll quotes every string in s.
+func quoteAll(s []string) []string {
+	out := make([]string, len(s))
+	for i, v := range s {
+		out[i] = fmt.Sprintf("%q", v)
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// duplicatedMilk returns two linked pairs of items that are all titled
+// "Milk", the second pair newer.
+func duplicatedMilk() (*mockReminders, *mockHA, *mockStore) {
+	older := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	rem := newMockReminders(
+		newItem("rem-1", "Milk", "Shopping", model.PriorityNone, false, older),
+		newItem("rem-2", "Milk", "Shopping", model.PriorityHigh, false, newer),
+	)
+	ha := newMockHA()
+	ha.addItems("todo.shopping",
+		model.Item{UID: "ha-1", Title: "Milk", ModifiedAt: older},
+		model.Item{UID: "ha-2", Title: "Milk", Priority: model.PriorityHigh, ModifiedAt: newer},
+	)
+	store := newMockStore()
+	for _, pair := range [][2]string{{"rem-1", "ha-1"}, {"rem-2", "ha-2"}} {
+		it := rem.get(pair[0])
+		store.seed(&state.Item{
+			RemindersUID: it.UID, HAUID: pair[1], ListName: "Shopping", Title: "Milk",
+			LastSyncHash: it.ContentHash(), RemindersModified: it.ModifiedAt, HAModified: it.ModifiedAt,
+		})
+	}
+	return rem, ha, store
+}
+
+func TestEngine_ReportsDuplicateTitles(t *testing.T) {
+	rem, ha, store := duplicatedMilk()
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+	e := NewEngine(r, nil, testMappings, 30*time.Second, testLogger)
+
+	if _, err := e.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	want := []Duplicate{{ListName: "Shopping", Title: "Milk", Reminders: 2, HA: 2}}
+	if got := e.Status().Duplicates; !slices.Equal(got, want) {
+		t.Errorf("Duplicates = %+v, want %+v", got, want)
+	}
+}
+
+func TestDedupe_Merge(t *testing.T) {
+	ctx := context.Background()
+	rem, ha, store := duplicatedMilk()
+	d := NewDedupe(rem, NewRegistry(ha), store, testLogger, strings.NewReader("m\n"), io.Discard)
+
+	res, err := d.Run(ctx, testMappings)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if res != (DedupeResult{Found: 1, Merged: 1}) {
+		t.Errorf("result = %+v, want one merge", res)
+	}
+	if rem.count() != 1 || rem.get("rem-2") == nil {
+		t.Errorf("Reminders kept %d item(s), want only the newest (rem-2)", rem.count())
+	}
+	if n := len(ha.getItems("todo.shopping")); n != 1 {
+		t.Errorf("HA kept %d item(s), want 1", n)
+	}
+	rows, _ := store.GetAllItemsForList(ctx, "Shopping")
+	if len(rows) != 1 || rows[0].RemindersUID != "rem-2" {
+		t.Fatalf("state rows = %+v, want one for rem-2", rows)
+	}
+
+	assertSettled(t, rem, ha, store)
+	if got := ha.getItems("todo.shopping")[0].Priority; got != model.PriorityHigh {
+		t.Errorf("HA priority = %v, want the kept reminder's (high)", got)
+	}
+}
+
+func TestDedupe_Rename(t *testing.T) {
+	ctx := context.Background()
+	rem, ha, store := duplicatedMilk()
+	d := NewDedupe(rem, NewRegistry(ha), store, testLogger, strings.NewReader("rename\n"), io.Discard)
+
+	res, err := d.Run(ctx, testMappings)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if res != (DedupeResult{Found: 1, Renamed: 1}) {
+		t.Errorf("result = %+v, want one rename", res)
+	}
+	if got := rem.get("rem-2").Title; got != "Milk (2)" {
+		t.Errorf("newer reminder title = %q, want %q", got, "Milk (2)")
+	}
+	var haTitles []string
+	for _, it := range ha.getItems("todo.shopping") {
+		haTitles = append(haTitles, it.Title)
+	}
+	slices.Sort(haTitles)
+	if !slices.Equal(haTitles, []string{"Milk", "Milk (2)"}) {
+		t.Errorf("HA titles = %v, want [Milk, Milk (2)]", haTitles)
+	}
+
+	assertSettled(t, rem, ha, store)
+}
+
+func TestDedupe_SkipChangesNothing(t *testing.T) {
+	rem, ha, store := duplicatedMilk()
+	d := NewDedupe(rem, NewRegistry(ha), store, testLogger, strings.NewReader("\n"), io.Discard)
+
+	if _, err := d.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if rem.count() != 2 || len(ha.getItems("todo.shopping")) != 2 || len(store.items) != 2 {
+		t.Error("skipping a group changed items or state")
+	}
+}
+
+// assertSettled runs a pass after dedupe and checks it created and deleted
+// nothing, so every remaining item was linked.
+func assertSettled(t *testing.T, rem *mockReminders, ha *mockHA, store *mockStore) {
+	t.Helper()
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+	stats, err := r.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("pass after dedupe: %v", err)
+	}
+	if stats.Created+stats.Deleted != 0 {
+		t.Errorf("pass after dedupe created %d and deleted %d item(s), want none", stats.Created, stats.Deleted)
+	}
+	if len(stats.Duplicates) != 0 {
+		t.Errorf("duplicates left: %+v", stats.Duplicates)
+	}
+}
@@ -0,0 +1,76 @@
+package sync
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// listDigest is what a list looked like on both sides at its last clean
+// reconcile.
+type listDigest struct {
+	rem    string      // Reminders items; see remindersDigest
+	target string      // target items; see itemsDigest
+	dups   []Duplicate // found by that reconcile, reported again while it is skipped
+}
+
+// remindersChanged reports whether the Reminders items of listName differ
+// from those of its last clean reconcile.
+func (r *Reconciler) remindersChanged(listName string, remByUID map[string]*model.Item) bool {
+	digest := remindersDigest(listName, remByUID)
+	r.digestMu.Lock()
+	defer r.digestMu.Unlock()
+	last, ok := r.digests[listName]
+	return !ok || last.rem != digest
+}
+
+// lastDigest returns the digest of the last clean reconcile of listName.
+func (r *Reconciler) lastDigest(listName string) (listDigest, bool) {
+	r.digestMu.Lock()
+	defer r.digestMu.Unlock()
+	d, ok := r.digests[listName]
+	return d, ok
+}
+
+// recordDigest remembers d for listName after a clean reconcile, or forgets
+// the list's digest after a failed one so that the next pass retries it.
+func (r *Reconciler) recordDigest(listName string, d listDigest, clean bool) {
+	r.digestMu.Lock()
+	defer r.digestMu.Unlock()
+	if !clean {
+		delete(r.digests, listName)
+		return
+	}
+	if r.digests == nil {
+		r.digests = make(map[string]listDigest)
+	}
+	r.digests[listName] = d
+}
+
+// remindersDigest hashes the UIDs and content of the items of listName in
+// remByUID.
+func remindersDigest(listName string, remByUID map[string]*model.Item) string {
+	var items []*model.Item
+	for _, it := range remByUID {
+		if it.ListName == listName {
+			items = append(items, it)
+		}
+	}
+	return itemsDigest(items)
+}
+
+// itemsDigest hashes the UIDs and content of items, in any order.
+func itemsDigest(items []*model.Item) string {
+	items = slices.Clone(items)
+	slices.SortFunc(items, func(a, b *model.Item) int { return cmp.Compare(a.UID, b.UID) })
+
+	h := sha256.New()
+	for _, it := range items {
+		_, _ = fmt.Fprintf(h, "%s|%s\n", it.UID, it.ContentHash())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,97 @@
+package sync
+
+import (
+	"context"
+	"maps"
+	"slices"
+)
+
+// ListSource enumerates the lists of a task backend. Implemented by
+// [*reminders.Backend].
+type ListSource interface {
+	Lists(ctx context.Context) ([]string, error)
+}
+
+// ListProvisioner creates a sync target for a list that has no mapping yet
+// and persists the new mapping, so it survives a restart.
+type ListProvisioner interface {
+	// Provision returns the target the list is now mapped to.
+	Provision(ctx context.Context, list string) (target string, err error)
+}
+
+// listDiscovery finds source lists without a mapping; see
+// [WithListDiscovery].
+type listDiscovery struct {
+	src         ListSource
+	ignore      map[string]bool
+	provisioner ListProvisioner // nil to only suggest
+	seen        map[string]bool // lists already suggested or provisioned
+}
+
+// WithListDiscovery checks src for lists without a mapping before every full
+// pass. Each one is logged once as a suggestion and reported in
+// [Status.UnmappedLists]. With a non-nil provisioner a target is created for
+// it instead, and the list syncs from that pass on; provisioning is attempted
+// once per list and engine. Lists in ignore are never reported.
+func WithListDiscovery(src ListSource, ignore []string, provisioner ListProvisioner) EngineOption {
+	return func(e *Engine) {
+		d := &listDiscovery{
+			src:         src,
+			ignore:      make(map[string]bool, len(ignore)),
+			provisioner: provisioner,
+			seen:        make(map[string]bool),
+		}
+		for _, name := range ignore {
+			d.ignore[name] = true
+		}
+		e.discovery = d
+	}
+}
+
+// discoverLists looks for unmapped lists and provisions them when
+// configured. The caller must hold passMu.
+func (e *Engine) discoverLists(ctx context.Context) {
+	d := e.discovery
+	lists, err := d.src.Lists(ctx)
+	if err != nil {
+		// The pass that follows reports the underlying problem.
+		e.log.Debug("listing Reminders lists for discovery failed", "error", err)
+		return
+	}
+
+	var unmapped []string
+	for _, list := range lists {
+		if _, mapped := e.listMappings[list]; mapped || d.ignore[list] {
+			continue
+		}
+		if d.seen[list] {
+			unmapped = append(unmapped, list)
+			continue
+		}
+		d.seen[list] = true
+
+		if d.provisioner == nil {
+			e.log.Info("Reminders list is not synced; map it with 'reminderrelay add-mapping' or add it to discovery.ignore",
+				"list", list)
+			unmapped = append(unmapped, list)
+			continue
+		}
+		target, err := d.provisioner.Provision(ctx, list)
+		if err != nil {
+			e.log.Error("creating a target for new Reminders list failed; map it with 'reminderrelay add-mapping'",
+				"list", list, "error", err)
+			unmapped = append(unmapped, list)
+			continue
+		}
+		e.log.Info("mapped new Reminders list", "list", list, "target", target)
+		// Copy rather than mutate: the caller's map may be shared.
+		mappings := maps.Clone(e.listMappings)
+		mappings[list] = target
+		e.listMappings = mappings
+	}
+	slices.Sort(unmapped)
+
+	e.statusMu.Lock()
+	e.status.UnmappedLists = unmapped
+	e.statusMu.Unlock()
+}
@@ -0,0 +1,90 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// staticLists is a ListSource with a fixed set of lists.
+type staticLists []string
+
+func (s staticLists) Lists(context.Context) ([]string, error) { return s, nil }
+
+// fakeProvisioner maps every list to "todo.<list>", or fails with err.
+type fakeProvisioner struct {
+	calls []string
+	err   error
+}
+
+func (f *fakeProvisioner) Provision(_ context.Context, list string) (string, error) {
+	f.calls = append(f.calls, list)
+	if f.err != nil {
+		return "", f.err
+	}
+	return "todo." + list, nil
+}
+
+func TestEngine_ReportsUnmappedLists(t *testing.T) {
+	r := NewReconciler(newMockReminders(), NewRegistry(newMockHA()), newMockStore(), testLogger)
+	e := NewEngine(r, nil, testMappings, 30*time.Second, testLogger,
+		WithListDiscovery(staticLists{"Shopping", "Work", "Archive", "Errands"}, []string{"Archive"}, nil))
+
+	if _, err := e.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if got, want := e.Status().UnmappedLists, []string{"Errands", "Work"}; !slices.Equal(got, want) {
+		t.Errorf("UnmappedLists = %v, want %v", got, want)
+	}
+}
+
+func TestEngine_ProvisionsNewLists(t *testing.T) {
+	at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	rem := newMockReminders(newItem("rem-1", "Draft talk", "Work", model.PriorityNone, false, at))
+	ha := newMockHA()
+	prov := &fakeProvisioner{}
+	r := NewReconciler(rem, NewRegistry(ha), newMockStore(), testLogger)
+	e := NewEngine(r, nil, testMappings, 30*time.Second, testLogger,
+		WithListDiscovery(staticLists{"Shopping", "Work"}, nil, prov))
+
+	for range 2 {
+		if _, err := e.RunOnce(context.Background()); err != nil {
+			t.Fatalf("RunOnce() error = %v", err)
+		}
+	}
+	if !slices.Equal(prov.calls, []string{"Work"}) {
+		t.Errorf("provisioned %v, want [Work] once", prov.calls)
+	}
+	if items := ha.getItems("todo.Work"); len(items) != 1 || items[0].Title != "Draft talk" {
+		t.Errorf("todo.Work items = %+v, want the Work reminder", items)
+	}
+	if got := e.Status().UnmappedLists; len(got) != 0 {
+		t.Errorf("UnmappedLists = %v, want none", got)
+	}
+	if _, ok := testMappings["Work"]; ok {
+		t.Error("provisioning modified the caller's mappings")
+	}
+}
+
+func TestEngine_ProvisioningFailureIsReported(t *testing.T) {
+	prov := &fakeProvisioner{err: errors.New("not an admin")}
+	r := NewReconciler(newMockReminders(), NewRegistry(newMockHA()), newMockStore(), testLogger)
+	e := NewEngine(r, nil, testMappings, 30*time.Second, testLogger,
+		WithListDiscovery(staticLists{"Shopping", "Work"}, nil, prov))
+
+	for range 2 {
+		if _, err := e.RunOnce(context.Background()); err != nil {
+			t.Fatalf("RunOnce() error = %v", err)
+		}
+	}
+	if len(prov.calls) != 1 {
+		t.Errorf("Provision called %d times, want 1", len(prov.calls))
+	}
+	if got := e.Status().UnmappedLists; !slices.Equal(got, []string{"Work"}) {
+		t.Errorf("UnmappedLists = %v, want [Work]", got)
+	}
+}
@@ -0,0 +1,61 @@
+package sync
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// Duplicate is a title shared by several items of one list on either side.
+// Home Assistant addresses items by title and bootstrap links them by title,
+// so such items may be updated, removed, or linked in place of each other.
+type Duplicate struct {
+	ListName string
+	Title    string
+	// Reminders and HA count the items with the title on each side.
+	Reminders int
+	HA        int
+}
+
+// findDuplicates returns every title that more than one of remItems or of
+// haItems carries, sorted by title. Titles are compared like [titleKey].
+func findDuplicates(listName string, remItems, haItems []*model.Item) []Duplicate {
+	byKey := make(map[string]*Duplicate)
+	count := func(items []*model.Item, side func(*Duplicate) *int) {
+		for _, it := range items {
+			key := titleKey(it.Title)
+			d, ok := byKey[key]
+			if !ok {
+				d = &Duplicate{ListName: listName, Title: it.Title}
+				byKey[key] = d
+			}
+			*side(d)++
+		}
+	}
+	count(remItems, func(d *Duplicate) *int { return &d.Reminders })
+	count(haItems, func(d *Duplicate) *int { return &d.HA })
+
+	var dups []Duplicate
+	for _, d := range byKey {
+		if d.Reminders > 1 || d.HA > 1 {
+			dups = append(dups, *d)
+		}
+	}
+	slices.SortFunc(dups, func(a, b Duplicate) int { return cmp.Compare(a.Title, b.Title) })
+	return dups
+}
+
+// noteDuplicates logs the duplicates of a full pass that the previous pass
+// did not report. The caller must hold passMu.
+func (e *Engine) noteDuplicates(dups []Duplicate) {
+	seen := make(map[Duplicate]bool, len(dups))
+	for _, d := range dups {
+		seen[d] = true
+		if !e.duplicates[d] {
+			e.log.Warn("several items share a title; updates and deletes may hit the wrong one, run 'reminderrelay dedupe'",
+				"list", d.ListName, "title", d.Title, "reminders", d.Reminders, "ha", d.HA)
+		}
+	}
+	e.duplicates = seen
+}
@@ -2,9 +2,16 @@ package sync
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/state"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
@@ -20,39 +27,106 @@ const (
 	metricDeleted   = "reminderrelay.sync.items.deleted"
 	metricConflicts = "reminderrelay.sync.conflicts"
 	metricErrors    = "reminderrelay.sync.errors"
+	metricLatency   = "reminderrelay.sync.latency"
+	metricListTime  = "reminderrelay.sync.list.duration"
+
+	// attrInstance labels spans and metrics with the instance set by
+	// [WithInstance].
+	attrInstance = "reminderrelay.instance"
+
+	// attrDirection labels latency samples with their [Direction].
+	attrDirection = "reminderrelay.direction"
+
+	// attrList and attrEntity label the item counters and list durations
+	// with the list mapping they belong to.
+	attrList   = "reminderrelay.list_name"
+	attrEntity = "reminderrelay.entity_id"
 )
 
 // HAConnector provides WebSocket lifecycle methods for the Engine.
 // Implemented by [homeassistant.Adapter].
 type HAConnector interface {
-	HASource
 	Connect(ctx context.Context) error
 	Close() error
 	SubscribeChanges(ctx context.Context, entityIDs []string, callback func(entityID string)) error
+	// OnRestart sets fn to be called when HA may have restarted, so every
+	// list is checked again; fn does not block.
+	OnRestart(fn func())
 }
 
 // Engine orchestrates the sync lifecycle: polling loop + optional WebSocket
-// listener for instant HA updates. Create one with [NewEngine] and start it
+// listener for instant HA updates and optional EventKit change watcher for
+// instant Reminders updates. Create one with [NewEngine] and start it
 // with [Engine.Run].
 type Engine struct {
 	reconciler   *Reconciler
 	haConn       HAConnector
-	listMappings map[string]string
+	listMappings map[string]string // replaced, never mutated, under passMu
 	pollInterval time.Duration
+	safetyPoll   time.Duration // see WithRemindersWatcher
 	log          *slog.Logger
+	clock        clock.Clock
+	instance     string
 
 	// OTel instruments — always non-nil (no-op when telemetry is disabled).
-	tracer     trace.Tracer
-	cntCreated metric.Int64Counter
-	cntUpdated metric.Int64Counter
-	cntDeleted metric.Int64Counter
+	tracer       trace.Tracer
+	spanOpts     []trace.SpanStartOption
+	cntCreated   metric.Int64Counter
+	cntUpdated   metric.Int64Counter
+	cntDeleted   metric.Int64Counter
 	cntConflicts metric.Int64Counter
-	cntErrors  metric.Int64Counter
+	cntErrors    metric.Int64Counter
+	histLatency  metric.Float64Histogram
+	histListTime metric.Float64Histogram
+
+	problems  []*problemTracker   // one per WithProblemNotifier
+	conflicts []*conflictReporter // one per WithConflictNotifier
+
+	passMu   sync.Mutex // serialises full passes from the poll loop and SyncNow
+	paused   atomic.Bool
+	statusMu sync.Mutex
+	status   Status // guarded by statusMu; see Engine.Status
+
+	nextConflictID int // guarded by statusMu
+
+	latencyObjective time.Duration
+	latencies        map[Direction][]time.Duration // guarded by statusMu; recent samples, oldest first
+
+	discovery *listDiscovery // nil unless WithListDiscovery; used under passMu
+	tracker   *listTracker   // nil unless WithListTracking; used under passMu
+
+	duplicates map[Duplicate]bool // reported by the last full pass; used under passMu
+
+	backup func(context.Context) error // nil unless WithPassBackup; run under passMu
+
+	watcher RemindersWatcher // nil unless WithRemindersWatcher
+}
+
+// EngineOption configures optional Engine behaviour.
+type EngineOption func(*Engine)
+
+// WithProblemNotifier publishes a persistent notification through n whenever
+// the daemon is degraded — a list has failed threshold consecutive passes,
+// the HA token is rejected, or Reminders access is denied — and dismisses it
+// once every list syncs again. It may be given once per notification channel.
+func WithProblemNotifier(n ProblemNotifier, threshold int) EngineOption {
+	return func(e *Engine) {
+		e.problems = append(e.problems, newProblemTracker(n, threshold, e.instance, e.log))
+	}
+}
+
+// WithPassBackup runs backup, which should write a backup of the state DB,
+// before every full pass. A failed backup is logged and the pass goes ahead.
+// Passes for a single list after a WebSocket event are not backed up.
+func WithPassBackup(backup func(context.Context) error) EngineOption {
+	return func(e *Engine) { e.backup = backup }
 }
 
 // NewEngine creates an Engine. If haConn is nil, WebSocket subscriptions are
-// skipped and the engine runs polling-only.
-func NewEngine(reconciler *Reconciler, haConn HAConnector, listMappings map[string]string, pollInterval time.Duration, logger *slog.Logger) *Engine {
+// skipped and the engine runs polling-only. The polling schedule follows the
+// reconciler's clock (see [WithClock]); log lines, spans, and metrics carry
+// the reconciler's instance label (see [WithInstance]).
+func NewEngine(reconciler *Reconciler, haConn HAConnector, listMappings map[string]string, pollInterval time.Duration, logger *slog.Logger, opts ...EngineOption) *Engine {
 	tracer := otel.Tracer(otelScope)
 	meter := otel.Meter(otelScope)
 
@@ -65,12 +139,14 @@ func NewEngine(reconciler *Reconciler, haConn HAConnector, listMappings map[stri
 		return c
 	}
 
-	return &Engine{
+	e := &Engine{
 		reconciler:   reconciler,
 		haConn:       haConn,
 		listMappings: listMappings,
 		pollInterval: pollInterval,
 		log:          logger,
+		clock:        reconciler.clock,
+		instance:     reconciler.instance,
 
 		tracer:       tracer,
 		cntCreated:   mustCounter(metricCreated, "Number of items created during sync"),
@@ -79,32 +155,58 @@ func NewEngine(reconciler *Reconciler, haConn HAConnector, listMappings map[stri
 		cntConflicts: mustCounter(metricConflicts, "Number of conflict resolutions during sync"),
 		cntErrors:    mustCounter(metricErrors, "Number of errors encountered during sync"),
 	}
+	mustHistogram := func(name, desc string) metric.Float64Histogram {
+		h, err := meter.Float64Histogram(name, metric.WithDescription(desc), metric.WithUnit("s"))
+		if err != nil {
+			logger.Error("creating OTel histogram", "name", name, "error", err)
+			return noop.Float64Histogram{}
+		}
+		return h
+	}
+	e.histLatency = mustHistogram(metricLatency, "Time from observing a change to writing it to the other side")
+	e.histListTime = mustHistogram(metricListTime, "Time taken to reconcile one list")
+	if e.instance != "" {
+		e.log = logger.With("instance", e.instance)
+		attr := attribute.String(attrInstance, e.instance)
+		e.spanOpts = []trace.SpanStartOption{trace.WithAttributes(attr)}
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
 // reconcile runs one full reconcile pass, recording a trace span and metrics.
 func (e *Engine) reconcile(ctx context.Context) (Stats, error) {
-	ctx, span := e.tracer.Start(ctx, spanReconcile)
-	defer span.End()
+	e.passMu.Lock()
+	defer e.passMu.Unlock()
 
-	stats, err := e.reconciler.Run(ctx, e.listMappings)
+	ctx, span := e.tracer.Start(ctx, spanReconcile, e.spanOpts...)
+	defer span.End()
 
-	// Record counters — these are always safe even if the span is a no-op.
-	if stats.Created > 0 {
-		e.cntCreated.Add(ctx, int64(stats.Created))
-	}
-	if stats.Updated > 0 {
-		e.cntUpdated.Add(ctx, int64(stats.Updated))
-	}
-	if stats.Deleted > 0 {
-		e.cntDeleted.Add(ctx, int64(stats.Deleted))
+	// Renames are followed first so a renamed list is not taken for a new,
+	// unmapped one.
+	if e.tracker != nil {
+		e.trackLists(ctx)
 	}
-	if stats.Conflicts > 0 {
-		e.cntConflicts.Add(ctx, int64(stats.Conflicts))
+	if e.discovery != nil {
+		e.discoverLists(ctx)
 	}
-	if stats.Errors > 0 {
-		e.cntErrors.Add(ctx, int64(stats.Errors))
+	if e.backup != nil {
+		if err := e.backup(ctx); err != nil {
+			e.log.Error("backing up state DB before pass", "error", err)
+		}
 	}
 
+	started := e.clock.Now()
+	stats, err := e.reconciler.Run(ctx, e.listMappings)
+	e.recordPass(stats, err)
+	e.recordRun(ctx, runFull, started, stats, err)
+	e.noteDuplicates(stats.Duplicates)
+
+	// Record counters — these are always safe even if the span is a no-op.
+	e.recordMetrics(ctx, stats)
+
 	span.SetAttributes(
 		attribute.Int("sync.created", stats.Created),
 		attribute.Int("sync.updated", stats.Updated),
@@ -115,46 +217,168 @@ func (e *Engine) reconcile(ctx context.Context) (Stats, error) {
 	if err != nil {
 		span.RecordError(err)
 	}
+
+	if len(e.problems) > 0 {
+		lists := make([]string, 0, len(e.listMappings))
+		for name := range e.listMappings {
+			lists = append(lists, name)
+		}
+		for _, p := range e.problems {
+			p.observe(ctx, lists, stats, err)
+		}
+	}
+	e.reportConflicts(ctx, stats)
+	e.recordLatencies(ctx, stats.Latencies)
 	return stats, err
 }
 
+// recordMetrics adds the item counts of a pass to the counters and records
+// how long each list took, labelled with the list and its target.
+func (e *Engine) recordMetrics(ctx context.Context, stats Stats) {
+	for _, l := range stats.Lists {
+		attrs := []attribute.KeyValue{
+			attribute.String(attrList, l.ListName),
+			attribute.String(attrEntity, l.Target),
+		}
+		if e.instance != "" {
+			attrs = append(attrs, attribute.String(attrInstance, e.instance))
+		}
+		opt := metric.WithAttributes(attrs...)
+		if l.Created > 0 {
+			e.cntCreated.Add(ctx, int64(l.Created), opt)
+		}
+		if l.Updated > 0 {
+			e.cntUpdated.Add(ctx, int64(l.Updated), opt)
+		}
+		if l.Deleted > 0 {
+			e.cntDeleted.Add(ctx, int64(l.Deleted), opt)
+		}
+		if l.Conflicts > 0 {
+			e.cntConflicts.Add(ctx, int64(l.Conflicts), opt)
+		}
+		if l.Errors > 0 {
+			e.cntErrors.Add(ctx, int64(l.Errors), opt)
+		}
+		e.histListTime.Record(ctx, l.Duration.Seconds(), opt)
+	}
+}
+
+// reportConflicts records the conflicts resolved in a pass and publishes them
+// through every conflict notifier.
+func (e *Engine) reportConflicts(ctx context.Context, stats Stats) {
+	if len(stats.Resolved) == 0 {
+		return
+	}
+	e.recordConflicts(stats.Resolved)
+	for _, c := range e.conflicts {
+		c.report(ctx, stats.Resolved)
+	}
+}
+
 // RunOnce performs a single reconciliation pass and returns.
 func (e *Engine) RunOnce(ctx context.Context) (Stats, error) {
 	return e.reconcile(ctx)
 }
 
 // Run starts the polling loop and optional WebSocket listener. It blocks until
-// ctx is cancelled.
+// ctx is cancelled, or returns early with an error matched by
+// [state.IsCorrupt] once a pass finds the state DB corrupted, since every
+// later pass would fail the same way. Either way it returns only once the
+// passes it started have stopped, after finishing the item each was writing
+// (see [WithShutdownGrace]).
 func (e *Engine) Run(ctx context.Context) error {
-	// Start WS listener if available.
+	// Deferred first so it runs last: the passes below stop once ctx is
+	// cancelled on return.
+	var passes sync.WaitGroup
+	defer passes.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	corrupted := make(chan error, 1)
+	checkCorrupt := func(stats Stats, err error) {
+		if cerr := corruptionError(stats, err); cerr != nil {
+			select {
+			case corrupted <- cerr:
+			default:
+			}
+		}
+	}
+
+	// Start WS listener if available. A restart of HA runs a full pass
+	// straight away: some integrations restore their items under new UIDs,
+	// which the pass re-links, and changes made while HA was down were not
+	// reported.
+	var restarted chan struct{}
+	var watched map[string][]string // entity ID → lists, while subscribed
 	if e.haConn != nil {
 		if err := e.haConn.Connect(ctx); err != nil {
 			e.log.Error("WebSocket connection failed, falling back to polling-only", "error", err)
 		} else {
 			defer func() { _ = e.haConn.Close() }()
 
-			entityIDs := make([]string, 0, len(e.listMappings))
-			for _, id := range e.listMappings {
-				entityIDs = append(entityIDs, id)
-			}
+			restarted = make(chan struct{}, 1)
+			e.haConn.OnRestart(func() {
+				select {
+				case restarted <- struct{}{}:
+				default: // a pass is already due
+				}
+			})
 
-			// Build reverse mapping: entityID → listName.
-			entityToList := make(map[string]string, len(e.listMappings))
-			for listName, entityID := range e.listMappings {
-				entityToList[entityID] = listName
+			// Build reverse mapping: entityID → the lists mapped to it.
+			// Targets served by another backend have no HA entity to
+			// subscribe to. Lists mapped later by discovery are not
+			// subscribed to; see pollPeriod.
+			e.passMu.Lock()
+			mappings := e.listMappings
+			e.passMu.Unlock()
+			entityToLists := make(map[string][]string, len(mappings))
+			entityIDs := make([]string, 0, len(mappings))
+			for _, listName := range slices.Sorted(maps.Keys(mappings)) {
+				target := mappings[listName]
+				if name, _ := SplitTarget(target); name != "" {
+					continue
+				}
+				if _, ok := entityToLists[target]; !ok {
+					entityIDs = append(entityIDs, target)
+				}
+				entityToLists[target] = append(entityToLists[target], listName)
 			}
+			watched = entityToLists
 
+			// Events arriving while an entity's pass is queued or running
+			// are merged into one follow-up pass.
+			queue := newPassQueue()
+			passes.Add(1)
 			go func() {
-				err := e.haConn.SubscribeChanges(ctx, entityIDs, func(entityID string) {
-					listName, ok := entityToList[entityID]
-					if !ok {
+				defer passes.Done()
+				queue.run(ctx, func(entityID string) {
+					if e.paused.Load() {
 						return
 					}
-					e.log.Info("WS event triggered reconcile", "entity_id", entityID)
-					if _, err := e.reconciler.ReconcileEntity(ctx, listName, entityID); err != nil {
-						e.log.Error("WS-triggered reconcile failed", "entity_id", entityID, "error", err)
+					for _, listName := range entityToLists[entityID] {
+						listName = e.currentName(listName)
+						e.log.Info("WS event triggered reconcile", "entity_id", entityID, "list", listName)
+						started := e.clock.Now()
+						stats, err := e.reconciler.ReconcileEntity(ctx, listName, entityID)
+						e.recordRun(ctx, runHAEvent, started, stats, err)
+						e.recordMetrics(ctx, stats)
+						if err != nil {
+							e.log.Error("WS-triggered reconcile failed", "entity_id", entityID, "error", err)
+							checkCorrupt(stats, err)
+						}
+						e.reportConflicts(ctx, stats)
+						e.recordLatencies(ctx, stats.Latencies)
 					}
 				})
+			}()
+
+			go func() {
+				err := e.haConn.SubscribeChanges(ctx, entityIDs, func(entityID string) {
+					if _, ok := entityToLists[entityID]; !ok || e.paused.Load() {
+						return
+					}
+					queue.add(entityID)
+				})
 				if err != nil && ctx.Err() == nil {
 					e.log.Error("WS subscription ended unexpectedly", "error", err)
 				}
@@ -162,24 +386,100 @@ func (e *Engine) Run(ctx context.Context) error {
 		}
 	}
 
+	if e.watcher != nil {
+		passes.Add(1)
+		go func() {
+			defer passes.Done()
+			e.watchReminders(ctx, checkCorrupt)
+		}()
+	}
+
 	// Polling loop.
-	ticker := time.NewTicker(e.pollInterval)
-	defer ticker.Stop()
+	period := e.pollPeriod(watched)
+	ticker := e.clock.NewTicker(period)
+	defer func() { ticker.Stop() }()
+	// repoll switches to the poll interval once discovery has mapped a list
+	// that no HA subscription covers.
+	repoll := func() {
+		if p := e.pollPeriod(watched); p != period {
+			e.log.Info("changing how often full passes run", "interval", p)
+			ticker.Stop()
+			period = p
+			ticker = e.clock.NewTicker(period)
+		}
+	}
 
 	// Run an immediate first pass.
-	if _, err := e.reconcile(ctx); err != nil {
-		e.log.Error("initial reconcile failed", "error", err)
-	}
+	var down outage
+	stats, err := e.reconcile(ctx)
+	e.observePoll(&down, stats, err)
+	checkCorrupt(stats, err)
+	repoll()
 
 	for {
 		select {
 		case <-ctx.Done():
 			e.log.Info("sync engine shutting down")
 			return ctx.Err()
-		case <-ticker.C:
-			if _, err := e.reconcile(ctx); err != nil {
-				e.log.Error("reconcile failed", "error", err)
+		case err := <-corrupted:
+			return fmt.Errorf("state DB: %w", err)
+		case <-restarted:
+			if e.paused.Load() {
+				continue
+			}
+			e.log.Info("Home Assistant restarted or reconnected, running a full pass")
+			stats, err := e.reconcile(ctx)
+			e.observePoll(&down, stats, err)
+			checkCorrupt(stats, err)
+			repoll()
+		case <-ticker.C():
+			if e.paused.Load() {
+				e.log.Debug("sync paused, skipping pass")
+				continue
 			}
+			if down.waiting(e.clock.Now()) {
+				continue
+			}
+			stats, err := e.reconcile(ctx)
+			e.observePoll(&down, stats, err)
+			checkCorrupt(stats, err)
+			repoll()
+		}
+	}
+}
+
+// pollPeriod returns how often full passes run. The longer safety interval
+// of [WithRemindersWatcher] applies only while every HA list is covered by
+// the WebSocket subscription to the entities of watched: with HA changes
+// not pushed, polling is all that picks them up.
+func (e *Engine) pollPeriod(watched map[string][]string) time.Duration {
+	if e.watcher == nil || e.safetyPoll <= 0 || watched == nil {
+		return e.pollInterval
+	}
+	e.passMu.Lock()
+	mappings := e.listMappings
+	e.passMu.Unlock()
+	for _, target := range mappings {
+		if name, _ := SplitTarget(target); name != "" {
+			continue
+		}
+		if _, ok := watched[target]; !ok {
+			return e.pollInterval
+		}
+	}
+	return e.safetyPoll
+}
+
+// corruptionError returns the first error of a pass, overall or per list,
+// that stems from a corrupted state DB.
+func corruptionError(stats Stats, err error) error {
+	if state.IsCorrupt(err) {
+		return err
+	}
+	for _, lerr := range stats.ListErrors {
+		if state.IsCorrupt(lerr) {
+			return lerr
 		}
 	}
+	return nil
 }
@@ -0,0 +1,363 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// waitFor polls cond until it holds or a second of real time has passed.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestEngine_PollsOnClockTicks(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	rem := newMockReminders()
+	r := NewReconciler(rem, NewRegistry(newMockHA()), newMockStore(), testLogger, WithClock(clk))
+	e := NewEngine(r, nil, testMappings, 30*time.Second, testLogger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- e.Run(ctx) }()
+
+	// Initial pass runs immediately, without any tick.
+	waitFor(t, "initial pass", func() bool { return rem.fetchCount() == 1 })
+
+	clk.BlockUntil(1)
+	clk.Advance(30 * time.Second)
+	waitFor(t, "ticked pass", func() bool { return rem.fetchCount() == 2 })
+
+	clk.Advance(30 * time.Second)
+	waitFor(t, "second ticked pass", func() bool { return rem.fetchCount() == 3 })
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+}
+
+// fakeConn is an HAConnector whose subscription reports nothing; tests
+// fire restarts through restart.
+type fakeConn struct {
+	mu      sync.Mutex
+	restart func()
+}
+
+func (c *fakeConn) Connect(context.Context) error { return nil }
+func (c *fakeConn) Close() error                  { return nil }
+
+func (c *fakeConn) SubscribeChanges(ctx context.Context, _ []string, _ func(string)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (c *fakeConn) OnRestart(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.restart = fn
+}
+
+func (c *fakeConn) restarted() bool {
+	c.mu.Lock()
+	fn := c.restart
+	c.mu.Unlock()
+	if fn == nil {
+		return false
+	}
+	fn()
+	return true
+}
+
+func TestEngine_FullPassOnHARestart(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	rem := newMockReminders()
+	r := NewReconciler(rem, NewRegistry(newMockHA()), newMockStore(), testLogger, WithClock(clk))
+	conn := &fakeConn{}
+	e := NewEngine(r, conn, testMappings, time.Hour, testLogger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- e.Run(ctx) }()
+	waitFor(t, "initial pass", func() bool { return rem.fetchCount() == 1 })
+
+	// No tick is due: only the restart runs the pass.
+	if !conn.restarted() {
+		t.Fatal("engine did not register for restarts")
+	}
+	waitFor(t, "pass after restart", func() bool { return rem.fetchCount() == 2 })
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestEngine_SyncNowWhilePaused(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	rem := newMockReminders()
+	r := NewReconciler(rem, NewRegistry(newMockHA()), newMockStore(), testLogger, WithClock(clk))
+	e := NewEngine(r, nil, testMappings, 30*time.Second, testLogger)
+
+	e.Pause()
+	if _, err := e.SyncNow(context.Background()); err != nil {
+		t.Fatalf("SyncNow() error = %v", err)
+	}
+	st := e.Status()
+	if !st.Paused || st.Passes != 1 || len(st.History) != 1 || !st.LastPassAt.Equal(clk.Now()) || rem.fetchCount() != 1 {
+		t.Errorf("Status() = %+v, fetches = %d; want one pass while paused", st, rem.fetchCount())
+	}
+
+	e.Resume()
+	if e.Status().Paused {
+		t.Error("Status().Paused = true after Resume")
+	}
+}
+
+func TestEngine_SyncSelected(t *testing.T) {
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	rem := newMockReminders(
+		newItem("r1", "Milk", "Shopping", model.PriorityNone, false, now),
+		newItem("r2", "Report", "Work", model.PriorityNone, false, now),
+	)
+	ha := newMockHA()
+	mappings := map[string]string{"Shopping": "todo.shopping", "Work": "todo.work"}
+	r := NewReconciler(rem, NewRegistry(ha), newMockStore(), testLogger)
+	e := NewEngine(r, nil, mappings, 30*time.Second, testLogger)
+
+	e.Pause()
+	stats, err := e.SyncSelected(context.Background(), "", "todo.work")
+	if err != nil {
+		t.Fatalf("SyncSelected() error = %v", err)
+	}
+	if stats.Created != 1 || len(ha.getItems("todo.work")) != 1 || len(ha.getItems("todo.shopping")) != 0 {
+		t.Errorf("stats = %+v, work = %v, shopping = %v; want only Work synced",
+			stats, ha.getItems("todo.work"), ha.getItems("todo.shopping"))
+	}
+	if st := e.Status(); st.Passes != 0 {
+		t.Errorf("Status().Passes = %d, want a selected pass not counted as full", st.Passes)
+	}
+
+	if _, err := e.SyncSelected(context.Background(), "Work", "todo.shopping"); err == nil {
+		t.Error("SyncSelected() with no matching mapping = nil, want an error")
+	}
+}
+
+// corruptReminders fails every fetch as if the state DB were corrupted.
+type corruptReminders struct{ *mockReminders }
+
+func (corruptReminders) Fetch(context.Context, []string) ([]*model.Item, error) {
+	return nil, fmt.Errorf("reading rows: %w", state.ErrCorrupt)
+}
+
+func TestEngine_StopsOnCorruptState(t *testing.T) {
+	r := NewReconciler(corruptReminders{newMockReminders()}, NewRegistry(newMockHA()), newMockStore(), testLogger)
+	e := NewEngine(r, nil, testMappings, 30*time.Second, testLogger)
+
+	done := make(chan error, 1)
+	go func() { done <- e.Run(context.Background()) }()
+
+	select {
+	case err := <-done:
+		if !state.IsCorrupt(err) {
+			t.Errorf("Run() error = %v, want corruption error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run() kept running on a corrupted state DB")
+	}
+}
+
+func TestEngine_BacksUpBeforeEachPass(t *testing.T) {
+	rem := newMockReminders()
+	r := NewReconciler(rem, NewRegistry(newMockHA()), newMockStore(), testLogger)
+	var backups, fetchesAtBackup int
+	e := NewEngine(r, nil, testMappings, 30*time.Second, testLogger, WithPassBackup(func(context.Context) error {
+		backups++
+		fetchesAtBackup = rem.fetchCount()
+		return errors.New("disk full")
+	}))
+
+	for range 2 {
+		if _, err := e.RunOnce(context.Background()); err != nil {
+			t.Fatalf("RunOnce() error = %v, want a failed backup not to fail the pass", err)
+		}
+	}
+	if backups != 2 || fetchesAtBackup != 1 {
+		t.Errorf("backups = %d, fetches before the last = %d; want one backup before each pass", backups, fetchesAtBackup)
+	}
+}
+
+func TestEngine_RecordsSyncRuns(t *testing.T) {
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	store := newMockStore()
+	rem := newMockReminders(newItem("r1", "Milk", "Shopping", model.PriorityNone, false, now))
+	r := NewReconciler(rem, NewRegistry(newMockHA()), store, testLogger, WithClock(clock.NewFake(now)))
+	e := NewEngine(r, nil, testMappings, 30*time.Second, testLogger)
+	if _, err := e.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+
+	failing := NewReconciler(corruptReminders{newMockReminders()}, NewRegistry(newMockHA()), store, testLogger)
+	if _, err := NewEngine(failing, nil, testMappings, 30*time.Second, testLogger).RunOnce(context.Background()); err == nil {
+		t.Fatal("RunOnce() = nil error with Reminders failing")
+	}
+
+	runs := store.syncRuns()
+	if len(runs) != 2 {
+		t.Fatalf("recorded %d runs, want 2", len(runs))
+	}
+	if ok := runs[0]; ok.Kind != runFull || !ok.StartedAt.Equal(now) || ok.Created != 1 || ok.Error != "" {
+		t.Errorf("first run = %+v, want a successful full pass creating 1 item", ok)
+	}
+	if runs[1].Error == "" {
+		t.Errorf("second run = %+v, want its error recorded", runs[1])
+	}
+}
+
+// chanWatcher reports a Reminders change for every value sent on it.
+type chanWatcher chan struct{}
+
+func (w chanWatcher) WatchChanges(ctx context.Context, changed func()) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w:
+			changed()
+		}
+	}
+}
+
+// listFetchCounter counts fetches per target list.
+type listFetchCounter struct {
+	*mockHA
+	mu      sync.Mutex
+	fetches map[string]int
+}
+
+func (c *listFetchCounter) Fetch(ctx context.Context, lists []string) ([]*model.Item, error) {
+	c.mu.Lock()
+	for _, l := range lists {
+		c.fetches[l]++
+	}
+	c.mu.Unlock()
+	return c.mockHA.Fetch(ctx, lists)
+}
+
+func (c *listFetchCounter) count(list string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.fetches[list]
+}
+
+func TestEngine_ReconcilesChangedRemindersLists(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	rem := newMockReminders()
+	ha := &listFetchCounter{mockHA: newMockHA(), fetches: make(map[string]int)}
+	mappings := map[string]string{"Shopping": "todo.shopping", "Errands": "todo.errands"}
+	r := NewReconciler(rem, NewRegistry(ha), newMockStore(), testLogger, WithClock(clk))
+	w := make(chanWatcher)
+	e := NewEngine(r, &fakeConn{}, mappings, 30*time.Second, testLogger, WithRemindersWatcher(w, 10*time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- e.Run(ctx) }()
+	waitFor(t, "initial pass", func() bool { return rem.fetchCount() == 1 })
+
+	if _, err := rem.Create(ctx, "Shopping", &model.Item{Title: "Bread"}); err != nil {
+		t.Fatal(err)
+	}
+	w <- struct{}{}
+	waitFor(t, "Bread in HA", func() bool { return len(ha.getItems("todo.shopping")) == 1 })
+	if n := ha.count("todo.errands"); n != 1 {
+		t.Errorf("Errands fetched %d times, want only by the initial pass", n)
+	}
+
+	// Polling is only a safety net now.
+	clk.BlockUntil(1)
+	clk.Advance(30 * time.Second)
+	time.Sleep(10 * time.Millisecond)
+	if n := rem.fetchCount(); n != 2 {
+		t.Errorf("Reminders fetched %d times, want no pass on the old poll interval", n)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+}
+
+// failingConn is an HAConnector that cannot connect.
+type failingConn struct{ fakeConn }
+
+func (c *failingConn) Connect(context.Context) error { return errors.New("connection refused") }
+
+func TestEngine_KeepsPollIntervalWithoutWebSocket(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	rem := newMockReminders()
+	r := NewReconciler(rem, NewRegistry(newMockHA()), newMockStore(), testLogger, WithClock(clk))
+	e := NewEngine(r, &failingConn{}, testMappings, 30*time.Second, testLogger,
+		WithRemindersWatcher(make(chanWatcher), 10*time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- e.Run(ctx) }()
+	waitFor(t, "initial pass", func() bool { return rem.fetchCount() == 1 })
+
+	// HA changes are not pushed, so polling has to pick them up.
+	clk.BlockUntil(1)
+	clk.Advance(30 * time.Second)
+	waitFor(t, "pass on the poll interval", func() bool { return rem.fetchCount() == 2 })
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestEngine_PollsDiscoveredListsOnPollInterval(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	rem := newMockReminders()
+	r := NewReconciler(rem, NewRegistry(newMockHA()), newMockStore(), testLogger, WithClock(clk))
+	e := NewEngine(r, &fakeConn{}, testMappings, 30*time.Second, testLogger,
+		WithRemindersWatcher(make(chanWatcher), time.Hour),
+		WithListDiscovery(staticLists{"Shopping", "Work"}, nil, &fakeProvisioner{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- e.Run(ctx) }()
+	waitFor(t, "initial pass", func() bool { return rem.fetchCount() == 1 })
+
+	// The initial pass mapped Work, which the subscription does not cover.
+	// The ticker is replaced after the pass, so keep advancing, but stay
+	// well short of the safety interval.
+	for range 10 {
+		clk.Advance(30 * time.Second)
+		time.Sleep(5 * time.Millisecond)
+		if rem.fetchCount() > 1 {
+			break
+		}
+	}
+	if n := rem.fetchCount(); n < 2 {
+		t.Errorf("Reminders fetched %d times, want a pass on the poll interval", n)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,38 @@
+package sync
+
+import (
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// WithExplain logs, for every tracked item of every pass, the hashes and
+// modification times of both sides, what the state DB recorded at the last
+// sync, and the action chosen with the reason for it.
+func WithExplain() ReconcilerOption {
+	return func(r *Reconciler) { r.explain = true }
+}
+
+// explainItem logs the decision act about si, made for the reason why, with
+// everything it was based on. remItem and haItem are nil for a side the item
+// is gone from.
+func (r *Reconciler) explainItem(listName string, si *state.Item, remItem, haItem *model.Item, act action, why string) {
+	args := []any{
+		"list", listName,
+		"title", si.Title,
+		"reminders_uid", si.RemindersUID,
+		"ha_uid", si.HAUID,
+		"last_sync_hash", si.LastSyncHash,
+		"last_synced_at", si.LastSyncedAt,
+	}
+	if remItem != nil {
+		args = append(args, "reminders_hash", remItem.ContentHash(), "reminders_modified", remItem.ModifiedAt)
+	}
+	if haItem != nil {
+		args = append(args, "ha_hash", haItem.ContentHash(), "ha_modified", haItem.ModifiedAt)
+	}
+	if si.Pin != "" {
+		args = append(args, "pin", si.Pin)
+	}
+	args = append(args, "action", act.String(), "reason", why)
+	r.log.Info("explain", args...)
+}
@@ -0,0 +1,107 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// ForceItem overwrites one side's version of every synced item of listName
+// titled title, as compared by the bootstrap, with the other side's, whatever
+// their hashes and modification times say, and records the result as synced.
+// dir names the side written: [ToHA] pushes the Reminders version to target,
+// [ToReminders] pulls the target's version into Reminders. An item missing on
+// the written side is created there again. It returns how many items were
+// forced, failing when no synced item has the title.
+func (r *Reconciler) ForceItem(ctx context.Context, listName, target, title string, dir Direction) (int, error) {
+	defer r.lockLists([]string{listName})()
+
+	tgt, err := r.resolveTarget(target, listName)
+	if err != nil {
+		return 0, err
+	}
+	remItems, err := r.rem.Fetch(ctx, []string{listName})
+	if err != nil {
+		return 0, fmt.Errorf("fetching reminders: %w", err)
+	}
+	haItems, err := tgt.backend.Fetch(ctx, []string{tgt.list})
+	if err != nil {
+		return 0, fmt.Errorf("fetching HA items for %s: %w", tgt.list, err)
+	}
+	stateItems, err := r.store.GetAllItemsForList(ctx, listName)
+	if err != nil {
+		return 0, fmt.Errorf("fetching state items for %q: %w", listName, err)
+	}
+	remByUID, haByUID := indexByUID(remItems), indexByUID(haItems)
+	drops := r.quirks[target].Drops | unsupportedFields(tgt.backend, tgt.list)
+
+	key := titleKey(title)
+	forced := 0
+	for _, si := range stateItems {
+		rem, ha := remByUID[si.RemindersUID], haByUID[si.HAUID]
+		if titleKey(si.Title) != key && (rem == nil || titleKey(rem.Title) != key) && (ha == nil || titleKey(ha.Title) != key) {
+			continue
+		}
+		if ha != nil {
+			ha.ListName = listName
+			if rem != nil {
+				copyFields(ha, rem, drops)
+			}
+		}
+		if err := r.force(ctx, si, rem, ha, tgt, dir); err != nil {
+			return forced, err
+		}
+		forced++
+		r.log.Info("item forced", "list", listName, "title", si.Title, "direction", dir)
+	}
+	if forced == 0 {
+		return 0, fmt.Errorf("no synced item titled %q in list %q", title, listName)
+	}
+	return forced, nil
+}
+
+// force writes rem to the target, or ha to Reminders, as dir says, and
+// records the written version as the synced content of si.
+func (r *Reconciler) force(ctx context.Context, si *state.Item, rem, ha *model.Item, tgt target, dir Direction) error {
+	src, dst, srcName := rem, ha, "Reminders"
+	if dir == ToReminders {
+		if ha != nil {
+			ha = withRawPriority(ha, rem, si.Base)
+		}
+		src, dst, srcName = ha, rem, "Home Assistant"
+	}
+	if src == nil {
+		return fmt.Errorf("%q is not in %s, so there is no version to copy", si.Title, srcName)
+	}
+	if dst == nil {
+		act := actionRestoreHA
+		if dir == ToReminders {
+			act = actionRestoreRem
+		}
+		return r.execute(ctx, plannedOp{act: act, si: si, rem: rem, ha: ha}, tgt)
+	}
+
+	fields := model.ChangedFields(dst, src)
+	if dir == ToHA {
+		if fields != 0 {
+			if err := tgt.backend.Update(ctx, tgt.list, ha, rem, fields); err != nil {
+				return fmt.Errorf("updating %q in HA: %w", rem.Title, err)
+			}
+		}
+		si.RemindersModified = rem.ModifiedAt
+	} else {
+		if fields != 0 {
+			if err := r.rem.Update(ctx, si.ListName, rem, ha, fields); err != nil {
+				return fmt.Errorf("updating %q in Reminders: %w", ha.Title, err)
+			}
+		}
+		si.HAModified = ha.ModifiedAt
+	}
+	si.Title = src.Title
+	si.LastSyncHash = src.ContentHash()
+	si.LastSyncedAt = r.clock.Now().UTC()
+	si.Base = baseOf(src)
+	return r.store.UpsertItem(ctx, si)
+}
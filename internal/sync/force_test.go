@@ -0,0 +1,53 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+func TestReconciler_ForceItem(t *testing.T) {
+	at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	synced := model.Item{Title: "Bread"}
+	rem := newMockReminders(
+		newItem("rem-bread", "Bread", "Shopping", model.PriorityHigh, false, at.Add(time.Hour)),
+		newItem("rem-milk", "Milk", "Shopping", model.PriorityNone, false, at),
+	)
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-bread", Title: "Bread", Priority: model.PriorityLow, ModifiedAt: at})
+	store := newMockStore()
+	store.seed(
+		&state.Item{RemindersUID: "rem-bread", HAUID: "ha-bread", ListName: "Shopping", Title: "Bread",
+			LastSyncHash: synced.ContentHash(), Base: baseOf(&synced)},
+		&state.Item{RemindersUID: "rem-milk", HAUID: "ha-milk", ListName: "Shopping", Title: "Milk"},
+	)
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+	ctx := context.Background()
+
+	// Reminders' version is newer, but the pull takes HA's regardless.
+	if n, err := r.ForceItem(ctx, "Shopping", "todo.shopping", "bread", ToReminders); err != nil || n != 1 {
+		t.Fatalf("ForceItem(Bread, to Reminders) = %d, %v", n, err)
+	}
+	if got := rem.get("rem-bread"); got.Priority != model.PriorityLow {
+		t.Errorf("Reminders priority = %v, want HA's low", got.Priority)
+	}
+	si, _ := store.GetItemByRemindersUID(ctx, "rem-bread")
+	if si.LastSyncHash != rem.get("rem-bread").ContentHash() {
+		t.Error("state row not recorded as synced after the pull")
+	}
+
+	// Milk is gone from HA; pushing creates it there again.
+	if _, err := r.ForceItem(ctx, "Shopping", "todo.shopping", "Milk", ToHA); err != nil {
+		t.Fatalf("ForceItem(Milk, to HA) error = %v", err)
+	}
+	if items := ha.getItems("todo.shopping"); len(items) != 2 {
+		t.Errorf("HA items = %v, want Milk created again", items)
+	}
+
+	if _, err := r.ForceItem(ctx, "Shopping", "todo.shopping", "Cheese", ToHA); err == nil {
+		t.Error("ForceItem(Cheese) = nil, want an error for an unknown title")
+	}
+}
@@ -0,0 +1,66 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Default limits of [WithDeletionGuard].
+const (
+	DefaultMaxDeletes       = 25
+	DefaultMaxDeletePercent = 50
+
+	// guardMinDeletes is the number of deletions a pass may always make,
+	// so emptying a short list is not mistaken for an outage.
+	guardMinDeletes = 5
+)
+
+// ErrTooManyDeletes is returned for a list whose pass would delete more
+// items than the deletion guard allows. Nothing of that list is applied.
+var ErrTooManyDeletes = errors.New("pass would delete too many items")
+
+// deletionGuard holds the limits of [WithDeletionGuard].
+type deletionGuard struct {
+	maxItems   int
+	maxPercent int
+}
+
+// WithDeletionGuard aborts the pass of a list that would delete more than
+// maxItems items, or more than maxPercent percent of the items tracked for
+// it, on both sides together. An empty fetch caused by an outage or revoked
+// Reminders access then does not wipe the other side. Zero limits select
+// [DefaultMaxDeletes] and [DefaultMaxDeletePercent]; a pass may always
+// delete a handful of items.
+func WithDeletionGuard(maxItems, maxPercent int) ReconcilerOption {
+	return func(r *Reconciler) {
+		if maxItems == 0 {
+			maxItems = DefaultMaxDeletes
+		}
+		if maxPercent == 0 {
+			maxPercent = DefaultMaxDeletePercent
+		}
+		r.guard = &deletionGuard{maxItems: maxItems, maxPercent: maxPercent}
+	}
+}
+
+// check returns an error wrapping [ErrTooManyDeletes] if ops delete more of
+// a list with tracked state rows than the guard allows.
+func (g *deletionGuard) check(listName string, ops []plannedOp, tracked int) error {
+	if g == nil {
+		return nil
+	}
+	deletes := 0
+	for _, op := range ops {
+		if op.act == actionDeleteFromHA || op.act == actionDeleteFromRem {
+			deletes++
+		}
+	}
+	if deletes <= guardMinDeletes {
+		return nil
+	}
+	if deletes > g.maxItems || deletes*100 > g.maxPercent*tracked {
+		return fmt.Errorf("%w: %d of %d tracked items in %q; run 'reminderrelay sync-once --force' if this is intended",
+			ErrTooManyDeletes, deletes, tracked, listName)
+	}
+	return nil
+}
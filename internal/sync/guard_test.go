@@ -0,0 +1,94 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// linkedList returns n linked pairs in Shopping, with the HA side holding
+// only the first haKept of them.
+func linkedList(n, haKept int) (*mockReminders, *mockHA, *mockStore) {
+	at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	rem := newMockReminders()
+	ha := newMockHA()
+	store := newMockStore()
+	for i := range n {
+		title := fmt.Sprintf("Item %d", i)
+		it := newItem(fmt.Sprintf("rem-%d", i), title, "Shopping", model.PriorityNone, false, at)
+		rem.items[it.UID] = it
+		if i < haKept {
+			ha.addItems("todo.shopping", model.Item{UID: fmt.Sprintf("ha-%d", i), Title: title, ModifiedAt: at})
+		}
+		store.seed(&state.Item{
+			RemindersUID: it.UID, HAUID: fmt.Sprintf("ha-%d", i), ListName: "Shopping", Title: title,
+			LastSyncHash: it.ContentHash(), RemindersModified: at, HAModified: at,
+		})
+	}
+	return rem, ha, store
+}
+
+func TestReconciler_DeletionGuardHoldsBackMassDelete(t *testing.T) {
+	// HA returns an empty list for a list of 30 linked items.
+	rem, ha, store := linkedList(30, 0)
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger, WithDeletionGuard(0, 0))
+
+	stats, err := r.Run(context.Background(), testMappings)
+	if !errors.Is(err, ErrTooManyDeletes) {
+		t.Fatalf("Run() error = %v, want ErrTooManyDeletes", err)
+	}
+	if !errors.Is(stats.ListErrors["Shopping"], ErrTooManyDeletes) {
+		t.Errorf("ListErrors = %v, want Shopping held back", stats.ListErrors)
+	}
+	if rem.count() != 30 || len(store.items) != 30 {
+		t.Errorf("Reminders has %d items and state %d rows, want all 30 kept", rem.count(), len(store.items))
+	}
+}
+
+func TestReconciler_DeletionGuardLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		n, haKept  int
+		maxItems   int
+		maxPercent int
+		wantHeld   bool
+	}{
+		{"a few deletes are always allowed", 6, 1, 0, 0, false},
+		{"within both limits", 20, 12, 0, 0, false},
+		{"above the percentage", 20, 8, 0, 0, true},
+		{"above the item count", 100, 70, 25, 90, true},
+		{"limits raised", 20, 0, 50, 100, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rem, ha, store := linkedList(tt.n, tt.haKept)
+			r := NewReconciler(rem, NewRegistry(ha), store, testLogger, WithDeletionGuard(tt.maxItems, tt.maxPercent))
+
+			stats, err := r.Run(context.Background(), testMappings)
+			if held := errors.Is(err, ErrTooManyDeletes); held != tt.wantHeld {
+				t.Fatalf("Run() error = %v, want held back %v", err, tt.wantHeld)
+			}
+			if !tt.wantHeld && stats.Deleted != tt.n-tt.haKept {
+				t.Errorf("Deleted = %d, want %d", stats.Deleted, tt.n-tt.haKept)
+			}
+		})
+	}
+}
+
+func TestProblemTracker_DeletionGuardIsImmediate(t *testing.T) {
+	n := &mockNotifier{}
+	tr := newProblemTracker(n, 3, "", testLogger)
+
+	err := fmt.Errorf("%w: 30 of 30 tracked items", ErrTooManyDeletes)
+	tr.observe(context.Background(), []string{"Shopping"}, Stats{ListErrors: map[string]error{"Shopping": err}}, err)
+
+	if len(n.created) != 1 || !strings.Contains(n.created[0], "too many items") {
+		t.Errorf("created = %v, want immediate deletion guard notification", n.created)
+	}
+}
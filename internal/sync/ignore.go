@@ -0,0 +1,121 @@
+package sync
+
+import (
+	"context"
+	"strings"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// IgnoreMarkers opt single items out of the sync. A marker starting with "#",
+// such as "#nosync", is a tag: it matches items whose title or description
+// has it as a word of its own. Any other marker, such as "!", matches items
+// whose title starts with it.
+type IgnoreMarkers []string
+
+// Match reports whether item carries one of the markers.
+func (m IgnoreMarkers) Match(item *model.Item) bool {
+	for _, marker := range m {
+		if strings.HasPrefix(marker, "#") {
+			if hasWord(item.Title, marker) || hasWord(item.Description, marker) {
+				return true
+			}
+		} else if strings.HasPrefix(item.Title, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasWord reports whether word is one of the whitespace-separated words of s.
+func hasWord(s, word string) bool {
+	for _, w := range strings.Fields(s) {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// WithIgnoreMarkers leaves items carrying one of markers out of the sync, on
+// either side. An item marked after it was synced loses its state row; its
+// counterpart on the other side takes the marked title and description once,
+// so that it is left alone too rather than synced back as a new item.
+// Neither is deleted.
+func WithIgnoreMarkers(markers IgnoreMarkers) ReconcilerOption {
+	return func(r *Reconciler) { r.ignore = markers }
+}
+
+// WithBootstrapIgnoreMarkers leaves items carrying one of markers out of the
+// bootstrap, so they are neither linked nor pushed to the other side.
+func WithBootstrapIgnoreMarkers(markers IgnoreMarkers) BootstrapOption {
+	return func(b *Bootstrap) { b.ignore = markers }
+}
+
+// skipIgnored returns haItems and remByUID without the marked items, and unlinks tracked items that have
+// been marked on either side since their last sync. remByUID is shared with
+// the other lists of the pass and left unchanged.
+func (r *Reconciler) skipIgnored(ctx context.Context, listName string, tgt target, haItems []*model.Item, remByUID map[string]*model.Item) ([]*model.Item, map[string]*model.Item, error) {
+	if len(r.ignore) == 0 {
+		return haItems, remByUID, nil
+	}
+	stateItems, err := r.store.GetAllItemsForList(ctx, listName)
+	if err != nil {
+		return nil, nil, err
+	}
+	haByUID := indexByUID(haItems)
+	skipRem := make(map[string]bool)
+	skipHA := make(map[string]bool)
+	for _, si := range stateItems {
+		rem, ha := remByUID[si.RemindersUID], haByUID[si.HAUID]
+		remMarked := rem != nil && r.ignore.Match(rem)
+		haMarked := ha != nil && r.ignore.Match(ha)
+		if !remMarked && !haMarked {
+			continue
+		}
+		skipRem[si.RemindersUID], skipHA[si.HAUID] = true, true
+		if r.readOnly || r.inShadow(listName) {
+			r.log.Info("read-only: would stop syncing marked item", "list", listName, "title", si.Title)
+			continue
+		}
+		switch {
+		case remMarked && ha != nil && !haMarked:
+			err = tgt.backend.Update(ctx, tgt.list, ha, marked(ha, rem), model.FieldTitle|model.FieldDescription)
+		case haMarked && rem != nil && !remMarked:
+			err = r.rem.Update(ctx, listName, rem, marked(rem, ha), model.FieldTitle|model.FieldDescription)
+		}
+		if err != nil {
+			// Keep the row, so the next pass tries again rather than
+			// syncing the counterpart back as a new item.
+			r.log.Error("marking counterpart of ignored item failed", "list", listName, "title", si.Title, "error", err)
+			err = nil
+			continue
+		}
+		if err := r.store.DeleteItem(ctx, si.ID); err != nil {
+			return nil, nil, err
+		}
+		r.log.Info("item marked as ignored, no longer synced", "list", listName, "title", si.Title)
+	}
+
+	kept := make([]*model.Item, 0, len(haItems))
+	for _, it := range haItems {
+		if !skipHA[it.UID] && !r.ignore.Match(it) {
+			kept = append(kept, it)
+		}
+	}
+	remKept := make(map[string]*model.Item, len(remByUID))
+	for uid, it := range remByUID {
+		if !skipRem[uid] && !r.ignore.Match(it) {
+			remKept[uid] = it
+		}
+	}
+	return kept, remKept, nil
+}
+
+// marked returns a copy of item with the title and description of from,
+// which carries an ignore marker.
+func marked(item, from *model.Item) *model.Item {
+	c := *item
+	c.Title, c.Description = from.Title, from.Description
+	return &c
+}
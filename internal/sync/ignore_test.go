@@ -0,0 +1,80 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+func TestIgnoreMarkers_Match(t *testing.T) {
+	m := IgnoreMarkers{"!", "#nosync"}
+	tests := []struct {
+		item model.Item
+		want bool
+	}{
+		{model.Item{Title: "!Private"}, true},
+		{model.Item{Title: "Buy milk #nosync"}, true},
+		{model.Item{Title: "Buy milk", Description: "#nosync for now"}, true},
+		{model.Item{Title: "Buy milk!"}, false},
+		{model.Item{Title: "Buy milk #nosyncing"}, false},
+	}
+	for _, tt := range tests {
+		if got := m.Match(&tt.item); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.item.Title, tt.item.Description, got, tt.want)
+		}
+	}
+}
+
+func TestReconcile_IgnoreMarkers(t *testing.T) {
+	at := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	rem := newMockReminders(
+		newItem("rem-1", "Milk", "Shopping", model.PriorityNone, false, at),
+		newItem("rem-2", "!Private", "Shopping", model.PriorityNone, false, at),
+		newItem("rem-3", "Gift", "Shopping", model.PriorityNone, false, at),
+	)
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-own", Title: "Bread #nosync", ModifiedAt: at})
+	store := newMockStore()
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger, WithIgnoreMarkers(IgnoreMarkers{"!", "#nosync"}))
+
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if got := len(ha.getItems("todo.shopping")); got != 3 {
+		t.Fatalf("HA has %d items, want Milk, Gift and its own marked item", got)
+	}
+	if got := rem.count(); got != 3 {
+		t.Fatalf("Reminders has %d items, want the marked HA item left out", got)
+	}
+
+	// Marking a synced item in Reminders unlinks it; HA's copy takes the
+	// marker and neither is deleted or synced back.
+	rem.get("rem-3").Title = "!Gift"
+	rem.get("rem-3").ModifiedAt = at.Add(time.Hour)
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	titles := map[string]bool{}
+	for _, it := range ha.getItems("todo.shopping") {
+		titles[it.Title] = true
+	}
+	if !titles["!Gift"] || len(titles) != 3 {
+		t.Errorf("HA titles = %v, want Gift marked and kept", titles)
+	}
+	if got := store.count(); got != 1 {
+		t.Errorf("state rows = %d, want only Milk's", got)
+	}
+
+	stats, err := r.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("third run: %v", err)
+	}
+	if stats.Created+stats.Updated+stats.Deleted != 0 {
+		t.Errorf("third run stats = %+v, want nothing to do", stats)
+	}
+	if got := rem.count(); got != 3 {
+		t.Errorf("Reminders has %d items, want 3", got)
+	}
+}
@@ -0,0 +1,132 @@
+package sync
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// Inspection is one item as Reminders, the target, and the state DB see it,
+// with what the next pass would do about it.
+type Inspection struct {
+	ListName  string
+	Target    string
+	Reminders *model.Item // nil when the item is not in Reminders
+	HA        *model.Item // nil when the item is not in the target list
+	State     *state.Item // nil when the item is not tracked
+
+	// Action is what the next pass would do, named as in the log, such as
+	// "update_ha"; "none" when the item is in sync.
+	Action string
+}
+
+// Inspect returns every item of the lists in listMappings whose title or UID
+// on either side or in the state DB is query, titles compared as by the
+// bootstrap. Results are sorted by list and title. Nothing is written to
+// either side or the state DB; items a target with unstable UIDs gave a new
+// UID show as deleted and untracked until a pass re-links them.
+func (r *Reconciler) Inspect(ctx context.Context, listMappings map[string]string, query string) ([]Inspection, error) {
+	listNames := slices.Sorted(maps.Keys(listMappings))
+	remItems, err := r.rem.Fetch(ctx, listNames)
+	if err != nil {
+		return nil, fmt.Errorf("fetching reminders: %w", err)
+	}
+	remByUID := make(map[string]*model.Item, len(remItems))
+	for _, item := range remItems {
+		remByUID[item.UID] = item
+	}
+
+	key := titleKey(query)
+	matches := func(uid, title string) bool {
+		return uid == query || titleKey(title) == key
+	}
+
+	var out []Inspection
+	for _, listName := range listNames {
+		targetName := listMappings[listName]
+		tgt, err := r.resolveTarget(targetName, listName)
+		if err != nil {
+			return nil, err
+		}
+		haItems, err := tgt.backend.Fetch(ctx, []string{tgt.list})
+		if err != nil {
+			return nil, fmt.Errorf("fetching HA items for %s: %w", tgt.list, err)
+		}
+		haByUID := make(map[string]*model.Item, len(haItems))
+		for _, item := range haItems {
+			item.ListName = listName
+			haByUID[item.UID] = item
+		}
+		stateItems, err := r.store.GetAllItemsForList(ctx, listName)
+		if err != nil {
+			return nil, fmt.Errorf("fetching state items for %q: %w", listName, err)
+		}
+		r.normalizeTitles(listName, stateItems, remByUID, haByUID)
+		drops := r.quirks[targetName].Drops | unsupportedFields(tgt.backend, tgt.list)
+		found := func(rem, ha *model.Item, si *state.Item, act action) {
+			out = append(out, Inspection{
+				ListName:  listName,
+				Target:    targetName,
+				Reminders: rem,
+				HA:        ha,
+				State:     si,
+				Action:    act.String(),
+			})
+		}
+
+		tracked := make(map[string]bool, 2*len(stateItems))
+		for _, si := range stateItems {
+			rem, ha := remByUID[si.RemindersUID], haByUID[si.HAUID]
+			tracked[si.RemindersUID], tracked[si.HAUID] = true, true
+			if !matches(si.RemindersUID, si.Title) && !matches(si.HAUID, si.Title) &&
+				(rem == nil || !matches(rem.UID, rem.Title)) && (ha == nil || !matches(ha.UID, ha.Title)) {
+				continue
+			}
+			if rem != nil && ha != nil {
+				copyFields(ha, rem, drops)
+				previewStamp(si, ha, r.clock.Now().UTC())
+			}
+			found(rem, ha, si, r.decide(si, rem, ha))
+		}
+		for _, rem := range remItems {
+			if rem.ListName == listName && !tracked[rem.UID] && matches(rem.UID, rem.Title) {
+				found(rem, nil, nil, actionCreateInHA)
+			}
+		}
+		for _, ha := range haItems {
+			if !tracked[ha.UID] && matches(ha.UID, ha.Title) {
+				found(nil, ha, nil, actionCreateInRem)
+			}
+		}
+	}
+
+	slices.SortStableFunc(out, func(a, b Inspection) int {
+		return cmp.Or(cmp.Compare(a.ListName, b.ListName), cmp.Compare(a.title(), b.title()))
+	})
+	return out, nil
+}
+
+// title returns the best available display title of the inspected item.
+func (in Inspection) title() string {
+	return plannedOp{si: in.State, rem: in.Reminders, ha: in.HA}.title()
+}
+
+// previewStamp sets the modification time of haItem as [Reconciler.stampHA]
+// would at now, without recording it.
+func previewStamp(si *state.Item, haItem *model.Item, now time.Time) {
+	if !haItem.ModifiedAt.IsZero() {
+		return
+	}
+	hash := haItem.ContentHash()
+	if hash == si.LastSyncHash || hash == si.HASeenHash {
+		haItem.ModifiedAt = si.HAModified
+		return
+	}
+	haItem.ModifiedAt = now
+}
@@ -0,0 +1,57 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+func TestReconciler_Inspect(t *testing.T) {
+	at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	synced := model.Item{Title: "Bread"}
+	rem := newMockReminders(
+		newItem("rem-bread", "Bread", "Shopping", model.PriorityHigh, false, at.Add(time.Hour)),
+		newItem("rem-milk", "Milk", "Shopping", model.PriorityNone, false, at),
+	)
+	ha := newMockHA()
+	ha.addItems("todo.shopping",
+		model.Item{UID: "ha-bread", Title: "Bread", ModifiedAt: at},
+		model.Item{UID: "ha-eggs", Title: "Eggs", ModifiedAt: at},
+	)
+	store := newMockStore()
+	store.seed(&state.Item{
+		RemindersUID: "rem-bread", HAUID: "ha-bread", ListName: "Shopping", Title: "Bread",
+		LastSyncHash: synced.ContentHash(), LastSyncedAt: at,
+	})
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+
+	tests := []struct {
+		query      string
+		wantAction string
+		wantState  bool
+	}{
+		{"bread ", "update_ha", true},
+		{"ha-bread", "update_ha", true},
+		{"Milk", "create_in_ha", false},
+		{"ha-eggs", "create_in_reminders", false},
+	}
+	for _, tt := range tests {
+		got, err := r.Inspect(context.Background(), testMappings, tt.query)
+		if err != nil {
+			t.Fatalf("Inspect(%q) error = %v", tt.query, err)
+		}
+		if len(got) != 1 || got[0].Action != tt.wantAction || (got[0].State != nil) != tt.wantState {
+			t.Errorf("Inspect(%q) = %+v, want one item with action %s", tt.query, got, tt.wantAction)
+		}
+	}
+
+	if got, _ := r.Inspect(context.Background(), testMappings, "Cheese"); len(got) != 0 {
+		t.Errorf("Inspect(Cheese) = %+v, want nothing", got)
+	}
+	if rem.count() != 2 || len(ha.getItems("todo.shopping")) != 2 || store.count() != 1 {
+		t.Error("Inspect changed items or state")
+	}
+}
@@ -0,0 +1,167 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// listName returns the Reminders list of the item op writes.
+func (op plannedOp) listName() string {
+	switch {
+	case op.si != nil:
+		return op.si.ListName
+	case op.rem != nil:
+		return op.rem.ListName
+	case op.ha != nil:
+		return op.ha.ListName
+	}
+	return ""
+}
+
+// executeIntent runs [Reconciler.execute] for op behind an intent in the
+// state DB: the intent is recorded before either side is written and
+// removed once the state DB reflects the write. A failed write leaves its
+// intent for the next pass to settle, as it may have reached a side before
+// failing.
+func (r *Reconciler) executeIntent(ctx context.Context, op plannedOp, tgt target) error {
+	key := op.outboxKey()
+	id, err := r.store.BeginIntent(ctx, state.Intent{
+		ListName:     op.listName(),
+		RemindersUID: key.remUID,
+		HAUID:        key.haUID,
+		Action:       op.act.String(),
+		Title:        op.title(),
+		StartedAt:    r.clock.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := r.execute(ctx, op, tgt); err != nil {
+		return err
+	}
+	if err := r.store.EndIntent(ctx, id); err != nil {
+		// The next pass finds the write recorded and drops the intent.
+		r.log.Warn("could not clear intent of a completed write", "title", op.title(), "error", err)
+	}
+	return nil
+}
+
+// settleIntents resolves the intents the last passes over listName left
+// behind, before the list is planned. A create interrupted after the item
+// was created but before its state row was written would otherwise look
+// like a new item on both sides and be copied back: such an item is linked
+// to its source instead, if it is the only unlinked item of its title.
+// Other interrupted writes need nothing, as planning compares both sides as
+// they are now.
+func (r *Reconciler) settleIntents(ctx context.Context, listName string, haItems []*model.Item, remByUID map[string]*model.Item) error {
+	intents, err := r.store.Intents(ctx, listName)
+	if err != nil || len(intents) == 0 {
+		return err
+	}
+	stateItems, err := r.store.GetAllItemsForList(ctx, listName)
+	if err != nil {
+		return fmt.Errorf("fetching state items for %q: %w", listName, err)
+	}
+
+	byRem := make(map[string]*state.Item, len(stateItems))
+	byHA := make(map[string]*state.Item, len(stateItems))
+	for _, si := range stateItems {
+		if si.RemindersUID != "" {
+			byRem[si.RemindersUID] = si
+		}
+		if si.HAUID != "" {
+			byHA[si.HAUID] = si
+		}
+	}
+	haByUID := make(map[string]*model.Item, len(haItems))
+	trackedHA := make(map[string]bool, len(haItems))
+	for _, it := range haItems {
+		haByUID[it.UID] = it
+		trackedHA[it.UID] = byHA[it.UID] != nil
+	}
+	var remItems []*model.Item
+	trackedRem := make(map[string]bool)
+	for _, it := range remByUID {
+		if it.ListName == listName {
+			remItems = append(remItems, it)
+			trackedRem[it.UID] = byRem[it.UID] != nil
+		}
+	}
+	unlinkedHA := unlinkedByTitle(haItems, trackedHA)
+	unlinkedRem := unlinkedByTitle(remItems, trackedRem)
+
+	now := r.clock.Now().UTC()
+	for _, in := range intents {
+		var linked *state.Item
+		switch in.Action {
+		case actionCreateInHA.String(), actionRestoreHA.String():
+			rem := remByUID[in.RemindersUID]
+			if rem == nil {
+				break
+			}
+			si := byRem[rem.UID]
+			if si != nil && haByUID[si.HAUID] != nil {
+				break // the write was recorded
+			}
+			ha := claimUnlinked(unlinkedHA, rem.Title)
+			if ha == nil {
+				break // the item was never created
+			}
+			if si == nil {
+				si = &state.Item{RemindersUID: rem.UID, ListName: listName}
+			}
+			si.HAUID = ha.UID
+			si.Title = rem.Title
+			si.LastSyncHash = rem.ContentHash()
+			si.RemindersModified = rem.ModifiedAt
+			si.LastSyncedAt = now
+			si.Base = baseOf(rem)
+			linked = si
+
+		case actionCreateInRem.String(), actionRestoreRem.String():
+			ha := haByUID[in.HAUID]
+			if ha == nil {
+				break
+			}
+			si := byHA[ha.UID]
+			if si != nil && remByUID[si.RemindersUID] != nil {
+				break // the write was recorded
+			}
+			rem := claimUnlinked(unlinkedRem, ha.Title)
+			if parsed, ok := r.shorthandOf(listName, ha); rem == nil && ok {
+				rem = claimUnlinked(unlinkedRem, parsed.Title)
+			}
+			if rem == nil {
+				break // the item was never created
+			}
+			if si == nil {
+				si = &state.Item{HAUID: ha.UID, ListName: listName}
+			} else if err := r.store.DeleteItem(ctx, si.ID); err != nil {
+				// The row is keyed by the Reminders UID; see actionRestoreRem.
+				return err
+			}
+			si.ID = 0
+			si.RemindersUID = rem.UID
+			si.Title = ha.Title
+			si.LastSyncHash = ha.ContentHash()
+			si.HAModified = ha.ModifiedAt
+			si.LastSyncedAt = now
+			si.Base = baseOf(ha)
+			linked = si
+		}
+
+		if linked != nil {
+			if err := r.store.UpsertItem(ctx, linked); err != nil {
+				return fmt.Errorf("linking %q after an interrupted write: %w", linked.Title, err)
+			}
+			r.log.Warn("linked item of an interrupted write", "list", listName, "title", linked.Title, "action", in.Action)
+		}
+		if err := r.store.EndIntent(ctx, in.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
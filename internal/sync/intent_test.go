@@ -0,0 +1,75 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// crashingStore is a mockStore whose item writes fail while crashed, like a
+// process killed between writing one side and the state DB.
+type crashingStore struct {
+	*mockStore
+	crashed bool
+}
+
+func (s *crashingStore) UpsertItem(ctx context.Context, item *state.Item) error {
+	if s.crashed {
+		return errors.New("killed")
+	}
+	return s.mockStore.UpsertItem(ctx, item)
+}
+
+func TestReconcile_InterruptedCreateIsLinked(t *testing.T) {
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	rem := newMockReminders(newItem("r1", "Milk", "Shopping", model.PriorityNone, false, now))
+	ha := newMockHA()
+	store := &crashingStore{mockStore: newMockStore(), crashed: true}
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger, WithClock(clock.NewFake(now)))
+
+	if _, err := r.Run(context.Background(), testMappings); err == nil {
+		t.Fatal("Run() = nil error with the state DB write failing")
+	}
+	if n := len(ha.getItems("todo.shopping")); n != 1 || len(store.intents) != 1 {
+		t.Fatalf("after the interrupted pass: %d HA items, %d intents; want 1, 1", n, len(store.intents))
+	}
+
+	store.crashed = false
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("Run() after restart: %v", err)
+	}
+	if n := len(ha.getItems("todo.shopping")); n != 1 {
+		t.Errorf("HA has %d items, want the interrupted create not repeated", n)
+	}
+	if n := rem.count(); n != 1 {
+		t.Errorf("Reminders has %d items, want the created HA item not copied back", n)
+	}
+	si, _ := store.GetItemByRemindersUID(context.Background(), "r1")
+	if si == nil || si.HAUID != ha.getItems("todo.shopping")[0].UID {
+		t.Errorf("state row = %+v, want Milk linked to its HA item", si)
+	}
+	if len(store.intents) != 0 {
+		t.Errorf("%d intents left, want none", len(store.intents))
+	}
+}
+
+func TestReconcile_CompletedWritesLeaveNoIntent(t *testing.T) {
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	rem := newMockReminders(newItem("r1", "Milk", "Shopping", model.PriorityNone, false, now))
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "h1", Title: "Eggs"})
+	store := newMockStore()
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger, WithClock(clock.NewFake(now)))
+
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if store.count() != 2 || len(store.intents) != 0 {
+		t.Errorf("%d state rows, %d intents; want 2, 0", store.count(), len(store.intents))
+	}
+}
@@ -1,7 +1,7 @@
 // Package sync implements the bidirectional reconciliation engine for
 // ReminderRelay. It compares Apple Reminders items and Home Assistant todo
 // items against the state database, detects creates, updates, deletes,
-// and conflicts, and dispatches mutations to the appropriate adapter.
+// and conflicts, and dispatches mutations to the appropriate [TaskBackend].
 //
 // The package contains two main components:
 //
@@ -12,29 +12,11 @@ package sync
 
 import (
 	"context"
+	"time"
 
-	"github.com/njoerd114/reminderrelay/internal/model"
 	"github.com/njoerd114/reminderrelay/internal/state"
 )
 
-// RemindersSource provides read/write access to Apple Reminders items.
-// Implemented by [reminders.Adapter].
-type RemindersSource interface {
-	FetchAll(ctx context.Context, listNames []string) ([]*model.Item, error)
-	Create(ctx context.Context, item *model.Item) (uid string, err error)
-	Update(ctx context.Context, uid string, item *model.Item) error
-	Delete(ctx context.Context, uid string) error
-}
-
-// HASource provides read/write access to Home Assistant todo items.
-// Implemented by [homeassistant.Adapter].
-type HASource interface {
-	GetItems(ctx context.Context, entityID string) ([]model.Item, error)
-	AddItem(ctx context.Context, entityID string, item *model.Item) error
-	UpdateItem(ctx context.Context, entityID, currentTitle string, item *model.Item) error
-	RemoveItem(ctx context.Context, entityID, title string) error
-}
-
 // StateStore provides access to the sync state database.
 // Implemented by [state.Store].
 type StateStore interface {
@@ -44,4 +26,14 @@ type StateStore interface {
 	UpsertItem(ctx context.Context, item *state.Item) error
 	DeleteItem(ctx context.Context, id int64) error
 	IsEmpty(ctx context.Context) (bool, error)
+	GetShadowList(ctx context.Context, listName string) (*state.ShadowList, error)
+	RecordShadowPass(ctx context.Context, listName string, creates, updates, deletes int, at time.Time) error
+	PromoteShadowList(ctx context.Context, listName string) error
+	QueueOutbound(ctx context.Context, o state.Outbound) error
+	QueuedOutbound(ctx context.Context, listName string) ([]*state.Outbound, error)
+	DeleteOutbound(ctx context.Context, id int64) error
+	BeginIntent(ctx context.Context, i state.Intent) (int64, error)
+	EndIntent(ctx context.Context, id int64) error
+	Intents(ctx context.Context, listName string) ([]*state.Intent, error)
+	RecordSyncRun(ctx context.Context, run state.SyncRun) error
 }
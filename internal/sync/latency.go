@@ -0,0 +1,204 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Bounds on the latency data kept in memory.
+const (
+	// maxLatencySamples is the number of recent samples per direction that
+	// percentiles are computed over.
+	maxLatencySamples = 500
+	// maxPendingChanges caps how many failed changes the reconciler
+	// remembers the first sighting of.
+	maxPendingChanges = 1000
+)
+
+// Direction is the side a change was propagated to.
+type Direction string
+
+// Propagation directions.
+const (
+	ToHA        Direction = "to_ha"
+	ToReminders Direction = "to_reminders"
+)
+
+// Latency is how long one change took to propagate: from the pass that first
+// observed it — triggered by a poll or a WebSocket event — until the write
+// to the other side completed. A change whose write failed is measured from
+// its first sighting once a later pass succeeds.
+type Latency struct {
+	Direction Direction
+	Duration  time.Duration
+}
+
+// LatencySummary describes the recent propagation latencies of one
+// direction.
+type LatencySummary struct {
+	// Samples is the number of recent changes the summary covers.
+	Samples int
+	P50     time.Duration
+	P90     time.Duration
+	P99     time.Duration
+	Max     time.Duration
+	// WithinObjective is the share of samples at or below the objective
+	// set with [WithLatencyObjective], from 0 to 1. Zero without an
+	// objective.
+	WithinObjective float64
+}
+
+// WithLatencyObjective sets the propagation latency users expect, reported
+// in [LatencySummary.WithinObjective] as the share of changes that met it.
+func WithLatencyObjective(d time.Duration) EngineOption {
+	return func(e *Engine) { e.latencyObjective = d }
+}
+
+// directions returns the sides op writes to.
+func (op plannedOp) directions() []Direction {
+	switch op.act {
+	case actionCreateInHA, actionUpdateHA, actionRestoreHA:
+		return []Direction{ToHA}
+	case actionCreateInRem, actionUpdateRem, actionRestoreRem:
+		return []Direction{ToReminders}
+	case actionDeleteFromHA:
+		if op.ha != nil {
+			return []Direction{ToHA}
+		}
+	case actionDeleteFromRem:
+		if op.rem != nil {
+			return []Direction{ToReminders}
+		}
+	case actionMerge:
+		var dirs []Direction
+		hash := op.merged.ContentHash()
+		if hash != op.ha.ContentHash() {
+			dirs = append(dirs, ToHA)
+		}
+		if hash != op.rem.ContentHash() {
+			dirs = append(dirs, ToReminders)
+		}
+		return dirs
+	}
+	return nil
+}
+
+// changeKey identifies the change op propagates in dir across passes.
+func (op plannedOp) changeKey(dir Direction) string {
+	switch {
+	case op.si != nil:
+		return fmt.Sprintf("%s|row:%d", dir, op.si.ID)
+	case op.rem != nil:
+		return fmt.Sprintf("%s|rem:%s", dir, op.rem.UID)
+	case op.ha != nil:
+		return fmt.Sprintf("%s|ha:%s", dir, op.ha.UID)
+	}
+	return ""
+}
+
+// propagated returns the latency of every write op made, measured from
+// when its change was first seen, and forgets the change.
+func (r *Reconciler) propagated(op plannedOp, seen time.Time) []Latency {
+	dirs := op.directions()
+	if len(dirs) == 0 {
+		return nil
+	}
+	done := r.clock.Now()
+
+	r.pendingMu.Lock()
+	defer r.pendingMu.Unlock()
+	out := make([]Latency, 0, len(dirs))
+	for _, dir := range dirs {
+		key := op.changeKey(dir)
+		first := seen
+		if t, ok := r.pending[key]; ok {
+			first = t
+			delete(r.pending, key)
+		}
+		out = append(out, Latency{Direction: dir, Duration: done.Sub(first)})
+	}
+	return out
+}
+
+// deferred remembers when the change of a failed op was first seen, so its
+// latency covers the retries.
+func (r *Reconciler) deferred(op plannedOp, seen time.Time) {
+	r.pendingMu.Lock()
+	defer r.pendingMu.Unlock()
+	if r.pending == nil {
+		r.pending = make(map[string]time.Time)
+	}
+	for _, dir := range op.directions() {
+		key := op.changeKey(dir)
+		if _, ok := r.pending[key]; ok || len(r.pending) >= maxPendingChanges {
+			continue
+		}
+		r.pending[key] = seen
+	}
+}
+
+// recordLatencies adds the latencies of a pass to the histogram metric and
+// to the window summarised by [Engine.Status].
+func (e *Engine) recordLatencies(ctx context.Context, latencies []Latency) {
+	if len(latencies) == 0 {
+		return
+	}
+	for _, l := range latencies {
+		attrs := []attribute.KeyValue{attribute.String(attrDirection, string(l.Direction))}
+		if e.instance != "" {
+			attrs = append(attrs, attribute.String(attrInstance, e.instance))
+		}
+		e.histLatency.Record(ctx, l.Duration.Seconds(), metric.WithAttributes(attrs...))
+	}
+
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+	if e.latencies == nil {
+		e.latencies = make(map[Direction][]time.Duration)
+	}
+	for _, l := range latencies {
+		e.latencies[l.Direction] = appendBounded(e.latencies[l.Direction], maxLatencySamples, l.Duration)
+	}
+}
+
+// latencySummaries summarises the recorded latency window. The caller must
+// hold statusMu.
+func (e *Engine) latencySummaries() map[Direction]LatencySummary {
+	if len(e.latencies) == 0 {
+		return nil
+	}
+	out := make(map[Direction]LatencySummary, len(e.latencies))
+	for dir, samples := range e.latencies {
+		out[dir] = summarize(samples, e.latencyObjective)
+	}
+	return out
+}
+
+// summarize computes the percentiles of samples using the nearest-rank
+// method.
+func summarize(samples []time.Duration, objective time.Duration) LatencySummary {
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+	rank := func(p float64) time.Duration {
+		i := int(math.Ceil(p*float64(len(sorted)))) - 1
+		return sorted[max(0, i)]
+	}
+	s := LatencySummary{
+		Samples: len(sorted),
+		P50:     rank(0.50),
+		P90:     rank(0.90),
+		P99:     rank(0.99),
+		Max:     sorted[len(sorted)-1],
+	}
+	if objective > 0 {
+		within, _ := slices.BinarySearch(sorted, objective+1)
+		s.WithinObjective = float64(within) / float64(len(sorted))
+	}
+	return s
+}
@@ -0,0 +1,68 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+func TestSummarize(t *testing.T) {
+	samples := make([]time.Duration, 0, 100)
+	for i := 100; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Second)
+	}
+
+	got := summarize(samples, 45*time.Second)
+	want := LatencySummary{
+		Samples:         100,
+		P50:             50 * time.Second,
+		P90:             90 * time.Second,
+		P99:             99 * time.Second,
+		Max:             100 * time.Second,
+		WithinObjective: 0.45,
+	}
+	if got != want {
+		t.Errorf("summarize = %+v, want %+v", got, want)
+	}
+}
+
+// flakyHA fails every Create while failing is set.
+type flakyHA struct {
+	*mockHA
+	failing bool
+}
+
+func (f *flakyHA) Create(ctx context.Context, entityID string, item *model.Item) (string, error) {
+	if f.failing {
+		return "", errors.New("HA unavailable")
+	}
+	return f.mockHA.Create(ctx, entityID, item)
+}
+
+func TestReconcile_LatencyCoversRetries(t *testing.T) {
+	at := time.Date(2026, 5, 4, 3, 2, 1, 0, time.UTC)
+	clk := clock.NewFake(at)
+	rem := newMockReminders(newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, at))
+	ha := &flakyHA{mockHA: newMockHA(), failing: true}
+	r := NewReconciler(rem, NewRegistry(ha), newMockStore(), testLogger, WithClock(clk))
+
+	stats, _ := r.Run(context.Background(), testMappings)
+	if len(stats.Latencies) != 0 {
+		t.Fatalf("failed pass Latencies = %v, want none", stats.Latencies)
+	}
+
+	clk.Advance(30 * time.Second)
+	ha.failing = false
+	stats, err := r.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Latency{{Direction: ToHA, Duration: 30 * time.Second}}
+	if len(stats.Latencies) != 1 || stats.Latencies[0] != want[0] {
+		t.Errorf("Latencies = %v, want %v", stats.Latencies, want)
+	}
+}
@@ -0,0 +1,92 @@
+package sync
+
+import "github.com/njoerd114/reminderrelay/internal/model"
+
+// mergeFields performs a three-way merge of rem and ha against base, the
+// content both sides agreed on at the last sync. A field changed on only one
+// side takes that side's value. A field changed on both sides to different
+// values is a conflict and takes the value of the side modified last; ties
+// favour Reminders, matching whole-item last-write-wins. The result is a copy
+// of rem with the merged content fields.
+func mergeFields(base, rem, ha *model.Item) (merged *model.Item, conflicted []string) {
+	remWins := !rem.ModifiedAt.Before(ha.ModifiedAt)
+	remChanged := model.ChangedFields(base, rem)
+	haChanged := model.ChangedFields(base, ha)
+	differ := model.ChangedFields(rem, ha)
+
+	m := *rem
+	for _, f := range []struct {
+		field model.Fields
+		name  string
+		take  func()
+	}{
+		{model.FieldTitle, "title", func() { m.Title = ha.Title }},
+		{model.FieldDescription, "description", func() { m.Description = ha.Description }},
+		{model.FieldDueDate, "due_date", func() { m.DueDate = ha.DueDate }},
+		{model.FieldPriority, "priority", func() { m.Priority = ha.Priority }},
+		{model.FieldCompleted, "completed", func() { m.Completed, m.CompletedAt = ha.Completed, ha.CompletedAt }},
+		{model.FieldAlert, "alert", func() { m.AlertAt = ha.AlertAt }},
+		{model.FieldURL, "url", func() { m.URL = ha.URL }},
+	} {
+		switch {
+		case !haChanged.Has(f.field):
+			// Keep Reminders' value, changed or not.
+		case !remChanged.Has(f.field):
+			f.take()
+		case differ.Has(f.field):
+			conflicted = append(conflicted, f.name)
+			if !remWins {
+				f.take()
+			}
+		}
+	}
+	return &m, conflicted
+}
+
+// baseOf returns a copy of item for use as a state row's merge base, so later
+// changes to item cannot alter the recorded base.
+func baseOf(item *model.Item) *model.Item {
+	b := *item
+	return &b
+}
+
+// withRawPriority returns item with the RawPriority of the first of prev
+// whose exact Reminders priority falls in item's priority level, so writing a
+// version from the target back to Reminders keeps, say, priority 2 instead of
+// the level's canonical 1. item is returned unchanged if none does.
+func withRawPriority(item *model.Item, prev ...*model.Item) *model.Item {
+	for _, p := range prev {
+		if p != nil && p.RawPriority != 0 && model.NormalizePriority(p.RawPriority) == item.Priority {
+			c := *item
+			c.RawPriority = p.RawPriority
+			return &c
+		}
+	}
+	return item
+}
+
+// copyFields sets the fields of dst named by fields to the values in src.
+func copyFields(dst, src *model.Item, fields model.Fields) {
+	if fields.Has(model.FieldTitle) {
+		dst.Title = src.Title
+	}
+	if fields.Has(model.FieldDescription) {
+		dst.Description = src.Description
+	}
+	if fields.Has(model.FieldDueDate) {
+		dst.DueDate = src.DueDate
+	}
+	if fields.Has(model.FieldPriority) {
+		dst.Priority = src.Priority
+	}
+	if fields.Has(model.FieldCompleted) {
+		dst.Completed = src.Completed
+		dst.CompletedAt = src.CompletedAt
+	}
+	if fields.Has(model.FieldAlert) {
+		dst.AlertAt = src.AlertAt
+	}
+	if fields.Has(model.FieldURL) {
+		dst.URL = src.URL
+	}
+}
@@ -0,0 +1,120 @@
+package sync
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+func TestMergeFields_DisjointEditsKeepBoth(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+
+	base := newItem("", "Buy milk", "Shopping", model.PriorityNone, false, older)
+	rem := newItem("rem-1", "Buy oat milk", "Shopping", model.PriorityNone, false, older.Add(2*time.Hour))
+	ha := newItem("ha-1", "Buy milk", "Shopping", model.PriorityHigh, false, older.Add(time.Hour))
+	ha.DueDate = &due
+
+	merged, conflicted := mergeFields(base, rem, ha)
+
+	if len(conflicted) != 0 {
+		t.Errorf("conflicted = %v, want none", conflicted)
+	}
+	if merged.Title != "Buy oat milk" {
+		t.Errorf("Title = %q, want Reminders' edit", merged.Title)
+	}
+	if merged.Priority != model.PriorityHigh || merged.DueDate == nil || !merged.DueDate.Equal(due) {
+		t.Errorf("Priority, DueDate = %v, %v, want HA's edits", merged.Priority, merged.DueDate)
+	}
+	if merged.UID != "rem-1" {
+		t.Errorf("UID = %q, want the Reminders UID", merged.UID)
+	}
+}
+
+func TestMergeFields_SameFieldFallsBackToLWW(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	base := newItem("", "Buy milk", "Shopping", model.PriorityNone, false, older)
+	rem := newItem("rem-1", "Buy oat milk", "Shopping", model.PriorityNone, true, older.Add(time.Hour))
+	ha := newItem("ha-1", "Buy skim milk", "Shopping", model.PriorityNone, false, older.Add(2*time.Hour))
+
+	merged, conflicted := mergeFields(base, rem, ha)
+
+	if !slices.Equal(conflicted, []string{"title"}) {
+		t.Errorf("conflicted = %v, want [title]", conflicted)
+	}
+	if merged.Title != "Buy skim milk" {
+		t.Errorf("Title = %q, want HA's newer edit", merged.Title)
+	}
+	if !merged.Completed {
+		t.Error("Completed = false, want Reminders' non-conflicting edit kept")
+	}
+}
+
+func TestMergeFields_IdenticalEditsDoNotConflict(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	base := newItem("", "Buy milk", "Shopping", model.PriorityNone, false, older)
+	rem := newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, true, older)
+	ha := newItem("ha-1", "Buy milk", "Shopping", model.PriorityNone, true, older)
+
+	merged, conflicted := mergeFields(base, rem, ha)
+	if len(conflicted) != 0 || !merged.Completed {
+		t.Errorf("merged.Completed, conflicted = %v, %v, want true, none", merged.Completed, conflicted)
+	}
+}
+
+func TestReconcile_Merge_DisjointEdits(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+
+	orig := newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, older)
+	store := newMockStore()
+	store.seed(&state.Item{
+		RemindersUID: "rem-1",
+		HAUID:        "ha-1",
+		ListName:     "Shopping",
+		Title:        "Buy milk",
+		LastSyncHash: orig.ContentHash(),
+		LastSyncedAt: older,
+		Base:         orig,
+	})
+
+	// Title edited in Reminders, due date edited in HA.
+	rem := newMockReminders(newItem("rem-1", "Buy oat milk", "Shopping", model.PriorityNone, false, older.Add(time.Hour)))
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{
+		UID:        "ha-1",
+		Title:      "Buy milk",
+		DueDate:    &due,
+		ModifiedAt: older.Add(2 * time.Hour),
+	})
+
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+	stats, err := r.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Updated != 1 || stats.Conflicts != 0 {
+		t.Errorf("Updated, Conflicts = %d, %d, want 1, 0", stats.Updated, stats.Conflicts)
+	}
+
+	for side, got := range map[string]*model.Item{"Reminders": rem.get("rem-1"), "HA": &ha.getItems("todo.shopping")[0]} {
+		if got.Title != "Buy oat milk" || got.DueDate == nil || !got.DueDate.Equal(due) {
+			t.Errorf("%s item = %q due %v, want both edits", side, got.Title, got.DueDate)
+		}
+	}
+
+	// The merged content is the new base: a second pass is a no-op.
+	stats, err = r.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("second pass: %v", err)
+	}
+	if stats.Updated != 0 {
+		t.Errorf("second pass Updated = %d, want 0", stats.Updated)
+	}
+}
@@ -0,0 +1,196 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// WithMirrors publishes Reminders lists to further targets besides the one
+// their list mapping names, keyed by Reminders list. The mapped target stays
+// authoritative: only its changes flow back into Reminders. A mirror is kept
+// a copy of the Reminders list, one way: edits made in a mirror are
+// overwritten and items deleted there are put back, while items added there
+// are left alone. stores returns the state store that keeps a mirror
+// target's rows apart from those of the list mappings.
+func WithMirrors(mirrors map[string][]string, stores func(target string) StateStore) ReconcilerOption {
+	return func(r *Reconciler) { r.mirrors, r.mirrorStores = mirrors, stores }
+}
+
+// syncList reconciles listName with its target and then, if that worked,
+// brings the list's mirrors in line.
+func (r *Reconciler) syncList(ctx context.Context, listName, targetName string, remByUID map[string]*model.Item, seen time.Time) (Stats, error) {
+	stats, err := r.reconcileList(ctx, listName, targetName, remByUID, seen)
+	if err != nil || len(r.mirrors[listName]) == 0 || r.inShadow(listName) {
+		return stats, err
+	}
+	mirrored, err := r.mirrorList(ctx, listName)
+	stats.add(mirrored)
+	return stats, err
+}
+
+// mirrorList copies the Reminders items of listName to each of its mirrors.
+// The items are fetched again so that changes the list's own reconcile wrote
+// to Reminders reach the mirrors in the same pass. Items carrying an ignore
+// marker are not mirrored. Each mirror counts as a
+// list of its own in the stats. The first error is returned.
+func (r *Reconciler) mirrorList(ctx context.Context, listName string) (Stats, error) {
+	remItems, err := r.rem.Fetch(ctx, []string{listName})
+	if err != nil {
+		return Stats{}, fmt.Errorf("fetching reminders for %q: %w", listName, err)
+	}
+	remByUID := make(map[string]*model.Item, len(remItems))
+	for _, item := range remItems {
+		if item.ListName == listName && !r.ignore.Match(item) {
+			remByUID[item.UID] = item
+		}
+	}
+
+	var stats Stats
+	var firstErr error
+	for _, targetName := range r.mirrors[listName] {
+		s, err := r.mirrorTo(ctx, listName, targetName, remByUID)
+		stats.add(s)
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("mirror %s: %w", targetName, err)
+		}
+	}
+	return stats, firstErr
+}
+
+// mirrorTo makes the mirror targetName hold the items of remByUID, the
+// Reminders items of listName. Failed writes are logged and counted; the
+// next pass tries them again.
+func (r *Reconciler) mirrorTo(ctx context.Context, listName, targetName string, remByUID map[string]*model.Item) (stats Stats, err error) {
+	started := r.clock.Now()
+	defer func() {
+		stats.Lists = []ListStats{{
+			ListName: listName,
+			Target:   targetName,
+			Created:  stats.Created,
+			Updated:  stats.Updated,
+			Deleted:  stats.Deleted,
+			Errors:   stats.Errors,
+			Duration: r.clock.Now().Sub(started),
+		}}
+	}()
+
+	tgt, err := r.resolveTarget(targetName, listName)
+	if err != nil {
+		return Stats{}, err
+	}
+	items, err := tgt.backend.Fetch(ctx, []string{tgt.list})
+	if err != nil {
+		return Stats{}, fmt.Errorf("fetching items of %s: %w", tgt.list, err)
+	}
+	mirrorByUID := indexByUID(items)
+	store := r.mirrorStores(targetName)
+	rows, err := store.GetAllItemsForList(ctx, listName)
+	if err != nil {
+		return Stats{}, fmt.Errorf("fetching state items for %q: %w", listName, err)
+	}
+	if missing := countMissing(rows, mirrorByUID); len(rows) > 1 && missing == len(rows) {
+		// Putting every item back would duplicate them all should the
+		// mirror have answered with an incomplete list.
+		r.log.Warn("mirror returned none of its items, not restoring them",
+			"list", listName, "mirror", targetName, "items", missing)
+		return Stats{}, nil
+	}
+	drops := unsupportedFields(tgt.backend, tgt.list)
+	now := r.clock.Now().UTC()
+
+	write := func(act action, title string, fn func() error) {
+		if r.readOnly {
+			r.log.Info("read-only: would apply", "list", listName, "mirror", targetName, "action", act, "title", title)
+		} else if werr := fn(); werr != nil {
+			r.log.Error("mirror write failed", "list", listName, "mirror", targetName, "action", act, "title", title, "error", werr)
+			stats.Errors++
+			if err == nil {
+				err = werr
+			}
+			return
+		}
+		switch act {
+		case actionCreateInHA, actionRestoreHA:
+			stats.Created++
+		case actionUpdateHA:
+			stats.Updated++
+		case actionDeleteFromHA:
+			stats.Deleted++
+		}
+	}
+	record := func(si *state.Item, rem *model.Item) error {
+		si.Title = rem.Title
+		si.LastSyncHash = rem.ContentHash()
+		si.RemindersModified = rem.ModifiedAt
+		si.LastSyncedAt = now
+		si.Base = baseOf(rem)
+		return store.UpsertItem(ctx, si)
+	}
+
+	tracked := make(map[string]bool, len(rows))
+	for _, si := range rows {
+		tracked[si.RemindersUID] = true
+		rem, m := remByUID[si.RemindersUID], mirrorByUID[si.HAUID]
+		switch {
+		case rem == nil:
+			if m != nil {
+				write(actionDeleteFromHA, si.Title, func() error {
+					return tgt.backend.Delete(ctx, tgt.list, m)
+				})
+			}
+			if !r.readOnly {
+				if derr := store.DeleteItem(ctx, si.ID); derr != nil && err == nil {
+					err = derr
+				}
+			}
+		case m == nil:
+			write(actionRestoreHA, rem.Title, func() error {
+				uid, cerr := tgt.backend.Create(ctx, tgt.list, rem)
+				if cerr != nil {
+					return cerr
+				}
+				si.HAUID = uid
+				return record(si, rem)
+			})
+		default:
+			copyFields(m, rem, drops)
+			if fields := model.ChangedFields(m, rem); fields != 0 {
+				write(actionUpdateHA, rem.Title, func() error {
+					if uerr := tgt.backend.Update(ctx, tgt.list, m, rem, fields); uerr != nil {
+						return uerr
+					}
+					return record(si, rem)
+				})
+			}
+		}
+	}
+
+	for uid, rem := range remByUID {
+		if tracked[uid] {
+			continue
+		}
+		write(actionCreateInHA, rem.Title, func() error {
+			haUID, cerr := tgt.backend.Create(ctx, tgt.list, rem)
+			if cerr != nil {
+				return cerr
+			}
+			return record(&state.Item{RemindersUID: uid, HAUID: haUID, ListName: listName}, rem)
+		})
+	}
+	return stats, err
+}
+
+// countMissing returns how many of rows have no item in byUID.
+func countMissing(rows []*state.Item, byUID map[string]*model.Item) int {
+	n := 0
+	for _, si := range rows {
+		if byUID[si.HAUID] == nil {
+			n++
+		}
+	}
+	return n
+}
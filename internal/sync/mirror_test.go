@@ -0,0 +1,72 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+func TestReconcile_MirrorsFollowReminders(t *testing.T) {
+	at := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	rem := newMockReminders(
+		newItem("rem-1", "Milk", "Shopping", model.PriorityNone, false, at),
+		newItem("rem-2", "Eggs", "Shopping", model.PriorityNone, false, at),
+	)
+	ha := newMockHA()
+	mirrorStore := newMockStore()
+	r := NewReconciler(rem, NewRegistry(ha), newMockStore(), testLogger,
+		WithMirrors(map[string][]string{"Shopping": {"todo.tablet"}}, func(string) StateStore { return mirrorStore }))
+
+	stats, err := r.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if got := len(ha.getItems("todo.tablet")); got != 2 {
+		t.Fatalf("mirror has %d items, want 2", got)
+	}
+	if stats.Created != 4 || len(stats.Lists) != 2 {
+		t.Errorf("stats = %+v, want 4 created over the mapping and its mirror", stats)
+	}
+
+	// Edit, delete, and add in the mirror; only Reminders' content counts.
+	items := ha.getItems("todo.tablet")
+	var edited string
+	for i := range items {
+		if items[i].Title == "Milk" {
+			items[i].Title = "Oat milk"
+			edited = items[i].UID
+		}
+	}
+	_ = ha.Delete(context.Background(), "todo.tablet", &model.Item{Title: "Eggs"})
+	ha.addItems("todo.tablet", model.Item{UID: "own", Title: "Tablet only"})
+
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	titles := map[string]string{}
+	for _, it := range ha.getItems("todo.tablet") {
+		titles[it.Title] = it.UID
+	}
+	if titles["Milk"] != edited || titles["Eggs"] == "" || titles["Tablet only"] != "own" || len(titles) != 3 {
+		t.Errorf("mirror items = %v, want Milk restored in place, Eggs put back, and its own item kept", titles)
+	}
+	if got := rem.get("rem-1"); got.Title != "Milk" {
+		t.Errorf("Reminders title = %q, want the mirror's edit ignored", got.Title)
+	}
+
+	// Deleting in Reminders removes the item from the mirror too.
+	_ = rem.Delete(context.Background(), "Shopping", &model.Item{UID: "rem-2"})
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("third run: %v", err)
+	}
+	for _, it := range ha.getItems("todo.tablet") {
+		if it.Title == "Eggs" {
+			t.Error("Eggs still in the mirror after it was deleted from Reminders")
+		}
+	}
+	if got := mirrorStore.count(); got != 1 {
+		t.Errorf("mirror state rows = %d, want 1", got)
+	}
+}
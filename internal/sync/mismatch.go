@@ -0,0 +1,196 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// MismatchPolicy decides which version of a bootstrap pair is kept when the
+// two items share a title but differ in content, such as their due dates.
+type MismatchPolicy string
+
+// Mismatch policies.
+const (
+	// MismatchAsk shows each mismatched pair and asks which side to keep.
+	MismatchAsk MismatchPolicy = "ask"
+	// MismatchReminders keeps the Reminders version.
+	MismatchReminders MismatchPolicy = "reminders"
+	// MismatchHA keeps the Home Assistant version.
+	MismatchHA MismatchPolicy = "ha"
+	// MismatchNewest keeps the version modified last, the Reminders one
+	// when that cannot be told.
+	MismatchNewest MismatchPolicy = "newest"
+)
+
+// WithMismatchPolicy sets how pairs whose contents differ are settled. The
+// default is [MismatchAsk]. Completion is settled by the [CompletionPolicy].
+func WithMismatchPolicy(p MismatchPolicy) BootstrapOption {
+	return func(b *Bootstrap) { b.mismatch = p }
+}
+
+// CompletionPolicy decides whether a bootstrap pair that is completed on one
+// side only ends up open or completed.
+type CompletionPolicy string
+
+// Completion policies.
+const (
+	// CompletionAsk shows each such pair and asks.
+	CompletionAsk CompletionPolicy = "ask"
+	// CompletionPreferOpen reopens the completed item.
+	CompletionPreferOpen CompletionPolicy = "prefer-open"
+	// CompletionPreferCompleted completes the open item.
+	CompletionPreferCompleted CompletionPolicy = "prefer-completed"
+)
+
+// WithCompletionPolicy sets how pairs completed on one side only are
+// settled. The default is [CompletionAsk].
+func WithCompletionPolicy(p CompletionPolicy) BootstrapOption {
+	return func(b *Bootstrap) { b.completion = p }
+}
+
+// mismatchFields returns the fields m differs in that the target of r can
+// store. Titles only have to match once normalised, so they do not count.
+func mismatchFields(r matchResult, m matchedPair) model.Fields {
+	fields := model.ChangedFields(m.rem, m.ha) &^ model.FieldTitle
+	if r.target.backend != nil {
+		fields &^= unsupportedFields(r.target.backend, r.target.list)
+	}
+	return fields
+}
+
+// resolveMismatches picks the version kept of every matched pair of results
+// whose contents differ, following the mismatch policy, and whether pairs
+// completed on one side only stay open, following the completion policy.
+func (b *Bootstrap) resolveMismatches(results []matchResult) {
+	for i := range results {
+		r := &results[i]
+		for j := range r.matched {
+			m := &r.matched[j]
+			fields := mismatchFields(*r, *m)
+			if content := fields &^ model.FieldCompleted; content != 0 {
+				switch b.mismatch {
+				case MismatchReminders:
+					m.keep = ToHA
+				case MismatchHA:
+					m.keep = ToReminders
+				case MismatchNewest:
+					m.keep = newerSide(m)
+				default:
+					m.keep = b.askMismatch(r.listName, m, content)
+				}
+			}
+			if fields.Has(model.FieldCompleted) {
+				open := false
+				switch b.completion {
+				case CompletionPreferOpen:
+					open = true
+				case CompletionPreferCompleted:
+				default:
+					open = b.askCompletion(r.listName, m)
+				}
+				// Copy the status wanted from the side that has it.
+				m.complete = ToHA
+				if m.rem.Completed == open {
+					m.complete = ToReminders
+				}
+			}
+		}
+	}
+}
+
+// newerSide returns the direction that copies the version of m modified
+// last over the other one.
+func newerSide(m *matchedPair) Direction {
+	if m.ha.ModifiedAt.After(m.rem.ModifiedAt) && !m.rem.ModifiedAt.IsZero() {
+		return ToReminders
+	}
+	return ToHA
+}
+
+// askMismatch shows how the two versions of m differ and asks which to keep.
+// An empty answer keeps the newer version.
+func (b *Bootstrap) askMismatch(listName string, m *matchedPair, fields model.Fields) Direction {
+	newest := newerSide(m)
+	_, _ = fmt.Fprintf(b.writer, "\nList %q: %q differs in %s\n", listName, m.rem.Title, fieldNames(fields))
+	_, _ = fmt.Fprintf(b.writer, "  Reminders  %s\n", describeCopy(m.rem))
+	_, _ = fmt.Fprintf(b.writer, "  %-10s %s\n", "HA", describeCopy(m.ha))
+	def := "r"
+	if newest == ToReminders {
+		def = "h"
+	}
+	_, _ = fmt.Fprintf(b.writer, "Keep the [r]eminders or the [h]a version? [%s] ", def)
+	if !b.in.Scan() {
+		return newest
+	}
+	switch strings.TrimSpace(strings.ToLower(b.in.Text())) {
+	case "r", "reminders":
+		return ToHA
+	case "h", "ha":
+		return ToReminders
+	default:
+		return newest
+	}
+}
+
+// askCompletion asks whether m, completed on one side only, should stay
+// open. An empty answer keeps it open.
+func (b *Bootstrap) askCompletion(listName string, m *matchedPair) bool {
+	_, _ = fmt.Fprintf(b.writer, "\nList %q: %q is %s\n", listName, m.rem.Title, completionNote(m))
+	_, _ = fmt.Fprint(b.writer, "Keep it [o]pen or [c]ompleted? [o] ")
+	if !b.in.Scan() {
+		return true
+	}
+	answer := strings.TrimSpace(strings.ToLower(b.in.Text()))
+	return answer != "c" && answer != "completed"
+}
+
+// completionNote says which side of m is completed.
+func completionNote(m *matchedPair) string {
+	if m.rem.Completed {
+		return "completed in Reminders but open in HA"
+	}
+	return "completed in HA but open in Reminders"
+}
+
+// settle copies the kept version of m over the other one, as m.keep and
+// m.complete say, and returns the pair as it then is on both sides.
+func (b *Bootstrap) settle(ctx context.Context, r matchResult, m matchedPair) (matchedPair, error) {
+	fields := mismatchFields(r, m)
+	var toHA, toRem model.Fields
+	switch m.keep {
+	case ToHA:
+		toHA |= fields &^ model.FieldCompleted
+	case ToReminders:
+		toRem |= fields &^ model.FieldCompleted
+	}
+	switch {
+	case !fields.Has(model.FieldCompleted):
+	case m.complete == ToHA:
+		toHA |= model.FieldCompleted
+	case m.complete == ToReminders:
+		toRem |= model.FieldCompleted
+	}
+
+	if toHA != 0 {
+		want := *m.ha
+		copyFields(&want, m.rem, toHA)
+		if err := r.target.backend.Update(ctx, r.target.list, m.ha, &want, toHA); err != nil {
+			return m, fmt.Errorf("updating %q in HA: %w", m.rem.Title, err)
+		}
+		m.ha = &want
+		b.log.Info("updated HA item from Reminders", "list", r.listName, "title", m.rem.Title, "fields", fieldNames(toHA))
+	}
+	if toRem != 0 {
+		want := *m.rem
+		copyFields(&want, m.ha, toRem)
+		if err := b.rem.Update(ctx, r.listName, m.rem, &want, toRem); err != nil {
+			return m, fmt.Errorf("updating %q in Reminders: %w", m.rem.Title, err)
+		}
+		m.rem = &want
+		b.log.Info("updated Reminders item from HA", "list", r.listName, "title", m.rem.Title, "fields", fieldNames(toRem))
+	}
+	return m, nil
+}
@@ -0,0 +1,185 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// mismatchedPair returns backends holding "Buy milk" with different notes:
+// the Reminders copy was modified an hour before the HA one.
+func mismatchedPair(now time.Time) (*mockReminders, *mockHA) {
+	remItem := newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, now.Add(-time.Hour))
+	remItem.Description = "2%"
+	rem := newMockReminders(remItem)
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Buy milk", Description: "oat", ModifiedAt: now})
+	return rem, ha
+}
+
+func TestBootstrap_MismatchAsksWhichSideToKeep(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name   string
+		answer string
+		want   string
+	}{
+		{"reminders", "r", "2%"},
+		{"ha", "h", "oat"},
+		{"default is newest", "", "oat"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rem, ha := mismatchedPair(now)
+			store := newMockStore()
+			var out bytes.Buffer
+			b := NewBootstrap(rem, NewRegistry(ha), store, slog.Default(), strings.NewReader(tt.answer+"\ny\n"), &out)
+
+			if ran, err := b.Run(context.Background(), testMappings); err != nil || !ran {
+				t.Fatalf("Run = %v, %v; want true, nil", ran, err)
+			}
+			if !strings.Contains(out.String(), `"Buy milk" differs in description`) {
+				t.Errorf("output does not show the difference:\n%s", out.String())
+			}
+			if got := rem.get("rem-1").Description; got != tt.want {
+				t.Errorf("Reminders description = %q, want %q", got, tt.want)
+			}
+			if got := ha.getItems("todo.shopping")[0].Description; got != tt.want {
+				t.Errorf("HA description = %q, want %q", got, tt.want)
+			}
+			si, _ := store.GetItemByRemindersUID(context.Background(), "rem-1")
+			if si == nil || si.LastSyncHash != rem.get("rem-1").ContentHash() {
+				t.Errorf("state row does not record the kept version: %+v", si)
+			}
+		})
+	}
+}
+
+func TestBootstrap_MismatchPolicy(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		policy MismatchPolicy
+		want   string
+	}{
+		{MismatchReminders, "2%"},
+		{MismatchHA, "oat"},
+		{MismatchNewest, "oat"},
+	} {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			rem, ha := mismatchedPair(now)
+			var out bytes.Buffer
+			b := NewBootstrap(rem, NewRegistry(ha), newMockStore(), slog.Default(), strings.NewReader("y\n"), &out,
+				WithMismatchPolicy(tt.policy))
+
+			if ran, err := b.Run(context.Background(), testMappings); err != nil || !ran {
+				t.Fatalf("Run = %v, %v; want true, nil", ran, err)
+			}
+			if strings.Contains(out.String(), "Keep the") {
+				t.Errorf("policy %s asked anyway:\n%s", tt.policy, out.String())
+			}
+			if got := rem.get("rem-1").Description; got != tt.want {
+				t.Errorf("Reminders description = %q, want %q", got, tt.want)
+			}
+			if got := ha.getItems("todo.shopping")[0].Description; got != tt.want {
+				t.Errorf("HA description = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBootstrap_MismatchNotAskedInSummaryOnly(t *testing.T) {
+	rem, ha := mismatchedPair(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	var out bytes.Buffer
+	b := NewBootstrap(rem, NewRegistry(ha), newMockStore(), slog.Default(), strings.NewReader(""), &out, WithSummaryOnly())
+
+	if _, err := b.Run(context.Background(), testMappings); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "Keep the") {
+		t.Errorf("summary-only run asked about a mismatch:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "✓ Buy milk (differs in description)") {
+		t.Errorf("summary does not show the mismatch:\n%s", out.String())
+	}
+	if got := ha.getItems("todo.shopping")[0].Description; got != "oat" {
+		t.Errorf("HA description = %q, want it untouched", got)
+	}
+}
+
+// completedPair returns backends holding "Buy milk", completed in Reminders
+// only.
+func completedPair() (*mockReminders, *mockHA) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	rem := newMockReminders(newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, true, now))
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Buy milk", ModifiedAt: now})
+	return rem, ha
+}
+
+func TestBootstrap_CompletedOnOneSide(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		policy CompletionPolicy
+		answer string
+		asked  bool
+		want   bool
+		note   string
+	}{
+		{"ask open", CompletionAsk, "o", true, false, "will be reopened"},
+		{"ask completed", CompletionAsk, "c", true, true, "will be completed"},
+		{"ask default", "", "", true, false, "will be reopened"},
+		{"prefer open", CompletionPreferOpen, "", false, false, "will be reopened"},
+		{"prefer completed", CompletionPreferCompleted, "", false, true, "will be completed"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rem, ha := completedPair()
+			input := "y\n"
+			if tt.asked {
+				input = tt.answer + "\n" + input
+			}
+			var out bytes.Buffer
+			b := NewBootstrap(rem, NewRegistry(ha), newMockStore(), slog.Default(), strings.NewReader(input), &out,
+				WithCompletionPolicy(tt.policy))
+
+			if ran, err := b.Run(context.Background(), testMappings); err != nil || !ran {
+				t.Fatalf("Run = %v, %v; want true, nil", ran, err)
+			}
+			if asked := strings.Contains(out.String(), "Keep it [o]pen"); asked != tt.asked {
+				t.Errorf("asked = %v, want %v:\n%s", asked, tt.asked, out.String())
+			}
+			if !strings.Contains(out.String(), "completed in Reminders but open in HA, "+tt.note) {
+				t.Errorf("summary does not surface the pair:\n%s", out.String())
+			}
+			if got := rem.get("rem-1").Completed; got != tt.want {
+				t.Errorf("Reminders completed = %v, want %v", got, tt.want)
+			}
+			if got := ha.getItems("todo.shopping")[0].Completed; got != tt.want {
+				t.Errorf("HA completed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBootstrap_CompletionSettledApartFromContent(t *testing.T) {
+	rem, ha := completedPair()
+	rem.get("rem-1").Description = "2%"
+	var out bytes.Buffer
+	b := NewBootstrap(rem, NewRegistry(ha), newMockStore(), slog.Default(), strings.NewReader("y\n"), &out,
+		WithMismatchPolicy(MismatchHA), WithCompletionPolicy(CompletionPreferCompleted))
+
+	if ran, err := b.Run(context.Background(), testMappings); err != nil || !ran {
+		t.Fatalf("Run = %v, %v; want true, nil", ran, err)
+	}
+	if got := rem.get("rem-1"); got.Description != "" || !got.Completed {
+		t.Errorf("Reminders item = %q, completed %v; want the HA notes, completed", got.Description, got.Completed)
+	}
+	if got := ha.getItems("todo.shopping")[0]; got.Description != "" || !got.Completed {
+		t.Errorf("HA item = %q, completed %v; want no notes, completed", got.Description, got.Completed)
+	}
+}
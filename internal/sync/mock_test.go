@@ -3,18 +3,21 @@ package sync
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/njoerd114/reminderrelay/internal/model"
 	"github.com/njoerd114/reminderrelay/internal/state"
 )
 
-// --- Mock Reminders Source ---------------------------------------------------
+// --- Mock Reminders Backend --------------------------------------------------
 
 type mockReminders struct {
 	mu    sync.Mutex
 	items map[string]*model.Item // UID → Item
 	nextUID int
+	fetches int
 }
 
 func newMockReminders(items ...*model.Item) *mockReminders {
@@ -25,10 +28,11 @@ func newMockReminders(items ...*model.Item) *mockReminders {
 	return m
 }
 
-func (m *mockReminders) FetchAll(_ context.Context, listNames []string) ([]*model.Item, error) {
+func (m *mockReminders) Fetch(_ context.Context, listNames []string) ([]*model.Item, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.fetches++
 	nameSet := make(map[string]bool, len(listNames))
 	for _, n := range listNames {
 		nameSet[n] = true
@@ -37,13 +41,14 @@ func (m *mockReminders) FetchAll(_ context.Context, listNames []string) ([]*mode
 	var result []*model.Item
 	for _, item := range m.items {
 		if nameSet[item.ListName] {
-			result = append(result, item)
+			cp := *item // the adapters return copies too
+			result = append(result, &cp)
 		}
 	}
 	return result, nil
 }
 
-func (m *mockReminders) Create(_ context.Context, item *model.Item) (string, error) {
+func (m *mockReminders) Create(_ context.Context, list string, item *model.Item) (string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -51,14 +56,16 @@ func (m *mockReminders) Create(_ context.Context, item *model.Item) (string, err
 	uid := fmt.Sprintf("rem-%d", m.nextUID)
 	cp := *item
 	cp.UID = uid
+	cp.ListName = list
 	m.items[uid] = &cp
 	return uid, nil
 }
 
-func (m *mockReminders) Update(_ context.Context, uid string, item *model.Item) error {
+func (m *mockReminders) Update(_ context.Context, _ string, current, item *model.Item, _ model.Fields) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	uid := current.UID
 	existing, ok := m.items[uid]
 	if !ok {
 		return fmt.Errorf("reminder %q not found", uid)
@@ -67,15 +74,19 @@ func (m *mockReminders) Update(_ context.Context, uid string, item *model.Item)
 	existing.Description = item.Description
 	existing.DueDate = item.DueDate
 	existing.Priority = item.Priority
+	existing.RawPriority = item.RawPriority
+	existing.AlertAt = item.AlertAt
 	existing.Completed = item.Completed
+	existing.CompletedAt = item.CompletedAt
 	existing.ModifiedAt = item.ModifiedAt
 	return nil
 }
 
-func (m *mockReminders) Delete(_ context.Context, uid string) error {
+func (m *mockReminders) Delete(_ context.Context, _ string, current *model.Item) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	uid := current.UID
 	if _, ok := m.items[uid]; !ok {
 		return fmt.Errorf("reminder %q not found", uid)
 	}
@@ -95,7 +106,13 @@ func (m *mockReminders) count() int {
 	return len(m.items)
 }
 
-// --- Mock HA Source -----------------------------------------------------------
+func (m *mockReminders) fetchCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.fetches
+}
+
+// --- Mock HA Backend ----------------------------------------------------------
 
 type mockHA struct {
 	mu      sync.Mutex
@@ -113,18 +130,23 @@ func (m *mockHA) addItems(entityID string, items ...model.Item) {
 	m.items[entityID] = append(m.items[entityID], items...)
 }
 
-func (m *mockHA) GetItems(_ context.Context, entityID string) ([]model.Item, error) {
+func (m *mockHA) Fetch(_ context.Context, entityIDs []string) ([]*model.Item, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	items := m.items[entityID]
 	// Return copies.
-	result := make([]model.Item, len(items))
-	copy(result, items)
+	var result []*model.Item
+	for _, entityID := range entityIDs {
+		for _, item := range m.items[entityID] {
+			cp := item
+			cp.ListName = entityID
+			result = append(result, &cp)
+		}
+	}
 	return result, nil
 }
 
-func (m *mockHA) AddItem(_ context.Context, entityID string, item *model.Item) error {
+func (m *mockHA) Create(_ context.Context, entityID string, item *model.Item) (string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -132,21 +154,32 @@ func (m *mockHA) AddItem(_ context.Context, entityID string, item *model.Item) e
 	cp := *item
 	cp.UID = fmt.Sprintf("ha-%d", m.nextUID)
 	m.items[entityID] = append(m.items[entityID], cp)
-	return nil
+	return cp.UID, nil
 }
 
-func (m *mockHA) UpdateItem(_ context.Context, entityID, currentTitle string, item *model.Item) error {
+func (m *mockHA) Update(_ context.Context, entityID string, current, item *model.Item, fields model.Fields) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	currentTitle := current.StoredTitle()
 	items := m.items[entityID]
 	for i, h := range items {
 		if h.Title == currentTitle {
-			items[i].Title = item.Title
-			items[i].Description = item.Description
-			items[i].DueDate = item.DueDate
-			items[i].Priority = item.Priority
-			items[i].Completed = item.Completed
+			if fields.Has(model.FieldTitle) {
+				items[i].Title = item.Title
+			}
+			if fields.Has(model.FieldDescription) {
+				items[i].Description = item.Description
+			}
+			if fields.Has(model.FieldDueDate) {
+				items[i].DueDate = item.DueDate
+			}
+			if fields.Has(model.FieldPriority) {
+				items[i].Priority = item.Priority
+			}
+			if fields.Has(model.FieldCompleted) {
+				items[i].Completed = item.Completed
+			}
 			items[i].ModifiedAt = item.ModifiedAt
 			return nil
 		}
@@ -154,10 +187,11 @@ func (m *mockHA) UpdateItem(_ context.Context, entityID, currentTitle string, it
 	return fmt.Errorf("item %q not found in %s", currentTitle, entityID)
 }
 
-func (m *mockHA) RemoveItem(_ context.Context, entityID, title string) error {
+func (m *mockHA) Delete(_ context.Context, entityID string, current *model.Item) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	title := current.StoredTitle()
 	items := m.items[entityID]
 	for i, h := range items {
 		if h.Title == title {
@@ -180,10 +214,17 @@ type mockStore struct {
 	mu    sync.Mutex
 	items map[int64]*state.Item
 	nextID int64
+	shadow map[string]*state.ShadowList
+	outbox []*state.Outbound
+	intents []*state.Intent
+	runs []state.SyncRun
 }
 
 func newMockStore() *mockStore {
-	return &mockStore{items: make(map[int64]*state.Item)}
+	return &mockStore{
+		items:  make(map[int64]*state.Item),
+		shadow: make(map[string]*state.ShadowList),
+	}
 }
 
 func (m *mockStore) seed(items ...*state.Item) {
@@ -266,8 +307,133 @@ func (m *mockStore) IsEmpty(_ context.Context) (bool, error) {
 	return len(m.items) == 0, nil
 }
 
+func (m *mockStore) GetShadowList(_ context.Context, listName string) (*state.ShadowList, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sl, ok := m.shadow[listName]
+	if !ok {
+		return nil, nil
+	}
+	cp := *sl
+	return &cp, nil
+}
+
+func (m *mockStore) RecordShadowPass(_ context.Context, listName string, creates, updates, deletes int, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sl, ok := m.shadow[listName]
+	if !ok {
+		sl = &state.ShadowList{ListName: listName}
+		m.shadow[listName] = sl
+	}
+	sl.Passes++
+	sl.WouldCreate, sl.WouldUpdate, sl.WouldDelete = creates, updates, deletes
+	sl.LastPassAt = at
+	return nil
+}
+
+func (m *mockStore) PromoteShadowList(_ context.Context, listName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sl, ok := m.shadow[listName]
+	if !ok {
+		sl = &state.ShadowList{ListName: listName}
+		m.shadow[listName] = sl
+	}
+	sl.Promoted = true
+	return nil
+}
+
 func (m *mockStore) count() int {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	return len(m.items)
 }
+
+func (m *mockStore) QueueOutbound(_ context.Context, o state.Outbound) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, q := range m.outbox {
+		if q.ListName == o.ListName && q.RemindersUID == o.RemindersUID && q.HAUID == o.HAUID {
+			q.Action, q.Title, q.LastError = o.Action, o.Title, o.LastError
+			q.Attempts++
+			return nil
+		}
+	}
+	m.nextID++
+	o.ID, o.Attempts = m.nextID, 1
+	m.outbox = append(m.outbox, &o)
+	return nil
+}
+
+func (m *mockStore) QueuedOutbound(_ context.Context, listName string) ([]*state.Outbound, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*state.Outbound
+	for _, q := range m.outbox {
+		if q.ListName == listName {
+			cp := *q
+			result = append(result, &cp)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockStore) DeleteOutbound(_ context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outbox = slices.DeleteFunc(m.outbox, func(q *state.Outbound) bool { return q.ID == id })
+	return nil
+}
+
+func (m *mockStore) queued() []state.Outbound {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]state.Outbound, 0, len(m.outbox))
+	for _, q := range m.outbox {
+		result = append(result, *q)
+	}
+	return result
+}
+
+func (m *mockStore) BeginIntent(_ context.Context, i state.Intent) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	i.ID = m.nextID
+	m.intents = append(m.intents, &i)
+	return i.ID, nil
+}
+
+func (m *mockStore) EndIntent(_ context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.intents = slices.DeleteFunc(m.intents, func(i *state.Intent) bool { return i.ID == id })
+	return nil
+}
+
+func (m *mockStore) Intents(_ context.Context, listName string) ([]*state.Intent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*state.Intent
+	for _, i := range m.intents {
+		if i.ListName == listName {
+			cp := *i
+			result = append(result, &cp)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockStore) RecordSyncRun(_ context.Context, run state.SyncRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs = append(m.runs, run)
+	return nil
+}
+
+func (m *mockStore) syncRuns() []state.SyncRun {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.runs)
+}
@@ -0,0 +1,98 @@
+package sync
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// Normalization selects the steps [WithNormalization] applies to titles
+// before they are hashed and compared.
+type Normalization struct {
+	Trim          bool // strip leading and trailing whitespace
+	CollapseSpace bool // replace each run of whitespace with one space
+	NFC           bool // compose to Unicode normalisation form C
+	Punctuation   bool // fold typographic quotes, dashes, and ellipses to ASCII
+}
+
+// Apply returns title with the steps of n applied.
+func (n Normalization) Apply(title string) string {
+	if n.NFC {
+		title = norm.NFC.String(title)
+	}
+	if n.Punctuation {
+		title = typography.Replace(title)
+	}
+	if n.CollapseSpace {
+		title = collapseSpace(title)
+	}
+	if n.Trim {
+		title = strings.TrimSpace(title)
+	}
+	return title
+}
+
+// collapseSpace replaces each run of Unicode whitespace in s with one space.
+func collapseSpace(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	inSpace := false
+	for _, c := range s {
+		if unicode.IsSpace(c) {
+			if !inSpace {
+				b.WriteByte(' ')
+			}
+			inSpace = true
+			continue
+		}
+		b.WriteRune(c)
+		inSpace = false
+	}
+	return b.String()
+}
+
+// WithNormalization normalises the title of every item as n says before it is
+// planned, so that sides storing the same title differently, such as with a
+// curly and a straight apostrophe, neither count as changed nor keep updating
+// each other. A normalised title is written to both sides along with the next
+// change of the item; until then each side keeps its own.
+func WithNormalization(n Normalization) ReconcilerOption {
+	return func(r *Reconciler) { r.normalization = n }
+}
+
+// normalizeTitles applies the reconciler's normalisation to the items of
+// listName in remByUID and haByUID, keeping the title each side stores in
+// RawTitle, and logs tracked items whose titles differed only by it.
+func (r *Reconciler) normalizeTitles(listName string, stateItems []*state.Item, remByUID, haByUID map[string]*model.Item) {
+	n := r.normalization
+	if n == (Normalization{}) {
+		return
+	}
+	for _, si := range stateItems {
+		rem, ha := remByUID[si.RemindersUID], haByUID[si.HAUID]
+		if rem != nil && ha != nil && rem.Title != ha.Title && n.Apply(rem.Title) == n.Apply(ha.Title) {
+			r.log.Debug("titles differ only by normalisation",
+				"list", listName, "reminders_title", rem.Title, "ha_title", ha.Title)
+		}
+	}
+	normalize := func(it *model.Item) {
+		if title := n.Apply(it.Title); title != it.Title {
+			if it.RawTitle == "" {
+				it.RawTitle = it.Title
+			}
+			it.Title = title
+		}
+	}
+	for _, it := range remByUID {
+		if it.ListName == listName {
+			normalize(it)
+		}
+	}
+	for _, it := range haByUID {
+		normalize(it)
+	}
+}
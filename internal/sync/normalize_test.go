@@ -0,0 +1,66 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+func TestNormalization_Apply(t *testing.T) {
+	all := Normalization{Trim: true, CollapseSpace: true, NFC: true, Punctuation: true}
+	for _, tt := range []struct {
+		n     Normalization
+		title string
+		want  string
+	}{
+		{all, "  Don’t  forget  ", "Don't forget"},
+		{all, "Café – later…", "Café - later..."},
+		{Normalization{Trim: true}, " a  b ", "a  b"},
+		{Normalization{CollapseSpace: true}, " a  b ", " a b "},
+		{Normalization{}, "Don’t", "Don’t"},
+	} {
+		if got := tt.n.Apply(tt.title); got != tt.want {
+			t.Errorf("%+v.Apply(%q) = %q, want %q", tt.n, tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestReconcile_NormalizedTitles(t *testing.T) {
+	at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	synced := model.Item{Title: "Don't forget"}
+	seed := func() (*mockReminders, *mockHA, *mockStore) {
+		rem := newMockReminders(newItem("rem-1", "Don’t forget", "Shopping", model.PriorityNone, false, at))
+		ha := newMockHA()
+		ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Don't  forget "})
+		store := newMockStore()
+		store.seed(&state.Item{RemindersUID: "rem-1", HAUID: "ha-1", ListName: "Shopping", Title: "Don't forget",
+			LastSyncHash: synced.ContentHash(), LastSyncedAt: at, Base: baseOf(&synced)})
+		return rem, ha, store
+	}
+	n := WithNormalization(Normalization{Trim: true, CollapseSpace: true, Punctuation: true})
+	ctx := context.Background()
+
+	// Titles that differ only by normalisation are in sync.
+	rem, ha, store := seed()
+	stats, err := NewReconciler(rem, NewRegistry(ha), store, testLogger, n).Run(ctx, testMappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Updated != 0 || stats.Conflicts != 0 {
+		t.Errorf("stats = %+v, want nothing to sync", stats)
+	}
+
+	// A real change still finds the HA item by the title HA stores.
+	rem, ha, store = seed()
+	rem.get("rem-1").Completed = true
+	stats, err = NewReconciler(rem, NewRegistry(ha), store, testLogger, n).Run(ctx, testMappings)
+	if err != nil || stats.Errors != 0 {
+		t.Fatalf("Run = %+v, %v; want no errors", stats, err)
+	}
+	if items := ha.getItems("todo.shopping"); len(items) != 1 || !items[0].Completed {
+		t.Errorf("HA items = %+v, want the item completed", items)
+	}
+}
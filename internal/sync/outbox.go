@@ -0,0 +1,120 @@
+package sync
+
+import (
+	"context"
+	"slices"
+
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// outboxKey identifies the item an op writes, as queued in the outbox.
+type outboxKey struct {
+	remUID string
+	haUID  string
+}
+
+// outboxKey returns the key of the item op writes.
+func (op plannedOp) outboxKey() outboxKey {
+	switch {
+	case op.si != nil:
+		return outboxKey{remUID: op.si.RemindersUID, haUID: op.si.HAUID}
+	case op.rem != nil:
+		return outboxKey{remUID: op.rem.UID}
+	case op.ha != nil:
+		return outboxKey{haUID: op.ha.UID}
+	}
+	return outboxKey{}
+}
+
+// outbound reports whether op writes to the target side.
+func (op plannedOp) outbound() bool {
+	return slices.Contains(op.directions(), ToHA)
+}
+
+// outbox tracks the queued target writes of one list during a pass. A nil
+// *outbox queues nothing.
+type outbox struct {
+	listName string
+	replayed map[outboxKey]int64 // queue entry of each replayed op
+}
+
+// replayQueued moves the ops of the queued target writes of listName to the
+// front of ops, oldest first, so that they are replayed before newer changes.
+// Entries whose item needs no target write any more, because it was synced
+// otherwise or is gone, are dropped.
+func (r *Reconciler) replayQueued(ctx context.Context, listName string, ops []plannedOp) ([]plannedOp, *outbox, error) {
+	queued, err := r.store.QueuedOutbound(ctx, listName)
+	if err != nil {
+		return nil, nil, err
+	}
+	box := &outbox{listName: listName, replayed: make(map[outboxKey]int64, len(queued))}
+	if len(queued) == 0 {
+		return ops, box, nil
+	}
+
+	index := make(map[outboxKey]int, len(ops))
+	for i, op := range ops {
+		if op.outbound() {
+			index[op.outboxKey()] = i
+		}
+	}
+	ordered := make([]plannedOp, 0, len(ops))
+	taken := make([]bool, len(ops))
+	for _, q := range queued {
+		key := outboxKey{remUID: q.RemindersUID, haUID: q.HAUID}
+		i, ok := index[key]
+		if !ok || taken[i] {
+			if err := r.store.DeleteOutbound(ctx, q.ID); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		taken[i] = true
+		box.replayed[key] = q.ID
+		ordered = append(ordered, ops[i])
+	}
+	for i, op := range ops {
+		if !taken[i] {
+			ordered = append(ordered, op)
+		}
+	}
+
+	if len(box.replayed) > 0 {
+		r.log.Info("replaying queued writes", "list", listName, "count", len(box.replayed))
+	}
+	return ordered, box, nil
+}
+
+// settle records the outcome of op in the outbox: a failed target write is
+// queued, and a replayed one that went through leaves the queue. Failures to
+// update the queue are only logged, as the next pass plans the write anyway.
+func (r *Reconciler) settle(ctx context.Context, box *outbox, op plannedOp, err error) {
+	if box == nil || !op.outbound() {
+		return
+	}
+	key := op.outboxKey()
+
+	if err == nil {
+		id, ok := box.replayed[key]
+		if !ok {
+			return
+		}
+		if err := r.store.DeleteOutbound(ctx, id); err != nil {
+			r.log.Warn("could not remove replayed write from the queue", "title", op.title(), "error", err)
+		}
+		return
+	}
+
+	q := state.Outbound{
+		ListName:     box.listName,
+		RemindersUID: key.remUID,
+		HAUID:        key.haUID,
+		Action:       op.act.String(),
+		Title:        op.title(),
+		QueuedAt:     r.clock.Now().UTC(),
+		LastError:    err.Error(),
+	}
+	if err := r.store.QueueOutbound(ctx, q); err != nil {
+		r.log.Error("could not queue failed write", "title", op.title(), "error", err)
+	}
+}
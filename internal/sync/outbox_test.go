@@ -0,0 +1,79 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// outageHA fails every Create while down, and records the titles it
+// created.
+type outageHA struct {
+	*mockHA
+	down    bool
+	created []string
+}
+
+func (o *outageHA) Create(ctx context.Context, list string, item *model.Item) (string, error) {
+	if o.down {
+		return "", errors.New("connection refused")
+	}
+	o.created = append(o.created, item.Title)
+	return o.mockHA.Create(ctx, list, item)
+}
+
+func TestReconcile_ReplaysQueuedWritesInOrder(t *testing.T) {
+	rem := newMockReminders()
+	ha := &outageHA{mockHA: newMockHA(), down: true}
+	store := newMockStore()
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+	ctx := context.Background()
+
+	add := func(title string) string {
+		t.Helper()
+		uid, err := rem.Create(ctx, "Shopping", &model.Item{Title: title})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return uid
+	}
+
+	// Writes fail across three passes, each adding a reminder.
+	var tea string
+	for _, title := range []string{"Milk", "Tea", "Eggs"} {
+		uid := add(title)
+		if title == "Tea" {
+			tea = uid
+		}
+		if _, err := r.Run(ctx, testMappings); err == nil {
+			t.Fatalf("Run() with HA down succeeded")
+		}
+	}
+	queued := store.queued()
+	var titles []string
+	for _, q := range queued {
+		titles = append(titles, q.Title)
+	}
+	if !slices.Equal(titles, []string{"Milk", "Tea", "Eggs"}) || queued[0].Attempts != 3 {
+		t.Fatalf("queue = %+v, want Milk (3 attempts), Tea, Eggs", queued)
+	}
+
+	// Tea is deleted before HA comes back, and Bread is added.
+	if err := rem.Delete(ctx, "Shopping", &model.Item{UID: tea}); err != nil {
+		t.Fatal(err)
+	}
+	add("Bread")
+	ha.down = false
+	if _, err := r.Run(ctx, testMappings); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !slices.Equal(ha.created, []string{"Milk", "Eggs", "Bread"}) {
+		t.Errorf("created = %v, want the queue replayed first, in order, without Tea", ha.created)
+	}
+	if q := store.queued(); len(q) != 0 {
+		t.Errorf("queue = %+v after replay, want empty", q)
+	}
+}
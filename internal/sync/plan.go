@@ -0,0 +1,146 @@
+package sync
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// nearMatchThreshold is how alike titles must be to be reported as near
+// matches in a plan when fuzzy matching is off.
+const nearMatchThreshold = 0.8
+
+// plan is the match plan of a bootstrap, as written by [WithPlanOut].
+type plan struct {
+	Lists []planList `json:"lists"`
+}
+
+// planList is the plan for one list mapping.
+type planList struct {
+	List        string     `json:"list"`
+	Target      string     `json:"target"`
+	Matched     []planPair `json:"matched"`
+	ToHA        []string   `json:"to_ha"`        // only in Reminders, pushed to the target
+	ToReminders []string   `json:"to_reminders"` // only in the target, pushed to Reminders
+	NearMatches []planPair `json:"near_matches"` // alike titles among the unmatched items
+}
+
+// planPair is a Reminders title and the target title it is or may be linked
+// with.
+type planPair struct {
+	Reminders  string  `json:"reminders"`
+	HA         string  `json:"ha"`
+	Similarity float64 `json:"similarity,omitempty"`
+	Differs    string  `json:"differs,omitempty"` // content fields that differ, comma-separated
+}
+
+// WithPlanOut writes the match plan to path before anything is asked, so a
+// long plan can be reviewed in an editor. A path ending in ".json" gets JSON,
+// any other path Markdown.
+func WithPlanOut(path string) BootstrapOption {
+	return func(b *Bootstrap) { b.planOut = path }
+}
+
+// buildPlan collects the plan of results, lists and titles sorted by name.
+// Near matches are those fuzzy matching would ask about.
+func (b *Bootstrap) buildPlan(results []matchResult) plan {
+	threshold := cmp.Or(b.fuzzy, nearMatchThreshold)
+	var p plan
+	for _, r := range results {
+		pl := planList{
+			List:        r.listName,
+			Target:      r.entityID,
+			Matched:     []planPair{},
+			ToHA:        []string{},
+			ToReminders: []string{},
+			NearMatches: []planPair{},
+		}
+		for _, m := range r.matched {
+			pl.Matched = append(pl.Matched, planPair{Reminders: m.rem.Title, HA: m.ha.Title, Differs: fieldNames(mismatchFields(r, m))})
+		}
+		for _, it := range r.remOnly {
+			pl.ToHA = append(pl.ToHA, it.Title)
+		}
+		for _, it := range r.haOnly {
+			pl.ToReminders = append(pl.ToReminders, it.Title)
+		}
+		for _, m := range fuzzyPairs(r.remOnly, r.haOnly, threshold) {
+			pl.NearMatches = append(pl.NearMatches, planPair{
+				Reminders:  m.rem.Title,
+				HA:         m.ha.Title,
+				Similarity: titleSimilarity(m.rem.Title, m.ha.Title),
+			})
+		}
+		slices.SortFunc(pl.Matched, func(a, b planPair) int { return cmp.Compare(a.Reminders, b.Reminders) })
+		slices.Sort(pl.ToHA)
+		slices.Sort(pl.ToReminders)
+		p.Lists = append(p.Lists, pl)
+	}
+	slices.SortFunc(p.Lists, func(a, b planList) int { return cmp.Compare(a.List, b.List) })
+	return p
+}
+
+// writePlan writes the plan of results to b.planOut.
+func (b *Bootstrap) writePlan(results []matchResult) error {
+	p := b.buildPlan(results)
+	var data []byte
+	if strings.EqualFold(filepath.Ext(b.planOut), ".json") {
+		var err error
+		if data, err = json.MarshalIndent(p, "", "  "); err != nil {
+			return fmt.Errorf("encoding bootstrap plan: %w", err)
+		}
+		data = append(data, '\n')
+	} else {
+		data = p.markdown(b.fuzzy > 0)
+	}
+	if err := os.WriteFile(b.planOut, data, 0o600); err != nil {
+		return fmt.Errorf("writing bootstrap plan: %w", err)
+	}
+	_, _ = fmt.Fprintf(b.writer, "\nBootstrap plan written to %s — review it before answering.\n", b.planOut)
+	return nil
+}
+
+// markdown renders the plan as a Markdown document. asked tells whether
+// near matches will be offered for linking.
+func (p plan) markdown(asked bool) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Bootstrap Plan\n")
+	for _, l := range p.Lists {
+		fmt.Fprintf(&buf, "\n## %s ↔ %s\n", l.List, l.Target)
+
+		fmt.Fprintf(&buf, "\n### Matched by title (%d)\n\n", len(l.Matched))
+		for _, m := range l.Matched {
+			if m.Differs != "" {
+				fmt.Fprintf(&buf, "- %s (differs in %s)\n", m.Reminders, m.Differs)
+				continue
+			}
+			fmt.Fprintf(&buf, "- %s\n", m.Reminders)
+		}
+		fmt.Fprintf(&buf, "\n### Only in Reminders, pushed to %s (%d)\n\n", l.Target, len(l.ToHA))
+		for _, t := range l.ToHA {
+			fmt.Fprintf(&buf, "- %s\n", t)
+		}
+		fmt.Fprintf(&buf, "\n### Only in %s, pushed to Reminders (%d)\n\n", l.Target, len(l.ToReminders))
+		for _, t := range l.ToReminders {
+			fmt.Fprintf(&buf, "- %s\n", t)
+		}
+		if len(l.NearMatches) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "\n### Near matches (%d)\n\n", len(l.NearMatches))
+		if asked {
+			buf.WriteString("You will be asked whether to link each pair.\n\n")
+		} else {
+			buf.WriteString("Not linked; set bootstrap.fuzzy_match to be asked about each pair.\n\n")
+		}
+		for _, m := range l.NearMatches {
+			fmt.Fprintf(&buf, "- %s ↔ %s (%.0f%% alike)\n", m.Reminders, m.HA, m.Similarity*100)
+		}
+	}
+	return buf.Bytes()
+}
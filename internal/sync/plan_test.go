@@ -0,0 +1,77 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+func TestBootstrap_WritesPlan(t *testing.T) {
+	now := time.Now().UTC()
+	rem := newMockReminders(
+		newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, now),
+		newItem("rem-2", "Call plumber", "Shopping", model.PriorityNone, false, now),
+		newItem("rem-3", "Water plants", "Shopping", model.PriorityNone, false, now),
+	)
+	ha := newMockHA()
+	ha.addItems("todo.shopping",
+		model.Item{UID: "ha-1", Title: "Buy milk", ModifiedAt: now},
+		model.Item{UID: "ha-2", Title: "Call plumbers", ModifiedAt: now},
+	)
+	dir := t.TempDir()
+
+	// Declined, so only the plan files are written.
+	jsonPath := filepath.Join(dir, "plan.json")
+	b := NewBootstrap(rem, NewRegistry(ha), newMockStore(), testLogger, strings.NewReader("n\n"), io.Discard,
+		WithPlanOut(jsonPath))
+	if _, err := b.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("reading plan: %v", err)
+	}
+	var p plan
+	if err := json.Unmarshal(data, &p); err != nil {
+		t.Fatalf("decoding plan: %v", err)
+	}
+	if len(p.Lists) != 1 {
+		t.Fatalf("plan has %d list(s), want 1", len(p.Lists))
+	}
+	l := p.Lists[0]
+	if l.List != "Shopping" || l.Target != "todo.shopping" {
+		t.Errorf("list = %q → %q, want Shopping → todo.shopping", l.List, l.Target)
+	}
+	if len(l.Matched) != 1 || l.Matched[0].Reminders != "Buy milk" {
+		t.Errorf("Matched = %+v, want Buy milk", l.Matched)
+	}
+	if len(l.ToHA) != 2 || len(l.ToReminders) != 1 {
+		t.Errorf("ToHA = %v, ToReminders = %v, want 2 and 1 titles", l.ToHA, l.ToReminders)
+	}
+	if len(l.NearMatches) != 1 || l.NearMatches[0].HA != "Call plumbers" {
+		t.Errorf("NearMatches = %+v, want Call plumber ↔ Call plumbers", l.NearMatches)
+	}
+
+	mdPath := filepath.Join(dir, "plan.md")
+	b = NewBootstrap(rem, NewRegistry(ha), newMockStore(), testLogger, strings.NewReader("n\n"), io.Discard,
+		WithPlanOut(mdPath))
+	if _, err := b.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	data, err = os.ReadFile(mdPath)
+	if err != nil {
+		t.Fatalf("reading plan: %v", err)
+	}
+	for _, want := range []string{"## Shopping ↔ todo.shopping", "- Water plants", "- Call plumber ↔ Call plumbers"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Markdown plan lacks %q:\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// SetListPrefix makes listName one of several Reminders lists sharing target,
+// told apart by a tag at the start of item titles, such as "[Work]" in
+// "[Work] Email boss". Items of listName are written to target with the tag,
+// and target items carrying it are read back into listName, so an item
+// created on the target is routed to the list its tag names. An empty tag
+// makes listName the default list, which takes the target's items without
+// another list's tag. Without a default list such items are left out of the
+// sync. It must be called before the registry is used.
+func (r *Registry) SetListPrefix(target, listName, tag string) error {
+	if tag != strings.TrimSpace(tag) {
+		return fmt.Errorf("list prefix %q must not start or end with whitespace", tag)
+	}
+	for other, t := range r.prefixes[target] {
+		if other != listName && t == tag {
+			if tag == "" {
+				return fmt.Errorf("lists %q and %q both lack a prefix for %s", other, listName, target)
+			}
+			return fmt.Errorf("lists %q and %q share the prefix %q for %s", other, listName, tag, target)
+		}
+	}
+	if r.prefixes == nil {
+		r.prefixes = make(map[string]map[string]string)
+	}
+	if r.prefixes[target] == nil {
+		r.prefixes[target] = make(map[string]string)
+	}
+	r.prefixes[target][listName] = tag
+	return nil
+}
+
+// prefixBackend shows one Reminders list the items of a target it shares
+// with other lists: those with its title tag, stripped of it, or for the
+// default list those with no other list's tag.
+type prefixBackend struct {
+	next   TaskBackend
+	tag    string   // "" for the default list
+	others []string // tags of the other lists sharing the target
+}
+
+// withPrefix returns b wrapped to hold only the items of listName, given the
+// tags of the lists sharing b's target, or b itself if it is not shared.
+func withPrefix(b TaskBackend, tags map[string]string, listName string) TaskBackend {
+	if len(tags) == 0 {
+		return b
+	}
+	p := &prefixBackend{next: b, tag: tags[listName]}
+	for other, tag := range tags {
+		if other != listName && tag != "" {
+			p.others = append(p.others, tag)
+		}
+	}
+	return p
+}
+
+// Fetch returns the items on lists that belong to the list, with their tag
+// stripped from the title.
+func (b *prefixBackend) Fetch(ctx context.Context, lists []string) ([]*model.Item, error) {
+	items, err := b.next.Fetch(ctx, lists)
+	if err != nil {
+		return nil, err
+	}
+	kept := items[:0]
+	for _, it := range items {
+		if b.tag != "" {
+			title, ok := cutTag(it.Title, b.tag)
+			if !ok {
+				continue
+			}
+			it.Title = title
+		} else if b.tagged(it.Title) {
+			continue
+		}
+		kept = append(kept, it)
+	}
+	return kept, nil
+}
+
+// Create adds item to list with the list's tag.
+func (b *prefixBackend) Create(ctx context.Context, list string, item *model.Item) (string, error) {
+	return b.next.Create(ctx, list, b.encode(item))
+}
+
+// Update writes item over current, both with the list's tag.
+func (b *prefixBackend) Update(ctx context.Context, list string, current, item *model.Item, fields model.Fields) error {
+	return b.next.Update(ctx, list, b.encode(current), b.encode(item), fields)
+}
+
+// Delete removes current from list.
+func (b *prefixBackend) Delete(ctx context.Context, list string, current *model.Item) error {
+	return b.next.Delete(ctx, list, b.encode(current))
+}
+
+// SupportedFields returns the fields the wrapped backend can store on list.
+func (b *prefixBackend) SupportedFields(list string) model.Fields {
+	return model.AllFields &^ unsupportedFields(b.next, list)
+}
+
+// tagged reports whether title carries the tag of another list.
+func (b *prefixBackend) tagged(title string) bool {
+	for _, tag := range b.others {
+		if _, ok := cutTag(title, tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// encode returns a copy of item with the list's tag before its title, and
+// before the title its backend stores should that differ.
+func (b *prefixBackend) encode(item *model.Item) *model.Item {
+	c := *item
+	if b.tag == "" {
+		return &c
+	}
+	c.Title = b.tag + " " + item.Title
+	if item.RawTitle != "" {
+		c.RawTitle = b.tag + " " + item.RawTitle
+	}
+	return &c
+}
+
+// cutTag returns title without a leading tag and the space after it, and
+// whether title starts with tag as a word of its own.
+func cutTag(title, tag string) (string, bool) {
+	rest, ok := strings.CutPrefix(title, tag)
+	if !ok || (rest != "" && rest[0] != ' ') {
+		return title, false
+	}
+	return strings.TrimPrefix(rest, " "), true
+}
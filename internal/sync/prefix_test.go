@@ -0,0 +1,109 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+func TestReconcile_ListPrefixesShareTarget(t *testing.T) {
+	at := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	rem := newMockReminders(
+		newItem("rem-w", "Email boss", "Work", model.PriorityNone, false, at),
+		newItem("rem-h", "Laundry", "Home", model.PriorityNone, false, at),
+	)
+	ha := newMockHA()
+	targets := NewRegistry(ha)
+	if err := targets.SetListPrefix("todo.tasks", "Work", "[Work]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := targets.SetListPrefix("todo.tasks", "Home", ""); err != nil {
+		t.Fatal(err)
+	}
+	mappings := map[string]string{"Work": "todo.tasks", "Home": "todo.tasks"}
+	r := NewReconciler(rem, targets, newMockStore(), testLogger)
+
+	if _, err := r.Run(context.Background(), mappings); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	titles := map[string]bool{}
+	for _, it := range ha.getItems("todo.tasks") {
+		titles[it.Title] = true
+	}
+	if !titles["[Work] Email boss"] || !titles["Laundry"] || len(titles) != 2 {
+		t.Fatalf("HA titles = %v, want the Work item tagged and the Home item as is", titles)
+	}
+
+	// Items added in HA go to the list their tag names, or the default one.
+	ha.addItems("todo.tasks",
+		model.Item{UID: "ha-1", Title: "[Work] Call Bob", ModifiedAt: at},
+		model.Item{UID: "ha-2", Title: "Buy milk", ModifiedAt: at},
+	)
+	stats, err := r.Run(context.Background(), mappings)
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if stats.Created != 2 {
+		t.Errorf("Created = %d, want 2", stats.Created)
+	}
+	items, _ := rem.Fetch(context.Background(), []string{"Work", "Home"})
+	lists := map[string]string{}
+	for _, it := range items {
+		lists[it.Title] = it.ListName
+	}
+	if lists["Call Bob"] != "Work" || lists["Buy milk"] != "Home" || len(lists) != 4 {
+		t.Errorf("Reminders items = %v, want Call Bob in Work and Buy milk in Home", lists)
+	}
+
+	// A rename in Reminders keeps the tag in HA.
+	rem.get("rem-w").Title = "Email the boss"
+	rem.get("rem-w").ModifiedAt = at.Add(time.Hour)
+	if _, err := r.Run(context.Background(), mappings); err != nil {
+		t.Fatalf("third run: %v", err)
+	}
+	found := false
+	for _, it := range ha.getItems("todo.tasks") {
+		found = found || it.Title == "[Work] Email the boss"
+	}
+	if !found {
+		t.Errorf("HA items = %+v, want the renamed Work item tagged", ha.getItems("todo.tasks"))
+	}
+}
+
+func TestRegistry_SetListPrefix(t *testing.T) {
+	reg := NewRegistry(newMockHA())
+	if err := reg.SetListPrefix("todo.tasks", "Work", "[Work]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.SetListPrefix("todo.tasks", "Office", "[Work]"); err == nil {
+		t.Error("two lists with the same prefix: want an error")
+	}
+	if err := reg.SetListPrefix("todo.tasks", "Home", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.SetListPrefix("todo.tasks", "Garden", ""); err == nil {
+		t.Error("two lists without a prefix: want an error")
+	}
+	if err := reg.SetListPrefix("todo.tasks", "Garden", " [Garden]"); err == nil {
+		t.Error("prefix with leading space: want an error")
+	}
+}
+
+func TestCutTag(t *testing.T) {
+	tests := []struct {
+		title, want string
+		ok          bool
+	}{
+		{"[Work] Email boss", "Email boss", true},
+		{"[Work]", "", true},
+		{"[Work]shop", "[Work]shop", false},
+		{"Email boss", "Email boss", false},
+	}
+	for _, tt := range tests {
+		if got, ok := cutTag(tt.title, "[Work]"); got != tt.want || ok != tt.ok {
+			t.Errorf("cutTag(%q) = %q, %v; want %q, %v", tt.title, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// ProblemNotifier publishes a single, household-visible summary of unresolved
+// sync problems and clears it once they are resolved.
+// Implemented by [homeassistant.Adapter] via persistent notifications and by
+// [desktop.Notifier] via macOS user notifications.
+type ProblemNotifier interface {
+	CreateNotification(ctx context.Context, notificationID, title, message string) error
+	DismissNotification(ctx context.Context, notificationID string) error
+}
+
+const (
+	problemNotificationID    = "reminderrelay_problems"
+	problemNotificationTitle = "ReminderRelay needs attention"
+)
+
+// problemTracker turns per-pass reconcile results into a degraded/healthy
+// signal. A list counts as degraded after threshold consecutive failed
+// passes; an authorization failure, denied Reminders access, or a list held
+// back by the deletion guard is degraded immediately.
+type problemTracker struct {
+	notifier  ProblemNotifier
+	threshold int
+	log       *slog.Logger
+	id, title string // notification ID and title, labelled per instance
+
+	failures  map[string]int   // list name → consecutive failed passes
+	lastErr   map[string]error // list name → most recent failure
+	authErr   error
+	accessErr error
+	posted    string // message currently shown, "" when cleared
+}
+
+// newProblemTracker creates a tracker. A non-empty instance gets its own
+// notification so several instances posting to one HA do not overwrite each
+// other.
+func newProblemTracker(notifier ProblemNotifier, threshold int, instance string, logger *slog.Logger) *problemTracker {
+	id, title := problemNotificationID, problemNotificationTitle
+	if instance != "" {
+		id += "_" + instance
+		title += " (" + instance + ")"
+	}
+	return &problemTracker{
+		notifier:  notifier,
+		threshold: threshold,
+		log:       logger,
+		id:        id,
+		title:     title,
+		failures:  make(map[string]int),
+		lastErr:   make(map[string]error),
+	}
+}
+
+// observe records the outcome of one reconcile pass over lists and creates,
+// updates, or dismisses the notification when the summary changes.
+func (t *problemTracker) observe(ctx context.Context, lists []string, stats Stats, passErr error) {
+	// passErr is only the first list's error; a rejected token or denied
+	// access can surface on any list.
+	t.authErr, t.accessErr = nil, nil
+	errs := []error{passErr}
+	for _, list := range slices.Sorted(maps.Keys(stats.ListErrors)) {
+		errs = append(errs, stats.ListErrors[list])
+	}
+	for _, err := range errs {
+		if t.authErr == nil && errors.Is(err, model.ErrUnauthorized) {
+			t.authErr = err
+		}
+		if t.accessErr == nil && errors.Is(err, model.ErrAccessDenied) {
+			t.accessErr = err
+		}
+	}
+
+	for _, list := range lists {
+		err := stats.ListErrors[list]
+		if err == nil && passErr != nil && len(stats.ListErrors) == 0 {
+			// The pass failed before any list was processed (e.g. the
+			// Reminders fetch) — every list is affected.
+			err = passErr
+		}
+		if err == nil {
+			delete(t.failures, list)
+			delete(t.lastErr, list)
+			continue
+		}
+		t.failures[list]++
+		t.lastErr[list] = err
+	}
+
+	t.publish(ctx, t.summary())
+}
+
+// summary renders the current problems as a Markdown message, or "" when
+// nothing is degraded.
+func (t *problemTracker) summary() string {
+	var lines []string
+	if t.authErr != nil {
+		lines = append(lines, "- Home Assistant rejected the access token. Update `ha_token` in the ReminderRelay config.")
+	}
+	if t.accessErr != nil {
+		lines = append(lines, "- macOS denied access to Reminders. Allow it in System Settings → Privacy & Security → Reminders.")
+	}
+
+	lists := make([]string, 0, len(t.failures))
+	for list, n := range t.failures {
+		// A list held back by the deletion guard stays so until someone
+		// looks, so it is reported at once.
+		if n >= t.threshold || errors.Is(t.lastErr[list], ErrTooManyDeletes) {
+			lists = append(lists, list)
+		}
+	}
+	sort.Strings(lists)
+	for _, list := range lists {
+		if errors.Is(t.lastErr[list], ErrTooManyDeletes) {
+			lines = append(lines, fmt.Sprintf("- List **%s** was not synced because the pass would delete too many items: %v",
+				list, t.lastErr[list]))
+			continue
+		}
+		// The threshold, not the running count, keeps the message the same
+		// while the failure lasts, so it is not re-posted on every pass.
+		lines = append(lines, fmt.Sprintf("- List **%s** has failed %d or more sync passes in a row: %v",
+			list, t.threshold, t.lastErr[list]))
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return "ReminderRelay is having trouble syncing:\n\n" + strings.Join(lines, "\n")
+}
+
+// publish pushes msg to the notifier if it differs from what is currently shown.
+// An empty msg dismisses the notification. Failures are logged only — an
+// expired token, for example, also prevents posting the notification.
+func (t *problemTracker) publish(ctx context.Context, msg string) {
+	if msg == t.posted {
+		return
+	}
+
+	if msg == "" {
+		if err := t.notifier.DismissNotification(ctx, t.id); err != nil {
+			t.log.Error("dismissing problem notification", "error", err)
+			return
+		}
+		t.log.Info("sync problems resolved, notification dismissed")
+		t.posted = ""
+		return
+	}
+
+	if err := t.notifier.CreateNotification(ctx, t.id, t.title, msg); err != nil {
+		t.log.Error("posting problem notification", "error", err)
+		return
+	}
+	t.log.Warn("sync degraded, problem notification posted")
+	t.posted = msg
+}
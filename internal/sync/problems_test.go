@@ -0,0 +1,122 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+type mockNotifier struct {
+	created   []string
+	ids       []string
+	dismissed int
+}
+
+func (m *mockNotifier) CreateNotification(_ context.Context, id, _, message string) error {
+	m.created = append(m.created, message)
+	m.ids = append(m.ids, id)
+	return nil
+}
+
+func (m *mockNotifier) DismissNotification(_ context.Context, _ string) error {
+	m.dismissed++
+	return nil
+}
+
+func TestProblemTracker_RepeatedListFailures(t *testing.T) {
+	n := &mockNotifier{}
+	tr := newProblemTracker(n, 3, "", testLogger)
+	ctx := context.Background()
+	lists := []string{"Shopping", "Work"}
+
+	failing := Stats{ListErrors: map[string]error{"Shopping": errors.New("HA timeout")}}
+	for range 2 {
+		tr.observe(ctx, lists, failing, failing.ListErrors["Shopping"])
+	}
+	if len(n.created) != 0 {
+		t.Fatalf("notified after 2 failures, want threshold of 3")
+	}
+
+	tr.observe(ctx, lists, failing, failing.ListErrors["Shopping"])
+	if len(n.created) != 1 || !strings.Contains(n.created[0], "Shopping") {
+		t.Fatalf("created = %v, want one notification mentioning Shopping", n.created)
+	}
+
+	// Further failures with the same error leave the notification alone.
+	for range 3 {
+		tr.observe(ctx, lists, failing, failing.ListErrors["Shopping"])
+	}
+	if len(n.created) != 1 {
+		t.Errorf("created = %d, want 1: the notification must not be re-posted while the failure lasts", len(n.created))
+	}
+
+	// A different error updates it.
+	other := Stats{ListErrors: map[string]error{"Shopping": errors.New("HA returned 500")}}
+	tr.observe(ctx, lists, other, other.ListErrors["Shopping"])
+	if len(n.created) != 2 || !strings.Contains(n.created[1], "500") {
+		t.Errorf("created = %v, want notification updated with the new error", n.created)
+	}
+
+	// Recovery dismisses once.
+	tr.observe(ctx, lists, Stats{}, nil)
+	tr.observe(ctx, lists, Stats{}, nil)
+	if n.dismissed != 1 {
+		t.Errorf("dismissed = %d, want 1", n.dismissed)
+	}
+}
+
+func TestProblemTracker_AuthFailureIsImmediate(t *testing.T) {
+	n := &mockNotifier{}
+	tr := newProblemTracker(n, 3, "", testLogger)
+
+	authErr := fmt.Errorf("get items: %w", model.ErrUnauthorized)
+	tr.observe(context.Background(), []string{"Shopping"}, Stats{}, authErr)
+
+	if len(n.created) != 1 || !strings.Contains(n.created[0], "ha_token") {
+		t.Errorf("created = %v, want immediate token notification", n.created)
+	}
+}
+
+func TestProblemTracker_AuthFailureOnLaterList(t *testing.T) {
+	n := &mockNotifier{}
+	tr := newProblemTracker(n, 3, "", testLogger)
+
+	// The pass error is the first list's; the token was rejected on another.
+	stats := Stats{ListErrors: map[string]error{
+		"Groceries": errors.New("HA timeout"),
+		"Shopping":  fmt.Errorf("get items: %w", model.ErrUnauthorized),
+	}}
+	tr.observe(context.Background(), []string{"Groceries", "Shopping"}, stats, stats.ListErrors["Groceries"])
+
+	if len(n.created) != 1 || !strings.Contains(n.created[0], "ha_token") {
+		t.Errorf("created = %v, want immediate token notification", n.created)
+	}
+}
+
+func TestProblemTracker_AccessDeniedIsImmediate(t *testing.T) {
+	n := &mockNotifier{}
+	tr := newProblemTracker(n, 3, "", testLogger)
+
+	accessErr := fmt.Errorf("fetching reminders: %w", model.ErrAccessDenied)
+	tr.observe(context.Background(), []string{"Shopping"}, Stats{}, accessErr)
+
+	if len(n.created) != 1 || !strings.Contains(n.created[0], "Privacy & Security") {
+		t.Errorf("created = %v, want immediate Reminders access notification", n.created)
+	}
+}
+
+func TestProblemTracker_InstanceHasOwnNotification(t *testing.T) {
+	n := &mockNotifier{}
+	tr := newProblemTracker(n, 3, "work", testLogger)
+
+	authErr := fmt.Errorf("get items: %w", model.ErrUnauthorized)
+	tr.observe(context.Background(), []string{"Shopping"}, Stats{}, authErr)
+
+	if len(n.ids) != 1 || n.ids[0] != problemNotificationID+"_work" {
+		t.Errorf("notification IDs = %v, want [%s_work]", n.ids, problemNotificationID)
+	}
+}
@@ -0,0 +1,58 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+)
+
+// progressInterval is how often a bulk operation reports its progress at
+// most. The last item is always reported.
+const progressInterval = 2 * time.Second
+
+// progress reports how far a bulk operation over many items has come, such
+// as the pushes of a bootstrap, as lines like
+//
+//	312/1500 pushed (Groceries), about 3m10s left
+//
+// The remaining time is estimated from the average time per item so far.
+type progress struct {
+	w     io.Writer
+	clock clock.Clock
+	verb  string // what is done to each item, as in "pushed"
+	total int
+	done  int
+	start time.Time
+	last  time.Time // when progress was last reported
+}
+
+// newProgress starts reporting to w on an operation over total items. A nil
+// w reports nothing.
+func newProgress(w io.Writer, clk clock.Clock, verb string, total int) *progress {
+	now := clk.Now()
+	return &progress{w: w, clock: clk, verb: verb, total: total, start: now, last: now}
+}
+
+// step counts one more item of listName as done and reports the progress
+// if progressInterval has passed since the last report, or if it was the
+// last item.
+func (p *progress) step(listName string) {
+	p.done++
+	if p.w == nil {
+		return
+	}
+	now := p.clock.Now()
+	elapsed := now.Sub(p.start)
+	switch {
+	case p.done >= p.total:
+		_, _ = fmt.Fprintf(p.w, "  %d/%d %s in %s\n", p.done, p.total, p.verb, elapsed.Round(time.Second))
+	case now.Sub(p.last) >= progressInterval:
+		left := elapsed / time.Duration(p.done) * time.Duration(p.total-p.done)
+		_, _ = fmt.Fprintf(p.w, "  %d/%d %s (%s), about %s left\n", p.done, p.total, p.verb, listName, left.Round(time.Second))
+	default:
+		return
+	}
+	p.last = now
+}
@@ -0,0 +1,45 @@
+package sync
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+)
+
+func TestProgress_ReportsEveryIntervalAndAtEnd(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	var out bytes.Buffer
+	p := newProgress(&out, clk, "pushed", 4)
+
+	clk.Advance(time.Second)
+	p.step("Shopping")
+	if out.Len() != 0 {
+		t.Fatalf("reported before the interval passed: %q", out.String())
+	}
+	clk.Advance(time.Second)
+	p.step("Shopping")
+	if got, want := out.String(), "  2/4 pushed (Shopping), about 2s left\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+	out.Reset()
+	clk.Advance(time.Second)
+	p.step("Work")
+	if out.Len() != 0 {
+		t.Fatalf("reported twice within the interval: %q", out.String())
+	}
+	clk.Advance(time.Second)
+	p.step("Work")
+	if got, want := out.String(), "  4/4 pushed in 4s\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestProgress_NilWriter(t *testing.T) {
+	p := newProgress(nil, clock.NewFake(time.Now()), "pushed", 1)
+	p.step("Shopping") // must not panic
+	if p.done != 1 {
+		t.Errorf("done = %d, want 1", p.done)
+	}
+}
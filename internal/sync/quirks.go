@@ -0,0 +1,130 @@
+package sync
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// Quirks adjusts how the reconciler treats a target whose integration
+// behaves unlike HA's Local To-do, such as one backed by a cloud service.
+type Quirks struct {
+	// UnstableUIDs says the target may give an item a new UID, for example
+	// when the service recreates it on every edit. A tracked item whose UID
+	// is gone is re-linked to the one untracked item with its title, even if
+	// its other fields changed, instead of being deleted from Reminders and
+	// created again as a duplicate. Other targets re-link only items whose
+	// due date and description match too.
+	UnstableUIDs bool
+
+	// Drops names the fields the target does not keep, or rewrites. They
+	// are taken from the Reminders side, as for fields a [FieldLimiter]
+	// reports unsupported.
+	Drops model.Fields
+}
+
+// quirkPresets are the named quirks that list mappings can select.
+var quirkPresets = map[string]Quirks{
+	"local_todo": {},
+	"todoist":    {UnstableUIDs: true, Drops: model.FieldPriority},
+	"bring":      {UnstableUIDs: true, Drops: model.FieldDescription | model.FieldDueDate | model.FieldPriority},
+	"anylist":    {UnstableUIDs: true, Drops: model.FieldDescription | model.FieldDueDate | model.FieldPriority},
+}
+
+// QuirksPreset returns the quirks named preset, such as "todoist".
+func QuirksPreset(preset string) (Quirks, error) {
+	q, ok := quirkPresets[preset]
+	if !ok {
+		return Quirks{}, fmt.Errorf("unknown quirks preset %q (known: %s)", preset, strings.Join(QuirksPresets(), ", "))
+	}
+	return q, nil
+}
+
+// QuirksPresets returns the names of the quirks presets, sorted.
+func QuirksPresets() []string {
+	names := make([]string, 0, len(quirkPresets))
+	for name := range quirkPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithQuirks applies quirks to list mapping targets, keyed by target.
+// Targets without an entry are treated as well behaved.
+func WithQuirks(quirks map[string]Quirks) ReconcilerOption {
+	return func(r *Reconciler) { r.quirks = quirks }
+}
+
+// relink points tracked items whose target UID is gone at the one untracked
+// target item that looks the same, recording the new UID instead of letting
+// the planning see a deletion and a new item. Some integrations give every
+// item a new UID when HA restarts. An item looks the same if its title, due
+// date, and description match the last synced version, counting only the
+// fields the target keeps; under quirks.UnstableUIDs the title alone must
+// match, as such a target may rewrite the rest. Items with no such item, or
+// several, are left for the usual planning.
+func (r *Reconciler) relink(ctx context.Context, stateItems []*state.Item, haByUID, remByUID map[string]*model.Item, quirks Quirks) error {
+	key := func(title string, content *model.Item) string {
+		if quirks.UnstableUIDs {
+			return titleKey(title)
+		}
+		return relinkKey(title, content, quirks.Drops)
+	}
+
+	tracked := make(map[string]bool, len(stateItems))
+	for _, si := range stateItems {
+		if si.HAUID != "" {
+			tracked[si.HAUID] = true
+		}
+	}
+	untracked := make(map[string][]*model.Item)
+	for uid, item := range haByUID {
+		if !tracked[uid] {
+			k := key(item.Title, item)
+			untracked[k] = append(untracked[k], item)
+		}
+	}
+
+	for _, si := range stateItems {
+		if si.HAUID == "" || si.RemindersUID == "" || haByUID[si.HAUID] != nil {
+			continue
+		}
+		content := cmp.Or(si.Base, remByUID[si.RemindersUID])
+		if content == nil && !quirks.UnstableUIDs {
+			continue
+		}
+		k := key(si.Title, content)
+		if len(untracked[k]) != 1 {
+			continue
+		}
+		oldUID := si.HAUID
+		si.HAUID = untracked[k][0].UID
+		delete(untracked, k)
+		if err := r.store.UpsertItem(ctx, si); err != nil {
+			return fmt.Errorf("re-linking %q: %w", si.Title, err)
+		}
+		r.log.Info("re-linked item whose target UID changed",
+			"title", si.Title, "old_uid", oldUID, "new_uid", si.HAUID)
+	}
+	return nil
+}
+
+// relinkKey identifies an item titled title with the due date and
+// description of content, leaving out those in drops.
+func relinkKey(title string, content *model.Item, drops model.Fields) string {
+	var due, desc string
+	if content.DueDate != nil && !drops.Has(model.FieldDueDate) {
+		due = content.DueDate.UTC().Format(time.RFC3339)
+	}
+	if !drops.Has(model.FieldDescription) {
+		desc = content.Description
+	}
+	return titleKey(title) + "\x00" + due + "\x00" + desc
+}
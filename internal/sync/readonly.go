@@ -0,0 +1,37 @@
+package sync
+
+// WithReadOnly makes every pass plan as usual but write to neither side:
+// each change is logged as what the pass would do and counted in its
+// [Stats], so history and metrics show what syncing would have changed. The
+// state DB keeps its rows as they were, so a pending change is reported
+// again on every pass until it is synced. Queued writes are not replayed,
+// and lists not bootstrapped yet are skipped, as their items would be
+// linked first.
+func WithReadOnly() ReconcilerOption {
+	return func(r *Reconciler) { r.readOnly = true }
+}
+
+// readOnlyPass logs the ops of plan for listName as a read-only pass and
+// tallies them as if they had been applied.
+func (r *Reconciler) readOnlyPass(listName string, plan listPlan) Stats {
+	stats := Stats{Duplicates: plan.dups}
+	if plan.tracked == 0 && len(plan.ops) > 0 {
+		r.log.Info("read-only: list not bootstrapped, not planning it", "list", listName, "items", len(plan.ops))
+		return stats
+	}
+	for _, op := range plan.ops {
+		r.log.Info("read-only: would apply", "list", listName, "action", op.act, "title", op.title())
+		switch op.act {
+		case actionCreateInHA, actionCreateInRem, actionRestoreHA, actionRestoreRem:
+			stats.Created++
+		case actionUpdateHA, actionUpdateRem, actionMerge:
+			stats.Updated++
+			if op.conflict {
+				stats.Conflicts++
+			}
+		case actionDeleteFromHA, actionDeleteFromRem:
+			stats.Deleted++
+		}
+	}
+	return stats
+}
@@ -1,13 +1,19 @@
 package sync
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sync"
 	"time"
 
+	"github.com/njoerd114/reminderrelay/internal/clock"
 	"github.com/njoerd114/reminderrelay/internal/model"
 	"github.com/njoerd114/reminderrelay/internal/state"
+	"golang.org/x/sync/errgroup"
 )
 
 // action describes a single mutation the reconciler wants to perform.
@@ -21,8 +27,36 @@ const (
 	actionUpdateRem           // HA is the winner → push to Reminders
 	actionDeleteFromHA        // item deleted from Reminders → remove from HA
 	actionDeleteFromRem       // item deleted from HA → remove from Reminders
+	actionMerge               // both sides changed → push the three-way merge to both
+	actionRestoreHA           // pinned to Reminders, deleted from HA → re-create in HA
+	actionRestoreRem          // pinned to HA, deleted from Reminders → re-create in Reminders
 )
 
+// String returns the name used in logs and the outbox.
+func (a action) String() string {
+	switch a {
+	case actionCreateInHA:
+		return "create_in_ha"
+	case actionCreateInRem:
+		return "create_in_reminders"
+	case actionUpdateHA:
+		return "update_ha"
+	case actionUpdateRem:
+		return "update_reminders"
+	case actionDeleteFromHA:
+		return "delete_from_ha"
+	case actionDeleteFromRem:
+		return "delete_from_reminders"
+	case actionMerge:
+		return "merge"
+	case actionRestoreHA:
+		return "restore_ha"
+	case actionRestoreRem:
+		return "restore_reminders"
+	}
+	return "none"
+}
+
 // Stats tracks the number of mutations performed in a single reconcile pass.
 type Stats struct {
 	Created  int
@@ -30,39 +64,285 @@ type Stats struct {
 	Deleted  int
 	Conflicts int
 	Errors   int
+
+	// ListErrors holds the first error of every list that did not reconcile
+	// cleanly, keyed by Reminders list name. Nil when all lists succeeded.
+	ListErrors map[string]error
+
+	// Resolved describes every conflict applied during the pass.
+	Resolved []Conflict
+
+	// Latencies holds the propagation latency of every change written
+	// during the pass.
+	Latencies []Latency
+
+	// Duplicates lists the titles shared by several items of a list.
+	Duplicates []Duplicate
+
+	// Lists breaks the pass down by list, one entry per list reconciled.
+	Lists []ListStats
+}
+
+// ListStats is the share of a pass of one list mapping.
+type ListStats struct {
+	ListName  string
+	Target    string // the mapping's target, such as an HA entity ID
+	Created   int
+	Updated   int
+	Deleted   int
+	Conflicts int
+	Errors    int
+	Duration  time.Duration
+}
+
+// Conflict describes an item edited on both sides since the last sync whose
+// edits overlapped, and how it was resolved.
+type Conflict struct {
+	ListName string
+	// Fields names the fields edited differently on both sides, or is nil
+	// when the whole item was resolved by last-write-wins.
+	Fields []string
+	// Reminders and HA are both versions as found before resolution.
+	Reminders model.Item
+	HA        model.Item
+	// Result is the content written to both sides.
+	Result model.Item
+	// RemindersWon is true when Reminders' values were kept for the
+	// conflicting fields.
+	RemindersWon bool
+}
+
+// add accumulates o into s.
+func (s *Stats) add(o Stats) {
+	s.Created += o.Created
+	s.Updated += o.Updated
+	s.Deleted += o.Deleted
+	s.Conflicts += o.Conflicts
+	s.Errors += o.Errors
+	s.Resolved = append(s.Resolved, o.Resolved...)
+	s.Latencies = append(s.Latencies, o.Latencies...)
+	s.Duplicates = append(s.Duplicates, o.Duplicates...)
+	s.Lists = append(s.Lists, o.Lists...)
+	for list, err := range o.ListErrors {
+		s.recordListError(list, err)
+	}
+}
+
+// recordListError remembers err as the failure for listName.
+func (s *Stats) recordListError(listName string, err error) {
+	if s.ListErrors == nil {
+		s.ListErrors = make(map[string]error)
+	}
+	s.ListErrors[listName] = err
+}
+
+// plannedOp is a single mutation the reconciler intends to perform. si is nil
+// for items that are not yet tracked in the state DB; merged is set only for
+// actionMerge.
+type plannedOp struct {
+	act      action
+	si       *state.Item
+	rem      *model.Item
+	ha       *model.Item
+	merged   *model.Item
+	conflict bool
+	fields   []string // conflicting fields of an actionMerge
+}
+
+// target is the resolved target side of a list mapping: the backend holding
+// the list and the backend's identifier for it.
+type target struct {
+	backend TaskBackend
+	list    string
+}
+
+// title returns the best available display title for the operation.
+func (op plannedOp) title() string {
+	switch {
+	case op.si != nil:
+		return op.si.Title
+	case op.rem != nil:
+		return op.rem.Title
+	case op.ha != nil:
+		return op.ha.Title
+	}
+	return ""
+}
+
+// resolution describes how a conflicting op was resolved. Only valid for
+// update and merge ops with conflict set.
+func (op plannedOp) resolution() Conflict {
+	c := Conflict{
+		ListName:  op.si.ListName,
+		Fields:    op.fields,
+		Reminders: *op.rem,
+		HA:        *op.ha,
+	}
+	switch op.act {
+	case actionMerge:
+		c.Result = *op.merged
+		c.RemindersWon = !op.rem.ModifiedAt.Before(op.ha.ModifiedAt)
+	case actionUpdateRem:
+		c.Result = *op.ha
+	default:
+		c.Result = *op.rem
+		c.RemindersWon = true
+	}
+	return c
+}
+
+// ReconcilerOption configures optional Reconciler behaviour.
+type ReconcilerOption func(*Reconciler)
+
+// WithShadow runs the given lists in shadow mode: each pass is planned and
+// recorded in the state DB but nothing is written to either side. After
+// passes shadow passes the list is promoted automatically when autoPromote
+// is true; otherwise it waits for an explicit promotion.
+func WithShadow(lists []string, passes int, autoPromote bool) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.shadowLists = make(map[string]bool, len(lists))
+		for _, l := range lists {
+			r.shadowLists[l] = true
+		}
+		r.shadowPasses = passes
+		r.shadowAutoPromote = autoPromote
+	}
+}
+
+// inShadow reports whether listName runs in shadow mode.
+func (r *Reconciler) inShadow(listName string) bool {
+	r.shadowMu.Lock()
+	defer r.shadowMu.Unlock()
+	return r.shadowLists[listName]
+}
+
+// renameList carries the per-list settings of oldName over to newName after
+// the list was renamed in Reminders.
+func (r *Reconciler) renameList(oldName, newName string) {
+	r.shadowMu.Lock()
+	defer r.shadowMu.Unlock()
+	if r.shadowLists[oldName] {
+		delete(r.shadowLists, oldName)
+		r.shadowLists[newName] = true
+	}
+}
+
+// WithParallelism reconciles up to n lists of a pass at a time. Lists are
+// reconciled one after another by default.
+func WithParallelism(n int) ReconcilerOption {
+	return func(r *Reconciler) { r.parallelism = n }
+}
+
+// WithPassTimeout abandons a pass still running d after it started reading
+// its lists, leaving the changes it did not write to the next pass. Zero
+// disables the limit.
+func WithPassTimeout(d time.Duration) ReconcilerOption {
+	return func(r *Reconciler) { r.passTimeout = d }
+}
+
+// WithClock replaces the wall clock used for sync timestamps and, via
+// [NewEngine], for the polling schedule. Intended for tests.
+func WithClock(c clock.Clock) ReconcilerOption {
+	return func(r *Reconciler) { r.clock = c }
+}
+
+// WithInstance labels the reconciler, and any [Engine] built from it, with
+// instance so that log lines, spans, and metrics from several sync sets in
+// one process can be told apart. Pair it with a store from
+// [state.Store.ForInstance] to keep their state rows apart as well.
+func WithInstance(instance string) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.instance = instance
+		r.log = r.log.With("instance", instance)
+	}
 }
 
 // Reconciler performs a single bidirectional sync pass across all configured
-// list mappings. It is stateless between calls — all persistent state lives
-// in the [StateStore].
+// list mappings. All persistent state lives in the [StateStore]; between
+// calls the reconciler only remembers when changes whose write failed were
+// first seen, for [Stats.Latencies], and what each list's items looked like
+// on both sides at its last clean reconcile, so that unchanged lists are
+// skipped.
+//
+// Every list mapping pairs a Reminders list with a target, which targets
+// resolves to the backend holding it; see [Registry].
+//
+// Its methods are safe for concurrent use. A pass waits for other passes over
+// any of its lists to finish before it reads them.
 type Reconciler struct {
-	rem   RemindersSource
-	ha    HASource
-	store StateStore
-	log   *slog.Logger
+	rem     TaskBackend
+	targets *Registry
+	store   StateStore
+	log     *slog.Logger
+	clock   clock.Clock
+
+	instance string
+
+	shadowMu          sync.Mutex
+	shadowLists       map[string]bool // guarded by shadowMu; see renameList
+	shadowPasses      int
+	shadowAutoPromote bool
+
+	guard       *deletionGuard // nil without WithDeletionGuard
+	parallelism int            // lists reconciled at once; see WithParallelism
+	passTimeout time.Duration  // zero for no limit; see WithPassTimeout
+
+	shutdownGrace time.Duration // see WithShutdownGrace
+
+	quirks   map[string]Quirks // target → quirks; see WithQuirks
+	readOnly bool              // see WithReadOnly
+	explain  bool              // see WithExplain
+
+	normalization Normalization // see WithNormalization
+	ignore        IgnoreMarkers // see WithIgnoreMarkers
+
+	shorthand    map[string]bool // Reminders lists parsing shorthand; see WithShorthand
+	shorthandLoc *time.Location  // see WithShorthand
+
+	mirrors      map[string][]string            // Reminders list → mirror targets; see WithMirrors
+	mirrorStores func(target string) StateStore // see WithMirrors
+
+	pendingMu sync.Mutex
+	pending   map[string]time.Time // change key → first seen; see deferred
+
+	digestMu sync.Mutex
+	digests  map[string]listDigest // Reminders list → items at its last clean reconcile
+
+	unavailMu   sync.Mutex
+	unavailable map[string]*unavailability // Reminders list → its unavailable target; see markUnavailable
+
+	locksMu   sync.Mutex
+	listLocks map[string]*sync.Mutex // Reminders list → held by the pass syncing it; see lockLists
 }
 
-// NewReconciler creates a Reconciler wired to the given adapters and state store.
-func NewReconciler(rem RemindersSource, ha HASource, store StateStore, logger *slog.Logger) *Reconciler {
-	return &Reconciler{rem: rem, ha: ha, store: store, log: logger}
+// NewReconciler creates a Reconciler that syncs the Reminders backend rem with
+// the list mapping targets in targets, keeping state in store.
+func NewReconciler(rem TaskBackend, targets *Registry, store StateStore, logger *slog.Logger, opts ...ReconcilerOption) *Reconciler {
+	r := &Reconciler{rem: rem, targets: targets, store: store, log: logger, clock: clock.Real()}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // Run performs a full bidirectional sync for all list mappings. It returns
 // aggregate statistics and the first error encountered (sync continues past
 // individual item errors to maximise progress).
 func (r *Reconciler) Run(ctx context.Context, listMappings map[string]string) (Stats, error) {
-	var stats Stats
-	var firstErr error
+	seen := r.clock.Now()
 
 	listNames := make([]string, 0, len(listMappings))
 	for name := range listMappings {
 		listNames = append(listNames, name)
 	}
+	defer r.lockLists(listNames)()
+	ctx, cancel := r.passContext(ctx)
+	defer cancel()
 
 	// 1. Fetch all Reminders items across configured lists.
-	remItems, err := r.rem.FetchAll(ctx, listNames)
+	remItems, err := r.rem.Fetch(ctx, listNames)
 	if err != nil {
-		return stats, fmt.Errorf("fetching reminders: %w", err)
+		return Stats{}, fmt.Errorf("fetching reminders: %w", err)
 	}
 
 	// Index Reminders items by UID for fast lookup.
@@ -72,17 +352,7 @@ func (r *Reconciler) Run(ctx context.Context, listMappings map[string]string) (S
 	}
 
 	// 2. Process each list mapping independently.
-	for listName, entityID := range listMappings {
-		ls, err := r.reconcileList(ctx, listName, entityID, remByUID)
-		stats.Created += ls.Created
-		stats.Updated += ls.Updated
-		stats.Deleted += ls.Deleted
-		stats.Conflicts += ls.Conflicts
-		stats.Errors += ls.Errors
-		if err != nil && firstErr == nil {
-			firstErr = err
-		}
-	}
+	stats, firstErr := r.reconcileLists(ctx, listMappings, listNames, remByUID, seen)
 
 	r.log.Info("reconcile complete",
 		"created", stats.Created,
@@ -95,11 +365,63 @@ func (r *Reconciler) Run(ctx context.Context, listMappings map[string]string) (S
 	return stats, firstErr
 }
 
+// reconcileLists reconciles listNames, up to r.parallelism lists at a time,
+// and aggregates their stats. The first error is that of the first failed
+// list in name order.
+func (r *Reconciler) reconcileLists(ctx context.Context, listMappings map[string]string, listNames []string, remByUID map[string]*model.Item, seen time.Time) (Stats, error) {
+	names := slices.Sorted(slices.Values(listNames))
+	results := make([]Stats, len(names))
+	errs := make([]error, len(names))
+
+	var g errgroup.Group
+	g.SetLimit(max(r.parallelism, 1))
+	for i, listName := range names {
+		g.Go(func() error {
+			// A failed list must not stop the others, so errors are
+			// collected rather than returned to the group.
+			results[i], errs[i] = r.syncList(ctx, listName, listMappings[listName], remByUID, seen)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	var stats Stats
+	var firstErr error
+	for i, listName := range names {
+		stats.add(results[i])
+		if err := errs[i]; err != nil {
+			stats.recordListError(listName, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return stats, firstErr
+}
+
+// passContext returns ctx limited to the pass timeout. Passes call it once
+// they hold their list locks, so time spent waiting for another pass does not
+// count.
+func (r *Reconciler) passContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.passTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, r.passTimeout)
+}
+
 // ReconcileEntity performs reconciliation for a single HA entity. Called by
 // the WebSocket listener when a state_changed event is received.
 func (r *Reconciler) ReconcileEntity(ctx context.Context, listName, entityID string) (Stats, error) {
+	defer r.lockLists([]string{listName})()
+	ctx, cancel := r.passContext(ctx)
+	defer cancel()
+	seen := r.clock.Now()
+
+	// The entity changed, so it may be available again.
+	r.retryUnavailable(listName)
+
 	// We need the Reminders items for just this list.
-	remItems, err := r.rem.FetchAll(ctx, []string{listName})
+	remItems, err := r.rem.Fetch(ctx, []string{listName})
 	if err != nil {
 		return Stats{}, fmt.Errorf("fetching reminders for %q: %w", listName, err)
 	}
@@ -109,41 +431,150 @@ func (r *Reconciler) ReconcileEntity(ctx context.Context, listName, entityID str
 		remByUID[item.UID] = item
 	}
 
-	return r.reconcileList(ctx, listName, entityID, remByUID)
+	return r.syncList(ctx, listName, entityID, remByUID, seen)
 }
 
-// reconcileList performs bidirectional sync for a single list ↔ entity pair.
-func (r *Reconciler) reconcileList(ctx context.Context, listName, entityID string, remByUID map[string]*model.Item) (Stats, error) {
-	var stats Stats
-	var firstErr error
+// reconcileList performs bidirectional sync for a single list ↔ target pair.
+// seen is when the pass started, the time its changes count as observed.
+// A list whose target is unavailable is skipped, without an error; see
+// markUnavailable.
+func (r *Reconciler) reconcileList(ctx context.Context, listName, targetName string, remByUID map[string]*model.Item, seen time.Time) (stats Stats, err error) {
+	if r.skipUnavailable(listName) {
+		r.log.Debug("skipping list with unavailable target", "list", listName, "entity", targetName)
+		return Stats{}, nil
+	}
+	r.log.Debug("reconciling list", "list", listName, "entity", targetName)
+	started := r.clock.Now()
+	digest := listDigest{rem: remindersDigest(listName, remByUID)}
+	unavailable := false
+	defer func() {
+		r.recordDigest(listName, digest, err == nil && !unavailable)
+		stats.Lists = []ListStats{{
+			ListName:  listName,
+			Target:    targetName,
+			Created:   stats.Created,
+			Updated:   stats.Updated,
+			Deleted:   stats.Deleted,
+			Conflicts: stats.Conflicts,
+			Errors:    stats.Errors,
+			Duration:  r.clock.Now().Sub(started),
+		}}
+	}()
+
+	tgt, err := r.resolveTarget(targetName, listName)
+	if err != nil {
+		return Stats{}, err
+	}
+	haItems, err := tgt.backend.Fetch(ctx, []string{tgt.list})
+	if errors.Is(err, model.ErrListUnavailable) {
+		// Its items are not gone, so nothing may be planned from them.
+		r.markUnavailable(listName, targetName, err)
+		unavailable = true
+		return Stats{}, nil
+	}
+	if err != nil {
+		return Stats{}, fmt.Errorf("fetching HA items for %s: %w", tgt.list, err)
+	}
+	r.markAvailable(listName, targetName)
+	if err := r.settleIntents(ctx, listName, haItems, remByUID); err != nil {
+		return Stats{}, err
+	}
+
+	// A list whose items are unchanged on both sides since its last clean
+	// reconcile needs nothing done. Shadow lists count every pass.
+	digest.target = itemsDigest(haItems)
+	if last, ok := r.lastDigest(listName); ok && last.rem == digest.rem && last.target == digest.target && !r.inShadow(listName) {
+		r.log.Debug("list unchanged since its last reconcile", "list", listName)
+		digest.dups = last.dups
+		return Stats{Duplicates: last.dups}, nil
+	}
+
+	haItems, remByUID, err = r.skipIgnored(ctx, listName, tgt, haItems, remByUID)
+	if err != nil {
+		return Stats{}, fmt.Errorf("skipping ignored items of %q: %w", listName, err)
+	}
+
+	quirks := r.quirks[targetName]
+	quirks.Drops |= unsupportedFields(tgt.backend, tgt.list)
+	plan, err := r.planList(ctx, listName, haItems, remByUID, quirks)
+	if err != nil {
+		return Stats{}, err
+	}
+	digest.dups = plan.dups
+
+	if r.inShadow(listName) {
+		applyNow, err := r.shadowPass(ctx, listName, plan.ops)
+		if err != nil || !applyNow {
+			return Stats{Duplicates: plan.dups}, err
+		}
+	}
+
+	if err := r.guard.check(listName, plan.ops, plan.tracked); err != nil {
+		r.log.Error("list not synced: too many deletions, the other side may have returned an incomplete list",
+			"list", listName, "error", err)
+		return Stats{Duplicates: plan.dups}, err
+	}
 
-	r.log.Debug("reconciling list", "list", listName, "entity", entityID)
+	if r.readOnly {
+		return r.readOnlyPass(listName, plan), nil
+	}
 
-	// Fetch HA items for this entity.
-	haItems, err := r.ha.GetItems(ctx, entityID)
+	ops, box, err := r.replayQueued(ctx, listName, plan.ops)
 	if err != nil {
-		return stats, fmt.Errorf("fetching HA items for %s: %w", entityID, err)
+		return Stats{Duplicates: plan.dups}, err
 	}
+	stats, err = r.apply(ctx, ops, tgt, box, seen)
+	stats.Duplicates = plan.dups
+	return stats, err
+}
+
+// resolveTarget looks up the backend serving a list mapping target for the
+// Reminders list listName.
+func (r *Reconciler) resolveTarget(targetName, listName string) (target, error) {
+	b, list, err := r.targets.resolve(targetName, listName)
+	if err != nil {
+		return target{}, err
+	}
+	return target{backend: b, list: list}, nil
+}
+
+// listPlan is what a pass intends to do with one list.
+type listPlan struct {
+	ops     []plannedOp
+	dups    []Duplicate // titles the list has more than once
+	tracked int         // state rows of the list
+}
 
-	// Index HA items by UID.
+// planList fetches the state DB view of a list and decides what to do with
+// every item of haItems, the target's items, and remByUID, without mutating
+// anything but the UIDs of relinked items (see relink). Tracked target items
+// take the fields quirks drops from their Reminders counterpart.
+func (r *Reconciler) planList(ctx context.Context, listName string, haItems []*model.Item, remByUID map[string]*model.Item, quirks Quirks) (listPlan, error) {
+	// Index target items by UID.
 	haByUID := make(map[string]*model.Item, len(haItems))
-	for i := range haItems {
-		haItems[i].ListName = listName
-		haByUID[haItems[i].UID] = &haItems[i]
+	for _, item := range haItems {
+		item.ListName = listName
+		haByUID[item.UID] = item
 	}
 
 	// Fetch all tracked state items for this list.
 	stateItems, err := r.store.GetAllItemsForList(ctx, listName)
 	if err != nil {
-		return stats, fmt.Errorf("fetching state items for %q: %w", listName, err)
+		return listPlan{}, fmt.Errorf("fetching state items for %q: %w", listName, err)
 	}
+	if err := r.relink(ctx, stateItems, haByUID, remByUID, quirks); err != nil {
+		return listPlan{}, err
+	}
+	r.normalizeTitles(listName, stateItems, remByUID, haByUID)
 
 	// Build a set of state RemindersUIDs and HAUIDs we've processed,
 	// so we can detect new items after processing tracked ones.
 	processedRemUIDs := make(map[string]bool, len(stateItems))
 	processedHAUIDs := make(map[string]bool, len(stateItems))
 
-	// 1. Process items we're already tracking.
+	var ops []plannedOp
+
+	// 1. Plan items we're already tracking.
 	for _, si := range stateItems {
 		remItem := remByUID[si.RemindersUID]
 		haItem := haByUID[si.HAUID]
@@ -155,100 +586,260 @@ func (r *Reconciler) reconcileList(ctx context.Context, listName, entityID strin
 			processedHAUIDs[si.HAUID] = true
 		}
 
-		act := r.decide(si, remItem, haItem)
-		oldHash := si.LastSyncHash // capture before execute modifies si
-		if err := r.execute(ctx, act, si, remItem, haItem, entityID); err != nil {
-			r.log.Error("sync action failed",
-				"action", act,
-				"title", si.Title,
-				"error", err,
-			)
-			stats.Errors++
-			if firstErr == nil {
-				firstErr = err
+		if remItem != nil && haItem != nil {
+			copyFields(haItem, remItem, quirks.Drops)
+			if err := r.stampHA(ctx, si, haItem); err != nil {
+				return listPlan{}, err
 			}
+			stampCompletion(si, remItem, haItem)
+		}
+
+		act, why := r.decideWhy(si, remItem, haItem)
+		if r.explain {
+			r.explainItem(listName, si, remItem, haItem, act, why)
+		}
+		if act == actionNone {
 			continue
 		}
 
-		switch act {
-		case actionCreateInHA, actionCreateInRem:
-			stats.Created++
-		case actionUpdateHA, actionUpdateRem:
-			stats.Updated++
-			// Check if this was a conflict (both sides changed).
-			if remItem != nil && haItem != nil {
-				remHash := remItem.ContentHash()
-				haHash := haItem.ContentHash()
-				if remHash != oldHash && haHash != oldHash {
-					stats.Conflicts++
-				}
+		op := plannedOp{act: act, si: si, rem: remItem, ha: haItem}
+		switch {
+		case act == actionMerge:
+			op.merged, op.fields = mergeFields(si.Base, remItem, haItem)
+			op.conflict = len(op.fields) > 0
+			if op.conflict {
+				r.log.Info("conflicting field edits resolved by last-write-wins",
+					"title", si.Title,
+					"fields", op.fields,
+				)
 			}
-		case actionDeleteFromHA, actionDeleteFromRem:
-			stats.Deleted++
+		case (act == actionUpdateHA || act == actionUpdateRem) && remItem != nil && haItem != nil:
+			// Both sides changed since the last sync and there is no base
+			// to merge against → whole-item conflict.
+			op.conflict = remItem.ContentHash() != si.LastSyncHash && haItem.ContentHash() != si.LastSyncHash
 		}
+		ops = append(ops, op)
 	}
 
-	// 2. Detect new Reminders items not in state DB → create in HA.
+	// 2. New Reminders items not in state DB → create in HA.
 	for uid, remItem := range remByUID {
-		if remItem.ListName != listName {
+		if remItem.ListName != listName || processedRemUIDs[uid] {
 			continue
 		}
-		if processedRemUIDs[uid] {
+		ops = append(ops, plannedOp{act: actionCreateInHA, rem: remItem})
+	}
+
+	// 3. New HA items not in state DB → create in Reminders.
+	for uid, haItem := range haByUID {
+		if processedHAUIDs[uid] {
 			continue
 		}
+		ops = append(ops, plannedOp{act: actionCreateInRem, ha: haItem})
+	}
 
-		r.log.Info("new reminder detected", "title", remItem.Title, "uid", uid)
-		if err := r.createInHA(ctx, remItem, entityID); err != nil {
-			r.log.Error("failed to create in HA", "title", remItem.Title, "error", err)
-			stats.Errors++
-			if firstErr == nil {
-				firstErr = err
-			}
-			continue
+	var remItems []*model.Item
+	for _, item := range remByUID {
+		if item.ListName == listName {
+			remItems = append(remItems, item)
 		}
-		stats.Created++
 	}
+	return listPlan{ops: ops, dups: findDuplicates(listName, remItems, haItems), tracked: len(stateItems)}, nil
+}
 
-	// 3. Detect new HA items not in state DB → create in Reminders.
-	for uid, haItem := range haByUID {
-		if processedHAUIDs[uid] {
-			continue
+// stampHA sets the modification time of haItem for targets that report
+// none, such as Home Assistant: the time its content was first seen
+// differing from the last sync, recorded in si so that it survives passes
+// that do not sync the item. Without it, Reminders would win every conflict.
+func (r *Reconciler) stampHA(ctx context.Context, si *state.Item, haItem *model.Item) error {
+	if !haItem.ModifiedAt.IsZero() {
+		return nil
+	}
+	hash := haItem.ContentHash()
+	if hash == si.LastSyncHash || hash == si.HASeenHash {
+		haItem.ModifiedAt = si.HAModified
+		return nil
+	}
+	si.HAModified = r.clock.Now().UTC()
+	si.HASeenHash = hash
+	haItem.ModifiedAt = si.HAModified
+	if err := r.store.UpsertItem(ctx, si); err != nil {
+		return fmt.Errorf("recording HA change of %q: %w", si.Title, err)
+	}
+	return nil
+}
+
+// stampCompletion sets the completion time of a completed haItem whose
+// target does not report one, such as Home Assistant: that of remItem or the
+// last synced content if they were completed too, otherwise the time the
+// change was first seen, as stamped by [Reconciler.stampHA].
+func stampCompletion(si *state.Item, remItem, haItem *model.Item) {
+	if !haItem.Completed || haItem.CompletedAt != nil {
+		return
+	}
+	switch {
+	case remItem.Completed && remItem.CompletedAt != nil:
+		haItem.CompletedAt = remItem.CompletedAt
+	case si.Base != nil && si.Base.Completed && si.Base.CompletedAt != nil:
+		haItem.CompletedAt = si.Base.CompletedAt
+	case !haItem.ModifiedAt.IsZero():
+		t := haItem.ModifiedAt
+		haItem.CompletedAt = &t
+	}
+}
+
+// apply executes planned operations in order and tallies the results.
+// Individual failures are logged and counted; the first one is returned.
+// Failed target writes are queued in box for replay. Once ctx ends, apply
+// returns between items; see [WithShutdownGrace].
+func (r *Reconciler) apply(ctx context.Context, ops []plannedOp, tgt target, box *outbox, seen time.Time) (Stats, error) {
+	var stats Stats
+	var firstErr error
+
+	for i, op := range ops {
+		if err := ctx.Err(); err != nil {
+			// The next pass plans the remaining ops again.
+			for _, rest := range ops[i:] {
+				r.deferred(rest, seen)
+			}
+			return stats, cmp.Or(firstErr, err)
+		}
+		switch op.act {
+		case actionCreateInHA:
+			r.log.Info("new reminder detected", "title", op.rem.Title, "uid", op.rem.UID)
+		case actionCreateInRem:
+			r.log.Info("new HA item detected", "title", op.ha.Title, "uid", op.ha.UID)
 		}
 
-		r.log.Info("new HA item detected", "title", haItem.Title, "uid", uid)
-		if err := r.createInReminders(ctx, haItem, entityID); err != nil {
-			r.log.Error("failed to create in Reminders", "title", haItem.Title, "error", err)
+		itemCtx, cancel := r.itemContext(ctx)
+		err := r.executeIntent(itemCtx, op, tgt)
+		r.settle(itemCtx, box, op, err)
+		cancel()
+		if err != nil {
+			r.log.Error("sync action failed",
+				"action", op.act,
+				"title", op.title(),
+				"error", err,
+			)
 			stats.Errors++
 			if firstErr == nil {
 				firstErr = err
 			}
+			r.deferred(op, seen)
 			continue
 		}
-		stats.Created++
+		stats.Latencies = append(stats.Latencies, r.propagated(op, seen)...)
+
+		switch op.act {
+		case actionCreateInHA, actionCreateInRem, actionRestoreHA, actionRestoreRem:
+			stats.Created++
+		case actionUpdateHA, actionUpdateRem, actionMerge:
+			stats.Updated++
+			if op.conflict {
+				stats.Conflicts++
+				stats.Resolved = append(stats.Resolved, op.resolution())
+			}
+		case actionDeleteFromHA, actionDeleteFromRem:
+			stats.Deleted++
+		}
 	}
 
 	return stats, firstErr
 }
 
+// shadowPass records a shadow-mode pass for listName and reports whether the
+// list has been promoted, in which case ops should be applied normally.
+func (r *Reconciler) shadowPass(ctx context.Context, listName string, ops []plannedOp) (bool, error) {
+	sl, err := r.store.GetShadowList(ctx, listName)
+	if err != nil {
+		return false, fmt.Errorf("reading shadow state for %q: %w", listName, err)
+	}
+	if sl != nil && sl.Promoted {
+		return true, nil
+	}
+
+	var creates, updates, deletes int
+	for _, op := range ops {
+		switch op.act {
+		case actionCreateInHA, actionCreateInRem, actionRestoreHA, actionRestoreRem:
+			creates++
+		case actionUpdateHA, actionUpdateRem, actionMerge:
+			updates++
+		case actionDeleteFromHA, actionDeleteFromRem:
+			deletes++
+		}
+		r.log.Debug("shadow: would apply", "list", listName, "action", op.act, "title", op.title())
+	}
+
+	if err := r.store.RecordShadowPass(ctx, listName, creates, updates, deletes, r.clock.Now().UTC()); err != nil {
+		return false, err
+	}
+
+	passes := 1
+	if sl != nil {
+		passes = sl.Passes + 1
+	}
+	r.log.Info("shadow pass",
+		"list", listName,
+		"pass", passes,
+		"of", r.shadowPasses,
+		"would_create", creates,
+		"would_update", updates,
+		"would_delete", deletes,
+	)
+
+	if passes < r.shadowPasses {
+		return false, nil
+	}
+	if !r.shadowAutoPromote || (sl != nil && sl.Held) {
+		r.log.Warn("shadow mode complete, awaiting promotion",
+			"list", listName,
+			"hint", "run 'reminderrelay promote "+listName+"' to start syncing",
+		)
+		return false, nil
+	}
+
+	if err := r.store.PromoteShadowList(ctx, listName); err != nil {
+		return false, err
+	}
+	r.log.Info("shadow list auto-promoted", "list", listName)
+	return false, nil
+}
+
 // decide determines what action to take for a tracked item based on hash
-// and timestamp comparison.
+// and timestamp comparison. When both sides changed, rows with a recorded
+// base are merged field by field; older rows fall back to whole-item
+// last-write-wins. A pinned item always takes the pinned side's version on
+// conflict, and a deletion on the other side re-creates it there instead.
 func (r *Reconciler) decide(si *state.Item, remItem, haItem *model.Item) action {
+	act, _ := r.decideWhy(si, remItem, haItem)
+	return act
+}
+
+// decideWhy is [Reconciler.decide], also returning why it chose the action,
+// as --explain logs it.
+func (r *Reconciler) decideWhy(si *state.Item, remItem, haItem *model.Item) (action, string) {
 	remExists := remItem != nil
 	haExists := haItem != nil
 
 	// Both deleted → just clean up state (handled as deleteFromHA path).
 	if !remExists && !haExists {
-		return actionDeleteFromHA // will clean state DB only
+		return actionDeleteFromHA, "gone from both sides, forgetting it" // will clean state DB only
 	}
 
 	// Deleted from Reminders, still in HA → delete from HA.
 	if !remExists && haExists {
-		return actionDeleteFromHA
+		if si.Pin == state.PinHA {
+			return actionRestoreRem, "gone from Reminders, but pinned to HA"
+		}
+		return actionDeleteFromHA, "gone from Reminders"
 	}
 
 	// Deleted from HA, still in Reminders → delete from Reminders.
 	if remExists && !haExists {
-		return actionDeleteFromRem
+		if si.Pin == state.PinReminders {
+			return actionRestoreHA, "gone from HA, but pinned to Reminders"
+		}
+		return actionDeleteFromRem, "gone from HA"
 	}
 
 	// Both exist — check for changes via content hash.
@@ -259,18 +850,33 @@ func (r *Reconciler) decide(si *state.Item, remItem, haItem *model.Item) action
 
 	// Neither changed → no-op.
 	if !remChanged && !haChanged {
-		return actionNone
+		return actionNone, "both hashes match the last sync"
 	}
 
 	// Only one side changed → propagate.
 	if remChanged && !haChanged {
-		return actionUpdateHA
+		return actionUpdateHA, "only the Reminders hash differs from the last sync"
 	}
 	if !remChanged && haChanged {
-		return actionUpdateRem
+		return actionUpdateRem, "only the HA hash differs from the last sync"
+	}
+
+	// Both changed on a pinned item → the pinned side wins outright.
+	switch si.Pin {
+	case state.PinReminders:
+		r.log.Info("conflict resolved by pin", "title", si.Title, "pinned", si.Pin)
+		return actionUpdateHA, "both hashes differ from the last sync, pinned to Reminders"
+	case state.PinHA:
+		r.log.Info("conflict resolved by pin", "title", si.Title, "pinned", si.Pin)
+		return actionUpdateRem, "both hashes differ from the last sync, pinned to HA"
+	}
+
+	// Both changed → merge field by field against the last synced content.
+	if si.Base != nil {
+		return actionMerge, "both hashes differ from the last sync, merging against its fields"
 	}
 
-	// Both changed → conflict → last-write-wins.
+	// No base recorded (row predates field tracking) → last-write-wins.
 	r.log.Info("conflict detected",
 		"title", si.Title,
 		"reminders_modified", remItem.ModifiedAt,
@@ -279,35 +885,30 @@ func (r *Reconciler) decide(si *state.Item, remItem, haItem *model.Item) action
 
 	if !remItem.ModifiedAt.Before(haItem.ModifiedAt) {
 		// Reminders wins (equal timestamps also favour Reminders as the "primary" source).
-		return actionUpdateHA
+		return actionUpdateHA, "both hashes differ from the last sync and no base is recorded; Reminders was modified last"
 	}
-	return actionUpdateRem
+	return actionUpdateRem, "both hashes differ from the last sync and no base is recorded; HA was modified last"
 }
 
-// execute dispatches the decided action to the appropriate adapter and
-// updates the state DB.
-func (r *Reconciler) execute(ctx context.Context, act action, si *state.Item, remItem, haItem *model.Item, entityID string) error {
-	now := time.Now().UTC()
+// execute dispatches the planned action to the appropriate backend and
+// updates the state DB. op.si is nil for the create actions.
+func (r *Reconciler) execute(ctx context.Context, op plannedOp, tgt target) error {
+	si, remItem, haItem := op.si, op.rem, op.ha
+	now := r.clock.Now().UTC()
 
-	switch act {
+	switch op.act {
 	case actionNone:
 		return nil
 
 	case actionCreateInHA:
-		// Reminders item exists but HA counterpart was deleted → shouldn't normally
-		// happen for tracked items; treat as delete from Reminders.
-		// Actually this case is: item tracked, Reminders still exists, HA gone →
-		// we chose actionDeleteFromRem above. So fall through here is unexpected.
-		// This branch handles the edge case defensively.
-		return r.store.DeleteItem(ctx, si.ID)
+		return r.createInHA(ctx, remItem, tgt)
 
 	case actionCreateInRem:
-		// Same defensive logic as above.
-		return r.store.DeleteItem(ctx, si.ID)
+		return r.createInReminders(ctx, haItem, tgt)
 
 	case actionDeleteFromHA:
 		if haItem != nil {
-			if err := r.ha.RemoveItem(ctx, entityID, haItem.Title); err != nil {
+			if err := tgt.backend.Delete(ctx, tgt.list, haItem); err != nil {
 				return fmt.Errorf("deleting %q from HA: %w", si.Title, err)
 			}
 		}
@@ -315,63 +916,124 @@ func (r *Reconciler) execute(ctx context.Context, act action, si *state.Item, re
 
 	case actionDeleteFromRem:
 		if remItem != nil {
-			if err := r.rem.Delete(ctx, remItem.UID); err != nil {
+			if err := r.rem.Delete(ctx, si.ListName, remItem); err != nil {
 				return fmt.Errorf("deleting %q from Reminders: %w", si.Title, err)
 			}
 		}
 		return r.store.DeleteItem(ctx, si.ID)
 
 	case actionUpdateHA:
-		// Use the HA item's current title to identify it (may differ from
-		// state DB title if both sides changed).
-		currentHATitle := si.Title
-		if haItem != nil {
-			currentHATitle = haItem.Title
+		fields := changedSince(si, haItem, remItem)
+		if op.conflict {
+			// Reminders' version replaces HA's wholesale.
+			fields = model.ChangedFields(haItem, remItem)
 		}
-		if err := r.ha.UpdateItem(ctx, entityID, currentHATitle, remItem); err != nil {
+		if err := tgt.backend.Update(ctx, tgt.list, haItem, remItem, fields); err != nil {
 			return fmt.Errorf("updating %q in HA: %w", remItem.Title, err)
 		}
 		si.Title = remItem.Title
 		si.LastSyncHash = remItem.ContentHash()
 		si.RemindersModified = remItem.ModifiedAt
 		si.LastSyncedAt = now
+		si.Base = baseOf(remItem)
 		return r.store.UpsertItem(ctx, si)
 
 	case actionUpdateRem:
-		if err := r.rem.Update(ctx, si.RemindersUID, haItem); err != nil {
+		haItem = withRawPriority(haItem, remItem, si.Base)
+		if err := r.rem.Update(ctx, si.ListName, remItem, haItem, changedSince(si, remItem, haItem)); err != nil {
 			return fmt.Errorf("updating %q in Reminders: %w", haItem.Title, err)
 		}
 		si.Title = haItem.Title
 		si.LastSyncHash = haItem.ContentHash()
 		si.HAModified = haItem.ModifiedAt
 		si.LastSyncedAt = now
+		si.Base = baseOf(haItem)
+		return r.store.UpsertItem(ctx, si)
+
+	case actionMerge:
+		merged := op.merged
+		hash := merged.ContentHash()
+		if hash != haItem.ContentHash() {
+			// HA changed too, so diff against its current content.
+			if err := tgt.backend.Update(ctx, tgt.list, haItem, merged, model.ChangedFields(haItem, merged)); err != nil {
+				return fmt.Errorf("updating %q in HA: %w", merged.Title, err)
+			}
+		}
+		if hash != remItem.ContentHash() {
+			if err := r.rem.Update(ctx, si.ListName, remItem, merged, model.ChangedFields(remItem, merged)); err != nil {
+				return fmt.Errorf("updating %q in Reminders: %w", merged.Title, err)
+			}
+		}
+		si.Title = merged.Title
+		si.LastSyncHash = hash
+		si.RemindersModified = remItem.ModifiedAt
+		si.HAModified = haItem.ModifiedAt
+		si.LastSyncedAt = now
+		si.Base = baseOf(merged)
+		return r.store.UpsertItem(ctx, si)
+
+	case actionRestoreHA:
+		haUID, err := r.addToHA(ctx, remItem, tgt)
+		if err != nil {
+			return err
+		}
+		r.log.Info("restored pinned item deleted from HA", "title", remItem.Title)
+		si.HAUID = haUID
+		si.Title = remItem.Title
+		si.LastSyncHash = remItem.ContentHash()
+		si.RemindersModified = remItem.ModifiedAt
+		si.LastSyncedAt = now
+		si.Base = baseOf(remItem)
+		return r.store.UpsertItem(ctx, si)
+
+	case actionRestoreRem:
+		haItem = withRawPriority(haItem, si.Base)
+		uid, err := r.rem.Create(ctx, si.ListName, haItem)
+		if err != nil {
+			return fmt.Errorf("re-creating %q in Reminders: %w", haItem.Title, err)
+		}
+		r.log.Info("restored pinned item deleted from Reminders", "title", haItem.Title)
+		// The row is keyed by the Reminders UID, so replace it; the pin
+		// carries over because the new row is inserted with si.Pin.
+		if err := r.store.DeleteItem(ctx, si.ID); err != nil {
+			return err
+		}
+		si.ID = 0
+		si.RemindersUID = uid
+		si.Title = haItem.Title
+		si.LastSyncHash = haItem.ContentHash()
+		si.HAModified = haItem.ModifiedAt
+		si.LastSyncedAt = now
+		si.Base = baseOf(haItem)
 		return r.store.UpsertItem(ctx, si)
 	}
 
 	return nil
 }
 
-// createInHA pushes a new Reminders item to HA and writes the state DB entry.
-func (r *Reconciler) createInHA(ctx context.Context, remItem *model.Item, entityID string) error {
-	if err := r.ha.AddItem(ctx, entityID, remItem); err != nil {
-		return fmt.Errorf("adding %q to HA: %w", remItem.Title, err)
+// changedSince returns the fields of src that differ from the last synced
+// content of si, so an update leaves every other field on the target side
+// untouched. Rows without a recorded base are diffed against target instead,
+// or fully written if that is unknown too.
+func changedSince(si *state.Item, target, src *model.Item) model.Fields {
+	switch {
+	case si.Base != nil:
+		return model.ChangedFields(si.Base, src)
+	case target != nil:
+		return model.ChangedFields(target, src)
 	}
+	return model.AllFields
+}
 
-	// After adding, fetch items again to get the HA UID.
-	haItems, err := r.ha.GetItems(ctx, entityID)
+// createInHA pushes a new Reminders item to the target and writes the state
+// DB entry.
+func (r *Reconciler) createInHA(ctx context.Context, remItem *model.Item, tgt target) error {
+	haUID, err := r.addToHA(ctx, remItem, tgt)
 	if err != nil {
-		return fmt.Errorf("refetching items from %s: %w", entityID, err)
-	}
-
-	var haUID string
-	for _, h := range haItems {
-		if h.Title == remItem.Title {
-			haUID = h.UID
-			break
-		}
+		return err
 	}
 
-	now := time.Now().UTC()
+	now := r.clock.Now().UTC()
 	si := &state.Item{
 		RemindersUID:      remItem.UID,
 		HAUID:             haUID,
@@ -380,26 +1042,50 @@ func (r *Reconciler) createInHA(ctx context.Context, remItem *model.Item, entity
 		LastSyncHash:      remItem.ContentHash(),
 		RemindersModified: remItem.ModifiedAt,
 		LastSyncedAt:      now,
+		Base:              baseOf(remItem),
 	}
 	return r.store.UpsertItem(ctx, si)
 }
 
-// createInReminders pushes a new HA item to Reminders and writes the state DB entry.
-func (r *Reconciler) createInReminders(ctx context.Context, haItem *model.Item, entityID string) error {
-	uid, err := r.rem.Create(ctx, haItem)
+// addToHA adds remItem to the target and returns the UID the target assigned
+// to it.
+func (r *Reconciler) addToHA(ctx context.Context, remItem *model.Item, tgt target) (string, error) {
+	uid, err := tgt.backend.Create(ctx, tgt.list, remItem)
+	if err != nil {
+		return "", fmt.Errorf("adding %q to HA: %w", remItem.Title, err)
+	}
+	return uid, nil
+}
+
+// createInReminders pushes a new target item to Reminders and writes the
+// state DB entry. An item whose title has shorthand to parse is created
+// parsed, and rewritten on the target to match.
+func (r *Reconciler) createInReminders(ctx context.Context, haItem *model.Item, tgt target) error {
+	item, parsed := r.shorthandOf(haItem.ListName, haItem)
+	if !parsed {
+		item = haItem
+	}
+	uid, err := r.rem.Create(ctx, item.ListName, item)
 	if err != nil {
 		return fmt.Errorf("creating %q in Reminders: %w", haItem.Title, err)
 	}
+	if parsed {
+		fields := model.ChangedFields(haItem, item) &^ unsupportedFields(tgt.backend, tgt.list)
+		if err := tgt.backend.Update(ctx, tgt.list, haItem, item, fields); err != nil {
+			return fmt.Errorf("writing parsed shorthand of %q to HA: %w", haItem.Title, err)
+		}
+	}
 
-	now := time.Now().UTC()
+	now := r.clock.Now().UTC()
 	si := &state.Item{
 		RemindersUID: uid,
 		HAUID:        haItem.UID,
-		ListName:     haItem.ListName,
-		Title:        haItem.Title,
-		LastSyncHash: haItem.ContentHash(),
+		ListName:     item.ListName,
+		Title:        item.Title,
+		LastSyncHash: item.ContentHash(),
 		HAModified:   haItem.ModifiedAt,
 		LastSyncedAt: now,
+		Base:         baseOf(item),
 	}
 	return r.store.UpsertItem(ctx, si)
 }
@@ -1,11 +1,16 @@
 package sync
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"log/slog"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/njoerd114/reminderrelay/internal/clock"
 	"github.com/njoerd114/reminderrelay/internal/model"
 	"github.com/njoerd114/reminderrelay/internal/state"
 )
@@ -38,7 +43,7 @@ func TestReconcile_NewReminderItem_CreatedInHA(t *testing.T) {
 	ha := newMockHA()
 	store := newMockStore()
 
-	r := NewReconciler(rem, ha, store, testLogger)
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
 	stats, err := r.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -80,7 +85,7 @@ func TestReconcile_NewHAItem_CreatedInReminders(t *testing.T) {
 	})
 	store := newMockStore()
 
-	r := NewReconciler(rem, ha, store, testLogger)
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
 	stats, err := r.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -139,7 +144,7 @@ func TestReconcile_Conflict_RemindersWins(t *testing.T) {
 		ModifiedAt: haTime,
 	})
 
-	r := NewReconciler(rem, ha, store, testLogger)
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
 	stats, err := r.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -194,7 +199,7 @@ func TestReconcile_Conflict_HAWins(t *testing.T) {
 		ModifiedAt: haTime,
 	})
 
-	r := NewReconciler(rem, ha, store, testLogger)
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
 	stats, err := r.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -215,6 +220,49 @@ func TestReconcile_Conflict_HAWins(t *testing.T) {
 	}
 }
 
+// HA reports no modification times, so a conflicting HA edit counts from when
+// it was first seen, which a row may have recorded in an earlier pass.
+func TestReconcile_Conflict_HAWithoutModificationTime(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	remTime := time.Date(2026, 1, 1, 11, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	haEdit := model.Item{UID: "ha-1", Title: "Buy whole milk"}
+
+	tests := []struct {
+		name      string
+		seenAt    time.Time // when an earlier pass saw haEdit; zero if none did
+		wantTitle string
+	}{
+		{"first seen now", time.Time{}, "Buy whole milk"},
+		{"seen before the Reminders edit", older.Add(30 * time.Minute), "Buy skim milk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, older)
+			row := &state.Item{
+				RemindersUID: "rem-1", HAUID: "ha-1", ListName: "Shopping", Title: "Buy milk",
+				LastSyncHash: orig.ContentHash(), RemindersModified: older, HAModified: older, LastSyncedAt: older,
+			}
+			if !tt.seenAt.IsZero() {
+				row.HAModified, row.HASeenHash = tt.seenAt, haEdit.ContentHash()
+			}
+			store := newMockStore()
+			store.seed(row)
+			rem := newMockReminders(newItem("rem-1", "Buy skim milk", "Shopping", model.PriorityNone, false, remTime))
+			ha := newMockHA()
+			ha.addItems("todo.shopping", haEdit)
+
+			r := NewReconciler(rem, NewRegistry(ha), store, testLogger, WithClock(clock.NewFake(now)))
+			if _, err := r.Run(context.Background(), testMappings); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := rem.get("rem-1"); got == nil || got.Title != tt.wantTitle {
+				t.Errorf("Reminders item = %+v, want title %q", got, tt.wantTitle)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Scenario 5: Deleted from Reminders → removed from HA + state DB
 // ---------------------------------------------------------------------------
@@ -242,7 +290,7 @@ func TestReconcile_DeletedFromReminders_RemovedFromHA(t *testing.T) {
 		Title: "Buy milk",
 	})
 
-	r := NewReconciler(rem, ha, store, testLogger)
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
 	stats, err := r.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -284,7 +332,7 @@ func TestReconcile_DeletedFromHA_RemovedFromReminders(t *testing.T) {
 	rem := newMockReminders(remItem)
 	ha := newMockHA() // HA: item gone
 
-	r := NewReconciler(rem, ha, store, testLogger)
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
 	stats, err := r.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -334,7 +382,7 @@ func TestReconcile_NoChanges_Idempotent(t *testing.T) {
 		ModifiedAt: now,
 	})
 
-	r := NewReconciler(rem, ha, store, testLogger)
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
 	stats, err := r.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -383,7 +431,7 @@ func TestReconcile_OnlyRemindersChanged_UpdatesHA(t *testing.T) {
 		ModifiedAt: older,
 	})
 
-	r := NewReconciler(rem, ha, store, testLogger)
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
 	stats, err := r.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -437,7 +485,7 @@ func TestReconcile_OnlyHAChanged_UpdatesReminders(t *testing.T) {
 		ModifiedAt: newer,
 	})
 
-	r := NewReconciler(rem, ha, store, testLogger)
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
 	stats, err := r.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -457,6 +505,51 @@ func TestReconcile_OnlyHAChanged_UpdatesReminders(t *testing.T) {
 	}
 }
 
+func TestReconcile_HAEditKeepsRawRemindersPriority(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	newer := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	remItem := newItem("rem-1", "Buy milk", "Shopping", model.PriorityHigh, false, older)
+	remItem.RawPriority = 2
+
+	store := newMockStore()
+	store.seed(&state.Item{
+		RemindersUID: "rem-1",
+		HAUID:        "ha-1",
+		ListName:     "Shopping",
+		Title:        "Buy milk",
+		LastSyncHash: remItem.ContentHash(),
+		LastSyncedAt: older,
+		Base:         baseOf(remItem),
+	})
+	rem := newMockReminders(remItem)
+
+	// HA: title edited; HA only knows the priority level.
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{
+		UID:        "ha-1",
+		Title:      "Buy whole milk",
+		Priority:   model.PriorityHigh,
+		ModifiedAt: newer,
+	})
+
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := rem.get("rem-1")
+	if got.Title != "Buy whole milk" {
+		t.Errorf("Reminders item title = %q, want %q", got.Title, "Buy whole milk")
+	}
+	if got.RawPriority != 2 {
+		t.Errorf("Reminders raw priority = %d, want 2", got.RawPriority)
+	}
+	if si, _ := store.GetItemByRemindersUID(context.Background(), "rem-1"); si == nil || si.Base == nil || si.Base.RawPriority != 2 {
+		t.Errorf("state base = %+v, want raw priority 2", si.Base)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Scenario: Multiple items across lists
 // ---------------------------------------------------------------------------
@@ -487,7 +580,7 @@ func TestReconcile_MultipleItems(t *testing.T) {
 		LastSyncedAt: now,
 	})
 
-	r := NewReconciler(rem, ha, store, testLogger)
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
 	stats, err := r.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -507,6 +600,36 @@ func TestReconcile_MultipleItems(t *testing.T) {
 	}
 }
 
+func TestReconcile_StatsPerList(t *testing.T) {
+	now := time.Now().UTC()
+	rem := newMockReminders(
+		newItem("rem-1", "Milk", "Shopping", model.PriorityNone, false, now),
+		newItem("rem-2", "Eggs", "Shopping", model.PriorityNone, false, now),
+		newItem("rem-3", "Report", "Work", model.PriorityNone, false, now),
+	)
+	mappings := map[string]string{"Shopping": "todo.shopping", "Work": "todo.work"}
+
+	r := NewReconciler(rem, NewRegistry(newMockHA()), newMockStore(), testLogger)
+	stats, err := r.Run(context.Background(), mappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(stats.Lists) != 2 {
+		t.Fatalf("Lists = %+v, want one entry per list", stats.Lists)
+	}
+	for i, want := range []ListStats{
+		{ListName: "Shopping", Target: "todo.shopping", Created: 2},
+		{ListName: "Work", Target: "todo.work", Created: 1},
+	} {
+		got := stats.Lists[i]
+		got.Duration = 0
+		if got != want {
+			t.Errorf("Lists[%d] = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Scenario: Completed status changed in Reminders → propagate to HA
 // ---------------------------------------------------------------------------
@@ -542,7 +665,7 @@ func TestReconcile_CompletedStatusChange(t *testing.T) {
 		ModifiedAt: older,
 	})
 
-	r := NewReconciler(rem, ha, store, testLogger)
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
 	stats, err := r.Run(context.Background(), testMappings)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -558,6 +681,139 @@ func TestReconcile_CompletedStatusChange(t *testing.T) {
 	}
 }
 
+// limitedHA is a mockHA whose lists store titles and completion only.
+type limitedHA struct{ *mockHA }
+
+func (limitedHA) SupportedFields(string) model.Fields {
+	return model.FieldTitle | model.FieldCompleted
+}
+
+func TestReconcile_UnsupportedFieldsNotSyncedBack(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	newer := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	remItem := newItem("rem-1", "Buy milk", "Shopping", model.PriorityHigh, false, older)
+	remItem.Description = "semi-skimmed"
+	store := newMockStore()
+	store.seed(&state.Item{
+		RemindersUID: "rem-1",
+		HAUID:        "ha-1",
+		ListName:     "Shopping",
+		Title:        "Buy milk",
+		LastSyncHash: remItem.ContentHash(),
+		LastSyncedAt: older,
+		Base:         baseOf(remItem),
+	})
+	rem := newMockReminders(remItem)
+
+	// HA dropped the description and priority, and the item was completed
+	// there.
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Buy milk", Completed: true, ModifiedAt: newer})
+
+	r := NewReconciler(rem, NewRegistry(limitedHA{ha}), store, testLogger)
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := rem.get("rem-1")
+	if !got.Completed {
+		t.Error("completion in HA was not synced to Reminders")
+	}
+	if got.Description != "semi-skimmed" || got.Priority != model.PriorityHigh {
+		t.Errorf("Reminders item = %q at %v, want its description and priority kept", got.Description, got.Priority)
+	}
+}
+
+func TestReconcile_QuirksRelinkChangedUID(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	remItem := newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, older)
+	store := newMockStore()
+	store.seed(&state.Item{
+		RemindersUID: "rem-1",
+		HAUID:        "ha-1",
+		ListName:     "Shopping",
+		Title:        "Buy milk",
+		LastSyncHash: remItem.ContentHash(),
+		LastSyncedAt: older,
+	})
+	rem := newMockReminders(remItem)
+
+	// The integration recreated the item under a new UID.
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-7", Title: "Buy milk", ModifiedAt: older})
+
+	quirks, err := QuirksPreset("bring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger,
+		WithQuirks(map[string]Quirks{"todo.shopping": quirks}))
+	stats, err := r.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stats.Created != 0 || stats.Deleted != 0 {
+		t.Errorf("Created = %d, Deleted = %d, want the item re-linked instead", stats.Created, stats.Deleted)
+	}
+	if rem.count() != 1 || len(ha.getItems("todo.shopping")) != 1 {
+		t.Errorf("items: %d in Reminders, %d in HA, want 1 each", rem.count(), len(ha.getItems("todo.shopping")))
+	}
+	if si, _ := store.GetItemByRemindersUID(context.Background(), "rem-1"); si == nil || si.HAUID != "ha-7" {
+		t.Errorf("state row = %+v, want it linked to ha-7", si)
+	}
+}
+
+func TestReconcile_RelinkRecreatedItem(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name   string
+		desc   string // of the recreated HA item
+		relink bool
+	}{
+		{"same content", "2%", true},
+		{"other description", "oat", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			remItem := newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, older)
+			remItem.Description = "2%"
+			remItem.DueDate = &due
+			store := newMockStore()
+			store.seed(&state.Item{
+				RemindersUID: "rem-1",
+				HAUID:        "ha-1",
+				ListName:     "Shopping",
+				Title:        "Buy milk",
+				LastSyncHash: remItem.ContentHash(),
+				LastSyncedAt: older,
+				Base:         baseOf(remItem),
+			})
+			rem := newMockReminders(remItem)
+
+			// HA restarted and handed out new UIDs.
+			ha := newMockHA()
+			ha.addItems("todo.shopping", model.Item{UID: "ha-7", Title: "Buy milk", Description: tt.desc, DueDate: &due, ModifiedAt: older})
+
+			r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+			if _, err := r.Run(context.Background(), testMappings); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			si, _ := store.GetItemByRemindersUID(context.Background(), "rem-1")
+			if relinked := si != nil && si.HAUID == "ha-7"; relinked != tt.relink {
+				t.Errorf("state row = %+v, relinked = %v, want %v", si, relinked, tt.relink)
+			}
+			if tt.relink && (rem.count() != 1 || len(ha.getItems("todo.shopping")) != 1) {
+				t.Errorf("items: %d in Reminders, %d in HA, want 1 each", rem.count(), len(ha.getItems("todo.shopping")))
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // decide() unit tests
 // ---------------------------------------------------------------------------
@@ -589,3 +845,461 @@ func TestDecide_EqualTimestampsFavourReminders(t *testing.T) {
 		t.Errorf("decide(equal timestamps) = %v, want actionUpdateHA (Reminders wins)", got)
 	}
 }
+
+func TestReconcile_ExplainLogsDecision(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	newer := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tracked := newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, older)
+	store := newMockStore()
+	store.seed(&state.Item{
+		RemindersUID: "rem-1",
+		HAUID:        "ha-1",
+		ListName:     "Shopping",
+		Title:        "Buy milk",
+		LastSyncHash: tracked.ContentHash(),
+		LastSyncedAt: older,
+	})
+	edited := *tracked
+	edited.Completed, edited.ModifiedAt = true, newer
+	rem := newMockReminders(&edited)
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Buy milk"})
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	r := NewReconciler(rem, NewRegistry(ha), store, logger, WithExplain())
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var found bool
+	for line := range bytes.Lines(buf.Bytes()) {
+		var rec map[string]any
+		if err := json.Unmarshal(line, &rec); err != nil || rec["msg"] != "explain" {
+			continue
+		}
+		found = true
+		if rec["action"] != "update_ha" || rec["reminders_hash"] != edited.ContentHash() || rec["last_sync_hash"] != tracked.ContentHash() {
+			t.Errorf("explain record = %v, want update_ha with both hashes", rec)
+		}
+		if rec["reason"] != "only the Reminders hash differs from the last sync" {
+			t.Errorf("reason = %q", rec["reason"])
+		}
+	}
+	if !found {
+		t.Errorf("no explain record logged:\n%s", buf.String())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Shadow mode
+// ---------------------------------------------------------------------------
+
+func TestReconcile_Shadow_PlansWithoutApplying(t *testing.T) {
+	now := time.Now().UTC()
+	rem := newMockReminders(newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, now))
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Buy eggs", ModifiedAt: now})
+	store := newMockStore()
+
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger, WithShadow([]string{"Shopping"}, 2, false))
+	for pass := 1; pass <= 3; pass++ {
+		stats, err := r.Run(context.Background(), testMappings)
+		if err != nil {
+			t.Fatalf("pass %d: unexpected error: %v", pass, err)
+		}
+		if stats.Created != 0 {
+			t.Errorf("pass %d: Created = %d, want 0 in shadow mode", pass, stats.Created)
+		}
+	}
+
+	if len(ha.getItems("todo.shopping")) != 1 || rem.count() != 1 || store.count() != 0 {
+		t.Error("shadow mode must not mutate either side or the state DB")
+	}
+
+	sl, _ := store.GetShadowList(context.Background(), "Shopping")
+	if sl == nil || sl.Passes != 3 || sl.WouldCreate != 2 {
+		t.Fatalf("shadow state = %+v, want 3 passes with 2 planned creates", sl)
+	}
+	if sl.Promoted {
+		t.Error("list should wait for explicit promotion when auto-promote is off")
+	}
+}
+
+func TestReconcile_Shadow_AutoPromote(t *testing.T) {
+	now := time.Now().UTC()
+	rem := newMockReminders(newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, now))
+	ha := newMockHA()
+	store := newMockStore()
+
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger, WithShadow([]string{"Shopping"}, 1, true))
+
+	// Pass 1: shadow pass reaches the threshold and promotes.
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ha.getItems("todo.shopping")) != 0 {
+		t.Fatal("promoting pass must not apply changes itself")
+	}
+
+	// Pass 2: promoted → changes are applied.
+	stats, err := r.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Created != 1 || len(ha.getItems("todo.shopping")) != 1 {
+		t.Errorf("Created = %d, HA items = %d, want 1 and 1 after promotion",
+			stats.Created, len(ha.getItems("todo.shopping")))
+	}
+}
+
+func TestReconcile_Shadow_HeldListIsNeverAutoPromoted(t *testing.T) {
+	now := time.Now().UTC()
+	rem := newMockReminders(newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, now))
+	ha := newMockHA()
+	store := newMockStore()
+	store.shadow["Shopping"] = &state.ShadowList{ListName: "Shopping", Held: true}
+
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger, WithShadow([]string{"Shopping"}, 1, true))
+	for pass := 1; pass <= 2; pass++ {
+		if _, err := r.Run(context.Background(), testMappings); err != nil {
+			t.Fatalf("pass %d: unexpected error: %v", pass, err)
+		}
+	}
+
+	if sl, _ := store.GetShadowList(context.Background(), "Shopping"); sl.Promoted {
+		t.Error("held list was auto-promoted")
+	}
+	if len(ha.getItems("todo.shopping")) != 0 {
+		t.Error("held list must not be written to")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Injected clock
+// ---------------------------------------------------------------------------
+
+func TestReconcile_HACompletionTimeIsWhenFirstSeen(t *testing.T) {
+	synced := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	seen := synced.Add(2 * time.Hour)
+	clk := clock.NewFake(seen)
+
+	remItem := newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, synced)
+	store := newMockStore()
+	store.seed(&state.Item{
+		RemindersUID: "rem-1", HAUID: "ha-1", ListName: "Shopping", Title: "Buy milk",
+		LastSyncHash: remItem.ContentHash(), LastSyncedAt: synced, Base: baseOf(remItem),
+	})
+	rem := newMockReminders(remItem)
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Buy milk", Completed: true})
+
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger, WithClock(clk))
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := rem.get("rem-1"); !got.Completed || got.CompletedAt == nil || !got.CompletedAt.Equal(seen) {
+		t.Errorf("Reminders item = %+v, want completed at %v", got, seen)
+	}
+	si, _ := store.GetItemByRemindersUID(context.Background(), "rem-1")
+	if si == nil || si.Base == nil || si.Base.CompletedAt == nil || !si.Base.CompletedAt.Equal(seen) {
+		t.Errorf("state base = %+v, want completed at %v", si, seen)
+	}
+}
+
+func TestReconcile_UsesInjectedClock(t *testing.T) {
+	at := time.Date(2026, 5, 4, 3, 2, 1, 0, time.UTC)
+	clk := clock.NewFake(at)
+	rem := newMockReminders(newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, at))
+	store := newMockStore()
+
+	r := NewReconciler(rem, NewRegistry(newMockHA()), store, testLogger, WithClock(clk))
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	si, _ := store.GetItemByRemindersUID(context.Background(), "rem-1")
+	if si == nil {
+		t.Fatal("expected state row for created item")
+	}
+	if !si.LastSyncedAt.Equal(at) {
+		t.Errorf("LastSyncedAt = %v, want %v", si.LastSyncedAt, at)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Pinned items
+// ---------------------------------------------------------------------------
+
+// pinnedRow returns a synced state row for "Buy milk" pinned to pin.
+func pinnedRow(at time.Time, pin state.Pin) *state.Item {
+	synced := newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, at)
+	return &state.Item{
+		RemindersUID:      "rem-1",
+		HAUID:             "ha-1",
+		ListName:          "Shopping",
+		Title:             "Buy milk",
+		LastSyncHash:      synced.ContentHash(),
+		RemindersModified: at,
+		HAModified:        at,
+		LastSyncedAt:      at,
+		Base:              baseOf(synced),
+		Pin:               pin,
+	}
+}
+
+func TestReconcile_PinnedSideWinsConflict(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := newMockStore()
+	store.seed(pinnedRow(older, state.PinHA))
+
+	// Reminders edited the title later, so it would win without the pin.
+	rem := newMockReminders(newItem("rem-1", "Buy whole milk", "Shopping", model.PriorityNone, false, older.Add(2*time.Hour)))
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Buy skim milk", ModifiedAt: older.Add(time.Hour)})
+
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+	stats, err := r.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := rem.get("rem-1").Title; got != "Buy skim milk" {
+		t.Errorf("Reminders title = %q, want HA's %q", got, "Buy skim milk")
+	}
+	if stats.Conflicts != 1 || len(stats.Resolved) != 1 || stats.Resolved[0].RemindersWon {
+		t.Errorf("Conflicts = %d, Resolved = %+v; want one conflict won by HA", stats.Conflicts, stats.Resolved)
+	}
+}
+
+func TestReconcile_PinnedItemRestoredInHA(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := newMockStore()
+	store.seed(pinnedRow(older, state.PinReminders))
+
+	rem := newMockReminders(newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, older))
+	ha := newMockHA() // deleted in HA
+
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if rem.get("rem-1") == nil {
+		t.Fatal("pinned item was deleted from Reminders")
+	}
+	haItems := ha.getItems("todo.shopping")
+	if len(haItems) != 1 || haItems[0].Title != "Buy milk" {
+		t.Fatalf("HA items = %v, want Buy milk re-created", haItems)
+	}
+	si, _ := store.GetItemByRemindersUID(context.Background(), "rem-1")
+	if si == nil || si.HAUID != haItems[0].UID || si.Pin != state.PinReminders {
+		t.Errorf("state row = %+v, want linked to %s and still pinned", si, haItems[0].UID)
+	}
+}
+
+func TestReconcile_PinnedItemRestoredInReminders(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := newMockStore()
+	store.seed(pinnedRow(older, state.PinHA))
+
+	rem := newMockReminders() // deleted in Reminders
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Buy milk", ModifiedAt: older})
+
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(ha.getItems("todo.shopping")) != 1 {
+		t.Fatal("pinned item was deleted from HA")
+	}
+	if rem.count() != 1 || store.count() != 1 {
+		t.Fatalf("Reminders items = %d, state rows = %d; want 1 and 1", rem.count(), store.count())
+	}
+	si, _ := store.GetItemByHAUID(context.Background(), "ha-1")
+	if si == nil || rem.get(si.RemindersUID) == nil || si.Pin != state.PinHA {
+		t.Errorf("state row = %+v, want linked to the re-created reminder and still pinned", si)
+	}
+}
+
+// barrierHA holds every Fetch until all expected fetches are in flight.
+type barrierHA struct {
+	*mockHA
+	inFlight sync.WaitGroup
+}
+
+func (b *barrierHA) Fetch(ctx context.Context, lists []string) ([]*model.Item, error) {
+	b.inFlight.Done()
+	all := make(chan struct{})
+	go func() {
+		b.inFlight.Wait()
+		close(all)
+	}()
+	select {
+	case <-all:
+	case <-time.After(time.Second):
+		return nil, errors.New("lists were not reconciled concurrently")
+	}
+	return b.mockHA.Fetch(ctx, lists)
+}
+
+func TestReconcile_ParallelListsAggregateStats(t *testing.T) {
+	now := time.Now()
+	rem := newMockReminders(
+		newItem("rem-1", "Milk", "Shopping", model.PriorityNone, false, now),
+		newItem("rem-2", "Report", "Work", model.PriorityNone, false, now),
+	)
+	ha := &barrierHA{mockHA: newMockHA()}
+	ha.inFlight.Add(3)
+	ha.addItems("todo.home", model.Item{UID: "ha-1", Title: "Vacuum", ModifiedAt: now})
+	mappings := map[string]string{
+		"Shopping": "todo.shopping",
+		"Work":     "todo.work",
+		"Home":     "todo.home",
+		"Garden":   "nowhere:garden", // no such backend
+	}
+
+	r := NewReconciler(rem, NewRegistry(ha), newMockStore(), testLogger, WithParallelism(4))
+	stats, err := r.Run(context.Background(), mappings)
+	if err == nil || stats.ListErrors["Garden"] == nil {
+		t.Fatalf("Run() error = %v, want the Garden list to fail", err)
+	}
+	if len(stats.ListErrors) != 1 {
+		t.Errorf("ListErrors = %v, want only Garden", stats.ListErrors)
+	}
+	if stats.Created != 3 {
+		t.Errorf("Created = %d, want 3 across the lists", stats.Created)
+	}
+	if rem.count() != 3 || len(ha.getItems("todo.shopping")) != 1 || len(ha.getItems("todo.work")) != 1 {
+		t.Error("items were not synced in every list")
+	}
+}
+
+// listReadCounter counts the state reads of whole lists, which only the
+// item-by-item reconcile does.
+type listReadCounter struct {
+	*mockStore
+	reads int
+}
+
+func (c *listReadCounter) GetAllItemsForList(ctx context.Context, listName string) ([]*state.Item, error) {
+	c.reads++
+	return c.mockStore.GetAllItemsForList(ctx, listName)
+}
+
+func TestReconcile_SkipsListsUnchangedOnBothSides(t *testing.T) {
+	now := time.Now()
+	rem := newMockReminders(
+		newItem("rem-1", "Milk", "Shopping", model.PriorityNone, false, now),
+		newItem("rem-2", "Eggs", "Shopping", model.PriorityNone, false, now),
+		newItem("rem-3", "Eggs", "Shopping", model.PriorityNone, false, now),
+	)
+	ha := newMockHA()
+	store := &listReadCounter{mockStore: newMockStore()}
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+	ctx := context.Background()
+
+	// The first pass creates the items in HA, the second finds them synced.
+	for range 2 {
+		if _, err := r.Run(ctx, testMappings); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	}
+	if store.reads != 2 {
+		t.Fatalf("state read %d times, want 2", store.reads)
+	}
+
+	stats, err := r.Run(ctx, testMappings)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if store.reads != 2 {
+		t.Errorf("state read %d times, want the unchanged list skipped", store.reads)
+	}
+	if len(stats.Duplicates) != 1 || stats.Duplicates[0].Title != "Eggs" {
+		t.Errorf("Duplicates = %+v, want Eggs reported while skipped", stats.Duplicates)
+	}
+
+	ha.mu.Lock()
+	for i, it := range ha.items["todo.shopping"] {
+		if it.Title == "Milk" {
+			ha.items["todo.shopping"][i].Title = "Oat milk"
+			ha.items["todo.shopping"][i].ModifiedAt = now.Add(time.Minute)
+		}
+	}
+	ha.mu.Unlock()
+	if _, err := r.Run(ctx, testMappings); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := rem.get("rem-1").Title; got != "Oat milk" {
+		t.Errorf("Reminders title = %q after an HA edit, want %q", got, "Oat milk")
+	}
+}
+
+// hungHA never answers a fetch before its context ends.
+type hungHA struct {
+	*mockHA
+}
+
+func (h hungHA) Fetch(ctx context.Context, _ []string) ([]*model.Item, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestReconcile_PassTimeout(t *testing.T) {
+	r := NewReconciler(newMockReminders(), NewRegistry(hungHA{newMockHA()}), newMockStore(), testLogger,
+		WithPassTimeout(20*time.Millisecond))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.Run(context.Background(), testMappings)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Run() error = %v, want the pass deadline exceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() still blocked after the pass timeout")
+	}
+}
+
+func TestReconcile_ReadOnlyWritesNothing(t *testing.T) {
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	newer := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tracked := newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, older)
+	store := newMockStore()
+	store.seed(&state.Item{
+		RemindersUID: "rem-1",
+		HAUID:        "ha-1",
+		ListName:     "Shopping",
+		Title:        "Buy milk",
+		LastSyncHash: tracked.ContentHash(),
+		LastSyncedAt: older,
+		Base:         baseOf(tracked),
+	})
+	edited := *tracked
+	edited.Completed, edited.ModifiedAt = true, newer
+	rem := newMockReminders(&edited, newItem("rem-2", "Bread", "Shopping", model.PriorityNone, false, newer))
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Buy milk"})
+
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger, WithReadOnly())
+	stats, err := r.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Created != 1 || stats.Updated != 1 {
+		t.Errorf("stats = %+v, want the would-be create and update counted", stats)
+	}
+	items := ha.getItems("todo.shopping")
+	if len(items) != 1 || items[0].Completed || store.count() != 1 {
+		t.Errorf("HA items = %+v, state rows = %d; want nothing written", items, store.count())
+	}
+}
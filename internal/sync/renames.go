@@ -0,0 +1,158 @@
+package sync
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// ListIDSource enumerates the lists of a task backend by an identifier that
+// survives renames. Implemented by [*reminders.Backend].
+type ListIDSource interface {
+	// ListIDs returns the name of every list, keyed by identifier.
+	ListIDs(ctx context.Context) (map[string]string, error)
+}
+
+// ListIDStore remembers the identifier of each list and moves a list's state
+// to its new name. Implemented by [*state.Store].
+type ListIDStore interface {
+	// ListIDs returns the recorded identifiers, keyed by list name.
+	ListIDs(ctx context.Context) (map[string]string, error)
+	SetListID(ctx context.Context, listName, id string) error
+	RenameList(ctx context.Context, oldName, newName string) error
+}
+
+// MappingRenamer persists a renamed list mapping outside the state DB, such
+// as in the config file, so the mapping survives a restart.
+type MappingRenamer interface {
+	RenameMapping(ctx context.Context, oldName, newName, target string) error
+}
+
+// listTracker follows renamed source lists; see [WithListTracking].
+type listTracker struct {
+	src     ListIDSource
+	store   ListIDStore
+	renamer MappingRenamer // nil to keep renames in memory and state only
+
+	mu      sync.Mutex
+	renamed map[string]string // old name → current name, for WebSocket events
+}
+
+// WithListTracking records the identifier of every mapped list in store
+// before each full pass. When a mapped list is gone but its identifier now
+// has another name, the list was renamed: its state rows, shadow-mode
+// setting and mapping move to the new name, and renamer, if non-nil, is
+// told so it can persist the new mapping. A list renamed to a name that is
+// already mapped is left alone with a warning.
+func WithListTracking(src ListIDSource, store ListIDStore, renamer MappingRenamer) EngineOption {
+	return func(e *Engine) {
+		e.tracker = &listTracker{
+			src:     src,
+			store:   store,
+			renamer: renamer,
+			renamed: make(map[string]string),
+		}
+	}
+}
+
+// trackLists records list identifiers and follows renames. The caller must
+// hold passMu.
+func (e *Engine) trackLists(ctx context.Context) {
+	t := e.tracker
+	current, err := t.src.ListIDs(ctx)
+	if err != nil {
+		// The pass that follows reports the underlying problem.
+		e.log.Debug("listing Reminders lists for rename tracking failed", "error", err)
+		return
+	}
+	known, err := t.store.ListIDs(ctx)
+	if err != nil {
+		e.log.Warn("reading list identifiers failed; renamed lists are not followed this pass", "error", err)
+		return
+	}
+
+	// Titles are not unique in Reminders; a title shared by several lists
+	// cannot be tied to one identifier.
+	byName := make(map[string]string, len(current))
+	shared := make(map[string]bool)
+	for id, name := range current {
+		if _, dup := byName[name]; dup {
+			shared[name] = true
+		}
+		byName[name] = id
+	}
+
+	for _, list := range slices.Sorted(maps.Keys(e.listMappings)) {
+		if id, ok := known[list]; ok {
+			if name, exists := current[id]; exists {
+				if name != list {
+					e.followRename(ctx, list, name)
+				}
+				continue
+			}
+		}
+		id, exists := byName[list]
+		if !exists || shared[list] || known[list] == id {
+			continue
+		}
+		if err := t.store.SetListID(ctx, list, id); err != nil {
+			e.log.Warn("recording list identifier failed", "list", list, "error", err)
+		}
+	}
+}
+
+// followRename moves the mapping of oldName, whose Reminders list is now
+// called newName, to newName. The caller must hold passMu.
+func (e *Engine) followRename(ctx context.Context, oldName, newName string) {
+	t := e.tracker
+	if _, taken := e.listMappings[newName]; taken {
+		e.log.Warn("Reminders list was renamed to a name that is already mapped; not following the rename",
+			"list", oldName, "new_name", newName)
+		return
+	}
+	if err := t.store.RenameList(ctx, oldName, newName); err != nil {
+		e.log.Error("moving sync state of renamed Reminders list failed", "list", oldName, "new_name", newName, "error", err)
+		return
+	}
+
+	target := e.listMappings[oldName]
+	// Copy rather than mutate: the caller's map may be shared.
+	mappings := maps.Clone(e.listMappings)
+	delete(mappings, oldName)
+	mappings[newName] = target
+	e.listMappings = mappings
+	e.reconciler.renameList(oldName, newName)
+
+	t.mu.Lock()
+	for old, name := range t.renamed {
+		if name == oldName {
+			t.renamed[old] = newName
+		}
+	}
+	t.renamed[oldName] = newName
+	t.mu.Unlock()
+
+	e.log.Info("followed renamed Reminders list", "list", oldName, "new_name", newName, "target", target)
+	if t.renamer == nil {
+		return
+	}
+	if err := t.renamer.RenameMapping(ctx, oldName, newName, target); err != nil {
+		e.log.Error("saving the renamed list mapping failed; rename it in the config file before restarting",
+			"list", oldName, "new_name", newName, "error", err)
+	}
+}
+
+// currentName returns the name the list mapped as listName at startup has
+// now, following renames.
+func (e *Engine) currentName(listName string) string {
+	if e.tracker == nil {
+		return listName
+	}
+	e.tracker.mu.Lock()
+	defer e.tracker.mu.Unlock()
+	if name, ok := e.tracker.renamed[listName]; ok {
+		return name
+	}
+	return listName
+}
@@ -0,0 +1,121 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// listIDs is a ListIDSource with a fixed identifier → name map.
+type listIDs map[string]string
+
+func (l listIDs) ListIDs(context.Context) (map[string]string, error) { return l, nil }
+
+// trackedStore adds list identifiers to a mockStore.
+type trackedStore struct {
+	*mockStore
+	ids map[string]string // name → identifier
+}
+
+func (s *trackedStore) ListIDs(context.Context) (map[string]string, error) {
+	out := make(map[string]string, len(s.ids))
+	for name, id := range s.ids {
+		out[name] = id
+	}
+	return out, nil
+}
+
+func (s *trackedStore) SetListID(_ context.Context, listName, id string) error {
+	s.ids[listName] = id
+	return nil
+}
+
+func (s *trackedStore) RenameList(_ context.Context, oldName, newName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range s.items {
+		if item.ListName == oldName {
+			item.ListName = newName
+		}
+	}
+	s.ids[newName] = s.ids[oldName]
+	delete(s.ids, oldName)
+	return nil
+}
+
+// fakeRenamer records the renames it is told about.
+type fakeRenamer struct{ calls []string }
+
+func (f *fakeRenamer) RenameMapping(_ context.Context, oldName, newName, target string) error {
+	f.calls = append(f.calls, oldName+"→"+newName+"="+target)
+	return nil
+}
+
+func TestEngine_FollowsRenamedList(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	rem := newMockReminders(newItem("rem-1", "Milk", "Shopping", model.PriorityNone, false, at))
+	ha := newMockHA()
+	store := &trackedStore{mockStore: newMockStore(), ids: make(map[string]string)}
+	ids := listIDs{"cal-1": "Shopping"}
+	renamer := &fakeRenamer{}
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger)
+	e := NewEngine(r, nil, testMappings, 30*time.Second, testLogger,
+		WithListTracking(ids, store, renamer))
+
+	if _, err := e.RunOnce(ctx); err != nil {
+		t.Fatalf("first RunOnce() error = %v", err)
+	}
+	if store.ids["Shopping"] != "cal-1" {
+		t.Fatalf("recorded identifiers = %v, want Shopping → cal-1", store.ids)
+	}
+
+	// The user renames the list in Reminders.
+	ids["cal-1"] = "Groceries"
+	rem.get("rem-1").ListName = "Groceries"
+
+	stats, err := e.RunOnce(ctx)
+	if err != nil {
+		t.Fatalf("RunOnce() after rename error = %v", err)
+	}
+	if n := stats.Created + stats.Updated + stats.Deleted; n != 0 {
+		t.Errorf("rename caused %d change(s), want none: %+v", n, stats)
+	}
+	if rem.count() != 1 || len(ha.getItems("todo.shopping")) != 1 {
+		t.Errorf("items: reminders %d, HA %d; want 1 each", rem.count(), len(ha.getItems("todo.shopping")))
+	}
+	if items, _ := store.GetAllItemsForList(ctx, "Groceries"); len(items) != 1 {
+		t.Errorf("state rows for Groceries = %d, want 1", len(items))
+	}
+	if want := "Shopping→Groceries=todo.shopping"; len(renamer.calls) != 1 || renamer.calls[0] != want {
+		t.Errorf("renamer calls = %v, want [%s]", renamer.calls, want)
+	}
+	if got := e.currentName("Shopping"); got != "Groceries" {
+		t.Errorf("currentName(Shopping) = %q, want Groceries", got)
+	}
+	if _, ok := testMappings["Groceries"]; ok {
+		t.Error("rename tracking modified the caller's mappings")
+	}
+}
+
+func TestEngine_IgnoresRenameOntoMappedList(t *testing.T) {
+	ctx := context.Background()
+	store := &trackedStore{mockStore: newMockStore(), ids: map[string]string{"Shopping": "cal-1"}}
+	renamer := &fakeRenamer{}
+	mappings := map[string]string{"Shopping": "todo.shopping", "Work": "todo.work"}
+	r := NewReconciler(newMockReminders(), NewRegistry(newMockHA()), store, testLogger)
+	e := NewEngine(r, nil, mappings, 30*time.Second, testLogger,
+		WithListTracking(listIDs{"cal-1": "Work", "cal-2": "Work"}, store, renamer))
+
+	if _, err := e.RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if len(renamer.calls) != 0 {
+		t.Errorf("renamer calls = %v, want none", renamer.calls)
+	}
+	if _, ok := store.ids["Work"]; ok {
+		t.Errorf("recorded an identifier for the ambiguous title Work: %v", store.ids)
+	}
+}
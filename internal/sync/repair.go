@@ -0,0 +1,185 @@
+package sync
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// RepairKind classifies a [Repair].
+type RepairKind string
+
+const (
+	// RepairRelink points a state row whose item is gone on one side at an
+	// unlinked item of the same title there, such as an item Home Assistant
+	// recreated with a new UID.
+	RepairRelink RepairKind = "relink"
+	// RepairDrop removes a state row whose item is gone on both sides.
+	RepairDrop RepairKind = "drop"
+	// RepairRehash records the content of a pair that agrees on both sides
+	// as last synced, so the next pass does not treat it as changed.
+	RepairRehash RepairKind = "rehash"
+)
+
+// Repair is one fix to the state DB proposed by [Verifier.PlanRepairs]. It
+// changes only the state DB, never the items of either side.
+type Repair struct {
+	Kind     RepairKind
+	ListName string
+	Title    string
+	Detail   string
+
+	before *state.Item // row as found
+	after  *state.Item // row as repaired; nil to delete it
+}
+
+// PlanRepairs returns the fixes for the state rows of listMappings, sorted
+// by list, kind, and title. Nothing is changed until they are passed to
+// [Verifier.ApplyRepairs].
+func (v *Verifier) PlanRepairs(ctx context.Context, listMappings map[string]string) ([]Repair, error) {
+	lists, err := v.load(ctx, listMappings)
+	if err != nil {
+		return nil, err
+	}
+
+	var repairs []Repair
+	for _, l := range lists {
+		rs, err := v.repairList(ctx, l)
+		if err != nil {
+			return nil, err
+		}
+		repairs = append(repairs, rs...)
+	}
+
+	slices.SortStableFunc(repairs, func(a, b Repair) int {
+		return cmp.Or(cmp.Compare(a.ListName, b.ListName), cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Title, b.Title))
+	})
+	return repairs, nil
+}
+
+// repairList plans the fixes for the state rows of one list.
+func (v *Verifier) repairList(ctx context.Context, l verifyList) ([]Repair, error) {
+	rows, err := v.store.GetAllItemsForList(ctx, l.name)
+	if err != nil {
+		return nil, fmt.Errorf("fetching state items for %q: %w", l.name, err)
+	}
+	remByUID := indexByUID(l.rem)
+	haByUID := indexByUID(l.ha)
+	trackedRem := make(map[string]bool, len(rows))
+	trackedHA := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		trackedRem[row.RemindersUID] = true
+		trackedHA[row.HAUID] = true
+	}
+	unlinkedRem := unlinkedByTitle(l.rem, trackedRem)
+	unlinkedHA := unlinkedByTitle(l.ha, trackedHA)
+
+	var repairs []Repair
+	for _, row := range rows {
+		rem, ha := remByUID[row.RemindersUID], haByUID[row.HAUID]
+		after := *row
+		r := Repair{ListName: l.name, Title: row.Title, before: row, after: &after}
+
+		switch {
+		case rem == nil && ha == nil:
+			r.Kind, r.after = RepairDrop, nil
+			r.Detail = "gone on both sides"
+
+		case ha == nil:
+			match := claimUnlinked(unlinkedHA, rem.Title)
+			if match == nil {
+				continue // the next pass deletes the Reminders item
+			}
+			after.HAUID, after.HAModified = match.UID, match.ModifiedAt
+			r.Kind = RepairRelink
+			r.Detail = fmt.Sprintf("%s item %s → %s", l.target, row.HAUID, match.UID)
+
+		case rem == nil:
+			match := claimUnlinked(unlinkedRem, ha.Title)
+			if match == nil {
+				continue // the next pass deletes the HA item
+			}
+			after.RemindersUID, after.RemindersModified = match.UID, match.ModifiedAt
+			r.Kind = RepairRelink
+			r.Detail = fmt.Sprintf("Reminders item %s → %s", row.RemindersUID, match.UID)
+
+		default:
+			hash := rem.ContentHash()
+			if hash != ha.ContentHash() || hash == row.LastSyncHash {
+				continue
+			}
+			after.Title, after.LastSyncHash, after.Base = rem.Title, hash, baseOf(rem)
+			after.RemindersModified, after.HAModified = rem.ModifiedAt, ha.ModifiedAt
+			after.LastSyncedAt = v.clock.Now()
+			r.Kind = RepairRehash
+			r.Detail = "both sides agree; last synced content is outdated"
+		}
+		repairs = append(repairs, r)
+	}
+	return repairs, nil
+}
+
+// unlinkedByTitle groups the items no state row links by title key. Items
+// whose UID several items carry are left out, as they cannot be linked
+// safely.
+func unlinkedByTitle(items []*model.Item, tracked map[string]bool) map[string][]*model.Item {
+	carriers := make(map[string]int, len(items))
+	for _, it := range items {
+		carriers[it.UID]++
+	}
+	m := make(map[string][]*model.Item)
+	for _, it := range items {
+		if tracked[it.UID] || carriers[it.UID] > 1 {
+			continue
+		}
+		k := titleKey(it.Title)
+		m[k] = append(m[k], it)
+	}
+	return m
+}
+
+// claimUnlinked returns the only unlinked item titled title and removes it
+// from unlinked, or nil when there is none or the title is ambiguous.
+func claimUnlinked(unlinked map[string][]*model.Item, title string) *model.Item {
+	k := titleKey(title)
+	if len(unlinked[k]) != 1 {
+		return nil
+	}
+	it := unlinked[k][0]
+	delete(unlinked, k)
+	return it
+}
+
+// ApplyRepairs writes repairs to the state DB in order and returns how many
+// were applied. It stops at the first failure.
+func (v *Verifier) ApplyRepairs(ctx context.Context, repairs []Repair) (int, error) {
+	for i, r := range repairs {
+		if err := v.apply(ctx, r); err != nil {
+			return i, fmt.Errorf("%s %q in %q: %w", r.Kind, r.Title, r.ListName, err)
+		}
+	}
+	return len(repairs), nil
+}
+
+// apply writes one repair. The state DB keys rows on their Reminders UID, so
+// a row that gets a new one is replaced rather than updated.
+func (v *Verifier) apply(ctx context.Context, r Repair) error {
+	switch {
+	case r.after == nil:
+		return v.store.DeleteItem(ctx, r.before.ID)
+	case r.after.RemindersUID != r.before.RemindersUID:
+		if err := v.store.DeleteItem(ctx, r.before.ID); err != nil {
+			return err
+		}
+		row := *r.after
+		row.ID = 0
+		return v.store.UpsertItem(ctx, &row)
+	default:
+		row := *r.after
+		return v.store.UpsertItem(ctx, &row)
+	}
+}
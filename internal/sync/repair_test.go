@@ -0,0 +1,101 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+func TestVerifier_Repairs(t *testing.T) {
+	at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	bread := newItem("rem-bread", "Bread", "Shopping", model.PriorityNone, false, at)
+	eggs := newItem("rem-eggs", "Eggs", "Shopping", model.PriorityLow, false, at)
+	rem := newMockReminders(
+		bread,
+		newItem("rem-milk-new", "Milk", "Shopping", model.PriorityNone, false, at),
+		eggs,
+		newItem("rem-tea", "Tea", "Shopping", model.PriorityNone, false, at),
+	)
+	ha := newMockHA()
+	ha.addItems("todo.shopping",
+		model.Item{UID: "ha-bread-new", Title: "bread", ModifiedAt: at},
+		model.Item{UID: "ha-milk", Title: "Milk", ModifiedAt: at},
+		model.Item{UID: "ha-eggs", Title: "Eggs", Priority: model.PriorityLow, ModifiedAt: at},
+		model.Item{UID: "ha-tea-1", Title: "Tea", ModifiedAt: at},
+		model.Item{UID: "ha-tea-2", Title: "Tea", ModifiedAt: at},
+	)
+	store := newMockStore()
+	for _, row := range []*state.Item{
+		{RemindersUID: "rem-bread", HAUID: "ha-bread-old", Title: "Bread", Pin: state.PinReminders},
+		{RemindersUID: "rem-milk-old", HAUID: "ha-milk", Title: "Milk"},
+		{RemindersUID: "rem-eggs", HAUID: "ha-eggs", Title: "Eggs", LastSyncHash: "stale"},
+		{RemindersUID: "rem-gone", HAUID: "ha-gone", Title: "Gone"},
+		{RemindersUID: "rem-tea", HAUID: "ha-tea-old", Title: "Tea"},
+	} {
+		row.ListName = "Shopping"
+		row.LastSyncedAt = at
+		store.seed(row)
+	}
+
+	v := NewVerifier(rem, NewRegistry(ha), store, time.Minute)
+	v.clock = clock.NewFake(at.Add(time.Hour))
+	repairs, err := v.PlanRepairs(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("PlanRepairs() error = %v", err)
+	}
+	got := make(map[string]RepairKind)
+	for _, r := range repairs {
+		got[r.Title] = r.Kind
+	}
+	want := map[string]RepairKind{
+		"Bread": RepairRelink,
+		"Milk":  RepairRelink,
+		"Eggs":  RepairRehash,
+		"Gone":  RepairDrop,
+	}
+	if len(got) != len(want) {
+		t.Errorf("repairs = %+v, want %v", repairs, want)
+	}
+	for title, kind := range want {
+		if got[title] != kind {
+			t.Errorf("%q: repair = %q, want %q", title, got[title], kind)
+		}
+	}
+	if len(store.items) != 5 {
+		t.Fatal("PlanRepairs changed state")
+	}
+
+	n, err := v.ApplyRepairs(context.Background(), repairs)
+	if err != nil || n != len(repairs) {
+		t.Fatalf("ApplyRepairs() = %d, %v, want %d applied", n, err, len(repairs))
+	}
+	if row := rowFor(store, "rem-bread"); row == nil || row.HAUID != "ha-bread-new" || row.Pin != state.PinReminders {
+		t.Errorf("Bread row = %+v, want relinked to ha-bread-new keeping its pin", row)
+	}
+	if row := rowFor(store, "rem-milk-new"); row == nil || row.HAUID != "ha-milk" {
+		t.Errorf("Milk row = %+v, want relinked to rem-milk-new", row)
+	}
+	if rowFor(store, "rem-milk-old") != nil || rowFor(store, "rem-gone") != nil {
+		t.Error("replaced or orphaned rows were kept")
+	}
+	if row := rowFor(store, "rem-eggs"); row == nil || row.LastSyncHash != eggs.ContentHash() {
+		t.Errorf("Eggs row = %+v, want hash recomputed", row)
+	}
+	// Two unlinked HA items are titled Tea, so its row is left alone.
+	if row := rowFor(store, "rem-tea"); row == nil || row.HAUID != "ha-tea-old" {
+		t.Errorf("Tea row = %+v, want unchanged", row)
+	}
+	if rem.count() != 4 || len(ha.getItems("todo.shopping")) != 5 {
+		t.Error("repairs changed items")
+	}
+}
+
+// rowFor returns the state row of the Reminders item uid, or nil.
+func rowFor(store *mockStore, uid string) *state.Item {
+	row, _ := store.GetItemByRemindersUID(context.Background(), uid)
+	return row
+}
@@ -0,0 +1,118 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// Resolution is the user's verdict on a conflict the engine already resolved.
+type Resolution int
+
+const (
+	// KeepResult confirms the engine's resolution.
+	KeepResult Resolution = iota
+	// UseReminders writes Reminders' version from before the conflict to
+	// both sides.
+	UseReminders
+	// UseHA writes Home Assistant's version from before the conflict to
+	// both sides.
+	UseHA
+)
+
+// String returns the name used in logs and dashboard forms.
+func (r Resolution) String() string {
+	switch r {
+	case UseReminders:
+		return "reminders"
+	case UseHA:
+		return "ha"
+	}
+	return "keep"
+}
+
+// ErrConflictNotFound is returned by [Engine.ResolveConflict] for an unknown
+// or already reviewed conflict.
+var ErrConflictNotFound = errors.New("conflict not found or already reviewed")
+
+// ResolveConflict applies the user's verdict on the recent conflict with the
+// given ID and marks it reviewed. Overriding fails if the item changed again
+// since the conflict, so a newer edit is never silently replaced.
+func (e *Engine) ResolveConflict(ctx context.Context, id int, res Resolution) error {
+	e.passMu.Lock()
+	defer e.passMu.Unlock()
+
+	e.statusMu.Lock()
+	idx := -1
+	for i, c := range e.status.Conflicts {
+		if c.ID == id && !c.Reviewed {
+			idx = i
+		}
+	}
+	var c RecentConflict
+	if idx >= 0 {
+		c = e.status.Conflicts[idx]
+	}
+	e.statusMu.Unlock()
+	if idx < 0 {
+		return ErrConflictNotFound
+	}
+
+	if res != KeepResult {
+		version := c.HA
+		if res == UseReminders {
+			version = c.Reminders
+		}
+		target, ok := e.listMappings[c.ListName]
+		if !ok {
+			return fmt.Errorf("list %q is no longer mapped", c.ListName)
+		}
+		if err := e.reconciler.override(ctx, c.Conflict, &version, target); err != nil {
+			return err
+		}
+	}
+
+	e.statusMu.Lock()
+	for i := range e.status.Conflicts {
+		if e.status.Conflicts[i].ID == id {
+			e.status.Conflicts[i].Reviewed = true
+		}
+	}
+	e.statusMu.Unlock()
+	e.log.Info("conflict reviewed", "list", c.ListName, "title", c.Result.Title, "resolution", res)
+	return nil
+}
+
+// override writes version to both sides of the item c was about, replacing
+// c.Result.
+func (r *Reconciler) override(ctx context.Context, c Conflict, version *model.Item, target string) error {
+	tgt, err := r.resolveTarget(target, c.ListName)
+	if err != nil {
+		return err
+	}
+
+	si, err := r.store.GetItemByRemindersUID(ctx, c.Reminders.UID)
+	if err != nil {
+		return fmt.Errorf("looking up %q: %w", c.Result.Title, err)
+	}
+	if si == nil || si.LastSyncHash != c.Result.ContentHash() {
+		return fmt.Errorf("%q changed since the conflict; edit it directly instead", c.Result.Title)
+	}
+
+	version = withRawPriority(version, si.Base)
+	fields := model.ChangedFields(&c.Result, version)
+	if err := tgt.backend.Update(ctx, tgt.list, &model.Item{UID: si.HAUID, Title: si.Title}, version, fields); err != nil {
+		return fmt.Errorf("updating %q in HA: %w", si.Title, err)
+	}
+	if err := r.rem.Update(ctx, si.ListName, &model.Item{UID: si.RemindersUID, Title: si.Title}, version, fields); err != nil {
+		return fmt.Errorf("updating %q in Reminders: %w", si.Title, err)
+	}
+
+	si.Title = version.Title
+	si.LastSyncHash = version.ContentHash()
+	si.LastSyncedAt = r.clock.Now().UTC()
+	si.Base = baseOf(version)
+	return r.store.UpsertItem(ctx, si)
+}
@@ -0,0 +1,89 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// conflictingEngine returns an engine whose first pass resolves a title
+// conflict on "Buy milk" in favour of Reminders.
+func conflictingEngine(t *testing.T) (*Engine, *mockReminders, *mockHA) {
+	t.Helper()
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := newMockStore()
+	store.seed(&state.Item{
+		RemindersUID: "rem-1",
+		HAUID:        "ha-1",
+		ListName:     "Shopping",
+		Title:        "Buy milk",
+		LastSyncHash: newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, older).ContentHash(),
+		LastSyncedAt: older,
+	})
+	rem := newMockReminders(newItem("rem-1", "Buy whole milk", "Shopping", model.PriorityNone, false, older.Add(2*time.Hour)))
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Buy skim milk", ModifiedAt: older.Add(time.Hour)})
+
+	e := NewEngine(NewReconciler(rem, NewRegistry(ha), store, testLogger), nil, testMappings, time.Minute, testLogger)
+	if _, err := e.SyncNow(context.Background()); err != nil {
+		t.Fatalf("SyncNow() error = %v", err)
+	}
+	return e, rem, ha
+}
+
+func TestResolveConflict_UseOtherVersion(t *testing.T) {
+	e, rem, ha := conflictingEngine(t)
+	ctx := context.Background()
+
+	conflicts := e.Status().Conflicts
+	if len(conflicts) != 1 || !conflicts[0].RemindersWon {
+		t.Fatalf("Conflicts = %+v, want one won by Reminders", conflicts)
+	}
+	if err := e.ResolveConflict(ctx, conflicts[0].ID, UseHA); err != nil {
+		t.Fatalf("ResolveConflict() error = %v", err)
+	}
+
+	if got := rem.get("rem-1").Title; got != "Buy skim milk" {
+		t.Errorf("Reminders title = %q, want HA's version", got)
+	}
+	if got := ha.getItems("todo.shopping")[0].Title; got != "Buy skim milk" {
+		t.Errorf("HA title = %q, want HA's version", got)
+	}
+	if !e.Status().Conflicts[0].Reviewed {
+		t.Error("conflict not marked reviewed")
+	}
+	if err := e.ResolveConflict(ctx, conflicts[0].ID, KeepResult); !errors.Is(err, ErrConflictNotFound) {
+		t.Errorf("second ResolveConflict() = %v, want ErrConflictNotFound", err)
+	}
+
+	// The override must not look like a fresh edit to the next pass.
+	stats, err := e.SyncNow(ctx)
+	if err != nil || stats.Updated != 0 || stats.Conflicts != 0 {
+		t.Errorf("next pass = %+v, %v; want no changes", stats, err)
+	}
+}
+
+func TestResolveConflict_RefusesAfterNewerEdit(t *testing.T) {
+	e, rem, _ := conflictingEngine(t)
+	ctx := context.Background()
+
+	edited := *rem.get("rem-1")
+	edited.Title = "Buy oat milk"
+	if err := rem.Update(ctx, "Shopping", &edited, &edited, model.AllFields); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.SyncNow(ctx); err != nil {
+		t.Fatalf("SyncNow() error = %v", err)
+	}
+
+	if err := e.ResolveConflict(ctx, e.Status().Conflicts[0].ID, UseHA); err == nil {
+		t.Error("ResolveConflict() succeeded over a newer edit")
+	}
+	if got := rem.get("rem-1").Title; got != "Buy oat milk" {
+		t.Errorf("Reminders title = %q, want the newer edit kept", got)
+	}
+}
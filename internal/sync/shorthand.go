@@ -0,0 +1,101 @@
+package sync
+
+import (
+	"strings"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// WithShorthand parses shorthand at the end of the titles of items created
+// on the target for lists, such as "milk !high tomorrow", into their
+// priority and due date before they are created in Reminders. The target's
+// item is rewritten to match. Due dates are midnight in loc; nil means UTC.
+// See [parseShorthand] for what is understood.
+func WithShorthand(lists []string, loc *time.Location) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.shorthand = make(map[string]bool, len(lists))
+		for _, l := range lists {
+			r.shorthand[l] = true
+		}
+		r.shorthandLoc = loc
+	}
+}
+
+// shorthandOf returns item with the shorthand in its title parsed, if
+// listName parses shorthand and the title has any.
+func (r *Reconciler) shorthandOf(listName string, item *model.Item) (*model.Item, bool) {
+	if !r.shorthand[listName] {
+		return nil, false
+	}
+	loc := r.shorthandLoc
+	if loc == nil {
+		loc = time.UTC
+	}
+	return parseShorthand(item, r.clock.Now().In(loc))
+}
+
+// shorthandPriorities maps priority tokens to the level they set.
+var shorthandPriorities = map[string]model.Priority{
+	"!high":   model.PriorityHigh,
+	"!!!":     model.PriorityHigh,
+	"!medium": model.PriorityMedium,
+	"!med":    model.PriorityMedium,
+	"!!":      model.PriorityMedium,
+	"!low":    model.PriorityLow,
+}
+
+// parseShorthand strips a priority token and a due date phrase from the end
+// of item's title, in either order, and returns a copy of item with them
+// set. Priority tokens are "!high" or "!!!", "!medium", "!med" or "!!", and
+// "!low". Due date phrases are "today", "tomorrow", a weekday such as
+// "friday" or "fri" — the next one, today included — and a date such as
+// "2026-03-01", each meaning midnight in now's location. Case does not
+// matter. A due date phrase is left in the title of an item that has a due
+// date already, and nothing is parsed that would leave the title empty. It
+// reports false if the title has no shorthand.
+func parseShorthand(item *model.Item, now time.Time) (*model.Item, bool) {
+	words := strings.Fields(item.Title)
+	c := *item
+	var gotPriority, gotDue bool
+	for len(words) > 1 {
+		last := strings.ToLower(words[len(words)-1])
+		if p, ok := shorthandPriorities[last]; ok && !gotPriority {
+			c.Priority, c.RawPriority = p, 0
+			gotPriority = true
+		} else if due, ok := shorthandDue(last, now); ok && !gotDue && item.DueDate == nil {
+			c.DueDate = &due
+			gotDue = true
+		} else {
+			break
+		}
+		words = words[:len(words)-1]
+	}
+	if !gotPriority && !gotDue {
+		return nil, false
+	}
+	c.Title = strings.Join(words, " ")
+	c.RawTitle = ""
+	return &c, true
+}
+
+// shorthandDue returns the midnight in now's location that word names.
+func shorthandDue(word string, now time.Time) (time.Time, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch word {
+	case "today":
+		return today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if word == name || word == name[:3] {
+			return today.AddDate(0, 0, (int(d)-int(now.Weekday())+7)%7), true
+		}
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, word, now.Location()); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
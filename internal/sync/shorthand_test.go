@@ -0,0 +1,86 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+func TestParseShorthand(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	now := time.Date(2026, 3, 4, 22, 0, 0, 0, berlin) // a Wednesday
+	day := func(m time.Month, d int) *time.Time {
+		t := time.Date(2026, m, d, 0, 0, 0, 0, berlin)
+		return &t
+	}
+	tests := []struct {
+		title     string
+		wantTitle string
+		wantPrio  model.Priority
+		wantDue   *time.Time
+	}{
+		{"milk !high tomorrow", "milk", model.PriorityHigh, day(3, 5)},
+		{"milk tomorrow !!", "milk", model.PriorityMedium, day(3, 5)},
+		{"Call mum Friday", "Call mum", model.PriorityNone, day(3, 6)},
+		{"Bins wed", "Bins", model.PriorityNone, day(3, 4)},
+		{"Taxes 2026-04-30 !low", "Taxes", model.PriorityLow, day(4, 30)},
+	}
+	for _, tt := range tests {
+		got, ok := parseShorthand(&model.Item{Title: tt.title}, now)
+		if !ok {
+			t.Errorf("parseShorthand(%q): nothing parsed", tt.title)
+			continue
+		}
+		if got.Title != tt.wantTitle || got.Priority != tt.wantPrio || got.DueDate == nil || !got.DueDate.Equal(*tt.wantDue) {
+			t.Errorf("parseShorthand(%q) = %q, %v, %v; want %q, %v, %v",
+				tt.title, got.Title, got.Priority, got.DueDate, tt.wantTitle, tt.wantPrio, tt.wantDue)
+		}
+	}
+
+	for _, title := range []string{"tomorrow", "Plan today's lunch", "today is the day", "milk"} {
+		if _, ok := parseShorthand(&model.Item{Title: title}, now); ok {
+			t.Errorf("parseShorthand(%q) parsed, want the title left alone", title)
+		}
+	}
+	due := now
+	if _, ok := parseShorthand(&model.Item{Title: "milk tomorrow", DueDate: &due}, now); ok {
+		t.Error("parseShorthand parsed a due date for an item that has one")
+	}
+}
+
+func TestReconcile_ShorthandFromHA(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	rem := newMockReminders()
+	ha := newMockHA()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "milk !high tomorrow", ModifiedAt: now})
+	store := newMockStore()
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger,
+		WithClock(clock.NewFake(now)), WithShorthand([]string{"Shopping"}, time.UTC))
+
+	if _, err := r.Run(context.Background(), testMappings); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	items, _ := rem.Fetch(context.Background(), []string{"Shopping"})
+	want := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+	if len(items) != 1 || items[0].Title != "milk" || items[0].Priority != model.PriorityHigh ||
+		items[0].DueDate == nil || !items[0].DueDate.Equal(want) {
+		t.Fatalf("Reminders items = %+v, want milk, high, due %v", items, want)
+	}
+	if got := ha.getItems("todo.shopping"); got[0].Title != "milk" {
+		t.Errorf("HA title = %q, want the parsed title written back", got[0].Title)
+	}
+
+	stats, err := r.Run(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if stats.Created+stats.Updated != 0 {
+		t.Errorf("second run stats = %+v, want nothing to do", stats)
+	}
+}
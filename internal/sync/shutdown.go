@@ -0,0 +1,35 @@
+package sync
+
+import (
+	"context"
+	"time"
+)
+
+// WithShutdownGrace lets the item a pass is writing when its context ends,
+// on shutdown or by the pass timeout, finish for up to d, so that a change
+// written to one side is also recorded in the state DB. The pass starts no
+// further items either way. Zero abandons the item at once.
+func WithShutdownGrace(d time.Duration) ReconcilerOption {
+	return func(r *Reconciler) { r.shutdownGrace = d }
+}
+
+// itemContext returns the context to write one item with: ctx, but ending
+// only the shutdown grace period after ctx does.
+func (r *Reconciler) itemContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.shutdownGrace <= 0 {
+		return ctx, func() {}
+	}
+	itemCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		select {
+		case <-r.clock.After(r.shutdownGrace):
+			r.log.Warn("item still being written after the shutdown grace period, abandoning it")
+			cancel()
+		case <-itemCtx.Done():
+		}
+	})
+	return itemCtx, func() {
+		stop()
+		cancel()
+	}
+}
@@ -0,0 +1,106 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// slowCreateHA is a mockHA whose creates report on started and then wait
+// for release or the end of their context.
+type slowCreateHA struct {
+	*mockHA
+	started chan string
+	release chan struct{}
+}
+
+func (h slowCreateHA) Create(ctx context.Context, entityID string, item *model.Item) (string, error) {
+	h.started <- item.Title
+	select {
+	case <-h.release:
+		return h.mockHA.Create(ctx, entityID, item)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// ctxStore is a mockStore that fails writes whose context has ended, as the
+// SQLite store does.
+type ctxStore struct {
+	*mockStore
+}
+
+func (s ctxStore) UpsertItem(ctx context.Context, item *state.Item) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.mockStore.UpsertItem(ctx, item)
+}
+
+func newShutdownFixture(grace time.Duration, clk clock.Clock) (*Reconciler, slowCreateHA, *mockStore) {
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	rem := newMockReminders(
+		newItem("r1", "Eggs", "Shopping", model.PriorityNone, false, now),
+		newItem("r2", "Milk", "Shopping", model.PriorityNone, false, now),
+	)
+	ha := slowCreateHA{mockHA: newMockHA(), started: make(chan string, 2), release: make(chan struct{})}
+	store := newMockStore()
+	r := NewReconciler(rem, NewRegistry(ha), ctxStore{store}, testLogger,
+		WithClock(clk), WithShutdownGrace(grace))
+	return r, ha, store
+}
+
+func TestReconcile_ShutdownFinishesItem(t *testing.T) {
+	r, ha, store := newShutdownFixture(time.Minute, clock.Real())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.Run(ctx, testMappings)
+		done <- err
+	}()
+
+	<-ha.started
+	cancel()
+	close(ha.release)
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+	if n := len(ha.getItems("todo.shopping")); n != 1 {
+		t.Errorf("HA has %d items, want only the one in flight created", n)
+	}
+	if n := store.count(); n != 1 {
+		t.Errorf("state DB has %d items, want the one created recorded", n)
+	}
+}
+
+func TestReconcile_ShutdownGraceExpires(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	r, ha, store := newShutdownFixture(5*time.Second, clk)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.Run(ctx, testMappings)
+		done <- err
+	}()
+
+	<-ha.started
+	cancel()
+	clk.BlockUntil(1)
+	select {
+	case err := <-done:
+		t.Fatalf("Run() = %v within the grace period", err)
+	default:
+	}
+	clk.Advance(5 * time.Second)
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+	if n := store.count(); n != 0 {
+		t.Errorf("state DB has %d items, want none after the abandoned create", n)
+	}
+}
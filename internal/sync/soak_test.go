@@ -0,0 +1,145 @@
+//go:build soak
+
+package sync
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/chaos"
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// Run with: go test -tags soak -run Soak ./internal/sync -soak.passes=2000
+var (
+	soakPasses = flag.Int("soak.passes", 300, "faulty passes per soak test")
+	soakRate   = flag.Float64("soak.rate", 0.2, "probability that a backend call fails")
+	soakSeed   = flag.Uint64("soak.seed", 1, "seed for edits and faults")
+)
+
+// maxSettlePasses bounds the fault-free passes a soak run may take to
+// converge.
+const maxSettlePasses = 3
+
+// TestSoak_ConvergesUnderFaults edits both sides at random while every
+// backend call may time out, fail, or half-succeed, then checks that fault-
+// free passes bring both sides and the state DB back in line.
+func TestSoak_ConvergesUnderFaults(t *testing.T) {
+	ctx := context.Background()
+	rng := rand.New(rand.NewPCG(*soakSeed, *soakSeed^0x5eed))
+	inj := chaos.NewInjector(*soakRate, *soakSeed, testLogger)
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+
+	rem := newMockReminders()
+	ha := newMockHA()
+	store := newMockStore()
+	r := NewReconciler(chaos.WrapBackend("reminders", rem, inj),
+		NewRegistry(chaos.WrapBackend("ha", ha, inj)), store, testLogger, WithClock(clk))
+
+	next := 0
+	for range *soakPasses {
+		clk.Advance(time.Minute)
+		for range rng.IntN(3) {
+			next++
+			soakEdit(rng, rem, ha, clk.Now(), next)
+		}
+		_, _ = r.Run(ctx, testMappings) // failures are expected
+	}
+
+	inj.SetRate(0)
+	var stats Stats
+	for i := range maxSettlePasses {
+		clk.Advance(time.Minute)
+		var err error
+		if stats, err = r.Run(ctx, testMappings); err != nil {
+			t.Fatalf("fault-free pass %d: %v", i+1, err)
+		}
+		if stats.Created+stats.Updated+stats.Deleted == 0 {
+			break
+		}
+	}
+	t.Logf("faults injected: %v", inj.Counts())
+
+	if n := stats.Created + stats.Updated + stats.Deleted; n != 0 {
+		t.Errorf("still %d change(s) after %d fault-free passes: %+v", n, maxSettlePasses, stats)
+	}
+	remContent, haContent := soakContent(rem.items), soakContent(haItems(ha))
+	if !slices.Equal(remContent, haContent) {
+		t.Errorf("sides differ:\n reminders %v\n ha        %v", remContent, haContent)
+	}
+	if rows := len(store.items); rows != len(remContent) {
+		t.Errorf("state rows = %d, want one per item (%d)", rows, len(remContent))
+	}
+	// Every user edit creates a unique title, so repeats are copies made
+	// when a create succeeded but reported failure.
+	t.Logf("items duplicated by partial creates: %d", len(remContent)-len(slices.Compact(slices.Clone(remContent))))
+}
+
+// soakEdit applies one random user edit to either side. n makes new titles
+// unique, as HA identifies items by title.
+func soakEdit(rng *rand.Rand, rem *mockReminders, ha *mockHA, now time.Time, n int) {
+	onRem := rng.IntN(2) == 0
+	var existing []*model.Item
+	if onRem {
+		for _, it := range rem.items {
+			existing = append(existing, it)
+		}
+	} else {
+		for i := range ha.items["todo.shopping"] {
+			existing = append(existing, &ha.items["todo.shopping"][i])
+		}
+	}
+	slices.SortFunc(existing, func(a, b *model.Item) int { return strings.Compare(a.Title, b.Title) })
+
+	if len(existing) == 0 || rng.IntN(3) == 0 {
+		item := model.Item{Title: fmt.Sprintf("item %d", n), ModifiedAt: now}
+		if onRem {
+			_, _ = rem.Create(context.Background(), "Shopping", &item)
+		} else {
+			ha.addItems("todo.shopping", model.Item{UID: fmt.Sprintf("ha-user-%d", n), Title: item.Title, ModifiedAt: now})
+		}
+		return
+	}
+
+	target := existing[rng.IntN(len(existing))]
+	switch rng.IntN(3) {
+	case 0:
+		target.Title = fmt.Sprintf("item %d", n)
+	case 1:
+		target.Completed = !target.Completed
+	default:
+		if onRem {
+			delete(rem.items, target.UID)
+		} else {
+			_ = ha.Delete(context.Background(), "todo.shopping", target)
+		}
+		return
+	}
+	target.ModifiedAt = now
+}
+
+// haItems returns the HA items as pointers.
+func haItems(ha *mockHA) map[string]*model.Item {
+	out := make(map[string]*model.Item)
+	for i, it := range ha.items["todo.shopping"] {
+		out[fmt.Sprint(i)] = &it
+	}
+	return out
+}
+
+// soakContent renders every item's synced content, sorted.
+func soakContent(items map[string]*model.Item) []string {
+	out := make([]string, 0, len(items))
+	for _, it := range items {
+		out = append(out, fmt.Sprintf("%s/%t", it.Title, it.Completed))
+	}
+	slices.Sort(out)
+	return out
+}
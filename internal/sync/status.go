@@ -0,0 +1,230 @@
+package sync
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// Bounds on the history kept for [Engine.Status].
+const (
+	maxRecentConflicts = 50
+	maxPassHistory     = 20
+)
+
+// Status is a point-in-time view of a running engine, served to the CLI over
+// the control socket and shown on the dashboard.
+type Status struct {
+	Paused bool
+	// Passes counts full reconcile passes since the engine was created.
+	Passes int
+	// LastPassAt is when the latest full pass finished; zero before the
+	// first one.
+	LastPassAt time.Time
+	LastStats  Stats
+	LastError  string // empty when the latest pass succeeded
+	// History holds the most recent full passes, newest first.
+	History []PassRecord
+	// Conflicts holds the most recently resolved conflicts, newest first.
+	Conflicts []RecentConflict
+	// Latency summarises how quickly recent changes propagated, per
+	// direction. Nil until the first change was written.
+	Latency map[Direction]LatencySummary
+	// UnmappedLists names the Reminders lists that have no mapping, sorted.
+	// Only filled in with [WithListDiscovery].
+	UnmappedLists []string
+	// Duplicates lists the titles the latest full pass found on several
+	// items of a list, sorted by list and title.
+	Duplicates []Duplicate
+}
+
+// PassRecord is the outcome of one full pass.
+type PassRecord struct {
+	At    time.Time
+	Stats Stats // without Resolved
+	Error string
+}
+
+// RecentConflict is a resolved conflict and when the engine resolved it.
+type RecentConflict struct {
+	Conflict
+	// ID identifies the conflict for [Engine.ResolveConflict]. IDs are
+	// unique within one engine's lifetime.
+	ID int
+	At time.Time
+	// Reviewed is set once the user has confirmed or overridden the
+	// resolution.
+	Reviewed bool
+}
+
+// Pause stops scheduled and WebSocket-triggered passes until [Engine.Resume].
+// [Engine.SyncNow] still runs while paused.
+func (e *Engine) Pause() {
+	if !e.paused.Swap(true) {
+		e.log.Info("sync paused")
+	}
+}
+
+// Resume undoes [Engine.Pause].
+func (e *Engine) Resume() {
+	if e.paused.Swap(false) {
+		e.log.Info("sync resumed")
+	}
+}
+
+// SyncNow runs a full pass immediately, even while paused, and returns its
+// result. It waits for a pass already in progress to finish first.
+func (e *Engine) SyncNow(ctx context.Context) (Stats, error) {
+	e.log.Info("sync requested via control socket")
+	return e.reconcile(ctx)
+}
+
+// SelectMappings returns the mappings of listMappings for the Reminders list
+// list and with the target target. An empty list or target matches any.
+func SelectMappings(listMappings map[string]string, list, target string) map[string]string {
+	selected := make(map[string]string)
+	for name, t := range listMappings {
+		if (list == "" || name == list) && (target == "" || t == target) {
+			selected[name] = t
+		}
+	}
+	return selected
+}
+
+// SyncSelected runs a pass over the mappings chosen as by [SelectMappings]
+// only, even while paused, leaving every other list alone. Renames and new
+// lists are not looked for, and the pass does not count as a full one in
+// [Engine.Status]. It returns an error when no mapping matches.
+func (e *Engine) SyncSelected(ctx context.Context, list, target string) (Stats, error) {
+	e.passMu.Lock()
+	defer e.passMu.Unlock()
+
+	selected := SelectMappings(e.listMappings, list, target)
+	if len(selected) == 0 {
+		return Stats{}, fmt.Errorf("no list mapping matches %s", describeSelection(list, target))
+	}
+	e.log.Info("sync of selected lists requested", "lists", slices.Sorted(maps.Keys(selected)))
+	started := e.clock.Now()
+	stats, err := e.reconciler.Run(ctx, selected)
+	e.recordRun(ctx, runSelected, started, stats, err)
+	e.recordMetrics(ctx, stats)
+	e.reportConflicts(ctx, stats)
+	e.recordLatencies(ctx, stats.Latencies)
+	return stats, err
+}
+
+// describeSelection names the mappings chosen by a list and a target.
+func describeSelection(list, target string) string {
+	switch {
+	case list != "" && target != "":
+		return fmt.Sprintf("list %q with target %s", list, target)
+	case list != "":
+		return fmt.Sprintf("list %q", list)
+	default:
+		return fmt.Sprintf("target %s", target)
+	}
+}
+
+// Status returns a snapshot of the engine's progress.
+func (e *Engine) Status() Status {
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	st := e.status
+	st.Paused = e.paused.Load()
+	st.History = newestFirst(e.status.History)
+	st.Conflicts = newestFirst(e.status.Conflicts)
+	st.Latency = e.latencySummaries()
+	return st
+}
+
+// newestFirst returns a reversed copy of s.
+func newestFirst[T any](s []T) []T {
+	out := make([]T, len(s))
+	for i, v := range s {
+		out[len(s)-1-i] = v
+	}
+	return out
+}
+
+// recordPass stores the outcome of a full pass for [Engine.Status].
+func (e *Engine) recordPass(stats Stats, err error) {
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	rec := PassRecord{At: e.clock.Now(), Stats: stats}
+	rec.Stats.Resolved = nil  // kept in Conflicts instead
+	rec.Stats.Latencies = nil // summarised in Latency instead
+	rec.Stats.Duplicates = nil
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	e.status.Passes++
+	e.status.LastPassAt = rec.At
+	e.status.LastStats = rec.Stats
+	e.status.LastError = rec.Error
+	e.status.History = appendBounded(e.status.History, maxPassHistory, rec)
+
+	e.status.Duplicates = slices.Clone(stats.Duplicates)
+	slices.SortFunc(e.status.Duplicates, func(a, b Duplicate) int {
+		return cmp.Or(cmp.Compare(a.ListName, b.ListName), cmp.Compare(a.Title, b.Title))
+	})
+}
+
+// Kinds of sync runs recorded in the state DB, by what started the pass.
+const (
+	runFull      = "full"      // a full pass: poll, startup or on request
+	runHAEvent   = "ha_event"  // a WebSocket event for one HA entity
+	runReminders = "reminders" // a Reminders change notification
+	runSelected  = "selected"  // a pass over chosen lists on request
+)
+
+// recordRun stores the outcome of a pass started at started in the state DB,
+// where it outlives the process for `reminderrelay stats`.
+func (e *Engine) recordRun(ctx context.Context, kind string, started time.Time, stats Stats, err error) {
+	run := state.SyncRun{
+		Kind:      kind,
+		StartedAt: started,
+		Duration:  e.clock.Now().Sub(started),
+		Created:   stats.Created,
+		Updated:   stats.Updated,
+		Deleted:   stats.Deleted,
+		Conflicts: stats.Conflicts,
+		Errors:    stats.Errors,
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+	// A pass cut short by shutdown is still recorded.
+	if rerr := e.reconciler.store.RecordSyncRun(context.WithoutCancel(ctx), run); rerr != nil {
+		e.log.Warn("could not record sync run", "kind", kind, "error", rerr)
+	}
+}
+
+// recordConflicts keeps resolved for [Engine.Status], oldest first.
+func (e *Engine) recordConflicts(resolved []Conflict) {
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	now := e.clock.Now()
+	for _, c := range resolved {
+		e.nextConflictID++
+		e.status.Conflicts = appendBounded(e.status.Conflicts, maxRecentConflicts,
+			RecentConflict{Conflict: c, ID: e.nextConflictID, At: now})
+	}
+}
+
+// appendBounded appends v to s, dropping the oldest entries beyond limit.
+func appendBounded[T any](s []T, limit int, v T) []T {
+	s = append(s, v)
+	if extra := len(s) - limit; extra > 0 {
+		s = append([]T(nil), s[extra:]...)
+	}
+	return s
+}
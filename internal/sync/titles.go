@@ -0,0 +1,107 @@
+package sync
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// typography folds characters that keyboards and autocorrect substitute for
+// each other, so "Don’t" and "Don't" compare equal.
+var typography = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'", "′", "'",
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`, "″", `"`,
+	"‐", "-", "‑", "-", "‒", "-", "–", "-", "—", "-",
+	"…", "...",
+)
+
+// titleKey is the form in which titles are compared: Unicode NFC, with
+// typographic quotes and dashes folded to ASCII, runs of whitespace collapsed
+// to one space, trimmed, and lower-cased.
+func titleKey(title string) string {
+	return strings.ToLower(keyNormalization.Apply(title))
+}
+
+// keyNormalization is every step of [Normalization], as titleKey applies it.
+var keyNormalization = Normalization{Trim: true, CollapseSpace: true, NFC: true, Punctuation: true}
+
+// titleSimilarity rates how alike two titles are, from 0 to 1 for equal
+// keys: one minus the edit distance between their keys over the length of
+// the longer key, in runes.
+func titleSimilarity(a, b string) float64 {
+	ka, kb := []rune(titleKey(a)), []rune(titleKey(b))
+	longest := max(len(ka), len(kb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(ka, kb))/float64(longest)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// fuzzyPairs pairs items of remItems with items of haItems whose titles are
+// at least threshold alike, most alike first. Each item is used once.
+func fuzzyPairs(remItems, haItems []*model.Item, threshold float64) []matchedPair {
+	type candidate struct {
+		pair  matchedPair
+		score float64
+	}
+	var candidates []candidate
+	for _, rem := range remItems {
+		for _, ha := range haItems {
+			if score := titleSimilarity(rem.Title, ha.Title); score >= threshold {
+				candidates = append(candidates, candidate{matchedPair{rem: rem, ha: ha, fuzzy: true}, score})
+			}
+		}
+	}
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(cmp.Compare(b.score, a.score),
+			cmp.Compare(a.pair.rem.Title, b.pair.rem.Title), cmp.Compare(a.pair.ha.Title, b.pair.ha.Title))
+	})
+
+	usedRem := make(map[*model.Item]bool)
+	usedHA := make(map[*model.Item]bool)
+	var pairs []matchedPair
+	for _, c := range candidates {
+		if usedRem[c.pair.rem] || usedHA[c.pair.ha] {
+			continue
+		}
+		usedRem[c.pair.rem], usedHA[c.pair.ha] = true, true
+		pairs = append(pairs, c.pair)
+	}
+	return pairs
+}
+
+// ClosestTitle returns the candidate most like name, for suggesting a
+// correction of a misspelt list or entity name. It reports false when no
+// candidate is at least half alike. Ties go to the candidate sorted first.
+func ClosestTitle(name string, candidates []string) (string, bool) {
+	best, bestScore := "", 0.0
+	for _, c := range slices.Sorted(slices.Values(candidates)) {
+		if score := titleSimilarity(name, c); score >= 0.5 && score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,60 @@
+package sync
+
+import "testing"
+
+func TestTitleKey(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"Buy milk ", "buy milk"},
+		{"  Buy \t milk", "Buy milk"},
+		{"Don’t forget", "Don't forget"},
+		{"“Quoted”", `"quoted"`},
+		{"Pages 3–5", "pages 3-5"},
+		{"Café", "Café"},
+	}
+	for _, tt := range tests {
+		if titleKey(tt.a) != titleKey(tt.b) {
+			t.Errorf("titleKey(%q) = %q, titleKey(%q) = %q; want equal", tt.a, titleKey(tt.a), tt.b, titleKey(tt.b))
+		}
+	}
+	if titleKey("Milk") == titleKey("Milks") {
+		t.Error("different titles share a key")
+	}
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"Buy milk", "buy milk ", 1, 1},
+		{"Call the plumber", "Call the plumbers", 0.9, 0.99},
+		{"Buy milk", "Walk the dog", 0, 0.3},
+		{"", "", 1, 1},
+	}
+	for _, tt := range tests {
+		if got := titleSimilarity(tt.a, tt.b); got < tt.min || got > tt.max {
+			t.Errorf("titleSimilarity(%q, %q) = %.2f, want within [%.2f, %.2f]", tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestClosestTitle(t *testing.T) {
+	candidates := []string{"Work", "Shopping", "todo.shopping"}
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{"Shoping", "Shopping", true},
+		{"todo.shoping", "todo.shopping", true},
+		{"work ", "Work", true},
+		{"Garden", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ClosestTitle(tt.name, candidates)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ClosestTitle(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
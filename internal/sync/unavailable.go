@@ -0,0 +1,83 @@
+package sync
+
+import "time"
+
+// A list whose target is unavailable is tried again after
+// unavailableRetry, the interval doubling with every further failed try up
+// to maxUnavailableRetry. Passes in between skip it without asking.
+const (
+	unavailableRetry    = 30 * time.Second
+	maxUnavailableRetry = 10 * time.Minute
+)
+
+// unavailability tracks a list whose target reported [model.ErrListUnavailable].
+type unavailability struct {
+	since time.Time // first failed try
+	next  time.Time // no try before then
+	tries int
+}
+
+// skipUnavailable reports whether the pass skips listName because its
+// target was unavailable at the last try and the retry is not yet due.
+func (r *Reconciler) skipUnavailable(listName string) bool {
+	r.unavailMu.Lock()
+	defer r.unavailMu.Unlock()
+	u := r.unavailable[listName]
+	return u != nil && r.clock.Now().Before(u.next)
+}
+
+// markUnavailable records that the target of listName could not be read
+// because it is unavailable. Only the first failed try is logged as a
+// warning, with when the list is tried again.
+func (r *Reconciler) markUnavailable(listName, targetName string, err error) {
+	r.unavailMu.Lock()
+	defer r.unavailMu.Unlock()
+	now := r.clock.Now()
+	u := r.unavailable[listName]
+	if u == nil {
+		u = &unavailability{since: now}
+		if r.unavailable == nil {
+			r.unavailable = make(map[string]*unavailability)
+		}
+		r.unavailable[listName] = u
+	}
+	retry := unavailableRetry
+	for i := 0; i < u.tries && retry < maxUnavailableRetry; i++ {
+		retry *= 2
+	}
+	retry = min(retry, maxUnavailableRetry)
+	u.tries++
+	u.next = now.Add(retry)
+
+	if u.tries == 1 {
+		r.log.Warn("list target unavailable, skipping the list until it is back",
+			"list", listName, "entity", targetName, "retry_after", retry, "error", err)
+		return
+	}
+	r.log.Debug("list target still unavailable",
+		"list", listName, "entity", targetName, "since", u.since, "retry_after", retry)
+}
+
+// markAvailable forgets that the target of listName was unavailable, logging
+// the end of the outage if it was.
+func (r *Reconciler) markAvailable(listName, targetName string) {
+	r.unavailMu.Lock()
+	defer r.unavailMu.Unlock()
+	u := r.unavailable[listName]
+	if u == nil {
+		return
+	}
+	delete(r.unavailable, listName)
+	r.log.Info("list target available again",
+		"list", listName, "entity", targetName, "unavailable_for", r.clock.Now().Sub(u.since).Round(time.Second))
+}
+
+// retryUnavailable lets the next pass of listName try its target again
+// whatever the retry interval says, as after HA reported a change to it.
+func (r *Reconciler) retryUnavailable(listName string) {
+	r.unavailMu.Lock()
+	defer r.unavailMu.Unlock()
+	if u := r.unavailable[listName]; u != nil {
+		u.next = time.Time{}
+	}
+}
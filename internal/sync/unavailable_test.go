@@ -0,0 +1,88 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+// unavailableHA is a mockHA whose entities can be made unavailable, as
+// while their integration reloads. It counts fetches.
+type unavailableHA struct {
+	*mockHA
+	down    bool
+	fetches int
+}
+
+func (u *unavailableHA) Fetch(ctx context.Context, lists []string) ([]*model.Item, error) {
+	u.fetches++
+	if u.down {
+		return nil, fmt.Errorf("%s is unavailable: %w", lists[0], model.ErrListUnavailable)
+	}
+	return u.mockHA.Fetch(ctx, lists)
+}
+
+func TestReconcile_SkipsUnavailableTarget(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	older := clk.Now().Add(-time.Hour)
+	remItem := newItem("rem-1", "Buy milk", "Shopping", model.PriorityNone, false, older)
+	store := newMockStore()
+	store.seed(&state.Item{
+		RemindersUID: "rem-1",
+		HAUID:        "ha-1",
+		ListName:     "Shopping",
+		Title:        "Buy milk",
+		LastSyncHash: remItem.ContentHash(),
+		LastSyncedAt: older,
+	})
+	rem := newMockReminders(remItem)
+	ha := &unavailableHA{mockHA: newMockHA(), down: true}
+	ha.addItems("todo.shopping", model.Item{UID: "ha-1", Title: "Buy milk", ModifiedAt: older})
+	r := NewReconciler(rem, NewRegistry(ha), store, testLogger, WithClock(clk))
+	ctx := context.Background()
+
+	pass := func() Stats {
+		t.Helper()
+		stats, err := r.Run(ctx, testMappings)
+		if err != nil {
+			t.Fatalf("Run() error = %v, want the list skipped", err)
+		}
+		return stats
+	}
+
+	if stats := pass(); stats.Deleted != 0 || stats.Errors != 0 || len(stats.ListErrors) != 0 {
+		t.Errorf("stats = %+v, want nothing done and no error", stats)
+	}
+	if rem.count() != 1 || store.count() != 1 {
+		t.Errorf("%d reminders, %d state rows, want the item kept", rem.count(), store.count())
+	}
+
+	// Until the retry is due, passes do not even ask.
+	clk.Advance(unavailableRetry / 2)
+	pass()
+	if ha.fetches != 1 {
+		t.Errorf("%d fetches, want the list skipped before its retry", ha.fetches)
+	}
+	clk.Advance(unavailableRetry / 2)
+	pass()
+	if ha.fetches != 2 {
+		t.Errorf("%d fetches, want one retry once due", ha.fetches)
+	}
+
+	// An event from the entity retries it straight away.
+	ha.down = false
+	if _, err := r.ReconcileEntity(ctx, "Shopping", "todo.shopping"); err != nil {
+		t.Fatalf("ReconcileEntity() error = %v", err)
+	}
+	if ha.fetches != 3 || r.skipUnavailable("Shopping") {
+		t.Errorf("%d fetches, skipped = %v; want the list synced again", ha.fetches, r.skipUnavailable("Shopping"))
+	}
+	if rem.count() != 1 || store.count() != 1 {
+		t.Errorf("%d reminders, %d state rows, want the item kept", rem.count(), store.count())
+	}
+}
@@ -0,0 +1,260 @@
+package sync
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// FindingKind classifies a [Finding].
+type FindingKind string
+
+const (
+	// FindingOrphaned is a state row whose item is gone on both sides.
+	FindingOrphaned FindingKind = "orphaned"
+	// FindingUntracked is an item no state row links to the other side.
+	FindingUntracked FindingKind = "untracked"
+	// FindingStuck is a linked pair whose content differs although neither
+	// side changed for a while, so syncing does not settle it.
+	FindingStuck FindingKind = "stuck"
+	// FindingCollision is a UID carried by several items.
+	FindingCollision FindingKind = "collision"
+)
+
+// Finding is one inconsistency between the state DB and the live items.
+type Finding struct {
+	Kind     FindingKind
+	ListName string
+	Title    string
+	// Side is "reminders" or "ha" for findings about one side's item, and
+	// empty for findings about a state row or a pair.
+	Side   string
+	UID    string
+	Detail string
+}
+
+// Verifier cross-checks the state DB against the live items of both sides
+// without changing either. Findings that a running daemon settles within a
+// pass, such as an edit not yet synced, are not reported.
+type Verifier struct {
+	rem     TaskBackend
+	targets *Registry
+	store   StateStore
+	clock   clock.Clock
+	settle  time.Duration
+}
+
+// NewVerifier creates a Verifier for the Reminders backend rem and the list
+// mapping targets in targets, with state in store. Linked items that differ
+// are reported once neither changed for settle.
+func NewVerifier(rem TaskBackend, targets *Registry, store StateStore, settle time.Duration) *Verifier {
+	return &Verifier{rem: rem, targets: targets, store: store, clock: clock.Real(), settle: settle}
+}
+
+// verifyList is the live items of one list mapping.
+type verifyList struct {
+	name   string
+	target string
+	rem    []*model.Item
+	ha     []*model.Item
+	drops  model.Fields // fields the target does not store
+}
+
+// Verify returns the findings for listMappings, sorted by list, kind, and
+// title.
+func (v *Verifier) Verify(ctx context.Context, listMappings map[string]string) ([]Finding, error) {
+	lists, err := v.load(ctx, listMappings)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	remLists := make(map[string][]string) // Reminders UID → lists carrying it
+	haLists := make(map[string][]string)  // HA UID → lists carrying it
+	for _, l := range lists {
+		for _, it := range l.rem {
+			remLists[it.UID] = append(remLists[it.UID], l.name)
+		}
+		for _, it := range l.ha {
+			haLists[it.UID] = append(haLists[it.UID], l.name)
+		}
+	}
+	for _, l := range lists {
+		findings = append(findings, collisions(l.name, "reminders", l.rem, remLists)...)
+		findings = append(findings, collisions(l.name, "ha", l.ha, haLists)...)
+		fs, err := v.verifyList(ctx, l)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, fs...)
+	}
+
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return cmp.Or(cmp.Compare(a.ListName, b.ListName), cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Title, b.Title))
+	})
+	return findings, nil
+}
+
+// load fetches the live items of listMappings, sorted by list name.
+func (v *Verifier) load(ctx context.Context, listMappings map[string]string) ([]verifyList, error) {
+	listNames := slices.Sorted(maps.Keys(listMappings))
+	remItems, err := v.rem.Fetch(ctx, listNames)
+	if err != nil {
+		return nil, fmt.Errorf("fetching reminders: %w", err)
+	}
+
+	lists := make([]verifyList, 0, len(listNames))
+	for _, name := range listNames {
+		l := verifyList{name: name, target: listMappings[name]}
+		for _, it := range remItems {
+			if it.ListName == name {
+				l.rem = append(l.rem, it)
+			}
+		}
+		b, list, err := v.targets.resolve(l.target, name)
+		if err != nil {
+			return nil, err
+		}
+		if l.ha, err = b.Fetch(ctx, []string{list}); err != nil {
+			return nil, fmt.Errorf("fetching HA items for %s: %w", list, err)
+		}
+		l.drops = unsupportedFields(b, list)
+		lists = append(lists, l)
+	}
+	return lists, nil
+}
+
+// verifyList checks the state rows of one list against its live items.
+func (v *Verifier) verifyList(ctx context.Context, l verifyList) ([]Finding, error) {
+	rows, err := v.store.GetAllItemsForList(ctx, l.name)
+	if err != nil {
+		return nil, fmt.Errorf("fetching state items for %q: %w", l.name, err)
+	}
+	remByUID := indexByUID(l.rem)
+	haByUID := indexByUID(l.ha)
+	tracked := make(map[string]bool, 2*len(rows))
+
+	var findings []Finding
+	for _, row := range rows {
+		tracked["reminders/"+row.RemindersUID] = true
+		tracked["ha/"+row.HAUID] = true
+		rem, ha := remByUID[row.RemindersUID], haByUID[row.HAUID]
+		if rem != nil && ha != nil && l.drops != 0 {
+			// The target cannot differ in fields it does not store.
+			cp := *ha
+			copyFields(&cp, rem, l.drops)
+			ha = &cp
+		}
+		switch {
+		case rem == nil && ha == nil:
+			findings = append(findings, Finding{
+				Kind: FindingOrphaned, ListName: l.name, Title: row.Title,
+				Detail: "gone on both sides; the next pass removes the row",
+			})
+		case rem != nil && ha != nil && rem.ContentHash() != ha.ContentHash():
+			last := latest(rem.ModifiedAt, ha.ModifiedAt, row.LastSyncedAt)
+			if v.clock.Now().Sub(last) < v.settle {
+				continue // still being synced
+			}
+			findings = append(findings, Finding{
+				Kind: FindingStuck, ListName: l.name, Title: row.Title,
+				Detail: fmt.Sprintf("differs in %s since %s", diffFields(rem, ha), last.Local().Format(time.DateTime)),
+			})
+		}
+	}
+
+	for _, side := range []struct {
+		name  string
+		items []*model.Item
+		other string
+	}{{"reminders", l.rem, l.target}, {"ha", l.ha, "Reminders"}} {
+		for _, it := range side.items {
+			if tracked[side.name+"/"+it.UID] {
+				continue
+			}
+			findings = append(findings, Finding{
+				Kind: FindingUntracked, ListName: l.name, Title: it.Title, Side: side.name, UID: it.UID,
+				Detail: "not linked; the next pass copies it to " + side.other,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// collisions reports the items of listName on side whose UID another item
+// carries, in the same list or another one per lists.
+func collisions(listName, side string, items []*model.Item, lists map[string][]string) []Finding {
+	var findings []Finding
+	reported := make(map[string]bool)
+	for _, it := range items {
+		carriers := lists[it.UID]
+		if len(carriers) < 2 || reported[it.UID] {
+			continue
+		}
+		reported[it.UID] = true
+		findings = append(findings, Finding{
+			Kind: FindingCollision, ListName: listName, Title: it.Title, Side: side, UID: it.UID,
+			Detail: fmt.Sprintf("UID carried by %d items (lists: %s)", len(carriers), joinUnique(carriers)),
+		})
+	}
+	return findings
+}
+
+// indexByUID maps the UIDs of items to the items.
+func indexByUID(items []*model.Item) map[string]*model.Item {
+	m := make(map[string]*model.Item, len(items))
+	for _, it := range items {
+		m[it.UID] = it
+	}
+	return m
+}
+
+// latest returns the latest of times.
+func latest(times ...time.Time) time.Time {
+	var l time.Time
+	for _, t := range times {
+		if t.After(l) {
+			l = t
+		}
+	}
+	return l
+}
+
+// diffFields names the content fields in which a and b differ.
+func diffFields(a, b *model.Item) string {
+	return fieldNames(model.ChangedFields(a, b))
+}
+
+// fieldNames names the content fields in fields.
+func fieldNames(fields model.Fields) string {
+	var names []string
+	for _, f := range []struct {
+		field model.Fields
+		name  string
+	}{
+		{model.FieldTitle, "title"},
+		{model.FieldDescription, "description"},
+		{model.FieldDueDate, "due_date"},
+		{model.FieldPriority, "priority"},
+		{model.FieldCompleted, "completed"},
+		{model.FieldAlert, "alert"},
+		{model.FieldURL, "url"},
+	} {
+		if fields.Has(f.field) {
+			names = append(names, f.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// joinUnique joins the distinct strings of s, sorted, with commas.
+func joinUnique(s []string) string {
+	return strings.Join(slices.Compact(slices.Sorted(slices.Values(s))), ", ")
+}
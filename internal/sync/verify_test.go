@@ -0,0 +1,88 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/clock"
+	"github.com/njoerd114/reminderrelay/internal/model"
+	"github.com/njoerd114/reminderrelay/internal/state"
+)
+
+func TestVerifier_ReportsFindings(t *testing.T) {
+	at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	rem := newMockReminders(
+		newItem("rem-ok", "In sync", "Shopping", model.PriorityNone, false, at),
+		newItem("rem-stuck", "Bread", "Shopping", model.PriorityHigh, false, at),
+		newItem("rem-new", "Only in Reminders", "Shopping", model.PriorityNone, false, at),
+		newItem("rem-fresh", "Eggs", "Shopping", model.PriorityLow, false, at.Add(time.Hour)),
+	)
+	ha := newMockHA()
+	ha.addItems("todo.shopping",
+		model.Item{UID: "ha-ok", Title: "In sync", ModifiedAt: at},
+		model.Item{UID: "ha-stuck", Title: "Bread", ModifiedAt: at},
+		model.Item{UID: "ha-fresh", Title: "Eggs", ModifiedAt: at},
+		model.Item{UID: "ha-twice", Title: "Milk", ModifiedAt: at},
+		model.Item{UID: "ha-twice", Title: "Milk", ModifiedAt: at},
+	)
+	store := newMockStore()
+	for _, row := range []*state.Item{
+		{RemindersUID: "rem-ok", HAUID: "ha-ok", Title: "In sync"},
+		{RemindersUID: "rem-stuck", HAUID: "ha-stuck", Title: "Bread"},
+		{RemindersUID: "rem-fresh", HAUID: "ha-fresh", Title: "Eggs"},
+		{RemindersUID: "rem-gone", HAUID: "ha-gone", Title: "Gone"},
+	} {
+		row.ListName = "Shopping"
+		row.LastSyncedAt = at
+		store.seed(row)
+	}
+
+	v := NewVerifier(rem, NewRegistry(ha), store, 10*time.Minute)
+	v.clock = clock.NewFake(at.Add(time.Hour + 5*time.Minute))
+	findings, err := v.Verify(context.Background(), testMappings)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	type key struct {
+		kind  FindingKind
+		title string
+	}
+	got := make(map[key]int)
+	for _, f := range findings {
+		got[key{f.Kind, f.Title}]++
+	}
+	want := map[key]int{
+		{FindingCollision, "Milk"}:              1,
+		{FindingOrphaned, "Gone"}:               1,
+		{FindingStuck, "Bread"}:                 1,
+		{FindingUntracked, "Milk"}:              2,
+		{FindingUntracked, "Only in Reminders"}: 1,
+	}
+	if len(got) != len(want) {
+		t.Errorf("findings = %+v, want %v", findings, want)
+	}
+	for k, n := range want {
+		if got[k] != n {
+			t.Errorf("%s %q reported %d time(s), want %d", k.kind, k.title, got[k], n)
+		}
+	}
+	// Eggs changed five minutes ago and may still be syncing.
+	if got[key{FindingStuck, "Eggs"}] != 0 {
+		t.Error("a recent difference was reported as stuck")
+	}
+}
+
+func TestVerifier_ChangesNothing(t *testing.T) {
+	rem, ha, store := duplicatedMilk()
+	ha.addItems("todo.shopping", model.Item{UID: "ha-3", Title: "Only in HA"})
+	v := NewVerifier(rem, NewRegistry(ha), store, time.Minute)
+
+	if _, err := v.Verify(context.Background(), testMappings); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if rem.count() != 2 || len(ha.getItems("todo.shopping")) != 3 || len(store.items) != 2 {
+		t.Error("Verify changed items or state")
+	}
+}
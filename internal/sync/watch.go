@@ -0,0 +1,95 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/njoerd114/reminderrelay/internal/model"
+)
+
+// RemindersWatcher reports changes to the Reminders database as they happen.
+// Implemented by [reminders.Adapter].
+type RemindersWatcher interface {
+	// WatchChanges calls changed after every change, from any process,
+	// until ctx is cancelled.
+	WatchChanges(ctx context.Context, changed func()) error
+}
+
+// WithRemindersWatcher reconciles the lists whose Reminders items changed as
+// soon as w reports a change, and makes full passes run only every
+// pollInterval, as a safety net against missed notifications. The longer
+// interval applies only while the HA WebSocket reports changes to every HA
+// list; otherwise passes keep the engine's poll interval.
+func WithRemindersWatcher(w RemindersWatcher, pollInterval time.Duration) EngineOption {
+	return func(e *Engine) {
+		e.watcher = w
+		e.safetyPoll = pollInterval
+	}
+}
+
+// watchReminders runs the Reminders change watcher until ctx is cancelled,
+// passing the outcome of every triggered pass to done.
+func (e *Engine) watchReminders(ctx context.Context, done func(Stats, error)) {
+	err := e.watcher.WatchChanges(ctx, func() {
+		if e.paused.Load() {
+			return
+		}
+		e.log.Debug("Reminders change triggered reconcile")
+		stats, err := e.reconcileReminders(ctx)
+		if err != nil {
+			e.log.Error("Reminders-triggered reconcile failed", "error", err)
+		}
+		done(stats, err)
+	})
+	if err != nil && ctx.Err() == nil {
+		e.log.Error("Reminders change notifications ended, relying on polling", "error", err)
+	}
+}
+
+// reconcileReminders reconciles the lists whose Reminders items changed,
+// serialised with full passes.
+func (e *Engine) reconcileReminders(ctx context.Context) (Stats, error) {
+	e.passMu.Lock()
+	defer e.passMu.Unlock()
+
+	started := e.clock.Now()
+	stats, err := e.reconciler.ReconcileReminders(ctx, e.listMappings)
+	e.recordRun(ctx, runReminders, started, stats, err)
+	e.recordMetrics(ctx, stats)
+	e.reportConflicts(ctx, stats)
+	e.recordLatencies(ctx, stats.Latencies)
+	return stats, err
+}
+
+// ReconcileReminders reconciles the lists of listMappings whose Reminders
+// items changed since they last reconciled cleanly. Called when EventKit
+// reports a store change, which does not say what changed.
+func (r *Reconciler) ReconcileReminders(ctx context.Context, listMappings map[string]string) (Stats, error) {
+	seen := r.clock.Now()
+
+	listNames := make([]string, 0, len(listMappings))
+	for name := range listMappings {
+		listNames = append(listNames, name)
+	}
+	defer r.lockLists(listNames)()
+	ctx, cancel := r.passContext(ctx)
+	defer cancel()
+
+	remItems, err := r.rem.Fetch(ctx, listNames)
+	if err != nil {
+		return Stats{}, fmt.Errorf("fetching reminders: %w", err)
+	}
+	remByUID := make(map[string]*model.Item, len(remItems))
+	for _, item := range remItems {
+		remByUID[item.UID] = item
+	}
+
+	var changed []string
+	for _, listName := range listNames {
+		if r.remindersChanged(listName, remByUID) {
+			changed = append(changed, listName)
+		}
+	}
+	return r.reconcileLists(ctx, listMappings, changed, remByUID, seen)
+}
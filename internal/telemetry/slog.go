@@ -0,0 +1,204 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// logScope is the instrumentation scope of bridged log records.
+const logScope = "reminderrelay"
+
+// LogHandler returns a handler that passes every record to h and, from level
+// up, to the global OTel LoggerProvider, so daemon logs reach the collector
+// alongside traces and metrics. Until [Setup] installs a provider the second
+// half is a no-op.
+func LogHandler(h slog.Handler, level slog.Leveler) slog.Handler {
+	bridge := &bridgeHandler{logger: global.GetLoggerProvider().Logger(logScope), level: level}
+	return fanoutHandler{h, bridge}
+}
+
+// fanoutHandler passes records to each of its handlers that is enabled for
+// them.
+type fanoutHandler []slog.Handler
+
+func (f fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var first error
+	for _, h := range f {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		// Handlers may keep the record, so each gets its own copy.
+		if err := h.Handle(ctx, r.Clone()); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (f fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(fanoutHandler, len(f))
+	for i, h := range f {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (f fanoutHandler) WithGroup(name string) slog.Handler {
+	out := make(fanoutHandler, len(f))
+	for i, h := range f {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
+
+// bridgeHandler converts slog records into OTel log records. Attributes
+// added under a group are nested in a map value named after the group, as
+// slog's own handlers qualify their keys.
+type bridgeHandler struct {
+	logger otellog.Logger
+	level  slog.Leveler
+
+	attrs  []otellog.KeyValue // added with WithAttrs, outside any group
+	groups []bridgeGroup      // open groups, outermost first
+}
+
+// bridgeGroup is a group opened with WithGroup and the attributes added to it
+// since.
+type bridgeGroup struct {
+	name  string
+	attrs []otellog.KeyValue
+}
+
+func (b *bridgeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level < b.level.Level() {
+		return false
+	}
+	return b.logger.Enabled(ctx, otellog.EnabledParameters{Severity: severity(level)})
+}
+
+func (b *bridgeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var rec otellog.Record
+	rec.SetTimestamp(r.Time)
+	rec.SetObservedTimestamp(time.Now())
+	rec.SetSeverity(severity(r.Level))
+	rec.SetSeverityText(r.Level.String())
+	rec.SetBody(otellog.StringValue(r.Message))
+
+	var attrs []otellog.KeyValue
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendAttr(attrs, a)
+		return true
+	})
+	// Close the open groups from the innermost out, each holding the
+	// attributes added to it and everything nested deeper.
+	for i := len(b.groups) - 1; i >= 0; i-- {
+		g := b.groups[i]
+		inner := append(append([]otellog.KeyValue(nil), g.attrs...), attrs...)
+		attrs = nil
+		if len(inner) > 0 {
+			attrs = []otellog.KeyValue{otellog.Map(g.name, inner...)}
+		}
+	}
+	rec.AddAttributes(b.attrs...)
+	rec.AddAttributes(attrs...)
+
+	b.logger.Emit(ctx, rec)
+	return nil
+}
+
+func (b *bridgeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var kvs []otellog.KeyValue
+	for _, a := range attrs {
+		kvs = appendAttr(kvs, a)
+	}
+	out := b.clone()
+	if n := len(out.groups); n > 0 {
+		out.groups[n-1].attrs = append(out.groups[n-1].attrs, kvs...)
+	} else {
+		out.attrs = append(out.attrs, kvs...)
+	}
+	return out
+}
+
+func (b *bridgeHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return b
+	}
+	out := b.clone()
+	out.groups = append(out.groups, bridgeGroup{name: name})
+	return out
+}
+
+// clone returns a copy of b that can be extended without affecting b.
+func (b *bridgeHandler) clone() *bridgeHandler {
+	out := *b
+	out.attrs = append([]otellog.KeyValue(nil), b.attrs...)
+	out.groups = make([]bridgeGroup, len(b.groups))
+	for i, g := range b.groups {
+		out.groups[i] = bridgeGroup{name: g.name, attrs: append([]otellog.KeyValue(nil), g.attrs...)}
+	}
+	return &out
+}
+
+// severity maps a slog level onto the OTel severity range: Debug, Info, Warn
+// and Error become DEBUG, INFO, WARN and ERROR, and levels in between keep
+// their offset.
+func severity(level slog.Level) otellog.Severity {
+	return otellog.Severity(level + 9)
+}
+
+// appendAttr converts a and appends it to kvs. Like slog's own handlers it
+// drops empty attributes and empty groups, and inlines groups without a key.
+func appendAttr(kvs []otellog.KeyValue, a slog.Attr) []otellog.KeyValue {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return kvs
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		return append(kvs, otellog.KeyValue{Key: a.Key, Value: convertValue(a.Value)})
+	}
+	var members []otellog.KeyValue
+	for _, ga := range a.Value.Group() {
+		members = appendAttr(members, ga)
+	}
+	switch {
+	case len(members) == 0:
+		return kvs
+	case a.Key == "":
+		return append(kvs, members...)
+	}
+	return append(kvs, otellog.Map(a.Key, members...))
+}
+
+// convertValue converts a resolved, non-group slog value.
+func convertValue(v slog.Value) otellog.Value {
+	switch v.Kind() {
+	case slog.KindBool:
+		return otellog.BoolValue(v.Bool())
+	case slog.KindInt64:
+		return otellog.Int64Value(v.Int64())
+	case slog.KindUint64:
+		return otellog.Int64Value(int64(v.Uint64()))
+	case slog.KindFloat64:
+		return otellog.Float64Value(v.Float64())
+	case slog.KindDuration:
+		return otellog.Int64Value(v.Duration().Nanoseconds())
+	case slog.KindTime:
+		return otellog.StringValue(v.Time().Format(time.RFC3339Nano))
+	default:
+		return otellog.StringValue(v.String())
+	}
+}
@@ -0,0 +1,84 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/embedded"
+)
+
+// recordingLogger keeps every record emitted to it.
+type recordingLogger struct {
+	embedded.Logger
+
+	mu      sync.Mutex
+	records []otellog.Record
+}
+
+func (l *recordingLogger) Emit(_ context.Context, r otellog.Record) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, r)
+}
+
+func (l *recordingLogger) Enabled(context.Context, otellog.EnabledParameters) bool { return true }
+
+func attrsOf(r otellog.Record) map[string]otellog.Value {
+	attrs := make(map[string]otellog.Value)
+	r.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	return attrs
+}
+
+func TestLogHandler_FansOutToTextAndOTel(t *testing.T) {
+	var text bytes.Buffer
+	rec := &recordingLogger{}
+	h := fanoutHandler{
+		slog.NewTextHandler(&text, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		&bridgeHandler{logger: rec, level: slog.LevelInfo},
+	}
+	logger := slog.New(h).With("instance", "home")
+
+	logger.Debug("polling")
+	logger.WithGroup("list").With("name", "Shopping").Warn("list not synced", "items", 3)
+
+	if !strings.Contains(text.String(), "msg=polling") || !strings.Contains(text.String(), "list.items=3") {
+		t.Errorf("text output = %q, want both records", text.String())
+	}
+	if len(rec.records) != 1 {
+		t.Fatalf("bridged %d records, want only the one at info or above", len(rec.records))
+	}
+
+	r := rec.records[0]
+	if r.Body().AsString() != "list not synced" || r.Severity() != otellog.SeverityWarn || r.SeverityText() != "WARN" {
+		t.Errorf("record = %q at %v (%s), want the warning", r.Body().AsString(), r.Severity(), r.SeverityText())
+	}
+	attrs := attrsOf(r)
+	if attrs["instance"].AsString() != "home" {
+		t.Errorf("instance = %v, want home", attrs["instance"])
+	}
+	group := make(map[string]otellog.Value)
+	for _, kv := range attrs["list"].AsMap() {
+		group[kv.Key] = kv.Value
+	}
+	if group["name"].AsString() != "Shopping" || group["items"].AsInt64() != 3 {
+		t.Errorf("list group = %v, want name and items nested", attrs["list"])
+	}
+}
+
+func TestAppendAttr_DropsEmptyAndInlinesUnnamedGroups(t *testing.T) {
+	var kvs []otellog.KeyValue
+	kvs = appendAttr(kvs, slog.Attr{})
+	kvs = appendAttr(kvs, slog.Group("empty"))
+	kvs = appendAttr(kvs, slog.Group("", slog.Int("a", 1), slog.Bool("b", true)))
+	if len(kvs) != 2 || kvs[0].Key != "a" || kvs[1].Key != "b" || !kvs[1].Value.AsBool() {
+		t.Errorf("attrs = %v, want a and b inlined", kvs)
+	}
+}
@@ -1,6 +1,8 @@
 // Package telemetry initialises optional OpenTelemetry trace, metric, and log
-// providers backed by an OTLP gRPC collector. All three providers share a
-// single gRPC connection to reduce overhead.
+// providers backed by an OTLP collector, over gRPC or HTTP. Over gRPC all
+// three providers share a single connection to reduce overhead. For local
+// debugging, spans and metrics can instead be written as JSON to stdout or a
+// file.
 //
 // Call [Setup] once during startup. The returned [ShutdownFunc] must be called
 // before the process exits to flush pending telemetry.
@@ -13,11 +15,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/log/global"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
@@ -29,13 +40,38 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+// OTLP transport protocols accepted in [Config.Protocol].
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http"
+)
+
+// Exporters accepted in [Config.Exporter].
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
 // Config groups all telemetry settings. It maps 1-to-1 with the
 // [config.TelemetryConfig] YAML block.
 type Config struct {
-	// OTLPEndpoint is the gRPC host:port of your OTLP collector,
-	// e.g. "localhost:4317" or "otelcol.example.com:4317".
+	// Exporter is [ExporterOTLP] or [ExporterStdout]. Empty means OTLP.
+	// The stdout exporter writes spans and metrics as JSON to File, or to
+	// stdout without one, and exports no logs.
+	Exporter string
+
+	// File is the file the stdout exporter appends to. Empty means stdout.
+	File string
+
+	// OTLPEndpoint is the host:port of your OTLP collector, e.g.
+	// "localhost:4317" or "otelcol.example.com:4318". Over HTTP it may also
+	// be a base URL such as "https://otlp.example.com/otlp", to which the
+	// signal paths /v1/traces, /v1/metrics and /v1/logs are appended.
 	OTLPEndpoint string
 
+	// Protocol is [ProtocolGRPC] or [ProtocolHTTP]. Empty means gRPC.
+	Protocol string
+
 	// Insecure disables TLS for the collector connection.
 	// Set to true for local collectors that have no TLS cert.
 	Insecure bool
@@ -44,7 +80,7 @@ type Config struct {
 	// Defaults to "reminderrelay".
 	ServiceName string
 
-	// Headers is sent as gRPC metadata on every OTLP request.
+	// Headers is sent as gRPC metadata or HTTP headers on every OTLP request.
 	// Equivalent to the OTEL_EXPORTER_OTLP_HEADERS environment variable.
 	// Typical use: authentication tokens such as {"Authorization": "Bearer <token>"}.
 	Headers map[string]string
@@ -56,7 +92,8 @@ type Config struct {
 type ShutdownFunc func(context.Context) error
 
 // Setup initialises the global OpenTelemetry trace, metric, and log providers.
-// The three exporters share a single gRPC connection to cfg.OTLPEndpoint.
+// Over gRPC the three exporters share a single connection to
+// cfg.OTLPEndpoint.
 //
 // Returns a [ShutdownFunc] that must be deferred by the caller to flush and
 // close all providers. The function is always non-nil — on error it becomes a
@@ -77,6 +114,78 @@ func Setup(ctx context.Context, cfg Config) (ShutdownFunc, error) {
 		return noopShutdown, fmt.Errorf("building OTel resource: %w", err)
 	}
 
+	var exp *exporters
+	switch {
+	case cfg.Exporter == ExporterStdout:
+		exp, err = stdoutExporters(cfg.File)
+	case cfg.Exporter != "" && cfg.Exporter != ExporterOTLP:
+		return noopShutdown, fmt.Errorf("unknown telemetry exporter %q", cfg.Exporter)
+	case cfg.Protocol == "" || cfg.Protocol == ProtocolGRPC:
+		exp, err = grpcExporters(ctx, cfg)
+	case cfg.Protocol == ProtocolHTTP:
+		exp, err = httpExporters(ctx, cfg)
+	default:
+		return noopShutdown, fmt.Errorf("unknown OTLP protocol %q", cfg.Protocol)
+	}
+	if err != nil {
+		return noopShutdown, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp.trace),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exp.metric)),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetMeterProvider(mp)
+
+	var lp *sdklog.LoggerProvider
+	if exp.log != nil {
+		lp = sdklog.NewLoggerProvider(
+			sdklog.WithProcessor(sdklog.NewBatchProcessor(exp.log)),
+			sdklog.WithResource(res),
+		)
+		global.SetLoggerProvider(lp)
+	}
+
+	// Return a shutdown function that flushes all providers and closes the
+	// shared gRPC connection or the output file, if any.
+	return func(ctx context.Context) error {
+		var errs []error
+		if err := tp.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("trace provider shutdown: %w", err))
+		}
+		if err := mp.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("metric provider shutdown: %w", err))
+		}
+		if lp != nil {
+			if err := lp.Shutdown(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("log provider shutdown: %w", err))
+			}
+		}
+		if err := exp.close(); err != nil {
+			errs = append(errs, fmt.Errorf("telemetry exporter close: %w", err))
+		}
+		return errors.Join(errs...)
+	}, nil
+}
+
+// exporters are the exporters of the three signals; log is nil when logs are
+// not exported. close releases what they share once the providers using them
+// have shut down.
+type exporters struct {
+	trace  sdktrace.SpanExporter
+	metric sdkmetric.Exporter
+	log    sdklog.Exporter
+	close  func() error
+}
+
+// grpcExporters creates exporters sharing one gRPC connection.
+func grpcExporters(ctx context.Context, cfg Config) (*exporters, error) {
 	// Dial the collector once; all three exporters share this connection.
 	var creds credentials.TransportCredentials
 	if cfg.Insecure {
@@ -86,78 +195,125 @@ func Setup(ctx context.Context, cfg Config) (ShutdownFunc, error) {
 	}
 	conn, err := grpc.NewClient(cfg.OTLPEndpoint, grpc.WithTransportCredentials(creds))
 	if err != nil {
-		return noopShutdown, fmt.Errorf("dialling OTLP collector at %q: %w", cfg.OTLPEndpoint, err)
+		return nil, fmt.Errorf("dialling OTLP collector at %q: %w", cfg.OTLPEndpoint, err)
 	}
 
-	// --- Trace provider -------------------------------------------------
-
 	traceExp, err := otlptracegrpc.New(ctx,
 		otlptracegrpc.WithGRPCConn(conn),
 		otlptracegrpc.WithHeaders(cfg.Headers),
 	)
 	if err != nil {
 		_ = conn.Close()
-		return noopShutdown, fmt.Errorf("creating OTLP trace exporter: %w", err)
+		return nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
 	}
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(traceExp),
-		sdktrace.WithResource(res),
-	)
-	otel.SetTracerProvider(tp)
-
-	// --- Metric provider ------------------------------------------------
-
 	metricExp, err := otlpmetricgrpc.New(ctx,
 		otlpmetricgrpc.WithGRPCConn(conn),
 		otlpmetricgrpc.WithHeaders(cfg.Headers),
 	)
 	if err != nil {
-		_ = tp.Shutdown(ctx)
+		_ = traceExp.Shutdown(ctx)
 		_ = conn.Close()
-		return noopShutdown, fmt.Errorf("creating OTLP metric exporter: %w", err)
+		return nil, fmt.Errorf("creating OTLP metric exporter: %w", err)
 	}
-	mp := sdkmetric.NewMeterProvider(
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExp)),
-		sdkmetric.WithResource(res),
-	)
-	otel.SetMeterProvider(mp)
-
-	// --- Log provider ---------------------------------------------------
-
 	logExp, err := otlploggrpc.New(ctx,
 		otlploggrpc.WithGRPCConn(conn),
 		otlploggrpc.WithHeaders(cfg.Headers),
 	)
 	if err != nil {
-		_ = tp.Shutdown(ctx)
-		_ = mp.Shutdown(ctx)
+		_ = traceExp.Shutdown(ctx)
+		_ = metricExp.Shutdown(ctx)
 		_ = conn.Close()
-		return noopShutdown, fmt.Errorf("creating OTLP log exporter: %w", err)
+		return nil, fmt.Errorf("creating OTLP log exporter: %w", err)
 	}
-	lp := sdklog.NewLoggerProvider(
-		sdklog.WithProcessor(sdklog.NewBatchProcessor(logExp)),
-		sdklog.WithResource(res),
-	)
-	global.SetLoggerProvider(lp)
+	return &exporters{trace: traceExp, metric: metricExp, log: logExp, close: conn.Close}, nil
+}
 
-	// Return a shutdown function that flushes all providers and closes the
-	// shared gRPC connection.
-	return func(ctx context.Context) error {
-		var errs []error
-		if err := tp.Shutdown(ctx); err != nil {
-			errs = append(errs, fmt.Errorf("trace provider shutdown: %w", err))
-		}
-		if err := mp.Shutdown(ctx); err != nil {
-			errs = append(errs, fmt.Errorf("metric provider shutdown: %w", err))
-		}
-		if err := lp.Shutdown(ctx); err != nil {
-			errs = append(errs, fmt.Errorf("log provider shutdown: %w", err))
-		}
-		if err := conn.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("OTLP gRPC connection close: %w", err))
+// httpExporters creates exporters posting to the collector over HTTP. Each
+// manages its own connections.
+func httpExporters(ctx context.Context, cfg Config) (*exporters, error) {
+	host, prefix, plain, err := httpEndpoint(cfg.OTLPEndpoint, cfg.Insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	traceOpts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(host),
+		otlptracehttp.WithURLPath(prefix + "/v1/traces"),
+		otlptracehttp.WithHeaders(cfg.Headers),
+	}
+	metricOpts := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithEndpoint(host),
+		otlpmetrichttp.WithURLPath(prefix + "/v1/metrics"),
+		otlpmetrichttp.WithHeaders(cfg.Headers),
+	}
+	logOpts := []otlploghttp.Option{
+		otlploghttp.WithEndpoint(host),
+		otlploghttp.WithURLPath(prefix + "/v1/logs"),
+		otlploghttp.WithHeaders(cfg.Headers),
+	}
+	if plain {
+		traceOpts = append(traceOpts, otlptracehttp.WithInsecure())
+		metricOpts = append(metricOpts, otlpmetrichttp.WithInsecure())
+		logOpts = append(logOpts, otlploghttp.WithInsecure())
+	}
+
+	traceExp, err := otlptracehttp.New(ctx, traceOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
+	}
+	metricExp, err := otlpmetrichttp.New(ctx, metricOpts...)
+	if err != nil {
+		_ = traceExp.Shutdown(ctx)
+		return nil, fmt.Errorf("creating OTLP metric exporter: %w", err)
+	}
+	logExp, err := otlploghttp.New(ctx, logOpts...)
+	if err != nil {
+		_ = traceExp.Shutdown(ctx)
+		_ = metricExp.Shutdown(ctx)
+		return nil, fmt.Errorf("creating OTLP log exporter: %w", err)
+	}
+	return &exporters{trace: traceExp, metric: metricExp, log: logExp, close: func() error { return nil }}, nil
+}
+
+// stdoutExporters creates exporters writing spans and metrics as JSON lines to
+// the file at path, appending to it, or to stdout if path is empty. Logs are
+// left to the daemon's own log output.
+func stdoutExporters(path string) (*exporters, error) {
+	var w io.Writer = os.Stdout
+	closeFn := func() error { return nil }
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("opening telemetry file: %w", err)
 		}
-		return errors.Join(errs...)
-	}, nil
+		w, closeFn = f, f.Close
+	}
+
+	traceExp, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		_ = closeFn()
+		return nil, fmt.Errorf("creating stdout trace exporter: %w", err)
+	}
+	metricExp, err := stdoutmetric.New(stdoutmetric.WithWriter(w))
+	if err != nil {
+		_ = closeFn()
+		return nil, fmt.Errorf("creating stdout metric exporter: %w", err)
+	}
+	return &exporters{trace: traceExp, metric: metricExp, close: closeFn}, nil
+}
+
+// httpEndpoint splits an OTLP/HTTP endpoint, host:port or a base URL, into
+// the host:port, the path prefix of the signal paths, and whether to use
+// plain HTTP. A URL's scheme takes precedence over insecure.
+func httpEndpoint(endpoint string, insecure bool) (host, prefix string, plain bool, err error) {
+	if !strings.Contains(endpoint, "://") {
+		return endpoint, "", insecure, nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", "", false, fmt.Errorf("OTLP endpoint %q must be host:port or an http or https URL", endpoint)
+	}
+	return u.Host, strings.TrimSuffix(u.Path, "/"), u.Scheme == "http", nil
 }
 
 // noopShutdown is returned on error so callers can always defer unconditionally.